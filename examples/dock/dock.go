@@ -0,0 +1,70 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gimain"
+	"github.com/goki/gi/oswin"
+)
+
+func main() {
+	gimain.Main(func() {
+		mainrun()
+	})
+}
+
+func mainrun() {
+	width := 1024
+	height := 768
+
+	oswin.TheApp.SetName("dock")
+
+	win := gi.NewWindow2D("gogi-dock-test", "GoGi Dock Test", width, height, true) // pixel sizes
+
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+
+	mfr := win.SetMainFrame()
+
+	dv := mfr.AddNewChild(gi.KiT_DockView, "dock").(*gi.DockView)
+
+	for _, nm := range []string{"Files", "Editor", "Output", "Outline"} {
+		lbl := &gi.Label{}
+		lbl.InitName(lbl, nm)
+		lbl.SetText("this is the " + nm + " panel -- drag its tab to the edge of another area to split it, to the center to stack it, or right-click the tab to float it")
+		lbl.SetProp("white-space", gi.WhiteSpaceNormal) // wrap
+		dv.AddPanel(lbl, nm)
+	}
+	if !dv.OpenState() { // default arrangement
+		dv.MovePanel("Files", "Editor", gi.DockLeft)
+		dv.MovePanel("Output", "Editor", gi.DockBottom)
+	}
+	dv.ConfigDock()
+
+	// main menu
+	appnm := oswin.TheApp.Name()
+	mmen := win.MainMenu
+	mmen.ConfigMenus([]string{appnm, "Edit", "Window"})
+
+	amen := win.MainMenu.KnownChildByName(appnm, 0).(*gi.Action)
+	amen.Menu = make(gi.Menu, 0, 10)
+	amen.Menu.AddAppMenu(win)
+
+	emen := win.MainMenu.KnownChildByName("Edit", 1).(*gi.Action)
+	emen.Menu = make(gi.Menu, 0, 10)
+	emen.Menu.AddCopyCutPaste(win)
+
+	win.OSWin.SetCloseCleanFunc(func(w oswin.Window) {
+		dv.SaveState()
+		go oswin.TheApp.Quit() // once main window is closed, quit
+	})
+
+	win.MainMenuUpdated()
+
+	vp.UpdateEndNoSig(updt)
+
+	win.StartEventLoop()
+}
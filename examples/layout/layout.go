@@ -145,6 +145,23 @@ func mainrun() {
 		// fr.SetProp("max-width", -1) // spacer
 	}
 
+	row5 := mfr.AddNewChild(gi.KiT_Layout, "row5").(*gi.Layout)
+	row5.Lay = gi.LayoutGrid
+	row5.SetProp("grid-template-areas", `"head head head" "side main main" "side foot foot"`)
+	row5.SetProp("grid-template-columns", "10em 1fr 2fr")
+	row5.SetProp("max-width", -1)
+	row5.SetProp("margin", 6.0)
+	row5.SetProp("spacing", 4.0)
+
+	for _, area := range []string{"head", "side", "main", "foot"} {
+		fr := row5.AddNewChild(gi.KiT_Frame, area).(*gi.Frame)
+		fr.SetProp("grid-area", area)
+		fr.SetProp("min-height", "2em")
+		fr.SetProp("max-width", -1)
+		fr.SetProp("max-height", -1)
+		fr.SetProp("margin", 2.0)
+	}
+
 	// main menu
 	appnm := oswin.TheApp.Name()
 	mmen := win.MainMenu
@@ -65,13 +65,30 @@ func FileViewOpenSVG(vp *gi.Viewport2D) {
 		})
 }
 
+func SaveSVG(fnm string) {
+	CurFilename = fnm
+	TheFile.SetText(CurFilename)
+	fmt.Printf("Saving: %v\n", CurFilename)
+	TheSVG.SaveXML(CurFilename)
+}
+
+func FileViewSaveSVG(vp *gi.Viewport2D) {
+	giv.FileViewDialog(vp, CurFilename, ".svg", giv.DlgOpts{Title: "Save SVG"}, nil,
+		vp.Win, func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.DialogAccepted) {
+				dlg, _ := send.(*gi.Dialog)
+				SaveSVG(giv.FileViewDialogValue(dlg))
+			}
+		})
+}
+
 func mainrun() {
 	width := 1600
 	height := 1200
 
 	oswin.TheApp.SetName("svg")
 	oswin.TheApp.SetAbout(`This is a demo of the SVG rendering (and start on editing) in the <b>GoGi</b> graphical interface system, within the <b>GoKi</b> tree framework.  See <a href="https://github.com/goki">GoKi on GitHub</a>
-<p>You can drag the image around and use the scroll wheel to zoom.</p>`)
+<p>You can drag the image around and use the scroll wheel to zoom, and select and edit elements in the Select and Nodes modes.</p>`)
 
 	win := gi.NewWindow2D("gogi-svg-viewer", "GoGi SVG Viewer", width, height, true)
 
@@ -105,6 +122,9 @@ func mainrun() {
 	loads.SetText("Open SVG")
 	loads.StartFocus()
 
+	saves := tbar.AddNewChild(gi.KiT_Action, "savesvg").(*gi.Action)
+	saves.SetText("Save SVG")
+
 	fnm := tbar.AddNewChild(gi.KiT_TextField, "cur-fname").(*gi.TextField)
 	TheFile = fnm
 	fnm.SetMinPrefWidth(units.NewValue(60, units.Ch))
@@ -155,10 +175,45 @@ func mainrun() {
 	try.SetValue(svge.Trans.Y)
 	TheTransY = try
 
+	tbar.AddNewChild(gi.KiT_Space, "spcmd")
+	mdlb := tbar.AddNewChild(gi.KiT_Label, "mdlb").(*gi.Label)
+	mdlb.Text = "Mode: "
+	mdlb.SetProp("vertical-align", gi.AlignMiddle)
+	mdlb.Tooltip = "editing mode -- View only pans and zooms, Select selects and transforms elements, Nodes also moves the points of selected paths"
+
+	mode := tbar.AddNewChild(gi.KiT_ComboBox, "mode").(*gi.ComboBox)
+	mode.ItemsFromEnum(svg.KiT_EditorModes, true, 0)
+	mode.Tooltip = mdlb.Tooltip
+
+	tbar.AddNewChild(gi.KiT_Space, "spcan")
+	anim := tbar.AddNewChild(gi.KiT_Action, "anim").(*gi.Action)
+	anim.SetText("Pause")
+	anim.Tooltip = "pause / play any animations in the svg"
+
+	mode.ComboSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		svge.Mode = svg.EditorModes(sig)
+		svge.ClearSelection()
+		win.FullReRender()
+	})
+
+	anim.ActionSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if svge.Clock.IsRunning() {
+			svge.AnimPause()
+			anim.SetText("Play")
+		} else {
+			svge.AnimStart()
+			anim.SetText("Pause")
+		}
+	})
+
 	loads.ActionSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		FileViewOpenSVG(vp)
 	})
 
+	saves.ActionSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		FileViewSaveSVG(vp)
+	})
+
 	fnm.TextFieldSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		if sig == int64(gi.TextFieldDone) {
 			tf := send.(*gi.TextField)
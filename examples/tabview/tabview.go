@@ -40,6 +40,10 @@ func mainrun() {
 	lbl2.SetText("this is the contents of the second tab")
 	lbl2.SetProp("white-space", gi.WhiteSpaceNormal) // wrap
 
+	// dirty tabs ask before closing -- drag tabs to reorder or tear them off
+	tv.ConfirmDirtyClose = true
+	tv.SetTabDirty(1, true)
+
 	tv.SelectTabIndex(0)
 
 	// main menu
@@ -0,0 +1,637 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unsafe"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+)
+
+// animations: values are interpolated over time by an Anim, whose Func is
+// called with the eased progress at each step -- the Animator of each Window
+// steps its running animations from the window event loop, AnimFPS times per
+// second, using an AnimClock that can be a ManualClock for deterministic
+// stepping in tests.  Style transitions (the transition style property)
+// animate style properties when a widget changes state, e.g., on hover.
+
+////////////////////////////////////////////////////////////////////////////////////////
+//    Easing
+
+// EaseFunc maps the linear progress of an animation, from 0 to 1, to the
+// eased progress that is used to interpolate its values
+type EaseFunc func(t float32) float32
+
+// EaseLinear is linear progress (no easing)
+func EaseLinear(t float32) float32 {
+	return t
+}
+
+// EaseInQuad starts slow and accelerates
+func EaseInQuad(t float32) float32 {
+	return t * t
+}
+
+// EaseOutQuad starts fast and decelerates
+func EaseOutQuad(t float32) float32 {
+	return t * (2 - t)
+}
+
+// EaseInOutQuad accelerates until halfway and then decelerates
+func EaseInOutQuad(t float32) float32 {
+	if t < .5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// EaseInCubic starts slow and accelerates, more sharply than EaseInQuad
+func EaseInCubic(t float32) float32 {
+	return t * t * t
+}
+
+// EaseOutCubic starts fast and decelerates, more sharply than EaseOutQuad
+func EaseOutCubic(t float32) float32 {
+	t--
+	return t*t*t + 1
+}
+
+// EaseInOutCubic accelerates until halfway and then decelerates, more
+// sharply than EaseInOutQuad
+func EaseInOutCubic(t float32) float32 {
+	if t < .5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return t*t*t/2 + 1
+}
+
+// EaseCubicBezier returns the easing function for the CSS
+// cubic-bezier(x1, y1, x2, y2) timing function: a cubic bezier curve from
+// (0,0) to (1,1) with given control points, where x is the linear progress
+// and y is the eased progress
+func EaseCubicBezier(x1, y1, x2, y2 float32) EaseFunc {
+	return func(t float32) float32 {
+		if t <= 0 {
+			return 0
+		}
+		if t >= 1 {
+			return 1
+		}
+		// x is monotonic in the curve parameter, so bisect for x == t
+		lo, hi := float32(0), float32(1)
+		u := t
+		for i := 0; i < 30; i++ {
+			x := bezierCoord(u, x1, x2)
+			if math32.Abs(x-t) < 1.0e-5 {
+				break
+			}
+			if x < t {
+				lo = u
+			} else {
+				hi = u
+			}
+			u = (lo + hi) / 2
+		}
+		return bezierCoord(u, y1, y2)
+	}
+}
+
+// bezierCoord returns one coordinate of a cubic bezier curve from 0 to 1
+// with given control point coordinates, at curve parameter u
+func bezierCoord(u, c1, c2 float32) float32 {
+	v := 1 - u
+	return 3*v*v*u*c1 + 3*v*u*u*c2 + u*u*u
+}
+
+// EaseFuncs are the easing functions by name, including the CSS timing
+// function keywords -- see EaseByName
+var EaseFuncs = map[string]EaseFunc{
+	"linear":            EaseLinear,
+	"ease":              EaseCubicBezier(.25, .1, .25, 1),
+	"ease-in":           EaseCubicBezier(.42, 0, 1, 1),
+	"ease-out":          EaseCubicBezier(0, 0, .58, 1),
+	"ease-in-out":       EaseCubicBezier(.42, 0, .58, 1),
+	"ease-in-quad":      EaseInQuad,
+	"ease-out-quad":     EaseOutQuad,
+	"ease-in-out-quad":  EaseInOutQuad,
+	"ease-in-cubic":     EaseInCubic,
+	"ease-out-cubic":    EaseOutCubic,
+	"ease-in-out-cubic": EaseInOutCubic,
+}
+
+// EaseByName returns the easing function with given name in EaseFuncs, or
+// for a CSS cubic-bezier(x1, y1, x2, y2) function -- false if not found
+func EaseByName(nm string) (EaseFunc, bool) {
+	nm = strings.TrimSpace(nm)
+	if ef, ok := EaseFuncs[nm]; ok {
+		return ef, true
+	}
+	if !strings.HasPrefix(nm, "cubic-bezier(") || !strings.HasSuffix(nm, ")") {
+		return nil, false
+	}
+	args := strings.Split(nm[len("cubic-bezier("):len(nm)-1], ",")
+	if len(args) != 4 {
+		return nil, false
+	}
+	var cp [4]float32
+	for i, a := range args {
+		f, err := strconv.ParseFloat(strings.TrimSpace(a), 32)
+		if err != nil {
+			return nil, false
+		}
+		cp[i] = float32(f)
+	}
+	return EaseCubicBezier(cp[0], cp[1], cp[2], cp[3]), true
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//    Interpolation
+
+// InterpolateFloat32 returns the value t (0..1) of the way from a to b
+func InterpolateFloat32(a, b, t float32) float32 {
+	return a + (b-a)*t
+}
+
+// InterpolateValue returns the value t (0..1) of the way from one value to
+// another of the same type, which can be float32, float64, int, Color, HSLA,
+// units.Value or Vec2D -- false if they are not both one of these types
+func InterpolateValue(from, to interface{}, t float32) (interface{}, bool) {
+	switch fv := from.(type) {
+	case float32:
+		if tv, ok := to.(float32); ok {
+			return InterpolateFloat32(fv, tv, t), true
+		}
+	case float64:
+		if tv, ok := to.(float64); ok {
+			return fv + (tv-fv)*float64(t), true
+		}
+	case int:
+		if tv, ok := to.(int); ok {
+			return fv + int(math.Round(float64(tv-fv)*float64(t))), true
+		}
+	case Color:
+		if tv, ok := to.(Color); ok {
+			return fv.Interpolate(tv, t), true
+		}
+	case HSLA:
+		if tv, ok := to.(HSLA); ok {
+			return fv.Interpolate(tv, t), true
+		}
+	case units.Value:
+		if tv, ok := to.(units.Value); ok {
+			return fv.Interpolate(tv, t), true
+		}
+	case Vec2D:
+		if tv, ok := to.(Vec2D); ok {
+			return fv.Interpolate(tv, t), true
+		}
+	}
+	return nil, false
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//    Anim, Animator
+
+// AnimClock provides the current time for animations -- an Animator uses
+// the system clock by default, and tests can use a ManualClock to step
+// animations deterministically
+type AnimClock interface {
+	// Now returns the current time
+	Now() time.Time
+}
+
+// SystemClock is the AnimClock for the actual time
+type SystemClock struct{}
+
+func (sc SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock is an AnimClock whose time only changes when it is set or
+// advanced -- for deterministic stepping of animations in tests
+type ManualClock struct {
+	T time.Time `desc:"the current time"`
+}
+
+func (mc *ManualClock) Now() time.Time {
+	return mc.T
+}
+
+// Advance advances the time by given duration
+func (mc *ManualClock) Advance(d time.Duration) {
+	mc.T = mc.T.Add(d)
+}
+
+// AnimProgress returns the eased progress, from 0 to 1, after given elapsed
+// time of an animation with given delay, duration and easing function
+// (linear if nil), and whether the animation has ended
+func AnimProgress(elapsed, delay, dur time.Duration, ease EaseFunc) (float32, bool) {
+	elapsed -= delay
+	if elapsed < 0 {
+		return 0, false
+	}
+	if elapsed >= dur {
+		return 1, true
+	}
+	p := float32(elapsed) / float32(dur)
+	if ease != nil {
+		p = ease(p)
+	}
+	return p, false
+}
+
+// Anim is an animation, which calls its Func with the eased progress, from
+// 0 to 1, at each step over its duration -- Func typically interpolates a
+// value with InterpolateValue, sets it, and updates the node
+type Anim struct {
+	Recv  ki.Ki           `desc:"node that is animated -- the animation is stopped if it is deleted, and starting another animation with the same Recv and Name replaces this one"`
+	Name  string          `desc:"name of the animation, e.g., the property that is animated"`
+	Dur   time.Duration   `desc:"duration of the animation, after the Delay -- AnimForever runs until stopped"`
+	Delay time.Duration   `desc:"delay before the animation starts"`
+	Ease  EaseFunc        `json:"-" xml:"-" desc:"easing function -- linear if nil"`
+	Func  func(p float32) `json:"-" xml:"-" desc:"function called with the eased progress at each step after the Delay -- it is always called with 1 at the end"`
+	Done  func()          `json:"-" xml:"-" desc:"optional function called after the animation has ended"`
+	Start time.Time       `desc:"time when the animation was started -- set by Animator.Start"`
+}
+
+// AnimForever is the Dur of an animation that runs until it is stopped --
+// its Func is called with a progress of 0 at each step, and it tracks its
+// own time, e.g., the animations of an svg.SVG
+const AnimForever time.Duration = -1
+
+// Progress returns the eased progress of the animation at given time, and
+// whether it has ended
+func (a *Anim) Progress(now time.Time) (float32, bool) {
+	if a.Dur == AnimForever {
+		return 0, false
+	}
+	return AnimProgress(now.Sub(a.Start), a.Delay, a.Dur, a.Ease)
+}
+
+// IsDeleted returns whether the Recv of the animation has been deleted
+func (a *Anim) IsDeleted() bool {
+	return a.Recv != nil && (a.Recv.This() == nil || a.Recv.IsDeleted())
+}
+
+// AnimFPS is the number of times per second that the running animations of
+// a window are stepped
+var AnimFPS = 60
+
+// AnimTick is sent as the Data of a CustomEvent to step the animations of a
+// window in its event loop -- it is not sent to any widgets
+type AnimTick struct{}
+
+// Animator runs the animations of a Window -- while any are running, the
+// window event loop steps them AnimFPS times per second
+type Animator struct {
+	Clock       AnimClock  `json:"-" xml:"-" desc:"clock for the animations -- the system clock if nil"`
+	Win         *Window    `json:"-" xml:"-" desc:"window whose event loop steps the animations -- if nil, Step must be called to step them"`
+	Anims       []*Anim    `json:"-" xml:"-" desc:"the running animations"`
+	Mu          sync.Mutex `json:"-" xml:"-" view:"-" desc:"mutex protecting the animations"`
+	ticking     bool
+	tickPending bool
+}
+
+// Now returns the current time of the Clock
+func (an *Animator) Now() time.Time {
+	if an.Clock == nil {
+		return time.Now()
+	}
+	return an.Clock.Now()
+}
+
+// Start starts given animation at the current time, replacing any running
+// animation with the same Recv and Name, and returns it
+func (an *Animator) Start(a *Anim) *Anim {
+	a.Start = an.Now()
+	an.Mu.Lock()
+	got := false
+	for i, ra := range an.Anims {
+		if ra.Recv == a.Recv && ra.Name == a.Name {
+			an.Anims[i] = a
+			got = true
+			break
+		}
+	}
+	if !got {
+		an.Anims = append(an.Anims, a)
+	}
+	tick := an.Win != nil && !an.ticking
+	if tick {
+		an.ticking = true
+	}
+	an.Mu.Unlock()
+	if tick {
+		go an.TickLoop()
+	}
+	return a
+}
+
+// Stop stops the running animation with given Recv and Name, without
+// calling its Func or Done again -- returns false if not found
+func (an *Animator) Stop(recv ki.Ki, name string) bool {
+	an.Mu.Lock()
+	defer an.Mu.Unlock()
+	for i, ra := range an.Anims {
+		if ra.Recv == recv && ra.Name == name {
+			an.Anims = append(an.Anims[:i], an.Anims[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// StopAll stops all the running animations of given node, or all of them
+// if nil
+func (an *Animator) StopAll(recv ki.Ki) {
+	an.Mu.Lock()
+	defer an.Mu.Unlock()
+	if recv == nil {
+		an.Anims = nil
+		return
+	}
+	for i := len(an.Anims) - 1; i >= 0; i-- {
+		if an.Anims[i].Recv == recv {
+			an.Anims = append(an.Anims[:i], an.Anims[i+1:]...)
+		}
+	}
+}
+
+// IsRunning returns whether an animation with given Recv and Name is running
+func (an *Animator) IsRunning(recv ki.Ki, name string) bool {
+	an.Mu.Lock()
+	defer an.Mu.Unlock()
+	for _, ra := range an.Anims {
+		if ra.Recv == recv && ra.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NRunning returns the number of running animations
+func (an *Animator) NRunning() int {
+	an.Mu.Lock()
+	defer an.Mu.Unlock()
+	return len(an.Anims)
+}
+
+// Step steps all the running animations to the current time of the Clock,
+// and removes those that have ended, after calling their Done -- returns
+// true if any are still running.  Called from the window event loop.
+func (an *Animator) Step() bool {
+	now := an.Now()
+	an.Mu.Lock()
+	an.tickPending = false
+	anims := make([]*Anim, len(an.Anims)) // Func can start or stop animations
+	copy(anims, an.Anims)
+	an.Mu.Unlock()
+
+	var ended []*Anim
+	for _, a := range anims {
+		if a.IsDeleted() {
+			ended = append(ended, a)
+			continue
+		}
+		if now.Sub(a.Start) < a.Delay {
+			continue
+		}
+		p, end := a.Progress(now)
+		if a.Func != nil {
+			a.Func(p)
+		}
+		if end {
+			ended = append(ended, a)
+		}
+	}
+
+	an.Mu.Lock()
+	for _, a := range ended {
+		for i, ra := range an.Anims {
+			if ra == a { // not if replaced in the meantime
+				an.Anims = append(an.Anims[:i], an.Anims[i+1:]...)
+				break
+			}
+		}
+	}
+	run := len(an.Anims) > 0
+	an.Mu.Unlock()
+
+	for _, a := range ended {
+		if a.Done != nil && !a.IsDeleted() {
+			a.Done()
+		}
+	}
+	return run
+}
+
+// TickLoop sends AnimTick events to the window, AnimFPS times per second,
+// until there are no running animations or the window is closed -- started
+// by Start as needed
+func (an *Animator) TickLoop() {
+	tick := time.NewTicker(time.Second / time.Duration(AnimFPS))
+	defer tick.Stop()
+	for range tick.C {
+		an.Mu.Lock()
+		if len(an.Anims) == 0 || an.Win.IsClosed() {
+			an.ticking = false
+			an.Mu.Unlock()
+			return
+		}
+		send := !an.tickPending // don't pile up ticks if steps are slow
+		an.tickPending = true
+		an.Mu.Unlock()
+		if send {
+			an.Win.SendCustomEvent(AnimTick{})
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//    Transitions
+
+// Transition is one transition parsed from the transition style property:
+// changes of the Prop style property animate over Dur, after Delay
+type Transition struct {
+	Prop  string        `desc:"name of the style property, e.g., background-color"`
+	Dur   time.Duration `desc:"duration of the transition"`
+	Delay time.Duration `desc:"delay before the transition starts"`
+	Ease  EaseFunc      `json:"-" xml:"-" desc:"easing function -- the CSS ease by default"`
+}
+
+// Progress returns the eased progress of the transition after given elapsed
+// time, and whether it has ended
+func (tr *Transition) Progress(elapsed time.Duration) (float32, bool) {
+	return AnimProgress(elapsed, tr.Delay, tr.Dur, tr.Ease)
+}
+
+// splitOutsideParens splits given string at the runes for which sep is
+// true, except within parentheses, as in cubic-bezier(...) -- empty fields
+// are dropped
+func splitOutsideParens(str string, sep func(r rune) bool) []string {
+	var flds []string
+	depth := 0
+	st := 0
+	for i, r := range str {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case depth == 0 && sep(r):
+			if f := strings.TrimSpace(str[st:i]); f != "" {
+				flds = append(flds, f)
+			}
+			st = i + 1
+		}
+	}
+	if f := strings.TrimSpace(str[st:]); f != "" {
+		flds = append(flds, f)
+	}
+	return flds
+}
+
+// ParseTransitions parses the value of the transition style property: a
+// comma-separated list of "property duration [easing] [delay]" items, as in
+// CSS, e.g., "background-color 0.2s ease-out, border-color 100ms" -- the
+// easing is a name in EaseFuncs or a cubic-bezier(x1, y1, x2, y2) function,
+// and "none" means no transitions
+func ParseTransitions(str string) []Transition {
+	var trs []Transition
+	for _, it := range splitOutsideParens(str, func(r rune) bool { return r == ',' }) {
+		flds := splitOutsideParens(it, unicode.IsSpace)
+		if flds[0] == "none" {
+			continue
+		}
+		tr := Transition{Prop: flds[0], Ease: EaseFuncs["ease"]}
+		ndur := 0
+		for _, f := range flds[1:] {
+			if d, err := time.ParseDuration(f); err == nil {
+				if ndur == 0 {
+					tr.Dur = d
+				} else {
+					tr.Delay = d
+				}
+				ndur++
+			} else if ef, ok := EaseByName(f); ok {
+				tr.Ease = ef
+			} else {
+				log.Printf("gi.ParseTransitions: could not parse: %v in transition: %v\n", f, it)
+			}
+		}
+		trs = append(trs, tr)
+	}
+	return trs
+}
+
+// StyleTransProp is the state of the transition of one style property
+type StyleTransProp struct {
+	From  interface{} `desc:"value the transition started from"`
+	To    interface{} `desc:"target value of the transition"`
+	Cur   interface{} `desc:"current value"`
+	Start time.Time   `desc:"time when the transition started"`
+	Trans Transition  `desc:"the transition"`
+}
+
+// StyleTransitions holds the state of the style transitions of a widget,
+// by style property name
+type StyleTransitions map[string]*StyleTransProp
+
+// styleTransValue returns the value of a style field from its FieldIface,
+// for transitions -- false if it is not a type that can be interpolated
+func styleTransValue(fi interface{}) (interface{}, bool) {
+	switch fv := fi.(type) {
+	case *ColorSpec:
+		if fv.Source != SolidColor {
+			return nil, false
+		}
+		return fv.Color, true
+	case *Color:
+		return *fv, true
+	case *units.Value:
+		return *fv, true
+	case *float32:
+		return *fv, true
+	case *float64:
+		return *fv, true
+	}
+	return nil, false
+}
+
+// setStyleTransValue sets the value of a style field from its FieldIface
+func setStyleTransValue(fi, val interface{}) {
+	switch fv := fi.(type) {
+	case *ColorSpec:
+		fv.Color = val.(Color)
+	case *Color:
+		*fv = val.(Color)
+	case *units.Value:
+		*fv = val.(units.Value)
+	case *float32:
+		*fv = val.(float32)
+	case *float64:
+		*fv = val.(float64)
+	}
+}
+
+// Apply applies the Transitions of given style, which has just been set to
+// the style for the current state, at given time: properties whose values
+// differ from the last target values start transitioning from their current
+// values, and all transitioning properties are set to their current values
+// -- if any were started, returns the time until the last of the running
+// transitions ends, and otherwise 0.  The first values seen for a property
+// do not transition.
+func (st StyleTransitions) Apply(sty *Style, now time.Time) time.Duration {
+	started := false
+	styptr := uintptr(unsafe.Pointer(sty))
+	for _, tr := range sty.Transitions {
+		fld, ok := StyleFields.Fields[tr.Prop]
+		if !ok {
+			continue
+		}
+		fi := fld.FieldIface(styptr)
+		trg, ok := styleTransValue(fi)
+		if !ok {
+			continue
+		}
+		tp, has := st[tr.Prop]
+		if !has {
+			st[tr.Prop] = &StyleTransProp{From: trg, To: trg, Cur: trg, Start: now, Trans: tr}
+			continue
+		}
+		if trg != tp.To {
+			tp.From = tp.Cur
+			tp.To = trg
+			tp.Start = now
+			tp.Trans = tr
+			started = true
+		}
+		p, _ := tp.Trans.Progress(now.Sub(tp.Start))
+		if cur, ok := InterpolateValue(tp.From, tp.To, p); ok {
+			tp.Cur = cur
+		}
+		setStyleTransValue(fi, tp.Cur)
+	}
+	var end time.Duration
+	if !started {
+		return end
+	}
+	for _, tp := range st {
+		if d := tp.Start.Add(tp.Trans.Delay + tp.Trans.Dur).Sub(now); d > end {
+			end = d
+		}
+	}
+	return end
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goki/gi/units"
+)
+
+func TestEaseFuncs(t *testing.T) {
+	for nm, ef := range EaseFuncs {
+		if ef(0) != 0 || ef(1) != 1 {
+			t.Errorf("ease %v endpoints: %v %v", nm, ef(0), ef(1))
+		}
+	}
+	if v := EaseInOutCubic(.5); v != .5 {
+		t.Errorf("ease-in-out-cubic: %v", v)
+	}
+	ef, ok := EaseByName("cubic-bezier(0, 0, 1, 1)")
+	if !ok {
+		t.Fatalf("cubic-bezier not parsed")
+	}
+	if v := ef(.25); v < .249 || v > .251 {
+		t.Errorf("linear cubic-bezier: %v", v)
+	}
+	if _, ok := EaseByName("cubic-bezier(0, 0, 1)"); ok {
+		t.Errorf("cubic-bezier with 3 args should not parse")
+	}
+	if _, ok := EaseByName("bouncy"); ok {
+		t.Errorf("unknown easing should not parse")
+	}
+}
+
+func TestInterpolateValue(t *testing.T) {
+	if v, ok := InterpolateValue(Color{0, 0, 0, 255}, Color{200, 100, 50, 255}, .5); !ok || v != (Color{100, 50, 25, 255}) {
+		t.Errorf("color: %v", v)
+	}
+	if v, ok := InterpolateValue(HSLA{H: 350, S: 1, L: .5, A: 1}, HSLA{H: 10, S: 1, L: .5, A: 1}, .5); !ok || v.(HSLA).H != 0 {
+		t.Errorf("hsla hue wrap: %v", v)
+	}
+	v, ok := InterpolateValue(units.NewValue(10, units.Px), units.NewValue(20, units.Px), .5)
+	if uv := v.(units.Value); !ok || uv.Val != 15 || uv.Un != units.Px {
+		t.Errorf("units value: %v", v)
+	}
+	if v, ok := InterpolateValue(Vec2D{0, 10}, Vec2D{10, 20}, .5); !ok || v != (Vec2D{5, 15}) {
+		t.Errorf("vec2d: %v", v)
+	}
+	if v, ok := InterpolateValue(1, 4, .5); !ok || v != 3 {
+		t.Errorf("int: %v", v)
+	}
+	if _, ok := InterpolateValue("a", "b", .5); ok {
+		t.Errorf("string should not interpolate")
+	}
+	if _, ok := InterpolateValue(float32(1), 2.0, .5); ok {
+		t.Errorf("mismatched types should not interpolate")
+	}
+}
+
+func TestParseTransitions(t *testing.T) {
+	trs := ParseTransitions("background-color 0.2s ease-out, width 100ms cubic-bezier(.1, .7, 1, .1) 50ms, none")
+	if len(trs) != 2 {
+		t.Fatalf("transitions: %v", trs)
+	}
+	if tr := trs[0]; tr.Prop != "background-color" || tr.Dur != 200*time.Millisecond || tr.Delay != 0 || tr.Ease(.5) != EaseFuncs["ease-out"](.5) {
+		t.Errorf("background-color: %v", tr)
+	}
+	if tr := trs[1]; tr.Prop != "width" || tr.Dur != 100*time.Millisecond || tr.Delay != 50*time.Millisecond {
+		t.Errorf("width: %v", tr)
+	}
+	if p, end := trs[1].Progress(25 * time.Millisecond); p != 0 || end {
+		t.Errorf("progress in delay: %v %v", p, end)
+	}
+	if p, end := trs[1].Progress(150 * time.Millisecond); p != 1 || !end {
+		t.Errorf("progress at end: %v %v", p, end)
+	}
+}
+
+func TestAnimator(t *testing.T) {
+	clk := &ManualClock{T: time.Unix(0, 0)}
+	an := &Animator{Clock: clk}
+	var prog []float32
+	done := 0
+	an.Start(&Anim{Name: "a", Dur: time.Second, Func: func(p float32) { prog = append(prog, p) }, Done: func() { done++ }})
+	an.Start(&Anim{Name: "b", Dur: time.Second, Delay: time.Second, Func: func(p float32) {
+		if p == 0 {
+			t.Errorf("anim b called in delay")
+		}
+	}})
+	if an.NRunning() != 2 || !an.IsRunning(nil, "a") {
+		t.Errorf("running: %v", an.NRunning())
+	}
+	an.Step()
+	clk.Advance(500 * time.Millisecond)
+	an.Step()
+	clk.Advance(time.Second)
+	if !an.Step() {
+		t.Errorf("anim b should still be running")
+	}
+	want := []float32{0, .5, 1}
+	if len(prog) != len(want) || prog[1] != want[1] || prog[2] != want[2] || done != 1 {
+		t.Errorf("progress: %v done: %v", prog, done)
+	}
+	if an.IsRunning(nil, "a") || !an.IsRunning(nil, "b") {
+		t.Errorf("anim a should have ended")
+	}
+
+	prog = nil
+	an.Start(&Anim{Name: "b", Dur: time.Second, Func: func(p float32) { prog = append(prog, p) }})
+	if an.NRunning() != 1 {
+		t.Errorf("anim b not replaced: %v", an.NRunning())
+	}
+	clk.Advance(2 * time.Second)
+	if an.Step() || len(prog) != 1 || prog[0] != 1 {
+		t.Errorf("replaced anim b: %v", prog)
+	}
+
+	steps := 0
+	an.Start(&Anim{Name: "forever", Dur: AnimForever, Func: func(p float32) {
+		if p != 0 {
+			t.Errorf("forever anim progress: %v", p)
+		}
+		steps++
+	}})
+	clk.Advance(time.Hour)
+	if !an.Step() || !an.Step() || steps != 2 {
+		t.Errorf("forever anim should keep running: %v", steps)
+	}
+	if !an.Stop(nil, "forever") || an.Step() {
+		t.Errorf("forever anim not stopped")
+	}
+}
+
+func TestStyleTransitions(t *testing.T) {
+	now := time.Unix(0, 0)
+	sty := Style{}
+	sty.Transitions = ParseTransitions("background-color 1s linear")
+	sty.Font.BgColor.SetColor(Color{0, 0, 0, 255})
+	st := make(StyleTransitions)
+	if st.Apply(&sty, now) != 0 {
+		t.Errorf("first value should not transition")
+	}
+	sty.Font.BgColor.SetColor(Color{200, 200, 200, 255})
+	if end := st.Apply(&sty, now); end != time.Second || sty.Font.BgColor.Color != (Color{0, 0, 0, 255}) {
+		t.Errorf("transition start: %v %v", end, sty.Font.BgColor.Color)
+	}
+	sty.Font.BgColor.SetColor(Color{200, 200, 200, 255})
+	if end := st.Apply(&sty, now.Add(time.Second/2)); end != 0 || sty.Font.BgColor.Color != (Color{100, 100, 100, 255}) {
+		t.Errorf("transition half way: %v %v", end, sty.Font.BgColor.Color)
+	}
+	sty.Font.BgColor.SetColor(Color{0, 0, 0, 255}) // back from the current value
+	st.Apply(&sty, now.Add(time.Second/2))
+	if sty.Font.BgColor.Color != (Color{100, 100, 100, 255}) {
+		t.Errorf("reversed transition: %v", sty.Font.BgColor.Color)
+	}
+	sty.Font.BgColor.SetColor(Color{0, 0, 0, 255})
+	st.Apply(&sty, now.Add(2*time.Second))
+	if sty.Font.BgColor.Color != (Color{0, 0, 0, 255}) {
+		t.Errorf("reversed transition end: %v", sty.Font.BgColor.Color)
+	}
+}
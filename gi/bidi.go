@@ -0,0 +1,852 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"sort"
+
+	"github.com/chewxy/math32"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// This file implements the Unicode Bidirectional Algorithm (UAX #9):
+// http://www.unicode.org/reports/tr9/ -- using the character classes from
+// golang.org/x/text/unicode/bidi, which does not (yet) provide the
+// algorithm itself.  BidiLevels resolves the embedding levels of the runes
+// of a paragraph, in logical order, and BidiLineLevels and BidiVisualOrder
+// then get the visual order of each line of it, after line wrapping.
+
+// bidiMaxDepth is the maximum explicit embedding level
+const bidiMaxDepth = 125
+
+// bidiMaxBrackets is the maximum depth of the bracket pair stack (BD16)
+const bidiMaxBrackets = 63
+
+// bidiMirrors maps brackets and other paired punctuation to their mirrored
+// counterparts, used for matching bracket pairs (rule N0) and for rendering
+// mirrored glyphs in right-to-left text (rule L4) -- covers the common cases
+var bidiMirrors = map[rune]rune{}
+
+func init() {
+	prs := []rune("()<>[]{}«»‹›≤≥⁅⁆⁽⁾₍₎⟨⟩⟦⟧〈〉《》「」『』【】〔〕〖〗〘〙〚〛（）＜＞［］｛｝｟｠｢｣")
+	for i := 0; i+1 < len(prs); i += 2 {
+		bidiMirrors[prs[i]] = prs[i+1]
+		bidiMirrors[prs[i+1]] = prs[i]
+	}
+}
+
+// BidiMirror returns the mirrored version of given rune for display in
+// right-to-left text, e.g., ) for ( -- returns the rune itself if it is not
+// mirrored
+func BidiMirror(r rune) rune {
+	if m, ok := bidiMirrors[r]; ok {
+		return m
+	}
+	return r
+}
+
+// bidiClass returns the bidi class of given rune
+func bidiClass(r rune) bidi.Class {
+	p, _ := bidi.LookupRune(r)
+	return p.Class()
+}
+
+// bidiIsIsolate returns true for the isolate initiator classes
+func bidiIsIsolate(c bidi.Class) bool {
+	return c == bidi.LRI || c == bidi.RLI || c == bidi.FSI
+}
+
+// bidiIsNI returns true for the neutral and isolate classes, resolved by
+// rules N1 and N2
+func bidiIsNI(c bidi.Class) bool {
+	switch c {
+	case bidi.B, bidi.S, bidi.WS, bidi.ON, bidi.LRI, bidi.RLI, bidi.FSI, bidi.PDI:
+		return true
+	}
+	return false
+}
+
+// bidiStrong returns the strong direction (L or R) that given class counts
+// as for the neutral rules -- numbers count as R -- and ON otherwise
+func bidiStrong(c bidi.Class) bidi.Class {
+	switch c {
+	case bidi.L:
+		return bidi.L
+	case bidi.R, bidi.AL, bidi.EN, bidi.AN:
+		return bidi.R
+	}
+	return bidi.ON
+}
+
+// bidiLevelDir returns the direction (L or R) of given embedding level
+func bidiLevelDir(lev int8) bidi.Class {
+	if lev&1 == 1 {
+		return bidi.R
+	}
+	return bidi.L
+}
+
+// bidiNextLevel returns the next odd (rtl) or even embedding level above
+// given level
+func bidiNextLevel(lev int8, rtl bool) int8 {
+	if rtl {
+		return (lev + 1) | 1
+	}
+	return (lev + 2) &^ 1
+}
+
+// BidiNeeded returns true if text with given paragraph level (see
+// BidiLevels) needs the bidi algorithm to be laid out -- otherwise it is
+// all left-to-right, and no right-to-left characters or explicit
+// right-to-left formatting characters are present
+func BidiNeeded(txt []rune, paraLevel int) bool {
+	if paraLevel > 0 {
+		return true
+	}
+	for _, r := range txt {
+		if r < 0x0590 { // no rtl chars before hebrew
+			continue
+		}
+		switch bidiClass(r) {
+		case bidi.R, bidi.AL, bidi.RLE, bidi.RLO, bidi.RLI, bidi.FSI:
+			return true
+		}
+	}
+	return false
+}
+
+// BidiLevels returns the embedding levels of the runes of one paragraph of
+// text, according to the Unicode Bidirectional Algorithm (rules P2 through
+// I2), where odd levels are right-to-left and even levels left-to-right.
+// paraLevel is the paragraph embedding level: 0 for left-to-right, 1 for
+// right-to-left, or -1 to determine it from the first strong character of
+// the text (rules P2, P3) -- the level used is returned.  The levels are in
+// logical order -- see BidiLineLevels and BidiVisualOrder for each line.
+func BidiLevels(txt []rune, paraLevel int) ([]int8, int) {
+	sz := len(txt)
+	orig := make([]bidi.Class, sz)
+	for i, r := range txt {
+		orig[i] = bidiClass(r)
+	}
+	if paraLevel < 0 {
+		paraLevel = bidiFirstStrong(orig, 0, sz)
+	}
+	levels := make([]int8, sz)
+	if sz == 0 {
+		return levels, paraLevel
+	}
+	cls := make([]bidi.Class, sz)
+	copy(cls, orig)
+	match := bidiMatchIsolates(orig)
+	removed := bidiExplicit(orig, cls, levels, match, paraLevel)
+	for _, seq := range bidiSequences(orig, levels, removed, match) {
+		bidiResolve(txt, orig, cls, levels, removed, seq, paraLevel)
+	}
+	for i := range levels { // removed chars just go along with the previous one
+		if removed[i] {
+			if i > 0 {
+				levels[i] = levels[i-1]
+			} else {
+				levels[i] = int8(paraLevel)
+			}
+		}
+	}
+	return levels, paraLevel
+}
+
+// bidiFirstStrong returns 1 if the first strong character in given range,
+// skipping over isolates, is right-to-left, and 0 otherwise (rules P2, P3)
+func bidiFirstStrong(cls []bidi.Class, st, ed int) int {
+	depth := 0
+	for i := st; i < ed; i++ {
+		switch cls[i] {
+		case bidi.L:
+			if depth == 0 {
+				return 0
+			}
+		case bidi.R, bidi.AL:
+			if depth == 0 {
+				return 1
+			}
+		case bidi.LRI, bidi.RLI, bidi.FSI:
+			depth++
+		case bidi.PDI:
+			if depth > 0 {
+				depth--
+			}
+		case bidi.B:
+			return 0
+		}
+	}
+	return 0
+}
+
+// bidiMatchIsolates returns for each isolate initiator the index of its
+// matching PDI, and for each matched PDI that of its initiator -- -1 if
+// none (BD9)
+func bidiMatchIsolates(cls []bidi.Class) []int {
+	match := make([]int, len(cls))
+	var stack []int
+	for i, c := range cls {
+		match[i] = -1
+		switch {
+		case bidiIsIsolate(c):
+			stack = append(stack, i)
+		case c == bidi.PDI:
+			if n := len(stack); n > 0 {
+				match[stack[n-1]] = i
+				match[i] = stack[n-1]
+				stack = stack[:n-1]
+			}
+		case c == bidi.B:
+			stack = stack[:0]
+		}
+	}
+	return match
+}
+
+// bidiStatus is an entry of the directional status stack
+type bidiStatus struct {
+	level    int8
+	override bidi.Class // L or R for overrides, ON for none
+	isolate  bool
+}
+
+// bidiExplicit applies the explicit levels and directions (rules X1-X8),
+// setting levels and any override classes into cls, and returns the chars
+// that are removed by rule X9
+func bidiExplicit(orig, cls []bidi.Class, levels []int8, match []int, paraLevel int) []bool {
+	sz := len(orig)
+	removed := make([]bool, sz)
+	stack := []bidiStatus{{level: int8(paraLevel), override: bidi.ON}}
+	overIso, overEmb, validIso := 0, 0, 0
+	for i, c := range orig {
+		top := stack[len(stack)-1]
+		switch c {
+		case bidi.RLE, bidi.LRE, bidi.RLO, bidi.LRO:
+			removed[i] = true
+			levels[i] = top.level
+			nl := bidiNextLevel(top.level, c == bidi.RLE || c == bidi.RLO)
+			if nl <= bidiMaxDepth && overIso == 0 && overEmb == 0 {
+				ov := bidi.ON
+				switch c {
+				case bidi.RLO:
+					ov = bidi.R
+				case bidi.LRO:
+					ov = bidi.L
+				}
+				stack = append(stack, bidiStatus{level: nl, override: ov})
+			} else if overIso == 0 {
+				overEmb++
+			}
+		case bidi.RLI, bidi.LRI, bidi.FSI:
+			levels[i] = top.level
+			if top.override != bidi.ON {
+				cls[i] = top.override
+			}
+			rtl := c == bidi.RLI
+			if c == bidi.FSI {
+				ed := match[i]
+				if ed < 0 {
+					ed = sz
+				}
+				rtl = bidiFirstStrong(orig, i+1, ed) == 1
+			}
+			nl := bidiNextLevel(top.level, rtl)
+			if nl <= bidiMaxDepth && overIso == 0 && overEmb == 0 {
+				validIso++
+				stack = append(stack, bidiStatus{level: nl, override: bidi.ON, isolate: true})
+			} else {
+				overIso++
+			}
+		case bidi.PDI:
+			if overIso > 0 {
+				overIso--
+			} else if validIso > 0 {
+				overEmb = 0
+				for !stack[len(stack)-1].isolate {
+					stack = stack[:len(stack)-1]
+				}
+				stack = stack[:len(stack)-1]
+				validIso--
+			}
+			top = stack[len(stack)-1]
+			levels[i] = top.level
+			if top.override != bidi.ON {
+				cls[i] = top.override
+			}
+		case bidi.PDF:
+			removed[i] = true
+			levels[i] = top.level
+			if overIso > 0 {
+			} else if overEmb > 0 {
+				overEmb--
+			} else if !top.isolate && len(stack) >= 2 {
+				stack = stack[:len(stack)-1]
+			}
+		case bidi.B:
+			levels[i] = int8(paraLevel)
+		case bidi.BN:
+			removed[i] = true
+			levels[i] = top.level
+		default:
+			levels[i] = top.level
+			if top.override != bidi.ON {
+				cls[i] = top.override
+			}
+		}
+	}
+	return removed
+}
+
+// bidiSequences returns the isolating run sequences (BD13, X10) as lists
+// of char indexes: level runs of the chars not removed, where a run ending
+// in an isolate initiator continues with the run starting with its
+// matching PDI
+func bidiSequences(orig []bidi.Class, levels []int8, removed []bool, match []int) [][]int {
+	var runs [][]int
+	var cur []int
+	for i := range levels {
+		if removed[i] {
+			continue
+		}
+		if len(cur) > 0 && levels[cur[len(cur)-1]] != levels[i] {
+			runs = append(runs, cur)
+			cur = nil
+		}
+		cur = append(cur, i)
+	}
+	if len(cur) > 0 {
+		runs = append(runs, cur)
+	}
+	runOf := make(map[int]int, len(runs)) // run index by first char
+	for ri, r := range runs {
+		runOf[r[0]] = ri
+	}
+	used := make([]bool, len(runs))
+	var seqs [][]int
+	for ri, r := range runs {
+		if used[ri] {
+			continue
+		}
+		seq := append([]int{}, r...)
+		for {
+			last := seq[len(seq)-1]
+			if !bidiIsIsolate(orig[last]) || match[last] < 0 {
+				break
+			}
+			nri, ok := runOf[match[last]]
+			if !ok || used[nri] {
+				break
+			}
+			used[nri] = true
+			seq = append(seq, runs[nri]...)
+		}
+		seqs = append(seqs, seq)
+	}
+	return seqs
+}
+
+// bidiResolve resolves the weak types (W1-W7), paired brackets (N0),
+// neutral types (N1, N2) and implicit levels (I1, I2) of one isolating run
+// sequence
+func bidiResolve(txt []rune, orig, cls []bidi.Class, levels []int8, removed []bool, seq []int, paraLevel int) {
+	sz := len(levels)
+	ns := len(seq)
+	lev := levels[seq[0]]
+	prev := int8(paraLevel)
+	for i := seq[0] - 1; i >= 0; i-- {
+		if !removed[i] {
+			prev = levels[i]
+			break
+		}
+	}
+	next := int8(paraLevel)
+	if last := seq[ns-1]; !bidiIsIsolate(orig[last]) {
+		for i := last + 1; i < sz; i++ {
+			if !removed[i] {
+				next = levels[i]
+				break
+			}
+		}
+	}
+	if prev < lev {
+		prev = lev
+	}
+	if next < lev {
+		next = lev
+	}
+	sos := bidiLevelDir(prev)
+	eos := bidiLevelDir(next)
+	emb := bidiLevelDir(lev)
+
+	// W1: nonspacing marks take the type of the previous char
+	for k, i := range seq {
+		if cls[i] != bidi.NSM {
+			continue
+		}
+		switch {
+		case k == 0:
+			cls[i] = sos
+		case bidiIsIsolate(cls[seq[k-1]]) || cls[seq[k-1]] == bidi.PDI:
+			cls[i] = bidi.ON
+		default:
+			cls[i] = cls[seq[k-1]]
+		}
+	}
+	// W2: european numbers after arabic letters are arabic numbers
+	strong := sos
+	for _, i := range seq {
+		switch cls[i] {
+		case bidi.L, bidi.R, bidi.AL:
+			strong = cls[i]
+		case bidi.EN:
+			if strong == bidi.AL {
+				cls[i] = bidi.AN
+			}
+		}
+	}
+	// W3
+	for _, i := range seq {
+		if cls[i] == bidi.AL {
+			cls[i] = bidi.R
+		}
+	}
+	// W4: single separators between numbers
+	for k := 1; k < ns-1; k++ {
+		i := seq[k]
+		p := cls[seq[k-1]]
+		n := cls[seq[k+1]]
+		switch cls[i] {
+		case bidi.ES:
+			if p == bidi.EN && n == bidi.EN {
+				cls[i] = bidi.EN
+			}
+		case bidi.CS:
+			if p == n && (p == bidi.EN || p == bidi.AN) {
+				cls[i] = p
+			}
+		}
+	}
+	// W5: terminators adjacent to european numbers
+	for k := 0; k < ns; k++ {
+		if cls[seq[k]] != bidi.ET {
+			continue
+		}
+		e := k
+		for e < ns && cls[seq[e]] == bidi.ET {
+			e++
+		}
+		if (k > 0 && cls[seq[k-1]] == bidi.EN) || (e < ns && cls[seq[e]] == bidi.EN) {
+			for j := k; j < e; j++ {
+				cls[seq[j]] = bidi.EN
+			}
+		}
+		k = e
+	}
+	// W6, W7
+	strong = sos
+	for _, i := range seq {
+		switch cls[i] {
+		case bidi.ES, bidi.ET, bidi.CS:
+			cls[i] = bidi.ON
+		case bidi.L, bidi.R:
+			strong = cls[i]
+		case bidi.EN:
+			if strong == bidi.L {
+				cls[i] = bidi.L
+			}
+		}
+	}
+
+	bidiBrackets(txt, orig, cls, seq, sos, emb)
+
+	// N1, N2: neutrals take the direction of the surrounding strong types if
+	// the same, and otherwise the embedding direction
+	for k := 0; k < ns; k++ {
+		if !bidiIsNI(cls[seq[k]]) {
+			continue
+		}
+		e := k
+		for e < ns && bidiIsNI(cls[seq[e]]) {
+			e++
+		}
+		before := sos
+		if k > 0 {
+			before = bidiStrong(cls[seq[k-1]])
+		}
+		after := eos
+		if e < ns {
+			after = bidiStrong(cls[seq[e]])
+		}
+		dir := emb
+		if before == after {
+			dir = before
+		}
+		for j := k; j < e; j++ {
+			cls[seq[j]] = dir
+		}
+		k = e
+	}
+
+	// I1, I2
+	for _, i := range seq {
+		if levels[i]&1 == 0 {
+			switch cls[i] {
+			case bidi.R:
+				levels[i]++
+			case bidi.AN, bidi.EN:
+				levels[i] += 2
+			}
+		} else {
+			switch cls[i] {
+			case bidi.L, bidi.EN, bidi.AN:
+				levels[i]++
+			}
+		}
+	}
+}
+
+// bidiBrackets resolves paired brackets (BD16, N0) within an isolating run
+// sequence, with given sos and embedding directions
+func bidiBrackets(txt []rune, orig, cls []bidi.Class, seq []int, sos, emb bidi.Class) {
+	type open struct {
+		close rune // the closing bracket that matches
+		k     int
+	}
+	type pair struct{ o, c int }
+	var stack []open
+	var pairs []pair
+	for k, i := range seq {
+		if cls[i] != bidi.ON {
+			continue
+		}
+		r := txt[i]
+		m, ok := bidiMirrors[r]
+		if !ok {
+			continue
+		}
+		p, _ := bidi.LookupRune(r)
+		if !p.IsBracket() {
+			continue
+		}
+		if p.IsOpeningBracket() {
+			if len(stack) == bidiMaxBrackets {
+				break
+			}
+			stack = append(stack, open{m, k})
+			continue
+		}
+		for s := len(stack) - 1; s >= 0; s-- {
+			if stack[s].close == r {
+				pairs = append(pairs, pair{stack[s].k, k})
+				stack = stack[:s]
+				break
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].o < pairs[j].o })
+
+	for _, pr := range pairs {
+		found := bidi.ON
+		for k := pr.o + 1; k < pr.c; k++ {
+			d := bidiStrong(cls[seq[k]])
+			if d == bidi.ON {
+				continue
+			}
+			found = d
+			if d == emb {
+				break
+			}
+		}
+		if found == bidi.ON {
+			continue
+		}
+		dir := emb
+		if found != emb { // only the opposite direction inside: check context
+			prior := sos
+			for k := pr.o - 1; k >= 0; k-- {
+				if d := bidiStrong(cls[seq[k]]); d != bidi.ON {
+					prior = d
+					break
+				}
+			}
+			if prior == found {
+				dir = found
+			}
+		}
+		for _, b := range []int{pr.o, pr.c} {
+			cls[seq[b]] = dir
+			for k := b + 1; k < len(seq) && orig[seq[k]] == bidi.NSM; k++ {
+				cls[seq[k]] = dir
+			}
+		}
+	}
+}
+
+// BidiLineLevels applies rule L1 to the levels of one line of text, as
+// returned by BidiLevels for its paragraph: segment and paragraph
+// separators, and any whitespace before them or at the end of the line, are
+// reset to the paragraph level -- modifies the levels in place
+func BidiLineLevels(txt []rune, levels []int8, paraLevel int) {
+	trail := true
+	for i := len(txt) - 1; i >= 0; i-- {
+		switch bidiClass(txt[i]) {
+		case bidi.S, bidi.B:
+			levels[i] = int8(paraLevel)
+			trail = true
+		case bidi.WS, bidi.LRI, bidi.RLI, bidi.FSI, bidi.PDI, bidi.BN,
+			bidi.LRE, bidi.RLE, bidi.LRO, bidi.RLO, bidi.PDF:
+			if trail {
+				levels[i] = int8(paraLevel)
+			}
+		default:
+			trail = false
+		}
+	}
+}
+
+// BidiVisualOrder returns the logical indexes of the runes of one line of
+// text with given levels (see BidiLineLevels) in visual order, from left to
+// right, by reversing each sequence of runes at or above each odd level,
+// from the highest level on down (rule L2)
+func BidiVisualOrder(levels []int8) []int {
+	sz := len(levels)
+	order := make([]int, sz)
+	hi, lo := int8(0), int8(bidiMaxDepth+2)
+	for i, l := range levels {
+		order[i] = i
+		if l > hi {
+			hi = l
+		}
+		if l < lo {
+			lo = l
+		}
+	}
+	if lo&1 == 0 {
+		lo++
+	}
+	for lev := hi; lev >= lo; lev-- {
+		for i := 0; i < sz; {
+			if levels[order[i]] < lev {
+				i++
+				continue
+			}
+			j := i
+			for j < sz && levels[order[j]] >= lev {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			i = j
+		}
+	}
+	return order
+}
+
+//////////////////////////////////////////////////////////////////////////////////
+//  Bidi layout of SpanRender, TextRender
+
+// SetBidi sets the bidi Level of each rune in all spans, applying the
+// unicode bidi algorithm to each paragraph according to the UnicodeBidi and
+// Direction styles, and sets the Dir of the spans of right-to-left paragraphs
+// to RLTB (else LRTB).  Paragraphs start at spans marked with DecoParaStart,
+// or if linePara is true, each span is its own paragraph, as for
+// preformatted text where each line is a span.  Called by the SetString,
+// SetRunes and SetHTML methods.
+func (tr *TextRender) SetBidi(txtSty *TextStyle, linePara bool) {
+	para := txtSty.BidiParaLevel()
+	ovr := txtSty.UnicodeBidi == BidiBidiOverride
+	nsp := len(tr.Spans)
+	for st := 0; st < nsp; {
+		ed := st + 1
+		if !linePara {
+			for ed < nsp && !tr.Spans[ed].IsNewPara() {
+				ed++
+			}
+		}
+		bidiSetPara(tr.Spans[st:ed], para, ovr)
+		st = ed
+	}
+}
+
+// bidiSetPara sets the bidi levels of the runes of the spans of one
+// paragraph, and the Dir of the spans
+func bidiSetPara(sps []SpanRender, para int, ovr bool) {
+	var txt []rune
+	if len(sps) == 1 {
+		txt = sps[0].Text
+	} else {
+		for i := range sps {
+			txt = append(txt, sps[i].Text...)
+		}
+	}
+	var levels []int8
+	switch {
+	case ovr: // all at the paragraph level
+		if para < 0 {
+			para = 0
+		}
+		if para > 0 {
+			levels = make([]int8, len(txt))
+			for i := range levels {
+				levels[i] = int8(para)
+			}
+		}
+	case BidiNeeded(txt, para):
+		levels, para = BidiLevels(txt, para)
+	default:
+		para = 0
+	}
+	dir := LRTB
+	if para == 1 {
+		dir = RLTB
+	}
+	li := 0
+	for i := range sps {
+		sr := &sps[i]
+		sr.Dir = dir
+		for ri := range sr.Render {
+			if levels != nil {
+				sr.Render[ri].Level = levels[li]
+			} else {
+				sr.Render[ri].Level = 0
+			}
+			li++
+		}
+	}
+}
+
+// HasBidi returns true if the span has any runes with a non-zero bidi Level,
+// i.e., it needs to be reordered for display
+func (sr *SpanRender) HasBidi() bool {
+	for i := range sr.Render {
+		if sr.Render[i].Level != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ReorderBidiLR reorders the rune positions of a line of text, as set in
+// logical order by SetRunePosLogicalLR, into visual left-to-right order
+// according to the bidi Levels of the runes (rules L1, L2 of the bidi
+// algorithm) -- the Text and Render elements remain in logical order.
+func (sr *SpanRender) ReorderBidiLR() {
+	sz := len(sr.Render)
+	if sz == 0 || len(sr.Text) != sz {
+		return
+	}
+	adv := make([]float32, sz) // advance of each rune including spacing, kerning
+	levels := make([]int8, sz)
+	for i := range sr.Render {
+		nx := sr.LastPos.X
+		if i < sz-1 {
+			nx = sr.Render[i+1].RelPos.X
+		}
+		adv[i] = nx - sr.Render[i].RelPos.X
+		levels[i] = sr.Render[i].Level
+	}
+	para := 0
+	if sr.Dir == RLTB {
+		para = 1
+	}
+	BidiLineLevels(sr.Text, levels, para)
+	x := sr.Render[0].RelPos.X
+	for _, i := range BidiVisualOrder(levels) {
+		rr := &sr.Render[i]
+		rr.Level = levels[i]
+		rr.RelPos.X = x
+		x += adv[i]
+	}
+	sr.LastPos.X = x
+}
+
+// CursorMoveVisual returns the cursor position (logical rune index, 0 to
+// len(Text)) that is visually next to given cursor position in given
+// direction: dir > 0 = right, dir < 0 = left -- in bidi text, this can move
+// forward or backward in the logical text.  Returns -1 if there is no
+// further position in that direction within the span.
+func (sr *SpanRender) CursorMoveVisual(pos, dir int) int {
+	sz := len(sr.Render)
+	cx := sr.RuneRelPos(pos).X
+	best := -1
+	var bd float32
+	for p := 0; p <= sz; p++ {
+		d := sr.RuneRelPos(p).X - cx
+		if dir < 0 {
+			d = -d
+		}
+		if d <= 0 {
+			continue
+		}
+		// among equal positions, the closest in logical order
+		if best < 0 || d < bd || (d == bd && math32.Abs(float32(p-pos)) < math32.Abs(float32(best-pos))) {
+			best = p
+			bd = d
+		}
+	}
+	return best
+}
+
+// CursorAtX returns the cursor position (logical rune index, 0 to
+// len(Text)) that is closest to given x position, in the same coordinates
+// as RuneRelPos
+func (sr *SpanRender) CursorAtX(x float32) int {
+	sz := len(sr.Render)
+	best := 0
+	bd := float32(-1)
+	for p := 0; p <= sz; p++ {
+		d := math32.Abs(sr.RuneRelPos(p).X - x)
+		if bd < 0 || d < bd {
+			best = p
+			bd = d
+		}
+	}
+	return best
+}
+
+// VisualRangesLR returns the horizontal ranges (start, end X, in the same
+// coordinates as RuneRelPos) covered by the runes in the logical range [st,
+// ed) -- for bidi text, this can be multiple separate ranges, from left to
+// right -- e.g., for rendering the selection
+func (sr *SpanRender) VisualRangesLR(st, ed int) [][2]float32 {
+	sz := len(sr.Render)
+	if st < 0 {
+		st = 0
+	}
+	if ed > sz {
+		ed = sz
+	}
+	if st >= ed {
+		return nil
+	}
+	vis := make([]int, sz)
+	for i := range vis {
+		vis[i] = i
+	}
+	sort.Slice(vis, func(i, j int) bool {
+		return sr.Render[vis[i]].RelPos.X < sr.Render[vis[j]].RelPos.X
+	})
+	var rngs [][2]float32
+	in := false
+	for _, i := range vis {
+		if i < st || i >= ed {
+			in = false
+			continue
+		}
+		rr := &sr.Render[i]
+		sx := sr.RelPos.X + rr.RelPos.X
+		ex := sx + rr.Size.X
+		if in {
+			rngs[len(rngs)-1][1] = ex
+		} else {
+			rngs = append(rngs, [2]float32{sx, ex})
+			in = true
+		}
+	}
+	return rngs
+}
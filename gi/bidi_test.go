@@ -0,0 +1,74 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"testing"
+)
+
+// bidiVisual returns the visual order of given single-line paragraph
+func bidiVisual(txt string, paraLevel int) (string, int) {
+	rs := []rune(txt)
+	levels, para := BidiLevels(rs, paraLevel)
+	BidiLineLevels(rs, levels, para)
+	vis := make([]rune, 0, len(rs))
+	for _, i := range BidiVisualOrder(levels) {
+		r := rs[i]
+		if levels[i]&1 == 1 {
+			r = BidiMirror(r)
+		}
+		vis = append(vis, r)
+	}
+	return string(vis), para
+}
+
+func TestBidiVisual(t *testing.T) {
+	tests := []struct {
+		txt       string
+		paraLevel int
+		vis       string
+		para      int
+	}{
+		{"hello world", -1, "hello world", 0},
+		{"שלום", -1, "םולש", 1},
+		{"abc אבג def", 0, "abc גבא def", 0},
+		{"abc אבג def", 1, "def גבא abc", 1},
+		{"אבג abc דהו", -1, "והד abc גבא", 1},
+		{"אבג 123 דהו", -1, "והד 123 גבא", 1},
+		{"abc 1.5 אבג", 0, "abc 1.5 גבא", 0},
+		{"אבג (abc)", -1, "(abc) גבא", 1},
+		{"abc (אבג)", 0, "abc (גבא)", 0},
+		{"אבג ", -1, " גבא", 1},
+		{"abc אבג ", 0, "abc גבא ", 0},
+		{"\u202eabc\u202c def", 0, "\u202e\u202ccba def", 0}, // RLO ... PDF
+		{"", -1, "", 0},
+	}
+	for _, ts := range tests {
+		vis, para := bidiVisual(ts.txt, ts.paraLevel)
+		if vis != ts.vis || para != ts.para {
+			t.Errorf("bidi visual of %q, para: %v = %q, %v, want: %q, %v", ts.txt, ts.paraLevel, vis, para, ts.vis, ts.para)
+		}
+	}
+}
+
+func TestBidiLevels(t *testing.T) {
+	levels, para := BidiLevels([]rune("ab אב 12"), 0)
+	want := []int8{0, 0, 0, 1, 1, 1, 2, 2} // numbers count as R for neutrals
+	if para != 0 {
+		t.Errorf("BidiLevels para level: %v, want: 0", para)
+	}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Errorf("BidiLevels: %v, want: %v", levels, want)
+			break
+		}
+	}
+	if BidiNeeded([]rune("plain ascii"), 0) {
+		t.Errorf("BidiNeeded: ascii text should not need bidi")
+	}
+	if !BidiNeeded([]rune("mixed שלום"), 0) {
+		t.Errorf("BidiNeeded: hebrew text should need bidi")
+	}
+}
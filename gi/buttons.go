@@ -219,6 +219,7 @@ func (bb *ButtonBase) SetButtonState(state ButtonStates) bool {
 	}
 	bb.State = state
 	bb.Sty = bb.StateStyles[state]
+	bb.TransitionStyle()
 	if prev != bb.State {
 		bb.SetFullReRenderIconLabel() // needs full rerender to update text, icon
 		return true
@@ -248,6 +249,7 @@ func (bb *ButtonBase) UpdateButtonStyle() bool {
 		}
 	}
 	bb.Sty = bb.StateStyles[bb.State]
+	bb.TransitionStyle()
 	bb.This().(ButtonWidget).ConfigPartsIfNeeded()
 	if prev != bb.State {
 		bb.SetFullReRenderIconLabel() // needs full rerender
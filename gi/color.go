@@ -526,6 +526,12 @@ func (c *Color) ParseHex(x string) error {
 	return nil
 }
 
+// HexString returns the color as an opaque #rrggbb hex string, as used in
+// svg -- the alpha must be written separately, e.g., as fill-opacity
+func (c *Color) HexString() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
 // Lighter returns a color that is lighter by the given percent, e.g., 50 = 50%
 // lighter, relative to maximum possible lightness -- converts to HSL,
 // multiplies the L factor, and then converts back to RGBA
@@ -628,6 +634,17 @@ func (c *Color) Blend(pct float32, clr color.Color) Color {
 	return ColorModel.Convert(f32).(Color)
 }
 
+// Interpolate returns the color t (0..1) of the way from this color to
+// given color -- interpolation is done on the alpha-premultiplied values, so
+// transparent colors do not darken the result
+func (c Color) Interpolate(to Color, t float32) Color {
+	return Color{interpUint8(c.R, to.R, t), interpUint8(c.G, to.G, t), interpUint8(c.B, to.B, t), interpUint8(c.A, to.A, t)}
+}
+
+func interpUint8(a, b uint8, t float32) uint8 {
+	return uint8(InRange32(float32(a)+(float32(b)-float32(a))*t+0.5, 0, 255))
+}
+
 /////////////////////////////////////////////////////////////////////////////
 //  float32 RGBA color
 
@@ -682,6 +699,24 @@ func (c HSLA) RGBA() (r, g, b, a uint32) {
 	return
 }
 
+// Interpolate returns the color t (0..1) of the way from this color to
+// given color, going the shortest way around the hue circle
+func (c HSLA) Interpolate(to HSLA, t float32) HSLA {
+	dh := to.H - c.H
+	if dh > 180 {
+		dh -= 360
+	} else if dh < -180 {
+		dh += 360
+	}
+	h := c.H + dh*t
+	if h < 0 {
+		h += 360
+	} else if h >= 360 {
+		h -= 360
+	}
+	return HSLA{h, c.S + (to.S-c.S)*t, c.L + (to.L-c.L)*t, c.A + (to.A-c.A)*t}
+}
+
 // HSLtoRGBf32 converts HSL values to RGB float32 0..1 values (non alpha-premultiplied) -- based on https://stackoverflow.com/questions/2353211/hsl-to-rgb-color-conversion, https://www.w3.org/TR/css-color-3/ and github.com/lucasb-eyer/go-colorful
 func HSLtoRGBf32(h, s, l float32) (r, g, b float32) {
 	if s == 0 {
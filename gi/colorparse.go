@@ -510,6 +510,72 @@ func (cs *ColorSpec) ReadGradAttr(attr xml.Attr) (err error) {
 	return nil
 }
 
+// MarshalXML writes the gradient as a linearGradient or radialGradient XML
+// element, depending on Source, with a stop element for each gradient stop
+// -- any attributes in the start element (e.g., id) are included.  A solid
+// color is written as given start element with a color attribute.
+func (cs *ColorSpec) MarshalXML(enc *xml.Encoder, se xml.StartElement) error {
+	if cs.Source == SolidColor || cs.Gradient == nil {
+		se.Attr = append(se.Attr, NewXMLAttr("color", cs.Color.HexString()))
+		if cs.Color.A != 255 {
+			se.Attr = append(se.Attr, NewXMLAttr("opacity", FmtFloat32(float32(cs.Color.A)/255)))
+		}
+		if err := enc.EncodeToken(se); err != nil {
+			return err
+		}
+		return enc.EncodeToken(se.End())
+	}
+	gr := cs.Gradient
+	pts := gr.Points
+	if cs.Source == RadialGradient {
+		se.Name.Local = "radialGradient"
+		se.Attr = append(se.Attr, NewXMLAttr("cx", FmtFloat64(pts[0])), NewXMLAttr("cy", FmtFloat64(pts[1])),
+			NewXMLAttr("fx", FmtFloat64(pts[2])), NewXMLAttr("fy", FmtFloat64(pts[3])), NewXMLAttr("r", FmtFloat64(pts[4])))
+	} else {
+		se.Name.Local = "linearGradient"
+		se.Attr = append(se.Attr, NewXMLAttr("x1", FmtFloat64(pts[0])), NewXMLAttr("y1", FmtFloat64(pts[1])),
+			NewXMLAttr("x2", FmtFloat64(pts[2])), NewXMLAttr("y2", FmtFloat64(pts[3])))
+	}
+	if gr.Units == rasterx.UserSpaceOnUse {
+		se.Attr = append(se.Attr, NewXMLAttr("gradientUnits", "userSpaceOnUse"))
+	}
+	switch gr.Spread {
+	case rasterx.ReflectSpread:
+		se.Attr = append(se.Attr, NewXMLAttr("spreadMethod", "reflect"))
+	case rasterx.RepeatSpread:
+		se.Attr = append(se.Attr, NewXMLAttr("spreadMethod", "repeat"))
+	}
+	if gr.Matrix != rasterx.Identity {
+		m := gr.Matrix
+		se.Attr = append(se.Attr, NewXMLAttr("gradientTransform", fmt.Sprintf("matrix(%v,%v,%v,%v,%v,%v)",
+			FmtFloat64(m.A), FmtFloat64(m.B), FmtFloat64(m.C), FmtFloat64(m.D), FmtFloat64(m.E), FmtFloat64(m.F))))
+	}
+	if err := enc.EncodeToken(se); err != nil {
+		return err
+	}
+	for _, stop := range gr.Stops {
+		st := xml.StartElement{Name: xml.Name{Local: "stop"}}
+		st.Attr = append(st.Attr, NewXMLAttr("offset", FmtFloat64(stop.Offset)))
+		op := stop.Opacity
+		if stop.StopColor != nil {
+			var clr Color
+			clr.SetColor(stop.StopColor)
+			st.Attr = append(st.Attr, NewXMLAttr("stop-color", clr.HexString()))
+			op *= float64(clr.A) / 255 // svg colors are opaque
+		}
+		if op != 1 {
+			st.Attr = append(st.Attr, NewXMLAttr("stop-opacity", FmtFloat64(op)))
+		}
+		if err := enc.EncodeToken(st); err != nil {
+			return err
+		}
+		if err := enc.EncodeToken(st.End()); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(se.End())
+}
+
 // FixGradientStops applies the CSS rules to regularize the gradient stops: https://www.w3.org/TR/css3-images/#color-stop-syntax
 func FixGradientStops(grad *rasterx.Gradient) {
 	sz := len(grad.Stops)
@@ -61,6 +61,13 @@ Controlling the layout involves the following style properties:
       extra space is allocated (only if there aren't any infinitely stretchy
       elements), e.g., right / left / center or justified.
 
+	* LayoutGrid: columns sets the number of columns for items placed
+      automatically in order, or row / col place an item explicitly, with
+      row-span / col-span.  grid-template-columns / grid-template-rows set
+      track sizes: auto, lengths, fractions of remaining space (e.g., 1fr),
+      minmax(min, max) and repeat(n, sizes), and grid-template-areas names
+      areas of cells that items occupy with grid-area, as in CSS.
+
 	* SetFixedWidth / Height method can be used to set all size params to the
       same value, causing that item to be definitively sized.  This is
       convenient for sizing the Space node which adds a fixed amount of space
@@ -0,0 +1,936 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/json"
+	"image"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/dnd"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//    DockLayout
+
+// DockSides are the places where a panel can be docked relative to a dock
+// area: stacked as a tab within it, or splitting it on one side
+type DockSides int32
+
+const (
+	// DockCenter stacks the panel as another tab in the dock area
+	DockCenter DockSides = iota
+
+	// DockLeft splits the dock area, with the panel on the left
+	DockLeft
+
+	// DockRight splits the dock area, with the panel on the right
+	DockRight
+
+	// DockTop splits the dock area, with the panel on top
+	DockTop
+
+	// DockBottom splits the dock area, with the panel on the bottom
+	DockBottom
+
+	DockSidesN
+)
+
+//go:generate stringer -type=DockSides
+
+var KiT_DockSides = kit.Enums.AddEnumAltLower(DockSidesN, false, nil, "Dock")
+
+// DockEdgeFrac is the proportion of a dock area along each edge where
+// dropping a panel splits the area on that side, instead of stacking the
+// panel as a tab
+var DockEdgeFrac = float32(0.25)
+
+// DockSideAt returns the side of a dock area with given bounding box that
+// the position is on -- DockCenter unless within DockEdgeFrac of an edge,
+// in which case it is the closest edge
+func DockSideAt(bb image.Rectangle, pos image.Point) DockSides {
+	sz := bb.Size()
+	if sz.X <= 0 || sz.Y <= 0 {
+		return DockCenter
+	}
+	fx := float32(pos.X-bb.Min.X) / float32(sz.X)
+	fy := float32(pos.Y-bb.Min.Y) / float32(sz.Y)
+	dists := [...]float32{DockLeft: fx, DockRight: 1 - fx, DockTop: fy, DockBottom: 1 - fy}
+	side, min := DockCenter, DockEdgeFrac
+	for sd := DockLeft; sd < DockSidesN; sd++ {
+		if dists[sd] < min {
+			side, min = sd, dists[sd]
+		}
+	}
+	return side
+}
+
+// DockLayout is the serializable arrangement of the panels in a DockView --
+// a tree of splits, which have Kids split along Dim, and dock areas, which
+// have no Kids and hold Panels stacked as tabs.  Panels are identified by
+// name.
+type DockLayout struct {
+	Dim         Dims2D        `desc:"dimension along which a split divides its space"`
+	Splits      []float32     `desc:"proportion of space allocated to each of the Kids of a split -- see SplitView.Splits"`
+	SavedSplits []float32     `desc:"saved splits for restoring collapsed Kids -- see SplitView.SavedSplits"`
+	Kids        []*DockLayout `desc:"elements of a split -- empty for a dock area"`
+	Panels      []string      `desc:"names of the panels in a dock area, in tab order"`
+	CurPanel    int           `desc:"index of the selected panel in a dock area"`
+}
+
+// IsArea returns true if this is a dock area holding panels, and false if it
+// is a split
+func (dl *DockLayout) IsArea() bool {
+	return len(dl.Kids) == 0
+}
+
+// AllPanels returns the names of all the panels in the layout, in order
+func (dl *DockLayout) AllPanels() []string {
+	if dl.IsArea() {
+		return append([]string{}, dl.Panels...)
+	}
+	var pns []string
+	for _, k := range dl.Kids {
+		pns = append(pns, k.AllPanels()...)
+	}
+	return pns
+}
+
+// FindPanel returns the dock area holding given panel, along with its parent
+// split and its index therein (nil, -1 if the area is the root) -- area is
+// nil if not found
+func (dl *DockLayout) FindPanel(name string) (area, par *DockLayout, idx int) {
+	if dl.IsArea() {
+		for _, pn := range dl.Panels {
+			if pn == name {
+				return dl, nil, -1
+			}
+		}
+		return nil, nil, -1
+	}
+	for i, k := range dl.Kids {
+		area, par, idx = k.FindPanel(name)
+		if area != nil {
+			if par == nil {
+				par, idx = dl, i
+			}
+			return
+		}
+	}
+	return nil, nil, -1
+}
+
+// FirstArea returns the first dock area in the layout, along with its parent
+// split and its index therein (nil, -1 if the area is the root)
+func (dl *DockLayout) FirstArea() (area, par *DockLayout, idx int) {
+	area, idx = dl, -1
+	for !area.IsArea() {
+		par, area, idx = area, area.Kids[0], 0
+	}
+	return
+}
+
+// EvenSplits makes sure a split has Splits for each of its Kids, dividing
+// the space evenly if not
+func (dl *DockLayout) EvenSplits() {
+	sz := len(dl.Kids)
+	if len(dl.Splits) == sz {
+		return
+	}
+	dl.Splits = make([]float32, sz)
+	for i := range dl.Splits {
+		dl.Splits[i] = 1.0 / float32(sz)
+	}
+}
+
+// InsertPanel inserts given panel relative to the dock area holding the
+// target panel: stacked as the selected tab for DockCenter, or otherwise
+// splitting off half of the space of that area on the given side -- the
+// first area is used if target is not found
+func (dl *DockLayout) InsertPanel(name, target string, side DockSides) {
+	area, par, idx := dl.FindPanel(target)
+	if area == nil {
+		area, par, idx = dl.FirstArea()
+	}
+	if side == DockCenter || len(area.Panels) == 0 {
+		area.Panels = append(area.Panels, name)
+		area.CurPanel = len(area.Panels) - 1
+		return
+	}
+	dim := X
+	if side == DockTop || side == DockBottom {
+		dim = Y
+	}
+	before := side == DockLeft || side == DockTop
+	na := &DockLayout{Panels: []string{name}}
+	if par != nil && par.Dim == dim { // add to existing split
+		par.EvenSplits()
+		sp := par.Splits[idx] / 2
+		par.Splits[idx] = sp
+		if !before {
+			idx++
+		}
+		par.Kids = append(par.Kids, nil)
+		copy(par.Kids[idx+1:], par.Kids[idx:])
+		par.Kids[idx] = na
+		par.Splits = append(par.Splits, 0)
+		copy(par.Splits[idx+1:], par.Splits[idx:])
+		par.Splits[idx] = sp
+		par.SavedSplits = nil
+		return
+	}
+	oa := &DockLayout{}
+	*oa = *area
+	kids := []*DockLayout{oa, na}
+	if before {
+		kids = []*DockLayout{na, oa}
+	}
+	*area = DockLayout{Dim: dim, Kids: kids, Splits: []float32{.5, .5}}
+}
+
+// RemovePanel removes given panel from its dock area, and prunes the area if
+// it is now empty -- returns false if not found
+func (dl *DockLayout) RemovePanel(name string) bool {
+	area, _, _ := dl.FindPanel(name)
+	if area == nil {
+		return false
+	}
+	for i, pn := range area.Panels {
+		if pn != name {
+			continue
+		}
+		area.Panels = append(area.Panels[:i], area.Panels[i+1:]...)
+		if area.CurPanel > i || area.CurPanel >= len(area.Panels) {
+			area.CurPanel = ints.MaxInt(area.CurPanel-1, 0)
+		}
+		break
+	}
+	dl.Prune()
+	return true
+}
+
+// Prune removes empty dock areas from splits, and replaces splits with only
+// one remaining element by that element -- an empty root area remains
+func (dl *DockLayout) Prune() {
+	if dl.IsArea() {
+		return
+	}
+	for i := len(dl.Kids) - 1; i >= 0; i-- {
+		k := dl.Kids[i]
+		k.Prune()
+		if !k.IsArea() || len(k.Panels) > 0 {
+			continue
+		}
+		dl.Kids = append(dl.Kids[:i], dl.Kids[i+1:]...)
+		if i < len(dl.Splits) {
+			dl.Splits = append(dl.Splits[:i], dl.Splits[i+1:]...)
+		}
+		dl.SavedSplits = nil
+	}
+	switch len(dl.Kids) {
+	case 0:
+		*dl = DockLayout{}
+	case 1:
+		*dl = *dl.Kids[0]
+	}
+}
+
+// MovePanel moves given panel relative to the dock area holding the target
+// panel -- see InsertPanel -- returns false if panel is not found, or if it
+// is moved relative to itself when it is alone in its area
+func (dl *DockLayout) MovePanel(name, target string, side DockSides) bool {
+	if name == target {
+		area, _, _ := dl.FindPanel(name)
+		if area == nil || len(area.Panels) == 1 {
+			return false
+		}
+		for _, pn := range area.Panels {
+			if pn != name {
+				target = pn
+				break
+			}
+		}
+	}
+	if !dl.RemovePanel(name) {
+		return false
+	}
+	dl.InsertPanel(name, target, side)
+	return true
+}
+
+// DockFloat records a panel that is floating in its own window
+type DockFloat struct {
+	Panel string `desc:"name of the floating panel"`
+	Area  string `desc:"name of a panel in the dock area the panel was floated from -- it is docked there again"`
+}
+
+// DockState is the full serializable state of a DockView: the layout of the
+// docked panels and the floating panels -- the geometry of the floating
+// windows is saved in WinGeomPrefs
+type DockState struct {
+	Root   DockLayout  `desc:"layout of the docked panels"`
+	Floats []DockFloat `desc:"panels floating in their own windows"`
+}
+
+// FloatIndex returns the index of given panel in Floats, -1 if not floating
+func (ds *DockState) FloatIndex(name string) int {
+	for i, fl := range ds.Floats {
+		if fl.Panel == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// CopyFrom copies the entire state from other state, without sharing any
+// elements
+func (ds *DockState) CopyFrom(fm *DockState) {
+	b, _ := json.Marshal(fm)
+	*ds = DockState{}
+	json.Unmarshal(b, ds)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//    DockPrefs
+
+// DockPrefs records the dock state of each application, by name, and saves
+// it persistently
+var DockPrefs = DockViewPrefs{}
+
+// DockViewPrefs records the DockState by application name
+type DockViewPrefs map[string]*DockState
+
+// DockPrefsFileName is the base name of the preferences file in GoGi prefs directory
+var DockPrefsFileName = "dock_prefs"
+
+// DockPrefsMu is read-write mutex that protects updating of DockPrefs
+var DockPrefsMu sync.RWMutex
+
+// Open Dock preferences from GoGi standard prefs directory
+// called under mutex or at start
+func (dp *DockViewPrefs) Open() error {
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, DockPrefsFileName+".json")
+	b, err := ioutil.ReadFile(pnm)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(b, dp)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// Save Dock Preferences to GoGi standard prefs directory
+// assumed to be under mutex
+func (dp *DockViewPrefs) Save() error {
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, DockPrefsFileName+".json")
+	b, err := json.MarshalIndent(dp, "", "  ")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = ioutil.WriteFile(pnm, b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// RecordState records a copy of given dock state for given application, and
+// saves the prefs -- re-opens the prefs first, to get any updates from other
+// applications
+func (dp *DockViewPrefs) RecordState(app string, st *DockState) {
+	DockPrefsMu.Lock()
+	defer DockPrefsMu.Unlock()
+
+	if *dp == nil {
+		*dp = make(DockViewPrefs, 10)
+	}
+	dp.Open()
+	cst := &DockState{}
+	cst.CopyFrom(st)
+	(*dp)[app] = cst
+	dp.Save()
+}
+
+// State returns a copy of the saved dock state for given application --
+// false if none
+func (dp *DockViewPrefs) State(app string) (*DockState, bool) {
+	DockPrefsMu.Lock()
+	defer DockPrefsMu.Unlock()
+
+	if len(*dp) == 0 {
+		dp.Open()
+	}
+	st, ok := (*dp)[app]
+	if !ok || st == nil {
+		return nil, false
+	}
+	cst := &DockState{}
+	cst.CopyFrom(st)
+	return cst, true
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//    DockView
+
+// DockView is a docking container for panels (any Node2D), which are shown
+// as tabs in dock areas (DockArea, a TabView) within nested SplitView's.
+// Panels can be dragged by their tabs onto another dock area, to be stacked
+// as a tab (center) or to split that area (edges), and floated into their
+// own Window (by dragging them out of the dock, or from the tab context
+// menu) and re-docked from there.  The arrangement is kept in State, which
+// is saved in DockPrefs under the AppName by SaveState, and restored by
+// OpenState.  Typical use: AddPanel for each panel, then OpenState, then
+// ConfigDock.
+type DockView struct {
+	Layout
+	AppName           string             `desc:"name under which the state is saved in DockPrefs -- the application name if empty"`
+	State             DockState          `desc:"arrangement of the panels -- UpdateState updates it from the current splits and tabs"`
+	Panels            map[string]Node2D  `json:"-" xml:"-" desc:"all the panels in the dock, by name"`
+	FloatWins         map[string]*Window `json:"-" xml:"-" desc:"windows of the floating panels, by panel name"`
+	DirtyPanels       map[string]bool    `json:"-" xml:"-" desc:"panels with unsaved changes, which are marked on their tabs -- see SetPanelDirty"`
+	ConfirmDirtyClose bool               `desc:"if true, closing the tab of a dirty panel asks the user to confirm first -- see TabView.ConfirmDirtyClose"`
+	DockSig           ki.Signal          `json:"-" xml:"-" desc:"signal for dock changes -- see DockSignals for the types -- data is the panel name"`
+}
+
+var KiT_DockView = kit.Types.AddType(&DockView{}, DockViewProps)
+
+var DockViewProps = ki.Props{
+	"max-width":  -1.0,
+	"max-height": -1.0,
+	"margin":     0,
+	"padding":    0,
+}
+
+// DockSignals are signals that the DockView can send
+type DockSignals int64
+
+const (
+	// DockPanelMoved indicates a panel was moved by the user -- data is the panel name
+	DockPanelMoved DockSignals = iota
+
+	// DockPanelFloated indicates a panel was floated into its own window
+	DockPanelFloated
+
+	// DockPanelDocked indicates a floating panel was docked again
+	DockPanelDocked
+
+	// DockPanelClosed indicates a panel was closed by its tab, which
+	// destroys it, and it was removed from the dock
+	DockPanelClosed
+
+	// DockPanelCloseReq indicates a request to close a panel by its tab --
+	// data is the *TabCloseData, whose Veto can be set to keep the panel
+	DockPanelCloseReq
+
+	DockSignalsN
+)
+
+//go:generate stringer -type=DockSignals
+
+// DockPanelMimeType is the mime type for the name of a DockView panel that
+// is being dragged
+const DockPanelMimeType = "application/x-gogi-dock-panel"
+
+// DockPanelFromMimeData returns the name of the DockView panel in given mime
+// data -- false if none
+func DockPanelFromMimeData(md mimedata.Mimes) (string, bool) {
+	for _, d := range md {
+		if d.Type == DockPanelMimeType {
+			return string(d.Data), true
+		}
+	}
+	return "", false
+}
+
+// StateName returns the name under which the state is saved in DockPrefs
+func (dv *DockView) StateName() string {
+	if dv.AppName != "" {
+		return dv.AppName
+	}
+	return oswin.TheApp.Name()
+}
+
+// AddPanel adds given widget as a panel in the dock, with given name (which
+// is also set as the name of the widget) -- it is added as a tab in the
+// first dock area unless the State already has a place for it -- reconfigures
+// the dock if it has already been configured
+func (dv *DockView) AddPanel(widg Node2D, name string) {
+	if dv.Panels == nil {
+		dv.Panels = make(map[string]Node2D)
+	}
+	widg.SetName(name)
+	dv.Panels[name] = widg
+	dv.UpdateState()
+	if area, _, _ := dv.State.Root.FindPanel(name); area == nil && dv.State.FloatIndex(name) < 0 {
+		dv.State.Root.InsertPanel(name, "", DockCenter)
+	}
+	if dv.HasChildren() {
+		dv.ConfigDock()
+	}
+}
+
+// PanelNames returns the sorted names of all the panels in the dock
+func (dv *DockView) PanelNames() []string {
+	pns := make([]string, 0, len(dv.Panels))
+	for pn := range dv.Panels {
+		pns = append(pns, pn)
+	}
+	sort.Strings(pns)
+	return pns
+}
+
+// OpenState restores the state saved in DockPrefs, if there is one --
+// panels that are not in the dock are dropped, and any that are not in the
+// saved state are added to the first dock area -- call after AddPanel and
+// before ConfigDock -- returns false if no state was saved
+func (dv *DockView) OpenState() bool {
+	st, ok := DockPrefs.State(dv.StateName())
+	if !ok {
+		return false
+	}
+	for _, pn := range st.Root.AllPanels() {
+		if _, has := dv.Panels[pn]; !has {
+			st.Root.RemovePanel(pn)
+		}
+	}
+	for i := len(st.Floats) - 1; i >= 0; i-- {
+		if _, has := dv.Panels[st.Floats[i].Panel]; !has {
+			st.Floats = append(st.Floats[:i], st.Floats[i+1:]...)
+		}
+	}
+	for _, pn := range dv.PanelNames() {
+		if area, _, _ := st.Root.FindPanel(pn); area == nil && st.FloatIndex(pn) < 0 {
+			st.Root.InsertPanel(pn, "", DockCenter)
+		}
+	}
+	dv.State = *st
+	return true
+}
+
+// UpdateState updates the State from the current splits and tabs
+func (dv *DockView) UpdateState() {
+	if !dv.HasChildren() {
+		return
+	}
+	dv.UpdateLayout(dv.KnownChild(0), &dv.State.Root)
+}
+
+// UpdateLayout updates given layout from the corresponding widget
+func (dv *DockView) UpdateLayout(k ki.Ki, dl *DockLayout) {
+	if da, ok := k.Embed(KiT_DockArea).(*DockArea); ok {
+		nt := da.NTabs()
+		dl.Panels = make([]string, nt)
+		for i := 0; i < nt; i++ {
+			dl.Panels[i] = da.TabName(i)
+		}
+		if _, idx, ok := da.CurTab(); ok {
+			dl.CurPanel = idx
+		}
+		return
+	}
+	sv, ok := k.Embed(KiT_SplitView).(*SplitView)
+	if !ok {
+		return
+	}
+	dl.Splits = append([]float32{}, sv.Splits...)
+	dl.SavedSplits = nil
+	if sv.SavedSplits != nil {
+		dl.SavedSplits = append([]float32{}, sv.SavedSplits...)
+	}
+	for i, kid := range sv.Kids {
+		if i < len(dl.Kids) {
+			dv.UpdateLayout(kid, dl.Kids[i])
+		}
+	}
+}
+
+// SaveState updates the State from the widgets and saves it in DockPrefs
+func (dv *DockView) SaveState() {
+	dv.UpdateState()
+	DockPrefs.RecordState(dv.StateName(), &dv.State)
+}
+
+// ConfigDock configures the dock areas and splits to show the panels
+// according to the State, and opens windows for any floating panels that do
+// not yet have one
+func (dv *DockView) ConfigDock() {
+	updt := dv.UpdateStart()
+	dv.SetFullReRender()
+	dv.Lay = LayoutVert
+	for pn, widg := range dv.Panels {
+		if _, fl := dv.FloatWins[pn]; fl {
+			continue
+		}
+		if par := widg.Parent(); par != nil {
+			par.DeleteChild(widg, false)
+		}
+	}
+	dv.DeleteChildren(true)
+	dv.ConfigLayout(dv.This(), &dv.State.Root)
+	for _, fl := range dv.State.Floats {
+		if _, has := dv.FloatWins[fl.Panel]; !has {
+			dv.OpenFloatWin(fl.Panel)
+		}
+	}
+	dv.UpdateEnd(updt)
+}
+
+// ConfigLayout adds the widgets for given layout to given parent
+func (dv *DockView) ConfigLayout(par ki.Ki, dl *DockLayout) {
+	if dl.IsArea() {
+		da := par.AddNewChild(KiT_DockArea, "area").(*DockArea)
+		for _, pn := range dl.Panels {
+			if widg, ok := dv.Panels[pn]; ok {
+				da.AddTab(widg, pn)
+			}
+		}
+		da.ConfirmDirtyClose = dv.ConfirmDirtyClose
+		for i, pn := range dl.Panels {
+			if dv.DirtyPanels[pn] {
+				da.SetTabDirty(i, true)
+			}
+		}
+		if dl.CurPanel > 0 && dl.CurPanel < da.NTabs() {
+			da.SelectTabIndex(dl.CurPanel)
+		}
+		da.TabViewSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dvv := recv.Embed(KiT_DockView).(*DockView)
+			switch TabViewSignals(sig) {
+			case TabDeleted:
+				dvv.PanelClosed(data.(string))
+			case TabCloseReq:
+				dvv.DockSig.Emit(dvv.This(), int64(DockPanelCloseReq), data)
+			}
+		})
+		return
+	}
+	sv := par.AddNewChild(KiT_SplitView, "split").(*SplitView)
+	sv.Dim = dl.Dim
+	for _, k := range dl.Kids {
+		dv.ConfigLayout(sv.This(), k)
+	}
+	sv.SetSplits(dl.Splits...)
+	if dl.SavedSplits != nil {
+		sv.SavedSplits = append([]float32{}, dl.SavedSplits...)
+	}
+}
+
+// MovePanel moves given panel relative to the dock area holding the target
+// panel, stacked as a tab or splitting that area on given side -- returns
+// false if not moved -- see DockLayout.InsertPanel
+func (dv *DockView) MovePanel(name, target string, side DockSides) bool {
+	dv.UpdateState()
+	if !dv.State.Root.MovePanel(name, target, side) {
+		return false
+	}
+	dv.ConfigDock()
+	return true
+}
+
+// MovePanelAction moves given panel, saves the state and emits the
+// DockPanelMoved signal -- this is what is called when a panel tab is
+// dropped on a dock area
+func (dv *DockView) MovePanelAction(name, target string, side DockSides) {
+	if dv.MovePanel(name, target, side) {
+		dv.SaveState()
+		dv.DockSig.Emit(dv.This(), int64(DockPanelMoved), name)
+	}
+}
+
+// FloatPanel moves given docked panel into its own window -- returns false
+// if it is not docked
+func (dv *DockView) FloatPanel(name string) bool {
+	dv.UpdateState()
+	area, _, _ := dv.State.Root.FindPanel(name)
+	if area == nil {
+		return false
+	}
+	fl := DockFloat{Panel: name}
+	for _, pn := range area.Panels {
+		if pn != name {
+			fl.Area = pn
+			break
+		}
+	}
+	dv.State.Root.RemovePanel(name)
+	dv.State.Floats = append(dv.State.Floats, fl)
+	dv.ConfigDock()
+	return true
+}
+
+// FloatPanelAction floats given panel, saves the state and emits the
+// DockPanelFloated signal
+func (dv *DockView) FloatPanelAction(name string) {
+	if dv.FloatPanel(name) {
+		dv.SaveState()
+		dv.DockSig.Emit(dv.This(), int64(DockPanelFloated), name)
+	}
+}
+
+// DockFloat docks given floating panel again as a tab in the area it was
+// floated from, and closes its window -- returns false if it is not floating
+func (dv *DockView) DockFloat(name string) bool {
+	fi := dv.State.FloatIndex(name)
+	if fi < 0 {
+		return false
+	}
+	dv.UpdateState()
+	fl := dv.State.Floats[fi]
+	dv.State.Floats = append(dv.State.Floats[:fi], dv.State.Floats[fi+1:]...)
+	if win, ok := dv.FloatWins[name]; ok {
+		delete(dv.FloatWins, name)
+		if widg, ok := dv.Panels[name]; ok {
+			if par := widg.Parent(); par != nil {
+				par.DeleteChild(widg, false)
+			}
+		}
+		win.Close()
+	}
+	dv.State.Root.InsertPanel(name, fl.Area, DockCenter)
+	dv.ConfigDock()
+	return true
+}
+
+// DockFloatAction docks given floating panel, saves the state and emits the
+// DockPanelDocked signal -- this is called by the Dock action in the
+// floating window, and when that window is closed
+func (dv *DockView) DockFloatAction(name string) {
+	if dv.DockFloat(name) {
+		dv.SaveState()
+		dv.DockSig.Emit(dv.This(), int64(DockPanelDocked), name)
+	}
+}
+
+// FloatWinName returns the name of the window for given floating panel --
+// its geometry is saved in WinGeomPrefs under this name
+func (dv *DockView) FloatWinName(name string) string {
+	return dv.StateName() + "-dock-" + name
+}
+
+// OpenFloatWin opens a window for given floating panel, with a toolbar
+// action to dock it again -- closing the window also docks it
+func (dv *DockView) OpenFloatWin(name string) *Window {
+	widg, ok := dv.Panels[name]
+	if !ok {
+		return nil
+	}
+	win := NewWindow2D(dv.FloatWinName(name), name, 640, 480, true)
+	if win == nil {
+		return nil
+	}
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	tb := mfr.AddNewChild(KiT_ToolBar, "toolbar").(*ToolBar)
+	tb.Lay = LayoutHoriz
+	tb.AddAction(ActOpts{Label: "Dock", Tooltip: "dock this panel in the window it was floated from"}, dv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			dvv := recv.Embed(KiT_DockView).(*DockView)
+			dvv.DockFloatAction(name)
+		})
+	if par := widg.Parent(); par != nil {
+		par.DeleteChild(widg, false)
+	}
+	widg.AsNode2D().ClearInvisible()
+	mfr.AddChild(widg)
+	if dv.FloatWins == nil {
+		dv.FloatWins = make(map[string]*Window)
+	}
+	dv.FloatWins[name] = win
+	win.OSWin.SetCloseCleanFunc(func(w oswin.Window) {
+		if dv.FloatWins[name] == win {
+			dv.DockFloatAction(name)
+		}
+	})
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+	return win
+}
+
+// SetPanelDirty sets whether given panel has unsaved changes, which is
+// marked on its tab -- see TabView.SetTabDirty
+func (dv *DockView) SetPanelDirty(name string, dirty bool) {
+	if dv.DirtyPanels == nil {
+		dv.DirtyPanels = make(map[string]bool)
+	}
+	if dirty {
+		dv.DirtyPanels[name] = true
+	} else {
+		delete(dv.DirtyPanels, name)
+	}
+	widg, ok := dv.Panels[name]
+	if !ok {
+		return
+	}
+	par, ok := widg.ParentByType(KiT_DockArea, true)
+	if !ok {
+		return
+	}
+	da := par.Embed(KiT_DockArea).(*DockArea)
+	if _, idx, ok := da.TabByName(name); ok {
+		da.SetTabDirty(idx, dirty)
+	}
+}
+
+// PanelClosed removes given panel from the dock after its tab has been
+// closed, which destroys it, and emits the DockPanelClosed signal
+func (dv *DockView) PanelClosed(name string) {
+	if _, ok := dv.Panels[name]; !ok {
+		return
+	}
+	delete(dv.Panels, name)
+	delete(dv.DirtyPanels, name)
+	dv.UpdateState()
+	dv.State.Root.Prune()
+	dv.ConfigDock()
+	dv.SaveState()
+	dv.DockSig.Emit(dv.This(), int64(DockPanelClosed), name)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//    DockArea
+
+// DockArea is a TabView holding the panels of one area of a DockView -- the
+// tabs can be dragged onto other dock areas, and dragging a tab out of the
+// dock or the tab context menu floats the panel into its own window
+type DockArea struct {
+	TabView
+}
+
+var KiT_DockArea = kit.Types.AddType(&DockArea{}, TabViewProps)
+
+// DockView returns the DockView that this area is in
+func (da *DockArea) DockView() *DockView {
+	dv, ok := da.ParentByType(KiT_DockView, true)
+	if !ok {
+		return nil
+	}
+	return dv.Embed(KiT_DockView).(*DockView)
+}
+
+// DragNDropStart starts dragging the panel whose tab is at the event position
+func (da *DockArea) DragNDropStart(de *dnd.Event) {
+	idx := da.TabAtPos(de.Where)
+	if idx < 0 {
+		return
+	}
+	de.SetProcessed()
+	da.StartTabDrag(idx, mimedata.Mimes{&mimedata.Data{Type: DockPanelMimeType, Data: []byte(da.TabName(idx))}})
+}
+
+// DragNDropTarget handles a drop of a panel onto this area: dropped on the
+// tabs, it is reordered among them or stacked as a tab, and otherwise it is
+// moved to the side given by the drop position -- see DockSideAt
+func (da *DockArea) DragNDropTarget(de *dnd.Event) {
+	name, ok := DockPanelFromMimeData(de.Data)
+	if !ok {
+		return
+	}
+	dv := da.DockView()
+	if dv == nil {
+		return
+	}
+	de.Target = da.This()
+	de.Mod = dnd.DropMove
+	de.SetProcessed()
+	target := ""
+	if da.NTabs() > 0 {
+		target = da.TabName(0)
+	}
+	if !de.Where.In(da.Tabs().WinBBox) {
+		dv.MovePanelAction(name, target, DockSideAt(da.WinBBox, de.Where))
+		return
+	}
+	if _, idx, ok := da.TabByName(name); ok {
+		da.MoveTabAction(idx, da.TabInsertIndex(de.Where))
+		dv.SaveState()
+		return
+	}
+	dv.MovePanelAction(name, target, DockCenter)
+}
+
+// DragNDropIgnored satisfies the DragNDropIgnorer interface: a panel dragged
+// from this area that was not dropped on anything that accepted it is floated
+func (da *DockArea) DragNDropIgnored(de *dnd.Event) {
+	name, ok := DockPanelFromMimeData(de.Data)
+	if !ok {
+		return
+	}
+	if dv := da.DockView(); dv != nil {
+		dv.FloatPanelAction(name)
+	}
+}
+
+// MakeContextMenu makes the menu for the panel whose tab the context menu
+// was opened on, followed by the list of all tabs
+func (da *DockArea) MakeContextMenu(m *Menu) {
+	dv := da.DockView()
+	if dv != nil && da.CtxtTab >= 0 && da.CtxtTab < da.NTabs() {
+		name := da.TabName(da.CtxtTab)
+		m.AddAction(ActOpts{Label: "Float " + name, Data: name}, dv.This(),
+			func(recv, send ki.Ki, sig int64, data interface{}) {
+				dvv := recv.Embed(KiT_DockView).(*DockView)
+				dvv.FloatPanelAction(data.(string))
+			})
+		m.AddAction(ActOpts{Label: "Close " + name, Data: name}, da.This(),
+			func(recv, send ki.Ki, sig int64, data interface{}) {
+				dav := recv.Embed(KiT_DockArea).(*DockArea)
+				if _, idx, ok := dav.TabByName(data.(string)); ok {
+					dav.CloseTabIndexAction(idx)
+				}
+			})
+		m.AddSeparator("sep-tabs")
+	}
+	da.MakeTabsMenu(m)
+}
+
+// DockAreaEvents connects the drag-n-drop events for the panels
+func (da *DockArea) DockAreaEvents() {
+	// HiPri so panels being dropped are not handled by the panel contents
+	da.ConnectEvent(oswin.DNDEvent, HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.Event)
+		dav := recv.Embed(KiT_DockArea).(*DockArea)
+		switch de.Action {
+		case dnd.Start:
+			dav.DragNDropStart(de)
+		case dnd.DropOnTarget:
+			dav.DragNDropTarget(de)
+		}
+	})
+	da.ConnectEvent(oswin.DNDFocusEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.FocusEvent)
+		dav := recv.Embed(KiT_DockArea).(*DockArea)
+		win := dav.Viewport.Win
+		if _, ok := DockPanelFromMimeData(win.DNDData); !ok {
+			return
+		}
+		switch de.Action {
+		case dnd.Enter:
+			win.DNDSetCursor(dnd.DropMove)
+		case dnd.Exit:
+			win.DNDNotCursor()
+		}
+	})
+}
+
+func (da *DockArea) ConnectEvents2D() {
+	da.Layout.ConnectEvents2D()
+	da.TabViewMouseEvents()
+	da.DockAreaEvents()
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestDockSideAt(t *testing.T) {
+	bb := image.Rect(100, 100, 200, 300)
+	sides := []struct {
+		pos  image.Point
+		side DockSides
+	}{
+		{image.Point{150, 200}, DockCenter},
+		{image.Point{110, 200}, DockLeft},
+		{image.Point{190, 200}, DockRight},
+		{image.Point{150, 110}, DockTop},
+		{image.Point{150, 290}, DockBottom},
+		{image.Point{105, 120}, DockLeft}, // closest edge
+		{image.Point{120, 105}, DockTop},
+	}
+	for _, s := range sides {
+		if sd := DockSideAt(bb, s.pos); sd != s.side {
+			t.Errorf("DockSideAt %v: %v, want: %v", s.pos, sd, s.side)
+		}
+	}
+}
+
+func TestDockLayout(t *testing.T) {
+	dl := &DockLayout{}
+	dl.InsertPanel("a", "", DockCenter)
+	dl.InsertPanel("b", "a", DockCenter)
+	dl.InsertPanel("c", "a", DockRight)
+	dl.InsertPanel("d", "c", DockLeft)
+	dl.InsertPanel("e", "d", DockBottom)
+	want := &DockLayout{Dim: X, Splits: []float32{.5, .25, .25}, Kids: []*DockLayout{
+		{Panels: []string{"a", "b"}, CurPanel: 1},
+		{Dim: Y, Splits: []float32{.5, .5}, Kids: []*DockLayout{
+			{Panels: []string{"d"}},
+			{Panels: []string{"e"}},
+		}},
+		{Panels: []string{"c"}},
+	}}
+	if !reflect.DeepEqual(dl, want) {
+		t.Errorf("InsertPanel: %v", dl.AllPanels())
+	}
+	if area, par, idx := dl.FindPanel("e"); area != dl.Kids[1].Kids[1] || par != dl.Kids[1] || idx != 1 {
+		t.Errorf("FindPanel: %v %v", area, idx)
+	}
+
+	if dl.MovePanel("c", "c", DockTop) || !dl.MovePanel("e", "a", DockCenter) {
+		t.Errorf("MovePanel")
+	}
+	want = &DockLayout{Dim: X, Splits: []float32{.5, .25, .25}, Kids: []*DockLayout{
+		{Panels: []string{"a", "b", "e"}, CurPanel: 2},
+		{Panels: []string{"d"}},
+		{Panels: []string{"c"}},
+	}}
+	if !reflect.DeepEqual(dl, want) {
+		t.Errorf("MovePanel pruned: %v", dl)
+	}
+
+	dl.MovePanel("b", "b", DockBottom)
+	if area, par, _ := dl.FindPanel("b"); area == nil || par == nil || par.Dim != Y || par.Kids[0].Panels[1] != "e" {
+		t.Errorf("MovePanel relative to itself: %v", par)
+	}
+	for _, pn := range []string{"a", "b", "c", "d"} {
+		dl.RemovePanel(pn)
+	}
+	if !reflect.DeepEqual(dl, &DockLayout{Panels: []string{"e"}}) {
+		t.Errorf("RemovePanel: %v", dl)
+	}
+}
+
+func TestDockStateCopy(t *testing.T) {
+	ds := &DockState{Floats: []DockFloat{{Panel: "f", Area: "a"}}}
+	ds.Root.InsertPanel("a", "", DockCenter)
+	ds.Root.InsertPanel("b", "a", DockTop)
+	cs := &DockState{}
+	cs.CopyFrom(ds)
+	if !reflect.DeepEqual(cs, ds) || cs.FloatIndex("f") != 0 || cs.FloatIndex("a") != -1 {
+		t.Errorf("CopyFrom: %v", cs)
+	}
+	cs.Root.Kids[0].Panels[0] = "x"
+	if ds.Root.Kids[0].Panels[0] != "b" {
+		t.Errorf("CopyFrom shares elements")
+	}
+}
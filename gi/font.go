@@ -72,7 +72,7 @@ type FontStyle struct {
 	Ch       float32         `desc:"Ch size of font -- this is the actual width of the 0 glyph in the font"`
 	Rem      float32         `desc:"Rem size of font -- 12pt converted to same effective DPI as above measurements"`
 	FaceName string          `desc:"full name of font face as loaded -- computed based on Family, Style, Weight, etc"`
-	// todo: kerning
+	// note: kerning, ligatures etc are done by text shaping -- see TextShaping
 	// todo: stretch -- css 3 -- not supported
 }
 
@@ -921,7 +921,7 @@ func FontNameFromMods(basenm string, str FontStretch, wt FontWeights, sty FontSt
 var FontExts = map[string]struct{}{
 	".ttf": struct{}{},
 	".ttc": struct{}{}, // note: unpack to raw .ttf to use -- otherwise only getting first font
-	//	".otf": struct{}{},  // not yet supported
+	".otf": struct{}{},
 }
 
 // FontsAvailFromPath scans for all fonts we can use on a given path,
@@ -1037,7 +1037,9 @@ var shortFontMods = map[string]string{
 
 // OpenFontFace loads a font file at given path, with given raw size in
 // display dots, and if strokeWidth is > 0, the font is drawn in outline form
-// (stroked) instead of filled (supported in SVG).
+// (stroked) instead of filled (supported in SVG, for .ttf fonts only).  The
+// OpenType layout tables of the font are used for shaping text in the face
+// (see FaceShaper).
 func OpenFontFace(path string, size int, strokeWidth int) (font.Face, error) {
 	if strings.HasPrefix(path, "gofont") {
 		return OpenGoFont(path, size, strokeWidth)
@@ -1046,31 +1048,35 @@ func OpenFontFace(path string, size int, strokeWidth int) (font.Face, error) {
 	if err != nil {
 		return nil, err
 	}
+	var face font.Face
 	ext := strings.ToLower(filepath.Ext(path))
-	if ext == ".otf" {
-		// note: this compiles but otf fonts are NOT yet supported apparently
+	if ext == ".otf" { // includes CFF (PostScript) outlines, which truetype does not read
 		f, err := sfnt.Parse(fontBytes)
 		if err != nil {
 			return nil, err
 		}
-		face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		face, err = opentype.NewFace(f, &opentype.FaceOptions{
 			Size: float64(size),
+			DPI:  72, // size is already in dots
 			// Hinting: font.HintingFull,
 		})
-		return face, err
+		if err != nil {
+			return nil, err
+		}
 	} else {
 		f, err := truetype.Parse(fontBytes)
 		if err != nil {
 			return nil, err
 		}
-		face := truetype.NewFace(f, &truetype.Options{
+		face = truetype.NewFace(f, &truetype.Options{
 			Size:   float64(size),
 			Stroke: strokeWidth,
 			// Hinting: font.HintingFull,
 			// GlyphCacheEntries: 1024, // default is 512 -- todo benchmark
 		})
-		return face, nil
 	}
+	AddFaceShaper(face, path, fontBytes, size)
+	return face, nil
 }
 
 // see: https://blog.golang.org/go-fonts
@@ -1108,6 +1114,7 @@ func OpenGoFont(path string, size int, strokeWidth int) (font.Face, error) {
 		// GlyphCacheEntries: 1024, // default is 512 -- todo benchmark
 
 	})
+	AddFaceShaper(face, path, gf.ttf, size)
 	return face, nil
 }
 
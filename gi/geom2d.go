@@ -658,6 +658,20 @@ func (a Matrix2D) ExtractScale() (scx, scy float32) {
 	return
 }
 
+// Inverse returns the inverse of the matrix, which undoes its transform --
+// a singular matrix (e.g., with a zero scaling) returns the identity
+func (a Matrix2D) Inverse() Matrix2D {
+	det := a.XX*a.YY - a.XY*a.YX
+	if det == 0 {
+		return Identity2D()
+	}
+	xx := a.YY / det
+	xy := -a.XY / det
+	yx := -a.YX / det
+	yy := a.XX / det
+	return Matrix2D{xx, yx, xy, yy, -(xx*a.X0 + xy*a.Y0), -(yx*a.X0 + yy*a.Y0)}
+}
+
 // ParseFloat32 logs any strconv.ParseFloat errors
 func ParseFloat32(pstr string) (float32, error) {
 	r, err := strconv.ParseFloat(pstr, 32)
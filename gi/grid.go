@@ -0,0 +1,405 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"strconv"
+	"strings"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ints"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Grid Tracks
+
+// GridTrack is the size of one track (row or column) of a grid layout, as
+// specified in the grid-template-columns or grid-template-rows style
+type GridTrack struct {
+	Min     units.Value `desc:"minimum size of the track -- only used if MinAuto is false"`
+	Max     units.Value `desc:"maximum size of the track -- only used if MaxAuto is false and Fr is 0"`
+	MinAuto bool        `desc:"minimum size is the size needed by the content of the track"`
+	MaxAuto bool        `desc:"maximum size is the preferred size of the content of the track, which stretches if any of the content does"`
+	Fr      float32     `desc:"if > 0, the track is flexible, and gets this fraction of the space remaining after the other tracks are sized, relative to the other flexible tracks -- like the css fr unit"`
+}
+
+// GridTrackAuto is an auto-sized track, sized by its content -- tracks not
+// specified in the template are auto
+var GridTrackAuto = GridTrack{MinAuto: true, MaxAuto: true}
+
+// ParseGridTrack parses one track size: auto, a length (e.g., 10em, 100px),
+// a fraction of the remaining space (e.g., 1fr), or minmax(min, max) of
+// those
+func ParseGridTrack(str string) GridTrack {
+	str = strings.ToLower(strings.TrimSpace(str))
+	if strings.HasPrefix(str, "minmax(") && strings.HasSuffix(str, ")") {
+		args := strings.SplitN(str[7:len(str)-1], ",", 2)
+		if len(args) == 2 {
+			mn := ParseGridTrack(args[0])
+			mx := ParseGridTrack(args[1])
+			return GridTrack{Min: mn.Min, MinAuto: mn.MinAuto, Max: mx.Max, MaxAuto: mx.MaxAuto, Fr: mx.Fr}
+		}
+	}
+	switch {
+	case str == "" || str == "auto" || str == "min-content" || str == "max-content":
+		return GridTrackAuto
+	case strings.HasSuffix(str, "fr"):
+		fr, _ := strconv.ParseFloat(strings.TrimSpace(str[:len(str)-2]), 32)
+		return GridTrack{MinAuto: true, Fr: float32(fr)}
+	}
+	v := units.StringToValue(str)
+	return GridTrack{Min: v, Max: v}
+}
+
+// ParseGridTracks parses a space-separated list of track sizes (see
+// ParseGridTrack), which can include repeat(n, sizes) to repeat the given
+// sizes n times
+func ParseGridTracks(str string) []GridTrack {
+	var trks []GridTrack
+	for _, tok := range gridTokens(str) {
+		if strings.HasPrefix(tok, "repeat(") && strings.HasSuffix(tok, ")") {
+			args := strings.SplitN(tok[7:len(tok)-1], ",", 2)
+			if len(args) != 2 {
+				continue
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(args[0]))
+			rep := ParseGridTracks(args[1])
+			for i := 0; i < n; i++ {
+				trks = append(trks, rep...)
+			}
+			continue
+		}
+		trks = append(trks, ParseGridTrack(tok))
+	}
+	return trks
+}
+
+// gridTokens splits given string at spaces that are not within parentheses
+func gridTokens(str string) []string {
+	var toks []string
+	depth := 0
+	st := -1
+	for i, r := range str {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case depth == 0 && (r == ' ' || r == '\t' || r == '\n'):
+			if st >= 0 {
+				toks = append(toks, str[st:i])
+				st = -1
+			}
+			continue
+		}
+		if st < 0 {
+			st = i
+		}
+	}
+	if st >= 0 {
+		toks = append(toks, str[st:])
+	}
+	return toks
+}
+
+// ParseGridAreas parses grid-template-areas, with the names of the cells in
+// each row given in a quoted string as in css, e.g., "head head" "side
+// main", or on separate lines if there are no quotes -- a . is an unnamed
+// cell.  Returns the range of cells of each named area (X = col, Y = row),
+// and the number of rows and columns in the template.
+func ParseGridAreas(str string) (areas map[string]image.Rectangle, rows, cols int) {
+	var lines []string
+	if strings.Contains(str, `"`) {
+		fs := strings.Split(str, `"`)
+		for i := 1; i < len(fs); i += 2 {
+			lines = append(lines, fs[i])
+		}
+	} else {
+		lines = strings.Split(str, "\n")
+	}
+	for _, ln := range lines {
+		nms := strings.Fields(ln)
+		if len(nms) == 0 {
+			continue
+		}
+		for c, nm := range nms {
+			if strings.Trim(nm, ".") == "" {
+				continue
+			}
+			if areas == nil {
+				areas = make(map[string]image.Rectangle)
+			}
+			cell := image.Rect(c, rows, c+1, rows+1)
+			if ar, has := areas[nm]; has {
+				cell = ar.Union(cell) // non-rectangular areas get their bounds
+			}
+			areas[nm] = cell
+		}
+		cols = ints.MaxInt(cols, len(nms))
+		rows++
+	}
+	return
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Grid Placement
+
+// GridPlace computes the cells of the children of a grid layout, setting
+// their LayData GridPos and GridSpan, and the GridSize of the layout, which
+// has at least the given numbers of rows and cols (e.g., from the track
+// templates) -- see GridPlaceItems for the placement rules
+func (ly *Layout) GridPlace(rows, cols int) {
+	var items []*LayoutStyle
+	var wbs []*WidgetBase
+	for _, c := range ly.Kids {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		items = append(items, &ni.Sty.Layout)
+		wbs = append(wbs, ni)
+	}
+	areas, arows, acols := ParseGridAreas(ly.Sty.Layout.GridTemplateAreas)
+	cols = ints.MaxInt(cols, ints.MaxInt(ly.Sty.Layout.Columns, acols))
+	pos, span, size := GridPlaceItems(items, cols, areas)
+	for i, wb := range wbs {
+		wb.LayData.GridPos = pos[i]
+		wb.LayData.GridSpan = span[i]
+	}
+	size.Y = ints.MaxInt(size.Y, ints.MaxInt(rows, arows))
+	ly.GridSize = size
+}
+
+// GridPlaceItems computes the cell position and span (X = col, Y = row) of
+// grid items with given layout styles, in a grid with at least the given
+// number of columns and the given named areas, returning also the overall
+// grid size.  Items in a named grid-area are placed there, then items with
+// an explicit Row or Col (either > 0) at that cell, and then the rest
+// automatically in order, each at the first free cells for its span after
+// the previous one, filling rows in turn (like css grid-auto-flow: row).  If
+// the number of columns is not otherwise determined, it is the square root
+// of the number of items.
+func GridPlaceItems(items []*LayoutStyle, cols int, areas map[string]image.Rectangle) (pos, span []image.Point, size image.Point) {
+	n := len(items)
+	pos = make([]image.Point, n)
+	span = make([]image.Point, n)
+	placed := make([]bool, n)
+	simple := true // no spans, areas or explicit cells
+	for i, ls := range items {
+		span[i] = image.Point{ints.MaxInt(ls.ColSpan, 1), ints.MaxInt(ls.RowSpan, 1)}
+		if ar, ok := areas[ls.GridArea]; ok && ls.GridArea != "" {
+			pos[i] = ar.Min
+			span[i] = ar.Size()
+			placed[i] = true
+		} else if ls.Row > 0 || ls.Col > 0 {
+			pos[i] = image.Point{ls.Col, ls.Row}
+			placed[i] = true
+		}
+		if placed[i] {
+			cols = ints.MaxInt(cols, pos[i].X+span[i].X)
+		}
+		if placed[i] || span[i] != (image.Point{1, 1}) {
+			simple = false
+		}
+	}
+	if cols == 0 {
+		cols = ints.MaxInt(int(math32.Sqrt(float32(n))), 1) // whatever -- not well defined
+	}
+	if simple {
+		for i := range items {
+			pos[i] = image.Point{i % cols, i / cols}
+		}
+		size = image.Point{cols, (n + cols - 1) / cols}
+		return
+	}
+
+	var occ [][]bool // occupied cells, by row
+	occupy := func(i int) {
+		for r := pos[i].Y; r < pos[i].Y+span[i].Y; r++ {
+			for len(occ) <= r {
+				occ = append(occ, make([]bool, cols))
+			}
+			for c := pos[i].X; c < pos[i].X+span[i].X; c++ {
+				occ[r][c] = true
+			}
+		}
+		size.Y = ints.MaxInt(size.Y, pos[i].Y+span[i].Y)
+	}
+	free := func(p, sp image.Point) bool {
+		for r := p.Y; r < p.Y+sp.Y && r < len(occ); r++ {
+			for c := p.X; c < p.X+sp.X; c++ {
+				if occ[r][c] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	for i := range items {
+		if placed[i] {
+			occupy(i)
+		}
+	}
+	var cur image.Point
+	for i := range items {
+		if placed[i] {
+			continue
+		}
+		span[i].X = ints.MinInt(span[i].X, cols)
+		for {
+			if cur.X+span[i].X > cols {
+				cur.X = 0
+				cur.Y++
+			}
+			if free(cur, span[i]) {
+				break
+			}
+			cur.X++
+		}
+		pos[i] = cur
+		occupy(i)
+		cur.X += span[i].X
+	}
+	size.X = cols
+	return
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Grid Track Sizes
+
+// SetTrackSizes applies the track template constraints to the need, pref and
+// max sizes accumulated from the content of the track, using given units
+// context to convert lengths
+func (gd *GridData) SetTrackSizes(uc *units.Context) {
+	tr := &gd.Track
+	if !tr.MinAuto {
+		gd.SizeNeed = tr.Min.ToDots(uc)
+	}
+	if tr.Fr > 0 { // gets its extra from the fr allocation
+		gd.SizePref = gd.SizeNeed
+		gd.SizeMax = 0
+		return
+	}
+	if !tr.MaxAuto {
+		mx := Max32(tr.Max.ToDots(uc), gd.SizeNeed)
+		gd.SizeMax = mx
+		gd.SizePref = Min32(gd.SizePref, mx)
+	}
+	gd.SizePref = Max32(gd.SizePref, gd.SizeNeed)
+}
+
+// GridSpanSizes distributes the sizes of an item spanning n tracks starting
+// at st among the content-sized tracks that it spans, to the extent that
+// those tracks (plus given spacing between them) are not already large
+// enough -- tracks sized only by their content get it first, then flexible
+// (fr) tracks with a content-sized minimum.  If the item stretches and none
+// of its tracks are flexible, the content-sized ones are set to stretch.
+func GridSpanSizes(gds []GridData, st, n int, need, pref, max, spc float32) {
+	var elig []int
+	for i := st; i < st+n; i++ {
+		tr := &gds[i].Track
+		if tr.MinAuto && tr.MaxAuto && tr.Fr == 0 {
+			elig = append(elig, i)
+		}
+	}
+	if len(elig) == 0 {
+		for i := st; i < st+n; i++ {
+			if gds[i].Track.MinAuto && gds[i].Track.Fr > 0 {
+				elig = append(elig, i)
+			}
+		}
+	}
+	if len(elig) == 0 {
+		return
+	}
+	gap := float32(n-1) * spc
+	sum := func() (sneed, spref float32) {
+		for i := st; i < st+n; i++ {
+			sneed += gds[i].SizeNeed
+			spref += gds[i].SizePref
+		}
+		return
+	}
+	sneed, _ := sum()
+	if ex := need - (sneed + gap); ex > 0 {
+		per := ex / float32(len(elig))
+		for _, i := range elig {
+			gds[i].SizeNeed += per
+			gds[i].SizePref = Max32(gds[i].SizePref, gds[i].SizeNeed)
+		}
+	}
+	_, spref := sum()
+	if ex := pref - (spref + gap); ex > 0 {
+		per := ex / float32(len(elig))
+		for _, i := range elig {
+			gds[i].SizePref += per
+		}
+	}
+	if max >= 0 {
+		return
+	}
+	for i := st; i < st+n; i++ {
+		if gds[i].SizeMax < 0 || gds[i].Track.Fr > 0 {
+			return
+		}
+	}
+	for _, i := range elig {
+		gds[i].SizeMax = -1
+	}
+}
+
+// GridFrSizes sets the allocated size of each track, for tracks including
+// flexible (fr) ones, which share the space remaining after the other tracks
+// get their size (pref if usePref, else need), in proportion to their Fr
+// factors, but not less than their own need
+func GridFrSizes(gds []GridData, avail float32, usePref bool) {
+	left := avail
+	flex := make([]bool, len(gds))
+	for i := range gds {
+		gd := &gds[i]
+		if gd.Track.Fr > 0 {
+			flex[i] = true
+			continue
+		}
+		gd.AllocSize = gd.SizeNeed
+		if usePref {
+			gd.AllocSize = gd.SizePref
+		}
+		left -= gd.AllocSize
+	}
+	for {
+		frTot := float32(0)
+		rem := left
+		for i := range gds {
+			if flex[i] {
+				frTot += gds[i].Track.Fr
+			} else if gds[i].Track.Fr > 0 {
+				rem -= gds[i].SizeNeed
+			}
+		}
+		if frTot == 0 {
+			return
+		}
+		unit := Max32(rem, 0) / frTot
+		done := true
+		for i := range gds {
+			if !flex[i] {
+				continue
+			}
+			gd := &gds[i]
+			gd.AllocSize = gd.Track.Fr * unit
+			if gd.AllocSize < gd.SizeNeed { // too small: fix at need, and redo the rest
+				gd.AllocSize = gd.SizeNeed
+				flex[i] = false
+				done = false
+			}
+		}
+		if done {
+			return
+		}
+	}
+}
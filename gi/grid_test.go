@@ -0,0 +1,152 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"testing"
+
+	"github.com/goki/gi/units"
+)
+
+func TestParseGridTracks(t *testing.T) {
+	trks := ParseGridTracks("auto 100px 2fr minmax(50px, 1fr) repeat(2, minmax(auto, 10px))")
+	if len(trks) != 6 {
+		t.Fatalf("tracks: %v", trks)
+	}
+	if trks[0] != GridTrackAuto {
+		t.Errorf("auto: %v", trks[0])
+	}
+	if tr := trks[1]; tr.MinAuto || tr.MaxAuto || tr.Min.Val != 100 || tr.Max.Val != 100 {
+		t.Errorf("length: %v", tr)
+	}
+	if tr := trks[2]; !tr.MinAuto || tr.Fr != 2 {
+		t.Errorf("fr: %v", tr)
+	}
+	if tr := trks[3]; tr.MinAuto || tr.Min.Val != 50 || tr.Fr != 1 {
+		t.Errorf("minmax fr: %v", tr)
+	}
+	if tr := trks[5]; !tr.MinAuto || tr.MaxAuto || tr.Max.Val != 10 || tr.Fr != 0 {
+		t.Errorf("repeat minmax: %v", tr)
+	}
+}
+
+func TestParseGridAreas(t *testing.T) {
+	areas, rows, cols := ParseGridAreas(`"head head head" "side main ." "side foot foot"`)
+	if rows != 3 || cols != 3 {
+		t.Errorf("rows: %v cols: %v", rows, cols)
+	}
+	want := map[string]image.Rectangle{
+		"head": image.Rect(0, 0, 3, 1),
+		"side": image.Rect(0, 1, 1, 3),
+		"main": image.Rect(1, 1, 2, 2),
+		"foot": image.Rect(1, 2, 3, 3),
+	}
+	if len(areas) != len(want) {
+		t.Errorf("areas: %v", areas)
+	}
+	for nm, ar := range want {
+		if areas[nm] != ar {
+			t.Errorf("area %v: %v, want: %v", nm, areas[nm], ar)
+		}
+	}
+	if areas, rows, _ := ParseGridAreas("a b\nc d"); rows != 2 || areas["d"] != image.Rect(1, 1, 2, 2) {
+		t.Errorf("unquoted areas: %v", areas)
+	}
+}
+
+func TestGridPlaceItems(t *testing.T) {
+	areas, _, _ := ParseGridAreas(`"head head head"`)
+	items := []*LayoutStyle{
+		{},                       // auto
+		{ColSpan: 2},             // auto, wraps to next row
+		{GridArea: "head"},       // area
+		{Row: 1, Col: 2},         // explicit
+		{RowSpan: 2},             // auto, after the previous one
+		{},                       // auto
+		{GridArea: "missing"},    // auto
+		{ColSpan: 5, RowSpan: 1}, // clamped to cols
+	}
+	pos, span, size := GridPlaceItems(items, 0, areas)
+	wantPos := []image.Point{{0, 1}, {0, 2}, {0, 0}, {2, 1}, {2, 2}, {0, 3}, {1, 3}, {0, 4}}
+	wantSpan := []image.Point{{1, 1}, {2, 1}, {3, 1}, {1, 1}, {1, 2}, {1, 1}, {1, 1}, {3, 1}}
+	for i := range items {
+		if pos[i] != wantPos[i] || span[i] != wantSpan[i] {
+			t.Errorf("item %v pos: %v span: %v, want: %v %v", i, pos[i], span[i], wantPos[i], wantSpan[i])
+		}
+	}
+	if size != (image.Point{3, 5}) {
+		t.Errorf("size: %v", size)
+	}
+
+	// regular grid with columns and no placement
+	items = make([]*LayoutStyle, 5)
+	for i := range items {
+		items[i] = &LayoutStyle{}
+	}
+	pos, _, size = GridPlaceItems(items, 2, nil)
+	if pos[3] != (image.Point{1, 1}) || size != (image.Point{2, 3}) {
+		t.Errorf("regular pos: %v size: %v", pos, size)
+	}
+	pos, _, size = GridPlaceItems(items, 0, nil)
+	if pos[4] != (image.Point{0, 2}) || size != (image.Point{2, 3}) {
+		t.Errorf("regular sqrt pos: %v size: %v", pos, size)
+	}
+	if _, _, size = GridPlaceItems(nil, 0, nil); size != (image.Point{1, 0}) {
+		t.Errorf("empty size: %v", size)
+	}
+
+	// auto items flow around an explicit one
+	items[1] = &LayoutStyle{Row: 0, Col: 1}
+	items[4] = &LayoutStyle{ColSpan: 2}
+	pos, _, size = GridPlaceItems(items, 3, nil)
+	wantPos = []image.Point{{0, 0}, {1, 0}, {2, 0}, {0, 1}, {1, 1}}
+	for i := range items {
+		if pos[i] != wantPos[i] {
+			t.Errorf("flow item %v pos: %v, want: %v", i, pos[i], wantPos[i])
+		}
+	}
+	if size != (image.Point{3, 2}) {
+		t.Errorf("flow size: %v", size)
+	}
+}
+
+func TestGridTrackSizes(t *testing.T) {
+	uc := &units.Context{}
+	gds := make([]GridData, 4)
+	for i, tr := range ParseGridTracks("100px 1fr 3fr minmax(auto, 20px)") {
+		gds[i].Track = tr
+		gds[i].SizeNeed = 10
+		gds[i].SizePref = 30
+		gds[i].SetTrackSizes(uc)
+	}
+	if gd := gds[0]; gd.SizeNeed != 100 || gd.SizePref != 100 || gd.SizeMax != 100 {
+		t.Errorf("length track: %v", gd)
+	}
+	if gd := gds[3]; gd.SizeNeed != 10 || gd.SizePref != 20 || gd.SizeMax != 20 {
+		t.Errorf("minmax track: %v", gd)
+	}
+	GridFrSizes(gds, 300, true)
+	if gds[0].AllocSize != 100 || gds[1].AllocSize != 45 || gds[2].AllocSize != 135 || gds[3].AllocSize != 20 {
+		t.Errorf("fr sizes: %v %v %v %v", gds[0].AllocSize, gds[1].AllocSize, gds[2].AllocSize, gds[3].AllocSize)
+	}
+	gds[1].SizeNeed = 60 // fixed at need, rest goes to the other
+	GridFrSizes(gds, 300, true)
+	if gds[1].AllocSize != 60 || gds[2].AllocSize != 120 {
+		t.Errorf("fr sizes with need: %v %v", gds[1].AllocSize, gds[2].AllocSize)
+	}
+
+	gds = make([]GridData, 3)
+	for i := range gds {
+		gds[i].Track = GridTrackAuto
+		gds[i].SizeNeed = 10
+		gds[i].SizePref = 10
+	}
+	gds[2].Track = ParseGridTrack("50px")
+	GridSpanSizes(gds, 0, 3, 90, 90, -1, 5)
+	if gds[0].SizeNeed != 35 || gds[1].SizeNeed != 35 || gds[2].SizeNeed != 10 || gds[0].SizeMax != -1 {
+		t.Errorf("span sizes: %v", gds)
+	}
+}
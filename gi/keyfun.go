@@ -108,7 +108,10 @@ func (kf *KeyFuns) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(
 
 // KeyMap is a map between a key sequence (chord) and a specific KeyFun
 // function.  This mapping must be unique, in that each chord has unique
-// KeyFun, but multiple chords can trigger the same function.
+// KeyFun, but multiple chords can trigger the same function.  Chords can be
+// multi-key sequences, e.g., "Control+X, Control+S" -- the prefix of a
+// sequence cannot then be bound as a chord by itself, as it would make the
+// sequence unreachable (see KeyMapsItem Conflicts).
 type KeyMap map[key.Chord]KeyFuns
 
 // ActiveKeyMap points to the active map -- users can set this to an
@@ -118,17 +121,68 @@ var ActiveKeyMap *KeyMap
 // ActiveKeyMapName is the name of the active keymap
 var ActiveKeyMapName KeyMapName
 
+// ActiveKeyContexts are the context-specific maps of the active keymap, which
+// are layered over the ActiveKeyMap for widgets in those contexts
+var ActiveKeyContexts KeyContextMaps
+
+// KeyContext is the name of a widget context for interpreting key chords, in
+// which the bindings of a context-specific KeyMap (see KeyContextMaps) are
+// layered over the global bindings of the KeyMap -- widgets return their
+// context with the KeyContexter interface, and use KeyFunContext
+type KeyContext string
+
+const (
+	// KeyContextGlobal is the global context, using the KeyMap alone
+	KeyContextGlobal KeyContext = ""
+
+	// KeyContextTextView is the context of giv.TextView text editors
+	KeyContextTextView KeyContext = "TextView"
+
+	// KeyContextTreeView is the context of giv.TreeView trees
+	KeyContextTreeView KeyContext = "TreeView"
+
+	// KeyContextSliceView is the context of giv.SliceView and TableView lists
+	KeyContextSliceView KeyContext = "SliceView"
+
+	// KeyContextRaw is the context of widgets that record the raw key chords
+	// typed, e.g., giv.KeyChordEdit -- multi-key sequences are not
+	// processed by the window for such widgets
+	KeyContextRaw KeyContext = "Raw"
+)
+
+// KeyContexter is an optional interface for widgets that interpret key
+// chords in a specific KeyContext -- the window uses the context of the
+// focus widget to determine whether a chord is the prefix of a multi-key
+// sequence
+type KeyContexter interface {
+	// KeyContext returns the key context of the widget
+	KeyContext() KeyContext
+}
+
+// KeyContextMaps are context-specific key maps, which are layered over the
+// main KeyMap of a KeyMapsItem for widgets in each context: chords bound in
+// the context map take precedence over those in the main map
+type KeyContextMaps map[KeyContext]KeyMap
+
+// KeyMapNotSet is the prefix of the placeholder chords that Update adds
+// for key functions that are missing from a map
+const KeyMapNotSet = "- Not Set - "
+
 // SetActiveKeyMap sets the current ActiveKeyMap, calling Update on the map
-// prior to setting it to ensure that it is a valid, complete map
+// prior to setting it to ensure that it is a valid, complete map -- also sets
+// the ActiveKeyContexts from the context-specific maps of the AvailKeyMaps
+// entry of the same name (nil if there is none)
 func SetActiveKeyMap(km *KeyMap, kmName KeyMapName) {
 	km.Update(kmName)
 	ActiveKeyMap = km
 	ActiveKeyMapName = kmName
+	ActiveKeyContexts = AvailKeyMaps.ContextsByName(kmName)
 }
 
 // SetActiveKeyMapName sets the current ActiveKeyMap by name from those
 // defined in AvailKeyMaps, calling Update on the map prior to setting it to
-// ensure that it is a valid, complete map
+// ensure that it is a valid, complete map -- also sets the ActiveKeyContexts
+// from its context-specific maps
 func SetActiveKeyMapName(mapnm KeyMapName) {
 	km, _, ok := AvailKeyMaps.MapByName(mapnm)
 	if ok {
@@ -151,16 +205,47 @@ func SetActiveKeyMapName(mapnm KeyMapName) {
 // KeyFun translates chord into keyboard function -- use oswin key.Chord
 // to get chord
 func KeyFun(chord key.Chord) KeyFuns {
+	return KeyFunContext(chord, KeyContextGlobal)
+}
+
+// KeyFunContext translates chord into keyboard function for a widget in
+// given context, using the map for that context in ActiveKeyContexts if it
+// binds the chord, and otherwise the ActiveKeyMap
+func KeyFunContext(chord key.Chord, ctxt KeyContext) KeyFuns {
 	kf := KeyFunNil
 	if chord != "" {
-		kf = (*ActiveKeyMap)[chord]
+		if cm, ok := ActiveKeyContexts[ctxt]; ok && ctxt != KeyContextGlobal {
+			kf = cm[chord]
+		}
+		if kf == KeyFunNil {
+			kf = (*ActiveKeyMap)[chord]
+		}
 		if KeyEventTrace {
-			fmt.Printf("gi.KeyFun chord: %v = %v\n", chord, kf)
+			fmt.Printf("gi.KeyFun chord: %v context: %v = %v\n", chord, ctxt, kf)
 		}
 	}
 	return kf
 }
 
+// KeySeqPrefix returns true if the given chord (which can itself be a
+// sequence) is the prefix of a multi-key sequence bound in the active maps
+// for given context, and is not bound itself
+func KeySeqPrefix(seq key.Chord, ctxt KeyContext) bool {
+	if seq == "" || KeyFunContext(seq, ctxt) != KeyFunNil {
+		return false
+	}
+	if cm, ok := ActiveKeyContexts[ctxt]; ok && ctxt != KeyContextGlobal && cm.HasPrefix(seq) {
+		return true
+	}
+	return ActiveKeyMap.HasPrefix(seq)
+}
+
+// IsChordPrefix returns true if the given chord is a multi-key sequence
+// starting with the given prefix sequence
+func IsChordPrefix(chord, prefix key.Chord) bool {
+	return strings.HasPrefix(string(chord), string(prefix)+key.ChordSeqSep)
+}
+
 // KeyMapItem records one element of the key map -- used for organizing the map.
 type KeyMapItem struct {
 	Key key.Chord `desc:"the key chord that activates a function"`
@@ -178,14 +263,30 @@ func (km *KeyMap) ToSlice() []KeyMapItem {
 	return kms
 }
 
-// ChordForFun returns first key chord trigger for given KeyFun in map
+// ChordForFun returns first key chord trigger for given KeyFun in map,
+// preferring single chords over multi-key sequences
 func (km *KeyMap) ChordForFun(kf KeyFuns) key.Chord {
-	for key, fun := range *km {
+	var seq key.Chord
+	for ch, fun := range *km {
 		if fun == kf {
-			return key
+			if !ch.IsSeq() {
+				return ch
+			}
+			seq = ch
 		}
 	}
-	return ""
+	return seq
+}
+
+// HasPrefix returns true if the map binds a multi-key sequence that starts
+// with the given prefix chord (which can itself be a sequence)
+func (km *KeyMap) HasPrefix(prefix key.Chord) bool {
+	for ch := range *km {
+		if IsChordPrefix(ch, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 // ShortcutForFun returns OS-specific formatted shortcut for first key chord
@@ -227,7 +328,7 @@ func (km *KeyMap) Update(kmName KeyMapName) {
 					fmt.Printf("gi.KeyMap: %v is missing a key for function: %v\n", kmName, mi)
 					s := mi.String()
 					s = strings.TrimPrefix(s, "KeyFun")
-					s = "- Not Set - " + s
+					s = KeyMapNotSet + s
 					nski := KeyMapItem{Key: key.Chord(s), Fun: mi}
 					addkm = append(addkm, nski)
 				}
@@ -256,6 +357,17 @@ func (km *KeyMap) Update(kmName KeyMapName) {
 // processing.
 type Shortcuts map[key.Chord]*Action
 
+// HasPrefix returns true if there is a shortcut for a multi-key sequence
+// that starts with the given prefix chord (which can itself be a sequence)
+func (sc Shortcuts) HasPrefix(prefix key.Chord) bool {
+	for ch := range sc {
+		if IsChordPrefix(ch, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 /////////////////////////////////////////////////////////////////////////////////
 // KeyMaps -- list of KeyMap's
 
@@ -269,9 +381,10 @@ var DefaultKeyMap = KeyMapName("MacEmacs")
 
 // KeyMapsItem is an entry in a KeyMaps list
 type KeyMapsItem struct {
-	Name string `width:"20" desc:"name of keymap"`
-	Desc string `desc:"description of keymap -- good idea to include source it was derived from"`
-	Map  KeyMap `desc:"to edit key sequence click button and type new key combination; to edit function mapped to key sequence choose from menu"`
+	Name     string         `width:"20" desc:"name of keymap"`
+	Desc     string         `desc:"description of keymap -- good idea to include source it was derived from"`
+	Map      KeyMap         `desc:"to edit key sequence click button and type new key combination -- type several in a row for a multi-key sequence; to edit function mapped to key sequence choose from menu"`
+	Contexts KeyContextMaps `desc:"context-specific maps, e.g., for TextView or TreeView, with bindings that take precedence over the main Map for widgets in that context"`
 }
 
 // Label satisfies the Labeler interface
@@ -279,6 +392,61 @@ func (km KeyMapsItem) Label() string {
 	return km.Name
 }
 
+// Conflicts returns a description of each conflict among the bindings of
+// this keymap: a chord that is bound and is also the prefix of a bound
+// multi-key sequence, making the sequence unreachable, within the main map,
+// and within each context map layered over the main map
+func (km *KeyMapsItem) Conflicts() []string {
+	var cfs []string
+	report := func(ctxt KeyContext, pch key.Chord, pfun KeyFuns, ch key.Chord, fun KeyFuns) {
+		cnm := string(ctxt)
+		if ctxt == KeyContextGlobal {
+			cnm = "Global"
+		}
+		cfs = append(cfs, fmt.Sprintf("%v %v: %v is bound to %v, so sequence %v for %v cannot be typed", km.Name, cnm, pch, pfun, ch, fun))
+	}
+	check := func(ctxt KeyContext, cm KeyMap) {
+		for ch, fun := range cm {
+			if !ch.IsSeq() {
+				continue
+			}
+			chs := ch.Chords()
+			pfx := chs[0]
+			for i := 0; i < len(chs)-1; i++ {
+				if i > 0 {
+					pfx += key.ChordSeqSep + chs[i]
+				}
+				pfun, has := cm[pfx]
+				if !has && ctxt != KeyContextGlobal {
+					pfun, has = km.Map[pfx]
+				}
+				if has {
+					report(ctxt, pfx, pfun, ch, fun)
+					break
+				}
+			}
+		}
+	}
+	check(KeyContextGlobal, km.Map)
+	for ctxt, cm := range km.Contexts {
+		check(ctxt, cm)
+	}
+	for ctxt, cm := range km.Contexts { // global sequences shadowed in context
+		for ch, fun := range km.Map {
+			if !ch.IsSeq() {
+				continue
+			}
+			for pch, pfun := range cm {
+				if IsChordPrefix(ch, pch) {
+					report(ctxt, pch, pfun, ch, fun)
+				}
+			}
+		}
+	}
+	sort.Strings(cfs)
+	return cfs
+}
+
 // KeyMaps is a list of KeyMap's -- users can edit these in Prefs -- to create
 // a custom one, just duplicate an existing map, rename, and customize
 type KeyMaps []KeyMapsItem
@@ -306,6 +474,17 @@ func (km *KeyMaps) MapByName(name KeyMapName) (*KeyMap, int, bool) {
 	return nil, -1, false
 }
 
+// ContextsByName returns the context-specific maps of the key map of given
+// name, or nil if there is no such map or it has no context maps
+func (km *KeyMaps) ContextsByName(name KeyMapName) KeyContextMaps {
+	for _, it := range *km {
+		if it.Name == string(name) {
+			return it.Contexts
+		}
+	}
+	return nil
+}
+
 // PrefsKeyMapsFileName is the name of the preferences file in GoGi prefs
 // directory for saving / loading the default AvailKeyMaps key maps list
 var PrefsKeyMapsFileName = "key_maps_prefs.json"
@@ -375,6 +554,22 @@ func (km *KeyMaps) ViewStd() {
 	TheViewIFace.KeyMapsView(&StdKeyMaps)
 }
 
+// CheckConflicts checks for conflicts among the bindings of each keymap (see
+// KeyMapsItem Conflicts), and reports them in a dialog -- returns true if
+// there are any conflicts
+func (km *KeyMaps) CheckConflicts() bool {
+	var cfs []string
+	for i := range *km {
+		cfs = append(cfs, (*km)[i].Conflicts()...)
+	}
+	if len(cfs) == 0 {
+		PromptDialog(nil, DlgOpts{Title: "No Key Map Conflicts", Prompt: "No conflicts were found among the key bindings of the key maps"}, true, false, nil, nil)
+		return false
+	}
+	PromptDialog(nil, DlgOpts{Title: "Key Map Conflicts", Prompt: strings.Join(cfs, "<br>\n")}, true, false, nil, nil)
+	return true
+}
+
 // AvailKeyMapsChanged is used to update giv.KeyMapsView toolbars via
 // following menu, toolbar props update methods -- not accurate if editing any
 // other map but works for now..
@@ -451,6 +646,9 @@ var KeyMapsProps = ki.Props{
 			},
 		}},
 		{"sep-std", ki.BlankProp{}},
+		{"CheckConflicts", ki.Props{
+			"desc": "Checks for conflicts among the key bindings of each key map, where a key chord is bound by itself and is also the start of a multi-key sequence, so that the sequence cannot be typed -- including bindings in context-specific maps that conflict with the main map",
+		}},
 		{"ViewStd", ki.Props{
 			"desc":    "Shows the standard maps that are compiled into the program and have all the lastest key functions bound to standard key chords.  Useful for comparing against custom maps.",
 			"confirm": true,
@@ -595,7 +793,7 @@ var StdKeyMaps = KeyMaps{
 		"Meta+W":                  KeyFunMenuClose,
 		"Shift+Meta+W":            KeyFunMenuCloseAlt1,
 		"Alt+Meta+W":              KeyFunMenuCloseAlt2,
-	}},
+	}, nil},
 	{"MacEmacs", "Mac with emacs-style navigation -- emacs wins in conflicts", KeyMap{
 		"UpArrow":                 KeyFunMoveUp,
 		"Shift+UpArrow":           KeyFunMoveUp,
@@ -720,7 +918,15 @@ var StdKeyMaps = KeyMaps{
 		"Meta+W":                  KeyFunMenuClose,
 		"Shift+Meta+W":            KeyFunMenuCloseAlt1,
 		"Alt+Meta+W":              KeyFunMenuCloseAlt2,
-	}},
+
+		// multi-key sequences
+		"Control+X, Control+S": KeyFunMenuSave,
+		"Control+X, Control+W": KeyFunMenuSaveAs,
+		"Control+X, Control+F": KeyFunMenuOpen,
+		"Control+X, k":         KeyFunMenuClose,
+		"Control+X, u":         KeyFunUndo,
+		"Control+X, h":         KeyFunSelectAll,
+	}, nil},
 	{"LinuxEmacs", "Linux with emacs-style navigation -- emacs wins in conflicts", KeyMap{
 		"UpArrow":                 KeyFunMoveUp,
 		"Shift+UpArrow":           KeyFunMoveUp,
@@ -833,7 +1039,15 @@ var StdKeyMaps = KeyMaps{
 		"Shift+Control+W":         KeyFunMenuClose,
 		"Shift+Alt+W":             KeyFunMenuCloseAlt1,
 		"Control+Alt+W":           KeyFunMenuCloseAlt2,
-	}},
+
+		// multi-key sequences
+		"Control+X, Control+S": KeyFunMenuSave,
+		"Control+X, Control+W": KeyFunMenuSaveAs,
+		"Control+X, Control+F": KeyFunMenuOpen,
+		"Control+X, k":         KeyFunMenuClose,
+		"Control+X, u":         KeyFunUndo,
+		"Control+X, h":         KeyFunSelectAll,
+	}, nil},
 	{"LinuxStd", "Standard Linux KeyMap", KeyMap{
 		"UpArrow":                 KeyFunMoveUp,
 		"Shift+UpArrow":           KeyFunMoveUp,
@@ -915,7 +1129,7 @@ var StdKeyMaps = KeyMaps{
 		"Control+W":               KeyFunMenuClose,
 		"Shift+Control+W":         KeyFunMenuCloseAlt1,
 		"Control+Alt+W":           KeyFunMenuCloseAlt2,
-	}},
+	}, nil},
 	{"WindowsStd", "Standard Windows KeyMap", KeyMap{
 		"UpArrow":                 KeyFunMoveUp,
 		"Shift+UpArrow":           KeyFunMoveUp,
@@ -997,7 +1211,7 @@ var StdKeyMaps = KeyMaps{
 		"Control+W":               KeyFunMenuClose,
 		"Shift+Control+W":         KeyFunMenuCloseAlt1,
 		"Control+Alt+W":           KeyFunMenuCloseAlt2,
-	}},
+	}, nil},
 	{"ChromeStd", "Standard chrome-browser and linux-under-chrome bindings", KeyMap{
 		"UpArrow":                 KeyFunMoveUp,
 		"Shift+UpArrow":           KeyFunMoveUp,
@@ -1079,5 +1293,5 @@ var StdKeyMaps = KeyMaps{
 		"Control+W":               KeyFunMenuClose,
 		"Shift+Control+W":         KeyFunMenuCloseAlt1,
 		"Control+Alt+W":           KeyFunMenuCloseAlt2,
-	}},
+	}, nil},
 }
@@ -0,0 +1,127 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"testing"
+
+	"github.com/goki/gi/oswin/key"
+)
+
+func TestKeySeqPrefix(t *testing.T) {
+	km := KeyMap{
+		"Control+X, Control+S": KeyFunMenuSave,
+		"Control+X, 4, f":      KeyFunMenuOpen,
+		"Control+A":            KeyFunHome,
+		"- Not Set - Undo":     KeyFunUndo,
+	}
+	svkm, svctxt := ActiveKeyMap, ActiveKeyContexts
+	defer func() { ActiveKeyMap, ActiveKeyContexts = svkm, svctxt }()
+	ActiveKeyMap = &km
+	ActiveKeyContexts = KeyContextMaps{
+		KeyContextTextView: KeyMap{"Control+X, Control+S": KeyFunMenuSaveAs, "Control+C, Control+C": KeyFunCopy},
+	}
+
+	prefixes := []struct {
+		seq  string
+		ctxt KeyContext
+		pfx  bool
+	}{
+		{"Control+X", KeyContextGlobal, true},
+		{"Control+X, 4", KeyContextGlobal, true},
+		{"Control+X, Control+S", KeyContextGlobal, false},
+		{"Control+A", KeyContextGlobal, false},
+		{"Control+C", KeyContextGlobal, false},
+		{"Control+C", KeyContextTextView, true},
+		{"Control+X", KeyContextTreeView, true},
+		{"-", KeyContextGlobal, false},
+	}
+	for _, p := range prefixes {
+		if pfx := KeySeqPrefix(key.Chord(p.seq), p.ctxt); pfx != p.pfx {
+			t.Errorf("KeySeqPrefix %q in %q: %v, want: %v", p.seq, p.ctxt, pfx, p.pfx)
+		}
+	}
+	if kf := KeyFunContext("Control+X, Control+S", KeyContextTextView); kf != KeyFunMenuSaveAs {
+		t.Errorf("KeyFunContext in TextView: %v", kf)
+	}
+	if kf := KeyFunContext("Control+X, Control+S", KeyContextTreeView); kf != KeyFunMenuSave {
+		t.Errorf("KeyFunContext in TreeView: %v", kf)
+	}
+	if kf := KeyFun("Control+C, Control+C"); kf != KeyFunNil {
+		t.Errorf("KeyFun of context-only sequence: %v", kf)
+	}
+	if ch := km.ChordForFun(KeyFunHome); ch != "Control+A" {
+		t.Errorf("ChordForFun: %v", ch)
+	}
+}
+
+func TestKeyMapConflicts(t *testing.T) {
+	km := KeyMapsItem{Name: "Test", Map: KeyMap{
+		"Control+X, Control+S": KeyFunMenuSave,
+		"Control+X":            KeyFunCut,
+		"Control+C, Control+C": KeyFunCopy,
+		"Control+V":            KeyFunPaste,
+	}, Contexts: KeyContextMaps{
+		KeyContextTextView: KeyMap{
+			"Control+C":            KeyFunCopy,
+			"Control+V, Control+V": KeyFunPaste,
+		},
+	}}
+	want := []string{
+		"Test Global: Control+X is bound to KeyFunCut, so sequence Control+X, Control+S for KeyFunMenuSave cannot be typed",
+		"Test TextView: Control+C is bound to KeyFunCopy, so sequence Control+C, Control+C for KeyFunCopy cannot be typed",
+		"Test TextView: Control+V is bound to KeyFunPaste, so sequence Control+V, Control+V for KeyFunPaste cannot be typed",
+	}
+	cfs := km.Conflicts()
+	if len(cfs) != len(want) {
+		t.Fatalf("Conflicts: %q", cfs)
+	}
+	for i := range want {
+		if cfs[i] != want[i] {
+			t.Errorf("Conflict %v: %q, want: %q", i, cfs[i], want[i])
+		}
+	}
+	for _, skm := range StdKeyMaps {
+		if cfs := skm.Conflicts(); len(cfs) > 0 {
+			t.Errorf("StdKeyMaps conflicts: %q", cfs)
+		}
+	}
+}
+
+func TestKeyFunContext(t *testing.T) {
+	svkm, svnm, svctxt := ActiveKeyMap, ActiveKeyMapName, ActiveKeyContexts
+	svavail := AvailKeyMaps
+	defer func() {
+		ActiveKeyMap, ActiveKeyMapName, ActiveKeyContexts = svkm, svnm, svctxt
+		AvailKeyMaps = svavail
+	}()
+	emacs, _, _ := AvailKeyMaps.MapByName("MacEmacs")
+	AvailKeyMaps = append(KeyMaps{}, svavail...)
+	AvailKeyMaps = append(AvailKeyMaps, KeyMapsItem{Name: "TestContexts", Map: *emacs, Contexts: KeyContextMaps{
+		KeyContextTreeView: KeyMap{"Control+K": KeyFunCut},
+	}})
+
+	SetActiveKeyMapName("TestContexts")
+	if ActiveKeyContexts == nil {
+		t.Fatalf("TestContexts key contexts not set")
+	}
+	if kf := KeyFunContext("Control+K", KeyContextTextView); kf != KeyFunKill {
+		t.Errorf("TestContexts TextView Control+K: %v", kf)
+	}
+	if kf := KeyFunContext("Control+K", KeyContextTreeView); kf != KeyFunCut {
+		t.Errorf("TestContexts TreeView Control+K: %v", kf)
+	}
+	if kf := KeyFunContext("Control+D", KeyContextTreeView); kf != KeyFunDelete {
+		t.Errorf("TestContexts TreeView Control+D: %v", kf)
+	}
+
+	SetActiveKeyMapName("MacEmacs")
+	if ActiveKeyContexts != nil {
+		t.Errorf("MacEmacs key contexts: %v", ActiveKeyContexts)
+	}
+	if kf := KeyFunContext("Control+K", KeyContextTreeView); kf != KeyFunKill {
+		t.Errorf("MacEmacs TreeView Control+K: %v", kf)
+	}
+}
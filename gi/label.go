@@ -65,7 +65,8 @@ func ToLabeler(it interface{}) string {
 // including box rendering, and full HTML styling, including links -- LinkSig
 // emits link with data of URL -- opens default browser if nobody receiving
 // signal.  The default white-space option is 'pre' -- set to 'normal' or
-// other options to get word-wrapping etc.
+// other options to get word-wrapping etc.  Set the writing-mode style to
+// vertical-rl (or vertical-lr) for vertical text (see LayoutStdTB).
 type Label struct {
 	WidgetBase
 	Text        string              `xml:"text" desc:"label to display"`
@@ -148,7 +149,7 @@ func (lb *Label) SetText(txt string) {
 	if !sz.IsZero() {
 		sz.SetSubVal(2 * spc)
 	}
-	lb.Render.LayoutStdLR(&lb.Sty.Text, &lb.Sty.Font, &lb.Sty.UnContext, sz)
+	lb.Render.LayoutStd(&lb.Sty.Text, &lb.Sty.Font, &lb.Sty.UnContext, sz)
 	lb.UpdateEnd(updt)
 }
 
@@ -175,6 +176,7 @@ func (lb *Label) SetStateStyle() {
 			lb.Sty.Font.BgColor.SetColor(lb.CurBgColor)
 		}
 	}
+	lb.TransitionStyle()
 }
 
 // OpenLink opens given link, either by sending LinkSig signal if there are
@@ -345,7 +347,7 @@ func (lb *Label) LayoutLabel() {
 	if !sz.IsZero() {
 		sz.SetSubVal(2 * spc)
 	}
-	lb.Render.LayoutStdLR(&lb.Sty.Text, &lb.Sty.Font, &lb.Sty.UnContext, sz)
+	lb.Render.LayoutStd(&lb.Sty.Text, &lb.Sty.Font, &lb.Sty.UnContext, sz)
 }
 
 func (lb *Label) Style2D() {
@@ -372,8 +374,14 @@ func (lb *Label) Layout2D(parBBox image.Rectangle, iter int) bool {
 	sz := lb.Size2DSubSpace()
 	if lb.Sty.Text.HasWordWrap() {
 		lb.Render.SetHTML(lb.Text, &lb.Sty.Font, &lb.Sty.Text, &lb.Sty.UnContext, lb.CSSAgg)
-		lb.Render.LayoutStdLR(&lb.Sty.Text, &lb.Sty.Font, &lb.Sty.UnContext, sz)
-		if lb.Render.Size.Y < (sz.Y - 1) { // allow for numerical issues
+		lb.Render.LayoutStd(&lb.Sty.Text, &lb.Sty.Font, &lb.Sty.UnContext, sz)
+		if lb.Sty.Text.IsVertical() { // wrapped along Y, into columns along X
+			if lb.Render.Size.X < (sz.X - 1) {
+				lb.LayData.SetFromStyle(&lb.Sty.Layout)
+				lb.Size2DFromWH(lb.Render.Size.X, lb.Render.Size.Y)
+				return true // needs a redo!
+			}
+		} else if lb.Render.Size.Y < (sz.Y - 1) { // allow for numerical issues
 			// fmt.Printf("label layout less vert: %v  new: %v  prev: %v\n", lb.Nm, lb.Render.Size.Y, sz.Y)
 			lb.LayData.SetFromStyle(&lb.Sty.Layout)
 			lb.Size2DFromWH(lb.Render.Size.X, lb.Render.Size.Y)
@@ -11,7 +11,6 @@ import (
 	"time"
 	"unicode"
 
-	"github.com/chewxy/math32"
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/dnd"
 	"github.com/goki/gi/oswin/key"
@@ -42,26 +41,33 @@ import (
 
 // LayoutStyle contains style preferences on the layout of the element.
 type LayoutStyle struct {
-	ZIndex         int         `xml:"z-index" desc:"prop: z-index = ordering factor for rendering depth -- lower numbers rendered first -- sort children according to this factor"`
-	AlignH         Align       `xml:"horizontal-align" desc:"prop: horizontal-align = horizontal alignment -- for widget layouts -- not a standard css property"`
-	AlignV         Align       `xml:"vertical-align" desc:"prop: vertical-align = vertical alignment -- for widget layouts -- not a standard css property"`
-	PosX           units.Value `xml:"x" desc:"prop: x = horizontal position -- often superceded by layout but otherwise used"`
-	PosY           units.Value `xml:"y" desc:"prop: y = vertical position -- often superceded by layout but otherwise used"`
-	Width          units.Value `xml:"width" desc:"prop: width = specified size of element -- 0 if not specified"`
-	Height         units.Value `xml:"height" desc:"prop: height = specified size of element -- 0 if not specified"`
-	MaxWidth       units.Value `xml:"max-width" desc:"prop: max-width = specified maximum size of element -- 0  means just use other values, negative means stretch"`
-	MaxHeight      units.Value `xml:"max-height" desc:"prop: max-height = specified maximum size of element -- 0 means just use other values, negative means stretch"`
-	MinWidth       units.Value `xml:"min-width" desc:"prop: min-width = specified mimimum size of element -- 0 if not specified"`
-	MinHeight      units.Value `xml:"min-height" desc:"prop: min-height = specified mimimum size of element -- 0 if not specified"`
-	Margin         units.Value `xml:"margin" desc:"prop: margin = outer-most transparent space around box element -- todo: can be specified per side"`
-	Padding        units.Value `xml:"padding" desc:"prop: padding = transparent space around central content of box -- todo: if 4 values it is top, right, bottom, left; 3 is top, right&left, bottom; 2 is top & bottom, right and left"`
-	Overflow       Overflow    `xml:"overflow" desc:"prop: overflow = what to do with content that overflows -- default is Auto add of scrollbars as needed -- todo: can have separate -x -y values"`
-	Columns        int         `xml:"columns" alt:"grid-cols" desc:"prop: columns = number of columns to use in a grid layout -- used as a constraint in layout if individual elements do not specify their row, column positions"`
-	Row            int         `xml:"row" desc:"prop: row = specifies the row that this element should appear within a grid layout"`
-	Col            int         `xml:"col" desc:"prop: col = specifies the column that this element should appear within a grid layout"`
-	RowSpan        int         `xml:"row-span" desc:"prop: row-span = specifies the number of sequential rows that this element should occupy within a grid layout (todo: not currently supported)"`
-	ColSpan        int         `xml:"col-span" desc:"prop: col-span = specifies the number of sequential columns that this element should occupy within a grid layout"`
-	ScrollBarWidth units.Value `xml:"scrollbar-width" desc:"prop: scrollbar-width = width of a layout scrollbar"`
+	ZIndex            int         `xml:"z-index" desc:"prop: z-index = ordering factor for rendering depth -- lower numbers rendered first -- sort children according to this factor"`
+	AlignH            Align       `xml:"horizontal-align" desc:"prop: horizontal-align = horizontal alignment -- for widget layouts -- not a standard css property"`
+	AlignV            Align       `xml:"vertical-align" desc:"prop: vertical-align = vertical alignment -- for widget layouts -- not a standard css property"`
+	PosX              units.Value `xml:"x" desc:"prop: x = horizontal position -- often superceded by layout but otherwise used"`
+	PosY              units.Value `xml:"y" desc:"prop: y = vertical position -- often superceded by layout but otherwise used"`
+	Width             units.Value `xml:"width" desc:"prop: width = specified size of element -- 0 if not specified"`
+	Height            units.Value `xml:"height" desc:"prop: height = specified size of element -- 0 if not specified"`
+	MaxWidth          units.Value `xml:"max-width" desc:"prop: max-width = specified maximum size of element -- 0  means just use other values, negative means stretch"`
+	MaxHeight         units.Value `xml:"max-height" desc:"prop: max-height = specified maximum size of element -- 0 means just use other values, negative means stretch"`
+	MinWidth          units.Value `xml:"min-width" desc:"prop: min-width = specified mimimum size of element -- 0 if not specified"`
+	MinHeight         units.Value `xml:"min-height" desc:"prop: min-height = specified mimimum size of element -- 0 if not specified"`
+	Margin            units.Value `xml:"margin" desc:"prop: margin = outer-most transparent space around box element -- todo: can be specified per side"`
+	Padding           units.Value `xml:"padding" desc:"prop: padding = transparent space around central content of box -- todo: if 4 values it is top, right, bottom, left; 3 is top, right&left, bottom; 2 is top & bottom, right and left"`
+	Overflow          Overflow    `xml:"overflow" desc:"prop: overflow = what to do with content that overflows -- default is Auto add of scrollbars as needed -- todo: can have separate -x -y values"`
+	Columns           int         `xml:"columns" alt:"grid-cols" desc:"prop: columns = number of columns to use in a grid layout -- used as a constraint in layout if individual elements do not specify their row, column positions"`
+	Row               int         `xml:"row" desc:"prop: row = specifies the row (starting at 0) that this element should appear within a grid layout -- the element is explicitly placed at row, col if either is > 0, and otherwise is placed automatically in the next free cell"`
+	Col               int         `xml:"col" desc:"prop: col = specifies the column (starting at 0) that this element should appear within a grid layout -- the element is explicitly placed at row, col if either is > 0, and otherwise is placed automatically in the next free cell"`
+	RowSpan           int         `xml:"row-span" desc:"prop: row-span = specifies the number of sequential rows that this element should occupy within a grid layout"`
+	ColSpan           int         `xml:"col-span" desc:"prop: col-span = specifies the number of sequential columns that this element should occupy within a grid layout"`
+	GridArea          string      `xml:"grid-area" desc:"prop: grid-area = name of the area in the grid-template-areas of the parent grid layout that this element should occupy -- takes precedence over row, col and spans"`
+	GridTemplateCols  string      `xml:"grid-template-columns" desc:"prop: grid-template-columns = sizes of the columns of a grid layout, as a space-separated list of: auto (sized by content), a length (e.g., 10em), a fraction of the remaining space (e.g., 1fr, 2fr), minmax(min, max) of those, or repeat(n, sizes) -- any further columns are auto"`
+	GridTemplateRows  string      `xml:"grid-template-rows" desc:"prop: grid-template-rows = sizes of the rows of a grid layout, in the same format as grid-template-columns -- any further rows are auto"`
+	GridTemplateAreas string      `xml:"grid-template-areas" desc:"prop: grid-template-areas = named areas of a grid layout, with the names of the cells in each row in a quoted string as in css, e.g., 'head head' 'side main' (with double quotes), or one row per line -- a . is an unnamed cell -- elements with a grid-area name occupy all the cells of that area"`
+	JustifyContent    Align       `xml:"justify-content" desc:"prop: justify-content = alignment of the items along each line of a flow layout (LayoutHorizFlow, LayoutVertFlow), when none of them stretch: flex-start, center, flex-end, justify (extra space between items) or space-around (extra space around items)"`
+	AlignItems        Align       `xml:"align-items" desc:"prop: align-items = alignment of the items across each line of a flow layout: flex-start, center, flex-end or stretch -- items with a negative max size across lines always stretch"`
+	AlignContent      Align       `xml:"align-content" desc:"prop: align-content = alignment of the lines of a flow layout in the space across them: flex-start, center, flex-end, justify (extra space between lines), space-around (extra space around lines) or stretch (lines share the extra space)"`
+	ScrollBarWidth    units.Value `xml:"scrollbar-width" desc:"prop: scrollbar-width = width of a layout scrollbar"`
 }
 
 func (ls *LayoutStyle) Defaults() {
@@ -71,7 +77,26 @@ func (ls *LayoutStyle) Defaults() {
 	ls.ScrollBarWidth.Set(16.0, units.Px)
 }
 
+// AlignCSSNames are the css names for alignments, as used in
+// justify-content, align-items and align-content, that differ from the Align
+// names
+var AlignCSSNames = map[string]Align{
+	"start":         AlignFlexStart,
+	"flex-start":    AlignFlexStart,
+	"end":           AlignFlexEnd,
+	"flex-end":      AlignFlexEnd,
+	"space-between": AlignJustify,
+	"space-around":  AlignSpaceAround,
+}
+
 func (ls *LayoutStyle) SetStylePost(props ki.Props) {
+	for key, fld := range map[string]*Align{"justify-content": &ls.JustifyContent, "align-items": &ls.AlignItems, "align-content": &ls.AlignContent} {
+		if als, ok := props[key].(string); ok {
+			if al, ok := AlignCSSNames[als]; ok {
+				*fld = al
+			}
+		}
+	}
 }
 
 // return the alignment for given dimension
@@ -130,6 +155,9 @@ const (
 	AlignSub
 	// align to superscript
 	AlignSuper
+	// stretch to fill the available space -- for align-items and
+	// align-content in flow layouts
+	AlignStretch
 	AlignN
 )
 
@@ -214,18 +242,19 @@ func (sp SizePrefs) CanStretchNeed(d Dims2D) bool {
 // within a layout -- includes computed values of style prefs -- everything is
 // concrete and specified here, whereas style may not be fully resolved
 type LayoutData struct {
-	Size          SizePrefs `desc:"size constraints for this item -- from layout style"`
-	AllocSize     Vec2D     `desc:"allocated size of this item, by the parent layout"`
-	AllocPos      Vec2D     `desc:"position of this item, computed by adding in the AllocPosRel to parent position"`
-	AllocPosRel   Vec2D     `desc:"allocated relative position of this item, computed by the parent layout"`
-	AllocSizeOrig Vec2D     `desc:"original copy of allocated size of this item, by the parent layout -- some widgets will resize themselves within a given layout (e.g., a TextView), but still need access to their original allocated size"`
-	AllocPosOrig  Vec2D     `desc:"original copy of allocated relative position of this item, by the parent layout -- need for scrolling which can update AllocPos"`
+	Size          SizePrefs   `desc:"size constraints for this item -- from layout style"`
+	AllocSize     Vec2D       `desc:"allocated size of this item, by the parent layout"`
+	AllocPos      Vec2D       `desc:"position of this item, computed by adding in the AllocPosRel to parent position"`
+	AllocPosRel   Vec2D       `desc:"allocated relative position of this item, computed by the parent layout"`
+	AllocSizeOrig Vec2D       `desc:"original copy of allocated size of this item, by the parent layout -- some widgets will resize themselves within a given layout (e.g., a TextView), but still need access to their original allocated size"`
+	AllocPosOrig  Vec2D       `desc:"original copy of allocated relative position of this item, by the parent layout -- need for scrolling which can update AllocPos"`
+	GridPos       image.Point `desc:"position within a grid (X = col, Y = row), computed by a parent grid layout"`
+	GridSpan      image.Point `desc:"number of grid cells that we take up in each direction, computed by a parent grid layout"`
+	MoveOff       Vec2D       `desc:"offset added to the position of this item by the parent layout while its move is animated -- see Layout.AnimateMoves"`
 }
 
 // todo: not using yet:
 // Margins Margins   `desc:"margins around this item"`
-// GridPos      image.Point `desc:"position within a grid"`
-// GridSpan     image.Point `desc:"number of grid elements that we take up in each direction"`
 
 func (ld *LayoutData) Defaults() {
 }
@@ -270,6 +299,7 @@ type GridData struct {
 	SizeMax     float32
 	AllocSize   float32
 	AllocPosRel float32
+	Track       GridTrack `desc:"size of the track from the template -- auto if not specified"`
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////
@@ -295,7 +325,9 @@ var LayoutFocusNameTabMSec = 2000
 type Layout struct {
 	WidgetBase
 	Lay           Layouts             `xml:"lay" desc:"type of layout to use"`
-	Spacing       units.Value         `xml:"spacing" desc:"extra space to add between elements in the layout"`
+	Spacing       units.Value         `xml:"spacing" alt:"gap" desc:"extra space to add between elements in the layout"`
+	LineSpacing   units.Value         `xml:"line-spacing" desc:"extra space to add between the lines of a flow layout (LayoutHorizFlow, LayoutVertFlow) -- Spacing is used if 0"`
+	FlowCross     float32             `json:"-" xml:"-" desc:"total size of the lines of a flow layout across them, as wrapped in the last Layout2D pass -- used for the size in a following Size2D pass"`
 	StackTop      int                 `desc:"for Stacked layout, index of node to use as the top of the stack -- only node at this index is rendered -- if not a valid index, nothing is rendered"`
 	ChildSize     Vec2D               `json:"-" xml:"-" desc:"total max size of children as laid out"`
 	ExtraSize     Vec2D               `json:"-" xml:"-" desc:"extra size in each dim due to scrollbars we add"`
@@ -322,18 +354,19 @@ const (
 	// LayoutVert arranges items vertically in a column
 	LayoutVert
 
-	// LayoutGrid arranges items according to a regular grid
+	// LayoutGrid arranges items in a grid of rows and columns, either placed
+	// automatically in order, or explicitly by row, col or a named
+	// grid-area, with optional row-span, col-span and track sizes from
+	// grid-template-columns, grid-template-rows
 	LayoutGrid
 
-	// todo: add LayoutGridIrreg that deals with irregular grids with spans etc -- keep
-	// the basic grid for fully regular cases -- need high performance for large grids
-
-	// LayoutHorizFlow arranges items horizontally across a row, overflowing
-	// vertically as needed
+	// LayoutHorizFlow arranges items horizontally across a row, wrapping
+	// onto further rows below as needed, like a css flexbox with flex-wrap
+	// -- see justify-content, align-items, align-content and line-spacing
 	LayoutHorizFlow
 
-	// LayoutVertFlow arranges items vertically within a column, overflowing
-	// horizontally as needed
+	// LayoutVertFlow arranges items vertically within a column, wrapping
+	// onto further columns to the right as needed -- see LayoutHorizFlow
 	LayoutVertFlow
 
 	// LayoutStacked arranges items stacked on top of each other -- Top index
@@ -383,6 +416,7 @@ func initLayout() *StyledFields {
 	sf.Default = &LayoutDefault
 	sf.AddField(&LayoutDefault, "Lay")
 	sf.AddField(&LayoutDefault, "Spacing")
+	sf.AddField(&LayoutDefault, "LineSpacing")
 	return sf
 }
 
@@ -474,8 +508,6 @@ func (ly *Layout) GatherSizes() {
 	}
 }
 
-// todo: grid does not process spans at all yet -- assumes = 1
-
 // GatherSizesGrid is size first pass: gather the size information from the
 // children, grid version
 func (ly *Layout) GatherSizesGrid() {
@@ -483,37 +515,10 @@ func (ly *Layout) GatherSizesGrid() {
 		return
 	}
 
-	cols := ly.Sty.Layout.Columns
-	rows := 0
-
-	sz := len(ly.Kids)
-	// collect overal size
-	for _, c := range ly.Kids {
-		ni := c.(Node2D).AsWidget()
-		if ni == nil {
-			continue
-		}
-		lst := ni.Sty.Layout
-		if lst.Col > 0 {
-			cols = ints.MaxInt(cols, lst.Col+lst.ColSpan)
-		}
-		if lst.Row > 0 {
-			rows = ints.MaxInt(rows, lst.Row+lst.RowSpan)
-		}
-	}
-
-	if cols == 0 {
-		cols = int(math32.Sqrt(float32(sz))) // whatever -- not well defined
-	}
-	if rows == 0 {
-		rows = sz / cols
-	}
-	for rows*cols < sz { // not defined to have multiple items per cell -- make room for everyone
-		rows++
-	}
-
-	ly.GridSize.X = cols
-	ly.GridSize.Y = rows
+	trks := [RowColN][]GridTrack{ParseGridTracks(ly.Sty.Layout.GridTemplateRows), ParseGridTracks(ly.Sty.Layout.GridTemplateCols)}
+	ly.GridPlace(len(trks[Row]), len(trks[Col]))
+	cols := ly.GridSize.X
+	rows := ly.GridSize.Y
 
 	if len(ly.GridData[Row]) != rows {
 		ly.GridData[Row] = make([]GridData, rows)
@@ -522,32 +527,28 @@ func (ly *Layout) GatherSizesGrid() {
 		ly.GridData[Col] = make([]GridData, cols)
 	}
 
-	for i := range ly.GridData[Row] {
-		gd := &ly.GridData[Row][i]
-		gd.SizeNeed = 0
-		gd.SizePref = 0
-	}
-	for i := range ly.GridData[Col] {
-		gd := &ly.GridData[Col][i]
-		gd.SizeNeed = 0
-		gd.SizePref = 0
+	for rc := Row; rc < RowColN; rc++ {
+		for i := range ly.GridData[rc] {
+			gd := &ly.GridData[rc][i]
+			gd.SizeNeed = 0
+			gd.SizePref = 0
+			gd.SizeMax = 0
+			gd.Track = GridTrackAuto
+			if i < len(trks[rc]) {
+				gd.Track = trks[rc][i]
+			}
+		}
 	}
 
-	col := 0
-	row := 0
+	// first items in a single cell, in each dimension
 	for _, c := range ly.Kids {
 		ni := c.(Node2D).AsWidget()
 		if ni == nil {
 			continue
 		}
 		ni.LayData.UpdateSizes()
-		lst := ni.Sty.Layout
-		if lst.Col > 0 {
-			col = lst.Col
-		}
-		if lst.Row > 0 {
-			row = lst.Row
-		}
+		gp := ni.LayData.GridPos
+		gs := ni.LayData.GridSpan
 		// r   0   1   col X = max(ea in col) (Y = not used)
 		//   +--+---+
 		// 0 |  |   |  row Y = max(ea in row) (X = not used)
@@ -555,38 +556,53 @@ func (ly *Layout) GatherSizesGrid() {
 		// 1 |  |   |
 		//   +--+---+
 
-		rgd := &(ly.GridData[Row][row])
-		cgd := &(ly.GridData[Col][col])
-
-		// todo: need to deal with span in sums..
-		SetMax32(&(rgd.SizeNeed), ni.LayData.Size.Need.Y)
-		SetMax32(&(rgd.SizePref), ni.LayData.Size.Pref.Y)
-		SetMax32(&(cgd.SizeNeed), ni.LayData.Size.Need.X)
-		SetMax32(&(cgd.SizePref), ni.LayData.Size.Pref.X)
-
-		// for max: any -1 stretch dominates, else accumulate any max
-		if rgd.SizeMax >= 0 {
-			if ni.LayData.Size.Max.Y < 0 { // stretch
-				rgd.SizeMax = -1
-			} else {
-				SetMax32(&(rgd.SizeMax), ni.LayData.Size.Max.Y)
+		if gs.Y == 1 {
+			rgd := &(ly.GridData[Row][gp.Y])
+			SetMax32(&(rgd.SizeNeed), ni.LayData.Size.Need.Y)
+			SetMax32(&(rgd.SizePref), ni.LayData.Size.Pref.Y)
+			// for max: any -1 stretch dominates, else accumulate any max
+			if rgd.SizeMax >= 0 {
+				if ni.LayData.Size.Max.Y < 0 { // stretch
+					rgd.SizeMax = -1
+				} else {
+					SetMax32(&(rgd.SizeMax), ni.LayData.Size.Max.Y)
+				}
 			}
 		}
-		if cgd.SizeMax >= 0 {
-			if ni.LayData.Size.Max.Y < 0 { // stretch
-				cgd.SizeMax = -1
-			} else {
-				SetMax32(&(cgd.SizeMax), ni.LayData.Size.Max.X)
+		if gs.X == 1 {
+			cgd := &(ly.GridData[Col][gp.X])
+			SetMax32(&(cgd.SizeNeed), ni.LayData.Size.Need.X)
+			SetMax32(&(cgd.SizePref), ni.LayData.Size.Pref.X)
+			if cgd.SizeMax >= 0 {
+				if ni.LayData.Size.Max.X < 0 { // stretch
+					cgd.SizeMax = -1
+				} else {
+					SetMax32(&(cgd.SizeMax), ni.LayData.Size.Max.X)
+				}
 			}
 		}
+	}
 
-		col++
-		if col >= cols { // todo: really only works if NO items specify row,col or ALL do..
-			col = 0
-			row++
-			if row >= rows { // wrap-around.. no other good option
-				row = 0
-			}
+	for rc := Row; rc < RowColN; rc++ {
+		for i := range ly.GridData[rc] {
+			ly.GridData[rc][i].SetTrackSizes(&ly.Sty.UnContext)
+		}
+	}
+
+	// then items spanning multiple cells
+	for _, c := range ly.Kids {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		gp := ni.LayData.GridPos
+		gs := ni.LayData.GridSpan
+		sz := &ni.LayData.Size
+		if gs.Y > 1 {
+			GridSpanSizes(ly.GridData[Row], gp.Y, gs.Y, sz.Need.Y, sz.Pref.Y, sz.Max.Y, ly.Spacing.Dots)
+		}
+		if gs.X > 1 {
+			GridSpanSizes(ly.GridData[Col], gp.X, gs.X, sz.Need.X, sz.Pref.X, sz.Max.X, ly.Spacing.Dots)
 		}
 	}
 
@@ -630,6 +646,74 @@ func (ly *Layout) GatherSizesGrid() {
 	}
 }
 
+// FlowDims returns the dimension along the lines of a flow layout, and the
+// one across them
+func (ly *Layout) FlowDims() (along, across Dims2D) {
+	if ly.Lay == LayoutVertFlow {
+		return Y, X
+	}
+	return X, Y
+}
+
+// LineSpacingDots returns the spacing between the lines of a flow layout
+func (ly *Layout) LineSpacingDots() float32 {
+	if ly.LineSpacing.Dots > 0 {
+		return ly.LineSpacing.Dots
+	}
+	return ly.Spacing.Dots
+}
+
+// GatherSizesFlow is size first pass: gather the size information from the
+// children, flow version -- the preferred size along the lines is that of
+// all items in one line, and the needed size is that of the largest item.
+// Across the lines, the size is that of one line, except in an iteration
+// after the layout has wrapped into more lines than fit (iter > 0), where it
+// is that of all the lines (FlowCross).
+func (ly *Layout) GatherSizesFlow(iter int) {
+	sz := len(ly.Kids)
+	if sz == 0 {
+		return
+	}
+	along, across := ly.FlowDims()
+
+	var sumPref, maxNeed, maxPref Vec2D
+	for _, c := range ly.Kids {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		ni.LayData.UpdateSizes()
+		sumPref = sumPref.Add(ni.LayData.Size.Pref)
+		maxNeed = maxNeed.Max(ni.LayData.Size.Need)
+		maxPref = maxPref.Max(ni.LayData.Size.Pref)
+	}
+
+	spc := ly.Sty.BoxSpace()
+	if ly.LayData.Size.Pref.Dim(along) == 0 {
+		ly.LayData.Size.Need.SetMaxDim(along, maxNeed.Dim(along)+2.0*spc)
+		ly.LayData.Size.Pref.SetMaxDim(along, sumPref.Dim(along)+float32(sz-1)*ly.Spacing.Dots+2.0*spc)
+	} else { // use target size from style
+		ly.LayData.Size.Need.SetDim(along, ly.LayData.Size.Pref.Dim(along))
+	}
+	if ly.LayData.Size.Pref.Dim(across) == 0 {
+		need := maxNeed.Dim(across) + 2.0*spc
+		pref := maxPref.Dim(across) + 2.0*spc
+		if iter > 0 && ly.FlowCross > 0 {
+			need = ly.FlowCross
+			pref = ly.FlowCross
+		}
+		ly.LayData.Size.Need.SetMaxDim(across, need)
+		ly.LayData.Size.Pref.SetMaxDim(across, pref)
+	} else {
+		ly.LayData.Size.Need.SetDim(across, ly.LayData.Size.Pref.Dim(across))
+	}
+
+	ly.LayData.UpdateSizes() // enforce max and normal ordering, etc
+	if Layout2DTrace {
+		fmt.Printf("Size:   %v gather sizes flow need: %v, pref: %v\n", ly.PathUnique(), ly.LayData.Size.Need, ly.LayData.Size.Pref)
+	}
+}
+
 // AllocFromParent: if we are not a child of a layout, then get allocation
 // from a parent obj that has a layout size
 func (ly *Layout) AllocFromParent() {
@@ -704,7 +788,7 @@ func (ly *Layout) LayoutSharedDimImpl(avail, need, pref, max, spc float32, al Al
 			pos += 0.5 * extra
 		} else if IsAlignEnd(al) {
 			pos += extra
-		} else if al == AlignJustify { // treat justify as stretch
+		} else if al == AlignJustify || al == AlignStretch { // treat justify as stretch
 			size += extra
 		}
 	}
@@ -751,7 +835,27 @@ func (ly *Layout) LayoutAlongDim(dim Dims2D) {
 	avail := ly.LayData.AllocSize.Dim(dim) - exspc
 	pref := ly.LayData.Size.Pref.Dim(dim) - exspc
 	need := ly.LayData.Size.Need.Dim(dim) - exspc
+	if IsAlignMiddle(al) { // middle is the default vertical-align, so it is not used along dim
+		al = AlignLeft
+	}
+
+	items := make([]*WidgetBase, 0, sz)
+	for _, c := range ly.Kids {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		items = append(items, ni)
+	}
+	ly.LayoutAlongDimItems(items, dim, spc, avail, pref, need, al)
+}
 
+// LayoutAlongDimItems lays out given items along given dim, starting at
+// given position, within given available space, with given total pref and
+// need sizes of the items (excluding spacing) -- items that stretch get any
+// extra space, and otherwise it is allocated according to the alignment.
+func (ly *Layout) LayoutAlongDimItems(items []*WidgetBase, dim Dims2D, start, avail, pref, need float32, al Align) {
+	sz := len(items)
 	targ := pref
 	usePref := true
 	extra := avail - targ
@@ -768,11 +872,7 @@ func (ly *Layout) LayoutAlongDim(dim Dims2D) {
 	stretchMax := false         // only stretch Max = neg
 	addSpace := false           // apply extra toward spacing -- for justify
 	if usePref && extra > 0.0 { // have some stretch extra
-		for _, c := range ly.Kids {
-			ni := c.(Node2D).AsWidget()
-			if ni == nil {
-				continue
-			}
+		for _, ni := range items {
 			if ni.LayData.Size.HasMaxStretch(dim) { // negative = stretch
 				nstretch++
 				stretchTot += ni.LayData.Size.Pref.Dim(dim)
@@ -782,11 +882,7 @@ func (ly *Layout) LayoutAlongDim(dim Dims2D) {
 			stretchMax = true // only stretch those marked as infinitely stretchy
 		}
 	} else if extra > 0.0 { // extra relative to Need
-		for _, c := range ly.Kids {
-			ni := c.(Node2D).AsWidget()
-			if ni == nil {
-				continue
-			}
+		for _, ni := range items {
 			if ni.LayData.Size.HasMaxStretch(dim) || ni.LayData.Size.CanStretchNeed(dim) {
 				nstretch++
 				stretchTot += ni.LayData.Size.Pref.Dim(dim)
@@ -797,30 +893,36 @@ func (ly *Layout) LayoutAlongDim(dim Dims2D) {
 		}
 	}
 
+	// now arrange everyone
+	pos := start
+
 	extraSpace := float32(0.0)
-	if sz > 1 && extra > 0.0 && al == AlignJustify && !stretchNeed && !stretchMax {
-		addSpace = true
-		// if neither, then just distribute as spacing for justify
-		extraSpace = extra / float32(sz-1)
+	if extra > 0.0 && !stretchNeed && !stretchMax {
+		if sz > 1 && al == AlignJustify {
+			addSpace = true
+			// if neither, then just distribute as spacing for justify
+			extraSpace = extra / float32(sz-1)
+		} else if sz > 0 && al == AlignSpaceAround {
+			addSpace = true
+			extraSpace = extra / float32(sz)
+			pos += 0.5 * extraSpace
+		}
 	}
 
-	// now arrange everyone
-	pos := spc
-
 	// todo: need a direction setting too
-	if IsAlignEnd(al) && !stretchNeed && !stretchMax {
-		pos += extra
+	if !stretchNeed && !stretchMax {
+		if IsAlignEnd(al) {
+			pos += extra
+		} else if IsAlignMiddle(al) {
+			pos += 0.5 * extra
+		}
 	}
 
 	if Layout2DTrace {
-		fmt.Printf("Layout: %v Along dim %v, avail: %v elspc: %v need: %v pref: %v targ: %v, extra %v, strMax: %v, strNeed: %v, nstr %v, strTot %v\n", ly.PathUnique(), dim, avail, elspc, need, pref, targ, extra, stretchMax, stretchNeed, nstretch, stretchTot)
+		fmt.Printf("Layout: %v Along dim %v, avail: %v need: %v pref: %v targ: %v, extra %v, strMax: %v, strNeed: %v, nstr %v, strTot %v\n", ly.PathUnique(), dim, avail, need, pref, targ, extra, stretchMax, stretchNeed, nstretch, stretchTot)
 	}
 
-	for i, c := range ly.Kids {
-		ni := c.(Node2D).AsWidget()
-		if ni == nil {
-			continue
-		}
+	for i, ni := range items {
 		size := ni.LayData.Size.Need.Dim(dim)
 		if usePref {
 			size = ni.LayData.Size.Pref.Dim(dim)
@@ -833,7 +935,7 @@ func (ly *Layout) LayoutAlongDim(dim Dims2D) {
 			if ni.LayData.Size.HasMaxStretch(dim) || ni.LayData.Size.CanStretchNeed(dim) {
 				size += extra * (ni.LayData.Size.Pref.Dim(dim) / stretchTot)
 			}
-		} else if addSpace { // implies align justify
+		} else if addSpace { // implies align justify or space around
 			if i > 0 {
 				pos += extraSpace
 			}
@@ -875,6 +977,27 @@ func (ly *Layout) LayoutGridDim(rowcol RowCol, dim Dims2D) {
 	}
 	extra = Max32(extra, 0.0) // no negatives
 
+	hasFr := false
+	for _, gd := range gds {
+		if gd.Track.Fr > 0 {
+			hasFr = true
+			break
+		}
+	}
+	if hasFr { // flexible tracks take all the extra
+		GridFrSizes(gds, avail, usePref)
+		pos := spc
+		for i := range gds {
+			gd := &gds[i]
+			gd.AllocPosRel = pos
+			if Layout2DTrace {
+				fmt.Printf("Grid %v pos: %v, size: %v, fr: %v\n", rowcol, pos, gd.AllocSize, gd.Track.Fr)
+			}
+			pos += gd.AllocSize + ly.Spacing.Dots
+		}
+		return
+	}
+
 	nstretch := 0
 	stretchTot := float32(0.0)
 	stretchNeed := false        // stretch relative to need
@@ -940,6 +1063,9 @@ func (ly *Layout) LayoutGridDim(rowcol RowCol, dim Dims2D) {
 				pos += extraSpace
 			}
 		}
+		if gd.SizeMax > 0 && !gd.Track.MaxAuto { // max from template
+			size = Min32(size, gd.SizeMax)
+		}
 
 		gd.AllocSize = size
 		gd.AllocPosRel = pos
@@ -960,10 +1086,6 @@ func (ly *Layout) LayoutGrid() {
 	ly.LayoutGridDim(Row, Y)
 	ly.LayoutGridDim(Col, X)
 
-	col := 0
-	row := 0
-	cols := ly.GridSize.X
-	rows := ly.GridSize.Y
 	for _, c := range ly.Kids {
 		ni := c.(Node2D).AsWidget()
 		if ni == nil {
@@ -971,52 +1093,145 @@ func (ly *Layout) LayoutGrid() {
 		}
 
 		lst := ni.Sty.Layout
-		if lst.Col > 0 {
-			col = lst.Col
-		}
-		if lst.Row > 0 {
-			row = lst.Row
+		gp := ni.LayData.GridPos
+		gs := ni.LayData.GridSpan
+		if gp.X+gs.X > len(ly.GridData[Col]) || gp.Y+gs.Y > len(ly.GridData[Row]) {
+			continue // added since sizing
 		}
 
 		{ // col, X dim
 			dim := X
-			gd := ly.GridData[Col][col]
-			avail := gd.AllocSize
+			sgd := ly.GridData[Col][gp.X]
+			egd := ly.GridData[Col][gp.X+gs.X-1]
+			avail := egd.AllocPosRel + egd.AllocSize - sgd.AllocPosRel
 			al := lst.AlignDim(dim)
 			pref := ni.LayData.Size.Pref.Dim(dim)
 			need := ni.LayData.Size.Need.Dim(dim)
 			max := ni.LayData.Size.Max.Dim(dim)
 			pos, size := ly.LayoutSharedDimImpl(avail, need, pref, max, 0, al)
 			ni.LayData.AllocSize.SetDim(dim, size)
-			ni.LayData.AllocPosRel.SetDim(dim, pos+gd.AllocPosRel)
+			ni.LayData.AllocPosRel.SetDim(dim, pos+sgd.AllocPosRel)
 
 		}
 		{ // row, Y dim
 			dim := Y
-			gd := ly.GridData[Row][row]
-			avail := gd.AllocSize
+			sgd := ly.GridData[Row][gp.Y]
+			egd := ly.GridData[Row][gp.Y+gs.Y-1]
+			avail := egd.AllocPosRel + egd.AllocSize - sgd.AllocPosRel
 			al := lst.AlignDim(dim)
 			pref := ni.LayData.Size.Pref.Dim(dim)
 			need := ni.LayData.Size.Need.Dim(dim)
 			max := ni.LayData.Size.Max.Dim(dim)
 			pos, size := ly.LayoutSharedDimImpl(avail, need, pref, max, 0, al)
 			ni.LayData.AllocSize.SetDim(dim, size)
-			ni.LayData.AllocPosRel.SetDim(dim, pos+gd.AllocPosRel)
+			ni.LayData.AllocPosRel.SetDim(dim, pos+sgd.AllocPosRel)
 		}
 
 		if Layout2DTrace {
-			fmt.Printf("Layout: %v grid col: %v row: %v pos: %v size: %v\n", ly.PathUnique(), col, row, ni.LayData.AllocPosRel, ni.LayData.AllocSize)
+			fmt.Printf("Layout: %v grid col: %v row: %v span: %v pos: %v size: %v\n", ly.PathUnique(), gp.X, gp.Y, gs, ni.LayData.AllocPosRel, ni.LayData.AllocSize)
 		}
+	}
+}
 
-		col++
-		if col >= cols { // todo: really only works if NO items specify row,col or ALL do..
-			col = 0
-			row++
-			if row >= rows { // wrap-around.. no other good option
-				row = 0
-			}
+// LayoutFlow lays out the children of a flow layout: items are placed along
+// lines in order, starting a new line when the next one does not fit in the
+// available space at its preferred size.  Along each line, items are laid
+// out as in LayoutAlongDim, with the JustifyContent alignment, and each line
+// is as large across as its largest item, with items aligned within it by
+// AlignItems.  The lines are aligned in the space across them by
+// AlignContent.  Returns true if the lines need more space across than is
+// allocated, in the first iteration, so that another iteration is needed to
+// get that space (see GatherSizesFlow).
+func (ly *Layout) LayoutFlow(iter int) bool {
+	if len(ly.Kids) == 0 {
+		return false
+	}
+	along, across := ly.FlowDims()
+	lst := &ly.Sty.Layout
+	spc := ly.Sty.BoxSpace()
+	avail := ly.LayData.AllocSize.Dim(along) - 2.0*spc
+	availX := ly.LayData.AllocSize.Dim(across) - 2.0*spc
+	lnspc := ly.LineSpacingDots()
+
+	// break into lines
+	var lines [][]*WidgetBase
+	var line []*WidgetBase
+	lsz := float32(0)
+	for _, c := range ly.Kids {
+		ni := c.(Node2D).AsWidget()
+		if ni == nil {
+			continue
+		}
+		isz := ni.LayData.Size.Pref.Dim(along)
+		if len(line) > 0 && lsz+ly.Spacing.Dots+isz > avail+0.1 {
+			lines = append(lines, line)
+			line = nil
+			lsz = 0
+		}
+		if len(line) > 0 {
+			lsz += ly.Spacing.Dots
+		}
+		line = append(line, ni)
+		lsz += isz
+	}
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+	nln := len(lines)
+
+	// lay out along each line, and get the size across it
+	lnsz := make([]float32, nln)
+	tot := float32(nln-1) * lnspc
+	for li, ln := range lines {
+		var pref, need float32
+		for _, ni := range ln {
+			pref += ni.LayData.Size.Pref.Dim(along)
+			need += ni.LayData.Size.Need.Dim(along)
+			lnsz[li] = Max32(lnsz[li], ni.LayData.Size.Pref.Dim(across))
+		}
+		elspc := float32(len(ln)-1) * ly.Spacing.Dots
+		ly.LayoutAlongDimItems(ln, along, spc, avail-elspc, pref, need, lst.JustifyContent)
+		tot += lnsz[li]
+	}
+
+	// align the lines across
+	extra := Max32(availX-tot, 0)
+	pos := spc
+	lnadd := float32(0) // space added after each line
+	switch {
+	case lst.AlignContent == AlignStretch:
+		for li := range lnsz {
+			lnsz[li] += extra / float32(nln)
+		}
+	case lst.AlignContent == AlignJustify:
+		if nln > 1 {
+			lnadd = extra / float32(nln-1)
+		}
+	case lst.AlignContent == AlignSpaceAround:
+		lnadd = extra / float32(nln)
+		pos += 0.5 * lnadd
+	case IsAlignMiddle(lst.AlignContent):
+		pos += 0.5 * extra
+	case IsAlignEnd(lst.AlignContent):
+		pos += extra
+	}
+	for li, ln := range lines {
+		for _, ni := range ln {
+			pref := ni.LayData.Size.Pref.Dim(across)
+			need := ni.LayData.Size.Need.Dim(across)
+			max := ni.LayData.Size.Max.Dim(across)
+			ipos, isz := ly.LayoutSharedDimImpl(lnsz[li], need, pref, max, 0, lst.AlignItems)
+			ni.LayData.AllocSize.SetDim(across, isz)
+			ni.LayData.AllocPosRel.SetDim(across, pos+ipos)
+		}
+		if Layout2DTrace {
+			fmt.Printf("Layout: %v flow line: %v items: %v pos: %v size: %v\n", ly.PathUnique(), li, len(ln), pos, lnsz[li])
 		}
+		pos += lnsz[li] + lnspc + lnadd
 	}
+
+	ly.FlowCross = tot + 2.0*spc
+	return iter == 0 && tot > availX+0.5
 }
 
 // FinalizeLayout is final pass through children to finalize the layout,
@@ -1301,13 +1516,33 @@ func (ly *Layout) Move2DChildren(delta image.Point) {
 	} else {
 		for _, kid := range ly.Kids {
 			nii, _ := KiToNode2D(kid)
-			if nii != nil {
-				nii.Move2D(delta, cbb)
+			if nii == nil {
+				continue
+			}
+			kdelta := delta
+			if ni := nii.AsWidget(); ni != nil {
+				kdelta = delta.Add(ni.LayData.MoveOff.ToPoint())
 			}
+			nii.Move2D(kdelta, cbb)
 		}
 	}
 }
 
+// HasMoveOff returns true if any of the children has a MoveOff from an
+// animated move
+func (ly *Layout) HasMoveOff() bool {
+	for _, c := range ly.Kids {
+		nii, _ := KiToNode2D(c)
+		if nii == nil {
+			continue
+		}
+		if ni := nii.AsWidget(); ni != nil && !ni.LayData.MoveOff.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
 // AutoScrollRate determines the rate of auto-scrolling of layouts
 var AutoScrollRate = float32(1.0)
 
@@ -1509,6 +1744,29 @@ func (ly *Layout) ScrollDimToCenter(dim Dims2D, pos int) bool {
 	return true
 }
 
+// AnimateScroll smoothly scrolls along given dimension to given scroll value
+// over given duration, with given easing function (linear if nil) --
+// returns false if there is no scrollbar in that dimension
+func (ly *Layout) AnimateScroll(dim Dims2D, val float32, dur time.Duration, ease EaseFunc) bool {
+	if !ly.HasScroll[dim] {
+		return false
+	}
+	sc := ly.Scrolls[dim]
+	win := ly.ParentWindow()
+	if win == nil || dur <= 0 {
+		sc.SetValueAction(val)
+		return true
+	}
+	from := sc.Value
+	win.Anims.Start(&Anim{Recv: ly.This(), Name: "scroll-" + dim.String(), Dur: dur, Ease: ease,
+		Func: func(p float32) {
+			if ly.HasScroll[dim] { // could have changed in the meantime
+				ly.Scrolls[dim].SetValueAction(InterpolateFloat32(from, val, p))
+			}
+		}})
+	return true
+}
+
 // ChildWithFocus returns a direct child of this layout that either is the
 // current window focus item, or contains that focus item (along with its
 // index) -- nil, -1 if none.
@@ -1544,7 +1802,7 @@ func (ly *Layout) FocusNextChild(updn bool) bool {
 	cur := win.CurFocus()
 	nxti := idx + 1
 	if ly.Lay == LayoutGrid && updn {
-		nxti = idx + ly.Sty.Layout.Columns
+		nxti = idx + ly.GridSize.X
 	}
 	did := false
 	if nxti < sz {
@@ -1573,7 +1831,7 @@ func (ly *Layout) FocusPrevChild(updn bool) bool {
 	cur := win.CurFocus()
 	nxti := idx - 1
 	if ly.Lay == LayoutGrid && updn {
-		nxti = idx - ly.Sty.Layout.Columns
+		nxti = idx - ly.GridSize.X
 	}
 	did := false
 	if nxti >= 0 {
@@ -1805,9 +2063,12 @@ func (ly *Layout) Style2D() {
 
 func (ly *Layout) Size2D(iter int) {
 	ly.InitLayout2D()
-	if ly.Lay == LayoutGrid {
+	switch ly.Lay {
+	case LayoutGrid:
 		ly.GatherSizesGrid()
-	} else {
+	case LayoutHorizFlow, LayoutVertFlow:
+		ly.GatherSizesFlow(iter)
+	default:
 		ly.GatherSizes()
 	}
 }
@@ -1820,6 +2081,7 @@ func (ly *Layout) Layout2D(parBBox image.Rectangle, iter int) bool {
 	//}
 	ly.AllocFromParent()                 // in case we didn't get anything
 	ly.Layout2DBase(parBBox, true, iter) // init style
+	flowRedo := false
 	switch ly.Lay {
 	case LayoutHoriz:
 		ly.LayoutAlongDim(X)
@@ -1829,6 +2091,8 @@ func (ly *Layout) Layout2D(parBBox image.Rectangle, iter int) bool {
 		ly.LayoutSharedDim(X)
 	case LayoutGrid:
 		ly.LayoutGrid()
+	case LayoutHorizFlow, LayoutVertFlow:
+		flowRedo = ly.LayoutFlow(iter)
 	case LayoutStacked:
 		ly.LayoutSharedDim(X)
 		ly.LayoutSharedDim(Y)
@@ -1838,10 +2102,13 @@ func (ly *Layout) Layout2D(parBBox image.Rectangle, iter int) bool {
 	ly.FinalizeLayout()
 	ly.ManageOverflow()
 	ly.NeedsRedo = ly.Layout2DChildren(iter) // layout done with canonical positions
+	if flowRedo {
+		ly.NeedsRedo = true
+	}
 
 	if !ly.NeedsRedo || iter == 1 {
 		delta := ly.Move2DDelta(image.ZP)
-		if delta != image.ZP {
+		if delta != image.ZP || ly.HasMoveOff() {
 			ly.Move2DChildren(delta) // move is a separate step
 		}
 	}
@@ -1901,6 +2168,60 @@ func (ly *Layout) HasFocus2D() bool {
 	return ly.ContainsFocus() // needed for getting key events
 }
 
+// AnimateMoves calls given change function, which changes the children of
+// the layout in a way that moves them, e.g., by adding, deleting, reordering
+// or resizing them, and then re-renders the layout, with the children that
+// were already there sliding from their previous to their new positions over
+// given duration, with given easing function (linear if nil)
+func (ly *Layout) AnimateMoves(dur time.Duration, ease EaseFunc, change func()) {
+	win := ly.ParentWindow()
+	if win == nil || dur <= 0 {
+		change()
+		return
+	}
+	prv := make(map[*WidgetBase]Vec2D, len(ly.Kids))
+	for _, c := range ly.Kids {
+		nii, _ := KiToNode2D(c)
+		if nii == nil {
+			continue
+		}
+		if ni := nii.AsWidget(); ni != nil {
+			prv[ni] = ni.LayData.AllocPos // includes any current MoveOff
+			ni.LayData.MoveOff = Vec2DZero
+		}
+	}
+	updt := ly.UpdateStart()
+	change()
+	ly.UpdateEndNoSig(updt)
+	ly.ReRender2DTree() // new positions
+
+	offs := make(map[*WidgetBase]Vec2D, len(prv))
+	for _, c := range ly.Kids {
+		nii, _ := KiToNode2D(c)
+		if nii == nil {
+			continue
+		}
+		ni := nii.AsWidget()
+		if pos, ok := prv[ni]; ok && pos != ni.LayData.AllocPos {
+			offs[ni] = pos.Sub(ni.LayData.AllocPos)
+		}
+	}
+	if len(offs) == 0 {
+		return
+	}
+	an := win.Anims.Start(&Anim{Recv: ly.This(), Name: "moves", Dur: dur, Ease: ease,
+		Func: func(p float32) {
+			for ni, off := range offs {
+				if ni.This() != nil {
+					ni.LayData.MoveOff = off.MulVal(1 - p)
+				}
+			}
+			ly.Move2DTree()
+			ly.UpdateSig()
+		}})
+	an.Func(0) // start at the previous positions
+}
+
 ///////////////////////////////////////////////////////////
 //    Stretch and Space -- dummy elements for layouts
 
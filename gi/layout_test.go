@@ -0,0 +1,64 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"testing"
+)
+
+// testFlowLayout returns a horizontal flow layout of given size, with 10
+// spacing and four items of pref size 40 x 10, need 20 x 10
+func testFlowLayout(sz Vec2D) *Layout {
+	ly := &Layout{}
+	ly.InitName(ly, "flow")
+	ly.Lay = LayoutHorizFlow
+	ly.Spacing.Dots = 10
+	ly.LayData.AllocSize = sz
+	for i := 0; i < 4; i++ {
+		fr := ly.AddNewChild(KiT_Frame, "fr").(*Frame)
+		fr.LayData.Size.Pref = Vec2D{40, 10}
+		fr.LayData.Size.Need = Vec2D{20, 10}
+	}
+	return ly
+}
+
+func testFlowPos(ly *Layout) []Vec2D {
+	var pos []Vec2D
+	for _, c := range ly.Kids {
+		pos = append(pos, c.(Node2D).AsWidget().LayData.AllocPosRel)
+	}
+	return pos
+}
+
+func TestLayoutFlow(t *testing.T) {
+	ly := testFlowLayout(Vec2D{100, 100})
+	if ly.LayoutFlow(0) {
+		t.Errorf("flow needs redo")
+	}
+	want := []Vec2D{{0, 0}, {50, 0}, {0, 20}, {50, 20}}
+	for i, p := range testFlowPos(ly) {
+		if p != want[i] {
+			t.Errorf("flow pos %v: %v, want: %v", i, p, want[i])
+		}
+	}
+	if ly.FlowCross != 30 {
+		t.Errorf("flow cross: %v", ly.FlowCross)
+	}
+
+	ly.Sty.Layout.JustifyContent = AlignJustify
+	ly.Sty.Layout.AlignContent = AlignFlexEnd
+	ly.LayoutFlow(0)
+	want = []Vec2D{{0, 70}, {60, 70}, {0, 90}, {60, 90}}
+	for i, p := range testFlowPos(ly) {
+		if p != want[i] {
+			t.Errorf("justified flow pos %v: %v, want: %v", i, p, want[i])
+		}
+	}
+
+	ly = testFlowLayout(Vec2D{100, 20})
+	if !ly.LayoutFlow(0) || ly.LayoutFlow(1) {
+		t.Errorf("flow should need redo only in first iteration")
+	}
+}
@@ -0,0 +1,982 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"math/bits"
+	"sort"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// This file contains the reading of the OpenType layout tables (GSUB, GPOS,
+// GDEF) and the application of their lookups to a buffer of glyphs, which
+// is used by the text shaping in shape.go.  It supports the subset of the
+// lookup types that matters for the scripts we shape: single, multiple,
+// alternate, ligature and (chaining) contextual substitution, and single,
+// pair, mark-to-base, mark-to-ligature, mark-to-mark and (chaining)
+// contextual positioning, plus the extension lookups for all of these.
+// Not supported: reverse chaining substitution and cursive attachment.
+// See: https://docs.microsoft.com/en-us/typography/opentype/spec/chapter2
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  otTable
+
+// otTable is a raw OpenType table, or a sub-table within one, with
+// big-endian accessors that return 0 for any out-of-range read, so that a
+// malformed font just does not shape, instead of panicking
+type otTable []byte
+
+func (t otTable) u16(off int) uint16 {
+	if off < 0 || off+2 > len(t) {
+		return 0
+	}
+	return uint16(t[off])<<8 | uint16(t[off+1])
+}
+
+func (t otTable) i16(off int) int16 {
+	return int16(t.u16(off))
+}
+
+func (t otTable) u32(off int) uint32 {
+	return uint32(t.u16(off))<<16 | uint32(t.u16(off+2))
+}
+
+// tag returns the 4-byte tag at given offset
+func (t otTable) tag(off int) string {
+	if off < 0 || off+4 > len(t) {
+		return ""
+	}
+	return string(t[off : off+4])
+}
+
+// sub returns the sub-table at given offset from the start of this table
+// -- an offset of 0 is a null offset and returns nil
+func (t otTable) sub(off int) otTable {
+	if off <= 0 || off >= len(t) {
+		return nil
+	}
+	return t[off:]
+}
+
+// sub16 returns the sub-table at the 16-bit offset stored at given offset
+func (t otTable) sub16(off int) otTable {
+	return t.sub(int(t.u16(off)))
+}
+
+// coverage returns the coverage index of glyph in this Coverage table, or
+// -1 if it is not covered
+func (t otTable) coverage(g sfnt.GlyphIndex) int {
+	switch t.u16(0) {
+	case 1:
+		n := int(t.u16(2))
+		i := sort.Search(n, func(i int) bool { return sfnt.GlyphIndex(t.u16(4+2*i)) >= g })
+		if i < n && sfnt.GlyphIndex(t.u16(4+2*i)) == g {
+			return i
+		}
+	case 2:
+		n := int(t.u16(2))
+		i := sort.Search(n, func(i int) bool { return sfnt.GlyphIndex(t.u16(4+6*i+2)) >= g })
+		if i < n && sfnt.GlyphIndex(t.u16(4+6*i)) <= g {
+			return int(t.u16(4+6*i+4)) + int(g) - int(t.u16(4+6*i))
+		}
+	}
+	return -1
+}
+
+// class returns the class of glyph in this ClassDef table -- 0 if not
+// defined
+func (t otTable) class(g sfnt.GlyphIndex) uint16 {
+	switch t.u16(0) {
+	case 1:
+		st := sfnt.GlyphIndex(t.u16(2))
+		n := int(t.u16(4))
+		if g >= st && int(g-st) < n {
+			return t.u16(6 + 2*int(g-st))
+		}
+	case 2:
+		n := int(t.u16(2))
+		i := sort.Search(n, func(i int) bool { return sfnt.GlyphIndex(t.u16(4+6*i+2)) >= g })
+		if i < n && sfnt.GlyphIndex(t.u16(4+6*i)) <= g {
+			return t.u16(4 + 6*i + 4)
+		}
+	}
+	return 0
+}
+
+// anchor returns the x, y coordinates of this Anchor table, in font units
+func (t otTable) anchor() (x, y int32) {
+	return int32(t.i16(2)), int32(t.i16(4))
+}
+
+// otValueSize returns the size in bytes of a ValueRecord with given format
+func otValueSize(vf uint16) int {
+	return 2 * bits.OnesCount16(vf)
+}
+
+// value reads the ValueRecord with given format at given offset, returning
+// the x, y placement and advance adjustments -- device tables are ignored
+func (t otTable) value(off int, vf uint16) (xp, yp, xa, ya int32) {
+	vals := [4]int32{}
+	for i := uint(0); i < 4; i++ {
+		if vf&(1<<i) != 0 {
+			vals[i] = int32(t.i16(off))
+			off += 2
+		}
+	}
+	return vals[0], vals[1], vals[2], vals[3]
+}
+
+// otTables returns the tables of the (first) font in given font file data,
+// by tag
+func otTables(data []byte) map[string]otTable {
+	t := otTable(data)
+	off := 0
+	if t.tag(0) == "ttcf" {
+		off = int(t.u32(12))
+	}
+	n := int(t.u16(off + 4))
+	tabs := make(map[string]otTable, n)
+	for i := 0; i < n; i++ {
+		rec := off + 12 + 16*i
+		if rec+16 > len(data) {
+			break
+		}
+		to, tl := int(t.u32(rec+8)), int(t.u32(rec+12))
+		if to <= 0 || tl <= 0 || to+tl > len(data) {
+			continue
+		}
+		tabs[t.tag(rec)] = t[to : to+tl]
+	}
+	return tabs
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  otLayout
+
+// otLookup is one lookup of a GSUB or GPOS table, with any extension
+// sub-tables resolved
+type otLookup struct {
+	Type    uint16
+	Flag    uint16
+	Subs    []otTable
+	MarkSet uint16
+}
+
+// lookup flag bits
+const (
+	otIgnoreBase      = 0x0002
+	otIgnoreLigatures = 0x0004
+	otIgnoreMarks     = 0x0008
+	otUseMarkSet      = 0x0010
+	otMarkAttachType  = 0xFF00
+)
+
+// otLayout is a GSUB or GPOS table, with its script, feature and lookup
+// lists
+type otLayout struct {
+	IsGPOS   bool
+	Scripts  otTable
+	Features otTable
+	Lookups  otTable
+	lookups  []*otLookup
+}
+
+// newOTLayout returns the layout for given GSUB or GPOS table, or nil if it
+// is empty
+func newOTLayout(t otTable, gpos bool) *otLayout {
+	if len(t) < 10 {
+		return nil
+	}
+	l := &otLayout{IsGPOS: gpos, Scripts: t.sub16(4), Features: t.sub16(6), Lookups: t.sub16(8)}
+	if l.Lookups == nil {
+		return nil
+	}
+	l.lookups = make([]*otLookup, l.Lookups.u16(0))
+	return l
+}
+
+// extType is the lookup type of extension lookups
+func (l *otLayout) extType() uint16 {
+	if l.IsGPOS {
+		return 9
+	}
+	return 7
+}
+
+// Lookup returns the lookup at given index, parsing it the first time
+func (l *otLayout) Lookup(li int) *otLookup {
+	if li < 0 || li >= len(l.lookups) {
+		return nil
+	}
+	if lk := l.lookups[li]; lk != nil {
+		return lk
+	}
+	lt := l.Lookups.sub16(2 + 2*li)
+	lk := &otLookup{Type: lt.u16(0), Flag: lt.u16(2)}
+	n := int(lt.u16(4))
+	ext := lk.Type == l.extType()
+	for i := 0; i < n; i++ {
+		st := lt.sub16(6 + 2*i)
+		if st == nil {
+			continue
+		}
+		if ext {
+			lk.Type = st.u16(2)
+			st = st.sub(int(st.u32(4)))
+			if st == nil {
+				continue
+			}
+		}
+		lk.Subs = append(lk.Subs, st)
+	}
+	if lk.Type == l.extType() {
+		lk.Type = 0
+	}
+	if lk.Flag&otUseMarkSet != 0 {
+		lk.MarkSet = lt.u16(6 + 2*n)
+	}
+	l.lookups[li] = lk
+	return lk
+}
+
+// LangSys returns the default LangSys table of the first of the given
+// script tags that is in the script list -- nil if none
+func (l *otLayout) LangSys(scripts ...string) otTable {
+	n := int(l.Scripts.u16(0))
+	for _, sc := range scripts {
+		for i := 0; i < n; i++ {
+			rec := 2 + 6*i
+			if l.Scripts.tag(rec) == sc {
+				st := l.Scripts.sub16(rec + 4)
+				return st.sub16(0)
+			}
+		}
+	}
+	return nil
+}
+
+// FeatureLookups returns the lookup indexes of the feature with given tag
+// in given LangSys table
+func (l *otLayout) FeatureLookups(ls otTable, tag string) []int {
+	var lis []int
+	n := int(ls.u16(4))
+	for i := 0; i < n; i++ {
+		fi := int(ls.u16(6 + 2*i))
+		if l.Features.tag(2+6*fi) == tag {
+			lis = append(lis, l.featureLookups(fi)...)
+		}
+	}
+	return lis
+}
+
+// RequiredLookups returns the lookup indexes of the required feature of
+// given LangSys table, if it has one
+func (l *otLayout) RequiredLookups(ls otTable) []int {
+	if rf := ls.u16(2); ls != nil && rf != 0xFFFF {
+		return l.featureLookups(int(rf))
+	}
+	return nil
+}
+
+// featureLookups returns the lookup indexes of the feature at given index
+func (l *otLayout) featureLookups(fi int) []int {
+	ft := l.Features.sub16(2 + 6*fi + 4)
+	nl := int(ft.u16(2))
+	lis := make([]int, nl)
+	for j := range lis {
+		lis[j] = int(ft.u16(4 + 2*j))
+	}
+	return lis
+}
+
+// otGDEF is the glyph definition table, with the glyph classes used for
+// lookup flags and mark positioning
+type otGDEF struct {
+	GlyphClass otTable
+	MarkClass  otTable
+	MarkSets   otTable
+}
+
+func newOTGDEF(t otTable) *otGDEF {
+	if len(t) < 12 {
+		return nil
+	}
+	gd := &otGDEF{GlyphClass: t.sub16(4), MarkClass: t.sub16(10)}
+	if t.u16(2) >= 2 {
+		gd.MarkSets = t.sub16(12)
+	}
+	return gd
+}
+
+// Class returns the GDEF class of given glyph, or the given default class
+// if the font has no glyph classes
+func (gd *otGDEF) Class(g sfnt.GlyphIndex, def uint16) uint16 {
+	if gd == nil || gd.GlyphClass == nil {
+		return def
+	}
+	return gd.GlyphClass.class(g)
+}
+
+// InMarkSet returns true if glyph is in the mark glyph set of given index
+func (gd *otGDEF) InMarkSet(set uint16, g sfnt.GlyphIndex) bool {
+	if int(set) >= int(gd.MarkSets.u16(2)) {
+		return false
+	}
+	return gd.MarkSets.sub(int(gd.MarkSets.u32(4+4*int(set)))).coverage(g) >= 0
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  otGlyph buffer and lookup application
+
+// GDEF glyph classes
+const (
+	otClassBase      = 1
+	otClassLigature  = 2
+	otClassMark      = 3
+	otClassComponent = 4
+)
+
+// otGlyph is one glyph in the buffer of glyphs being shaped
+type otGlyph struct {
+	ID      sfnt.GlyphIndex
+	Rune    rune   // rune that maps to this glyph, if it has not been substituted
+	Cluster int    // index of the first rune of the cluster this glyph belongs to
+	Mask    uint32 // mask of the features that apply to this glyph
+	Class   uint16 // GDEF glyph class
+	Subst   bool   // glyph has been substituted, so is not the cmap glyph of Rune
+	Cat     uint8  // script-specific character category, for reordering
+	Syl     int    // script-specific syllable index, for reordering
+	LigID   int    // id of the ligature this glyph is, or a mark was attached to
+	LigComp int    // ligature component a mark was following, 1-based
+	XAdv    int32  // horizontal advance, in font units
+	XOff    int32  // horizontal placement offset, in font units
+	YOff    int32  // vertical placement offset, in font units -- up is positive
+	Attach  int    // index of the glyph this (mark) glyph is attached to, -1 if none
+}
+
+// otMaxNesting is the maximum depth of lookups applied by contextual lookups
+const otMaxNesting = 8
+
+// otApplier applies lookups to a buffer of glyphs
+type otApplier struct {
+	Lay     *otLayout
+	GDEF    *otGDEF
+	Buf     []otGlyph
+	Lk      *otLookup
+	Mask    uint32
+	ligIDs  int
+	nesting int
+}
+
+// ApplyLookup applies the lookup at given index to all the glyphs in the
+// buffer that have any of the given feature mask bits
+func (a *otApplier) ApplyLookup(li int, mask uint32) {
+	lk := a.Lay.Lookup(li)
+	if lk == nil || len(lk.Subs) == 0 {
+		return
+	}
+	a.Lk, a.Mask = lk, mask
+	for i := 0; i < len(a.Buf); {
+		g := &a.Buf[i]
+		if g.Mask&mask == 0 || a.skip(g) {
+			i++
+			continue
+		}
+		if nx := a.applyAt(i); nx > i {
+			i = nx
+		} else {
+			i++
+		}
+	}
+}
+
+// otFeature is a feature to apply in shaping, with the mask of the glyphs
+// it applies to
+type otFeature struct {
+	Tag  string
+	Mask uint32
+}
+
+// ApplyFeatures applies the lookups of all given features, as one stage, in
+// the order of the lookups, each to the glyphs with the mask of its feature
+func (a *otApplier) ApplyFeatures(ls otTable, feats []otFeature) {
+	masks := make(map[int]uint32)
+	for _, ft := range feats {
+		for _, li := range a.Lay.FeatureLookups(ls, ft.Tag) {
+			masks[li] |= ft.Mask
+		}
+	}
+	lis := make([]int, 0, len(masks))
+	for li := range masks {
+		lis = append(lis, li)
+	}
+	sort.Ints(lis)
+	for _, li := range lis {
+		a.ApplyLookup(li, masks[li])
+	}
+}
+
+// skip returns true if the glyph is to be skipped (ignored) by the current
+// lookup, according to its flags
+func (a *otApplier) skip(g *otGlyph) bool {
+	fl := a.Lk.Flag
+	switch g.Class {
+	case otClassBase:
+		return fl&otIgnoreBase != 0
+	case otClassLigature:
+		return fl&otIgnoreLigatures != 0
+	case otClassMark:
+		if fl&otIgnoreMarks != 0 {
+			return true
+		}
+		if a.GDEF == nil {
+			return false
+		}
+		if fl&otUseMarkSet != 0 {
+			return !a.GDEF.InMarkSet(a.Lk.MarkSet, g.ID)
+		}
+		if mat := fl & otMarkAttachType; mat != 0 {
+			return a.GDEF.MarkClass.class(g.ID) != mat>>8
+		}
+	}
+	return false
+}
+
+// next returns the index of the next glyph after i that is not skipped by
+// the current lookup, or -1 if none
+func (a *otApplier) next(i int) int {
+	for i++; i < len(a.Buf); i++ {
+		if !a.skip(&a.Buf[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// prev returns the index of the previous glyph before i that is not
+// skipped by the current lookup, or -1 if none
+func (a *otApplier) prev(i int) int {
+	for i--; i >= 0; i-- {
+		if !a.skip(&a.Buf[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyAt applies the current lookup at glyph i, returning the index to
+// continue with after it, or -1 if the lookup did not apply
+func (a *otApplier) applyAt(i int) int {
+	for _, st := range a.Lk.Subs {
+		var nx int
+		if a.Lay.IsGPOS {
+			nx = a.applyPos(st, i)
+		} else {
+			nx = a.applySubst(st, i)
+		}
+		if nx >= 0 {
+			return nx
+		}
+	}
+	return -1
+}
+
+// applyNested applies the lookup at given index at glyph i, for contextual
+// lookups, returning true if it applied
+func (a *otApplier) applyNested(li, i int) bool {
+	lk := a.Lay.Lookup(li)
+	if lk == nil || a.nesting >= otMaxNesting || i >= len(a.Buf) {
+		return false
+	}
+	olk := a.Lk
+	a.Lk = lk
+	a.nesting++
+	applied := false
+	if !a.skip(&a.Buf[i]) {
+		applied = a.applyAt(i) >= 0
+	}
+	a.nesting--
+	a.Lk = olk
+	return applied
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  GSUB
+
+// applySubst applies GSUB sub-table st at glyph i
+func (a *otApplier) applySubst(st otTable, i int) int {
+	g := &a.Buf[i]
+	sfmt := st.u16(0)
+	switch a.Lk.Type {
+	case 1: // single
+		ci := st.sub16(2).coverage(g.ID)
+		if ci < 0 {
+			return -1
+		}
+		if sfmt == 1 {
+			a.setGlyph(i, sfnt.GlyphIndex(int(g.ID)+int(st.i16(4))))
+		} else {
+			if ci >= int(st.u16(4)) {
+				return -1
+			}
+			a.setGlyph(i, sfnt.GlyphIndex(st.u16(6+2*ci)))
+		}
+		return i + 1
+	case 2, 3: // multiple, alternate -- alternate always uses the first one
+		ci := st.sub16(2).coverage(g.ID)
+		if ci < 0 || ci >= int(st.u16(4)) {
+			return -1
+		}
+		seq := st.sub16(6 + 2*ci)
+		n := int(seq.u16(0))
+		if a.Lk.Type == 3 {
+			if n == 0 {
+				return -1
+			}
+			n = 1
+		}
+		ids := make([]sfnt.GlyphIndex, n)
+		for k := range ids {
+			ids[k] = sfnt.GlyphIndex(seq.u16(2 + 2*k))
+		}
+		return a.replace(i, ids)
+	case 4: // ligature
+		ci := st.sub16(2).coverage(g.ID)
+		if ci < 0 || ci >= int(st.u16(4)) {
+			return -1
+		}
+		ls := st.sub16(6 + 2*ci)
+		nl := int(ls.u16(0))
+		for li := 0; li < nl; li++ {
+			lig := ls.sub16(2 + 2*li)
+			nc := int(lig.u16(2))
+			pos := a.matchInput(i, nc, func(k int, gl sfnt.GlyphIndex) bool {
+				return gl == sfnt.GlyphIndex(lig.u16(4+2*(k-1)))
+			})
+			if pos != nil {
+				a.ligate(pos, sfnt.GlyphIndex(lig.u16(0)))
+				return i + 1
+			}
+		}
+		return -1
+	case 5, 6:
+		return a.applyContext(st, i)
+	}
+	return -1
+}
+
+// setGlyph substitutes glyph i with given glyph
+func (a *otApplier) setGlyph(i int, id sfnt.GlyphIndex) {
+	g := &a.Buf[i]
+	g.ID = id
+	g.Subst = true
+	g.Class = a.GDEF.Class(id, g.Class)
+}
+
+// replace replaces glyph i with given glyphs, returning the index after them
+func (a *otApplier) replace(i int, ids []sfnt.GlyphIndex) int {
+	if len(ids) == 1 {
+		a.setGlyph(i, ids[0])
+		return i + 1
+	}
+	og := a.Buf[i]
+	ng := make([]otGlyph, len(ids))
+	for k, id := range ids {
+		ng[k] = og
+		ng[k].ID = id
+		ng[k].Subst = true
+		ng[k].Class = a.GDEF.Class(id, og.Class)
+	}
+	nb := make([]otGlyph, 0, len(a.Buf)+len(ids)-1)
+	nb = append(nb, a.Buf[:i]...)
+	nb = append(nb, ng...)
+	nb = append(nb, a.Buf[i+1:]...)
+	a.Buf = nb
+	return i + len(ids)
+}
+
+// ligate replaces the glyphs at given positions with the given ligature
+// glyph -- any skipped glyphs (marks) between them stay, after the
+// ligature, with their component recorded for mark-to-ligature positioning
+func (a *otApplier) ligate(pos []int, id sfnt.GlyphIndex) {
+	st, ed := pos[0], pos[len(pos)-1]
+	cl := a.Buf[st].Cluster
+	for k := st + 1; k <= ed; k++ { // merge clusters
+		if a.Buf[k].Cluster < cl {
+			cl = a.Buf[k].Cluster
+		}
+	}
+	a.ligIDs++
+	lg := a.Buf[st]
+	lg.ID = id
+	lg.Subst = true
+	lg.Cluster = cl
+	lg.Class = a.GDEF.Class(id, otClassLigature)
+	lg.LigID = a.ligIDs
+	lg.LigComp = 0
+	nb := make([]otGlyph, 0, len(a.Buf))
+	nb = append(nb, a.Buf[:st]...)
+	nb = append(nb, lg)
+	comp := 1
+	pi := 1
+	for k := st + 1; k <= ed; k++ {
+		if pi < len(pos) && k == pos[pi] {
+			comp++
+			pi++
+			continue
+		}
+		mg := a.Buf[k]
+		mg.Cluster = cl
+		mg.LigID = a.ligIDs
+		mg.LigComp = comp
+		nb = append(nb, mg)
+	}
+	nb = append(nb, a.Buf[ed+1:]...)
+	a.Buf = nb
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Contextual lookups
+
+// matchInput matches an input sequence of n glyphs starting with glyph i
+// (which has already been matched), calling match for the rest, returning
+// the positions of the matched glyphs or nil if no match -- the input
+// glyphs must all have the current feature mask
+func (a *otApplier) matchInput(i, n int, match func(k int, g sfnt.GlyphIndex) bool) []int {
+	pos := make([]int, 1, n)
+	pos[0] = i
+	j := i
+	for k := 1; k < n; k++ {
+		j = a.next(j)
+		if j < 0 || a.Buf[j].Mask&a.Mask == 0 || !match(k, a.Buf[j].ID) {
+			return nil
+		}
+		pos = append(pos, j)
+	}
+	return pos
+}
+
+// matchContext matches n glyphs of backtrack (dir < 0) or lookahead (dir >
+// 0) context, starting from glyph i, returning true if they all match
+func (a *otApplier) matchContext(i, dir, n int, match func(k int, g sfnt.GlyphIndex) bool) bool {
+	j := i
+	for k := 0; k < n; k++ {
+		if dir < 0 {
+			j = a.prev(j)
+		} else {
+			j = a.next(j)
+		}
+		if j < 0 || !match(k, a.Buf[j].ID) {
+			return false
+		}
+	}
+	return true
+}
+
+// otRule is a (chaining) context rule, with the number of glyphs in each of
+// its sequences, and functions to match them
+type otRule struct {
+	NBack, NInput, NAhead int
+	Back, Input, Ahead    func(k int, g sfnt.GlyphIndex) bool
+	Records               otTable
+	NRecords              int
+}
+
+// applyRule applies the rule at glyph i, returning the index after the
+// matched input, or -1 if it does not match
+func (a *otApplier) applyRule(rl *otRule, i int) int {
+	pos := a.matchInput(i, rl.NInput, rl.Input)
+	if pos == nil {
+		return -1
+	}
+	if !a.matchContext(pos[len(pos)-1], 1, rl.NAhead, rl.Ahead) || !a.matchContext(i, -1, rl.NBack, rl.Back) {
+		return -1
+	}
+	for r := 0; r < rl.NRecords; r++ {
+		si := int(rl.Records.u16(4 * r))
+		li := int(rl.Records.u16(4*r + 2))
+		if si >= len(pos) {
+			continue
+		}
+		sz := len(a.Buf)
+		a.applyNested(li, pos[si])
+		if d := len(a.Buf) - sz; d != 0 {
+			for k := si + 1; k < len(pos); k++ {
+				pos[k] += d
+			}
+		}
+	}
+	nx := pos[len(pos)-1] + 1
+	if nx <= i {
+		nx = i + 1
+	}
+	return nx
+}
+
+// glyphSeq returns a match function for a sequence of glyph ids at given
+// offset, which starts at sequence element st
+func glyphSeq(t otTable, off, st int) func(k int, g sfnt.GlyphIndex) bool {
+	return func(k int, g sfnt.GlyphIndex) bool {
+		return g == sfnt.GlyphIndex(t.u16(off+2*(k-st)))
+	}
+}
+
+// classSeq returns a match function for a sequence of classes at given
+// offset, which starts at sequence element st, using given class def
+func classSeq(t otTable, off, st int, cd otTable) func(k int, g sfnt.GlyphIndex) bool {
+	return func(k int, g sfnt.GlyphIndex) bool {
+		return cd.class(g) == t.u16(off+2*(k-st))
+	}
+}
+
+// coverageSeq returns a match function for a sequence of coverage table
+// offsets at given offset, relative to table st
+func coverageSeq(st otTable, off int) func(k int, g sfnt.GlyphIndex) bool {
+	return func(k int, g sfnt.GlyphIndex) bool {
+		return st.sub16(off+2*k).coverage(g) >= 0
+	}
+}
+
+// applyContext applies a context (GSUB 5, GPOS 7) or chaining context
+// (GSUB 6, GPOS 8) sub-table at glyph i, in any of its three formats
+func (a *otApplier) applyContext(st otTable, i int) int {
+	g := &a.Buf[i]
+	chain := (a.Lay.IsGPOS && a.Lk.Type == 8) || (!a.Lay.IsGPOS && a.Lk.Type == 6)
+	sfmt := st.u16(0)
+	if sfmt == 3 {
+		rl := &otRule{}
+		off := 2
+		if chain {
+			rl.NBack = int(st.u16(off))
+			rl.Back = coverageSeq(st, off+2)
+			off += 2 + 2*rl.NBack
+			rl.NInput = int(st.u16(off))
+			rl.Input = coverageSeq(st, off+2)
+			off += 2 + 2*rl.NInput
+			rl.NAhead = int(st.u16(off))
+			rl.Ahead = coverageSeq(st, off+2)
+			off += 2 + 2*rl.NAhead
+			rl.NRecords = int(st.u16(off))
+			rl.Records = st.sub(off + 2)
+		} else {
+			rl.NInput = int(st.u16(2))
+			rl.NRecords = int(st.u16(4))
+			rl.Input = coverageSeq(st, 6)
+			rl.Records = st.sub(6 + 2*rl.NInput)
+		}
+		if rl.NInput == 0 || !rl.Input(0, g.ID) {
+			return -1
+		}
+		return a.applyRule(rl, i)
+	}
+	ci := st.sub16(2).coverage(g.ID)
+	if ci < 0 {
+		return -1
+	}
+	var set otTable
+	var bcd, icd, lcd otTable
+	switch {
+	case sfmt == 1:
+		if ci >= int(st.u16(4)) {
+			return -1
+		}
+		set = st.sub16(6 + 2*ci)
+	case sfmt == 2 && chain:
+		bcd, icd, lcd = st.sub16(4), st.sub16(6), st.sub16(8)
+		cl := int(icd.class(g.ID))
+		if cl >= int(st.u16(10)) {
+			return -1
+		}
+		set = st.sub16(12 + 2*cl)
+	case sfmt == 2:
+		icd = st.sub16(4)
+		cl := int(icd.class(g.ID))
+		if cl >= int(st.u16(6)) {
+			return -1
+		}
+		set = st.sub16(8 + 2*cl)
+	default:
+		return -1
+	}
+	nr := int(set.u16(0))
+	for r := 0; r < nr; r++ {
+		rt := set.sub16(2 + 2*r)
+		rl := &otRule{}
+		seq := func(off, st int, cd otTable) func(k int, g sfnt.GlyphIndex) bool {
+			if sfmt == 1 {
+				return glyphSeq(rt, off, st)
+			}
+			return classSeq(rt, off, st, cd)
+		}
+		if chain {
+			off := 0
+			rl.NBack = int(rt.u16(off))
+			rl.Back = seq(off+2, 0, bcd)
+			off += 2 + 2*rl.NBack
+			rl.NInput = int(rt.u16(off))
+			rl.Input = seq(off+2, 1, icd)
+			off += 2 + 2*(rl.NInput-1)
+			rl.NAhead = int(rt.u16(off))
+			rl.Ahead = seq(off+2, 0, lcd)
+			off += 2 + 2*rl.NAhead
+			rl.NRecords = int(rt.u16(off))
+			rl.Records = rt.sub(off + 2)
+		} else {
+			rl.NInput = int(rt.u16(0))
+			rl.NRecords = int(rt.u16(2))
+			rl.Input = seq(4, 1, icd)
+			rl.Records = rt.sub(4 + 2*(rl.NInput-1))
+		}
+		if rl.NInput == 0 {
+			continue
+		}
+		if nx := a.applyRule(rl, i); nx >= 0 {
+			return nx
+		}
+	}
+	return -1
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  GPOS
+
+// applyPos applies GPOS sub-table st at glyph i
+func (a *otApplier) applyPos(st otTable, i int) int {
+	g := &a.Buf[i]
+	sfmt := st.u16(0)
+	switch a.Lk.Type {
+	case 1: // single
+		ci := st.sub16(2).coverage(g.ID)
+		if ci < 0 {
+			return -1
+		}
+		vf := st.u16(4)
+		off := 6
+		if sfmt == 2 {
+			off = 8 + ci*otValueSize(vf)
+		}
+		a.adjust(i, st, off, vf)
+		return i + 1
+	case 2: // pair
+		ci := st.sub16(2).coverage(g.ID)
+		if ci < 0 {
+			return -1
+		}
+		j := a.next(i)
+		if j < 0 {
+			return -1
+		}
+		g2 := a.Buf[j].ID
+		vf1, vf2 := st.u16(4), st.u16(6)
+		s1, s2 := otValueSize(vf1), otValueSize(vf2)
+		var rec otTable
+		off := 0
+		if sfmt == 1 {
+			ps := st.sub16(10 + 2*ci)
+			rsz := 2 + s1 + s2
+			n := int(ps.u16(0))
+			k := sort.Search(n, func(k int) bool { return sfnt.GlyphIndex(ps.u16(2+k*rsz)) >= g2 })
+			if k >= n || sfnt.GlyphIndex(ps.u16(2+k*rsz)) != g2 {
+				return -1
+			}
+			rec, off = ps, 2+k*rsz+2
+		} else if sfmt == 2 {
+			c1 := int(st.sub16(8).class(g.ID))
+			c2 := int(st.sub16(10).class(g2))
+			n1, n2 := int(st.u16(12)), int(st.u16(14))
+			if c1 >= n1 || c2 >= n2 {
+				return -1
+			}
+			rec, off = st, 16+(c1*n2+c2)*(s1+s2)
+		} else {
+			return -1
+		}
+		a.adjust(i, rec, off, vf1)
+		a.adjust(j, rec, off+s1, vf2)
+		if vf2 != 0 {
+			return j + 1
+		}
+		return j
+	case 4, 5, 6: // mark-to-base, mark-to-ligature, mark-to-mark
+		mi := st.sub16(2).coverage(g.ID)
+		if mi < 0 {
+			return -1
+		}
+		bi := a.markBase(i)
+		if bi < 0 {
+			return -1
+		}
+		b := &a.Buf[bi]
+		bci := st.sub16(4).coverage(b.ID)
+		if bci < 0 {
+			return -1
+		}
+		ncl := int(st.u16(6))
+		marr := st.sub16(8)
+		cl := int(marr.u16(2 + 4*mi))
+		if cl >= ncl {
+			return -1
+		}
+		mx, my := marr.sub16(2 + 4*mi + 2).anchor()
+		barr := st.sub16(10)
+		var bat otTable
+		if a.Lk.Type == 5 {
+			la := barr.sub16(2 + 2*bci)
+			nc := int(la.u16(0))
+			comp := nc
+			if g.LigID == b.LigID && g.LigComp > 0 && g.LigComp <= nc {
+				comp = g.LigComp
+			}
+			if comp == 0 {
+				return -1
+			}
+			bat = la.sub16(2 + ((comp-1)*ncl+cl)*2)
+		} else {
+			bat = barr.sub16(2 + (bci*ncl+cl)*2)
+		}
+		if bat == nil {
+			return -1
+		}
+		bx, by := bat.anchor()
+		g.XOff = bx - mx
+		g.YOff = by - my
+		g.Attach = bi
+		return i + 1
+	case 7, 8:
+		return a.applyContext(st, i)
+	}
+	return -1
+}
+
+// adjust applies the ValueRecord with given format at given offset to glyph i
+func (a *otApplier) adjust(i int, t otTable, off int, vf uint16) {
+	if vf == 0 {
+		return
+	}
+	xp, yp, xa, _ := t.value(off, vf)
+	g := &a.Buf[i]
+	g.XOff += xp
+	g.YOff += yp
+	g.XAdv += xa
+}
+
+// markBase returns the index of the glyph that mark glyph i attaches to, for
+// the current mark attachment lookup, or -1 if none
+func (a *otApplier) markBase(i int) int {
+	if a.Lk.Type == 6 { // mark-to-mark: previous glyph, which must be a mark
+		j := a.prev(i)
+		if j < 0 || a.Buf[j].Class != otClassMark {
+			return -1
+		}
+		return j
+	}
+	for j := i - 1; j >= 0; j-- {
+		if a.Buf[j].Class != otClassMark {
+			return j
+		}
+	}
+	return -1
+}
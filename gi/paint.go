@@ -239,9 +239,25 @@ type RenderState struct {
 	XFormStack     []Matrix2D        `desc:"stack of transforms"`
 	BoundsStack    []image.Rectangle `desc:"stack of bounds -- every render starts with a push onto this stack, and finishes with a pop"`
 	ClipStack      []*image.Alpha    `desc:"stack of clips, if needed"`
+	ClipImage      *image.RGBA       `desc:"offscreen image that fill and stroke render into when there is a Mask, which is then drawn into Image through the Mask"`
+	ClipRaster     *rasterx.Dasher   `desc:"rasterizer for rendering into ClipImage"`
+	ClipScanner    *scanFT.ScannerFT `desc:"scanner for ClipRaster"`
 	PaintBack      Paint             `desc:"backup of paint -- don't need a full stack but sometimes safer to backup and restore"`
 	RenderMu       sync.Mutex        `desc:"mutex for overall rendering"`
 	RasterMu       sync.Mutex        `desc:"mutex for final rasterx rendering -- only one at a time"`
+	targetStack    []renderTarget
+}
+
+// renderTarget holds the image and rasterizers that a RenderState renders
+// into -- saved and restored by StartOffscreen / EndOffscreen
+type renderTarget struct {
+	image       *image.RGBA
+	mask        *image.Alpha
+	raster      *rasterx.Dasher
+	scanner     *scanFT.ScannerFT
+	clipImage   *image.RGBA
+	clipRaster  *rasterx.Dasher
+	clipScanner *scanFT.ScannerFT
 }
 
 // Init initializes RenderState -- must be called whenever image size changes
@@ -255,6 +271,9 @@ func (rs *RenderState) Init(width, height int, img *image.RGBA) {
 	painter := scanFT.NewRGBAPainter(img)
 	rs.Scanner = scanFT.NewScannerFT(width, height, painter)
 	rs.Raster = rasterx.NewDasher(width, height, rs.Scanner)
+	rs.ClipImage = nil
+	rs.ClipRaster = nil
+	rs.ClipScanner = nil
 }
 
 // PushXForm pushes current xform onto stack and apply new xform on top of it
@@ -343,11 +362,9 @@ func (rs *RenderState) PopBounds() {
 	rs.BoundsStack = rs.BoundsStack[:sz-1]
 }
 
-// PushClip pushes current Mask onto the clip stack
+// PushClip pushes current Mask onto the clip stack -- a nil Mask is pushed
+// too, so that every PushClip must be balanced by a PopClip
 func (rs *RenderState) PushClip() {
-	if rs.Mask == nil {
-		return
-	}
 	if rs.ClipStack == nil {
 		rs.ClipStack = make([]*image.Alpha, 0, 10)
 	}
@@ -367,6 +384,119 @@ func (rs *RenderState) PopClip() {
 	rs.ClipStack = rs.ClipStack[:sz-1]
 }
 
+// StartOffscreen starts rendering into a new blank offscreen image the same
+// size as Image, with no Mask -- everything rendered until the matching
+// EndOffscreen call goes into that image (e.g., for SVG filter effects).
+// Calls can be nested.
+func (rs *RenderState) StartOffscreen() {
+	rs.targetStack = append(rs.targetStack, rs.target())
+	b := rs.Image.Bounds()
+	img := image.NewRGBA(b)
+	rs.setTarget(renderTarget{image: img})
+	rs.Scanner = scanFT.NewScannerFT(b.Max.X, b.Max.Y, scanFT.NewRGBAPainter(img))
+	rs.Raster = rasterx.NewDasher(b.Max.X, b.Max.Y, rs.Scanner)
+}
+
+// EndOffscreen ends rendering into the offscreen image started by
+// StartOffscreen, restoring the prior image and Mask, and returns the
+// offscreen image.
+func (rs *RenderState) EndOffscreen() *image.RGBA {
+	sz := len(rs.targetStack)
+	if sz == 0 {
+		log.Printf("gi.RenderState EndOffscreen: stack is empty -- programmer error\n")
+		return nil
+	}
+	img := rs.Image
+	rs.setTarget(rs.targetStack[sz-1])
+	rs.targetStack[sz-1] = renderTarget{}
+	rs.targetStack = rs.targetStack[:sz-1]
+	return img
+}
+
+// StartMask starts rendering into an offscreen image (see StartOffscreen)
+// whose alpha channel is then returned as a clipping mask by EndMask --
+// everything rendered in between (e.g., the elements of an SVG clipPath)
+// goes into the mask.  Calls can be nested.
+func (rs *RenderState) StartMask() {
+	rs.StartOffscreen()
+}
+
+// EndMask ends rendering into the mask started by StartMask, restoring the
+// prior image, and returns the alpha channel of everything rendered in
+// between, for use as a mask (see SetMask).
+func (rs *RenderState) EndMask() *image.Alpha {
+	img := rs.EndOffscreen()
+	if img == nil {
+		return nil
+	}
+	b := img.Bounds()
+	mask := image.NewAlpha(b)
+	draw.Draw(mask, b, img, b.Min, draw.Src)
+	return mask
+}
+
+// target returns the current render target
+func (rs *RenderState) target() renderTarget {
+	return renderTarget{image: rs.Image, mask: rs.Mask, raster: rs.Raster, scanner: rs.Scanner,
+		clipImage: rs.ClipImage, clipRaster: rs.ClipRaster, clipScanner: rs.ClipScanner}
+}
+
+// setTarget sets the current render target
+func (rs *RenderState) setTarget(rt renderTarget) {
+	rs.Image = rt.image
+	rs.Mask = rt.mask
+	rs.Raster = rt.raster
+	rs.Scanner = rt.scanner
+	rs.ClipImage = rt.clipImage
+	rs.ClipRaster = rt.clipRaster
+	rs.ClipScanner = rt.clipScanner
+}
+
+// rasterizer returns the rasterizer and scanner to use for fill and stroke:
+// if there is a Mask, rendering goes into ClipImage (allocated as needed),
+// and is then drawn into Image through the Mask by drawMasked
+func (rs *RenderState) rasterizer() (*rasterx.Dasher, *scanFT.ScannerFT) {
+	if rs.Mask == nil {
+		return rs.Raster, rs.Scanner
+	}
+	b := rs.Image.Bounds()
+	if rs.ClipImage == nil || rs.ClipImage.Bounds() != b {
+		rs.ClipImage = image.NewRGBA(b)
+		rs.ClipScanner = scanFT.NewScannerFT(b.Max.X, b.Max.Y, scanFT.NewRGBAPainter(rs.ClipImage))
+		rs.ClipRaster = rasterx.NewDasher(b.Max.X, b.Max.Y, rs.ClipScanner)
+	}
+	return rs.ClipRaster, rs.ClipScanner
+}
+
+// drawMasked draws the LastRenderBBox region of ClipImage into Image through
+// the Mask, and clears that region of ClipImage -- no-op if no Mask
+func (rs *RenderState) drawMasked() {
+	if rs.Mask == nil || rs.ClipImage == nil {
+		return
+	}
+	bb := rs.LastRenderBBox.Intersect(rs.Bounds).Intersect(rs.Image.Bounds())
+	if bb.Empty() {
+		return
+	}
+	draw.DrawMask(rs.Image, bb, rs.ClipImage, bb.Min, rs.Mask, bb.Min, draw.Over)
+	draw.Draw(rs.ClipImage, bb, image.Transparent, image.ZP, draw.Src)
+}
+
+// IntersectMasks returns the intersection of two masks, i.e., the product of
+// their alpha values -- if either is nil, the other is returned.
+func IntersectMasks(a, b *image.Alpha) *image.Alpha {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	bb := a.Bounds()
+	mask := image.NewAlpha(bb)
+	draw.DrawMask(mask, bb, a, bb.Min, b, bb.Min, draw.Src)
+	return mask
+}
+
 // BackupPaint copies style settings from Paint to PaintBack
 func (rs *RenderState) BackupPaint() {
 	rs.PaintBack.CopyStyleFrom(&rs.Paint)
@@ -570,21 +700,23 @@ func (pc *Paint) stroke(rs *RenderState) {
 		}
 	}
 
-	rs.Raster.SetStroke(
+	raster, scanner := rs.rasterizer()
+	raster.SetStroke(
 		Float32ToFixed(pc.StrokeWidth(rs)),
 		Float32ToFixed(pc.StrokeStyle.MiterLimit),
 		pc.capfunc(), nil, nil, pc.joinmode(), // todo: supports leading / trailing caps, and "gaps"
 		dash, 0,
 	)
-	rs.Scanner.SetClip(rs.Bounds)
-	rs.Path.AddTo(rs.Raster)
-	fbox := rs.Raster.Scanner.GetPathExtent()
+	scanner.SetClip(rs.Bounds)
+	rs.Path.AddTo(raster)
+	fbox := scanner.GetPathExtent()
 	// fmt.Printf("node: %v fbox: %v\n", g.Nm, fbox)
 	rs.LastRenderBBox = image.Rectangle{Min: image.Point{fbox.Min.X.Floor(), fbox.Min.Y.Floor()},
 		Max: image.Point{fbox.Max.X.Ceil(), fbox.Max.Y.Ceil()}}
-	rs.Raster.SetColor(pc.StrokeStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.StrokeStyle.Opacity, rs.LastRenderBBox, rs.XForm))
-	rs.Raster.Draw()
-	rs.Raster.Clear()
+	raster.SetColor(pc.StrokeStyle.Color.RenderColor(pc.FontStyle.Opacity*pc.StrokeStyle.Opacity, rs.LastRenderBBox, rs.XForm))
+	raster.Draw()
+	raster.Clear()
+	rs.drawMasked()
 
 	pr.End()
 }
@@ -595,11 +727,12 @@ func (pc *Paint) fill(rs *RenderState) {
 	rs.RasterMu.Lock()
 	defer rs.RasterMu.Unlock()
 
-	rf := &rs.Raster.Filler
+	raster, scanner := rs.rasterizer()
+	rf := &raster.Filler
 	rf.SetWinding(pc.FillStyle.Rule == FillRuleNonZero)
-	rs.Scanner.SetClip(rs.Bounds)
+	scanner.SetClip(rs.Bounds)
 	rs.Path.AddTo(rf)
-	fbox := rs.Scanner.GetPathExtent()
+	fbox := scanner.GetPathExtent()
 	// fmt.Printf("node: %v fbox: %v\n", g.Nm, fbox)
 	rs.LastRenderBBox = image.Rectangle{Min: image.Point{fbox.Min.X.Floor(), fbox.Min.Y.Floor()},
 		Max: image.Point{fbox.Max.X.Ceil(), fbox.Max.Y.Ceil()}}
@@ -610,6 +743,7 @@ func (pc *Paint) fill(rs *RenderState) {
 	}
 	rf.Draw()
 	rf.Clear()
+	rs.drawMasked()
 
 	pr.End()
 }
@@ -665,16 +799,17 @@ func (pc *Paint) FillBoxColor(rs *RenderState, pos, size Vec2D, clr color.Color)
 // clipping region with the current path as it would be filled by pc.Fill().
 // The path is preserved after this operation.
 func (pc *Paint) ClipPreserve(rs *RenderState) {
-	clip := image.NewAlpha(rs.Image.Bounds())
-	// painter := raster.NewAlphaOverPainter(clip) // todo!
+	fs := pc.FillStyle
+	op := pc.FontStyle.Opacity
+	pc.FillStyle.Color.SetColor(color.Black)
+	pc.FillStyle.Opacity = 1
+	pc.FontStyle.Opacity = 1
+	rs.StartMask()
 	pc.fill(rs)
-	if rs.Mask == nil {
-		rs.Mask = clip
-	} else { // todo: this one operation MASSIVELY slows down clip usage -- unclear why
-		mask := image.NewAlpha(rs.Image.Bounds())
-		draw.DrawMask(mask, mask.Bounds(), clip, image.ZP, rs.Mask, image.ZP, draw.Over)
-		rs.Mask = mask
-	}
+	clip := rs.EndMask()
+	pc.FillStyle = fs
+	pc.FontStyle.Opacity = op
+	rs.Mask = IntersectMasks(rs.Mask, clip)
 }
 
 // SetMask allows you to directly set the *image.Alpha to be used as a clipping
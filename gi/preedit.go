@@ -0,0 +1,105 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"github.com/goki/gi/oswin/ime"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/bitflag"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Preedit
+
+// Preedit is the composition (preedit) text of an input method editor (IME),
+// which text widgets display inline at their cursor, underlined, while text
+// is being composed -- it is replaced by the committed text when done
+type Preedit struct {
+	Text      []rune     `desc:"the current preedit text -- empty when not composing"`
+	Cursor    int        `desc:"cursor position within the preedit text, in runes"`
+	CursorOff float32    `desc:"rendered offset of the cursor relative to the start of the preedit text -- set by Underline during rendering"`
+	Render    TextRender `json:"-" xml:"-" desc:"render of the preedit text, for RenderAt"`
+}
+
+// IsActive returns true if there is preedit text being composed
+func (pe *Preedit) IsActive() bool {
+	return len(pe.Text) > 0
+}
+
+// Clear clears the preedit text
+func (pe *Preedit) Clear() {
+	pe.Text = nil
+	pe.Cursor = 0
+	pe.CursorOff = 0
+}
+
+// SetFromEvent updates the preedit text from an ime.Preedit event, or clears
+// it for an ime.Commit event, as the committed text replaces it
+func (pe *Preedit) SetFromEvent(ie *ime.Event) {
+	if ie.Action == ime.Commit {
+		pe.Clear()
+		return
+	}
+	pe.Text = []rune(ie.Text)
+	pe.Cursor = ie.Cursor
+	if pe.Cursor < 0 || pe.Cursor > len(pe.Text) {
+		pe.Cursor = len(pe.Text)
+	}
+}
+
+// Insert returns given text with the preedit text inserted at given
+// position, for rendering -- the preedit text starts at pos in the result
+func (pe *Preedit) Insert(txt []rune, pos int) []rune {
+	if pos < 0 || pos > len(txt) {
+		return txt
+	}
+	nt := make([]rune, 0, len(txt)+len(pe.Text))
+	nt = append(nt, txt[:pos]...)
+	nt = append(nt, pe.Text...)
+	nt = append(nt, txt[pos:]...)
+	return nt
+}
+
+// Underline underlines the preedit text in the given text render, where it
+// was inserted at given position in its first span by Insert, and sets the
+// CursorOff offset of the preedit cursor
+func (pe *Preedit) Underline(tr *TextRender, pos int) {
+	if len(tr.Spans) == 0 {
+		return
+	}
+	sr := &tr.Spans[0]
+	sr.SetDecoRange(pos, pos+len(pe.Text), DecoUnderline)
+	pe.CursorOff = sr.RuneRelPos(pos+pe.Cursor).X - sr.RuneRelPos(pos).X
+}
+
+// RenderAt renders the preedit text at given top-left position, underlined,
+// over a fill of the given background color -- for widgets that cannot
+// insert the preedit text inline into their own text render, e.g., where
+// it is laid out with markup -- it then covers the text after the cursor
+// while composing
+func (pe *Preedit) RenderAt(rs *RenderState, pos Vec2D, fontSty *FontStyle, ctxt *units.Context, txtSty *TextStyle, bg *ColorSpec) {
+	pe.Render.SetRunes(pe.Text, fontSty, ctxt, txtSty, true, 0, 0)
+	pe.Underline(&pe.Render, 0)
+	rs.Paint.FillBox(rs, pos, pe.Render.Size, bg)
+	pe.Render.RenderTopPos(rs, pos)
+}
+
+// SetDecoRange sets given decoration (underline etc) for the runes in the
+// given range [st, ed) of the span
+func (sr *SpanRender) SetDecoRange(st, ed int, deco TextDecorations) {
+	if st < 0 {
+		st = 0
+	}
+	if ed > len(sr.Render) {
+		ed = len(sr.Render)
+	}
+	if st >= ed {
+		return
+	}
+	for i := st; i < ed; i++ {
+		bitflag.Set32((*int32)(&sr.Render[i].Deco), int(deco))
+	}
+	bitflag.Set32((*int32)(&sr.HasDeco), int(deco))
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"testing"
+
+	"github.com/goki/gi/oswin/ime"
+	"github.com/goki/ki/bitflag"
+)
+
+func TestPreeditSetFromEvent(t *testing.T) {
+	tests := []struct {
+		ev     ime.Event
+		text   string
+		cursor int
+	}{
+		{ime.Event{Action: ime.Preedit, Text: "abc", Cursor: 2}, "abc", 2},
+		{ime.Event{Action: ime.Preedit, Text: "ab", Cursor: 5}, "ab", 2},
+		{ime.Event{Action: ime.Preedit, Text: "ab", Cursor: -1}, "ab", 2},
+		{ime.Event{Action: ime.Preedit, Text: "日本語", Cursor: 1}, "日本語", 1},
+		{ime.Event{Action: ime.Commit, Text: "x"}, "", 0},
+		{ime.Event{Action: ime.Preedit}, "", 0},
+	}
+	pe := Preedit{}
+	for _, tt := range tests {
+		pe.SetFromEvent(&tt.ev)
+		if string(pe.Text) != tt.text || pe.Cursor != tt.cursor || pe.IsActive() != (tt.text != "") {
+			t.Errorf("%v: text: %q cursor: %v", tt.ev.Action, string(pe.Text), pe.Cursor)
+		}
+	}
+}
+
+func TestPreeditInsert(t *testing.T) {
+	pe := Preedit{Text: []rune("XY")}
+	txt := []rune("hello")
+	tests := []struct {
+		pos  int
+		want string
+	}{{2, "heXYllo"}, {0, "XYhello"}, {5, "helloXY"}, {6, "hello"}, {-1, "hello"}}
+	for _, tt := range tests {
+		if got := string(pe.Insert(txt, tt.pos)); got != tt.want {
+			t.Errorf("insert at %v: %q, want %q", tt.pos, got, tt.want)
+		}
+	}
+	if string(txt) != "hello" {
+		t.Errorf("insert modified the text: %q", string(txt))
+	}
+}
+
+func TestPreeditUnderline(t *testing.T) {
+	pe := Preedit{Text: []rune("XY"), Cursor: 1}
+	txt := pe.Insert([]rune("hello"), 2)
+	sr := SpanRender{Text: txt, Render: make([]RuneRender, len(txt)), LastPos: Vec2D{70, 0}}
+	for i := range sr.Render {
+		sr.Render[i].RelPos.X = float32(i * 10)
+	}
+	tr := &TextRender{Spans: []SpanRender{sr}}
+	pe.Underline(tr, 2)
+	for i, rr := range tr.Spans[0].Render {
+		if ul := bitflag.Has32(int32(rr.Deco), int(DecoUnderline)); ul != (i == 2 || i == 3) {
+			t.Errorf("rune %v underline: %v", i, ul)
+		}
+	}
+	if !bitflag.Has32(int32(tr.Spans[0].HasDeco), int(DecoUnderline)) {
+		t.Errorf("span HasDeco not set")
+	}
+	if pe.CursorOff != 10 {
+		t.Errorf("cursor offset: %v", pe.CursorOff)
+	}
+	pe.Cursor = 2
+	pe.Underline(tr, 2)
+	if pe.CursorOff != 20 {
+		t.Errorf("cursor offset at end: %v", pe.CursorOff)
+	}
+	pe.Underline(&TextRender{}, 0) // no spans: no-op
+}
@@ -0,0 +1,929 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"unicode"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/ki/ints"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// Text shaping converts the runes of a span of text into the glyphs that
+// are actually rendered, using the OpenType layout tables of the font: GSUB
+// substitutes glyphs for ligatures, contextual forms (e.g., Arabic joining)
+// and conjuncts (e.g., Devanagari), and GPOS positions them, for kerning and
+// the placement of combining marks.  It happens between setting the runes
+// of a SpanRender (SetRunes etc) and positioning them (SetRunePosLR), in
+// ShapeLR, which produces the Glyphs of the span, grouped into clusters of
+// runes -- the advance of each cluster is divided among its runes, so the
+// one-to-one correspondence of runes and RuneRender positions, used for
+// cursor positioning and selection, is preserved.
+
+// TextShaping turns on the OpenType shaping of text (ligatures, contextual
+// forms, kerning, mark positioning) for fonts that have the layout tables
+// for it -- if off, each rune is rendered with its own glyph, positioned
+// just by its advance and any kerning pair from the font
+var TextShaping = true
+
+// GlyphRender is one shaped glyph of a SpanRender, which renders (part of)
+// a cluster of runes -- the glyphs are in logical order, and each cluster is
+// rendered at the visual position of its runes
+type GlyphRender struct {
+	ID      sfnt.GlyphIndex `desc:"glyph index in the font"`
+	Rune    rune            `desc:"rune that this glyph is the standard glyph for, which is rendered through the font face -- 0 if the glyph was substituted by shaping, in which case it is rendered from its outline"`
+	Cluster int             `desc:"index in span Text of the first rune of the cluster that this glyph renders"`
+	Pos     Vec2D           `desc:"position of the glyph relative to the left of its cluster, at the baseline -- for a mark glyph attached to another glyph, relative to that glyph instead"`
+	Advance float32         `desc:"advance of the glyph, in dots -- the sum of the advances of the glyphs of a cluster is its width"`
+	Attach  int             `desc:"index of the glyph that this (mark) glyph is attached to, or -1 if not attached"`
+}
+
+// feature mask bits, for features that only apply to some glyphs
+const (
+	otMaskGlobal uint32 = 1 << iota
+	otMaskIsol
+	otMaskFina
+	otMaskMedi
+	otMaskInit
+	otMaskRphf
+	otMaskHalf
+	otMaskPost
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  FontShaper
+
+// otFont is the font data used for shaping, which is shared by all the faces
+// (sizes) opened from the same font file -- the buffers are only used with
+// TextFontRenderMu locked
+type otFont struct {
+	SFNT *sfnt.Font
+	GSUB *otLayout
+	GPOS *otLayout
+	GDEF *otGDEF
+	UPEM float32
+	buf  sfnt.Buffer
+	rast vector.Rasterizer
+	mask image.Alpha
+}
+
+// newOTFont returns the shaping data for given font file data, or nil if it
+// has no layout tables to shape with
+func newOTFont(data []byte) *otFont {
+	tabs := otTables(data)
+	of := &otFont{GSUB: newOTLayout(tabs["GSUB"], false), GPOS: newOTLayout(tabs["GPOS"], true), GDEF: newOTGDEF(tabs["GDEF"])}
+	if of.GSUB == nil && of.GPOS == nil {
+		return nil
+	}
+	var err error
+	if otTable(data).tag(0) == "ttcf" {
+		var fc *sfnt.Collection
+		if fc, err = sfnt.ParseCollection(data); err == nil {
+			of.SFNT, err = fc.Font(0)
+		}
+	} else {
+		of.SFNT, err = sfnt.Parse(data)
+	}
+	if err != nil {
+		return nil
+	}
+	of.UPEM = float32(of.SFNT.UnitsPerEm())
+	return of
+}
+
+// FontShaper shapes text for one font face, using the OpenType layout tables
+// of its font -- see FaceShaper
+type FontShaper struct {
+	Size float32 `desc:"size of the face, in dots"`
+	font *otFont
+}
+
+var (
+	shaperMu    sync.Mutex
+	faceShapers = map[font.Face]*FontShaper{}
+	otFonts     = map[string]*otFont{}
+)
+
+// AddFaceShaper adds the shaper for given face, opened at given size (in
+// dots) from the font file data at given path -- the layout tables of each
+// path are only read once, and shared by all of its faces -- this is called
+// when fonts are opened (OpenFontFace)
+func AddFaceShaper(face font.Face, path string, data []byte, size int) {
+	shaperMu.Lock()
+	defer shaperMu.Unlock()
+	of, has := otFonts[path]
+	if !has {
+		of = newOTFont(data)
+		otFonts[path] = of // nil too: nothing to shape with
+	}
+	if of != nil {
+		faceShapers[face] = &FontShaper{Size: float32(size), font: of}
+	}
+}
+
+// FaceShaper returns the shaper for given font face, or nil if its font has
+// no OpenType layout tables to shape with
+func FaceShaper(face font.Face) *FontShaper {
+	if face == nil {
+		return nil
+	}
+	shaperMu.Lock()
+	defer shaperMu.Unlock()
+	return faceShapers[face]
+}
+
+// ScriptTag returns the OpenType script tag for given rune, for the scripts
+// that shaping distinguishes, or "" for runes that are common to scripts
+// (spaces, digits, punctuation, combining marks) or in other scripts --
+// these take on the script of the text around them
+func ScriptTag(r rune) string {
+	if r < 0x80 {
+		if unicode.IsLetter(r) {
+			return "latn"
+		}
+		return ""
+	}
+	for _, st := range scriptTags {
+		if unicode.Is(st.tab, r) {
+			return st.tag
+		}
+	}
+	return ""
+}
+
+var scriptTags = []struct {
+	tab *unicode.RangeTable
+	tag string
+}{
+	{unicode.Latin, "latn"},
+	{unicode.Arabic, "arab"},
+	{unicode.Hebrew, "hebr"},
+	{unicode.Devanagari, "dev2"},
+	{unicode.Greek, "grek"},
+	{unicode.Cyrillic, "cyrl"},
+	{unicode.Syriac, "syrc"},
+	{unicode.Thai, "thai"},
+	{unicode.Han, "hani"},
+	{unicode.Hiragana, "kana"},
+	{unicode.Katakana, "kana"},
+	{unicode.Hangul, "hang"},
+}
+
+// otScriptTags returns the script tags to look for in the layout tables
+// for given script, in order of preference
+func otScriptTags(script string) []string {
+	switch script {
+	case "", "DFLT":
+		return []string{"DFLT", "dflt", "latn"}
+	case "dev2":
+		return []string{"dev2", "deva", "DFLT", "dflt", "latn"}
+	}
+	return []string{script, "DFLT", "dflt", "latn"}
+}
+
+// otStages returns the GSUB features to apply for given script, in stages
+// -- the lookups of each stage are applied together, in lookup order
+func otStages(script string) [][]otFeature {
+	glob := func(tags ...string) []otFeature {
+		fs := make([]otFeature, len(tags))
+		for i, t := range tags {
+			fs[i] = otFeature{t, otMaskGlobal}
+		}
+		return fs
+	}
+	switch script {
+	case "arab", "syrc":
+		return [][]otFeature{glob("ccmp", "locl"), {{"isol", otMaskIsol}}, {{"fina", otMaskFina}},
+			{{"medi", otMaskMedi}}, {{"init", otMaskInit}}, glob("rlig"), glob("calt"), glob("liga", "clig", "mset")}
+	case "dev2":
+		return [][]otFeature{glob("locl", "ccmp"), glob("nukt"), glob("akhn"), {{"rphf", otMaskRphf}}, glob("rkrf"),
+			{{"blwf", otMaskPost}}, {{"abvf", otMaskPost}}, {{"half", otMaskHalf}}, {{"pstf", otMaskPost}},
+			glob("vatu"), glob("cjct"), glob("init", "pres", "abvs", "blws", "psts", "haln", "calt", "liga", "clig")}
+	}
+	return [][]otFeature{glob("ccmp", "locl"), glob("rlig", "calt", "liga", "clig", "rclt")}
+}
+
+// isCombiningMark returns true for runes that combine with the rune before
+// them into one cluster
+func isCombiningMark(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) || r == 0x200C || r == 0x200D ||
+		unicode.Is(unicode.Variation_Selector, r)
+}
+
+// Shape shapes the given runes, which are all rendered with the face of this
+// shaper, as one run of text in given script (see ScriptTag) and direction
+// -- right-to-left runes are mirrored as needed.  Returns the glyphs in
+// logical order, with clusters indexing the runes.  Must be called with
+// TextFontRenderMu locked.
+func (fs *FontShaper) Shape(txt []rune, script string, rtl bool) []GlyphRender {
+	of := fs.font
+	a := &otApplier{GDEF: of.GDEF}
+	a.Buf = of.glyphs(txt, rtl)
+	indic := script == "dev2"
+	switch script {
+	case "arab", "syrc":
+		arabicMasks(a.Buf, txt)
+	case "dev2":
+		indicSetup(a.Buf)
+	}
+	if of.GSUB != nil {
+		a.Lay = of.GSUB
+		ls := a.Lay.LangSys(otScriptTags(script)...)
+		for _, li := range a.Lay.RequiredLookups(ls) {
+			a.ApplyLookup(li, otMaskGlobal)
+		}
+		for _, stage := range otStages(script) {
+			a.ApplyFeatures(ls, stage)
+			if indic && stage[0].Tag == "rphf" {
+				indicFindReph(a.Buf)
+			}
+		}
+	}
+	if indic {
+		indicFinalReorder(a.Buf)
+	}
+	ppem := fixed.I(int(of.UPEM))
+	for i := range a.Buf {
+		g := &a.Buf[i]
+		adv, _ := of.SFNT.GlyphAdvance(&of.buf, g.ID, ppem, font.HintingNone)
+		g.XAdv = int32(adv.Round())
+	}
+	if of.GPOS != nil {
+		a.Lay = of.GPOS
+		ls := a.Lay.LangSys(otScriptTags(script)...)
+		for _, li := range a.Lay.RequiredLookups(ls) {
+			a.ApplyLookup(li, otMaskGlobal)
+		}
+		feats := []otFeature{{"kern", otMaskGlobal}, {"mark", otMaskGlobal}, {"mkmk", otMaskGlobal}}
+		if indic {
+			feats = append(feats, otFeature{"dist", otMaskGlobal}, otFeature{"abvm", otMaskGlobal}, otFeature{"blwm", otMaskGlobal})
+		}
+		a.ApplyFeatures(ls, feats)
+	} else {
+		of.kernFallback(a.Buf, ppem)
+	}
+	if !indic {
+		for i := range a.Buf {
+			if a.Buf[i].Class == otClassMark {
+				a.Buf[i].XAdv = 0
+			}
+		}
+	}
+	return fs.glyphRenders(a.Buf, rtl)
+}
+
+// glyphs returns the initial glyph buffer for given runes
+func (of *otFont) glyphs(txt []rune, rtl bool) []otGlyph {
+	buf := make([]otGlyph, len(txt))
+	cl := 0
+	for i, r := range txt {
+		if rtl {
+			r = BidiMirror(r)
+		}
+		if i == 0 || !isCombiningMark(r) {
+			cl = i
+		}
+		id, _ := of.SFNT.GlyphIndex(&of.buf, r)
+		def := uint16(otClassBase)
+		if unicode.In(r, unicode.Mn, unicode.Me) {
+			def = otClassMark
+		}
+		buf[i] = otGlyph{ID: id, Rune: r, Cluster: cl, Mask: otMaskGlobal, Class: of.GDEF.Class(id, def), Attach: -1}
+	}
+	return buf
+}
+
+// kernFallback applies the kerning pairs of the kern table, for fonts that
+// have no GPOS table
+func (of *otFont) kernFallback(buf []otGlyph, ppem fixed.Int26_6) {
+	pi := -1
+	for i := range buf {
+		if buf[i].Class == otClassMark {
+			continue
+		}
+		if pi >= 0 {
+			if k, err := of.SFNT.Kern(&of.buf, buf[pi].ID, buf[i].ID, ppem, font.HintingNone); err == nil {
+				buf[pi].XAdv += int32(k.Round())
+			}
+		}
+		pi = i
+	}
+}
+
+// glyphRenders converts the shaped glyph buffer into GlyphRender glyphs,
+// positioned within their clusters, in dots
+func (fs *FontShaper) glyphRenders(buf []otGlyph, rtl bool) []GlyphRender {
+	scale := fs.Size / fs.font.UPEM
+	gls := make([]GlyphRender, len(buf))
+	for st := 0; st < len(buf); {
+		ed := st + 1
+		for ed < len(buf) && buf[ed].Cluster == buf[st].Cluster {
+			ed++
+		}
+		var cadv, pen int32
+		for k := st; k < ed; k++ {
+			cadv += buf[k].XAdv
+		}
+		for k := st; k < ed; k++ {
+			g := &buf[k]
+			x := pen
+			if rtl {
+				x = cadv - pen - g.XAdv
+			}
+			if g.Attach >= 0 {
+				x = 0
+			}
+			gr := &gls[k]
+			gr.ID = g.ID
+			if !g.Subst {
+				gr.Rune = g.Rune
+			}
+			gr.Cluster = g.Cluster
+			gr.Attach = g.Attach
+			gr.Advance = float32(g.XAdv) * scale
+			gr.Pos = Vec2D{float32(x+g.XOff) * scale, -float32(g.YOff) * scale}
+			pen += g.XAdv
+		}
+		st = ed
+	}
+	return gls
+}
+
+// GlyphMask returns the mask of the glyph with given index, rendered at
+// given dot position, as font.Face Glyph does for a rune -- for glyphs
+// substituted by shaping, which have no rune to render with the face.  Must
+// be called with TextFontRenderMu locked, and the mask is only valid until
+// the next call.
+func (fs *FontShaper) GlyphMask(dot fixed.Point26_6, id sfnt.GlyphIndex) (dr image.Rectangle, mask image.Image, maskp image.Point, ok bool) {
+	of := fs.font
+	segs, err := of.SFNT.LoadGlyph(&of.buf, id, fixed.Int26_6(fs.Size*64), nil)
+	if err != nil {
+		return
+	}
+	bds := segs.Bounds().Add(dot)
+	dr = image.Rect(bds.Min.X.Floor(), bds.Min.Y.Floor(), bds.Max.X.Ceil(), bds.Max.Y.Ceil())
+	w, h := dr.Dx(), dr.Dy()
+	if w <= 0 || h <= 0 {
+		return
+	}
+	bx := float32(dot.X-fixed.I(dr.Min.X)) / 64
+	by := float32(dot.Y-fixed.I(dr.Min.Y)) / 64
+	pt := func(p fixed.Point26_6) (float32, float32) {
+		return float32(p.X)/64 + bx, float32(p.Y)/64 + by
+	}
+	if cap(of.mask.Pix) < w*h {
+		of.mask.Pix = make([]uint8, 2*w*h)
+	}
+	of.mask.Pix = of.mask.Pix[:w*h]
+	of.mask.Stride = w
+	of.mask.Rect = image.Rect(0, 0, w, h)
+	of.rast.Reset(w, h)
+	of.rast.DrawOp = draw.Src
+	for _, sg := range segs {
+		x0, y0 := pt(sg.Args[0])
+		switch sg.Op {
+		case sfnt.SegmentOpMoveTo:
+			of.rast.MoveTo(x0, y0)
+		case sfnt.SegmentOpLineTo:
+			of.rast.LineTo(x0, y0)
+		case sfnt.SegmentOpQuadTo:
+			x1, y1 := pt(sg.Args[1])
+			of.rast.QuadTo(x0, y0, x1, y1)
+		case sfnt.SegmentOpCubeTo:
+			x1, y1 := pt(sg.Args[1])
+			x2, y2 := pt(sg.Args[2])
+			of.rast.CubeTo(x0, y0, x1, y1, x2, y2)
+		}
+	}
+	of.rast.Draw(&of.mask, of.mask.Rect, image.Opaque, image.Point{})
+	return dr, &of.mask, image.Point{}, true
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Arabic
+
+// arabicJoining returns the joining type of given rune: 'R' right-joining
+// (to the rune before it), 'D' dual-joining, 'C' join-causing, 'T'
+// transparent, 'U' non-joining
+func arabicJoining(r rune) byte {
+	for _, jr := range arabicJoiningRanges {
+		if r >= jr.lo && r <= jr.hi {
+			return jr.jt
+		}
+	}
+	if r != 0x200C && unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 'T'
+	}
+	return 'U'
+}
+
+var arabicJoiningRanges = []struct {
+	lo, hi rune
+	jt     byte
+}{
+	{0x0620, 0x0620, 'D'}, {0x0621, 0x0621, 'U'}, {0x0622, 0x0625, 'R'}, {0x0626, 0x0626, 'D'},
+	{0x0627, 0x0627, 'R'}, {0x0628, 0x0628, 'D'}, {0x0629, 0x0629, 'R'}, {0x062A, 0x062E, 'D'},
+	{0x062F, 0x0632, 'R'}, {0x0633, 0x063F, 'D'}, {0x0640, 0x0640, 'C'}, {0x0641, 0x0647, 'D'},
+	{0x0648, 0x0648, 'R'}, {0x0649, 0x064A, 'D'}, {0x066E, 0x066F, 'D'}, {0x0671, 0x0673, 'R'},
+	{0x0674, 0x0674, 'U'}, {0x0675, 0x0677, 'R'}, {0x0678, 0x0687, 'D'}, {0x0688, 0x0699, 'R'},
+	{0x069A, 0x06BF, 'D'}, {0x06C0, 0x06C0, 'R'}, {0x06C1, 0x06C2, 'D'}, {0x06C3, 0x06CB, 'R'},
+	{0x06CC, 0x06CC, 'D'}, {0x06CD, 0x06CD, 'R'}, {0x06CE, 0x06CE, 'D'}, {0x06CF, 0x06CF, 'R'},
+	{0x06D0, 0x06D1, 'D'}, {0x06D2, 0x06D3, 'R'}, {0x06D5, 0x06D5, 'R'}, {0x06EE, 0x06EF, 'R'},
+	{0x06FA, 0x06FC, 'D'}, {0x06FF, 0x06FF, 'D'}, {0x0750, 0x0758, 'D'}, {0x0759, 0x075B, 'R'},
+	{0x075C, 0x076A, 'D'}, {0x076B, 0x076C, 'R'}, {0x076D, 0x0770, 'D'}, {0x0771, 0x0771, 'R'},
+	{0x0772, 0x0772, 'D'}, {0x0773, 0x0774, 'R'}, {0x0775, 0x0777, 'D'}, {0x0778, 0x0779, 'R'},
+	{0x077A, 0x077F, 'D'}, {0x200D, 0x200D, 'C'},
+}
+
+// ArabicForms returns the contextual form of each rune of given Arabic
+// text, from the joining types of the runes: 'i' isolated, 'f' final, 'm'
+// medial, 'n' initial, or 0 for runes that have no forms -- transparent
+// runes (marks) are skipped in determining the joining of the runes around
+// them
+func ArabicForms(txt []rune) []byte {
+	forms := make([]byte, len(txt))
+	prev := -1   // previous non-transparent rune
+	var pjt byte // its joining type
+	for i, r := range txt {
+		jt := arabicJoining(r)
+		if jt == 'T' {
+			continue
+		}
+		if jt == 'R' || jt == 'D' {
+			forms[i] = 'i'
+		}
+		if prev >= 0 && (pjt == 'D' || pjt == 'C') && (jt == 'D' || jt == 'R' || jt == 'C') {
+			switch forms[prev] {
+			case 'i':
+				forms[prev] = 'n'
+			case 'f':
+				forms[prev] = 'm'
+			}
+			if forms[i] == 'i' {
+				forms[i] = 'f'
+			}
+		}
+		prev, pjt = i, jt
+	}
+	return forms
+}
+
+// arabicMasks sets the masks for the isol, fina, medi and init features of
+// the glyphs, from the Arabic forms of their runes
+func arabicMasks(buf []otGlyph, txt []rune) {
+	for i, f := range ArabicForms(txt) {
+		switch f {
+		case 'i':
+			buf[i].Mask |= otMaskIsol
+		case 'f':
+			buf[i].Mask |= otMaskFina
+		case 'm':
+			buf[i].Mask |= otMaskMedi
+		case 'n':
+			buf[i].Mask |= otMaskInit
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Indic (Devanagari)
+
+// Indic character categories, for syllable analysis and reordering
+const (
+	indicOther uint8 = iota
+	indicConsonant
+	indicRa
+	indicNukta
+	indicHalant
+	indicVowel
+	indicMatra
+	indicPreMatra
+	indicModifier
+	indicJoiner
+	indicReph
+)
+
+// indicCategory returns the Indic category of given (Devanagari) rune
+func indicCategory(r rune) uint8 {
+	switch {
+	case r == 0x0930:
+		return indicRa
+	case r >= 0x0915 && r <= 0x0939, r >= 0x0958 && r <= 0x095F, r >= 0x0978 && r <= 0x097F:
+		return indicConsonant
+	case r == 0x093C:
+		return indicNukta
+	case r == 0x094D:
+		return indicHalant
+	case r == 0x093F || r == 0x094E:
+		return indicPreMatra
+	case r == 0x093D:
+		return indicOther
+	case r >= 0x093A && r <= 0x094C, r == 0x094F, r >= 0x0955 && r <= 0x0957, r == 0x0962 || r == 0x0963:
+		return indicMatra
+	case r >= 0x0904 && r <= 0x0914, r == 0x0960 || r == 0x0961, r >= 0x0972 && r <= 0x0977:
+		return indicVowel
+	case r >= 0x0900 && r <= 0x0903:
+		return indicModifier
+	case r == 0x200C || r == 0x200D:
+		return indicJoiner
+	}
+	return indicOther
+}
+
+// indicCat returns the category of glyph i, or indicOther if out of range
+func indicCat(buf []otGlyph, i int) uint8 {
+	if i < 0 || i >= len(buf) {
+		return indicOther
+	}
+	return buf[i].Cat
+}
+
+// indicIsCons returns true if glyph i is a consonant
+func indicIsCons(buf []otGlyph, i int) bool {
+	c := indicCat(buf, i)
+	return c == indicConsonant || c == indicRa
+}
+
+// indicSyllableEnd returns the end of the syllable starting at glyph i, and
+// whether it is a consonant syllable
+func indicSyllableEnd(buf []otGlyph, i int) (int, bool) {
+	k := i
+	cons := false
+	switch {
+	case indicIsCons(buf, k):
+		cons = true
+		for {
+			k++
+			if indicCat(buf, k) == indicNukta {
+				k++
+			}
+			if indicCat(buf, k) != indicHalant {
+				break
+			}
+			j := k + 1
+			if indicCat(buf, j) == indicJoiner {
+				j++
+			}
+			if !indicIsCons(buf, j) {
+				break
+			}
+			k = j
+		}
+	case indicCat(buf, k) == indicVowel:
+		k++
+		if indicCat(buf, k) == indicNukta {
+			k++
+		}
+	default:
+		return k + 1, false
+	}
+	if indicCat(buf, k) == indicHalant {
+		k++
+		if indicCat(buf, k) == indicJoiner {
+			k++
+		}
+	}
+	for c := indicCat(buf, k); c == indicMatra || c == indicPreMatra || c == indicNukta; c = indicCat(buf, k) {
+		k++
+	}
+	for indicCat(buf, k) == indicModifier {
+		k++
+	}
+	return k, cons
+}
+
+// indicSetup finds the syllables of the glyphs, merging each into one
+// cluster, and for consonant syllables sets the masks of the reph, pre-base
+// (half) and post-base glyphs, and moves any pre-base matra before the
+// consonants
+func indicSetup(buf []otGlyph) {
+	for i := range buf {
+		buf[i].Cat = indicCategory(buf[i].Rune)
+	}
+	syl := 0
+	for st := 0; st < len(buf); syl++ {
+		ed, cons := indicSyllableEnd(buf, st)
+		for k := st; k < ed; k++ {
+			buf[k].Syl = syl
+			buf[k].Cluster = buf[st].Cluster
+		}
+		if cons {
+			indicSyllable(buf, st, ed)
+		}
+		st = ed
+	}
+}
+
+// indicSyllable sets up the consonant syllable [st, ed)
+func indicSyllable(buf []otGlyph, st, ed int) {
+	rephEnd := st
+	if buf[st].Cat == indicRa && indicCat(buf, st+1) == indicHalant && indicIsCons(buf, st+2) {
+		rephEnd = st + 2
+		buf[st].Mask |= otMaskRphf
+		buf[st+1].Mask |= otMaskRphf
+	}
+	base := -1
+	for k := ed - 1; k >= rephEnd; k-- {
+		if indicIsCons(buf, k) {
+			base = k
+			break
+		}
+	}
+	if base < 0 {
+		return
+	}
+	if buf[base].Cat == indicRa && indicCat(buf, base-1) == indicHalant { // below-base ra
+		for k := base - 2; k >= rephEnd; k-- {
+			if indicIsCons(buf, k) {
+				base = k
+				break
+			}
+		}
+	}
+	for k := rephEnd; k < ed; k++ {
+		switch {
+		case k < base:
+			buf[k].Mask |= otMaskHalf
+		case k > base && buf[k].Cat != indicPreMatra:
+			buf[k].Mask |= otMaskPost
+		}
+	}
+	for k := base + 1; k < ed; k++ {
+		if buf[k].Cat == indicPreMatra {
+			pm := buf[k]
+			copy(buf[rephEnd+1:k+1], buf[rephEnd:k])
+			buf[rephEnd] = pm
+		}
+	}
+}
+
+// indicFindReph marks the glyphs that became a reph, by the rphf feature
+func indicFindReph(buf []otGlyph) {
+	for i := range buf {
+		g := &buf[i]
+		if g.Mask&otMaskRphf == 0 || g.Cat != indicRa || !g.Subst || (i > 0 && buf[i-1].Syl == g.Syl) {
+			continue
+		}
+		if i+1 < len(buf) && buf[i+1].Syl == g.Syl && buf[i+1].Cat == indicHalant {
+			continue // did not ligate
+		}
+		g.Cat = indicReph
+	}
+}
+
+// indicFinalReorder moves reph glyphs to the end of their syllable (before
+// any modifiers), and pre-base matras after any halant that remains
+// explicit before the base, after the substitutions
+func indicFinalReorder(buf []otGlyph) {
+	for st := 0; st < len(buf); {
+		ed := st + 1
+		for ed < len(buf) && buf[ed].Syl == buf[st].Syl {
+			ed++
+		}
+		if buf[st].Cat == indicReph {
+			to := ed - 1
+			for to > st && buf[to].Cat == indicModifier {
+				to--
+			}
+			rg := buf[st]
+			copy(buf[st:to], buf[st+1:to+1])
+			buf[to] = rg
+		}
+		for k := st; k < ed; k++ {
+			if buf[k].Cat != indicPreMatra {
+				continue
+			}
+			to := k
+			for j := k + 1; j < ed-1; j++ {
+				if buf[j].Cat == indicHalant && indicIsCons(buf, j+1) {
+					to = j
+				}
+			}
+			if to > k {
+				pm := buf[k]
+				copy(buf[k:to], buf[k+1:to+1])
+				buf[to] = pm
+			}
+			break
+		}
+		st = ed
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  SpanRender shaping
+
+// ShapeLR shapes the text of the span using the OpenType layout of its fonts
+// (see TextShaping), in runs of the same face, script and direction, setting
+// the Glyphs that render it.  Returns the advance of each rune from the
+// shaping, with the advance of each cluster divided among its (non-mark)
+// runes -- runes in faces that have no layout tables have a -1 advance.
+// Glyphs and the advances are nil if nothing was shaped.  Must be called
+// with TextFontRenderMu locked.
+func (sr *SpanRender) ShapeLR() []float32 {
+	sr.Glyphs = nil
+	sz := len(sr.Text)
+	if !TextShaping || sz == 0 {
+		return nil
+	}
+	faces := make([]font.Face, sz)
+	scripts := make([]string, sz)
+	curFace := sr.Render[0].Face
+	lsc := ""
+	for i, r := range sr.Text {
+		curFace = sr.Render[i].CurFace(curFace)
+		faces[i] = curFace
+		if sc := ScriptTag(r); sc != "" {
+			if lsc == "" { // leading common runes take on the first script
+				for k := 0; k < i; k++ {
+					scripts[k] = sc
+				}
+			}
+			lsc = sc
+		}
+		scripts[i] = lsc
+	}
+	advs := make([]float32, sz)
+	gls := make([]GlyphRender, 0, sz)
+	shaped := false
+	for st := 0; st < sz; {
+		rtl := sr.Render[st].IsRTL()
+		ed := st + 1
+		for ed < sz && faces[ed] == faces[st] && scripts[ed] == scripts[st] && sr.Render[ed].IsRTL() == rtl {
+			ed++
+		}
+		fs := FaceShaper(faces[st])
+		if fs == nil {
+			for k := st; k < ed; k++ {
+				advs[k] = -1
+				r := sr.Text[k]
+				if rtl {
+					r = BidiMirror(r)
+				}
+				gls = append(gls, GlyphRender{Rune: r, Cluster: k, Attach: -1})
+			}
+			st = ed
+			continue
+		}
+		shaped = true
+		rg := fs.Shape(sr.Text[st:ed], scripts[st], rtl)
+		off := len(gls)
+		for gi := range rg {
+			rg[gi].Cluster += st
+			if rg[gi].Attach >= 0 {
+				rg[gi].Attach += off
+			}
+		}
+		sr.clusterAdvs(rg, ed, advs)
+		gls = append(gls, rg...)
+		st = ed
+	}
+	if !shaped {
+		return nil
+	}
+	sr.Glyphs = gls
+	return advs
+}
+
+// clusterAdvs sets the advances of the runes from the advances of the
+// clusters of given glyphs, which end at rune ed
+func (sr *SpanRender) clusterAdvs(gls []GlyphRender, ed int, advs []float32) {
+	for gi := 0; gi < len(gls); {
+		cs := gls[gi].Cluster
+		var adv float32
+		gj := gi
+		for ; gj < len(gls) && gls[gj].Cluster == cs; gj++ {
+			adv += gls[gj].Advance
+		}
+		ce := ed
+		if gj < len(gls) {
+			ce = gls[gj].Cluster
+		}
+		n := 0
+		for k := cs; k < ce; k++ {
+			advs[k] = 0
+			if !unicode.In(sr.Text[k], unicode.Mn, unicode.Me) {
+				n++
+			}
+		}
+		switch {
+		case n == 0:
+			advs[cs] = adv
+		default:
+			for k := cs; k < ce; k++ {
+				if !unicode.In(sr.Text[k], unicode.Mn, unicode.Me) {
+					advs[k] = adv / float32(n)
+				}
+			}
+		}
+		gi = gj
+	}
+}
+
+// GlyphsRange returns the shaped glyphs of the runes in given range [st, ed)
+// of the span, with clusters relative to st -- for splitting and trimming
+// spans -- nil if the span is not shaped
+func (sr *SpanRender) GlyphsRange(st, ed int) []GlyphRender {
+	if sr.Glyphs == nil {
+		return nil
+	}
+	var gls []GlyphRender
+	first := -1
+	for gi, g := range sr.Glyphs {
+		if g.Cluster < st || g.Cluster >= ed {
+			continue
+		}
+		if first < 0 {
+			first = gi
+		}
+		g.Cluster -= st
+		if g.Attach >= 0 {
+			g.Attach = ints.MaxInt(g.Attach-first, -1)
+		}
+		gls = append(gls, g)
+	}
+	return gls
+}
+
+// RenderGlyphs renders the shaped Glyphs of the span, each relative to the
+// visual position of the runes of its cluster, with their face and color --
+// called by TextRender Render, with TextFontRenderMu locked
+func (sr *SpanRender) RenderGlyphs(rs *RenderState, tpos Vec2D) {
+	nr := len(sr.Render)
+	faces := make([]font.Face, nr)
+	clrs := make([]color.Color, nr)
+	curFace := sr.Render[0].Face
+	curColor := sr.Render[0].Color
+	for i := range sr.Render {
+		curFace = sr.Render[i].CurFace(curFace)
+		curColor = sr.Render[i].CurColor(curColor)
+		faces[i], clrs[i] = curFace, curColor
+	}
+	var src image.Image
+	var srcColor color.Color
+	gpos := make([]Vec2D, len(sr.Glyphs))
+	cs := -1
+	var cleft float32
+	for gi := range sr.Glyphs {
+		g := &sr.Glyphs[gi]
+		if g.Cluster >= nr {
+			break
+		}
+		if g.Cluster != cs { // left of the runes of the new cluster
+			cs = g.Cluster
+			ce := nr
+			for gj := gi + 1; gj < len(sr.Glyphs); gj++ {
+				if sr.Glyphs[gj].Cluster != cs {
+					ce = ints.MinInt(sr.Glyphs[gj].Cluster, nr)
+					break
+				}
+			}
+			cleft = sr.Render[cs].RelPos.X
+			for k := cs + 1; k < ce; k++ {
+				cleft = math32.Min(cleft, sr.Render[k].RelPos.X)
+			}
+		}
+		rr := &sr.Render[cs]
+		if g.Attach >= 0 && g.Attach < gi {
+			gpos[gi] = gpos[g.Attach].Add(g.Pos)
+		} else {
+			gpos[gi] = Vec2D{cleft + g.Pos.X, rr.RelPos.Y + g.Pos.Y}
+		}
+		if g.Rune != 0 && !unicode.IsPrint(g.Rune) {
+			continue
+		}
+		rp := tpos.Add(gpos[gi])
+		if int(math32.Floor(rp.X)) > rs.Bounds.Max.X || int(math32.Floor(rp.Y-rr.Size.Y)) > rs.Bounds.Max.Y ||
+			int(math32.Ceil(rp.X+rr.Size.X)) < rs.Bounds.Min.X || int(math32.Ceil(rp.Y+rr.Size.Y)) < rs.Bounds.Min.Y {
+			continue
+		}
+		face := faces[cs]
+		var dr image.Rectangle
+		var mask image.Image
+		var maskp image.Point
+		ok := false
+		if g.Rune != 0 {
+			dr, mask, maskp, _, ok = face.Glyph(rp.Fixed(), g.Rune)
+		} else if fs := FaceShaper(face); fs != nil {
+			dr, mask, maskp, ok = fs.GlyphMask(rp.Fixed(), g.ID)
+		}
+		if !ok {
+			continue
+		}
+		if src == nil || clrs[cs] != srcColor {
+			srcColor = clrs[cs]
+			src = image.NewUniform(srcColor)
+		}
+		renderGlyphMask(rs, src, rr, rp, dr, mask, maskp)
+	}
+}
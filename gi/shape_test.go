@@ -0,0 +1,188 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// otU16s returns the big-endian bytes of given 16-bit values
+func otU16s(vals ...int) []byte {
+	b := make([]byte, 0, 2*len(vals))
+	for _, v := range vals {
+		b = append(b, byte(v>>8), byte(v))
+	}
+	return b
+}
+
+type testOTLookup struct {
+	typ, flag int
+	sub       []byte
+}
+
+// testOTLayout returns a GSUB or GPOS table with one DFLT script, with one
+// feature of given tag that uses the first lookup, and given lookups each
+// with one sub-table
+func testOTLayout(tag string, gpos bool, lookups ...testOTLookup) *otLayout {
+	t := otU16s(1, 0, 10, 30, 44)                      // header
+	t = append(t, otU16s(1)...)                        // script list
+	t = append(t, "DFLT"...)                           //
+	t = append(t, otU16s(8, 4, 0, 0, 0xFFFF, 1, 0)...) // script, langsys
+	t = append(t, otU16s(1)...)                        // feature list
+	t = append(t, tag...)                              //
+	t = append(t, otU16s(8, 0, 1, 0)...)               // feature
+	t = append(t, otU16s(len(lookups))...)             // lookup list
+	off := 2 + 2*len(lookups)
+	for _, lk := range lookups {
+		t = append(t, otU16s(off)...)
+		off += 8 + len(lk.sub)
+	}
+	for _, lk := range lookups {
+		t = append(t, otU16s(lk.typ, lk.flag, 1, 8)...)
+		t = append(t, lk.sub...)
+	}
+	return newOTLayout(t, gpos)
+}
+
+// testOTApply applies the feature of given layout to glyphs with given ids
+// and classes (base if 0)
+func testOTApply(lay *otLayout, tag string, ids []int, classes []uint16) []otGlyph {
+	a := &otApplier{Lay: lay}
+	for i, id := range ids {
+		g := otGlyph{ID: sfnt.GlyphIndex(id), Cluster: i, Mask: otMaskGlobal, Class: otClassBase, XAdv: 500, Attach: -1}
+		if i < len(classes) && classes[i] != 0 {
+			g.Class = classes[i]
+		}
+		a.Buf = append(a.Buf, g)
+	}
+	a.ApplyFeatures(lay.LangSys("DFLT"), []otFeature{{tag, otMaskGlobal}})
+	return a.Buf
+}
+
+func testOTIDs(buf []otGlyph) []int {
+	ids := make([]int, len(buf))
+	for i := range buf {
+		ids[i] = int(buf[i].ID)
+	}
+	return ids
+}
+
+func testOTEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestOTSubst(t *testing.T) {
+	// ligature 10 11 -> 99, ignoring marks
+	lig := testOTLookup{4, otIgnoreMarks, append(otU16s(1, 8, 1, 14, 1, 1, 10), otU16s(1, 4, 99, 2, 11)...)}
+	lay := testOTLayout("liga", false, lig)
+	buf := testOTApply(lay, "liga", []int{10, 11, 12, 10}, nil)
+	if ids := testOTIDs(buf); !testOTEqual(ids, []int{99, 12, 10}) {
+		t.Errorf("ligature: %v", ids)
+	}
+	if buf[0].Cluster != 0 || buf[1].Cluster != 2 {
+		t.Errorf("ligature clusters: %v %v", buf[0].Cluster, buf[1].Cluster)
+	}
+	buf = testOTApply(lay, "liga", []int{10, 50, 11}, []uint16{0, otClassMark})
+	if ids := testOTIDs(buf); !testOTEqual(ids, []int{99, 50}) || buf[1].LigComp != 1 || buf[1].Cluster != 0 {
+		t.Errorf("ligature skipping mark: %v, comp: %v", ids, buf[1].LigComp)
+	}
+	if buf := testOTApply(lay, "calt", []int{10, 11}, nil); !testOTEqual(testOTIDs(buf), []int{10, 11}) {
+		t.Errorf("ligature applied for other feature: %v", testOTIDs(buf))
+	}
+
+	// chaining context: 10 after 20 -> single subst +5
+	single := testOTLookup{1, 0, otU16s(1, 6, 5, 1, 1, 10)}
+	chain := testOTLookup{6, 0, append(otU16s(3, 1, 18, 1, 24, 0, 1, 0, 1), otU16s(1, 1, 20, 1, 1, 10)...)}
+	lay = testOTLayout("calt", false, chain, single)
+	buf = testOTApply(lay, "calt", []int{10, 20, 10, 10}, nil)
+	if ids := testOTIDs(buf); !testOTEqual(ids, []int{10, 20, 15, 10}) {
+		t.Errorf("chaining context: %v", ids)
+	}
+}
+
+func TestOTPos(t *testing.T) {
+	// pair kerning 10 11: -50 advance
+	pair := testOTLookup{2, 0, append(otU16s(1, 12, 4, 0, 1, 18, 1, 1, 10), otU16s(1, 11, 0xFFCE)...)}
+	lay := testOTLayout("kern", true, pair)
+	buf := testOTApply(lay, "kern", []int{10, 11, 10, 12}, nil)
+	if buf[0].XAdv != 450 || buf[1].XAdv != 500 || buf[2].XAdv != 500 {
+		t.Errorf("pair kerning advances: %v %v %v", buf[0].XAdv, buf[1].XAdv, buf[2].XAdv)
+	}
+
+	// mark 50 on base 10: base anchor 300,700, mark anchor 100,200
+	mark := testOTLookup{4, 0, append(otU16s(1, 12, 18, 1, 24, 36, 1, 1, 50, 1, 1, 10),
+		otU16s(1, 0, 6, 1, 100, 200, 1, 4, 1, 300, 700)...)}
+	lay = testOTLayout("mark", true, mark)
+	buf = testOTApply(lay, "mark", []int{10, 50}, []uint16{0, otClassMark})
+	if g := buf[1]; g.Attach != 0 || g.XOff != 200 || g.YOff != 500 {
+		t.Errorf("mark attachment: %v, offset: %v, %v", g.Attach, g.XOff, g.YOff)
+	}
+}
+
+func TestArabicForms(t *testing.T) {
+	tests := []struct {
+		txt   string
+		forms string
+	}{
+		{"بسم", "nmf"},
+		{"دار", "iii"},
+		{"بدب", "nfi"},
+		{"بَب", "n\x00f"},      // fatha is transparent
+		{"ب\u200cب", "i\x00i"}, // zwnj breaks joining
+		{"بـ", "n\x00"},        // tatweel joins
+	}
+	for _, ts := range tests {
+		if forms := string(ArabicForms([]rune(ts.txt))); forms != ts.forms {
+			t.Errorf("ArabicForms of %q: %q, want: %q", ts.txt, forms, ts.forms)
+		}
+	}
+}
+
+func TestIndicReorder(t *testing.T) {
+	buf := func(txt string) []otGlyph {
+		var b []otGlyph
+		for i, r := range txt {
+			b = append(b, otGlyph{ID: sfnt.GlyphIndex(r), Rune: r, Cluster: i, Mask: otMaskGlobal, Attach: -1})
+		}
+		indicSetup(b)
+		return b
+	}
+	runes := func(b []otGlyph) string {
+		rs := make([]rune, len(b))
+		for i := range b {
+			rs[i] = b[i].Rune
+		}
+		return string(rs)
+	}
+	b := buf("कि")
+	if rs := runes(b); rs != "िक" {
+		t.Errorf("pre-base matra: %q", rs)
+	}
+	if b[0].Cluster != b[1].Cluster {
+		t.Errorf("syllable clusters not merged")
+	}
+	b = buf("र्कि")
+	if rs := runes(b); rs != "र्िक" || b[0].Mask&otMaskRphf == 0 || b[1].Mask&otMaskRphf == 0 {
+		t.Errorf("reph: %q", rs)
+	}
+	b = buf("स्थि") // no half form: matra moves after the explicit halant
+	indicFinalReorder(b)
+	if rs := runes(b); rs != "स्िथ" {
+		t.Errorf("final matra: %q", rs)
+	}
+	if b[0].Mask&otMaskHalf == 0 || b[2].Mask&otMaskHalf != 0 {
+		t.Errorf("half masks: %v %v", b[0].Mask, b[2].Mask)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"image"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/key"
@@ -108,6 +109,52 @@ func (sv *SplitView) SetSplitsAction(splits ...float32) {
 	sv.Viewport.FullRender2DTree()
 }
 
+// AnimateSplits smoothly changes the split proportions from the current ones
+// to given ones over given duration, with given easing function (linear if
+// nil) -- re-renders the splitview at each step, and does a full rebuild at
+// the end as in SetSplitsAction
+func (sv *SplitView) AnimateSplits(dur time.Duration, ease EaseFunc, splits ...float32) {
+	win := sv.ParentWindow()
+	if win == nil || dur <= 0 {
+		sv.SetSplitsAction(splits...)
+		return
+	}
+	sv.UpdateSplits()
+	from := make([]float32, len(sv.Splits))
+	copy(from, sv.Splits)
+	to := make([]float32, len(from))
+	copy(to, from)
+	copy(to, splits)
+	sum := float32(0)
+	for _, sp := range to {
+		sum += sp
+	}
+	for i := range to {
+		if sum == 0 {
+			to[i] = 1 / float32(len(to))
+		} else {
+			to[i] /= sum
+		}
+	}
+	cur := make([]float32, len(to))
+	win.Anims.Start(&Anim{Recv: sv.This(), Name: "splits", Dur: dur, Ease: ease,
+		Func: func(p float32) {
+			if len(sv.Splits) != len(to) { // kids changed in the meantime
+				return
+			}
+			for i := range to {
+				cur[i] = InterpolateFloat32(from[i], to[i], p)
+			}
+			sv.SetSplits(cur...)
+			sv.ReRender2DTree()
+		},
+		Done: func() {
+			if len(sv.Splits) == len(to) {
+				sv.SetSplitsAction(to...)
+			}
+		}})
+}
+
 // SaveSplits saves the current set of splits in SavedSplits, for a later RestoreSplits
 func (sv *SplitView) SaveSplits() {
 	sz := len(sv.Splits)
@@ -40,6 +40,8 @@ type Style struct {
 	Text          TextStyle     `desc:"text parameters -- no xml prefix"`
 	Outline       BorderStyle   `xml:"outline" desc:"prop: outline = draw an outline around an element -- mostly same styles as border -- default to none"`
 	PointerEvents bool          `xml:"pointer-events" desc:"prop: pointer-events = does this element respond to pointer events -- default is true"`
+	Transition    string        `xml:"transition" desc:"prop: transition = animate changes of style properties when the state of the element changes (e.g., :hover) -- comma-separated list of 'property duration [easing] [delay]' items as in CSS, e.g., 'background-color 0.2s ease-out' -- color, length and number properties can transition -- see ParseTransitions"`
+	Transitions   []Transition  `xml:"-" json:"-" desc:"transitions parsed from the Transition property"`
 	UnContext     units.Context `xml:"-" desc:"units context -- parameters necessary for anchoring relative units"`
 	IsSet         bool          `desc:"has this style been set from object values yet?"`
 	PropsNil      bool          `desc:"set to true if parent node has no props -- allows optimization of styling"`
@@ -47,8 +49,6 @@ type Style struct {
 	lastUnCtxt    units.Context
 }
 
-// todo: Animation
-
 // Clear -- no floating elements
 
 // Clip -- clip images
@@ -65,8 +65,6 @@ type Style struct {
 
 // visibility -- support more than just hidden  inherit:"true"
 
-// transition -- animation of hover, etc
-
 // RebuildDefaultStyles is a global state var used by Prefs to trigger rebuild
 // of all the default styles, which are otherwise compiled and not updated
 var RebuildDefaultStyles bool
@@ -233,6 +231,9 @@ func (s *Style) SetStyleProps(par *Style, props ki.Props, vp *Viewport2D) {
 		s.InheritFields(par)
 	}
 	StyleFields.Style(s, par, props, vp)
+	if _, has := props["transition"]; has {
+		s.Transitions = ParseTransitions(s.Transition)
+	}
 	s.Text.AlignV = s.Layout.AlignV
 	if s.Layout.Margin.Val > 0 && s.Text.ParaSpacing.Val == 0 {
 		s.Text.ParaSpacing = s.Layout.Margin
@@ -5,10 +5,17 @@
 package gi
 
 import (
+	"fmt"
+	"image"
 	"log"
 	"reflect"
+	"strconv"
 	"sync"
 
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/dnd"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/oswin/mouse"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki"
 	"github.com/goki/ki/kit"
@@ -20,14 +27,21 @@ import (
 // HorizFlow Layout for the tabs (which can flow across multiple rows as
 // needed) and a Stacked Frame that actually contains all the children, and
 // provides scrollbars as needed to any content within.  Typically should have
-// max stretch and a set preferred size, so it expands.
+// max stretch and a set preferred size, so it expands.  Tabs can be dragged
+// to reorder them, onto another TabView to move them there, or outside of
+// any TabView to tear them off into a new window.  Tabs that do not fit
+// scroll with the mouse wheel, and the context menu of the tabs lists all of
+// them.
 type TabView struct {
 	Layout
-	MaxChars     int          `desc:"maximum number of characters to include in tab label -- elides labels that are longer than that"`
-	TabViewSig   ki.Signal    `json:"-" xml:"-" desc:"signal for tab widget -- see TabViewSignals for the types"`
-	NewTabButton bool         `desc:"show a new tab button at right of list of tabs"`
-	NewTabType   reflect.Type `desc:"type of widget to create in a new tab via new tab button -- Frame by default"`
-	Mu           sync.Mutex   `json:"-" xml:"-" view:"-" desc:"mutex protecting updates to tabs -- tabs can be driven programmatically and via user input so need extra protection"`
+	MaxChars          int          `desc:"maximum number of characters to include in tab label -- elides labels that are longer than that"`
+	TabViewSig        ki.Signal    `json:"-" xml:"-" desc:"signal for tab widget -- see TabViewSignals for the types"`
+	NewTabButton      bool         `desc:"show a new tab button at right of list of tabs"`
+	NewTabType        reflect.Type `desc:"type of widget to create in a new tab via new tab button -- Frame by default"`
+	ConfirmDirtyClose bool         `desc:"prompt the user for confirmation before closing a tab that is marked as dirty (see SetTabDirty)"`
+	CtxtTab           int          `json:"-" xml:"-" view:"-" desc:"index of the tab that the context menu was opened on -- -1 if none"`
+	CtxtPos           image.Point  `json:"-" xml:"-" view:"-" desc:"window position where the context menu was opened"`
+	Mu                sync.Mutex   `json:"-" xml:"-" view:"-" desc:"mutex protecting updates to tabs -- tabs can be driven programmatically and via user input so need extra protection"`
 }
 
 var KiT_TabView = kit.Types.AddType(&TabView{}, TabViewProps)
@@ -108,6 +122,7 @@ func (tv *TabView) InsertTab(widg Node2D, label string, idx int) {
 	tv.SetFullReRender()
 	fr.InsertChild(widg, idx)
 	tv.InsertTabOnlyAt(widg, label, idx)
+	tv.RenumberTabs()
 	tv.Mu.Unlock()
 	tv.UpdateEnd(updt)
 }
@@ -137,6 +152,7 @@ func (tv *TabView) InsertNewTab(typ reflect.Type, label string, idx int) Node2D
 	tv.SetFullReRender()
 	widg := fr.InsertNewChild(typ, idx, label).(Node2D)
 	tv.InsertTabOnlyAt(widg, label, idx)
+	tv.RenumberTabs()
 	tv.UpdateEnd(updt)
 	return widg
 }
@@ -178,6 +194,7 @@ func (tv *TabView) SelectTabIndex(idx int) (Node2D, bool) {
 	widg.AsNode2D().SetFullReRender()
 	// frame  / layout will set invisible etc
 	tv.Mu.Unlock()
+	tv.Tabs().ScrollToItem(tab)
 	tv.UpdateEnd(updt)
 	return widg, true
 }
@@ -322,6 +339,20 @@ const (
 	// TabDeleted indicates tab was deleted -- data is the tab name
 	TabDeleted
 
+	// TabMoved indicates tab was moved to a new position by the user --
+	// data is the new tab index
+	TabMoved
+
+	// TabRemoved indicates tab was removed without destroying its contents,
+	// by moving it to another TabView or tearing it off into a new window --
+	// data is the tab name
+	TabRemoved
+
+	// TabCloseReq indicates a request to close a tab, e.g., by its close
+	// button -- data is a *TabCloseData, and setting its Veto prevents the
+	// tab from being closed
+	TabCloseReq
+
 	TabViewSignalsN
 )
 
@@ -345,7 +376,8 @@ func (tv *TabView) InitTabView() {
 	// tabs.SetStretchMaxHeight()
 	// tabs.SetMinPrefWidth(units.NewValue(10, units.Em))
 	tabs.SetProp("height", units.NewValue(1.8, units.Em))
-	tabs.SetProp("overflow", "hidden") // no scrollbars!
+	tabs.SetProp("overflow", "auto") // scrolls, but with no visible scrollbars
+	tabs.SetProp("scrollbar-width", units.NewValue(0, units.Px))
 	tabs.SetProp("padding", units.NewValue(0, units.Px))
 	tabs.SetProp("margin", units.NewValue(0, units.Px))
 	tabs.SetProp("spacing", units.NewValue(4, units.Px))
@@ -442,6 +474,395 @@ func (tv *TabView) Render2D() {
 	}
 }
 
+func (tv *TabView) ConnectEvents2D() {
+	tv.Layout.ConnectEvents2D()
+	tv.TabViewDNDEvents()
+	tv.TabViewMouseEvents()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//    Moving, closing and dirty tabs
+
+// TabDirtyMarker is shown before the label of tabs that are marked as dirty
+var TabDirtyMarker = "* "
+
+// SetTabDirty sets whether the contents of the tab at given index have
+// unsaved changes, which is shown by TabDirtyMarker before the label
+func (tv *TabView) SetTabDirty(idx int, dirty bool) {
+	_, tab, ok := tv.TabAtIndex(idx)
+	if !ok || tab.Dirty == dirty {
+		return
+	}
+	tab.Dirty = dirty
+	if dirty {
+		tab.SetText(TabDirtyMarker + tab.Nm)
+	} else {
+		tab.SetText(tab.Nm)
+	}
+}
+
+// TabIsDirty returns whether the tab at given index is marked as dirty
+func (tv *TabView) TabIsDirty(idx int) bool {
+	sz := tv.NTabs()
+	if idx < 0 || idx >= sz {
+		return false
+	}
+	return tv.Tabs().KnownChild(idx).Embed(KiT_TabButton).(*TabButton).Dirty
+}
+
+// DirtyTabs returns the indexes of all the tabs marked as dirty
+func (tv *TabView) DirtyTabs() []int {
+	var dts []int
+	sz := tv.NTabs()
+	for i := 0; i < sz; i++ {
+		if tv.TabIsDirty(i) {
+			dts = append(dts, i)
+		}
+	}
+	return dts
+}
+
+// TabCloseData is the data for the TabCloseReq signal -- receivers can set
+// Veto to prevent the tab from closing, e.g., to first prompt to save its
+// changes, and then close it with DeleteTabIndexAction
+type TabCloseData struct {
+	Index int    `desc:"index of the tab"`
+	Name  string `desc:"name of the tab"`
+	Dirty bool   `desc:"whether the tab is marked as dirty"`
+	Veto  bool   `desc:"set by receivers to prevent the tab from closing"`
+}
+
+// CloseTabIndexAction requests to close the tab at given index: emits the
+// TabCloseReq signal, and unless a receiver vetoes it, deletes the tab with
+// DeleteTabIndexAction -- if ConfirmDirtyClose is set, dirty tabs are only
+// deleted after the user confirms it -- returns true if deleted.  This is
+// called by the close button on the tab.
+func (tv *TabView) CloseTabIndexAction(idx int) bool {
+	if idx < 0 || idx >= tv.NTabs() {
+		return false
+	}
+	cd := &TabCloseData{Index: idx, Name: tv.TabName(idx), Dirty: tv.TabIsDirty(idx)}
+	tv.TabViewSig.Emit(tv.This(), int64(TabCloseReq), cd)
+	if cd.Veto {
+		return false
+	}
+	if cd.Dirty && tv.ConfirmDirtyClose {
+		ChoiceDialog(tv.Viewport, DlgOpts{Title: "Close Without Saving?",
+			Prompt: fmt.Sprintf("The tab: %v has unsaved changes -- do you want to close it anyway, discarding the changes?", cd.Name)},
+			[]string{"Close Without Saving", "Cancel"},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				if sig != 0 {
+					return
+				}
+				tvv := recv.Embed(KiT_TabView).(*TabView)
+				if _, idx, ok := tvv.TabByName(cd.Name); ok {
+					tvv.DeleteTabIndexAction(idx)
+				}
+			})
+		return false
+	}
+	tv.DeleteTabIndexAction(idx)
+	return true
+}
+
+// MoveTab moves the tab at index from to be before the tab at index to
+// (NTabs to move it to the end), keeping the same tab selected -- returns
+// false if not moved
+func (tv *TabView) MoveTab(from, to int) bool {
+	sz := tv.NTabs()
+	if from < 0 || from >= sz || to < 0 || to > sz {
+		return false
+	}
+	if to > from {
+		to-- // index after removing from
+	}
+	if to == from {
+		return false
+	}
+	cur, _, _ := tv.CurTab()
+	dirty := tv.TabIsDirty(from)
+	updt := tv.UpdateStart()
+	widg, label, _ := tv.DeleteTabIndex(from, false)
+	tv.InsertTab(widg, label, to)
+	tv.SetTabDirty(to, dirty)
+	fr := tv.Frame()
+	for i, k := range fr.Kids {
+		if cur != nil && k.This() == cur.This() {
+			fr.StackTop = -1 // force update
+			tv.SelectTabIndex(i)
+			break
+		}
+	}
+	tv.UpdateEnd(updt)
+	return true
+}
+
+// MoveTabAction moves the tab at index from to be before the tab at index
+// to, and emits the TabMoved signal with its new index
+func (tv *TabView) MoveTabAction(from, to int) {
+	if tv.MoveTab(from, to) {
+		if to > from {
+			to--
+		}
+		tv.TabViewSig.Emit(tv.This(), int64(TabMoved), to)
+	}
+}
+
+// MoveTabTo moves the tab at given index to another TabView, before the tab
+// at index to there, and selects it -- returns false if not moved
+func (tv *TabView) MoveTabTo(idx int, otv *TabView, to int) bool {
+	if to < 0 || to > otv.NTabs() {
+		return false
+	}
+	dirty := tv.TabIsDirty(idx)
+	widg, label, ok := tv.DeleteTabIndex(idx, false)
+	if !ok {
+		return false
+	}
+	otv.InsertTab(widg, label, to)
+	otv.SetTabDirty(to, dirty)
+	otv.SelectTabIndex(to)
+	return true
+}
+
+// MoveTabToAction moves the tab at given index to another TabView, and emits
+// the TabRemoved signal here and the TabAdded signal there
+func (tv *TabView) MoveTabToAction(idx int, otv *TabView, to int) {
+	label := tv.TabName(idx)
+	if tv.MoveTabTo(idx, otv, to) {
+		tv.TabViewSig.Emit(tv.This(), int64(TabRemoved), label)
+		otv.TabViewSig.Emit(otv.This(), int64(TabAdded), to)
+	}
+}
+
+// TearOffTab moves the tab at given index into a TabView in a new window,
+// and returns that window -- nil if the index is invalid
+func (tv *TabView) TearOffTab(idx int) *Window {
+	dirty := tv.TabIsDirty(idx)
+	widg, label, ok := tv.DeleteTabIndex(idx, false)
+	if !ok {
+		return nil
+	}
+	win := NewWindow2D("gogi-tab-win:"+label, label, 800, 600, true)
+	if win == nil {
+		return nil
+	}
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	ntv := mfr.AddNewChild(KiT_TabView, "tabs").(*TabView)
+	ntv.MaxChars = tv.MaxChars
+	ntv.ConfirmDirtyClose = tv.ConfirmDirtyClose
+	ntv.AddTab(widg, label)
+	ntv.SetTabDirty(0, dirty)
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+	return win
+}
+
+// TearOffTabAction tears off the tab at given index into a new window, and
+// emits the TabRemoved signal with its name
+func (tv *TabView) TearOffTabAction(idx int) {
+	label := tv.TabName(idx)
+	if win := tv.TearOffTab(idx); win != nil {
+		tv.TabViewSig.Emit(tv.This(), int64(TabRemoved), label)
+	}
+}
+
+// TabAtPos returns the index of the tab at given window position -- -1 if none
+func (tv *TabView) TabAtPos(pos image.Point) int {
+	tbs := tv.Tabs()
+	sz := tv.NTabs()
+	for i := 0; i < sz; i++ {
+		if _, ni := KiToNode2D(tbs.KnownChild(i)); ni != nil && pos.In(ni.WinBBox) {
+			return i
+		}
+	}
+	return -1
+}
+
+// TabInsertIndex returns the index where a tab dropped at given window
+// position goes: before the first tab whose center is to the right of it
+func (tv *TabView) TabInsertIndex(pos image.Point) int {
+	tbs := tv.Tabs()
+	sz := tv.NTabs()
+	for i := 0; i < sz; i++ {
+		if _, ni := KiToNode2D(tbs.KnownChild(i)); ni != nil && pos.X < (ni.WinBBox.Min.X+ni.WinBBox.Max.X)/2 {
+			return i
+		}
+	}
+	return sz
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//    Drag-n-Drop and context menu
+
+// TabMimeType is the mime type for a tab being dragged from a TabView --
+// the data is the tab index, and the TabView is the drag source
+const TabMimeType = "application/x-gogi-tab"
+
+// TabIndexFromMimeData returns the index of the tab in given mime data --
+// false if none
+func TabIndexFromMimeData(md mimedata.Mimes) (int, bool) {
+	for _, d := range md {
+		if d.Type == TabMimeType {
+			idx, err := strconv.Atoi(string(d.Data))
+			return idx, err == nil
+		}
+	}
+	return -1, false
+}
+
+// StartTabDrag starts dragging the tab at given index, with given data,
+// using an image of the tab, with this TabView as the source
+func (tv *TabView) StartTabDrag(idx int, md mimedata.Mimes) {
+	_, tab, ok := tv.TabAtIndex(idx)
+	if !ok {
+		return
+	}
+	bi := &Bitmap{}
+	bi.InitName(bi, tab.UniqueName())
+	bi.GrabRenderFrom(tab)
+	ImageClearer(bi.Pixels, 50.0)
+	tv.Viewport.Win.StartDragNDrop(tv.This(), md, bi)
+}
+
+// DragNDropStart starts dragging the tab at the event position, if any
+func (tv *TabView) DragNDropStart(de *dnd.Event) {
+	idx := tv.TabAtPos(de.Where)
+	if idx < 0 {
+		return
+	}
+	de.SetProcessed()
+	tv.StartTabDrag(idx, mimedata.Mimes{&mimedata.Data{Type: TabMimeType, Data: []byte(strconv.Itoa(idx))}})
+}
+
+// DragNDropTarget handles a drop of a tab on this TabView: it is moved to the
+// position of the drop among the tabs, or to the end if not dropped on the
+// tabs -- dropping a tab on its own contents does nothing
+func (tv *TabView) DragNDropTarget(de *dnd.Event) {
+	idx, ok := TabIndexFromMimeData(de.Data)
+	if !ok || de.Source == nil {
+		return
+	}
+	stv, ok := de.Source.Embed(KiT_TabView).(*TabView)
+	if !ok {
+		return
+	}
+	de.Target = tv.This()
+	de.Mod = dnd.DropMove
+	de.SetProcessed()
+	onTabs := de.Where.In(tv.Tabs().WinBBox)
+	to := tv.NTabs()
+	if onTabs {
+		to = tv.TabInsertIndex(de.Where)
+	}
+	if stv.This() == tv.This() {
+		if onTabs {
+			tv.MoveTabAction(idx, to)
+		}
+		return
+	}
+	stv.MoveTabToAction(idx, tv, to)
+}
+
+// DragNDropIgnored satisfies the DragNDropIgnorer interface: a tab dragged
+// from this TabView that was not dropped on anything that accepted it is
+// torn off into a new window
+func (tv *TabView) DragNDropIgnored(de *dnd.Event) {
+	if idx, ok := TabIndexFromMimeData(de.Data); ok {
+		tv.TearOffTabAction(idx)
+	}
+}
+
+// TabViewDNDEvents connects the drag-n-drop events for the tabs
+func (tv *TabView) TabViewDNDEvents() {
+	tv.ConnectEvent(oswin.DNDEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.Event)
+		tvv := recv.Embed(KiT_TabView).(*TabView)
+		switch de.Action {
+		case dnd.Start:
+			tvv.DragNDropStart(de)
+		case dnd.DropOnTarget:
+			tvv.DragNDropTarget(de)
+		}
+	})
+	tv.ConnectEvent(oswin.DNDFocusEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.FocusEvent)
+		tvv := recv.Embed(KiT_TabView).(*TabView)
+		win := tvv.Viewport.Win
+		if _, ok := TabIndexFromMimeData(win.DNDData); !ok {
+			return
+		}
+		switch de.Action {
+		case dnd.Enter:
+			win.DNDSetCursor(dnd.DropMove)
+		case dnd.Exit:
+			win.DNDNotCursor()
+		}
+	})
+}
+
+// TabViewMouseEvents connects the context menu for the tabs
+func (tv *TabView) TabViewMouseEvents() {
+	tv.ConnectEvent(oswin.MouseEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		tvv := recv.Embed(KiT_TabView).(*TabView)
+		if me.Action != mouse.Release || me.Button != mouse.Right || !me.Where.In(tvv.Tabs().WinBBox) {
+			return
+		}
+		me.SetProcessed()
+		tvv.CtxtTab = tvv.TabAtPos(me.Where)
+		tvv.CtxtPos = me.Where
+		tvv.This().(Node2D).ContextMenu()
+	})
+}
+
+// MakeTabsMenu adds actions to select each of the tabs to given menu --
+// this is how tabs that do not fit can be reached
+func (tv *TabView) MakeTabsMenu(m *Menu) {
+	sz := tv.NTabs()
+	_, cur, _ := tv.CurTab()
+	for i := 0; i < sz; i++ {
+		label := tv.TabName(i)
+		if tv.TabIsDirty(i) {
+			label = TabDirtyMarker + label
+		}
+		ac := m.AddAction(ActOpts{Label: label, Data: i}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv := recv.Embed(KiT_TabView).(*TabView)
+			tvv.SelectTabIndexAction(data.(int))
+		})
+		ac.SetSelectedState(i == cur)
+	}
+}
+
+// MakeContextMenu makes the menu for the tab the context menu was opened on,
+// followed by the list of all tabs
+func (tv *TabView) MakeContextMenu(m *Menu) {
+	if tv.CtxtTab >= 0 && tv.CtxtTab < tv.NTabs() {
+		label := tv.TabName(tv.CtxtTab)
+		m.AddAction(ActOpts{Label: "Close " + label, Data: label}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv := recv.Embed(KiT_TabView).(*TabView)
+			if _, idx, ok := tvv.TabByName(data.(string)); ok {
+				tvv.CloseTabIndexAction(idx)
+			}
+		})
+		m.AddAction(ActOpts{Label: "Move to New Window", Data: label}, tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv := recv.Embed(KiT_TabView).(*TabView)
+			if _, idx, ok := tvv.TabByName(data.(string)); ok {
+				tvv.TearOffTabAction(idx)
+			}
+		})
+		m.AddSeparator("sep-tabs")
+	}
+	tv.MakeTabsMenu(m)
+}
+
+// ContextMenuPos is where the context menu was opened
+func (tv *TabView) ContextMenuPos() image.Point {
+	return tv.CtxtPos
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////
 // TabButton
 
@@ -449,6 +870,7 @@ func (tv *TabView) Render2D() {
 // icon is used for close icon.
 type TabButton struct {
 	Action
+	Dirty bool `desc:"contents of the tab have unsaved changes -- shown by TabDirtyMarker before the label"`
 }
 
 var KiT_TabButton = kit.Types.AddType(&TabButton{}, TabButtonProps)
@@ -558,7 +980,7 @@ func (tb *TabButton) ConfigParts() {
 			tvv := tb.TabView()
 			if tvv != nil {
 				if tbb.IsSelected() { // only process delete when already selected
-					tvv.DeleteTabIndexAction(tabIdx)
+					tvv.CloseTabIndexAction(tabIdx)
 				} else {
 					tvv.SelectTabIndexAction(tabIdx) // otherwise select
 				}
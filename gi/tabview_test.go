@@ -0,0 +1,209 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi_test
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gitest"
+	"github.com/goki/ki"
+)
+
+// tabWin makes a window with two TabViews side by side: tva with tabs a1,
+// a2, a3 and tvb with tab b1, and starts a harness for it
+func tabWin(t *testing.T, name string) (*gitest.Harness, *gi.TabView, *gi.TabView) {
+	win := gi.NewWindow2D(name, name, 800, 400, true)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	mfr.Lay = gi.LayoutHoriz
+	var tvs [2]*gi.TabView
+	for i, tabs := range [][]string{{"a1", "a2", "a3"}, {"b1"}} {
+		tv := mfr.AddNewChild(gi.KiT_TabView, "tv"+tabs[0][:1]).(*gi.TabView)
+		for _, tab := range tabs {
+			lbl, _ := tv.AddNewTab(gi.KiT_Label, tab)
+			lbl.(*gi.Label).SetText("contents of " + tab)
+		}
+		tv.SelectTabIndex(0)
+		tvs[i] = tv
+	}
+	vp.UpdateEndNoSig(updt)
+	h := gitest.NewHarness(win)
+	if err := h.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return h, tvs[0], tvs[1]
+}
+
+// tabNames returns the names of the tabs, separated by spaces
+func tabNames(tv *gi.TabView) string {
+	nms := make([]string, tv.NTabs())
+	for i := range nms {
+		nms[i] = tv.TabName(i)
+	}
+	return strings.Join(nms, " ")
+}
+
+// tabButton returns the tab button at given index
+func tabButton(t *testing.T, tv *gi.TabView, idx int) *gi.TabButton {
+	_, tab, ok := tv.TabAtIndex(idx)
+	if !ok {
+		t.Fatalf("no tab %v in %v", idx, tv.Nm)
+	}
+	return tab
+}
+
+func TestTabViewMoveTab(t *testing.T) {
+	h, tva, tvb := tabWin(t, "test-tabview-move")
+	defer h.Close()
+
+	if !tva.MoveTab(0, 3) {
+		t.Errorf("MoveTab to end not moved")
+	}
+	if nms := tabNames(tva); nms != "a2 a3 a1" {
+		t.Errorf("MoveTab to end: %v", nms)
+	}
+	if _, idx, _ := tva.CurTab(); idx != 2 {
+		t.Errorf("MoveTab selection: %v", idx)
+	}
+	if tva.MoveTab(1, 1) || tva.MoveTab(1, 2) || tva.MoveTab(0, 4) {
+		t.Errorf("MoveTab to same or invalid index moved")
+	}
+	if err := h.Settle(); err != nil {
+		t.Fatal(err)
+	}
+
+	// drag a1 to the left edge of a2
+	from, err := gitest.Center(tabButton(t, tva, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb, err := gitest.WinBBox(tabButton(t, tva, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DragNDrop(from, image.Point{bb.Min.X + 2, (bb.Min.Y + bb.Max.Y) / 2}, 4); err != nil {
+		t.Fatal(err)
+	}
+	if nms := tabNames(tva); nms != "a1 a2 a3" {
+		t.Errorf("drag tab: %v", nms)
+	}
+
+	if tva.MoveTabTo(0, tvb, 2) {
+		t.Errorf("MoveTabTo invalid index moved")
+	}
+	if !tva.MoveTabTo(0, tvb, 1) {
+		t.Errorf("MoveTabTo not moved")
+	}
+	if nms := tabNames(tva); nms != "a2 a3" {
+		t.Errorf("MoveTabTo source: %v", nms)
+	}
+	if nms := tabNames(tvb); nms != "b1 a1" {
+		t.Errorf("MoveTabTo dest: %v", nms)
+	}
+	if _, idx, _ := tvb.CurTab(); idx != 1 {
+		t.Errorf("MoveTabTo selection: %v", idx)
+	}
+	if err := h.Settle(); err != nil {
+		t.Fatal(err)
+	}
+
+	// drag a3 onto the contents of tvb, which adds it at the end
+	if err := h.DragNDropOnto(tabButton(t, tva, 1), tvb.Frame()); err != nil {
+		t.Fatal(err)
+	}
+	if nms := tabNames(tva); nms != "a2" {
+		t.Errorf("drag tab to other source: %v", nms)
+	}
+	if nms := tabNames(tvb); nms != "b1 a1 a3" {
+		t.Errorf("drag tab to other dest: %v", nms)
+	}
+}
+
+func TestTabViewCloseVeto(t *testing.T) {
+	h, tva, _ := tabWin(t, "test-tabview-close")
+	defer h.Close()
+
+	veto := true
+	var reqs []gi.TabCloseData
+	tva.TabViewSig.Connect(tva.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(gi.TabCloseReq) {
+			return
+		}
+		cd := data.(*gi.TabCloseData)
+		reqs = append(reqs, *cd)
+		cd.Veto = veto
+	})
+
+	tva.SetTabDirty(1, true)
+	if tva.CloseTabIndexAction(1) {
+		t.Errorf("vetoed close deleted the tab")
+	}
+	if nms := tabNames(tva); nms != "a1 a2 a3" {
+		t.Errorf("vetoed close: %v", nms)
+	}
+	if len(reqs) != 1 || reqs[0] != (gi.TabCloseData{Index: 1, Name: "a2", Dirty: true, Veto: false}) {
+		t.Errorf("close request: %v", reqs)
+	}
+
+	// the close button of the selected tab requests to close it
+	cls := tabButton(t, tva, 0).Parts.KnownChildByName("close", 0)
+	if err := h.ClickOn(cls); err != nil {
+		t.Fatal(err)
+	}
+	if nms := tabNames(tva); nms != "a1 a2 a3" || len(reqs) != 2 || reqs[1].Name != "a1" {
+		t.Errorf("vetoed close button: %v %v", nms, reqs)
+	}
+
+	veto = false
+	if err := h.ClickOn(cls); err != nil {
+		t.Fatal(err)
+	}
+	if nms := tabNames(tva); nms != "a2 a3" || len(reqs) != 3 {
+		t.Errorf("close button: %v %v", nms, reqs)
+	}
+	if !tva.CloseTabIndexAction(0) {
+		t.Errorf("close not vetoed did not delete the tab")
+	}
+	if nms := tabNames(tva); nms != "a3" {
+		t.Errorf("close: %v", nms)
+	}
+}
+
+func TestTabViewDirty(t *testing.T) {
+	h, tva, tvb := tabWin(t, "test-tabview-dirty")
+	defer h.Close()
+
+	tva.SetTabDirty(1, true)
+	if tab := tabButton(t, tva, 1); tab.Text != gi.TabDirtyMarker+"a2" || !tva.TabIsDirty(1) {
+		t.Errorf("dirty label: %q", tab.Text)
+	}
+	if dts := tva.DirtyTabs(); len(dts) != 1 || dts[0] != 1 {
+		t.Errorf("dirty tabs: %v", dts)
+	}
+	if tva.TabName(1) != "a2" {
+		t.Errorf("dirty tab name: %v", tva.TabName(1))
+	}
+
+	tva.MoveTab(1, 0)
+	if tab := tabButton(t, tva, 0); tab.Text != gi.TabDirtyMarker+"a2" || tva.TabIsDirty(1) {
+		t.Errorf("moved dirty label: %q", tab.Text)
+	}
+	tva.MoveTabTo(0, tvb, 0)
+	if tab := tabButton(t, tvb, 0); tab.Text != gi.TabDirtyMarker+"a2" || len(tva.DirtyTabs()) != 0 {
+		t.Errorf("moved to dirty label: %q", tab.Text)
+	}
+
+	tvb.SetTabDirty(0, false)
+	if tab := tabButton(t, tvb, 0); tab.Text != "a2" || tvb.TabIsDirty(0) {
+		t.Errorf("clean label: %q", tab.Text)
+	}
+	if err := h.Settle(); err != nil {
+		t.Fatal(err)
+	}
+}
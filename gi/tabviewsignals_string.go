@@ -9,9 +9,9 @@ import (
 
 var _ = errors.New("dummy error")
 
-const _TabViewSignals_name = "TabSelectedTabAddedTabDeletedTabViewSignalsN"
+const _TabViewSignals_name = "TabSelectedTabAddedTabDeletedTabMovedTabRemovedTabCloseReqTabViewSignalsN"
 
-var _TabViewSignals_index = [...]uint8{0, 11, 19, 29, 44}
+var _TabViewSignals_index = [...]uint8{0, 11, 19, 29, 37, 47, 58, 73}
 
 func (i TabViewSignals) String() string {
 	if i < 0 || i >= TabViewSignals(len(_TabViewSignals_index)-1) {
@@ -60,6 +60,7 @@ type RuneRender struct {
 	Size    Vec2D           `desc:"size of the rune itself, exclusive of spacing that might surround it"`
 	RotRad  float32         `desc:"rotation in radians for this character, relative to its lower-left baseline rendering position"`
 	ScaleX  float32         `desc:"scaling of the X dimension, in case of non-uniform scaling, 0 = no separate scaling"`
+	Level   int8            `desc:"bidirectional embedding level of the rune, from the unicode bidi algorithm -- odd levels are right-to-left -- set by TextRender SetBidi"`
 }
 
 // HasNil returns error if any of the key info (face, color) is nil -- only
@@ -91,6 +92,11 @@ func (rr *RuneRender) CurColor(curColor color.Color) color.Color {
 	return curColor
 }
 
+// IsRTL returns true if the rune is right-to-left, per its bidi Level
+func (rr *RuneRender) IsRTL() bool {
+	return rr.Level&1 == 1
+}
+
 // RelPosAfterLR returns the relative position after given rune for LR order: RelPos.X + Size.X
 func (rr *RuneRender) RelPosAfterLR() float32 {
 	return rr.RelPos.X + rr.Size.X
@@ -128,6 +134,7 @@ type SpanRender struct {
 	LastPos Vec2D           `desc:"rune position for further edge of last rune -- for standard flat strings this is the overall length of the string -- used for size / layout computations -- you do not add RelPos to this -- it is in same TextRender relative coordinates"`
 	Dir     TextDirections  `desc:"where relevant, this is the (default, dominant) text direction for the span"`
 	HasDeco TextDecorations `desc:"mask of decorations that have been set on this span -- optimizes rendering passes"`
+	Glyphs  []GlyphRender   `desc:"shaped glyphs that render the text, if it was shaped with the OpenType layout of its fonts -- set by ShapeLR in SetRunePosLR -- nil if not shaped, in which case each rune renders its own glyph"`
 }
 
 // Init initializes a new span with given capacity
@@ -156,7 +163,13 @@ func (sr *SpanRender) SizeHV() Vec2D {
 	if sr.IsValid() != nil {
 		return Vec2D{}
 	}
+	if sr.Dir == TBRL || sr.Dir == TBLR { // vertical text laid out by SetRunePosTB
+		return Vec2D{0, sr.LastPos.Y}
+	}
 	sz := sr.Render[0].RelPos.Sub(sr.LastPos)
+	if sr.HasBidi() { // runes are in visual order starting at 0
+		sz.X = sr.LastPos.X
+	}
 	if sz.X < 0 {
 		sz.X = -sz.X
 	}
@@ -169,23 +182,39 @@ func (sr *SpanRender) SizeHV() Vec2D {
 // RuneRelPos returns the relative (starting) position of the given rune index
 // (adds Span RelPos and rune RelPos) -- this is typically the baseline
 // position where rendering will start, not the upper left corner. if index >
-// length, then uses LastPos
+// length, then uses LastPos.  For right-to-left (bidi) runes, the starting
+// position is on the right side of the rune, and the end of a span ending in
+// bidi text is the trailing edge of its last rune -- i.e., this is the
+// cursor position for the index.
 func (sr *SpanRender) RuneRelPos(idx int) Vec2D {
-	if idx >= len(sr.Render) {
-		return sr.LastPos
+	sz := len(sr.Render)
+	if idx >= sz {
+		if sz == 0 || !sr.HasBidi() {
+			return sr.LastPos
+		}
+		return sr.RuneEndPos(sz - 1)
+	}
+	rr := &sr.Render[idx]
+	spos := sr.RelPos.Add(rr.RelPos)
+	if rr.IsRTL() {
+		spos.X += rr.Size.X
 	}
-	return sr.RelPos.Add(sr.Render[idx].RelPos)
+	return spos
 }
 
 // RuneEndPos returns the relative ending position of the given rune index
-// (adds Span RelPos and rune RelPos + rune Size.X for LR writing). If index >
-// length, then uses LastPos
+// (adds Span RelPos and rune RelPos + rune Size.X for LR writing, or just
+// rune RelPos for right-to-left bidi runes). If index > length, then uses
+// LastPos
 func (sr *SpanRender) RuneEndPos(idx int) Vec2D {
 	if idx >= len(sr.Render) {
 		return sr.LastPos
 	}
-	spos := sr.RelPos.Add(sr.Render[idx].RelPos)
-	spos.X += sr.Render[idx].Size.X
+	rr := &sr.Render[idx]
+	spos := sr.RelPos.Add(rr.RelPos)
+	if !rr.IsRTL() {
+		spos.X += rr.Size.X
+	}
 	return spos
 }
 
@@ -348,13 +377,31 @@ var TextFontRenderMu sync.Mutex
 
 // SetRunePosLR sets relative positions of each rune using a flat
 // left-to-right text layout, based on font size info and additional extra
-// letter and word spacing parameters (which can be negative)
+// letter and word spacing parameters (which can be negative) -- any
+// right-to-left (bidi) runes are then reordered into their visual positions
+// (see ReorderBidiLR)
 func (sr *SpanRender) SetRunePosLR(letterSpace, wordSpace, chsz float32, tabSize int) {
+	sr.SetRunePosLogicalLR(letterSpace, wordSpace, chsz, tabSize)
+	if sr.HasBidi() {
+		sr.ReorderBidiLR()
+	}
+}
+
+// SetRunePosLogicalLR sets relative positions of each rune using a flat
+// left-to-right text layout in logical order, i.e., ignoring any
+// right-to-left (bidi) runes, as needed for wrapping text into lines before
+// reordering each line -- see SetRunePosLR.  The text is first shaped
+// (ShapeLR), and shaped runes are positioned by the advances of their glyph
+// clusters, which include kerning.  The Dir is reset to LRTB, unless it is
+// RLTB as set for a right-to-left paragraph by SetBidi.
+func (sr *SpanRender) SetRunePosLogicalLR(letterSpace, wordSpace, chsz float32, tabSize int) {
 	if err := sr.IsValid(); err != nil {
 		// log.Println(err)
 		return
 	}
-	sr.Dir = LRTB
+	if sr.Dir != RLTB {
+		sr.Dir = LRTB
+	}
 	sz := len(sr.Text)
 	prevR := rune(-1)
 	lspc := letterSpace
@@ -366,13 +413,16 @@ func (sr *SpanRender) SetRunePosLR(letterSpace, wordSpace, chsz float32, tabSize
 	curFace := sr.Render[0].Face
 	TextFontRenderMu.Lock()
 	defer TextFontRenderMu.Unlock()
+	advs := sr.ShapeLR()
+	prevShaped := false
 	col := 0 // current column position -- todo: does NOT deal with indent
 	for i, r := range sr.Text {
 		rr := &(sr.Render[i])
 		curFace = rr.CurFace(curFace)
 
+		shaped := advs != nil && advs[i] >= 0
 		fht := FixedToFloat32(curFace.Metrics().Height)
-		if prevR >= 0 {
+		if prevR >= 0 && !shaped && !prevShaped {
 			fpos += FixedToFloat32(curFace.Kern(prevR, r))
 		}
 		rr.RelPos.X = fpos
@@ -386,10 +436,15 @@ func (sr *SpanRender) SetRunePosLR(letterSpace, wordSpace, chsz float32, tabSize
 		}
 
 		// todo: could check for various types of special unicode space chars here
-		a, _ := curFace.GlyphAdvance(r)
-		a32 := FixedToFloat32(a)
-		if a32 == 0 {
-			a32 = .1 * fht // something..
+		var a32 float32
+		if shaped {
+			a32 = advs[i]
+		} else {
+			a, _ := curFace.GlyphAdvance(r)
+			a32 = FixedToFloat32(a)
+			if a32 == 0 {
+				a32 = .1 * fht // something..
+			}
 		}
 		rr.Size = Vec2D{a32, fht}
 
@@ -412,6 +467,7 @@ func (sr *SpanRender) SetRunePosLR(letterSpace, wordSpace, chsz float32, tabSize
 			}
 		}
 		prevR = r
+		prevShaped = shaped
 	}
 	sr.LastPos.X = fpos
 	sr.LastPos.Y = 0
@@ -498,6 +554,7 @@ func (sr *SpanRender) TrimSpaceLeftLR() {
 	srr0 := sr.Render[0]
 	for range sr.Text {
 		if unicode.IsSpace(sr.Text[0]) {
+			sr.Glyphs = sr.GlyphsRange(1, len(sr.Text))
 			sr.Text = sr.Text[1:]
 			sr.Render = sr.Render[1:]
 			if len(sr.Render) > 0 {
@@ -521,6 +578,7 @@ func (sr *SpanRender) TrimSpaceRightLR() {
 	for range sr.Text {
 		lidx := len(sr.Text) - 1
 		if unicode.IsSpace(sr.Text[lidx]) {
+			sr.Glyphs = sr.GlyphsRange(0, lidx)
 			sr.Text = sr.Text[:lidx]
 			sr.Render = sr.Render[:lidx]
 			lidx--
@@ -549,7 +607,8 @@ func (sr *SpanRender) SplitAtLR(idx int) *SpanRender {
 	if idx <= 0 || idx >= len(sr.Text)-1 { // shouldn't happen
 		return nil
 	}
-	nsr := SpanRender{Text: sr.Text[idx:], Render: sr.Render[idx:], Dir: sr.Dir, HasDeco: sr.HasDeco}
+	nsr := SpanRender{Text: sr.Text[idx:], Render: sr.Render[idx:], Dir: sr.Dir, HasDeco: sr.HasDeco, Glyphs: sr.GlyphsRange(idx, len(sr.Text))}
+	sr.Glyphs = sr.GlyphsRange(0, idx)
 	sr.Text = sr.Text[:idx]
 	sr.Render = sr.Render[:idx]
 	sr.LastPos.X = sr.Render[idx-1].RelPosAfterLR()
@@ -589,8 +648,241 @@ func (sr *SpanRender) LastFont() (face font.Face, color color.Color) {
 	return
 }
 
-// todo: TB, RL cases -- layout is complicated.. with unicode-bidi, direction,
-// writing-mode styles all interacting: https://www.w3.org/TR/SVG11/text.html#TextLayout
+//////////////////////////////////////////////////////////////////////////////////
+//  TB vertical layout
+
+// note: vertical text is laid out in columns of runes, with each rune either
+// upright or rotated 90 degrees clockwise (sideways), per the
+// glyph-orientation-vertical and text-orientation styles --
+// https://www.w3.org/TR/css-writing-modes-3/#text-orientation -- the RelPos
+// of each rune is its baseline rendering position as always, and RunePosTB
+// gives the position of each rune along the column.  Vertical text is not
+// shaped, and vertical alternate glyphs are not used (todo).
+
+// SetRunePosTB sets relative positions of each rune using a flat
+// top-to-bottom vertical text layout, based on font size info and additional
+// extra letter and word spacing parameters (which can be negative) -- the
+// column is centered on X = 0 and starts at Y = 0.  Runes of scripts that are
+// written vertically (see RuneUprightTB) are set upright, and all others are
+// rotated sideways, unless upright is true, in which case all runes are upright.
+func (sr *SpanRender) SetRunePosTB(letterSpace, wordSpace float32, upright bool) {
+	if err := sr.IsValid(); err != nil {
+		// log.Println(err)
+		return
+	}
+	sz := len(sr.Text)
+	if sr.Dir != TBLR {
+		sr.Dir = TBRL
+	}
+	sr.Glyphs = nil // runes are positioned individually
+	prevR := rune(-1)
+	var fpos float32
+	curFace := sr.Render[0].Face
+	TextFontRenderMu.Lock()
+	defer TextFontRenderMu.Unlock()
+	for i, r := range sr.Text {
+		rr := &(sr.Render[i])
+		rr.Level = 0 // runes are always in logical order in vertical text
+		curFace = rr.CurFace(curFace)
+		met := curFace.Metrics()
+		asc := FixedToFloat32(met.Ascent)
+		dsc := FixedToFloat32(met.Descent)
+		fht := FixedToFloat32(met.Height)
+		a, _ := curFace.GlyphAdvance(r)
+		a32 := FixedToFloat32(a)
+		if a32 == 0 {
+			a32 = .1 * fht // something..
+		}
+		if upright || RuneUprightTB(r) {
+			rr.RotRad = 0
+			rr.RelPos = Vec2D{-a32 / 2, fpos + asc}
+			rr.Size = Vec2D{a32, asc + dsc}
+			fpos += asc + dsc
+			prevR = -1
+		} else {
+			if prevR >= 0 {
+				fpos += FixedToFloat32(curFace.Kern(prevR, r))
+			}
+			rr.RotRad = math32.Pi / 2
+			rr.RelPos = Vec2D{-(asc - dsc) / 2, fpos} // center the em box on the column
+			rr.Size = Vec2D{a32, fht}
+			fpos += a32
+			prevR = r
+		}
+		if i < sz-1 {
+			fpos += letterSpace
+			if unicode.IsSpace(r) {
+				fpos += wordSpace
+			}
+		}
+	}
+	sr.LastPos.X = 0
+	sr.LastPos.Y = fpos
+}
+
+// RunePosTB returns the relative positions along the column of the top of
+// each rune of vertical text laid out by SetRunePosTB, followed by the
+// position of the bottom of the last rune
+func (sr *SpanRender) RunePosTB() []float32 {
+	sz := len(sr.Render)
+	pos := make([]float32, sz+1)
+	if sz == 0 {
+		return pos
+	}
+	var curFace font.Face
+	for i := range sr.Render {
+		rr := &(sr.Render[i])
+		curFace = rr.CurFace(curFace)
+		if rr.RotRad != 0 {
+			pos[i] = rr.RelPos.Y
+		} else {
+			pos[i] = rr.RelPos.Y - FixedToFloat32(curFace.Metrics().Ascent)
+		}
+	}
+	lr := &(sr.Render[sz-1])
+	if lr.RotRad != 0 {
+		pos[sz] = pos[sz-1] + lr.Size.X
+	} else {
+		pos[sz] = pos[sz-1] + lr.Size.Y
+	}
+	return pos
+}
+
+// RuneUprightTB returns true if given rune is set upright in vertical text
+// with the default mixed orientation -- i.e., it is from a script that is
+// written vertically (CJK), or is a full-width form, excepting brackets and
+// other punctuation that are rotated in vertical text
+func RuneUprightTB(r rune) bool {
+	if r < 0x1100 {
+		return false
+	}
+	for _, rg := range RotatedRangesTB {
+		if r >= rg[0] && r <= rg[1] {
+			return false
+		}
+	}
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Bopomofo, unicode.Yi) {
+		return true
+	}
+	for _, rg := range UprightRangesTB {
+		if r >= rg[0] && r <= rg[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// UprightRangesTB are the inclusive ranges of runes, in addition to those of
+// the CJK scripts, that are set upright in vertical text (see RuneUprightTB)
+var UprightRangesTB = [][2]rune{
+	{0x2E80, 0x2FFF}, // CJK radicals, kangxi radicals, ideographic description
+	{0x3000, 0x303F}, // CJK symbols and punctuation
+	{0x3190, 0x31EF}, // kanbun, CJK strokes
+	{0x3200, 0x33FF}, // enclosed CJK letters and months, CJK compatibility
+	{0xFE10, 0xFE1F}, // vertical forms
+	{0xFE30, 0xFE4F}, // CJK compatibility forms
+	{0xFF01, 0xFF60}, // full-width forms
+	{0xFFE0, 0xFFE6},
+}
+
+// RotatedRangesTB are the inclusive ranges of runes among the CJK and
+// full-width runes that are nevertheless rotated sideways in vertical text,
+// in the absence of vertical alternate glyphs -- brackets, dashes and the
+// prolonged sound mark, which run along the line of text
+var RotatedRangesTB = [][2]rune{
+	{0x3008, 0x3011}, // CJK angle and corner brackets
+	{0x3014, 0x301F}, // CJK brackets, wave dash
+	{0x30A0, 0x30A0}, // katakana-hiragana double hyphen
+	{0x30FC, 0x30FC}, // prolonged sound mark
+	{0xFF08, 0xFF09}, // full-width parentheses
+	{0xFF0D, 0xFF0D}, // full-width hyphen-minus
+	{0xFF1C, 0xFF1E}, // full-width less-than, equals, greater-than
+	{0xFF3B, 0xFF3B}, // full-width brackets
+	{0xFF3D, 0xFF3D},
+	{0xFF5B, 0xFF60}, // full-width braces, tilde, white parentheses
+}
+
+// FindWrapPosTB finds a position to do word wrapping of vertical text to fit
+// within trgSize -- RelPos positions must have already been set
+// (SetRunePosTB).  Lines can be broken after white space, and between any
+// runes that are set upright (CJK), which are not separated by spaces.
+func (sr *SpanRender) FindWrapPosTB(trgSize float32) int {
+	sz := len(sr.Text)
+	if sz == 0 {
+		return -1
+	}
+	pos := sr.RunePosTB()
+	idx := 0 // index of first rune that does not fit
+	for idx < sz && sr.RelPos.Y+pos[idx+1] <= trgSize {
+		idx++
+	}
+	if idx == sz {
+		return -1
+	}
+	if unicode.IsSpace(sr.Text[idx]) {
+		for idx < sz && unicode.IsSpace(sr.Text[idx]) { // break at END of whitespace
+			idx++
+		}
+		return idx
+	}
+	wp := idx
+	for wp > 0 && !sr.CanBreakTB(wp) {
+		wp--
+	}
+	if wp > 0 {
+		return wp
+	}
+	// no breaks within size -- find next break going down
+	wp = idx + 1
+	for wp < sz && !sr.CanBreakTB(wp) {
+		wp++
+	}
+	if wp >= sz-1 { // unbreakable
+		return -1
+	}
+	return wp
+}
+
+// CanBreakTB returns true if a line of vertical text can be broken before the
+// given rune index: at the end of white space, or before or after an upright
+// rune
+func (sr *SpanRender) CanBreakTB(idx int) bool {
+	if idx <= 0 || idx >= len(sr.Text) {
+		return false
+	}
+	r, pr := sr.Text[idx], sr.Text[idx-1]
+	if unicode.IsSpace(r) {
+		return false
+	}
+	return unicode.IsSpace(pr) || RuneUprightTB(r) || RuneUprightTB(pr)
+}
+
+// SplitAtTB splits current span at given index, returning a new span with
+// remainder after index, for vertical text -- the new span must then be laid
+// out again with SetRunePosTB
+func (sr *SpanRender) SplitAtTB(idx int) *SpanRender {
+	if idx <= 0 || idx >= len(sr.Text)-1 { // shouldn't happen
+		return nil
+	}
+	nsr := SpanRender{Text: sr.Text[idx:], Render: sr.Render[idx:], Dir: sr.Dir, HasDeco: sr.HasDeco}
+	pos := sr.RunePosTB()
+	sr.Text = sr.Text[:idx]
+	sr.Render = sr.Render[:idx]
+	if lr := &(sr.Render[idx-1]); lr.RotRad != 0 {
+		sr.LastPos.Y = pos[idx-1] + lr.Size.X
+	} else {
+		sr.LastPos.Y = pos[idx-1] + lr.Size.Y
+	}
+	nrr0 := &(nsr.Render[0])
+	face, color := sr.LastFont()
+	if nrr0.Face == nil {
+		nrr0.Face = face
+	}
+	if nrr0.Color == nil {
+		nrr0.Color = color
+	}
+	return &nsr
+}
 
 //////////////////////////////////////////////////////////////////////////////////
 //  TextLink
@@ -723,60 +1015,43 @@ func (tr *TextRender) Render(rs *RenderState, pos Vec2D) {
 			sr.RenderLine(rs, tpos, DecoOverline, 1.1)
 		}
 
-		for i, r := range sr.Text {
-			rr := &(sr.Render[i])
-			if rr.Color != nil {
-				curColor = rr.Color
-				d.Src = image.NewUniform(curColor)
-			}
-			curFace = rr.CurFace(curFace)
-			if !unicode.IsPrint(r) {
-				continue
-			}
-			dsc32 := FixedToFloat32(curFace.Metrics().Descent)
-			rp := tpos.Add(rr.RelPos)
-			scx := float32(1)
-			if rr.ScaleX != 0 {
-				scx = rr.ScaleX
-			}
-			tx := Scale2D(scx, 1).Rotate(rr.RotRad)
-			ll := rp.Add(tx.TransformVectorVec2D(Vec2D{0, dsc32}))
-			ur := ll.Add(tx.TransformVectorVec2D(Vec2D{rr.Size.X, -rr.Size.Y}))
-			if int(math32.Floor(ll.X)) > rs.Bounds.Max.X || int(math32.Floor(ur.Y)) > rs.Bounds.Max.Y ||
-				int(math32.Ceil(ur.X)) < rs.Bounds.Min.X || int(math32.Ceil(ll.Y)) < rs.Bounds.Min.Y {
-				continue
-			}
-			d.Face = curFace
-			d.Dot = rp.Fixed()
-			dr, mask, maskp, _, ok := d.Face.Glyph(d.Dot, r)
-			if !ok {
-				// fmt.Printf("not ok rendering rune: %v\n", string(r))
-				continue
-			}
-			if rr.RotRad == 0 && (rr.ScaleX == 0 || rr.ScaleX == 1) {
-				idr := dr.Intersect(rs.Bounds)
-				soff := image.ZP
-				if dr.Min.X < rs.Bounds.Min.X {
-					soff.X = rs.Bounds.Min.X - dr.Min.X
-					maskp.X += rs.Bounds.Min.X - dr.Min.X
+		if len(sr.Glyphs) > 0 {
+			sr.RenderGlyphs(rs, tpos)
+		} else {
+			for i, r := range sr.Text {
+				rr := &(sr.Render[i])
+				if rr.Color != nil {
+					curColor = rr.Color
+					d.Src = image.NewUniform(curColor)
 				}
-				if dr.Min.Y < rs.Bounds.Min.Y {
-					soff.Y = rs.Bounds.Min.Y - dr.Min.Y
-					maskp.Y += rs.Bounds.Min.Y - dr.Min.Y
+				curFace = rr.CurFace(curFace)
+				if !unicode.IsPrint(r) {
+					continue
 				}
-				draw.DrawMask(d.Dst, idr, d.Src, soff, mask, maskp, draw.Over)
-			} else {
-				srect := dr.Sub(dr.Min)
-				dbase := Vec2D{rp.X - float32(dr.Min.X), rp.Y - float32(dr.Min.Y)}
-
-				transformer := draw.BiLinear
-				fx, fy := float32(dr.Min.X), float32(dr.Min.Y)
-				m := Translate2D(fx+dbase.X, fy+dbase.Y).Scale(scx, 1).Rotate(rr.RotRad).Translate(-dbase.X, -dbase.Y)
-				s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
-				transformer.Transform(d.Dst, s2d, d.Src, srect, draw.Over, &draw.Options{
-					SrcMask:  mask,
-					SrcMaskP: maskp,
-				})
+				if rr.IsRTL() {
+					r = BidiMirror(r)
+				}
+				dsc32 := FixedToFloat32(curFace.Metrics().Descent)
+				rp := tpos.Add(rr.RelPos)
+				scx := float32(1)
+				if rr.ScaleX != 0 {
+					scx = rr.ScaleX
+				}
+				tx := Scale2D(scx, 1).Rotate(rr.RotRad)
+				ll := rp.Add(tx.TransformVectorVec2D(Vec2D{0, dsc32}))
+				ur := ll.Add(tx.TransformVectorVec2D(Vec2D{rr.Size.X, -rr.Size.Y}))
+				if int(math32.Floor(ll.X)) > rs.Bounds.Max.X || int(math32.Floor(ur.Y)) > rs.Bounds.Max.Y ||
+					int(math32.Ceil(ur.X)) < rs.Bounds.Min.X || int(math32.Ceil(ll.Y)) < rs.Bounds.Min.Y {
+					continue
+				}
+				d.Face = curFace
+				d.Dot = rp.Fixed()
+				dr, mask, maskp, _, ok := d.Face.Glyph(d.Dot, r)
+				if !ok {
+					// fmt.Printf("not ok rendering rune: %v\n", string(r))
+					continue
+				}
+				renderGlyphMask(rs, d.Src, rr, rp, dr, mask, maskp)
 			}
 		}
 		if bitflag.Has32(int32(sr.HasDeco), int(DecoLineThrough)) {
@@ -785,6 +1060,54 @@ func (tr *TextRender) Render(rs *RenderState, pos Vec2D) {
 	}
 }
 
+// renderGlyphMask draws the given glyph mask, for a rune or shaped glyph
+// rendered at position rp, with the rotation and scaling of its rune -- the
+// glyph is clipped by the Mask of the RenderState, if any (e.g., clip-path)
+func renderGlyphMask(rs *RenderState, src image.Image, rr *RuneRender, rp Vec2D, dr image.Rectangle, mask image.Image, maskp image.Point) {
+	if rr.RotRad == 0 && (rr.ScaleX == 0 || rr.ScaleX == 1) {
+		idr := dr.Intersect(rs.Bounds)
+		soff := image.ZP
+		if dr.Min.X < rs.Bounds.Min.X {
+			soff.X = rs.Bounds.Min.X - dr.Min.X
+			maskp.X += rs.Bounds.Min.X - dr.Min.X
+		}
+		if dr.Min.Y < rs.Bounds.Min.Y {
+			soff.Y = rs.Bounds.Min.Y - dr.Min.Y
+			maskp.Y += rs.Bounds.Min.Y - dr.Min.Y
+		}
+		if rs.Mask != nil { // glyph mask times clip mask
+			gm := image.NewAlpha(idr)
+			draw.DrawMask(gm, idr, rs.Mask, idr.Min, mask, maskp, draw.Src)
+			mask, maskp = gm, idr.Min
+		}
+		draw.DrawMask(rs.Image, idr, src, soff, mask, maskp, draw.Over)
+	} else {
+		scx := float32(1)
+		if rr.ScaleX != 0 {
+			scx = rr.ScaleX
+		}
+		srect := dr.Sub(dr.Min)
+		dbase := Vec2D{rp.X - float32(dr.Min.X), rp.Y - float32(dr.Min.Y)}
+
+		transformer := draw.BiLinear
+		fx, fy := float32(dr.Min.X), float32(dr.Min.Y)
+		m := Translate2D(fx+dbase.X, fy+dbase.Y).Scale(scx, 1).Rotate(rr.RotRad).Translate(-dbase.X, -dbase.Y)
+		s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
+		opts := &draw.Options{SrcMask: mask, SrcMaskP: maskp}
+		if rs.Mask != nil { // note: nil *image.Alpha is not a nil image.Image
+			opts.DstMask = rs.Mask // in Image coordinates
+			// x/image/draw kernels ignore the DstMask for a Uniform src with a
+			// SrcMask, so use a solid image instead
+			if u, ok := src.(*image.Uniform); ok {
+				sim := image.NewRGBA(srect)
+				draw.Draw(sim, srect, u, image.ZP, draw.Src)
+				src = sim
+			}
+		}
+		transformer.Transform(rs.Image, s2d, src, srect, draw.Over, opts)
+	}
+}
+
 // RenderBg renders the background behind chars
 func (sr *SpanRender) RenderBg(rs *RenderState, tpos Vec2D) {
 	curFace := sr.Render[0].Face
@@ -882,7 +1205,7 @@ func (sr *SpanRender) RenderUnderline(rs *RenderState, tpos Vec2D) {
 		sp := rp.Add(tx.TransformVectorVec2D(Vec2D{0, 2 * dw}))
 		ep := rp.Add(tx.TransformVectorVec2D(Vec2D{rr.Size.X, 2 * dw}))
 
-		if didLast {
+		if didLast && !rr.IsRTL() && !sr.Render[i-1].IsRTL() { // rtl runes are not contiguous in logical order
 			pc.LineTo(rs, sp.X, sp.Y)
 		} else {
 			pc.NewSubPath(rs)
@@ -946,7 +1269,7 @@ func (sr *SpanRender) RenderLine(rs *RenderState, tpos Vec2D, deco TextDecoratio
 		sp := rp.Add(tx.TransformVectorVec2D(Vec2D{0, -yo}))
 		ep := rp.Add(tx.TransformVectorVec2D(Vec2D{rr.Size.X, -yo}))
 
-		if didLast {
+		if didLast && !rr.IsRTL() && !sr.Render[i-1].IsRTL() { // rtl runes are not contiguous in logical order
 			pc.LineTo(rs, sp.X, sp.Y)
 		} else {
 			pc.NewSubPath(rs)
@@ -991,6 +1314,7 @@ func (tr *TextRender) SetString(str string, fontSty *FontStyle, ctxt *units.Cont
 	tr.Links = nil
 	sr := &(tr.Spans[0])
 	sr.SetString(str, fontSty, ctxt, noBG, rot, scalex)
+	tr.SetBidi(txtSty, true)
 	sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Ch, txtSty.TabSize)
 	ssz := sr.SizeHV()
 	vht := fontSty.Face.Metrics().Height
@@ -1012,6 +1336,7 @@ func (tr *TextRender) SetRunes(str []rune, fontSty *FontStyle, ctxt *units.Conte
 	tr.Links = nil
 	sr := &(tr.Spans[0])
 	sr.SetRunes(str, fontSty, ctxt, noBG, rot, scalex)
+	tr.SetBidi(txtSty, true)
 	sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Ch, txtSty.TabSize)
 	ssz := sr.SizeHV()
 	vht := fontSty.Face.Metrics().Height
@@ -1268,6 +1593,7 @@ func (tr *TextRender) SetHTMLNoPre(str []byte, font *FontStyle, txtSty *TextStyl
 			}
 		}
 	}
+	tr.SetBidi(txtSty, false)
 }
 
 // note: adding print / log statements to following when inside gide will cause
@@ -1492,6 +1818,7 @@ func (tr *TextRender) SetHTMLPre(str []byte, font *FontStyle, txtSty *TextStyle,
 			}
 		}
 	}
+	tr.SetBidi(txtSty, true)
 }
 
 // RuneSpanPos returns the position (span, rune index within span) within a
@@ -1541,36 +1868,35 @@ func (tx *TextRender) SpanPosToRuneIdx(si, ri int) (idx int, ok bool) {
 // index, counting progressively through all spans present (adds Span RelPos
 // and rune RelPos) -- this is typically the baseline position where rendering
 // will start, not the upper left corner. If index > length, then uses
-// LastPos.  Returns also the index of the span that holds that char (-1 = no
-// spans at all) and the rune index within that span, and false if index is
-// out of range.
+// LastPos.  For right-to-left (bidi) runes, the starting position is on the
+// right side of the rune -- see SpanRender RuneRelPos.  Returns also the
+// index of the span that holds that char (-1 = no spans at all) and the rune
+// index within that span, and false if index is out of range.
 func (tx *TextRender) RuneRelPos(idx int) (pos Vec2D, si, ri int, ok bool) {
 	si, ri, ok = tx.RuneSpanPos(idx)
 	if ok {
 		sr := &tx.Spans[si]
-		return sr.RelPos.Add(sr.Render[ri].RelPos), si, ri, true
+		return sr.RuneRelPos(ri), si, ri, true
 	}
 	nsp := len(tx.Spans)
 	if nsp > 0 {
 		sr := &tx.Spans[nsp-1]
-		return sr.LastPos, nsp - 1, len(sr.Render), false
+		return sr.RuneRelPos(len(sr.Render)), nsp - 1, len(sr.Render), false
 	}
 	return Vec2DZero, -1, -1, false
 }
 
 // RuneEndPos returns the relative ending position of the given rune index,
 // counting progressively through all spans present(adds Span RelPos and rune
-// RelPos + rune Size.X for LR writing). If index > length, then uses LastPos.
-// Returns also the index of the span that holds that char (-1 = no spans at
-// all) and the rune index within that span, and false if index is out of
-// range.
+// RelPos + rune Size.X for LR writing, or just rune RelPos for right-to-left
+// bidi runes). If index > length, then uses LastPos.  Returns also the index
+// of the span that holds that char (-1 = no spans at all) and the rune index
+// within that span, and false if index is out of range.
 func (tx *TextRender) RuneEndPos(idx int) (pos Vec2D, si, ri int, ok bool) {
 	si, ri, ok = tx.RuneSpanPos(idx)
 	if ok {
 		sr := &tx.Spans[si]
-		spos := sr.RelPos.Add(sr.Render[ri].RelPos)
-		spos.X += sr.Render[ri].Size.X
-		return spos, si, ri, true
+		return sr.RuneEndPos(ri), si, ri, true
 	}
 	nsp := len(tx.Spans)
 	if nsp > 0 {
@@ -1595,9 +1921,9 @@ type TextStyle struct {
 	LineHeight       float32        `xml:"line-height" inherit:"true" desc:"prop: line-height = specified height of a line of text, in proportion to default font height, 0 = 1 = normal (todo: specific values such as pixels are not supported, in order to properly support percentage) -- text is centered within the overall lineheight"`
 	WhiteSpace       WhiteSpaces    `xml:"white-space" inherit:"true" desc:"prop: white-space = specifies how white space is processed, and how lines are wrapped"`
 	UnicodeBidi      UnicodeBidi    `xml:"unicode-bidi" inherit:"true" desc:"prop: unicode-bidi = determines how to treat unicode bidirectional information"`
-	Direction        TextDirections `xml:"direction" inherit:"true" desc:"prop: direction = direction of text -- only applicable for unicode-bidi = bidi-override or embed -- applies to all text elements"`
-	WritingMode      TextDirections `xml:"writing-mode" inherit:"true" desc:"prop: writing-mode = overall writing mode -- only for text elements, not tspan"`
-	OrientationVert  float32        `xml:"glyph-orientation-vertical" inherit:"true" desc:"prop: glyph-orientation-vertical = for TBRL writing mode (only), determines orientation of alphabetic characters -- 90 is default (rotated) -- 0 means keep upright"`
+	Direction        TextDirections `xml:"direction" inherit:"true" desc:"prop: direction = base direction of text paragraphs for the unicode bidi algorithm (ltr or rtl) -- right-to-left paragraphs are aligned to the right for start alignment -- applies to all text elements"`
+	WritingMode      TextDirections `xml:"writing-mode" inherit:"true" desc:"prop: writing-mode = overall writing mode -- only for text elements, not tspan -- vertical modes (TBRL, TBLR) are laid out by LayoutStdTB -- CSS and SVG 1.1 names are supported (see WritingModes)"`
+	OrientationVert  float32        `xml:"glyph-orientation-vertical" inherit:"true" desc:"prop: glyph-orientation-vertical = for vertical writing modes (only), determines orientation of alphabetic characters -- 90 is default (rotated sideways, while CJK characters are upright) -- 0 means keep upright -- also set by text-orientation: upright = 0, mixed = 90"`
 	OrientationHoriz float32        `xml:"glyph-orientation-horizontal" inherit:"true" desc:"prop: glyph-orientation-horizontal = for horizontal LR/RL writing mode (only), determines orientation of all characters -- 0 is default (upright)"`
 	Indent           units.Value    `xml:"text-indent" inherit:"true" desc:"prop: text-indent = how much to indent the first line in a paragraph"`
 	ParaSpacing      units.Value    `xml:"para-spacing" inherit:"true" desc:"prop: para-spacing = extra spacing between paragraphs -- copied from Style.Layout.Margin per CSS spec if that is non-zero, else can be set directy with para-spacing"`
@@ -1611,14 +1937,28 @@ type TextStyle struct {
 	// user-select -- can user select text?
 }
 
-// https://godoc.org/golang.org/x/text/unicode/bidi
-// UnicodeBidi determines how
+// UnicodeBidi determines how bidirectional text is laid out according to the
+// unicode bidi algorithm (see bidi.go), together with the Direction
 type UnicodeBidi int32
 
 const (
+	// BidiNormal applies the bidi algorithm with the base paragraph direction
+	// given by the Direction
 	BidiNormal UnicodeBidi = iota
+
+	// BidiEmbed is the same as BidiNormal, as text is always embedded in
+	// its own element
 	BidiEmbed
+
+	// BidiBidiOverride forces all characters to the Direction, ignoring
+	// their inherent directionality
 	BidiBidiOverride
+
+	// BidiPlaintext determines the base direction of each paragraph from its
+	// first strongly directional character, ignoring the Direction -- e.g.,
+	// for text entered by the user
+	BidiPlaintext
+
 	UnicodeBidiN
 )
 
@@ -1641,6 +1981,12 @@ const (
 	TB
 	LTR
 	RTL
+
+	// TBLR is vertical top-to-bottom text with lines progressing
+	// left-to-right (vertical-lr), whereas they progress right-to-left for
+	// TBRL and TB
+	TBLR
+
 	TextDirectionsN
 )
 
@@ -1743,8 +2089,54 @@ func (ts *TextStyle) Defaults() {
 	ts.TabSize = 4
 }
 
+// WritingModes maps the CSS and SVG 1.1 values of the writing-mode property
+// to the corresponding TextDirections
+var WritingModes = map[string]TextDirections{
+	"horizontal-tb": LRTB,
+	"lr-tb":         LRTB,
+	"lr":            LRTB,
+	"rl-tb":         RLTB,
+	"rl":            RLTB,
+	"vertical-rl":   TBRL,
+	"tb-rl":         TBRL,
+	"tb":            TBRL,
+	"vertical-lr":   TBLR,
+}
+
 // SetStylePost applies any updates after generic xml-tag property setting
 func (ts *TextStyle) SetStylePost(props ki.Props) {
+	if pwm, ok := props["writing-mode"]; ok {
+		if wm, ok := pwm.(string); ok {
+			if dir, ok := WritingModes[wm]; ok {
+				ts.WritingMode = dir
+			}
+		}
+	}
+	if pto, ok := props["text-orientation"]; ok {
+		if to, ok := pto.(string); ok {
+			switch to {
+			case "upright":
+				ts.OrientationVert = 0
+			case "mixed", "sideways":
+				ts.OrientationVert = 90
+			}
+		}
+	}
+	if pgo, ok := props["glyph-orientation-vertical"]; ok {
+		if gov, ok := pgo.(string); ok && gov == "auto" {
+			ts.OrientationVert = 90
+		}
+	}
+}
+
+// IsVertical returns true if the WritingMode is vertical, with lines of text
+// running top-to-bottom (see LayoutStdTB)
+func (ts *TextStyle) IsVertical() bool {
+	switch ts.WritingMode {
+	case TBRL, TB, TBLR:
+		return true
+	}
+	return false
 }
 
 // InheritFields from parent: Manual inheriting of values is much faster than
@@ -1773,6 +2165,21 @@ func (ts *TextStyle) EffLineHeight() float32 {
 	return ts.LineHeight
 }
 
+// BidiParaLevel returns the paragraph embedding level for the unicode bidi
+// algorithm (see BidiLevels): 1 for a right-to-left Direction, 0 for
+// left-to-right, and -1 for BidiPlaintext, where it is determined from the
+// text of each paragraph
+func (ts *TextStyle) BidiParaLevel() int {
+	if ts.UnicodeBidi == BidiPlaintext {
+		return -1
+	}
+	switch ts.Direction {
+	case RTL, RL, RLTB:
+		return 1
+	}
+	return 0
+}
+
 // AlignFactors gets basic text alignment factors
 func (ts *TextStyle) AlignFactors() (ax, ay float32) {
 	ax = 0.0
@@ -1805,7 +2212,10 @@ func (ts *TextStyle) AlignFactors() (ax, ay float32) {
 
 // LayoutStdLR does basic standard layout of text in LR direction, assigning
 // relative positions to spans and runes according to given styles, and given
-// size overall box (nonzero values used to constrain). Returns total
+// size overall box (nonzero values used to constrain).  Text is wrapped in
+// logical order, and then right-to-left (bidi) runes are reordered within
+// each line, and right-to-left paragraphs have start and end alignment
+// reversed.  Returns total
 // resulting size box for text.  Font face in FontStyle is used for
 // determining line spacing here -- other versions can do more expensive
 // calculations of variable line spacing as needed.
@@ -1835,8 +2245,8 @@ func (tr *TextRender) LayoutStdLR(txtSty *TextStyle, fontSty *FontStyle, ctxt *u
 			si++
 			continue
 		}
-		if sr.LastPos.X == 0 { // don't re-do unless necessary
-			sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Ch, txtSty.TabSize)
+		if sr.LastPos.X == 0 || sr.HasBidi() { // don't re-do unless necessary -- bidi must wrap in logical order
+			sr.SetRunePosLogicalLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Ch, txtSty.TabSize)
 		}
 		if sr.IsNewPara() {
 			sr.RelPos.X = txtSty.Indent.Dots
@@ -1858,29 +2268,10 @@ func (tr *TextRender) LayoutStdLR(txtSty *TextStyle, fontSty *FontStyle, ctxt *u
 					}
 					si++
 					sr = &(tr.Spans[si]) // keep going with nsr
-					sr.SetRunePosLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Ch, txtSty.TabSize)
+					sr.SetRunePosLogicalLR(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, fontSty.Ch, txtSty.TabSize)
 					ssz = sr.SizeHV()
 
-					// fixup links
-					for li := range tr.Links {
-						tl := &tr.Links[li]
-						if tl.StartSpan == si-1 {
-							if tl.StartIdx >= wp {
-								tl.StartIdx -= wp
-								tl.StartSpan++
-							}
-						} else if tl.StartSpan > si-1 {
-							tl.StartSpan++
-						}
-						if tl.EndSpan == si-1 {
-							if tl.EndIdx >= wp {
-								tl.EndIdx -= wp
-								tl.EndSpan++
-							}
-						} else if tl.EndSpan > si-1 {
-							tl.EndSpan++
-						}
-					}
+					tr.SplitLinks(si-1, wp)
 
 					if ssz.X <= size.X {
 						if ssz.X > maxw {
@@ -1904,18 +2295,7 @@ func (tr *TextRender) LayoutStdLR(txtSty *TextStyle, fontSty *FontStyle, ctxt *u
 	}
 	// have maxw, can do alignment cases..
 
-	// make sure links are still in range
-	for li := range tr.Links {
-		tl := &tr.Links[li]
-		stsp := tr.Spans[tl.StartSpan]
-		if tl.StartIdx >= len(stsp.Text) {
-			tl.StartIdx = len(stsp.Text) - 1
-		}
-		edsp := tr.Spans[tl.EndSpan]
-		if tl.EndIdx >= len(edsp.Text) {
-			tl.EndIdx = len(edsp.Text) - 1
-		}
-	}
+	tr.ClampLinks()
 
 	if maxw > size.X {
 		size.X = maxw
@@ -1959,6 +2339,9 @@ func (tr *TextRender) LayoutStdLR(txtSty *TextStyle, fontSty *FontStyle, ctxt *u
 		}
 		sr.RelPos.Y = vpos
 		sr.LastPos.Y = vpos
+		if sr.HasBidi() {
+			sr.ReorderBidiLR()
+		}
 		ssz := sr.SizeHV()
 		ssz.X += sr.RelPos.X
 		hextra := size.X - ssz.X
@@ -1966,7 +2349,7 @@ func (tr *TextRender) LayoutStdLR(txtSty *TextStyle, fontSty *FontStyle, ctxt *u
 			switch {
 			case IsAlignMiddle(txtSty.Align):
 				sr.RelPos.X += hextra / 2
-			case IsAlignEnd(txtSty.Align):
+			case IsAlignEnd(txtSty.Align) != (sr.Dir == RLTB): // start is on the right for rtl
 				sr.RelPos.X += hextra
 			}
 		}
@@ -1975,6 +2358,166 @@ func (tr *TextRender) LayoutStdLR(txtSty *TextStyle, fontSty *FontStyle, ctxt *u
 	return size
 }
 
+// LayoutStdTB does basic standard layout of text in vertical TB direction,
+// assigning relative positions to spans and runes according to given styles,
+// and given size overall box (nonzero values used to constrain).  Each span
+// is a column of text, with columns progressing right-to-left from the right
+// side of the box for TBRL, or left-to-right for TBLR, and text is aligned
+// within each column by the text-align style, where start is the top.  Runes
+// are upright or rotated sideways according to OrientationVert (see
+// SetRunePosTB).  Returns total resulting size box for text.
+func (tr *TextRender) LayoutStdTB(txtSty *TextStyle, fontSty *FontStyle, ctxt *units.Context, size Vec2D) Vec2D {
+	if len(tr.Spans) == 0 {
+		return Vec2DZero
+	}
+
+	pr := prof.Start("TextRenderLayout")
+	defer pr.End()
+
+	tr.Dir = TBRL
+	if txtSty.WritingMode == TBLR {
+		tr.Dir = TBLR
+	}
+	fontSty.OpenFont(ctxt)
+	fht := fontSty.Height
+	lspc := fht * txtSty.EffLineHeight() // width of each column
+	upright := txtSty.OrientationVert == 0
+
+	maxh := float32(0)
+
+	// first pass gets rune positions and wraps text as needed, and gets max height
+	si := 0
+	for si < len(tr.Spans) {
+		sr := &(tr.Spans[si])
+		if err := sr.IsValid(); err != nil {
+			si++
+			continue
+		}
+		sr.Dir = tr.Dir
+		sr.SetRunePosTB(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, upright)
+		if sr.IsNewPara() {
+			sr.RelPos.Y = txtSty.Indent.Dots
+		} else {
+			sr.RelPos.Y = 0
+		}
+		if size.Y > 0 && sr.RelPos.Y+sr.LastPos.Y > size.Y && txtSty.HasWordWrap() {
+			for {
+				wp := sr.FindWrapPosTB(size.Y)
+				if wp <= 0 || wp >= len(sr.Text)-1 {
+					break
+				}
+				nsr := sr.SplitAtTB(wp)
+				tr.InsertSpan(si+1, nsr)
+				tr.SplitLinks(si, wp)
+				maxh = Max32(maxh, sr.RelPos.Y+sr.LastPos.Y)
+				si++
+				sr = &(tr.Spans[si]) // keep going with nsr
+				sr.SetRunePosTB(txtSty.LetterSpacing.Dots, txtSty.WordSpacing.Dots, upright)
+				if sr.LastPos.Y <= size.Y {
+					break
+				}
+			}
+		}
+		maxh = Max32(maxh, sr.RelPos.Y+sr.LastPos.Y)
+		si++
+	}
+
+	tr.ClampLinks()
+
+	if maxh > size.Y {
+		size.Y = maxh
+	}
+
+	nsp := len(tr.Spans)
+	npara := 0
+	for si := 1; si < nsp; si++ {
+		if tr.Spans[si].IsNewPara() {
+			npara++
+		}
+	}
+
+	wd := lspc*float32(nsp) + float32(npara)*txtSty.ParaSpacing.Dots
+	if wd > size.X {
+		size.X = wd
+	}
+
+	tr.Size = Vec2D{wd, maxh}
+
+	cpos := float32(0) // position of the start of the current column from the start side
+	for si := range tr.Spans {
+		sr := &(tr.Spans[si])
+		if si > 0 && sr.IsNewPara() {
+			cpos += txtSty.ParaSpacing.Dots
+		}
+		if tr.Dir == TBLR {
+			sr.RelPos.X = cpos + 0.5*lspc
+		} else {
+			sr.RelPos.X = size.X - cpos - 0.5*lspc
+		}
+		vextra := size.Y - (sr.RelPos.Y + sr.LastPos.Y)
+		if vextra > 0 {
+			switch {
+			case IsAlignMiddle(txtSty.Align):
+				sr.RelPos.Y += vextra / 2
+			case IsAlignEnd(txtSty.Align):
+				sr.RelPos.Y += vextra
+			}
+		}
+		sr.LastPos.X = sr.RelPos.X
+		cpos += lspc
+	}
+	return size
+}
+
+// LayoutStd does standard layout of text according to the writing-mode of
+// the given text style: LayoutStdTB for vertical modes, and otherwise
+// LayoutStdLR
+func (tr *TextRender) LayoutStd(txtSty *TextStyle, fontSty *FontStyle, ctxt *units.Context, size Vec2D) Vec2D {
+	if txtSty.IsVertical() {
+		return tr.LayoutStdTB(txtSty, fontSty, ctxt, size)
+	}
+	return tr.LayoutStdLR(txtSty, fontSty, ctxt, size)
+}
+
+// SplitLinks updates the links after span si has been split at rune index
+// wp, with the remainder inserted as the next span
+func (tr *TextRender) SplitLinks(si, wp int) {
+	for li := range tr.Links {
+		tl := &tr.Links[li]
+		if tl.StartSpan == si {
+			if tl.StartIdx >= wp {
+				tl.StartIdx -= wp
+				tl.StartSpan++
+			}
+		} else if tl.StartSpan > si {
+			tl.StartSpan++
+		}
+		if tl.EndSpan == si {
+			if tl.EndIdx >= wp {
+				tl.EndIdx -= wp
+				tl.EndSpan++
+			}
+		} else if tl.EndSpan > si {
+			tl.EndSpan++
+		}
+	}
+}
+
+// ClampLinks makes sure the links are still in range of their spans
+func (tr *TextRender) ClampLinks() {
+	for li := range tr.Links {
+		tl := &tr.Links[li]
+		stsp := tr.Spans[tl.StartSpan]
+		if tl.StartIdx >= len(stsp.Text) {
+			tl.StartIdx = len(stsp.Text) - 1
+		}
+		edsp := tr.Spans[tl.EndSpan]
+		if tl.EndIdx >= len(edsp.Text) {
+			tl.EndIdx = len(edsp.Text) - 1
+		}
+	}
+}
+
 //////////////////////////////////////////////////////////////////////////////////
 //  Utilities
 
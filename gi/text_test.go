@@ -0,0 +1,137 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/goki/ki"
+	"golang.org/x/image/font/basicfont"
+)
+
+// tbSpan returns a span of given text in the basic 7x13 font (ascent 11,
+// descent 2), laid out vertically
+func tbSpan(txt string) *SpanRender {
+	sr := &SpanRender{Text: []rune(txt)}
+	sr.Render = make([]RuneRender, len(sr.Text))
+	sr.Render[0].Face = basicfont.Face7x13
+	sr.SetRunePosTB(0, 0, false)
+	return sr
+}
+
+func TestSetRunePosTB(t *testing.T) {
+	sr := tbSpan("ab 日本語 cd")
+	want := []float32{0, 7, 14, 21, 34, 47, 60, 67, 74, 81}
+	pos := sr.RunePosTB()
+	for i := range want {
+		if pos[i] != want[i] {
+			t.Errorf("RunePosTB: %v, want: %v", pos, want)
+			break
+		}
+	}
+	if sr.LastPos.Y != 81 || sr.Dir != TBRL {
+		t.Errorf("LastPos: %v, Dir: %v", sr.LastPos, sr.Dir)
+	}
+	if rr := sr.Render[0]; rr.RotRad == 0 || rr.RelPos != (Vec2D{-4.5, 0}) {
+		t.Errorf("sideways rune: %v rot: %v", rr.RelPos, rr.RotRad)
+	}
+	if rr := sr.Render[3]; rr.RotRad != 0 || rr.RelPos != (Vec2D{-3.5, 32}) {
+		t.Errorf("upright rune: %v rot: %v", rr.RelPos, rr.RotRad)
+	}
+	if sz := sr.SizeHV(); sz != (Vec2D{0, 81}) {
+		t.Errorf("SizeHV: %v", sz)
+	}
+
+	wraps := []struct {
+		trg float32
+		wp  int
+	}{
+		{10, 3}, // no break within size: after the space
+		{40, 4}, // between upright runes
+		{60, 7}, // at the end of the space
+		{100, -1},
+	}
+	for _, w := range wraps {
+		if wp := sr.FindWrapPosTB(w.trg); wp != w.wp {
+			t.Errorf("FindWrapPosTB %v: %v, want: %v", w.trg, wp, w.wp)
+		}
+	}
+	nsr := sr.SplitAtTB(4)
+	if string(sr.Text) != "ab 日" || sr.LastPos.Y != 34 || string(nsr.Text) != "本語 cd" || nsr.Render[0].Face == nil {
+		t.Errorf("SplitAtTB: %q %v, %q", string(sr.Text), sr.LastPos.Y, string(nsr.Text))
+	}
+}
+
+func TestSetRunePosLRDir(t *testing.T) {
+	sr := tbSpan("ab")
+	sr.SetRunePosLR(0, 0, 7, 8)
+	if sr.Dir != LRTB {
+		t.Errorf("vertical span laid out horizontally: Dir: %v", sr.Dir)
+	}
+	if sz := sr.SizeHV(); sz.X != 14 || sz.Y != 0 {
+		t.Errorf("SizeHV: %v", sz)
+	}
+	sr.Dir = RLTB // set by SetBidi for a right-to-left paragraph
+	sr.SetRunePosLR(0, 0, 7, 8)
+	if sr.Dir != RLTB {
+		t.Errorf("right-to-left span: Dir: %v", sr.Dir)
+	}
+}
+
+func TestRuneUprightTB(t *testing.T) {
+	for _, r := range "日あア한。Ａ" {
+		if !RuneUprightTB(r) {
+			t.Errorf("%q should be upright", r)
+		}
+	}
+	for _, r := range "aЖ1「ー（" {
+		if RuneUprightTB(r) {
+			t.Errorf("%q should be sideways", r)
+		}
+	}
+}
+
+func TestWritingModes(t *testing.T) {
+	modes := map[string]TextDirections{"vertical-rl": TBRL, "tb-rl": TBRL, "vertical-lr": TBLR, "horizontal-tb": LRTB}
+	for wm, dir := range modes {
+		ts := TextStyle{}
+		ts.Defaults()
+		ts.SetStylePost(ki.Props{"writing-mode": wm, "text-orientation": "upright"})
+		if ts.WritingMode != dir || ts.IsVertical() != (dir != LRTB) || ts.OrientationVert != 0 {
+			t.Errorf("writing-mode %v: %v, orientation: %v", wm, ts.WritingMode, ts.OrientationVert)
+		}
+	}
+}
+
+func TestRenderGlyphMaskClip(t *testing.T) {
+	bb := image.Rect(0, 0, 40, 20)
+	glyph := image.NewAlpha(bb)
+	draw.Draw(glyph, glyph.Bounds(), image.Opaque, image.ZP, draw.Src)
+	clip := image.NewAlpha(bb) // clip to the left half
+	draw.Draw(clip, image.Rect(0, 0, 20, 20), image.Opaque, image.ZP, draw.Src)
+	src := image.NewUniform(color.Black)
+
+	rrs := []RuneRender{{}, {ScaleX: 2}, {RotRad: .1}} // untransformed, transformed
+	for i := range rrs {
+		rr := &rrs[i]
+		rs := &RenderState{Image: image.NewRGBA(bb), Bounds: bb}
+		renderGlyphMask(rs, src, rr, Vec2D{0, 20}, image.Rect(0, 0, 10, 20), glyph, image.ZP)
+		if a := rs.Image.RGBAAt(5, 10).A; a != 255 {
+			t.Errorf("glyph %v without clip: alpha: %v", i, a)
+		}
+
+		rs = &RenderState{Image: image.NewRGBA(bb), Bounds: bb, Mask: clip}
+		renderGlyphMask(rs, src, rr, Vec2D{15, 20}, image.Rect(15, 0, 25, 20), glyph, image.ZP)
+		if a := rs.Image.RGBAAt(17, 10).A; a != 255 {
+			t.Errorf("glyph %v inside clip: alpha: %v", i, a)
+		}
+		if a := rs.Image.RGBAAt(23, 10).A; a != 0 {
+			t.Errorf("glyph %v outside clip: alpha: %v", i, a)
+		}
+	}
+}
@@ -9,9 +9,9 @@ import (
 
 var _ = errors.New("dummy error")
 
-const _TextDirections_name = "LRTBRLTBTBRLLRRLTBLTRRTLTextDirectionsN"
+const _TextDirections_name = "LRTBRLTBTBRLLRRLTBLTRRTLTBLRTextDirectionsN"
 
-var _TextDirections_index = [...]uint8{0, 4, 8, 12, 14, 16, 18, 21, 24, 39}
+var _TextDirections_index = [...]uint8{0, 4, 8, 12, 14, 16, 18, 21, 24, 28, 43}
 
 func (i TextDirections) String() string {
 	if i < 0 || i >= TextDirections(len(_TextDirections_index)-1) {
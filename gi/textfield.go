@@ -18,6 +18,7 @@ import (
 	"github.com/goki/gi/complete"
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/ime"
 	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mimedata"
 	"github.com/goki/gi/oswin/mouse"
@@ -65,6 +66,15 @@ type TextField struct {
 	BlinkOn      bool                    `json:"-" xml:"-" desc:"oscillates between on and off for blinking"`
 	CursorMu     sync.Mutex              `json:"-" xml:"-" view:"-" desc:"mutex for updating cursor between blinker and field"`
 	Complete     *Complete               `json:"-" xml:"-" desc:"functions and data for textfield completion"`
+	NoEcho       bool                    `xml:"no-echo" desc:"password mode: each character is displayed as TextFieldNoEchoRune, and the text cannot be copied or cut -- set from no-echo property"`
+	MaxLen       int                     `xml:"max-len" desc:"maximum number of characters that can be entered -- 0 for no limit"`
+	Mask         string                  `xml:"mask" desc:"input mask that constrains the text to a given format, e.g., TextFieldMaskDate -- see MaskMatch for the mask characters -- an empty text is always allowed"`
+	Validator    func(txt string) error  `json:"-" xml:"-" view:"-" desc:"function that validates the edited text, returning an error describing why it is invalid, or nil -- invalid text is styled with the :invalid style and is not applied by EditDone -- see RegexpValidator"`
+	ValidErr     error                   `json:"-" xml:"-" view:"-" desc:"the error from the last validation of the text against the Mask and Validator -- nil if valid"`
+	UndoStack    []TextFieldUndo         `json:"-" xml:"-" view:"-" desc:"undo history of the edited text, with the most recent state last"`
+	RedoStack    []TextFieldUndo         `json:"-" xml:"-" view:"-" desc:"redo history of undone states of the edited text, with the most recently undone last"`
+	Preedit      Preedit                 `json:"-" xml:"-" view:"-" desc:"composition text from an input method editor (IME), shown inline at the cursor while composing"`
+	undoTyping   bool                    // the last undo state was saved at the start of a run of typed characters, which are grouped into one undo step
 }
 
 var KiT_TextField = kit.Types.AddType(&TextField{}, TextFieldProps)
@@ -77,6 +87,7 @@ var TextFieldProps = ki.Props{
 	"padding":          units.NewValue(4, units.Px),
 	"margin":           units.NewValue(1, units.Px),
 	"text-align":       AlignLeft,
+	"unicode-bidi":     BidiPlaintext,
 	"color":            &Prefs.Colors.Font,
 	"background-color": &Prefs.Colors.Control,
 	"clear-act":        true,
@@ -100,6 +111,10 @@ var TextFieldProps = ki.Props{
 	TextFieldSelectors[TextFieldSel]: ki.Props{
 		"background-color": &Prefs.Colors.Select,
 	},
+	TextFieldSelectors[TextFieldInvalid]: ki.Props{
+		"border-width": units.NewValue(2, units.Px),
+		"border-color": "#e02020",
+	},
 }
 
 // TextFieldSignals are signals that that textfield can send
@@ -126,6 +141,10 @@ const (
 	// TextFieldCleared means the clear button was clicked
 	TextFieldCleared
 
+	// TextFieldRejected means that the edit was not applied because the text
+	// failed validation against the Mask or Validator.  data is the error.
+	TextFieldRejected
+
 	TextFieldSignalsN
 )
 
@@ -147,13 +166,29 @@ const (
 	// selected -- for inactive state, can select entire element
 	TextFieldSel
 
+	// invalid -- the text failed validation against the Mask or Validator
+	TextFieldInvalid
+
 	TextFieldStatesN
 )
 
 //go:generate stringer -type=TextFieldStates
 
 // Style selector names for the different states
-var TextFieldSelectors = []string{":active", ":focus", ":inactive", ":selected"}
+var TextFieldSelectors = []string{":active", ":focus", ":inactive", ":selected", ":invalid"}
+
+// TextFieldNoEchoRune is the rune that is displayed in place of each
+// character of a NoEcho (password) TextField
+var TextFieldNoEchoRune = '•'
+
+// TextFieldUndoMax is the maximum number of undo steps saved for each TextField
+var TextFieldUndoMax = 100
+
+// TextFieldUndo is one saved state of the edited text of a TextField, for undo / redo
+type TextFieldUndo struct {
+	Txt       []rune `desc:"the edited text"`
+	CursorPos int    `desc:"the cursor position"`
+}
 
 // these extend NodeBase NodeFlags to hold TextField state
 const (
@@ -184,20 +219,28 @@ func (tf *TextField) SetText(txt string) {
 	}
 	tf.Txt = txt
 	tf.Revert()
+	tf.UndoStack = nil
+	tf.RedoStack = nil
+	tf.undoTyping = false
 }
 
 // Label returns the display label for this node, satisfying the Labeler interface
 func (tf *TextField) Label() string {
-	if tf.Txt != "" {
+	if tf.Txt != "" && !tf.NoEcho {
 		return tf.Txt
 	}
 	return tf.Nm
 }
 
 // EditDone completes editing and copies the active edited text to the text --
-// called when the return key is pressed or goes out of focus
+// called when the return key is pressed or goes out of focus -- if the text
+// is not valid, it is not applied, and a TextFieldRejected signal is sent
 func (tf *TextField) EditDone() {
 	if tf.Edited {
+		if !tf.Validate() {
+			tf.TextFieldSig.Emit(tf.This(), int64(TextFieldRejected), tf.ValidErr)
+			return
+		}
 		tf.Edited = false
 		tf.Txt = string(tf.EditTxt)
 		tf.TextFieldSig.Emit(tf.This(), int64(TextFieldDone), tf.Txt)
@@ -207,9 +250,16 @@ func (tf *TextField) EditDone() {
 }
 
 // EditDeFocused completes editing and copies the active edited text to the text --
-// called when field is made inactive due to interactions elsewhere.
+// called when field is made inactive due to interactions elsewhere -- invalid
+// text is not applied, as in EditDone
 func (tf *TextField) EditDeFocused() {
 	if tf.Edited {
+		if !tf.Validate() {
+			tf.TextFieldSig.Emit(tf.This(), int64(TextFieldRejected), tf.ValidErr)
+			tf.ClearSelected()
+			tf.ClearCursor()
+			return
+		}
 		tf.Edited = false
 		tf.Txt = string(tf.EditTxt)
 		tf.TextFieldSig.Emit(tf.This(), int64(TextFieldDeFocused), tf.Txt)
@@ -222,8 +272,12 @@ func (tf *TextField) EditDeFocused() {
 func (tf *TextField) Revert() {
 	updt := tf.UpdateStart()
 	defer tf.UpdateEnd(updt)
+	if tf.Edited {
+		tf.SaveUndo(false)
+	}
 	tf.EditTxt = []rune(tf.Txt)
 	tf.Edited = false
+	tf.ValidErr = nil
 	tf.StartPos = 0
 	tf.EndPos = tf.CharWidth
 	tf.SelectReset()
@@ -233,6 +287,7 @@ func (tf *TextField) Revert() {
 func (tf *TextField) Clear() {
 	updt := tf.UpdateStart()
 	defer tf.UpdateEnd(updt)
+	tf.SaveUndo(false)
 	tf.Edited = true
 	tf.EditTxt = tf.EditTxt[:0]
 	tf.StartPos = 0
@@ -282,6 +337,31 @@ func (tf *TextField) CursorBackward(steps int) {
 	}
 }
 
+// CursorMoveVisual moves the cursor one position visually to the right (dir
+// > 0) or left (dir < 0) -- in bidirectional text, this can be forward or
+// backward in the text, and otherwise it is the same as CursorForward or
+// CursorBackward
+func (tf *TextField) CursorMoveVisual(dir int) {
+	sr := tf.BidiSpan()
+	if sr == nil {
+		if dir > 0 {
+			tf.CursorForward(1)
+		} else {
+			tf.CursorBackward(1)
+		}
+		return
+	}
+	np := sr.CursorMoveVisual(tf.CursorPos, dir)
+	switch {
+	case np < 0:
+		return
+	case np > tf.CursorPos:
+		tf.CursorForward(np - tf.CursorPos)
+	case np < tf.CursorPos:
+		tf.CursorBackward(tf.CursorPos - np)
+	}
+}
+
 // CursorStart moves the cursor to the start of the text, updating selection
 // if select mode is active
 func (tf *TextField) CursorStart() {
@@ -332,6 +412,7 @@ func (tf *TextField) CursorBackspace(steps int) {
 	}
 	updt := tf.UpdateStart()
 	defer tf.UpdateEnd(updt)
+	tf.SaveUndo(false)
 	tf.Edited = true
 	tf.EditTxt = append(tf.EditTxt[:tf.CursorPos-steps], tf.EditTxt[tf.CursorPos:]...)
 	tf.CursorBackward(steps)
@@ -353,6 +434,7 @@ func (tf *TextField) CursorDelete(steps int) {
 	}
 	updt := tf.UpdateStart()
 	defer tf.UpdateEnd(updt)
+	tf.SaveUndo(false)
 	tf.Edited = true
 	tf.EditTxt = append(tf.EditTxt[:tf.CursorPos], tf.EditTxt[tf.CursorPos+steps:]...)
 }
@@ -438,7 +520,7 @@ func (tf *TextField) SelectWord() {
 	updt := tf.UpdateStart()
 	defer tf.UpdateEnd(updt)
 	sz := len(tf.EditTxt)
-	if sz <= 3 {
+	if sz <= 3 || tf.NoEcho {
 		tf.SelectAll()
 		return
 	}
@@ -505,8 +587,12 @@ func (tf *TextField) SelectUpdate() {
 	}
 }
 
-// Cut cuts any selected text and adds it to the clipboard, also returns cut text
+// Cut cuts any selected text and adds it to the clipboard, also returns cut
+// text -- does nothing in NoEcho mode
 func (tf *TextField) Cut() string {
+	if tf.NoEcho {
+		return ""
+	}
 	wupdt := tf.Viewport.Win.UpdateStart()
 	defer tf.Viewport.Win.UpdateEnd(wupdt)
 	cut := tf.DeleteSelection()
@@ -526,6 +612,7 @@ func (tf *TextField) DeleteSelection() string {
 	updt := tf.UpdateStart()
 	defer tf.UpdateEnd(updt)
 	cut := tf.Selection()
+	tf.SaveUndo(false)
 	tf.Edited = true
 	tf.EditTxt = append(tf.EditTxt[:tf.SelectStart], tf.EditTxt[tf.SelectEnd:]...)
 	if tf.CursorPos > tf.SelectStart {
@@ -540,8 +627,11 @@ func (tf *TextField) DeleteSelection() string {
 }
 
 // Copy copies any selected text to the clipboard, and returns that text,
-// optionaly resetting the current selection
+// optionaly resetting the current selection -- does nothing in NoEcho mode
 func (tf *TextField) Copy(reset bool) string {
+	if tf.NoEcho {
+		return ""
+	}
 	wupdt := tf.Viewport.Win.UpdateStart()
 	defer tf.Viewport.Win.UpdateEnd(wupdt)
 	tf.SelectUpdate()
@@ -570,18 +660,39 @@ func (tf *TextField) Paste() {
 	}
 }
 
-// InsertAtCursor inserts given text at current cursor position
+// InsertAtCursor inserts given text at current cursor position -- text that
+// does not fit the Mask or MaxLen is dropped
 func (tf *TextField) InsertAtCursor(str string) {
+	tf.SaveUndo(false)
+	tf.insertAtCursor(str)
+}
+
+// insertAtCursor inserts given text at current cursor position, without
+// saving the undo state
+func (tf *TextField) insertAtCursor(str string) {
 	wupdt := tf.Viewport.Win.UpdateStart()
 	defer tf.Viewport.Win.UpdateEnd(wupdt)
 	updt := tf.UpdateStart()
 	defer tf.UpdateEnd(updt)
 	if tf.HasSelection() {
-		tf.Cut()
+		if tf.NoEcho {
+			tf.DeleteSelection()
+		} else {
+			tf.Cut()
+		}
 	}
-	tf.Edited = true
 	rs := []rune(str)
+	if tf.Mask != "" {
+		rs = MaskInsert(tf.Mask, tf.EditTxt, tf.CursorPos, rs)
+	}
+	if tf.MaxLen > 0 && len(tf.EditTxt)+len(rs) > tf.MaxLen {
+		rs = rs[:ints.MaxInt(0, tf.MaxLen-len(tf.EditTxt))]
+	}
 	rsl := len(rs)
+	if rsl == 0 {
+		return
+	}
+	tf.Edited = true
 	nt := append(tf.EditTxt, rs...)                // first append to end
 	copy(nt[tf.CursorPos+rsl:], nt[tf.CursorPos:]) // move stuff to end
 	copy(nt[tf.CursorPos:], rs)                    // copy into position
@@ -599,7 +710,7 @@ func (tf *TextField) MakeContextMenu(m *Menu) {
 			tff := recv.Embed(KiT_TextField).(*TextField)
 			tff.Copy(true)
 		})
-	ac.SetActiveState(tf.HasSelection())
+	ac.SetActiveState(tf.HasSelection() && !tf.NoEcho)
 	if !tf.IsInactive() {
 		ctsc := ActiveKeyMap.ChordForFun(KeyFunCut)
 		ptsc := ActiveKeyMap.ChordForFun(KeyFunPaste)
@@ -608,16 +719,152 @@ func (tf *TextField) MakeContextMenu(m *Menu) {
 				tff := recv.Embed(KiT_TextField).(*TextField)
 				tff.Cut()
 			})
-		ac.SetActiveState(tf.HasSelection())
+		ac.SetActiveState(tf.HasSelection() && !tf.NoEcho)
 		ac = m.AddAction(ActOpts{Label: "Paste", Shortcut: ptsc},
 			tf.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 				tff := recv.Embed(KiT_TextField).(*TextField)
 				tff.Paste()
 			})
 		ac.SetInactiveState(oswin.TheApp.ClipBoard(tf.Viewport.Win.OSWin).IsEmpty())
+		m.AddSeparator("sep-undo")
+		ac = m.AddAction(ActOpts{Label: "Undo", Shortcut: ActiveKeyMap.ChordForFun(KeyFunUndo)},
+			tf.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				tff := recv.Embed(KiT_TextField).(*TextField)
+				tff.Undo()
+			})
+		ac.SetActiveState(len(tf.UndoStack) > 0)
+		ac = m.AddAction(ActOpts{Label: "Redo", Shortcut: ActiveKeyMap.ChordForFun(KeyFunRedo)},
+			tf.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				tff := recv.Embed(KiT_TextField).(*TextField)
+				tff.Redo()
+			})
+		ac.SetActiveState(len(tf.RedoStack) > 0)
 	}
 }
 
+///////////////////////////////////////////////////////////////////////////////
+//    Undo / Redo
+
+// SaveUndo saves the current state of the edited text on the UndoStack,
+// prior to an edit, and discards any redo history -- typing indicates that
+// the edit is a typed character: runs of typed characters are grouped into
+// one undo step, which ends at a space or any other key or edit
+func (tf *TextField) SaveUndo(typing bool) {
+	if typing && tf.undoTyping {
+		return
+	}
+	n := len(tf.UndoStack)
+	if n > 0 && string(tf.UndoStack[n-1].Txt) == string(tf.EditTxt) {
+		// already saved, e.g., for an edit made within another edit
+		if typing {
+			tf.undoTyping = true
+			tf.RedoStack = nil
+		}
+		return
+	}
+	tf.undoTyping = typing
+	txt := make([]rune, len(tf.EditTxt))
+	copy(txt, tf.EditTxt)
+	tf.UndoStack = append(tf.UndoStack, TextFieldUndo{Txt: txt, CursorPos: tf.CursorPos})
+	if len(tf.UndoStack) > TextFieldUndoMax {
+		tf.UndoStack = tf.UndoStack[len(tf.UndoStack)-TextFieldUndoMax:]
+	}
+	tf.RedoStack = nil
+}
+
+// Undo reverts the edited text to the previous state on the UndoStack,
+// returning false if there is nothing to undo
+func (tf *TextField) Undo() bool {
+	n := len(tf.UndoStack)
+	if n == 0 {
+		return false
+	}
+	ur := tf.UndoStack[n-1]
+	tf.UndoStack = tf.UndoStack[:n-1]
+	tf.RedoStack = append(tf.RedoStack, tf.undoState())
+	tf.restoreUndo(ur)
+	return true
+}
+
+// Redo restores the edited text to the last state that was undone,
+// returning false if there is nothing to redo
+func (tf *TextField) Redo() bool {
+	n := len(tf.RedoStack)
+	if n == 0 {
+		return false
+	}
+	ur := tf.RedoStack[n-1]
+	tf.RedoStack = tf.RedoStack[:n-1]
+	tf.UndoStack = append(tf.UndoStack, tf.undoState())
+	tf.restoreUndo(ur)
+	return true
+}
+
+// undoState returns a copy of the current state of the edited text
+func (tf *TextField) undoState() TextFieldUndo {
+	txt := make([]rune, len(tf.EditTxt))
+	copy(txt, tf.EditTxt)
+	return TextFieldUndo{Txt: txt, CursorPos: tf.CursorPos}
+}
+
+// restoreUndo sets the edited text to given undo state
+func (tf *TextField) restoreUndo(ur TextFieldUndo) {
+	wupdt := tf.Viewport.Win.UpdateStart()
+	defer tf.Viewport.Win.UpdateEnd(wupdt)
+	updt := tf.UpdateStart()
+	defer tf.UpdateEnd(updt)
+	tf.undoTyping = false
+	tf.EditTxt = ur.Txt
+	tf.Edited = string(tf.EditTxt) != tf.Txt
+	tf.CursorPos = InRangeInt(ur.CursorPos, 0, len(tf.EditTxt))
+	tf.SelectReset()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//    Validation
+
+// Validate checks the edited text against the Mask (which must be complete,
+// unless the text is empty) and the Validator, setting ValidErr, and
+// returning true if the text is valid
+func (tf *TextField) Validate() bool {
+	tf.ValidErr = nil
+	txt := string(tf.EditTxt)
+	if tf.Mask != "" && txt != "" {
+		if _, complete := MaskMatch(tf.Mask, txt); !complete {
+			tf.ValidErr = fmt.Errorf("text does not match the required format: %v", tf.Mask)
+		}
+	}
+	if tf.ValidErr == nil && tf.Validator != nil {
+		tf.ValidErr = tf.Validator(txt)
+	}
+	return tf.ValidErr == nil
+}
+
+// SetValidRegexp sets the Validator to require the entire text to match the
+// given regular expression, with the given message as the error if it does
+// not -- returns an error if the regexp is invalid
+func (tf *TextField) SetValidRegexp(re string, msg string) error {
+	vf, err := RegexpValidator(re, msg)
+	if err != nil {
+		return err
+	}
+	tf.Validator = vf
+	return nil
+}
+
+// DisplayRunes returns the runes to display for given runes of the text --
+// the runes themselves, or TextFieldNoEchoRune for each in NoEcho mode
+func (tf *TextField) DisplayRunes(rs []rune) []rune {
+	if !tf.NoEcho {
+		return rs
+	}
+	dr := make([]rune, len(rs))
+	for i := range dr {
+		dr[i] = TextFieldNoEchoRune
+	}
+	return dr
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 //    Complete
 
@@ -700,7 +947,9 @@ func (tf *TextField) TextWidth(st, ed int) float32 {
 	return tf.StartCharPos(ed) - tf.StartCharPos(st)
 }
 
-// StartCharPos returns the starting position of the given rune
+// StartCharPos returns the starting position of the given rune -- for
+// bidirectional text, this is its offset in logical order, as used for
+// scrolling, not its rendered position (see CharStartPos)
 func (tf *TextField) StartCharPos(idx int) float32 {
 	if idx <= 0 || len(tf.RenderAll.Spans) != 1 {
 		return 0.0
@@ -710,6 +959,13 @@ func (tf *TextField) StartCharPos(idx int) float32 {
 	if sz == 0 {
 		return 0.0
 	}
+	if sr.HasBidi() {
+		var x float32
+		for i := 0; i < idx && i < sz; i++ {
+			x += sr.Render[i].Size.X
+		}
+		return x
+	}
 	if idx >= sz {
 		return sr.LastPos.X
 	}
@@ -723,10 +979,40 @@ func (tf *TextField) CharStartPos(charidx int) Vec2D {
 	st := &tf.Sty
 	spc := st.BoxSpace()
 	pos := tf.LayData.AllocPos.AddVal(spc)
+	if sr := tf.BidiSpan(); sr != nil {
+		return Vec2D{pos.X + sr.RuneRelPos(charidx).X - tf.BidiVisStart(), pos.Y}
+	}
 	cpos := tf.TextWidth(tf.StartPos, charidx)
 	return Vec2D{pos.X + cpos, pos.Y}
 }
 
+// BidiSpan returns the rendered span of the text if it has bidirectional
+// text, where rendered positions are not in logical order, and nil otherwise
+func (tf *TextField) BidiSpan() *SpanRender {
+	if len(tf.RenderAll.Spans) != 1 {
+		return nil
+	}
+	sr := &(tf.RenderAll.Spans[0])
+	if len(sr.Text) != len(tf.EditTxt) || !sr.HasBidi() {
+		return nil
+	}
+	return sr
+}
+
+// BidiVisStart returns the rendered position of the left edge of the visible
+// text, for bidirectional text (see BidiSpan)
+func (tf *TextField) BidiVisStart() float32 {
+	sr := tf.BidiSpan()
+	if sr == nil || tf.StartPos == 0 {
+		return 0
+	}
+	mx := sr.LastPos.X
+	for i := tf.StartPos; i < tf.EndPos && i < len(sr.Render); i++ {
+		mx = math32.Min(mx, sr.Render[i].RelPos.X)
+	}
+	return mx
+}
+
 // TextFieldBlinkMu is mutex protecting TextFieldBlink updating and access
 var TextFieldBlinkMu sync.Mutex
 
@@ -851,7 +1137,14 @@ func (tf *TextField) RenderCursor(on bool) {
 	} else {
 		win.InactivateSprite(sp.Nm)
 	}
-	sp.Geom.Pos = tf.CharStartPos(tf.CursorPos).ToPointFloor()
+	cpos := tf.CharStartPos(tf.CursorPos)
+	if tf.Preedit.IsActive() {
+		cpos.X += tf.Preedit.CursorOff
+	}
+	sp.Geom.Pos = cpos.ToPointFloor()
+	if on && win.OSWin != nil {
+		win.OSWin.SetIMEPos(image.Point{sp.Geom.Pos.X, sp.Geom.Pos.Y + int(tf.FontHeight)})
+	}
 	win.RenderOverlays() // needs an explicit call!
 	win.UpdateSig()      // publish
 }
@@ -912,6 +1205,13 @@ func (tf *TextField) RenderSelect() {
 	rs := &tf.Viewport.Render
 	pc := &rs.Paint
 	st := &tf.StateStyles[TextFieldSel]
+	if sr := tf.BidiSpan(); sr != nil { // selection can be visually discontinuous
+		x0 := tf.LayData.AllocPos.X + tf.Sty.BoxSpace() - tf.BidiVisStart()
+		for _, rng := range sr.VisualRangesLR(effst, effed) {
+			pc.FillBox(rs, Vec2D{x0 + rng[0], spos.Y}, Vec2D{rng[1] - rng[0], tf.FontHeight}, &st.Font.BgColor)
+		}
+		return
+	}
 	tsz := tf.TextWidth(effst, effed)
 	pc.FillBox(rs, spos, Vec2D{tsz, tf.FontHeight}, &st.Font.BgColor)
 }
@@ -1012,6 +1312,11 @@ func (tf *TextField) PixelToCursor(pixOff float32) int {
 	spc := st.BoxSpace()
 	px := pixOff - spc
 
+	if sr := tf.BidiSpan(); sr != nil {
+		c := sr.CursorAtX(px + tf.BidiVisStart())
+		return InRangeInt(c, tf.StartPos, tf.EndPos)
+	}
+
 	if px <= 0 {
 		return tf.StartPos
 	}
@@ -1058,6 +1363,7 @@ func (tf *TextField) SetCursorFromPixel(pixOff float32, selMode mouse.SelectMode
 	defer tf.Viewport.Win.UpdateEnd(wupdt)
 	updt := tf.UpdateStart()
 	defer tf.UpdateEnd(updt)
+	tf.undoTyping = false
 	oldPos := tf.CursorPos
 	tf.CursorPos = tf.PixelToCursor(pixOff)
 	if tf.SelectMode || selMode != mouse.NoSelectMode {
@@ -1098,15 +1404,19 @@ func (tf *TextField) KeyInput(kt *key.ChordEvent) {
 		return
 	}
 
+	if kf != KeyFunNil {
+		tf.undoTyping = false // any other key ends a run of typing
+	}
+
 	// first all the keys that work for both inactive and active
 	switch kf {
 	case KeyFunMoveRight:
 		kt.SetProcessed()
-		tf.CursorForward(1)
+		tf.CursorMoveVisual(1)
 		tf.OfferComplete(dontForce)
 	case KeyFunMoveLeft:
 		kt.SetProcessed()
-		tf.CursorBackward(1)
+		tf.CursorMoveVisual(-1)
 		tf.OfferComplete(dontForce)
 	case KeyFunHome:
 		kt.SetProcessed()
@@ -1145,12 +1455,16 @@ func (tf *TextField) KeyInput(kt *key.ChordEvent) {
 		kt.SetProcessed()
 		tf.CancelComplete()
 		tf.EditDone()
-		tf.FocusNext()
+		if tf.ValidErr == nil { // stay here to fix invalid text
+			tf.FocusNext()
+		}
 	case KeyFunFocusPrev:
 		kt.SetProcessed()
 		tf.CancelComplete()
 		tf.EditDone()
-		tf.FocusPrev()
+		if tf.ValidErr == nil {
+			tf.FocusPrev()
+		}
 	case KeyFunAbort: // esc
 		kt.SetProcessed()
 		tf.CancelComplete()
@@ -1175,6 +1489,14 @@ func (tf *TextField) KeyInput(kt *key.ChordEvent) {
 		kt.SetProcessed()
 		tf.CancelComplete()
 		tf.Paste()
+	case KeyFunUndo:
+		kt.SetProcessed()
+		tf.CancelComplete()
+		tf.Undo()
+	case KeyFunRedo:
+		kt.SetProcessed()
+		tf.CancelComplete()
+		tf.Redo()
 	case KeyFunComplete:
 		kt.SetProcessed()
 		tf.OfferComplete(force)
@@ -1182,8 +1504,10 @@ func (tf *TextField) KeyInput(kt *key.ChordEvent) {
 		if unicode.IsPrint(kt.Rune) {
 			if !kt.HasAnyModifier(key.Control, key.Meta) {
 				kt.SetProcessed()
-				tf.InsertAtCursor(string(kt.Rune))
+				tf.SaveUndo(true)
+				tf.insertAtCursor(string(kt.Rune))
 				if kt.Rune == ' ' {
+					tf.undoTyping = false
 					tf.CancelComplete()
 				} else {
 					tf.OfferComplete(dontForce)
@@ -1193,6 +1517,22 @@ func (tf *TextField) KeyInput(kt *key.ChordEvent) {
 	}
 }
 
+// IMEInput handles composed text input from an input method editor (IME) --
+// the preedit text is shown at the cursor until the final text is committed
+// and inserted
+func (tf *TextField) IMEInput(ie *ime.Event) {
+	if tf.IsInactive() {
+		return
+	}
+	ie.SetProcessed()
+	tf.Preedit.SetFromEvent(ie)
+	if ie.Action == ime.Commit {
+		tf.InsertAtCursor(ie.Text)
+		tf.OfferComplete(dontForce)
+	}
+	tf.UpdateSig()
+}
+
 // HandleMouseEvent handles the mouse.Event
 func (tf *TextField) HandleMouseEvent(me *mouse.Event) {
 	if tf.Viewport == nil || tf.Viewport.Win == nil {
@@ -1294,12 +1634,21 @@ func (tf *TextField) KeyChordEvent() {
 	}
 }
 
+func (tf *TextField) IMEEvent() {
+	tf.ConnectEvent(oswin.IMEEvent, RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		tff := recv.Embed(KiT_TextField).(*TextField)
+		ie := d.(*ime.Event)
+		tff.IMEInput(ie)
+	})
+}
+
 func (tf *TextField) TextFieldEvents() {
 	tf.HoverTooltipEvent()
 	tf.MouseDragEvent()
 	tf.MouseEvent()
 	tf.MouseFocusEvent()
 	tf.KeyChordEvent()
+	tf.IMEEvent()
 }
 
 func (tf *TextField) ConfigParts() {
@@ -1353,6 +1702,9 @@ func (tf *TextField) StyleTextField() {
 	if pv, ok := tf.PropInherit("clear-act", true, true); ok {
 		tf.ClearAct, _ = kit.ToBool(pv)
 	}
+	if pv, ok := tf.Prop("no-echo"); ok {
+		tf.NoEcho, _ = kit.ToBool(pv)
+	}
 	tf.ConfigParts()
 	pr.End()
 }
@@ -1365,7 +1717,7 @@ func (tf *TextField) Style2D() {
 func (tf *TextField) UpdateRenderAll() bool {
 	st := &tf.Sty
 	st.Font.OpenFont(&st.UnContext)
-	tf.RenderAll.SetRunes(tf.EditTxt, &st.Font, &st.UnContext, &st.Text, true, 0, 0)
+	tf.RenderAll.SetRunes(tf.DisplayRunes(tf.EditTxt), &st.Font, &st.UnContext, &st.Text, true, 0, 0)
 	return true
 }
 
@@ -1437,6 +1789,9 @@ func (tf *TextField) Render2D() {
 		} else {
 			tf.Sty = tf.StateStyles[TextFieldActive]
 		}
+		if !tf.IsInactive() && !tf.Validate() {
+			tf.Sty = tf.StateStyles[TextFieldInvalid]
+		}
 		st := &tf.Sty
 		st.Font.OpenFont(&st.UnContext)
 		tf.RenderStdBox(st)
@@ -1448,8 +1803,14 @@ func (tf *TextField) Render2D() {
 			tf.RenderVis.SetString(tf.Placeholder, &st.Font, &st.UnContext, &st.Text, true, 0, 0)
 			tf.RenderVis.RenderTopPos(rs, pos)
 
+		} else if tf.Preedit.IsActive() && tf.CursorPos >= tf.StartPos && tf.CursorPos <= tf.EndPos {
+			ci := tf.CursorPos - tf.StartPos
+			vis := tf.Preedit.Insert(cur, ci)
+			tf.RenderVis.SetRunes(tf.DisplayRunes(vis), &st.Font, &st.UnContext, &st.Text, true, 0, 0)
+			tf.Preedit.Underline(&tf.RenderVis, ci)
+			tf.RenderVis.RenderTopPos(rs, pos)
 		} else {
-			tf.RenderVis.SetRunes(cur, &st.Font, &st.UnContext, &st.Text, true, 0, 0)
+			tf.RenderVis.SetRunes(tf.DisplayRunes(cur), &st.Font, &st.UnContext, &st.Text, true, 0, 0)
 			tf.RenderVis.RenderTopPos(rs, pos)
 		}
 		rs.Unlock()
@@ -1476,6 +1837,7 @@ func (tf *TextField) FocusChanged2D(change FocusChanges) {
 	switch change {
 	case FocusLost:
 		tf.ClearFlag(int(TextFieldFocusActive))
+		tf.Preedit.Clear()
 		tf.EditDone()
 		tf.UpdateSig()
 	case FocusGot:
@@ -0,0 +1,221 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gitest"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/headlessdriver"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/ki"
+)
+
+func TestMain(m *testing.M) {
+	headlessdriver.Main(func(app oswin.App) {
+		os.Exit(m.Run())
+	})
+}
+
+// textFieldWin makes a window with a text field named "tf", with given
+// props, calls fun (if non-nil) to configure it, and starts a harness for it
+// with the text field in focus
+func textFieldWin(t *testing.T, name string, props ki.Props, fun func(tf *gi.TextField)) (*gitest.Harness, *gi.TextField) {
+	win := gi.NewWindow2D(name, name, 400, 200, true)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	tf := mfr.AddNewChild(gi.KiT_TextField, "tf").(*gi.TextField)
+	for k, v := range props {
+		tf.SetProp(k, v)
+	}
+	if fun != nil {
+		fun(tf)
+	}
+	vp.UpdateEndNoSig(updt)
+	h := gitest.NewHarness(win)
+	if err := h.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.ClickOn(tf); err != nil {
+		h.Close()
+		t.Fatal(err)
+	}
+	return h, tf
+}
+
+// textFieldExpect checks the edited text of the text field
+func textFieldExpect(t *testing.T, tf *gi.TextField, what, exp string) {
+	t.Helper()
+	if txt := string(tf.EditTxt); txt != exp {
+		t.Errorf("%v: %q, want %q", what, txt, exp)
+	}
+}
+
+func TestTextFieldUndo(t *testing.T) {
+	h, tf := textFieldWin(t, "test-textfield-undo", nil, nil)
+	defer h.Close()
+
+	// a run of typed characters is one undo step, ending at a space
+	h.Type("abc def")
+	textFieldExpect(t, tf, "typed", "abc def")
+	h.KeyFun(gi.KeyFunUndo)
+	textFieldExpect(t, tf, "undo typed word", "abc ")
+	h.KeyFun(gi.KeyFunUndo)
+	textFieldExpect(t, tf, "undo typed word and space", "")
+	h.KeyFun(gi.KeyFunUndo)
+	textFieldExpect(t, tf, "undo with nothing to undo", "")
+	h.KeyFun(gi.KeyFunRedo)
+	textFieldExpect(t, tf, "redo", "abc ")
+	h.KeyFun(gi.KeyFunRedo)
+	textFieldExpect(t, tf, "redo", "abc def")
+	if tf.CursorPos != 7 || len(tf.RedoStack) != 0 {
+		t.Errorf("redo cursor: %v redos: %v", tf.CursorPos, len(tf.RedoStack))
+	}
+
+	// an edit after an undo discards the redo history
+	h.KeyFun(gi.KeyFunUndo)
+	h.Type("x")
+	h.KeyFun(gi.KeyFunRedo)
+	textFieldExpect(t, tf, "redo after edit", "abc x")
+
+	// any other key, here redo and move, ends a run of typing, and each
+	// deletion is its own step
+	h.Type("yz")
+	h.KeyFun(gi.KeyFunMoveLeft)
+	h.Type("1")
+	textFieldExpect(t, tf, "typed after move", "abc xy1z")
+	h.KeyCode(key.CodeDeleteBackspace)
+	h.KeyCode(key.CodeDeleteBackspace)
+	textFieldExpect(t, tf, "backspace", "abc xz")
+	h.KeyFun(gi.KeyFunUndo)
+	textFieldExpect(t, tf, "undo second backspace", "abc xyz")
+	h.KeyFun(gi.KeyFunUndo)
+	textFieldExpect(t, tf, "undo first backspace", "abc xy1z")
+	h.KeyFun(gi.KeyFunUndo)
+	textFieldExpect(t, tf, "undo typed after move", "abc xyz")
+	h.KeyFun(gi.KeyFunUndo)
+	textFieldExpect(t, tf, "undo typed run", "abc x")
+
+	// setting the text resets the history
+	tf.SetText("new")
+	if tf.Undo() || tf.Redo() {
+		t.Errorf("undo / redo after SetText")
+	}
+	textFieldExpect(t, tf, "set text", "new")
+}
+
+func TestTextFieldNoEcho(t *testing.T) {
+	h, tf := textFieldWin(t, "test-textfield-noecho", ki.Props{"no-echo": true}, nil)
+	defer h.Close()
+
+	h.Type("secret")
+	if !tf.NoEcho {
+		t.Fatalf("no-echo property not applied")
+	}
+	mask := strings.Repeat(string(gi.TextFieldNoEchoRune), 6)
+	if dr := string(tf.DisplayRunes(tf.EditTxt)); dr != mask {
+		t.Errorf("display runes: %q", dr)
+	}
+	if len(tf.RenderAll.Spans) != 1 || string(tf.RenderAll.Spans[0].Text) != mask {
+		t.Errorf("rendered text: %v", tf.RenderAll.Spans)
+	}
+
+	tf.SelectAll()
+	if cp := tf.Copy(false); cp != "" {
+		t.Errorf("copy in no-echo mode: %q", cp)
+	}
+	if ct := tf.Cut(); ct != "" {
+		t.Errorf("cut in no-echo mode: %q", ct)
+	}
+	textFieldExpect(t, tf, "after cut", "secret")
+	h.KeyFun(gi.KeyFunCopy)
+	h.KeyFun(gi.KeyFunCut)
+	textFieldExpect(t, tf, "after cut key", "secret")
+
+	h.KeyCode(key.CodeReturnEnter)
+	if tf.Txt != "secret" || tf.Label() != "tf" {
+		t.Errorf("text: %q label: %q", tf.Txt, tf.Label())
+	}
+}
+
+func TestTextFieldValidate(t *testing.T) {
+	var sigs []gi.TextFieldSignals
+	var data []interface{}
+	h, tf := textFieldWin(t, "test-textfield-validate", nil, func(tf *gi.TextField) {
+		if err := tf.SetValidRegexp("[0-9]+", "digits only"); err != nil {
+			t.Fatal(err)
+		}
+		tf.TextFieldSig.Connect(tf.This(), func(recv, send ki.Ki, sig int64, d interface{}) {
+			sigs = append(sigs, gi.TextFieldSignals(sig))
+			data = append(data, d)
+		})
+	})
+	defer h.Close()
+
+	h.Type("12a")
+	h.KeyCode(key.CodeReturnEnter)
+	if len(sigs) != 1 || sigs[0] != gi.TextFieldRejected {
+		t.Fatalf("invalid text signals: %v", sigs)
+	}
+	if err, ok := data[0].(error); !ok || err.Error() != "digits only" {
+		t.Errorf("rejected signal data: %v", data[0])
+	}
+	if tf.Txt != "" || !tf.Edited || tf.ValidErr == nil || h.Focus() != tf.This() {
+		t.Errorf("invalid text applied: %q edited: %v err: %v", tf.Txt, tf.Edited, tf.ValidErr)
+	}
+
+	h.KeyCode(key.CodeDeleteBackspace)
+	h.KeyCode(key.CodeReturnEnter)
+	if len(sigs) != 2 || sigs[1] != gi.TextFieldDone || data[1] != "12" {
+		t.Errorf("valid text signals: %v %v", sigs, data)
+	}
+	if tf.Txt != "12" || tf.Edited || tf.ValidErr != nil {
+		t.Errorf("valid text not applied: %q edited: %v err: %v", tf.Txt, tf.Edited, tf.ValidErr)
+	}
+
+	// an incomplete mask is invalid, but an empty text is always allowed
+	tf.Validator = nil
+	tf.Mask = gi.TextFieldMaskDate
+	tf.EditTxt = []rune("2018-1")
+	if tf.Validate() {
+		t.Errorf("incomplete mask should be invalid")
+	}
+	tf.EditTxt = []rune("2018-10-21")
+	if !tf.Validate() {
+		t.Errorf("complete mask: %v", tf.ValidErr)
+	}
+	tf.EditTxt = nil
+	if !tf.Validate() {
+		t.Errorf("empty text with mask: %v", tf.ValidErr)
+	}
+}
+
+func TestTextFieldMaxLen(t *testing.T) {
+	h, tf := textFieldWin(t, "test-textfield-maxlen", nil, func(tf *gi.TextField) {
+		tf.MaxLen = 5
+	})
+	defer h.Close()
+
+	h.Type("abcdefg")
+	textFieldExpect(t, tf, "typed past max", "abcde")
+	h.KeyFun(gi.KeyFunHome)
+	h.Type("x")
+	textFieldExpect(t, tf, "typed at start when full", "abcde")
+	h.KeyFun(gi.KeyFunEnd)
+	h.KeyCode(key.CodeDeleteBackspace)
+	h.KeyCode(key.CodeDeleteBackspace)
+	textFieldExpect(t, tf, "deleted", "abc")
+	// inserted text is truncated to fit
+	tf.InsertAtCursor("12345")
+	textFieldExpect(t, tf, "inserted past max", "abc12")
+	if tf.CursorPos != 5 {
+		t.Errorf("cursor after truncated insert: %v", tf.CursorPos)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Input Masks
+
+// TextFieldMaskDate is an input mask for dates in YYYY-MM-DD format
+var TextFieldMaskDate = "9999-99-99"
+
+// TextFieldMaskTime is an input mask for times in HH:MM format
+var TextFieldMaskTime = "99:99"
+
+// TextFieldMaskIPv4 is an input mask for IPv4 addresses, e.g., 192.168.0.1
+// -- it does not check that each number is <= 255, which can be done with a
+// Validator
+var TextFieldMaskIPv4 = "9##.9##.9##.9##"
+
+// maskElem is one element of a compiled input mask
+type maskElem struct {
+	class rune // one of 9 # a *, or 0 for a literal
+	lit   rune // the literal rune, if class is 0
+}
+
+// matches returns true if given rune is accepted by the element
+func (me maskElem) matches(r rune) bool {
+	switch me.class {
+	case '9', '#':
+		return unicode.IsDigit(r)
+	case 'a':
+		return unicode.IsLetter(r)
+	case '*':
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	return r == me.lit
+}
+
+// compileMask compiles the mask string into elements
+func compileMask(mask string) []maskElem {
+	rs := []rune(mask)
+	els := make([]maskElem, 0, len(rs))
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch r {
+		case '9', '#', 'a', '*':
+			els = append(els, maskElem{class: r})
+		case '\\':
+			if i+1 < len(rs) {
+				i++
+				els = append(els, maskElem{lit: rs[i]})
+			}
+		default:
+			els = append(els, maskElem{lit: r})
+		}
+	}
+	return els
+}
+
+// maskClosure adds the states reachable by skipping optional elements
+func maskClosure(els []maskElem, sts []bool) {
+	for i := range els {
+		if sts[i] && els[i].class == '#' {
+			sts[i+1] = true
+		}
+	}
+}
+
+// maskStates returns the set of mask positions that the given text can
+// reach (len(els) = the end of the mask), or nil if the text does not match
+// the start of the mask
+func maskStates(els []maskElem, txt []rune) []bool {
+	sts := make([]bool, len(els)+1)
+	sts[0] = true
+	maskClosure(els, sts)
+	for _, r := range txt {
+		nsts := make([]bool, len(els)+1)
+		matched := false
+		for i, me := range els {
+			if sts[i] && me.matches(r) {
+				nsts[i+1] = true
+				matched = true
+			}
+		}
+		if !matched {
+			return nil
+		}
+		maskClosure(els, nsts)
+		sts = nsts
+	}
+	return sts
+}
+
+// maskNextLit returns the literal that must come next after given text, if
+// there is exactly one such literal, and nothing else could come next
+func maskNextLit(els []maskElem, txt []rune) (rune, bool) {
+	sts := maskStates(els, txt)
+	if sts == nil {
+		return 0, false
+	}
+	var lit rune
+	for i, me := range els {
+		if !sts[i] {
+			continue
+		}
+		if me.class != 0 || (lit != 0 && me.lit != lit) {
+			return 0, false
+		}
+		lit = me.lit
+	}
+	return lit, lit != 0
+}
+
+// MaskMatch checks given text against an input mask, returning prefix = true
+// if the text matches the start of the mask (i.e., is valid so far), and
+// complete = true if it matches the entire mask.  Input masks constrain the
+// text of a TextField to a given format, where each character of the mask is:
+//   - 9 is a required digit
+//   - # is an optional digit
+//   - a is a required letter
+//   - * is a required letter or digit
+//   - \ escapes the following character, which is then a literal
+//   - any other character is a literal that must appear as-is, and is
+//     inserted automatically while typing
+func MaskMatch(mask string, txt string) (prefix, complete bool) {
+	els := compileMask(mask)
+	sts := maskStates(els, []rune(txt))
+	if sts == nil {
+		return false, false
+	}
+	return true, sts[len(els)]
+}
+
+// MaskInsert returns the runes to actually insert into given text at given
+// position to enter the given runes, consistent with the input mask -- runes
+// that are not accepted are dropped, and any literals of the mask that must
+// come before an accepted rune are inserted automatically
+func MaskInsert(mask string, txt []rune, pos int, ins []rune) []rune {
+	els := compileMask(mask)
+	pre := txt[:pos]
+	post := txt[pos:]
+	var out []rune
+	try := func(add []rune) bool {
+		nt := make([]rune, 0, len(txt)+len(out)+len(add))
+		nt = append(nt, pre...)
+		nt = append(nt, out...)
+		nt = append(nt, add...)
+		nt = append(nt, post...)
+		return maskStates(els, nt) != nil
+	}
+	for _, r := range ins {
+		if try([]rune{r}) {
+			out = append(out, r)
+			continue
+		}
+		var lits []rune
+		for {
+			cur := make([]rune, 0, len(pre)+len(out)+len(lits))
+			cur = append(cur, pre...)
+			cur = append(cur, out...)
+			cur = append(cur, lits...)
+			lit, ok := maskNextLit(els, cur)
+			if !ok {
+				break
+			}
+			lits = append(lits, lit)
+			add := append(append([]rune{}, lits...), r)
+			if try(add) {
+				out = append(out, add...)
+				break
+			}
+		}
+	}
+	return out
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Validation
+
+// RegexpValidator returns a TextField Validator function that requires the
+// entire text to match the given regular expression, with the given message
+// as the error if it does not (a standard message is used if empty)
+func RegexpValidator(re string, msg string) (func(txt string) error, error) {
+	rx, err := regexp.Compile("^(?:" + re + ")$")
+	if err != nil {
+		return nil, err
+	}
+	return func(txt string) error {
+		if rx.MatchString(txt) {
+			return nil
+		}
+		if msg != "" {
+			return errors.New(msg)
+		}
+		return fmt.Errorf("text does not match the required pattern: %v", re)
+	}, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"testing"
+)
+
+func TestMaskMatch(t *testing.T) {
+	tests := []struct {
+		mask, txt        string
+		prefix, complete bool
+	}{
+		{TextFieldMaskDate, "", true, false},
+		{TextFieldMaskDate, "2018", true, false},
+		{TextFieldMaskDate, "2018-10-2", true, false},
+		{TextFieldMaskDate, "2018-10-21", true, true},
+		{TextFieldMaskDate, "2018/10/21", false, false},
+		{TextFieldMaskDate, "2018-10-211", false, false},
+		{TextFieldMaskIPv4, "192.168.0.1", true, true},
+		{TextFieldMaskIPv4, "10.0.0", true, false},
+		{TextFieldMaskIPv4, "1921.168.0.1", false, false},
+		{"aa-\\9*", "ab-9x", true, true},
+		{"aa-\\9*", "ab-8", false, false},
+	}
+	for _, ts := range tests {
+		prefix, complete := MaskMatch(ts.mask, ts.txt)
+		if prefix != ts.prefix || complete != ts.complete {
+			t.Errorf("MaskMatch(%q, %q) = %v, %v, want: %v, %v", ts.mask, ts.txt, prefix, complete, ts.prefix, ts.complete)
+		}
+	}
+}
+
+func TestMaskInsert(t *testing.T) {
+	tests := []struct {
+		mask, txt string
+		pos       int
+		ins, want string
+	}{
+		{TextFieldMaskDate, "", 0, "20181021", "2018-10-21"},
+		{TextFieldMaskDate, "2018", 4, "1", "-1"},
+		{TextFieldMaskDate, "2018", 4, "-", "-"},
+		{TextFieldMaskDate, "2018-", 5, "x-", ""},
+		{TextFieldMaskDate, "2018-10-21", 10, "1", ""},
+		{TextFieldMaskIPv4, "19", 2, ".", "."},
+		{TextFieldMaskIPv4, "192", 3, "1", ".1"},
+		{TextFieldMaskIPv4, "", 0, "10.0.0.1", "10.0.0.1"},
+		{TextFieldMaskTime, "", 0, "9:30", "93:0"},
+	}
+	for _, ts := range tests {
+		got := string(MaskInsert(ts.mask, []rune(ts.txt), ts.pos, []rune(ts.ins)))
+		if got != ts.want {
+			t.Errorf("MaskInsert(%q, %q, %v, %q) = %q, want: %q", ts.mask, ts.txt, ts.pos, ts.ins, got, ts.want)
+		}
+	}
+}
+
+func TestRegexpValidator(t *testing.T) {
+	vf, err := RegexpValidator(`\d+`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vf("123"); err != nil {
+		t.Errorf("RegexpValidator: 123 should be valid: %v", err)
+	}
+	if err := vf("12a"); err == nil {
+		t.Errorf("RegexpValidator: 12a should be invalid")
+	}
+	if _, err := RegexpValidator(`(`, ""); err == nil {
+		t.Errorf("RegexpValidator: invalid regexp should return an error")
+	}
+}
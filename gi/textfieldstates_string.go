@@ -9,9 +9,9 @@ import (
 
 var _ = errors.New("dummy error")
 
-const _TextFieldStates_name = "TextFieldActiveTextFieldFocusTextFieldInactiveTextFieldSelTextFieldStatesN"
+const _TextFieldStates_name = "TextFieldActiveTextFieldFocusTextFieldInactiveTextFieldSelTextFieldInvalidTextFieldStatesN"
 
-var _TextFieldStates_index = [...]uint8{0, 15, 29, 46, 58, 74}
+var _TextFieldStates_index = [...]uint8{0, 15, 29, 46, 58, 74, 90}
 
 func (i TextFieldStates) String() string {
 	if i < 0 || i >= TextFieldStates(len(_TextFieldStates_index)-1) {
@@ -9,9 +9,9 @@ import (
 
 var _ = errors.New("dummy error")
 
-const _UnicodeBidi_name = "BidiNormalBidiEmbedBidiBidiOverrideUnicodeBidiN"
+const _UnicodeBidi_name = "BidiNormalBidiEmbedBidiBidiOverrideBidiPlaintextUnicodeBidiN"
 
-var _UnicodeBidi_index = [...]uint8{0, 10, 19, 35, 47}
+var _UnicodeBidi_index = [...]uint8{0, 10, 19, 35, 48, 60}
 
 func (i UnicodeBidi) String() string {
 	if i < 0 || i >= UnicodeBidi(len(_UnicodeBidi_index)-1) {
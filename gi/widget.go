@@ -9,6 +9,7 @@ import (
 	"image"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/mouse"
@@ -26,12 +27,13 @@ import (
 // includes toggling selection on left mouse press.
 type WidgetBase struct {
 	Node2DBase
-	Tooltip      string       `desc:"text for tooltip for this widget -- can use HTML formatting"`
-	Sty          Style        `json:"-" xml:"-" desc:"styling settings for this widget -- set in SetStyle2D during an initialization step, and when the structure changes"`
-	DefStyle     *Style       `view:"-" json:"-" xml:"-" desc:"default style values computed by a parent widget for us -- if set, we are a part of a parent widget and should use these as our starting styles instead of type-based defaults"`
-	LayData      LayoutData   `json:"-" xml:"-" desc:"all the layout information for this item"`
-	WidgetSig    ki.Signal    `json:"-" xml:"-" view:"-" desc:"general widget signals supported by all widgets, including select, focus, and context menu (right mouse button) events, which can be used by views and other compound widgets"`
-	CtxtMenuFunc CtxtMenuFunc `view:"-" json:"-" xml:"-" desc:"optional context menu function called by MakeContextMenu AFTER any native items are added -- this function can decide where to insert new elements -- typically add a separator to disambiguate"`
+	Tooltip      string           `desc:"text for tooltip for this widget -- can use HTML formatting"`
+	Sty          Style            `json:"-" xml:"-" desc:"styling settings for this widget -- set in SetStyle2D during an initialization step, and when the structure changes"`
+	DefStyle     *Style           `view:"-" json:"-" xml:"-" desc:"default style values computed by a parent widget for us -- if set, we are a part of a parent widget and should use these as our starting styles instead of type-based defaults"`
+	LayData      LayoutData       `json:"-" xml:"-" desc:"all the layout information for this item"`
+	WidgetSig    ki.Signal        `json:"-" xml:"-" view:"-" desc:"general widget signals supported by all widgets, including select, focus, and context menu (right mouse button) events, which can be used by views and other compound widgets"`
+	CtxtMenuFunc CtxtMenuFunc     `view:"-" json:"-" xml:"-" desc:"optional context menu function called by MakeContextMenu AFTER any native items are added -- this function can decide where to insert new elements -- typically add a separator to disambiguate"`
+	StyTrans     StyleTransitions `view:"-" json:"-" xml:"-" desc:"state of the style transitions, for widgets with a transition style property -- see TransitionStyle"`
 }
 
 var KiT_WidgetBase = kit.Types.AddType(&WidgetBase{}, WidgetBaseProps)
@@ -214,6 +216,31 @@ func (wb *WidgetBase) StylePart(pk Node2D) {
 	}
 }
 
+// TransitionStyle applies the transitions of the style (transition style
+// property), after Sty has been set to the style for a new state (e.g.,
+// hover): properties that changed animate from their previous values, with
+// the widget updated at each step of the animation -- widgets with state
+// styles call this whenever they set Sty from them
+func (wb *WidgetBase) TransitionStyle() {
+	if len(wb.Sty.Transitions) == 0 && wb.StyTrans == nil {
+		return
+	}
+	if wb.StyTrans == nil {
+		wb.StyTrans = make(StyleTransitions)
+	}
+	win := wb.ParentWindow()
+	now := time.Now()
+	if win != nil {
+		now = win.Anims.Now()
+	}
+	end := wb.StyTrans.Apply(&wb.Sty, now)
+	if end > 0 && win != nil {
+		win.Anims.Start(&Anim{Recv: wb.This(), Name: "transition", Dur: end, Func: func(p float32) {
+			wb.UpdateSig()
+		}})
+	}
+}
+
 func (wb *WidgetBase) Style2D() {
 	wb.Style2DWidget()
 	wb.LayData.SetFromStyle(&wb.Sty.Layout) // also does reset
@@ -139,6 +139,7 @@ type Window struct {
 	StartFocus        ki.Ki                                   `json:"-" xml:"-" desc:"node to focus on at start when no other focus has been set yet -- use SetStartFocus"`
 	FocusMu           sync.RWMutex                            `json:"-" xml:"-" view:"-" desc:"mutex that protects focus updating"`
 	Shortcuts         Shortcuts                               `json:"-" xml:"-" desc:"currently active shortcuts for this window (shortcuts are always window-wide -- use widget key event processing for more local key functions)"`
+	KeySeq            key.Chord                               `json:"-" xml:"-" desc:"pending prefix of a multi-key sequence that has been typed so far -- shown in a tooltip until the sequence is completed"`
 	DNDData           mimedata.Mimes                          `json:"-" xml:"-" desc:"drag-n-drop data -- if non-nil, then DND is taking place"`
 	DNDSource         ki.Ki                                   `json:"-" xml:"-" desc:"drag-n-drop source node"`
 	DNDImage          ki.Ki                                   `json:"-" xml:"-" desc:"drag-n-drop node with image of source, that is actually dragged -- typically a Bitmap but can be anything (that renders in Overlay for 2D)"`
@@ -153,6 +154,7 @@ type Window struct {
 	DelPopup          ki.Ki                                   `json:"-" xml:"-" desc:"this popup will be popped at the end of the current event cycle -- use SetDelPopup"`
 	PopMu             sync.RWMutex                            `json:"-" xml:"-" view:"-" desc:"read-write mutex that protects popup updating and access"`
 	TimerMu           sync.Mutex                              `json:"-" xml:"-" view:"-" desc:"mutex that protects timer variable updates (e.g., hover AferFunc's)"`
+	Anims             Animator                                `json:"-" xml:"-" view:"-" desc:"animations running in this window -- stepped by the event loop at AnimFPS while any are running"`
 	lastWinMenuUpdate time.Time
 }
 
@@ -253,6 +255,7 @@ func NewWindow(name, title string, opts *oswin.NewWindowOptions) *Window {
 	win := &Window{}
 	win.InitName(win, name)
 	win.Title = title
+	win.Anims.Win = win
 	win.SetOnlySelfUpdate() // has its own PublishImage update logic
 	var err error
 	win.OSWin, err = oswin.TheApp.NewWindow(opts)
@@ -755,6 +758,21 @@ func (w *Window) SendCustomEvent(data interface{}) {
 	oswin.SendCustomEvent(w.OSWin, data)
 }
 
+// WinSync is a channel that is sent as the Data of a CustomEvent to
+// synchronize with the window event loop: it is closed by the EventLoop when
+// the event is reached, i.e., after all previously-sent events have been
+// processed.  It is not sent to any widgets.
+type WinSync chan struct{}
+
+// SendSync sends a WinSync event to this window, returning the channel that
+// will be closed when the event loop has processed all prior events --
+// mainly useful for scripted event injection in testing.
+func (w *Window) SendSync() WinSync {
+	ws := make(WinSync)
+	oswin.SendCustomEvent(w.OSWin, ws)
+	return ws
+}
+
 /////////////////////////////////////////////////////////////////////////////
 //                   Rendering
 
@@ -1226,6 +1244,16 @@ mainloop:
 			fmt.Printf("Win: %v got out-of-range event: %v\n", w.Nm, et)
 			continue
 		}
+		if ce, ok := evi.(*oswin.CustomEvent); ok {
+			if ws, ok := ce.Data.(WinSync); ok { // all prior events now processed
+				close(ws)
+				continue
+			}
+			if _, ok := ce.Data.(AnimTick); ok {
+				w.Anims.Step()
+				continue
+			}
+		}
 
 		{ // popup delete check
 			w.PopMu.RLock()
@@ -1560,6 +1588,7 @@ mainloop:
 				}
 			}
 		case *key.ChordEvent:
+			w.KeySeqEvent(e)
 			keyDelPop := w.KeyChordEventHiPri(e)
 			if keyDelPop {
 				delPop = true
@@ -1609,7 +1638,7 @@ mainloop:
 			cpop := w.CurPopup()
 			if cpop != nil && !delPop {
 				if PopupIsTooltip(cpop) {
-					if et != oswin.MouseMoveEvent {
+					if et != oswin.MouseMoveEvent && !(et == oswin.KeyChordEvent && w.KeySeq != "") {
 						delPop = true
 					}
 				} else if me, ok := evi.(*mouse.Event); ok {
@@ -1622,7 +1651,7 @@ mainloop:
 
 				if PopupIsCompleter(cpop) {
 					fsz := len(w.FocusStack)
-					if fsz > 0 && et == oswin.KeyChordEvent {
+					if fsz > 0 && (et == oswin.KeyChordEvent || et == oswin.IMEEvent) {
 						for pri := HiPri; pri < EventPrisN; pri++ {
 							w.EventSigs[et][pri].SendSig(w.FocusStack[fsz-1], cpop, int64(et), evi)
 						}
@@ -1995,12 +2024,14 @@ func (w *Window) SendKeyFunEvent(kf KeyFuns, popup bool) {
 	if chord == "" {
 		return
 	}
-	r, mods, err := chord.Decode()
+	seq, last := chord.SplitSeq()
+	r, mods, err := last.Decode()
 	if err != nil {
 		return
 	}
 	ke := key.ChordEvent{}
 	ke.SetTime()
+	ke.Seq = seq
 	ke.Modifiers = mods
 	ke.Rune = r
 	ke.Action = key.Press
@@ -2260,6 +2291,54 @@ func (w *Window) PopPopup(pop ki.Ki) bool {
 /////////////////////////////////////////////////////////////////////////////
 //                   Key Events Handled by Window
 
+// KeySeqEvent processes multi-key sequences for the given key chord event,
+// prior to any other processing: if the chord, after any pending KeySeq
+// prefix, is the prefix of a sequence bound in the active key maps (for the
+// context of the current focus, see KeyContexter) or in the Shortcuts, then
+// it is added to the pending prefix, which is shown in a tooltip, and the
+// event is marked as processed.  Otherwise, the event Seq is set to any
+// pending prefix, so that its Chord is the full sequence, and the prefix is
+// reset -- an unbound sequence is ignored.
+func (w *Window) KeySeqEvent(e *key.ChordEvent) {
+	ctxt := KeyContextGlobal
+	if kc, ok := w.CurFocus().(KeyContexter); ok {
+		ctxt = kc.KeyContext()
+	}
+	if ctxt == KeyContextRaw {
+		w.KeySeq = ""
+		return
+	}
+	seq := e.KeyChord()
+	if w.KeySeq != "" {
+		seq = w.KeySeq + key.ChordSeqSep + seq
+	}
+	bound := KeyFunContext(seq, ctxt) != KeyFunNil || w.Shortcuts[seq] != nil
+	if !bound && (KeySeqPrefix(seq, ctxt) || w.Shortcuts.HasPrefix(seq)) {
+		if KeyEventTrace {
+			fmt.Printf("Window KeySeq: %v pending\n", seq)
+		}
+		if cpop := w.CurPopup(); PopupIsTooltip(cpop) {
+			w.ClosePopup(cpop)
+		}
+		w.KeySeq = seq
+		e.SetProcessed()
+		vp := w.Viewport
+		PopupTooltip(seq.Shortcut()+" -", 0, vp.Geom.Size.Y, vp, "KeySeq")
+		return
+	}
+	if w.KeySeq == "" {
+		return
+	}
+	e.Seq = w.KeySeq
+	w.KeySeq = ""
+	if !bound {
+		if KeyEventTrace {
+			fmt.Printf("Window KeySeq: %v is not bound\n", seq)
+		}
+		e.SetProcessed()
+	}
+}
+
 // KeyChordEventHiPri handles all the high-priority window-specific key
 // events, returning its input on whether any existing popup should be deleted
 func (w *Window) KeyChordEventHiPri(e *key.ChordEvent) bool {
@@ -2825,9 +2904,24 @@ func (w *Window) DNDDropEvent(e *mouse.Event) {
 	w.SendEventSignal(&de, false) // popup = false: ignore any popups
 	w.DNDFinalEvent = &de
 	w.ClearDragNDrop()
+	if !de.IsProcessed() { // nobody took it: let source know if it wants
+		if ds, ok := de.Source.(DragNDropIgnorer); ok {
+			ds.DragNDropIgnored(&de)
+		}
+	}
 	e.SetProcessed()
 }
 
+// DragNDropIgnorer is an optional interface for drag-n-drop sources that
+// handle a drop that no target accepted -- e.g., TabView tears off the
+// dragged tab into a new window.  Other sources are not told about such
+// drops.
+type DragNDropIgnorer interface {
+	// DragNDropIgnored is called on the source with the drop event, after
+	// the drag-n-drop has been cleared, when no target processed the drop
+	DragNDropIgnored(de *dnd.Event)
+}
+
 // FinalizeDragNDrop is called by a node to finalize the drag-n-drop
 // operation, after given action has been performed on the target -- allows
 // target to cancel, by sending dnd.DropIgnore.
@@ -0,0 +1,27 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gi
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// NewXMLAttr returns an xml.Attr with given name and value
+func NewXMLAttr(name, val string) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: name}, Value: val}
+}
+
+// FmtFloat32 formats a float with the minimal precision needed to read it
+// back in, and without exponents, which are not supported in svg / css
+func FmtFloat32(val float32) string {
+	return strconv.FormatFloat(float64(val), 'f', -1, 32)
+}
+
+// FmtFloat64 formats a float with the minimal precision needed to read it
+// back in, and without exponents, which are not supported in svg / css
+func FmtFloat64(val float64) string {
+	return strconv.FormatFloat(val, 'f', -1, 64)
+}
@@ -8,6 +8,9 @@
 package gimain
 
 import (
+	"log"
+	"os"
+
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/giv"
 	"github.com/goki/gi/oswin"
@@ -20,9 +23,31 @@ var dummyGi gi.Vec2D
 var dummSvg svg.Line
 var dummyVV giv.ValueViewBase
 
+// Headless selects the offscreen headlessdriver instead of the standard
+// platform driver -- it is also selected if the GOGI_DRIVER environment
+// variable is set to "headless", e.g., for running in CI containers without
+// a display server.  The headlessdriver is only included in programs built
+// with the headless build tag (go build -tags headless).
+var Headless = false
+
+// headlessMain is the Main function of the headlessdriver, set if it is
+// included by the headless build tag
+var headlessMain func(f func(oswin.App))
+
+// Main runs the given main function under the oswin driver, which is the
+// platform driver unless Headless is selected.
 func Main(mainrun func()) {
 	DebugEnumSizes()
 
+	if Headless || os.Getenv("GOGI_DRIVER") == "headless" {
+		if headlessMain != nil {
+			headlessMain(func(app oswin.App) {
+				mainrun()
+			})
+			return
+		}
+		log.Printf("gimain.Main: headless driver not included -- build with -tags headless -- using platform driver\n")
+	}
 	driver.Main(func(app oswin.App) {
 		mainrun()
 	})
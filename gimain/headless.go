@@ -0,0 +1,23 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build headless
+
+package gimain
+
+import (
+	"github.com/goki/gi/oswin/driver/headlessdriver"
+)
+
+func init() {
+	headlessMain = headlessdriver.Main
+}
+
+// HeadlessMain runs the given main function under the headlessdriver,
+// regardless of the Headless setting -- only available with the headless
+// build tag.  Tests can also call headlessdriver.Main directly.
+func HeadlessMain(mainrun func()) {
+	Headless = true
+	Main(mainrun)
+}
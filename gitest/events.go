@@ -0,0 +1,285 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/oswin/window"
+)
+
+// Send initializes and sends the given event to the window event deque,
+// without waiting -- use Settle to wait for it to be processed.
+func (h *Harness) Send(ev oswin.Event) {
+	ev.Init()
+	h.Win.OSWin.Send(ev)
+}
+
+// SendSettle sends the given events in order and then waits for the window
+// to settle.
+func (h *Harness) SendSettle(evs ...oswin.Event) error {
+	for _, ev := range evs {
+		h.Send(ev)
+	}
+	return h.Settle()
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Mouse
+
+// MouseMove moves the mouse to given position (in window dots), which
+// generates mouse focus (enter / exit) events for widgets.
+func (h *Harness) MouseMove(pos image.Point, mods ...key.Modifiers) error {
+	ev := &mouse.MoveEvent{
+		Event: mouse.Event{
+			Where:  pos,
+			Button: mouse.NoButton,
+			Action: mouse.Move,
+		},
+		From: h.Win.LastMousePos,
+	}
+	ev.SetModifiers(mods...)
+	return h.SendSettle(ev)
+}
+
+// mouseEvent returns a new mouse.Event with given params
+func mouseEvent(pos image.Point, but mouse.Buttons, act mouse.Actions, mods ...key.Modifiers) *mouse.Event {
+	ev := &mouse.Event{
+		Where:  pos,
+		Button: but,
+		Action: act,
+	}
+	ev.SetModifiers(mods...)
+	return ev
+}
+
+// MousePress presses given mouse button at given position.
+func (h *Harness) MousePress(pos image.Point, but mouse.Buttons, mods ...key.Modifiers) error {
+	return h.SendSettle(mouseEvent(pos, but, mouse.Press, mods...))
+}
+
+// MouseRelease releases given mouse button at given position.
+func (h *Harness) MouseRelease(pos image.Point, but mouse.Buttons, mods ...key.Modifiers) error {
+	return h.SendSettle(mouseEvent(pos, but, mouse.Release, mods...))
+}
+
+// Click moves the mouse to given position and clicks (press, release) the
+// given button there.
+func (h *Harness) Click(pos image.Point, but mouse.Buttons, mods ...key.Modifiers) error {
+	if err := h.MouseMove(pos, mods...); err != nil {
+		return err
+	}
+	return h.SendSettle(mouseEvent(pos, but, mouse.Press, mods...),
+		mouseEvent(pos, but, mouse.Release, mods...))
+}
+
+// DoubleClick moves the mouse to given position and double-clicks the given
+// button there, with the same event sequence as generated by the drivers.
+func (h *Harness) DoubleClick(pos image.Point, but mouse.Buttons, mods ...key.Modifiers) error {
+	if err := h.Click(pos, but, mods...); err != nil {
+		return err
+	}
+	return h.SendSettle(mouseEvent(pos, but, mouse.DoubleClick, mods...),
+		mouseEvent(pos, but, mouse.Release, mods...))
+}
+
+// Scroll sends a scroll wheel event at given position, with given delta.
+func (h *Harness) Scroll(pos image.Point, delta image.Point, mods ...key.Modifiers) error {
+	ev := &mouse.ScrollEvent{
+		Event: mouse.Event{
+			Where:  pos,
+			Action: mouse.Scroll,
+		},
+		Delta: delta,
+	}
+	ev.SetModifiers(mods...)
+	return h.SendSettle(ev)
+}
+
+// dragEvent returns a new mouse.DragEvent with given params
+func dragEvent(from, to image.Point, but mouse.Buttons, mods ...key.Modifiers) *mouse.DragEvent {
+	ev := &mouse.DragEvent{
+		MoveEvent: mouse.MoveEvent{
+			Event: mouse.Event{
+				Where:  to,
+				Button: but,
+				Action: mouse.Drag,
+			},
+			From: from,
+		},
+	}
+	ev.SetModifiers(mods...)
+	return ev
+}
+
+// drag does the drag sequence, waiting startMSec after the initial drag
+// event so the window recognizes the start of the drag
+func (h *Harness) drag(from, to image.Point, steps, startMSec int, but mouse.Buttons, mods ...key.Modifiers) error {
+	if steps < 1 {
+		steps = 1
+	}
+	if err := h.MouseMove(from, mods...); err != nil {
+		return err
+	}
+	if err := h.MousePress(from, but, mods...); err != nil {
+		return err
+	}
+	if err := h.SendSettle(dragEvent(from, from, but, mods...)); err != nil {
+		return err
+	}
+	time.Sleep(time.Duration(startMSec+1) * time.Millisecond)
+	last := from
+	for i := 1; i <= steps; i++ {
+		pos := image.Point{
+			from.X + ((to.X-from.X)*i)/steps,
+			from.Y + ((to.Y-from.Y)*i)/steps,
+		}
+		// each event must be processed before the next is sent, so that
+		// the window does not skip lagging repeated drag events
+		if err := h.SendSettle(dragEvent(last, pos, but, mods...)); err != nil {
+			return err
+		}
+		last = pos
+	}
+	return h.MouseRelease(to, but, mods...)
+}
+
+// Drag does a regular mouse drag (e.g., for sliders, scrollbars, splitters)
+// with given button from one position to another, in given number of
+// intermediate steps.
+func (h *Harness) Drag(from, to image.Point, steps int, but mouse.Buttons, mods ...key.Modifiers) error {
+	return h.drag(from, to, steps, gi.DragStartMSec, but, mods...)
+}
+
+// DragNDrop does a drag-n-drop with the left mouse button from one position
+// to another, in given number of intermediate steps -- waits long enough and
+// moves far enough to trigger the window drag-n-drop logic.  Modifiers
+// determine the drop action as usual (e.g., copy vs. move).
+func (h *Harness) DragNDrop(from, to image.Point, steps int, mods ...key.Modifiers) error {
+	d := to.Sub(from)
+	if d.X*d.X+d.Y*d.Y < gi.DNDStartPix*gi.DNDStartPix {
+		return fmt.Errorf("gitest: DragNDrop: distance from %v to %v is less than DNDStartPix: %v", from, to, gi.DNDStartPix)
+	}
+	return h.drag(from, to, steps, gi.DNDStartMSec, mouse.Left, mods...)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Keyboard
+
+// KeyChord sends a key.ChordEvent for given rune and modifiers to the
+// widget in focus.
+func (h *Harness) KeyChord(r rune, mods ...key.Modifiers) error {
+	ev := &key.ChordEvent{}
+	ev.Rune = r
+	ev.Action = key.Press
+	ev.SetModifiers(mods...)
+	return h.SendSettle(ev)
+}
+
+// KeyCode sends a key.ChordEvent for given non-printing key code (e.g.,
+// key.CodeReturnEnter) and modifiers to the widget in focus.
+func (h *Harness) KeyCode(code key.Codes, mods ...key.Modifiers) error {
+	ev := &key.ChordEvent{}
+	ev.Rune = -1
+	ev.Code = code
+	ev.Action = key.Press
+	ev.SetModifiers(mods...)
+	return h.SendSettle(ev)
+}
+
+// KeyFun sends the key.ChordEvent for the chord bound to given key function
+// in the active key map.
+func (h *Harness) KeyFun(kf gi.KeyFuns) error {
+	chord := gi.ActiveKeyMap.ChordForFun(kf)
+	if chord == "" {
+		return fmt.Errorf("gitest: KeyFun: no chord in active key map for function: %v", kf)
+	}
+	return h.KeyChordString(chord)
+}
+
+// KeyChordString sends the key.ChordEvent for given chord string, e.g.,
+// "Control+S" or "Home" -- for a multi-key sequence, e.g., "Control+X,
+// Control+S", an event is sent for each chord in turn.
+func (h *Harness) KeyChordString(chord key.Chord) error {
+	for _, ch := range chord.Chords() {
+		mods, cs := key.ModsFmString(string(ch))
+		ev := &key.ChordEvent{}
+		if rs := []rune(cs); len(rs) == 1 {
+			ev.Rune = rs[0]
+		} else if code := keyCodeByName(cs); code != key.CodeUnknown {
+			ev.Rune = -1
+			ev.Code = code
+		} else {
+			return fmt.Errorf("gitest: could not decode key chord: %v: unknown key: %v", chord, cs)
+		}
+		ev.Modifiers = mods
+		ev.Action = key.Press
+		if err := h.SendSettle(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyCodeByName returns the key code with given name, without the "Code"
+// prefix, as used in key chords for non-printing keys, e.g., Home --
+// CodeUnknown if there is no such code
+func keyCodeByName(nm string) key.Codes {
+	for c := key.CodeA; c <= key.CodeRightGUI; c++ {
+		if strings.TrimPrefix(c.String(), "Code") == nm {
+			return c
+		}
+	}
+	if nm == "Compose" {
+		return key.CodeCompose
+	}
+	return key.CodeUnknown
+}
+
+// Type sends key.ChordEvent's for each rune in given string, to the widget
+// in focus -- e.g., for entering text in a TextField.
+func (h *Harness) Type(text string) error {
+	for _, r := range text {
+		ev := &key.ChordEvent{}
+		ev.Rune = r
+		ev.Action = key.Press
+		h.Send(ev)
+	}
+	return h.Settle()
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Window
+
+// WindowEvent sends a window.Event with given action -- note that the size
+// or position should be set on the OSWin first for Resize or Move actions.
+func (h *Harness) WindowEvent(act window.Actions) error {
+	ev := &window.Event{Action: act}
+	if act == window.Close {
+		h.Send(ev)
+		return nil
+	}
+	return h.SendSettle(ev)
+}
+
+// Resize resizes the window to given size in dots, waiting for the
+// resulting re-render to complete.
+func (h *Harness) Resize(sz image.Point) error {
+	h.Win.OSWin.SetSize(sz)
+	err := h.waitFor("resize", func() bool {
+		return h.Win.OSWin.Size() == sz
+	})
+	if err != nil {
+		return err
+	}
+	return h.WindowEvent(window.Resize)
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"fmt"
+	"image"
+	"reflect"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki"
+)
+
+// roots returns the roots of all the trees to search for widgets: the
+// window itself, followed by any popups, top-most first
+func (h *Harness) roots() []ki.Ki {
+	w := h.Win
+	rts := make([]ki.Ki, 0, 4)
+	w.PopMu.RLock()
+	if w.Popup != nil {
+		rts = append(rts, w.Popup)
+	}
+	for i := len(w.PopupStack) - 1; i >= 0; i-- {
+		if w.PopupStack[i] != w.Popup {
+			rts = append(rts, w.PopupStack[i])
+		}
+	}
+	w.PopMu.RUnlock()
+	rts = append(rts, w.This())
+	return rts
+}
+
+// FindName returns the first node with given name in the window or any of
+// its popups (searching the popups first), or nil if not found.
+func (h *Harness) FindName(name string) ki.Ki {
+	var fk ki.Ki
+	for _, rt := range h.roots() {
+		rt.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+			if fk != nil {
+				return false
+			}
+			if k.Name() == name {
+				fk = k
+				return false
+			}
+			return true
+		})
+		if fk != nil {
+			return fk
+		}
+	}
+	return nil
+}
+
+// FindPath returns the node at given path, which is as returned by
+// PathUnique, and can be given either relative to the window or a popup
+// viewport, or as a full path starting with the window -- returns nil if
+// not found.
+func (h *Harness) FindPath(path string) ki.Ki {
+	path = strings.TrimPrefix(path, "/")
+	for _, rt := range h.roots() {
+		if k, ok := rt.FindPathUnique(path); ok {
+			return k
+		}
+		rtp := strings.TrimPrefix(rt.PathUnique(), "/")
+		if strings.HasPrefix(path, rtp+"/") {
+			if k, ok := rt.FindPathUnique(strings.TrimPrefix(path, rtp+"/")); ok {
+				return k
+			}
+		}
+	}
+	return nil
+}
+
+// FindType returns all the nodes of given type (or embedding it) in the
+// window and its popups -- e.g., gi.KiT_Button.
+func (h *Harness) FindType(typ reflect.Type) []ki.Ki {
+	var fks []ki.Ki
+	for _, rt := range h.roots() {
+		rt.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+			if k.TypeEmbeds(typ) {
+				fks = append(fks, k)
+			}
+			return true
+		})
+	}
+	return fks
+}
+
+// WinBBox returns the window bounding box of given node, which must be a
+// 2D node that has been rendered.
+func WinBBox(k ki.Ki) (image.Rectangle, error) {
+	if k == nil {
+		return image.ZR, fmt.Errorf("gitest: nil node")
+	}
+	_, ni := gi.KiToNode2D(k)
+	if ni == nil {
+		return image.ZR, fmt.Errorf("gitest: node %v is not a 2D node", k.PathUnique())
+	}
+	if ni.WinBBox.Empty() {
+		return image.ZR, fmt.Errorf("gitest: node %v has an empty WinBBox -- not visible?", k.PathUnique())
+	}
+	return ni.WinBBox, nil
+}
+
+// Center returns the center of the window bounding box of given node.
+func Center(k ki.Ki) (image.Point, error) {
+	bb, err := WinBBox(k)
+	if err != nil {
+		return image.ZP, err
+	}
+	return image.Point{(bb.Min.X + bb.Max.X) / 2, (bb.Min.Y + bb.Max.Y) / 2}, nil
+}
+
+// ClickOn clicks the left mouse button in the center of given node.
+func (h *Harness) ClickOn(k ki.Ki, mods ...key.Modifiers) error {
+	pos, err := Center(k)
+	if err != nil {
+		return err
+	}
+	return h.Click(pos, mouse.Left, mods...)
+}
+
+// ClickOnName clicks the left mouse button in the center of the node with
+// given name.
+func (h *Harness) ClickOnName(name string, mods ...key.Modifiers) error {
+	k := h.FindName(name)
+	if k == nil {
+		return fmt.Errorf("gitest: ClickOnName: node named %v not found", name)
+	}
+	return h.ClickOn(k, mods...)
+}
+
+// DragNDropOnto drag-n-drops from the center of the src node to the center
+// of the dst node -- e.g., for TreeView nodes.
+func (h *Harness) DragNDropOnto(src, dst ki.Ki, mods ...key.Modifiers) error {
+	from, err := Center(src)
+	if err != nil {
+		return err
+	}
+	to, err := Center(dst)
+	if err != nil {
+		return err
+	}
+	return h.DragNDrop(from, to, 4, mods...)
+}
+
+// Focus returns the node that currently has the keyboard focus
+func (h *Harness) Focus() ki.Ki {
+	return h.Win.CurFocus()
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gitest provides a harness for scripted, automated testing of
+// gi.Window GUIs: it injects synthetic mouse, key and window events into the
+// window event deque, waits for the event loop and rendering to settle, and
+// finds widgets by path or name so they can be clicked, dragged, typed into
+// and inspected.
+//
+// It also supports golden-image snapshot testing: Render renders any Node2D
+// subtree into an offscreen Viewport2D at a fixed DPI, and AssertGolden
+// compares rendered images with stored PNG golden images, within a
+// perceptual Tolerance -- run the tests with GOGI_UPDATE_GOLDENS=1 set in
+// the environment, or with -update-goldens if they call RegisterFlags, to
+// (re)create the golden images.
+//
+// It works with any oswin driver, but is typically used with the
+// oswin/driver/headlessdriver so tests can run without a display, e.g.:
+//
+//	func TestMain(m *testing.M) {
+//		gitest.RegisterFlags()
+//		headlessdriver.Main(func(app oswin.App) {
+//			os.Exit(m.Run())
+//		})
+//	}
+//
+//	func TestButton(t *testing.T) {
+//		win := gi.NewWindow2D("test", "Test", 400, 300, true)
+//		... configure window ...
+//		h := gitest.NewHarness(win)
+//		if err := h.Start(); err != nil {
+//			t.Fatal(err)
+//		}
+//		defer h.Close()
+//		h.ClickOn(h.FindName("my-button"))
+//		...
+//	}
+package gitest
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"time"
+
+	"github.com/goki/gi/gi"
+)
+
+// DefaultTimeout is the default Harness.Timeout for waiting for the window
+// to settle.
+var DefaultTimeout = 5 * time.Second
+
+// SettlePollMSec is the number of milliseconds between checks for pending
+// updates when waiting for the window to settle.
+var SettlePollMSec = 5
+
+// Harness drives a gi.Window through scripted events -- all event methods
+// wait for the window to settle after sending their events, so the effects
+// of the events can be checked immediately afterward.
+type Harness struct {
+	Win     *gi.Window    `desc:"the window being driven"`
+	Timeout time.Duration `desc:"maximum time to wait for the window to settle -- an error is returned if exceeded"`
+}
+
+// NewHarness returns a new Harness for given window -- call Start to start
+// its event loop, if not already running.
+func NewHarness(win *gi.Window) *Harness {
+	return &Harness{Win: win, Timeout: DefaultTimeout}
+}
+
+// Start starts the window event loop in a separate goroutine, and waits
+// until the window has been fully rendered and shown.
+func (h *Harness) Start() error {
+	h.Win.GoStartEventLoop()
+	err := h.waitFor("window show", func() bool {
+		return h.Win.HasFlag(int(gi.WinFlagSentShow))
+	})
+	if err != nil {
+		return err
+	}
+	return h.Settle()
+}
+
+// Close closes the window and waits for it to be closed.
+func (h *Harness) Close() error {
+	h.Win.Close()
+	return h.waitFor("window close", func() bool {
+		return h.Win.IsClosed()
+	})
+}
+
+// Settle waits until all events sent to the window so far have been
+// processed, and there are no pending updates (UpdateStart without
+// UpdateEnd) on the window or its viewport.  Returns an error if that
+// does not happen within Timeout.
+func (h *Harness) Settle() error {
+	if !h.Win.IsVisible() {
+		return fmt.Errorf("gitest: Settle: window %v is not visible", h.Win.Nm)
+	}
+	select {
+	case <-h.Win.SendSync():
+	case <-time.After(h.Timeout):
+		return fmt.Errorf("gitest: Settle: timeout waiting for event loop of window %v", h.Win.Nm)
+	}
+	return h.waitFor("updates", func() bool {
+		if h.Win.IsWinUpdating() || h.Win.IsUpdating() {
+			return false
+		}
+		vp := h.Win.Viewport
+		return vp == nil || !vp.IsUpdating()
+	})
+}
+
+// waitFor polls the given test function until it returns true, returning
+// an error naming what we were waiting for if it takes longer than Timeout.
+func (h *Harness) waitFor(what string, test func() bool) error {
+	deadline := time.Now().Add(h.Timeout)
+	for !test() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("gitest: timeout waiting for %v on window %v", what, h.Win.Nm)
+		}
+		time.Sleep(time.Duration(SettlePollMSec) * time.Millisecond)
+	}
+	return nil
+}
+
+// Capture returns a copy of the current window image -- if the oswin driver
+// supports capturing published window contents (e.g., headlessdriver) then
+// that is used, otherwise the image of the main window viewport is returned.
+func (h *Harness) Capture() *image.RGBA {
+	if cw, ok := h.Win.OSWin.(interface{ Capture() *image.RGBA }); ok {
+		if img := cw.Capture(); img != nil {
+			return img
+		}
+	}
+	vp := h.Win.Viewport
+	if vp == nil || vp.Pixels == nil {
+		return nil
+	}
+	img := image.NewRGBA(vp.Pixels.Bounds())
+	draw.Draw(img, img.Bounds(), vp.Pixels, vp.Pixels.Bounds().Min, draw.Src)
+	return img
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/headlessdriver"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/ki"
+)
+
+func TestMain(m *testing.M) {
+	headlessdriver.Main(func(app oswin.App) {
+		os.Exit(m.Run())
+	})
+}
+
+// formWin makes a window with a button named "but", whose clicks are
+// counted in clicks, and a text field named "tf", and starts a harness for it
+func formWin(t *testing.T, name string, clicks *int) *Harness {
+	win := gi.NewWindow2D(name, name, 400, 300, true)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	but := mfr.AddNewChild(gi.KiT_Button, "but").(*gi.Button)
+	but.SetText("Click")
+	but.ButtonSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonClicked) {
+			*clicks++
+		}
+	})
+	mfr.AddNewChild(gi.KiT_TextField, "tf")
+	vp.UpdateEndNoSig(updt)
+	h := NewHarness(win)
+	if err := h.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestFind(t *testing.T) {
+	clicks := 0
+	h := formWin(t, "test-gitest-find", &clicks)
+	defer h.Close()
+
+	but := h.FindName("but")
+	if but == nil || but.Embed(gi.KiT_Button) == nil {
+		t.Fatalf("FindName: %v", but)
+	}
+	if k := h.FindPath(but.PathUnique()); k != but {
+		t.Errorf("FindPath full path: %v", k)
+	}
+	rel := but.PathUnique()[len(h.Win.PathUnique())+1:]
+	if k := h.FindPath(rel); k != but {
+		t.Errorf("FindPath relative path %v: %v", rel, k)
+	}
+	if h.FindName("nothing") != nil || h.FindPath("nothing/here") != nil {
+		t.Errorf("found missing node")
+	}
+	if bs := h.FindType(gi.KiT_Button); len(bs) != 1 || bs[0] != but {
+		t.Errorf("FindType buttons: %v", bs)
+	}
+	if tfs := h.FindType(gi.KiT_TextField); len(tfs) != 1 || tfs[0].Name() != "tf" {
+		t.Errorf("FindType text fields: %v", tfs)
+	}
+	bb, err := WinBBox(but)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := Center(but); !c.In(bb) {
+		t.Errorf("Center %v not in WinBBox %v", c, bb)
+	}
+	if _, err := WinBBox(nil); err == nil {
+		t.Errorf("WinBBox of nil node should fail")
+	}
+}
+
+func TestEvents(t *testing.T) {
+	clicks := 0
+	h := formWin(t, "test-gitest-events", &clicks)
+	defer h.Close()
+
+	if err := h.ClickOnName("but"); err != nil {
+		t.Fatal(err)
+	}
+	if clicks != 1 {
+		t.Errorf("button clicks: %v", clicks)
+	}
+	pos, _ := Center(h.FindName("but"))
+	if h.Win.LastMousePos != pos {
+		t.Errorf("mouse pos: %v, want: %v", h.Win.LastMousePos, pos)
+	}
+	if err := h.ClickOnName("nothing"); err == nil {
+		t.Errorf("click on missing node should fail")
+	}
+
+	tf := h.FindName("tf").Embed(gi.KiT_TextField).(*gi.TextField)
+	if err := h.ClickOn(tf); err != nil {
+		t.Fatal(err)
+	}
+	if h.Focus() != tf.This() {
+		t.Errorf("focus: %v", h.Focus())
+	}
+	if err := h.Type("abc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.KeyCode(key.CodeDeleteBackspace); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.KeyFun(gi.KeyFunHome); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.KeyChord('x'); err != nil {
+		t.Fatal(err)
+	}
+	if txt := string(tf.EditTxt); txt != "xab" {
+		t.Errorf("typed text: %q", txt)
+	}
+}
+
+func TestSendSync(t *testing.T) {
+	clicks := 0
+	h := formWin(t, "test-gitest-sync", &clicks)
+	defer h.Close()
+
+	tf := h.FindName("tf").Embed(gi.KiT_TextField).(*gi.TextField)
+	if err := h.ClickOn(tf); err != nil {
+		t.Fatal(err)
+	}
+	// events sent without waiting are all processed, in order, by the time
+	// the sync channel is closed
+	for _, r := range "hello" {
+		ev := &key.ChordEvent{}
+		ev.Rune = r
+		ev.Action = key.Press
+		h.Send(ev)
+	}
+	select {
+	case <-h.Win.SendSync():
+	case <-time.After(h.Timeout):
+		t.Fatalf("SendSync channel not closed")
+	}
+	if txt := string(tf.EditTxt); txt != "hello" {
+		t.Errorf("text after sync: %q", txt)
+	}
+}
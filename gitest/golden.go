@@ -0,0 +1,341 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/headlessdriver"
+)
+
+// golden contains support for golden-image snapshot testing: rendering a
+// Node2D subtree into an offscreen Viewport2D, and comparing the result with
+// a stored PNG golden image within a perceptual tolerance.
+
+// GoldenDir is the directory where golden images are stored, relative to
+// the package directory in which the tests run.
+var GoldenDir = "testdata"
+
+// GoldenFailDir is the directory where the rendered image and diff image
+// are saved when a comparison with a golden image fails.
+var GoldenFailDir = filepath.Join("testdata", "failed")
+
+// UpdateGoldens causes the golden images to be (re)written from the
+// rendered images, instead of being compared -- it is set by setting the
+// GOGI_UPDATE_GOLDENS environment variable to a non-empty value, or by the
+// -update-goldens test flag if the tests call RegisterFlags.
+var UpdateGoldens = os.Getenv("GOGI_UPDATE_GOLDENS") != ""
+
+// RegisterFlags registers the -update-goldens flag, which sets
+// UpdateGoldens, on the default flag set -- call it from TestMain before
+// m.Run (which parses the flags) or from an init function of the test
+// package -- it does nothing if the flag is already registered.
+func RegisterFlags() {
+	if flag.Lookup("update-goldens") != nil {
+		return
+	}
+	flag.BoolVar(&UpdateGoldens, "update-goldens", UpdateGoldens, "update golden images from rendered images instead of comparing against them")
+}
+
+// Tolerance specifies how different a rendered image can be from its
+// golden image and still be considered matching -- small differences in
+// anti-aliasing and font rasterization are common across platforms.
+type Tolerance struct {
+	PixelDelta float32 `desc:"perceptual color difference (0-1) above which a pixel is counted as different -- 0 = any difference counts"`
+	MaxDiffPct float32 `desc:"maximum percent (0-100) of pixels that can be different"`
+}
+
+// DefaultTolerance is the tolerance used when nil is passed -- ignores
+// barely visible differences, and allows a tiny fraction of pixels to
+// differ, e.g., at the anti-aliased edges of text.
+var DefaultTolerance = Tolerance{PixelDelta: 0.1, MaxDiffPct: 0.1}
+
+// DiffReport reports the differences between a rendered image and its
+// golden image.
+type DiffReport struct {
+	Size      image.Point `desc:"size of the golden image"`
+	GotSize   image.Point `desc:"size of the rendered image -- if different from Size, no pixels are compared"`
+	NPixels   int         `desc:"total number of pixels compared"`
+	NDiff     int         `desc:"number of pixels whose perceptual difference exceeds Tolerance.PixelDelta"`
+	DiffPct   float32     `desc:"percent of pixels compared that are different"`
+	MaxDelta  float32     `desc:"maximum perceptual difference (0-1) over all pixels"`
+	MeanDelta float32     `desc:"mean perceptual difference (0-1) over all pixels"`
+	Tol       Tolerance   `desc:"tolerance used for the comparison"`
+}
+
+// SizeMismatch returns true if the rendered and golden images are of
+// different sizes.
+func (dr *DiffReport) SizeMismatch() bool {
+	return dr.Size != dr.GotSize
+}
+
+// OK returns true if the rendered image is within tolerance of the golden
+// image.
+func (dr *DiffReport) OK() bool {
+	if dr.SizeMismatch() {
+		return false
+	}
+	return dr.DiffPct <= dr.Tol.MaxDiffPct
+}
+
+func (dr *DiffReport) String() string {
+	if dr.SizeMismatch() {
+		return fmt.Sprintf("image size mismatch: golden: %v rendered: %v", dr.Size, dr.GotSize)
+	}
+	return fmt.Sprintf("%v of %v pixels differ (%.4g%%, max allowed: %.4g%%), max delta: %.4g, mean delta: %.4g (pixel threshold: %.4g)", dr.NDiff, dr.NPixels, dr.DiffPct, dr.Tol.MaxDiffPct, dr.MaxDelta, dr.MeanDelta, dr.Tol.PixelDelta)
+}
+
+// maxYIQDelta is the YIQ perceptual delta between black and white
+const maxYIQDelta = 35215.0
+
+// PerceptualDelta returns the perceptual difference between two colors,
+// normalized to 0-1 range, using the weighted YIQ color space distance
+// metric of Kotsarenko & Ramos, 2010 -- this weights differences in
+// brightness more than in hue, as does the eye.  Colors are blended onto a
+// white background first, so that differences in fully-transparent colors
+// do not count.
+func PerceptualDelta(a, b color.Color) float32 {
+	ar, ag, ab := blendWhite(a)
+	br, bg, bb := blendWhite(b)
+	if ar == br && ag == bg && ab == bb {
+		return 0
+	}
+	y := rgbToY(ar, ag, ab) - rgbToY(br, bg, bb)
+	i := rgbToI(ar, ag, ab) - rgbToI(br, bg, bb)
+	q := rgbToQ(ar, ag, ab) - rgbToQ(br, bg, bb)
+	d := 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+	return float32(math.Sqrt(d / maxYIQDelta))
+}
+
+// blendWhite returns the 0-255 r,g,b components of the color blended onto
+// a white background
+func blendWhite(c color.Color) (r, g, b float64) {
+	cr, cg, cb, ca := c.RGBA() // alpha-premultiplied
+	wt := 0xffff - float64(ca)
+	r = (float64(cr) + wt) / 257
+	g = (float64(cg) + wt) / 257
+	b = (float64(cb) + wt) / 257
+	return
+}
+
+func rgbToY(r, g, b float64) float64 {
+	return r*0.29889531 + g*0.58662247 + b*0.11448223
+}
+
+func rgbToI(r, g, b float64) float64 {
+	return r*0.59597799 - g*0.27417610 - b*0.32180189
+}
+
+func rgbToQ(r, g, b float64) float64 {
+	return r*0.21147017 - g*0.52261711 + b*0.31114694
+}
+
+// DiffImages compares the rendered (got) image with the golden (want)
+// image, within given tolerance (DefaultTolerance if nil), returning a
+// report of the differences, and a diff image showing the golden image
+// faded to light gray, with pixels that are different in red, with an
+// intensity proportional to the amount of difference -- the diff image is
+// nil if the images are of different sizes.
+func DiffImages(want, got image.Image, tol *Tolerance) (*DiffReport, *image.RGBA) {
+	if tol == nil {
+		tol = &DefaultTolerance
+	}
+	wb := want.Bounds()
+	gb := got.Bounds()
+	dr := &DiffReport{Size: wb.Size(), GotSize: gb.Size(), Tol: *tol}
+	if dr.SizeMismatch() {
+		return dr, nil
+	}
+	diff := image.NewRGBA(image.Rectangle{Max: dr.Size})
+	sum := float64(0)
+	for y := 0; y < dr.Size.Y; y++ {
+		for x := 0; x < dr.Size.X; x++ {
+			wc := want.At(wb.Min.X+x, wb.Min.Y+y)
+			d := PerceptualDelta(wc, got.At(gb.Min.X+x, gb.Min.Y+y))
+			sum += float64(d)
+			if d > dr.MaxDelta {
+				dr.MaxDelta = d
+			}
+			if d > tol.PixelDelta {
+				dr.NDiff++
+				rd := uint8(128 + 127*d)
+				diff.SetRGBA(x, y, color.RGBA{rd, 0, 0, 0xff})
+				continue
+			}
+			r, g, b := blendWhite(wc)
+			gy := uint8(255 - 0.1*(255-rgbToY(r, g, b))) // faded golden
+			diff.SetRGBA(x, y, color.RGBA{gy, gy, gy, 0xff})
+		}
+	}
+	dr.NPixels = dr.Size.X * dr.Size.Y
+	if dr.NPixels > 0 {
+		dr.DiffPct = 100 * float32(dr.NDiff) / float32(dr.NPixels)
+		dr.MeanDelta = float32(sum / float64(dr.NPixels))
+	}
+	return dr, diff
+}
+
+// CompareGolden compares the given rendered image with the golden image
+// named name (GoldenDir/name.png), within given tolerance (DefaultTolerance
+// if nil) -- returns the report of the differences (nil if the golden was
+// not compared) and an error if the images do not match, or if the golden
+// image could not be read.  If they do not match, the rendered image and
+// diff image are saved in GoldenFailDir as name.png and name_diff.png.  If
+// UpdateGoldens is set, the rendered image is instead saved as the new
+// golden image.
+func CompareGolden(name string, img image.Image, tol *Tolerance) (*DiffReport, error) {
+	fnm := filepath.Join(GoldenDir, name+".png")
+	if UpdateGoldens {
+		if err := os.MkdirAll(filepath.Dir(fnm), 0755); err != nil {
+			return nil, err
+		}
+		if err := gi.SavePNG(fnm, img); err != nil {
+			return nil, fmt.Errorf("gitest: could not save golden image %v: %v", fnm, err)
+		}
+		return nil, nil
+	}
+	want, err := gi.OpenPNG(fnm)
+	if err != nil {
+		return nil, fmt.Errorf("gitest: could not open golden image %v: %v -- set GOGI_UPDATE_GOLDENS=1 to create", fnm, err)
+	}
+	dr, diff := DiffImages(want, img, tol)
+	if dr.OK() {
+		return dr, nil
+	}
+	gnm := filepath.Join(GoldenFailDir, name+".png")
+	dnm := filepath.Join(GoldenFailDir, name+"_diff.png")
+	if err := os.MkdirAll(filepath.Dir(gnm), 0755); err == nil {
+		gi.SavePNG(gnm, img)
+		if diff != nil {
+			gi.SavePNG(dnm, diff)
+		}
+	}
+	return dr, fmt.Errorf("gitest: rendered image does not match golden %v: %v -- see %v and %v", fnm, dr, gnm, dnm)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Offscreen rendering
+
+// ensureApp makes sure there is an oswin.App to create images with,
+// starting the headlessdriver if no driver is running -- this allows
+// offscreen rendering directly from tests, without a TestMain.
+func ensureApp() {
+	if oswin.TheApp == nil {
+		headlessdriver.Main(func(app oswin.App) {})
+	}
+}
+
+// NewRenderViewport returns a new offscreen Viewport2D of given size, not
+// associated with any window, which fills its background -- add nodes to
+// it and call Render on it to render them.  As there is no window, the
+// viewport always renders at the fixed default DPI of units.PxPerInch, so
+// rendering does not depend on the display of the machine.
+func NewRenderViewport(width, height int) *gi.Viewport2D {
+	ensureApp()
+	vp := gi.NewViewport2D(width, height)
+	vp.InitName(vp, "gitest-render")
+	vp.Fill = true
+	return vp
+}
+
+// Grab returns a copy of the current rendered image of given node, which
+// is the region of its parent viewport within its bounding box, or the
+// entire image of a viewport -- returns nil if it has not been rendered.
+func Grab(nii gi.Node2D) *image.RGBA {
+	ensureApp()
+	bm := &gi.Bitmap{}
+	bm.InitName(bm, "gitest-grab")
+	if !bm.GrabRenderFrom(nii) {
+		return nil
+	}
+	img := image.NewRGBA(bm.Pixels.Bounds())
+	draw.Draw(img, img.Bounds(), bm.Pixels, bm.Pixels.Bounds().Min, draw.Src)
+	bm.Destroy()
+	return img
+}
+
+// Render does a full render of given node into an offscreen viewport of
+// given size, and returns a copy of the resulting image.  If the node is a
+// Viewport2D without a parent (e.g., an svg.SVG or a viewport from
+// NewRenderViewport) it is rendered directly (resized to size unless it is
+// zero), otherwise it must not have a parent, and it is temporarily added
+// to a new viewport from NewRenderViewport for rendering.
+func Render(nii gi.Node2D, size image.Point) (*image.RGBA, error) {
+	ensureApp()
+	if nii.Parent() == nil {
+		if vp := nii.AsViewport2D(); vp != nil {
+			if size != image.ZP {
+				vp.Resize(size)
+			}
+			vp.FullRender2DTree()
+			return Grab(vp), nil
+		}
+	} else {
+		return nil, fmt.Errorf("gitest: Render: node %v must not have a parent", nii.PathUnique())
+	}
+	if size.X <= 0 || size.Y <= 0 {
+		return nil, fmt.Errorf("gitest: Render: invalid size %v for node %v", size, nii.Name())
+	}
+	vp := NewRenderViewport(size.X, size.Y)
+	vp.AddChild(nii)
+	vp.FullRender2DTree()
+	img := Grab(vp)
+	vp.DeleteChild(nii, false) // caller still owns the node
+	vp.Destroy()
+	return img, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//   Test helpers
+
+// AssertGolden compares the given rendered image with the golden image
+// named name, as in CompareGolden, and reports an error on t if they do not
+// match -- tol is DefaultTolerance if nil.
+func AssertGolden(t testing.TB, name string, img image.Image, tol *Tolerance) {
+	t.Helper()
+	if img == nil {
+		t.Errorf("gitest: nil image for golden %v", name)
+		return
+	}
+	if _, err := CompareGolden(name, img, tol); err != nil {
+		t.Error(err)
+	}
+}
+
+// AssertRenderGolden renders given node at given size, as in Render, and
+// compares the result with the golden image named name, as in
+// CompareGolden, reporting any error on t -- tol is DefaultTolerance if
+// nil.
+func AssertRenderGolden(t testing.TB, name string, nii gi.Node2D, size image.Point, tol *Tolerance) {
+	t.Helper()
+	img, err := Render(nii, size)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	AssertGolden(t, name, img, tol)
+}
+
+// AssertWindowGolden compares the current window image, as returned by
+// Capture, with the golden image named name, as in CompareGolden,
+// reporting any error on t -- tol is DefaultTolerance if nil.
+func (h *Harness) AssertWindowGolden(t testing.TB, name string, tol *Tolerance) {
+	t.Helper()
+	if err := h.Settle(); err != nil {
+		t.Error(err)
+		return
+	}
+	AssertGolden(t, name, h.Capture(), tol)
+}
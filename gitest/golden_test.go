@@ -0,0 +1,162 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gitest
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/draw"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/svg"
+)
+
+func TestPerceptualDelta(t *testing.T) {
+	if d := PerceptualDelta(color.White, color.White); d != 0 {
+		t.Errorf("same color delta: %v != 0", d)
+	}
+	if d := PerceptualDelta(color.Black, color.White); d < 0.9 || d > 1 {
+		t.Errorf("black vs. white delta: %v not within [0.9, 1]", d)
+	}
+	if d := PerceptualDelta(color.Transparent, color.White); d != 0 {
+		t.Errorf("transparent vs. white delta: %v != 0", d)
+	}
+	gray := color.RGBA{0x80, 0x80, 0x80, 0xff}
+	ngray := color.RGBA{0x82, 0x80, 0x80, 0xff}
+	if d := PerceptualDelta(gray, ngray); d == 0 || d > DefaultTolerance.PixelDelta {
+		t.Errorf("near gray delta: %v not within (0, %v]", d, DefaultTolerance.PixelDelta)
+	}
+}
+
+func TestDiffImages(t *testing.T) {
+	want := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	draw.Draw(want, want.Bounds(), image.White, image.ZP, draw.Src)
+	got := image.NewRGBA(want.Bounds())
+	draw.Draw(got, got.Bounds(), image.White, image.ZP, draw.Src)
+
+	dr, diff := DiffImages(want, got, nil)
+	if !dr.OK() || dr.NDiff != 0 || dr.MaxDelta != 0 {
+		t.Errorf("identical images: %v", dr)
+	}
+	if diff == nil || diff.Bounds() != want.Bounds() {
+		t.Errorf("diff image bounds wrong: %v", diff)
+	}
+
+	got.Set(3, 4, color.Black)
+	dr, diff = DiffImages(want, got, nil)
+	if dr.OK() || dr.NDiff != 1 || dr.NPixels != 200 {
+		t.Errorf("one black pixel: %v", dr)
+	}
+	if r, g, _, _ := diff.At(3, 4).RGBA(); r < 0x8000 || g != 0 {
+		t.Errorf("diff pixel is not red: %v", diff.At(3, 4))
+	}
+	dr, _ = DiffImages(want, got, &Tolerance{PixelDelta: 0.1, MaxDiffPct: 1})
+	if !dr.OK() {
+		t.Errorf("one black pixel within 1%% tolerance: %v", dr)
+	}
+
+	dr, diff = DiffImages(want, image.NewRGBA(image.Rect(0, 0, 10, 10)), nil)
+	if dr.OK() || !dr.SizeMismatch() || diff != nil {
+		t.Errorf("size mismatch not detected: %v", dr)
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	RegisterFlags()
+	RegisterFlags() // must not panic
+	if flag.Lookup("update-goldens") == nil {
+		t.Errorf("update-goldens flag not registered")
+	}
+}
+
+// rectsSVG returns a 20x10 svg with a red rect on the left half and a blue
+// one on the right, as in the testdata/svg-rects.png golden image
+func rectsSVG() *svg.SVG {
+	sv := &svg.SVG{}
+	sv.InitName(sv, "rects")
+	sv.ViewBox.Size = gi.Vec2D{20, 10}
+	for i, clr := range []string{"red", "blue"} {
+		r := sv.AddNewChild(svg.KiT_Rect, clr).(*svg.Rect)
+		r.Pos = gi.Vec2D{float32(i * 10), 0}
+		r.Size = gi.Vec2D{10, 10}
+		r.SetProp("fill", clr)
+		r.SetProp("stroke", "none")
+	}
+	return sv
+}
+
+// failDir sets GoldenFailDir to a temporary directory for the test,
+// returning a function that removes it and restores GoldenFailDir
+func failDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "gitest-failed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv := GoldenFailDir
+	GoldenFailDir = dir
+	return func() {
+		GoldenFailDir = sv
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	if UpdateGoldens {
+		t.Skip("updating golden images")
+	}
+	defer failDir(t)()
+	img, err := Render(rectsSVG(), image.Point{20, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Size() != (image.Point{20, 10}) {
+		t.Fatalf("rendered size: %v", img.Bounds())
+	}
+	if dr, err := CompareGolden("svg-rects", img, &Tolerance{}); err != nil || dr.NDiff != 0 {
+		t.Errorf("rendered svg: %v %v", dr, err)
+	}
+	AssertRenderGolden(t, "svg-rects", rectsSVG(), image.Point{20, 10}, nil)
+
+	if _, err := Render(rectsSVG(), image.ZP); err != nil {
+		t.Errorf("render at own size: %v", err)
+	}
+	lbl := &gi.Label{}
+	lbl.InitName(lbl, "lbl")
+	if _, err := Render(lbl, image.ZP); err == nil {
+		t.Errorf("render of widget at zero size should fail")
+	}
+}
+
+func TestCompareGoldenFail(t *testing.T) {
+	if UpdateGoldens {
+		t.Skip("updating golden images")
+	}
+	defer failDir(t)()
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	draw.Draw(img, image.Rect(0, 0, 10, 10), image.NewUniform(color.RGBA{255, 0, 0, 255}), image.ZP, draw.Src)
+	draw.Draw(img, image.Rect(10, 0, 20, 10), image.NewUniform(color.RGBA{0, 0, 255, 255}), image.ZP, draw.Src)
+	if dr, err := CompareGolden("svg-rects", img, &Tolerance{}); err != nil || !dr.OK() {
+		t.Fatalf("same image as golden: %v %v", dr, err)
+	}
+
+	img.Set(15, 5, color.RGBA{0, 255, 0, 255}) // one changed pixel
+	dr, err := CompareGolden("svg-rects", img, &Tolerance{})
+	if err == nil || dr == nil || dr.NDiff != 1 {
+		t.Errorf("changed pixel not detected: %v %v", dr, err)
+	}
+	for _, fnm := range []string{"svg-rects.png", "svg-rects_diff.png"} {
+		if _, err := os.Stat(filepath.Join(GoldenFailDir, fnm)); err != nil {
+			t.Errorf("failed image not saved: %v", err)
+		}
+	}
+	if _, err := CompareGolden("no-such-golden", img, nil); err == nil {
+		t.Errorf("missing golden should fail")
+	}
+}
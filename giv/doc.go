@@ -43,8 +43,18 @@ TableView
 
 TableView displays a slice-of-struct as a table with columns as the struct fields
 and rows as the elements in the struct.  You can sort by the column headers
-and it supports full editing with drag-n-drop etc.  If set to Inactive, then it
-serves as a chooser, as in the FileView.
+(shift-click to sort by further columns), filter the rows shown with the
+filter bar (SetFilter), and it supports full editing with drag-n-drop etc.
+The rows shown can be exported to, and rows imported from, CSV, TSV or JSON
+files (ExportFile, ImportFile), as can the elements of a SliceView -- set the
+'tools' property to show the Filter, Export and Import actions in the toolbar.
+Columns can be resized and moved by dragging the headers, and hidden with the
+header context menu, and this layout is remembered for each struct type
+(TVColsPrefs).  If set to Inactive, then it serves as a chooser, as in the
+FileView.  Large slices (above TableViewVirtualSize) are shown in a Virtual
+mode, where widgets are only built for the rows that fit in the window, and
+reused as the table is scrolled -- SliceView does the same above
+SliceViewVirtualSize.
 
 MethodView
 
@@ -0,0 +1,77 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gitest"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/headlessdriver"
+)
+
+func TestMain(m *testing.M) {
+	headlessdriver.Main(func(app oswin.App) {
+		os.Exit(m.Run())
+	})
+}
+
+// testRow is the struct viewed in the table tests
+type testRow struct {
+	Name  string `label:"Full Name"`
+	Size  int
+	Val   float32
+	On    bool
+	Notes string `view:"-"`
+}
+
+// testRowFields returns all of the fields of testRow
+func testRowFields() []reflect.StructField {
+	typ := reflect.TypeOf(testRow{})
+	flds := make([]reflect.StructField, typ.NumField())
+	for i := range flds {
+		flds[i] = typ.Field(i)
+	}
+	return flds
+}
+
+// testFieldNames returns the names of the fields, separated by commas
+func testFieldNames(flds []reflect.StructField) string {
+	nms := make([]string, len(flds))
+	for i, fld := range flds {
+		nms[i] = fld.Name
+	}
+	return strings.Join(nms, ",")
+}
+
+// testRows returns n rows, named by their index
+func testRows(n int) []testRow {
+	sl := make([]testRow, n)
+	for i := range sl {
+		sl[i] = testRow{Name: fmt.Sprintf("row %v", i), Size: i, Val: float32(i) / 2}
+	}
+	return sl
+}
+
+// testWin makes a window, calls fun to add the views to its main frame, and
+// starts a harness for it
+func testWin(t *testing.T, name string, fun func(mfr *gi.Frame)) *gitest.Harness {
+	win := gi.NewWindow2D(name, name, 800, 600, true)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	fun(mfr)
+	vp.UpdateEndNoSig(updt)
+	h := gitest.NewHarness(win)
+	if err := h.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
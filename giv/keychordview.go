@@ -6,6 +6,7 @@ package giv
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/oswin"
@@ -62,12 +63,19 @@ func (vv *KeyChordValueView) HasAction() bool {
 /////////////////////////////////////////////////////////////////////////////////
 // KeyChordEdit
 
+// KeyChordEditSeqTimeoutMSec is the number of milliseconds between chords
+// typed into a KeyChordEdit to combine them into a multi-key sequence --
+// starts over with a new chord after this delay.
+var KeyChordEditSeqTimeoutMSec = 1500
+
 // KeyChordEdit is a label widget that shows a key chord string, and, when in
 // focus (after being clicked) will update to whatever key chord is typed --
 // used for representing and editing key chords.
 type KeyChordEdit struct {
 	gi.Label
 	FocusActive bool      `json:"-" xml:"-" desc:"true if the keyboard focus is active or not -- when we lose active focus we apply changes"`
+	SeqActive   bool      `json:"-" xml:"-" desc:"true if a chord has been entered since getting focus -- further chords entered within KeyChordEditSeqTimeoutMSec are added to make a multi-key sequence"`
+	SeqTime     time.Time `json:"-" xml:"-" desc:"time of last chord entered -- for timeout of the sequence"`
 	KeyChordSig ki.Signal `json:"-" xml:"-" view:"-" desc:"signal -- only one event, when chord is updated from key input"`
 }
 
@@ -111,6 +119,12 @@ func (kc *KeyChordEdit) MakeContextMenu(m *gi.Menu) {
 		})
 }
 
+// KeyContext satisfies the gi.KeyContexter interface -- the edit records the
+// raw chords typed, without multi-key sequence processing by the window
+func (kc *KeyChordEdit) KeyContext() gi.KeyContext {
+	return gi.KeyContextRaw
+}
+
 func (kc *KeyChordEdit) MouseEvent() {
 	kc.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
 		me := d.(*mouse.Event)
@@ -140,8 +154,15 @@ func (kc *KeyChordEdit) KeyChordEvent() {
 		if kcc.HasFocus() && kcc.FocusActive {
 			kt := d.(*key.ChordEvent)
 			kt.SetProcessed()
-			kcc.SetText(string(kt.Chord())) // that's easy!
-			oswin.TheApp.ClipBoard(kc.Viewport.Win.OSWin).Write(mimedata.NewText(string(kt.Chord())))
+			ch := kt.Chord()
+			delayMs := int(kt.Time().Sub(kcc.SeqTime) / time.Millisecond)
+			kcc.SeqTime = kt.Time()
+			if kcc.SeqActive && kcc.Text != "" && delayMs <= KeyChordEditSeqTimeoutMSec { // subsequent chords make a sequence
+				ch = key.Chord(kcc.Text) + key.ChordSeqSep + ch
+			}
+			kcc.SeqActive = true
+			kcc.SetText(string(ch))
+			oswin.TheApp.ClipBoard(kc.Viewport.Win.OSWin).Write(mimedata.NewText(string(ch)))
 			kcc.ChordUpdated()
 		}
 	})
@@ -171,6 +192,7 @@ func (kc *KeyChordEdit) FocusChanged2D(change gi.FocusChanges) {
 		kc.UpdateSig()
 	case gi.FocusGot:
 		kc.FocusActive = true
+		kc.SeqActive = false
 		kc.SetSelected()
 		kc.ScrollToMe()
 		kc.EmitFocusedSignal()
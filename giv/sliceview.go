@@ -27,10 +27,21 @@ import (
 ////////////////////////////////////////////////////////////////////////////////////////
 //  SliceView
 
+// SliceViewVirtualSize is the length of the slice above which the view
+// automatically switches to Virtual mode, where widgets are only built for
+// the visible rows -- the 'virtual' property (bool) overrides this
+var SliceViewVirtualSize = 2000
+
+// SliceViewVirtualBuffer is the number of rows of widgets built in Virtual
+// mode beyond those needed to fill the window
+var SliceViewVirtualBuffer = 4
+
 // SliceView represents a slice, creating a property editor of the values --
 // constructs Children widgets to show the index / value pairs, within an
 // overall frame. Set to Inactive for select-only mode, which emits WidgetSig
-// WidgetSelected signals when selection is updated
+// WidgetSelected signals when selection is updated.  For large slices, it
+// switches to a Virtual mode that only builds widgets for the visible rows,
+// and reuses them for other rows as the view is scrolled.
 type SliceView struct {
 	gi.Frame
 	Slice            interface{}        `desc:"the slice that we are a view onto -- must be a pointer to that slice"`
@@ -41,7 +52,12 @@ type SliceView struct {
 	Changed          bool               `desc:"has the slice been edited?"`
 	Values           []ValueView        `json:"-" xml:"-" desc:"ValueView representations of the slice values"`
 	ShowIndex        bool               `xml:"index" desc:"whether to show index or not -- updated from 'index' property (bool)"`
+	ShowTools        bool               `xml:"tools" desc:"whether to show the Export and Import actions in the toolbar (default false) -- updated from 'tools' property (bool)"`
 	InactKeyNav      bool               `xml:"inact-key-nav" desc:"support key navigation when inactive (default true) -- updated from 'intact-key-nav' property (bool) -- no focus really plausible in inactive case, so it uses a low-pri capture of up / down events"`
+	VisRows          int                `desc:"number of rows visible in display"`
+	Virtual          bool               `xml:"virtual" desc:"only build widgets for the rows visible in the window (plus SliceViewVirtualBuffer), and reuse them for other rows as the view is scrolled -- set automatically for slices longer than SliceViewVirtualSize, or from the 'virtual' property (bool)"`
+	StartIdx         int                `json:"-" xml:"-" desc:"slice index of the first row that has widgets -- always 0 unless Virtual"`
+	VirtRows         int                `json:"-" xml:"-" desc:"number of rows that have widgets -- the full slice length unless Virtual"`
 	SelVal           interface{}        `view:"-" json:"-" xml:"-" desc:"current selection value -- initially select this value if set"`
 	SelectedIdx      int                `json:"-" xml:"-" desc:"index of currently-selected item, in Inactive mode only"`
 	SelectMode       bool               `desc:"editing-mode select rows mode"`
@@ -74,6 +90,7 @@ func (sv *SliceView) SetSlice(sl interface{}, tmpSave ValueView) {
 		updt = sv.UpdateStart()
 		sv.Slice = sl
 		sv.IsArray = kit.NonPtrType(reflect.TypeOf(sl)).Kind() == reflect.Array
+		sv.StartIdx = 0
 		if !sv.IsInactive() {
 			sv.SelectedIdx = -1
 		}
@@ -89,6 +106,9 @@ func (sv *SliceView) SetSlice(sl interface{}, tmpSave ValueView) {
 	if siknp, ok := sv.Prop("inact-key-nav"); ok {
 		sv.InactKeyNav, _ = kit.ToBool(siknp)
 	}
+	if stp, ok := sv.Prop("tools"); ok {
+		sv.ShowTools, _ = kit.ToBool(stp)
+	}
 	sv.TmpSave = tmpSave
 	sv.UpdateFromSlice()
 	sv.UpdateEnd(updt)
@@ -119,6 +139,7 @@ const (
 
 // UpdateFromSlice performs overall configuration for given slice
 func (sv *SliceView) UpdateFromSlice() {
+	sv.SetVirtual()
 	mods, updt := sv.StdConfig()
 	sv.ConfigSliceGrid(true)
 	sv.ConfigToolbar()
@@ -132,7 +153,9 @@ func (sv *SliceView) UpdateFromSlice() {
 func (sv *SliceView) UpdateValues() {
 	updt := sv.UpdateStart()
 	for _, vv := range sv.Values {
-		vv.UpdateWidget()
+		if vv != nil { // nil for blank rows past the end in Virtual mode
+			vv.UpdateWidget()
+		}
 	}
 	sv.UpdateEnd(updt)
 }
@@ -142,7 +165,21 @@ func (sv *SliceView) UpdateValues() {
 func (sv *SliceView) StdFrameConfig() kit.TypeAndNameList {
 	config := kit.TypeAndNameList{}
 	config.Add(gi.KiT_ToolBar, "toolbar")
+	if sv.Virtual {
+		config.Add(gi.KiT_Layout, "slice-grid-lay")
+	} else {
+		config.Add(gi.KiT_Frame, "slice-grid")
+	}
+	return config
+}
+
+// StdGridLayConfig returns a TypeAndNameList for configuring the
+// slice-grid-lay layout used in Virtual mode, with the grid and its vertical
+// scrollbar
+func (sv *SliceView) StdGridLayConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
 	config.Add(gi.KiT_Frame, "slice-grid")
+	config.Add(gi.KiT_ScrollBar, "vscroll")
 	return config
 }
 
@@ -157,13 +194,36 @@ func (sv *SliceView) StdConfig() (mods, updt bool) {
 }
 
 // SliceGrid returns the SliceGrid grid frame widget, which contains all the
-// fields and values, and its index, within frame -- nil, -1 if not found
+// fields and values, and its index, within frame -- nil, -1 if not found --
+// in Virtual mode it is within the slice-grid-lay layout together with the
+// VScroll scrollbar, and the index is that of the layout
 func (sv *SliceView) SliceGrid() (*gi.Frame, int) {
-	idx, ok := sv.Children().IndexByName("slice-grid", 0)
+	if idx, ok := sv.Children().IndexByName("slice-grid", 0); ok {
+		return sv.KnownChild(idx).(*gi.Frame), idx
+	}
+	idx, ok := sv.Children().IndexByName("slice-grid-lay", 0)
 	if !ok {
 		return nil, -1
 	}
-	return sv.KnownChild(idx).(*gi.Frame), idx
+	gl := sv.KnownChild(idx).(*gi.Layout)
+	if len(gl.Kids) == 0 {
+		return nil, -1
+	}
+	return gl.KnownChild(0).(*gi.Frame), idx
+}
+
+// VScroll returns the vertical scrollbar used for scrolling the rows in
+// Virtual mode -- nil if not Virtual
+func (sv *SliceView) VScroll() *gi.ScrollBar {
+	idx, ok := sv.Children().IndexByName("slice-grid-lay", 0)
+	if !ok {
+		return nil
+	}
+	gl := sv.KnownChild(idx).(*gi.Layout)
+	if len(gl.Kids) < 2 {
+		return nil
+	}
+	return gl.KnownChild(1).(*gi.ScrollBar)
 }
 
 // ToolBar returns the toolbar widget
@@ -189,6 +249,45 @@ func (sv *SliceView) RowWidgetNs() (nWidgPerRow, idxOff int) {
 	return
 }
 
+// SetVirtual sets the Virtual mode from the length of the slice and the
+// 'virtual' property -- the slice-grid is configured accordingly by StdConfig
+func (sv *SliceView) SetVirtual() {
+	if kit.IfaceIsNil(sv.Slice) {
+		return
+	}
+	sz := kit.NonPtrValue(reflect.ValueOf(sv.Slice)).Len()
+	sv.Virtual = sz > SliceViewVirtualSize
+	if vp, ok := sv.Prop("virtual"); ok {
+		sv.Virtual, _ = kit.ToBool(vp)
+	}
+}
+
+// RowHeight returns the estimated height of a row, in dots, used for
+// determining the number of rows to build in Virtual mode
+func (sv *SliceView) RowHeight() int {
+	if sv.Sty.Font.Height > 0 {
+		return int(1.8 * sv.Sty.Font.Height)
+	}
+	return 24
+}
+
+// VirtRowsN returns the number of rows to build for a slice of given size in
+// Virtual mode: enough to fill the window, plus SliceViewVirtualBuffer
+func (sv *SliceView) VirtRowsN(sz int) int {
+	nr := 30
+	if win := sv.ParentWindow(); win != nil && win.Viewport.Geom.Size.Y > 0 {
+		nr = win.Viewport.Geom.Size.Y / sv.RowHeight()
+	}
+	return ints.MinInt(sz, nr+SliceViewVirtualBuffer)
+}
+
+// MaxStartIdx returns the largest StartIdx for Virtual mode, at which the
+// last row of the slice is the last visible row
+func (sv *SliceView) MaxStartIdx() int {
+	vis := ints.MinInt(ints.MaxInt(sv.VisRows, 1), sv.VirtRows)
+	return ints.MaxInt(0, sv.BuiltSize-vis)
+}
+
 // ConfigSliceGrid configures the SliceGrid for the current slice
 func (sv *SliceView) ConfigSliceGrid(forceUpdt bool) {
 	if kit.IfaceIsNil(sv.Slice) {
@@ -204,6 +303,31 @@ func (sv *SliceView) ConfigSliceGrid(forceUpdt bool) {
 	sv.BuiltSlice = sv.Slice
 	sv.BuiltSize = sz
 
+	if sv.Virtual {
+		sv.VirtRows = sv.VirtRowsN(sz)
+		if sv.SelVal != nil {
+			sv.SelectedIdx, _ = SliceRowByValue(sv.Slice, sv.SelVal)
+		}
+		if sv.SelectedIdx >= 0 && (sv.SelectedIdx < sv.StartIdx || sv.SelectedIdx >= sv.StartIdx+sv.VisRows) {
+			sv.StartIdx = sv.SelectedIdx
+		}
+		sv.StartIdx = ints.MaxInt(0, ints.MinInt(sv.StartIdx, sv.MaxStartIdx()))
+		if glk, ok := sv.ChildByName("slice-grid-lay", 1); ok {
+			gl := glk.(*gi.Layout)
+			gl.Lay = gi.LayoutHoriz
+			gl.SetProp("overflow", "hidden") // rows beyond the visible ones are clipped
+			gl.SetProp("spacing", 0)
+			// a pref here keeps the built rows from determining our size
+			gl.SetMinPrefHeight(units.NewValue(10, units.Em))
+			gl.SetStretchMaxHeight()
+			gl.SetStretchMaxWidth()
+			gl.ConfigChildren(sv.StdGridLayConfig(), false)
+		}
+	} else {
+		sv.VirtRows = sz
+		sv.StartIdx = 0
+	}
+
 	sg, _ := sv.SliceGrid()
 	if sg == nil {
 		return
@@ -221,18 +345,23 @@ func (sv *SliceView) ConfigSliceGrid(forceUpdt bool) {
 	sg.SetMinPrefWidth(units.NewValue(10, units.Em))
 	sg.SetStretchMaxHeight() // for this to work, ALL layers above need it too
 	sg.SetStretchMaxWidth()  // for this to work, ALL layers above need it too
+	if sv.Virtual {
+		sg.SetProp("overflow", "hidden") // VScroll does the scrolling
+		sv.ConfigVScroll()
+	}
 
-	sv.Values = make([]ValueView, sz)
+	sv.Values = make([]ValueView, sv.VirtRows)
 
 	sg.DeleteChildren(true)
-	sg.Kids = make(ki.Slice, nWidgPerRow*sz)
+	sg.Kids = make(ki.Slice, nWidgPerRow*sv.VirtRows)
 
 	sv.ConfigSliceGridRows()
 }
 
 // ConfigSliceGridRows configures the SliceGrid rows for the current slice --
 // assumes .Kids is created at the right size -- only call this for a direct
-// re-render e.g., after sorting
+// re-render e.g., after sorting.  In Virtual mode, the built rows show the
+// slice rows starting at StartIdx, reusing existing widgets where possible.
 func (sv *SliceView) ConfigSliceGridRows() {
 	mv := reflect.ValueOf(sv.Slice)
 	mvnp := kit.NonPtrValue(mv)
@@ -243,15 +372,20 @@ func (sv *SliceView) ConfigSliceGridRows() {
 	updt := sg.UpdateStart()
 	defer sg.UpdateEnd(updt)
 
-	for i := 0; i < sz; i++ {
-		ridx := i * nWidgPerRow
+	for r := 0; r < sv.VirtRows; r++ {
+		i := sv.StartIdx + r
+		ridx := r * nWidgPerRow
+		if i >= sz {
+			sv.ConfigBlankRow(r)
+			continue
+		}
 		val := kit.OnePtrValue(mvnp.Index(i)) // deal with pointer lists
 		vv := ToValueView(val.Interface(), "")
 		if vv == nil { // shouldn't happen
 			continue
 		}
 		vv.SetSliceValue(val, sv.Slice, i, sv.TmpSave)
-		sv.Values[i] = vv
+		sv.Values[r] = vv
 		vtyp := vv.WidgetType()
 		idxtxt := fmt.Sprintf("%05d", i)
 		labnm := fmt.Sprintf("index-%v", idxtxt)
@@ -279,11 +413,12 @@ func (sv *SliceView) ConfigSliceGridRows() {
 		}
 
 		var widg gi.Node2D
-		if sg.Kids[ridx+idxOff] != nil {
+		if sg.Kids[ridx+idxOff] != nil && sg.Kids[ridx+idxOff].Type() == vtyp {
 			widg = sg.Kids[ridx+idxOff].(gi.Node2D)
-		} else {
+		} else { // new, or a different type of value in an interface slice
 			widg = ki.NewOfType(vtyp).(gi.Node2D)
 			sg.SetChild(widg, ridx+idxOff, valnm)
+			sg.SetFullReRender()
 		}
 		vv.ConfigWidget(widg)
 
@@ -311,10 +446,16 @@ func (sv *SliceView) ConfigSliceGridRows() {
 			if !sv.IsArray {
 				addnm := fmt.Sprintf("add-%v", idxtxt)
 				delnm := fmt.Sprintf("del-%v", idxtxt)
-				addact := gi.Action{}
-				delact := gi.Action{}
-				sg.SetChild(&addact, ridx+idxOff+1, addnm)
-				sg.SetChild(&delact, ridx+idxOff+2, delnm)
+				addact, ok := sg.Kids[ridx+idxOff+1].(*gi.Action)
+				if !ok {
+					addact = &gi.Action{}
+					sg.SetChild(addact, ridx+idxOff+1, addnm)
+				}
+				delact, ok := sg.Kids[ridx+idxOff+2].(*gi.Action)
+				if !ok {
+					delact = &gi.Action{}
+					sg.SetChild(delact, ridx+idxOff+2, delnm)
+				}
 
 				addact.SetIcon("plus")
 				addact.Tooltip = "insert a new element at this index"
@@ -337,7 +478,16 @@ func (sv *SliceView) ConfigSliceGridRows() {
 		if sv.StyleFunc != nil {
 			sv.StyleFunc(sv, mvnp.Interface(), widg, i, vv)
 		}
-
+		if sv.Virtual { // widgets may have been showing another row
+			for c := 0; c < nWidgPerRow; c++ {
+				sg.KnownChild(ridx + c).(gi.Node2D).AsNode2D().ClearInvisible()
+			}
+			if sv.IsInactive() {
+				sv.SelectRowWidgets(i, i == sv.SelectedIdx)
+			} else {
+				sv.SelectRowWidgets(i, sv.RowIsSelected(i))
+			}
+		}
 	}
 	if sv.SelVal != nil {
 		sv.SelectedIdx, _ = SliceRowByValue(sv.Slice, sv.SelVal)
@@ -347,6 +497,91 @@ func (sv *SliceView) ConfigSliceGridRows() {
 	}
 }
 
+// ConfigBlankRow configures given built row (not slice index) as a blank
+// row past the end of the slice, which can happen at the end of the slice
+// in Virtual mode -- its widgets are made invisible, and placeholders are
+// created for any that do not exist yet
+func (sv *SliceView) ConfigBlankRow(r int) {
+	nWidgPerRow, _ := sv.RowWidgetNs()
+	sg, _ := sv.SliceGrid()
+	ridx := r * nWidgPerRow
+	for c := 0; c < nWidgPerRow; c++ {
+		if sg.Kids[ridx+c] == nil {
+			sg.SetChild(&gi.Label{}, ridx+c, fmt.Sprintf("blank-%v.%v", r, c))
+		}
+		sg.KnownChild(ridx + c).(gi.Node2D).AsNode2D().SetInvisible()
+	}
+	sv.Values[r] = nil
+}
+
+// ConfigVScroll configures the vertical scrollbar used in Virtual mode
+func (sv *SliceView) ConfigVScroll() {
+	sb := sv.VScroll()
+	if sb == nil {
+		return
+	}
+	sb.Dim = gi.Y
+	sb.Defaults()
+	sb.Tracking = true
+	sb.Min = 0
+	sbw := sv.Sty.Layout.ScrollBarWidth
+	if sbw.Val == 0 { // not yet styled
+		sbw = units.NewValue(16, units.Px)
+	}
+	sb.SetFixedWidth(sbw)
+	sb.SetStretchMaxHeight()
+	sb.SliderSig.ConnectOnly(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(gi.SliderValueChanged) {
+			return
+		}
+		svv := recv.Embed(KiT_SliceView).(*SliceView)
+		svv.SetStartIdx(int(data.(float32) + 0.5))
+	})
+	sv.UpdateVScroll()
+}
+
+// UpdateVScroll updates the range and position of the Virtual mode vertical
+// scrollbar from the current BuiltSize, VisRows and StartIdx
+func (sv *SliceView) UpdateVScroll() {
+	sb := sv.VScroll()
+	if sb == nil {
+		return
+	}
+	vis := float32(ints.MinInt(ints.MaxInt(sv.VisRows, 1), sv.BuiltSize))
+	sb.Max = float32(sv.BuiltSize)
+	sb.ThumbVal = vis
+	sb.Step = 1
+	sb.PageStep = vis
+	sb.TrackThr = 1
+	sb.SetValue(float32(sv.StartIdx))
+}
+
+// SetStartIdx sets the slice index of the first row shown in Virtual mode,
+// updating the row widgets to show the slice rows from there
+func (sv *SliceView) SetStartIdx(idx int) {
+	if !sv.Virtual {
+		return
+	}
+	idx = ints.MaxInt(0, ints.MinInt(idx, sv.MaxStartIdx()))
+	if idx == sv.StartIdx {
+		return
+	}
+	var win *gi.Window
+	if sv.Viewport != nil {
+		win = sv.Viewport.Win
+	}
+	wupdt := false
+	if win != nil {
+		wupdt = win.UpdateStart()
+	}
+	sv.StartIdx = idx
+	sv.ConfigSliceGridRows()
+	sv.UpdateVScroll()
+	if win != nil {
+		win.UpdateEnd(wupdt)
+	}
+}
+
 // SetChanged sets the Changed flag and emits the ViewSig signal for the
 // SliceView, indicating that some kind of edit / change has taken place to
 // the table data.  It isn't really practical to record all the different
@@ -439,34 +674,50 @@ func (sv *SliceView) SliceDeleteAt(idx int, reconfig bool) {
 	}
 }
 
-// ConfigToolbar configures the toolbar actions
+// ConfigToolbar configures the toolbar actions -- Add is only shown when the
+// slice can be modified, and Export and Import (when it can be modified) only
+// if ShowTools -- these standard actions end with a separator, after which the
+// ToolBarView actions of the slice are added when not inactive
 func (sv *SliceView) ConfigToolbar() {
-	if kit.IfaceIsNil(sv.Slice) || sv.IsInactive() {
+	if kit.IfaceIsNil(sv.Slice) || (sv.IsInactive() && !sv.ShowTools) {
 		return
 	}
 	if sv.ToolbarSlice == sv.Slice {
 		return
 	}
 	tb := sv.ToolBar()
-	nact := 1
-	if sv.IsArray || sv.IsInactive() {
-		nact = 0
-	}
-	if len(*tb.Children()) < nact {
+	canAdd := !sv.IsArray && !sv.IsInactive()
+	if len(*tb.Children()) == 0 {
 		tb.SetStretchMaxWidth()
-		tb.AddAction(gi.ActOpts{Label: "Add", Icon: "plus"},
-			sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-				svv := recv.Embed(KiT_SliceView).(*SliceView)
-				svv.SliceNewAt(-1, true)
-			})
+		if canAdd {
+			tb.AddAction(gi.ActOpts{Label: "Add", Icon: "plus"},
+				sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					svv := recv.Embed(KiT_SliceView).(*SliceView)
+					svv.SliceNewAt(-1, true)
+				})
+		}
+		if sv.ShowTools {
+			tb.AddAction(gi.ActOpts{Label: "Export", Icon: "file-save", Tooltip: "export the elements to a .csv, .tsv or .json file"},
+				sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					svv := recv.Embed(KiT_SliceView).(*SliceView)
+					svv.ExportDialog()
+				})
+			if canAdd {
+				tb.AddAction(gi.ActOpts{Label: "Import", Icon: "file-open", Tooltip: "add the elements from a .csv, .tsv or .json file"},
+					sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+						svv := recv.Embed(KiT_SliceView).(*SliceView)
+						svv.ImportDialog()
+					})
+			}
+		}
+		tb.AddNewChild(gi.KiT_Separator, "std-sep")
 	}
-	sz := len(*tb.Children())
-	if sz > nact {
-		for i := sz - 1; i >= nact; i-- {
+	if sidx, ok := tb.Children().IndexByName("std-sep", 0); ok {
+		for i := len(*tb.Children()) - 1; i > sidx; i-- {
 			tb.DeleteChildAtIndex(i, true)
 		}
 	}
-	if HasToolBarView(sv.Slice) {
+	if !sv.IsInactive() && HasToolBarView(sv.Slice) {
 		ToolBarView(sv.Slice, sv.Viewport, tb)
 	}
 	sv.ToolbarSlice = sv.Slice
@@ -492,12 +743,21 @@ func (sv *SliceView) Render2D() {
 		return
 	}
 	if sv.PushBounds() {
+		if sv.Virtual {
+			sv.VisRows = sv.VirtVisRows()
+			sv.UpdateVScroll()
+		} else if sv.Sty.Font.Height > 0 {
+			sv.VisRows = (sv.VpBBox.Max.Y - sv.VpBBox.Min.Y) / int(1.8*sv.Sty.Font.Height)
+		} else {
+			sv.VisRows = 10
+		}
 		sv.FrameStdRender()
 		sv.This().(gi.Node2D).ConnectEvents2D()
 		sv.RenderScrolls()
 		sv.Render2DChildren()
 		sv.PopBounds()
-		if sv.SelectedIdx > -1 {
+		// in Virtual mode, ConfigSliceGrid keeps the selected row in view
+		if sv.SelectedIdx > -1 && !sv.Virtual {
 			sv.ScrollToRow(sv.SelectedIdx)
 		}
 	} else {
@@ -505,6 +765,20 @@ func (sv *SliceView) Render2D() {
 	}
 }
 
+// VirtVisRows returns the number of rows that fit within the visible part of
+// the grid in Virtual mode, based on the allocated height of the rows
+func (sv *SliceView) VirtVisRows() int {
+	sg, _ := sv.SliceGrid()
+	if sg == nil {
+		return 10
+	}
+	rowht := float32(sv.RowHeight())
+	if len(sg.GridData[gi.Row]) > 0 && sg.GridData[gi.Row][0].AllocSize > 0 {
+		rowht = sg.GridData[gi.Row][0].AllocSize + sg.Spacing.Dots
+	}
+	return ints.MaxInt(1, int(float32(sg.VpBBox.Max.Y-sg.VpBBox.Min.Y)/rowht))
+}
+
 func (sv *SliceView) ConnectEvents2D() {
 	sv.SliceViewEvents()
 }
@@ -533,6 +807,22 @@ func (sv *SliceView) RowVal(row int) interface{} {
 	return vali
 }
 
+// RowGridIdx returns the index within the SliceGrid children of the first
+// widget for given row -- false if out of range or, in Virtual mode, if the
+// row does not currently have widgets
+func (sv *SliceView) RowGridIdx(row int) (int, bool) {
+	if row < sv.StartIdx || row >= sv.StartIdx+sv.VirtRows {
+		return -1, false
+	}
+	nWidgPerRow, _ := sv.RowWidgetNs()
+	ridx := (row - sv.StartIdx) * nWidgPerRow
+	sg, _ := sv.SliceGrid()
+	if sg == nil || !sg.Kids.IsValidIndex(ridx+nWidgPerRow-1) {
+		return -1, false
+	}
+	return ridx, true
+}
+
 // RowFirstWidget returns the first widget for given row (could be index or
 // not) -- false if out of range
 func (sv *SliceView) RowFirstWidget(row int) (*gi.WidgetBase, bool) {
@@ -542,12 +832,12 @@ func (sv *SliceView) RowFirstWidget(row int) (*gi.WidgetBase, bool) {
 	if sv.RowVal(row) == nil { // range check
 		return nil, false
 	}
-	nWidgPerRow, _ := sv.RowWidgetNs()
-	sg, _ := sv.SliceGrid()
-	if sg == nil {
+	ridx, ok := sv.RowGridIdx(row)
+	if !ok {
 		return nil, false
 	}
-	widg := sg.Kids[row*nWidgPerRow].(gi.Node2D).AsWidget()
+	sg, _ := sv.SliceGrid()
+	widg := sg.Kids[ridx].(gi.Node2D).AsWidget()
 	return widg, true
 }
 
@@ -558,12 +848,12 @@ func (sv *SliceView) RowGrabFocus(row int) *gi.WidgetBase {
 	if sv.RowVal(row) == nil || sv.inFocusGrab { // range check
 		return nil
 	}
-	nWidgPerRow, idxOff := sv.RowWidgetNs()
-	sg, _ := sv.SliceGrid()
-	if sg == nil {
+	_, idxOff := sv.RowWidgetNs()
+	ridx, ok := sv.RowGridIdx(row)
+	if !ok {
 		return nil
 	}
-	ridx := nWidgPerRow * row
+	sg, _ := sv.SliceGrid()
 	widg := sg.KnownChild(ridx + idxOff).(gi.Node2D).AsWidget()
 	if widg.HasFocus() {
 		return widg
@@ -590,7 +880,8 @@ func (sv *SliceView) RowPos(row int) image.Point {
 // RowFromPos returns the row that contains given vertical position, false if not found
 func (sv *SliceView) RowFromPos(posY int) (int, bool) {
 	// todo: could optimize search to approx loc, and search up / down from there
-	for rw := 0; rw < sv.BuiltSize; rw++ {
+	nrw := ints.MinInt(sv.BuiltSize, sv.StartIdx+sv.VirtRows)
+	for rw := sv.StartIdx; rw < nrw; rw++ {
 		widg, ok := sv.RowFirstWidget(rw)
 		if ok {
 			if widg.WinBBox.Min.Y < posY && posY < widg.WinBBox.Max.Y {
@@ -602,9 +893,27 @@ func (sv *SliceView) RowFromPos(posY int) (int, bool) {
 }
 
 // ScrollToRow ensures that given row is visible by scrolling layout as needed
-// -- returns true if any scrolling was performed
+// -- returns true if any scrolling was performed.  In Virtual mode, this
+// moves the StartIdx to bring the row into view.
 func (sv *SliceView) ScrollToRow(row int) bool {
 	row = ints.MinInt(row, sv.BuiltSize-1)
+	if sv.Virtual {
+		vis := ints.MinInt(ints.MaxInt(sv.VisRows, 1), sv.VirtRows)
+		st := sv.StartIdx
+		switch {
+		case row < st:
+			st = row
+		case row >= st+vis:
+			st = row - vis + 1
+		default:
+			return false
+		}
+		sv.SetStartIdx(st)
+		if !sv.IsInactive() && sv.RowIsSelected(row) {
+			sv.RowGrabFocus(row)
+		}
+		return true
+	}
 	sg, _ := sv.SliceGrid()
 	if widg, ok := sv.RowFirstWidget(row); ok {
 		return sg.ScrollToItem(widg)
@@ -707,11 +1016,15 @@ func (sv *SliceView) MoveUpAction(selMode mouse.SelectModes) int {
 //////////////////////////////////////////////////////////////////////////////
 //    Selection: user operates on the index labels
 
-// SelectRowWidgets sets the selection state of given row of widgets
+// SelectRowWidgets sets the selection state of given row of widgets -- does
+// nothing for rows that do not currently have widgets in Virtual mode
 func (sv *SliceView) SelectRowWidgets(idx int, sel bool) {
+	rowidx, ok := sv.RowGridIdx(idx)
+	if !ok {
+		return
+	}
 	sg, _ := sv.SliceGrid()
-	nWidgPerRow, idxOff := sv.RowWidgetNs()
-	rowidx := idx * nWidgPerRow
+	_, idxOff := sv.RowWidgetNs()
 	if sv.ShowIndex {
 		if sg.Kids.IsValidIndex(rowidx) {
 			widg := sg.KnownChild(rowidx).(gi.Node2D).AsNode2D()
@@ -1148,7 +1461,16 @@ func (sv *SliceView) DragNDropStart() {
 		sv.MimeDataRow(&md, r)
 	}
 	rws := sv.SelectedRowsList(true) // descending sort
-	widg, ok := sv.RowFirstWidget(rws[0])
+	var widg *gi.WidgetBase
+	ok := false
+	for _, r := range rws { // in Virtual mode, rows may not have widgets
+		if widg, ok = sv.RowFirstWidget(r); ok {
+			break
+		}
+	}
+	if !ok && sv.Virtual {
+		widg, ok = sv.RowFirstWidget(sv.StartIdx)
+	}
 	if ok {
 		bi := &gi.Bitmap{}
 		bi.InitName(bi, sv.UniqueName())
@@ -1340,11 +1662,17 @@ func (sv *SliceView) ItemCtxtMenu(row int) {
 	}
 }
 
+// KeyContext satisfies the gi.KeyContexter interface -- slice views use the
+// SliceView context-specific key map
+func (sv *SliceView) KeyContext() gi.KeyContext {
+	return gi.KeyContextSliceView
+}
+
 func (sv *SliceView) KeyInputActive(kt *key.ChordEvent) {
 	if gi.KeyEventTrace {
 		fmt.Printf("SliceView KeyInput: %v\n", sv.PathUnique())
 	}
-	kf := gi.KeyFun(kt.Chord())
+	kf := gi.KeyFunContext(kt.Chord(), gi.KeyContextSliceView)
 	selMode := mouse.SelectModeBits(kt.Modifiers)
 	row := sv.SelectedIdx
 	switch kf {
@@ -1407,7 +1735,7 @@ func (sv *SliceView) KeyInputInactive(kt *key.ChordEvent) {
 	if gi.KeyEventTrace {
 		fmt.Printf("SliceView Inactive KeyInput: %v\n", sv.PathUnique())
 	}
-	kf := gi.KeyFun(kt.Chord())
+	kf := gi.KeyFunContext(kt.Chord(), gi.KeyContextSliceView)
 	row := sv.SelectedIdx
 	switch kf {
 	case gi.KeyFunMoveDown:
@@ -1428,6 +1756,24 @@ func (sv *SliceView) KeyInputInactive(kt *key.ChordEvent) {
 }
 
 func (sv *SliceView) SliceViewEvents() {
+	if sv.Virtual {
+		sv.ConnectEvent(oswin.MouseScrollEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+			me := d.(*mouse.ScrollEvent)
+			svv := recv.Embed(KiT_SliceView).(*SliceView)
+			if me.Delta.Y == 0 {
+				return
+			}
+			me.SetProcessed()
+			nr := me.Delta.Y / svv.RowHeight()
+			if nr == 0 {
+				nr = 1
+				if me.Delta.Y < 0 {
+					nr = -1
+				}
+			}
+			svv.SetStartIdx(svv.StartIdx + nr)
+		})
+	}
 	if sv.IsInactive() {
 		if sv.InactKeyNav {
 			sv.ConnectEvent(oswin.KeyChordEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
@@ -0,0 +1,141 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin/mouse"
+)
+
+// testSliceView returns a SliceView of n strings in a window, with Virtual
+// mode for slices longer than 50 rows
+func testSliceView(t *testing.T, name string, n int, props map[string]interface{}) (*SliceView, func()) {
+	vsz := SliceViewVirtualSize
+	SliceViewVirtualSize = 50
+	sl := make([]string, n)
+	for i := range sl {
+		sl[i] = fmt.Sprintf("row %v", i)
+	}
+	var sv *SliceView
+	h := testWin(t, name, func(mfr *gi.Frame) {
+		sv = mfr.AddNewChild(KiT_SliceView, "sv").(*SliceView)
+		for k, v := range props {
+			sv.SetProp(k, v)
+		}
+		sv.SetSlice(&sl, nil)
+	})
+	return sv, func() {
+		h.Close()
+		SliceViewVirtualSize = vsz
+	}
+}
+
+// testSliceSelRows returns the rows whose widgets show them as selected
+func testSliceSelRows(sv *SliceView) []int {
+	var sel []int
+	nWidgPerRow, idxOff := sv.RowWidgetNs()
+	sg, _ := sv.SliceGrid()
+	for r := 0; r < sv.VirtRows; r++ {
+		if sg.KnownChild(r*nWidgPerRow + idxOff).(gi.Node2D).AsNode2D().IsSelected() {
+			sel = append(sel, sv.StartIdx+r)
+		}
+	}
+	return sel
+}
+
+func TestSliceViewVirtual(t *testing.T) {
+	sv, done := testSliceView(t, "test-sliceview-virtual", 200, nil)
+	defer done()
+
+	vis := sv.VisRows
+	if !sv.Virtual || sv.VirtRows >= 200 || vis < 3 || vis > sv.VirtRows {
+		t.Fatalf("virtual: %v built rows: %v visible: %v", sv.Virtual, sv.VirtRows, vis)
+	}
+	nWidgPerRow, _ := sv.RowWidgetNs()
+	sg, _ := sv.SliceGrid()
+	if n := len(sg.Kids); n != sv.VirtRows*nWidgPerRow {
+		t.Errorf("built widgets: %v", n)
+	}
+
+	sv.SetStartIdx(1000)
+	if sv.StartIdx != 200-vis || sv.MaxStartIdx() != 200-vis {
+		t.Errorf("start past the end: %v max: %v", sv.StartIdx, sv.MaxStartIdx())
+	}
+	sv.SetStartIdx(-5)
+	if sv.StartIdx != 0 {
+		t.Errorf("start before the beginning: %v", sv.StartIdx)
+	}
+
+	sv.SetStartIdx(10)
+	if lbl := sg.KnownChild(0).(*gi.Label); lbl.Text != "00010" {
+		t.Errorf("first row label: %v", lbl.Text)
+	}
+	if _, ok := sv.RowGridIdx(9); ok {
+		t.Errorf("row before the built rows has widgets")
+	}
+	if ridx, ok := sv.RowGridIdx(10); !ok || ridx != 0 {
+		t.Errorf("first built row: %v %v", ridx, ok)
+	}
+	last := 10 + sv.VirtRows - 1
+	if ridx, ok := sv.RowGridIdx(last); !ok || ridx != (sv.VirtRows-1)*nWidgPerRow {
+		t.Errorf("last built row: %v %v", ridx, ok)
+	}
+	if _, ok := sv.RowGridIdx(last + 1); ok {
+		t.Errorf("row after the built rows has widgets")
+	}
+
+	if sv.ScrollToRow(11) || sv.StartIdx != 10 {
+		t.Errorf("scroll to visible row: %v", sv.StartIdx)
+	}
+	if !sv.ScrollToRow(100) || sv.StartIdx != 100-vis+1 {
+		t.Errorf("scroll down: %v", sv.StartIdx)
+	}
+	if !sv.ScrollToRow(5) || sv.StartIdx != 5 {
+		t.Errorf("scroll up: %v", sv.StartIdx)
+	}
+}
+
+func TestSliceViewVirtualMove(t *testing.T) {
+	sv, done := testSliceView(t, "test-sliceview-virtual-move", 200, nil)
+	defer done()
+
+	vis := sv.VisRows
+	sv.SelectRowAction(vis-1, mouse.NoSelectMode)
+	if row := sv.MoveDownAction(mouse.NoSelectMode); row != vis || sv.StartIdx != 1 {
+		t.Errorf("move down: %v start: %v", row, sv.StartIdx)
+	}
+	if sel := testSliceSelRows(sv); !reflect.DeepEqual(sel, []int{vis}) {
+		t.Errorf("move down selection: %v", sel)
+	}
+	sv.SetStartIdx(50)
+	if sel := testSliceSelRows(sv); len(sel) != 0 || !sv.RowIsSelected(vis) {
+		t.Errorf("selection scrolled out of view: %v", sel)
+	}
+	// moving up from a row without widgets scrolls back to it
+	if row := sv.MoveUpAction(mouse.NoSelectMode); row != vis-1 || sv.StartIdx != vis-1 {
+		t.Errorf("move up: %v start: %v", row, sv.StartIdx)
+	}
+	if sel := testSliceSelRows(sv); !reflect.DeepEqual(sel, []int{vis - 1}) {
+		t.Errorf("move up selection: %v", sel)
+	}
+}
+
+func TestSliceViewVirtualProp(t *testing.T) {
+	sv, done := testSliceView(t, "test-sliceview-virtual-off", 60, map[string]interface{}{"virtual": false})
+	if sv.Virtual || sv.VirtRows != 60 || sv.StartIdx != 0 {
+		t.Errorf("virtual off: %v built rows: %v", sv.Virtual, sv.VirtRows)
+	}
+	done()
+
+	sv, done = testSliceView(t, "test-sliceview-virtual-on", 10, map[string]interface{}{"virtual": true})
+	defer done()
+	if !sv.Virtual || sv.VirtRows != 10 || sv.MaxStartIdx() != 0 {
+		t.Errorf("virtual on: %v built rows: %v max start: %v", sv.Virtual, sv.VirtRows, sv.MaxStartIdx())
+	}
+}
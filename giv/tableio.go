@@ -0,0 +1,696 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// TableIOExts are the file extensions for exporting and importing TableView
+// and SliceView rows -- the format is determined by the extension: .tsv is
+// tab-separated, .json is a JSON array, and anything else is comma-separated
+var TableIOExts = ".csv,.tsv,.json"
+
+// TableIOMaxErrors is the maximum number of errors listed by TableIOErrors
+var TableIOMaxErrors = 20
+
+// TableIOError records a value that could not be parsed when importing rows
+type TableIOError struct {
+	Row   int    `desc:"row in the file, starting at 1 -- for CSV / TSV this is the line, including the header, and for JSON the element of the array"`
+	Col   int    `desc:"column in the file, starting at 1"`
+	Field string `desc:"name of the field for the column"`
+	Value string `desc:"the value that could not be parsed"`
+	Err   error  `desc:"the parsing error"`
+}
+
+func (te *TableIOError) Error() string {
+	return fmt.Sprintf("row: %v col: %v field: %v value: %q: %v", te.Row, te.Col, te.Field, te.Value, te.Err)
+}
+
+// TableIOErrors are the errors from importing rows -- values that cannot be
+// parsed are left at their zero value, and all the other values are imported
+type TableIOErrors []*TableIOError
+
+func (tes TableIOErrors) Error() string {
+	ers := make([]string, 0, len(tes))
+	for i, te := range tes {
+		if i == TableIOMaxErrors {
+			ers = append(ers, fmt.Sprintf("... and %v more", len(tes)-i))
+			break
+		}
+		ers = append(ers, te.Error())
+	}
+	return strings.Join(ers, "\n")
+}
+
+// TableIODelim returns the delimiter for exporting to or importing from
+// given file, based on its extension: tab for .tsv, comma for .csv or
+// anything else, and 0 for .json
+func TableIODelim(filename string) rune {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return 0
+	case ".tsv":
+		return '\t'
+	}
+	return ','
+}
+
+// TableIOFields returns the fields of given struct fields that are
+// exported and imported: all except those with a view:"-" tag
+func TableIOFields(fields []reflect.StructField) []reflect.StructField {
+	iof := make([]reflect.StructField, 0, len(fields))
+	for _, fld := range fields {
+		if fld.Tag.Get("view") == "-" {
+			continue
+		}
+		iof = append(iof, fld)
+	}
+	return iof
+}
+
+// TableIOErrorDialog shows given export / import error in a dialog, one
+// error per line
+func TableIOErrorDialog(vp *gi.Viewport2D, title string, err error) {
+	gi.PromptDialog(vp, gi.DlgOpts{Title: title, Prompt: strings.Replace(err.Error(), "\n", "<br>\n", -1)}, true, false, nil, nil)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Columns and values
+
+// tableIOCol is one column of exported / imported data: a struct field, or
+// the elements themselves for a slice of non-structs
+type tableIOCol struct {
+	name  string
+	label string
+	field bool
+}
+
+// tableIOCols returns the columns for given fields -- a single Value column
+// for the elements themselves if fields is nil -- the label of a column is
+// the label tag of its field, as shown in a StructView, or its name
+func tableIOCols(fields []reflect.StructField) []tableIOCol {
+	if fields == nil {
+		return []tableIOCol{{name: "Value", label: "Value"}}
+	}
+	cols := make([]tableIOCol, len(fields))
+	for i, fld := range fields {
+		lbl := fld.Tag.Get("label")
+		if lbl == "" {
+			lbl = fld.Name
+		}
+		cols[i] = tableIOCol{name: fld.Name, label: lbl, field: true}
+	}
+	return cols
+}
+
+// tableIOColByName returns the index of the column with given label or
+// field name, ignoring case, -1 if not found
+func tableIOColByName(cols []tableIOCol, nm string) int {
+	nm = strings.TrimSpace(nm)
+	for ci, c := range cols {
+		if strings.EqualFold(c.label, nm) || strings.EqualFold(c.name, nm) {
+			return ci
+		}
+	}
+	return -1
+}
+
+// value returns the value of the column for given element (pointers are
+// followed) -- invalid for a nil element
+func (tc *tableIOCol) value(el reflect.Value) reflect.Value {
+	el = kit.NonPtrValue(el)
+	if !tc.field || !el.IsValid() {
+		return el
+	}
+	return el.FieldByName(tc.name)
+}
+
+// tableIOString returns the string for exporting given value, using its
+// MarshalText method if it has one
+func tableIOString(v reflect.Value) string {
+	if !v.IsValid() || !v.CanInterface() {
+		return ""
+	}
+	if tm, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if b, err := tm.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return kit.ToString(v.Interface())
+}
+
+// tableIOSetString sets given (addressable) value from an imported string,
+// with conversion to the type of the value through the enum registry, its
+// UnmarshalText method, or kit.SetRobust -- an empty string sets the zero
+// value
+func tableIOSetString(v reflect.Value, s string) error {
+	ptr := v.Addr().Interface()
+	if v.Kind() >= reflect.Int && v.Kind() <= reflect.Uint64 && kit.Enums.Enum(kit.FullTypeName(v.Type())) != nil {
+		return kit.Enums.SetAnyEnumIfaceFromString(ptr, strings.TrimSpace(s))
+	}
+	if tu, ok := ptr.(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(s))
+	}
+	if v.Kind() == reflect.String {
+		v.SetString(s)
+		return nil
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if !kit.SetRobust(ptr, s) {
+		return fmt.Errorf("could not convert to type: %v", v.Type())
+	}
+	return nil
+}
+
+// tableIOSetJSON sets given (addressable) value from an imported JSON value
+// -- JSON strings that do not decode directly as the type of the value are
+// converted as in tableIOSetString, e.g., "12" for an int
+func tableIOSetJSON(v reflect.Value, raw json.RawMessage) error {
+	err := json.Unmarshal(raw, v.Addr().Interface())
+	if err == nil {
+		return nil
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return tableIOSetString(v, s)
+	}
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Export
+
+// SliceExportCSV writes the given rows of a slice (a pointer to it) in CSV
+// format with given delimiter (e.g., ',' or '\t'), with a header row of the
+// column labels (the label tag of each field, or its name) -- the columns are given struct fields of the elements, or
+// a single Value column with the elements themselves if fields is nil -- all
+// rows are written, in order, if rows is nil
+func SliceExportCSV(w io.Writer, slice interface{}, fields []reflect.StructField, rows []int, delim rune) error {
+	mvnp := kit.NonPtrValue(reflect.ValueOf(slice))
+	cols := tableIOCols(fields)
+	cw := csv.NewWriter(w)
+	cw.Comma = delim
+	rec := make([]string, len(cols))
+	for ci, c := range cols {
+		rec[ci] = c.label
+	}
+	if err := cw.Write(rec); err != nil {
+		return err
+	}
+	nrow := mvnp.Len()
+	if rows != nil {
+		nrow = len(rows)
+	}
+	for r := 0; r < nrow; r++ {
+		idx := r
+		if rows != nil {
+			idx = rows[r]
+		}
+		el := mvnp.Index(idx)
+		for ci := range cols {
+			rec[ci] = tableIOString(cols[ci].value(el))
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// SliceExportJSON writes the given rows of a slice (a pointer to it) as a
+// JSON array, with an object for each element with the given struct fields
+// of the elements, in order, keyed by their labels (as in SliceExportCSV),
+// or the elements themselves if fields is nil -- all rows are written, in
+// order, if rows is nil
+func SliceExportJSON(w io.Writer, slice interface{}, fields []reflect.StructField, rows []int) error {
+	mvnp := kit.NonPtrValue(reflect.ValueOf(slice))
+	cols := tableIOCols(fields)
+	var b bytes.Buffer
+	b.WriteString("[")
+	nrow := mvnp.Len()
+	if rows != nil {
+		nrow = len(rows)
+	}
+	for r := 0; r < nrow; r++ {
+		idx := r
+		if rows != nil {
+			idx = rows[r]
+		}
+		if r > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  ")
+		el := mvnp.Index(idx)
+		if fields != nil {
+			b.WriteString("{")
+		}
+		for ci, c := range cols {
+			if ci > 0 {
+				b.WriteString(", ")
+			}
+			if fields != nil {
+				kb, _ := json.Marshal(c.label)
+				b.Write(kb)
+				b.WriteString(": ")
+			}
+			v := c.value(el)
+			if !v.IsValid() || !v.CanInterface() {
+				b.WriteString("null")
+				continue
+			}
+			vb, err := json.Marshal(v.Interface())
+			if err != nil {
+				return fmt.Errorf("giv.SliceExportJSON: row: %v field: %v: %v", idx, c.name, err)
+			}
+			b.Write(vb)
+		}
+		if fields != nil {
+			b.WriteString("}")
+		}
+	}
+	b.WriteString("\n]\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// SliceExportFile writes the given rows of a slice to given file, in the
+// format for its extension (see TableIODelim)
+func SliceExportFile(filename gi.FileName, slice interface{}, fields []reflect.StructField, rows []int) error {
+	fp, err := os.Create(string(filename))
+	if err != nil {
+		return err
+	}
+	if delim := TableIODelim(string(filename)); delim == 0 {
+		err = SliceExportJSON(fp, slice, fields, rows)
+	} else {
+		err = SliceExportCSV(fp, slice, fields, rows, delim)
+	}
+	if cerr := fp.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Import
+
+// tableIOCanImport returns an error if rows cannot be imported into given
+// slice: it must be a pointer to a slice of non-Ki, non-interface elements
+func tableIOCanImport(slice interface{}) error {
+	styp := reflect.TypeOf(slice)
+	if styp == nil || styp.Kind() != reflect.Ptr || styp.Elem().Kind() != reflect.Slice {
+		return errors.New("giv: rows can only be imported into a pointer to a slice")
+	}
+	eltyp := kit.SliceElType(slice)
+	if ki.IsKi(eltyp) || kit.NonPtrType(eltyp).Kind() == reflect.Interface {
+		return fmt.Errorf("giv: rows cannot be imported into a slice of type: %v", styp.Elem())
+	}
+	return nil
+}
+
+// tableIONewElem returns a new element for given slice, and the value that
+// its columns are set in
+func tableIONewElem(slice interface{}) (elem, val reflect.Value) {
+	eltyp := kit.SliceElType(slice)
+	nv := reflect.New(kit.NonPtrType(eltyp))
+	if eltyp.Kind() == reflect.Ptr {
+		return nv, nv.Elem()
+	}
+	return nv.Elem(), nv.Elem()
+}
+
+// tableIOAppend appends given elements to the slice
+func tableIOAppend(slice interface{}, elems []reflect.Value) {
+	if len(elems) == 0 {
+		return
+	}
+	svnp := kit.NonPtrValue(reflect.ValueOf(slice))
+	svnp.Set(reflect.Append(svnp, elems...))
+}
+
+// SliceImportCSV appends rows read in CSV format with given delimiter (e.g.,
+// ',' or '\t') to a slice (a pointer to it) -- the columns are given struct
+// fields of the elements, or the elements themselves if fields is nil (see
+// SliceExportCSV) -- the first row is a header with the labels or field
+// names of the columns (ignoring case), unless none of them match, in which
+// case the columns are taken to be the fields in order -- values are
+// converted to the type of each field, and values that cannot be converted
+// are left at their zero value, and returned as TableIOErrors
+func SliceImportCSV(r io.Reader, slice interface{}, fields []reflect.StructField, delim rune) error {
+	if err := tableIOCanImport(slice); err != nil {
+		return err
+	}
+	cr := csv.NewReader(r)
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1
+	recs, err := cr.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(recs) == 0 {
+		return nil
+	}
+	cols := tableIOCols(fields)
+	var errs TableIOErrors
+	hdr := recs[0]
+	cmap := make([]int, len(hdr))
+	nmatch := 0
+	for fi, h := range hdr {
+		cmap[fi] = tableIOColByName(cols, h)
+		if cmap[fi] >= 0 {
+			nmatch++
+		}
+	}
+	st := 1
+	if nmatch == 0 { // no header
+		st = 0
+		for fi := range cmap {
+			cmap[fi] = fi
+			if fi >= len(cols) {
+				cmap[fi] = -1
+			}
+		}
+	} else {
+		for fi, h := range hdr {
+			if cmap[fi] < 0 {
+				errs = append(errs, &TableIOError{Row: 1, Col: fi + 1, Field: h, Value: h, Err: errors.New("no field with that name -- column ignored")})
+			}
+		}
+	}
+	elems := make([]reflect.Value, 0, len(recs)-st)
+	for ri := st; ri < len(recs); ri++ {
+		elem, val := tableIONewElem(slice)
+		for fi, s := range recs[ri] {
+			if fi >= len(cmap) || cmap[fi] < 0 {
+				continue
+			}
+			c := cols[cmap[fi]]
+			fv := c.value(val)
+			if err := tableIOSetString(fv, s); err != nil {
+				fv.Set(reflect.Zero(fv.Type()))
+				errs = append(errs, &TableIOError{Row: ri + 1, Col: fi + 1, Field: c.name, Value: s, Err: err})
+			}
+		}
+		elems = append(elems, elem)
+	}
+	tableIOAppend(slice, elems)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// SliceImportJSON appends rows read from a JSON array to a slice (a pointer
+// to it) -- each element of the array is an object with the labels or names
+// of the given struct fields of the elements as keys (ignoring case), or the
+// elements themselves if fields is nil (see SliceExportJSON) -- values are
+// converted to the type of each field, and values that cannot be converted
+// are left at their zero value, and returned as TableIOErrors -- elements
+// that are not objects are skipped
+func SliceImportJSON(r io.Reader, slice interface{}, fields []reflect.StructField) error {
+	if err := tableIOCanImport(slice); err != nil {
+		return err
+	}
+	var raws []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raws); err != nil {
+		return err
+	}
+	cols := tableIOCols(fields)
+	var errs TableIOErrors
+	elems := make([]reflect.Value, 0, len(raws))
+	for ri, raw := range raws {
+		elem, val := tableIONewElem(slice)
+		if fields == nil {
+			if err := tableIOSetJSON(val, raw); err != nil {
+				val.Set(reflect.Zero(val.Type()))
+				errs = append(errs, &TableIOError{Row: ri + 1, Col: 1, Field: cols[0].name, Value: string(raw), Err: err})
+			}
+		} else {
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				errs = append(errs, &TableIOError{Row: ri + 1, Value: string(raw), Err: err})
+				continue
+			}
+			for key, fraw := range obj {
+				ci := tableIOColByName(cols, key)
+				if ci < 0 {
+					continue
+				}
+				fv := cols[ci].value(val)
+				if err := tableIOSetJSON(fv, fraw); err != nil {
+					fv.Set(reflect.Zero(fv.Type()))
+					errs = append(errs, &TableIOError{Row: ri + 1, Col: ci + 1, Field: cols[ci].name, Value: string(fraw), Err: err})
+				}
+			}
+		}
+		elems = append(elems, elem)
+	}
+	tableIOAppend(slice, elems)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// SliceImportFile appends rows read from given file to a slice, in the
+// format for its extension (see TableIODelim)
+func SliceImportFile(filename gi.FileName, slice interface{}, fields []reflect.StructField) error {
+	fp, err := os.Open(string(filename))
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	if delim := TableIODelim(string(filename)); delim != 0 {
+		return SliceImportCSV(fp, slice, fields, delim)
+	}
+	return SliceImportJSON(fp, slice, fields)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  TableView
+
+// ExportFields returns the fields that are exported: the visible fields, in
+// display order, except for those with a view:"-" tag -- hidden columns are
+// not exported, just as rows that do not pass the filters are not: the
+// export is of what is shown
+func (tv *TableView) ExportFields() []reflect.StructField {
+	if tv.VisFields == nil {
+		tv.CacheVisFields()
+	}
+	return TableIOFields(tv.VisFields)
+}
+
+// ImportFields returns the fields that are imported: the ExportFields,
+// followed by the fields of the hidden columns -- so columns for hidden
+// fields are imported too, e.g., from a file exported before hiding them,
+// while a file without a header has the columns of ExportFields
+func (tv *TableView) ImportFields() []reflect.StructField {
+	flds := tv.ExportFields()
+	for _, fld := range TableIOFields(tv.AllFields) {
+		if tv.ColState.IsHidden(fld.Name) {
+			flds = append(flds, fld)
+		}
+	}
+	return flds
+}
+
+// ExportCSV writes the rows shown (passing the filters, in the current sort
+// order) in CSV format with given delimiter (e.g., ',' or '\t') -- see
+// SliceExportCSV
+func (tv *TableView) ExportCSV(w io.Writer, delim rune) error {
+	return SliceExportCSV(w, tv.Slice, tv.ExportFields(), tv.ViewIdxs, delim)
+}
+
+// ExportJSON writes the rows shown (passing the filters, in the current sort
+// order) as a JSON array -- see SliceExportJSON
+func (tv *TableView) ExportJSON(w io.Writer) error {
+	return SliceExportJSON(w, tv.Slice, tv.ExportFields(), tv.ViewIdxs)
+}
+
+// ExportFile writes the rows shown to given file, in the format for its
+// extension: .csv, .tsv or .json
+func (tv *TableView) ExportFile(filename gi.FileName) error {
+	return SliceExportFile(filename, tv.Slice, tv.ExportFields(), tv.ViewIdxs)
+}
+
+// ImportCSV appends rows read in CSV format with given delimiter to the
+// slice, and updates the display -- returns TableIOErrors for values that
+// could not be parsed (see SliceImportCSV)
+func (tv *TableView) ImportCSV(r io.Reader, delim rune) error {
+	err := SliceImportCSV(r, tv.Slice, tv.ImportFields(), delim)
+	tv.ImportUpdate()
+	return err
+}
+
+// ImportJSON appends rows read from a JSON array to the slice, and updates
+// the display -- returns TableIOErrors for values that could not be parsed
+// (see SliceImportJSON)
+func (tv *TableView) ImportJSON(r io.Reader) error {
+	err := SliceImportJSON(r, tv.Slice, tv.ImportFields())
+	tv.ImportUpdate()
+	return err
+}
+
+// ImportFile appends rows read from given file to the slice, in the format
+// for its extension: .csv, .tsv or .json
+func (tv *TableView) ImportFile(filename gi.FileName) error {
+	err := SliceImportFile(filename, tv.Slice, tv.ImportFields())
+	tv.ImportUpdate()
+	return err
+}
+
+// ImportUpdate updates the display after importing rows
+func (tv *TableView) ImportUpdate() {
+	if kit.IfaceIsNil(tv.Slice) || kit.NonPtrValue(reflect.ValueOf(tv.Slice)).Len() == tv.BuiltSize {
+		return
+	}
+	updt := tv.UpdateStart()
+	defer tv.UpdateEnd(updt)
+	if tv.TmpSave != nil {
+		tv.TmpSave.SaveTmp()
+	}
+	tv.SetChanged()
+	tv.ConfigSliceGrid(true)
+}
+
+// ExportDialog opens a dialog to choose a file to export the rows shown to
+func (tv *TableView) ExportDialog() {
+	FileViewDialog(tv.Viewport, "", TableIOExts, DlgOpts{Title: "Export", Prompt: "Export the rows shown to a .csv, .tsv or .json file"}, nil,
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			fn := FileViewDialogValue(send.(*gi.Dialog))
+			if err := tvv.ExportFile(gi.FileName(fn)); err != nil {
+				TableIOErrorDialog(tvv.Viewport, "Export Error", err)
+			}
+		})
+}
+
+// ImportDialog opens a dialog to choose a file to import rows from
+func (tv *TableView) ImportDialog() {
+	FileViewDialog(tv.Viewport, "", TableIOExts, DlgOpts{Title: "Import", Prompt: "Add the rows from a .csv, .tsv or .json file, with the field names in the header row or as keys"}, nil,
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			fn := FileViewDialogValue(send.(*gi.Dialog))
+			if err := tvv.ImportFile(gi.FileName(fn)); err != nil {
+				TableIOErrorDialog(tvv.Viewport, "Import Errors", err)
+			}
+		})
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  SliceView
+
+// ExportCSV writes the elements in CSV format with given delimiter (e.g.,
+// ',' or '\t'), as a single Value column -- see SliceExportCSV
+func (sv *SliceView) ExportCSV(w io.Writer, delim rune) error {
+	return SliceExportCSV(w, sv.Slice, nil, nil, delim)
+}
+
+// ExportJSON writes the elements as a JSON array -- see SliceExportJSON
+func (sv *SliceView) ExportJSON(w io.Writer) error {
+	return SliceExportJSON(w, sv.Slice, nil, nil)
+}
+
+// ExportFile writes the elements to given file, in the format for its
+// extension: .csv, .tsv or .json
+func (sv *SliceView) ExportFile(filename gi.FileName) error {
+	return SliceExportFile(filename, sv.Slice, nil, nil)
+}
+
+// ImportCSV appends elements read in CSV format with given delimiter to the
+// slice, and updates the display -- returns TableIOErrors for elements that
+// could not be parsed (see SliceImportCSV)
+func (sv *SliceView) ImportCSV(r io.Reader, delim rune) error {
+	err := SliceImportCSV(r, sv.Slice, nil, delim)
+	sv.ImportUpdate()
+	return err
+}
+
+// ImportJSON appends elements read from a JSON array to the slice, and
+// updates the display -- returns TableIOErrors for elements that could not
+// be parsed (see SliceImportJSON)
+func (sv *SliceView) ImportJSON(r io.Reader) error {
+	err := SliceImportJSON(r, sv.Slice, nil)
+	sv.ImportUpdate()
+	return err
+}
+
+// ImportFile appends elements read from given file to the slice, in the
+// format for its extension: .csv, .tsv or .json
+func (sv *SliceView) ImportFile(filename gi.FileName) error {
+	err := SliceImportFile(filename, sv.Slice, nil)
+	sv.ImportUpdate()
+	return err
+}
+
+// ImportUpdate updates the display after importing elements
+func (sv *SliceView) ImportUpdate() {
+	if kit.IfaceIsNil(sv.Slice) || kit.NonPtrValue(reflect.ValueOf(sv.Slice)).Len() == sv.BuiltSize {
+		return
+	}
+	updt := sv.UpdateStart()
+	defer sv.UpdateEnd(updt)
+	if sv.TmpSave != nil {
+		sv.TmpSave.SaveTmp()
+	}
+	sv.SetChanged()
+	sv.ConfigSliceGrid(true)
+}
+
+// ExportDialog opens a dialog to choose a file to export the elements to
+func (sv *SliceView) ExportDialog() {
+	FileViewDialog(sv.Viewport, "", TableIOExts, DlgOpts{Title: "Export", Prompt: "Export the elements to a .csv, .tsv or .json file"}, nil,
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			svv := recv.Embed(KiT_SliceView).(*SliceView)
+			fn := FileViewDialogValue(send.(*gi.Dialog))
+			if err := svv.ExportFile(gi.FileName(fn)); err != nil {
+				TableIOErrorDialog(svv.Viewport, "Export Error", err)
+			}
+		})
+}
+
+// ImportDialog opens a dialog to choose a file to import elements from
+func (sv *SliceView) ImportDialog() {
+	FileViewDialog(sv.Viewport, "", TableIOExts, DlgOpts{Title: "Import", Prompt: "Add the elements from a .csv, .tsv or .json file"}, nil,
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			svv := recv.Embed(KiT_SliceView).(*SliceView)
+			fn := FileViewDialogValue(send.(*gi.Dialog))
+			if err := svv.ImportFile(gi.FileName(fn)); err != nil {
+				TableIOErrorDialog(svv.Viewport, "Import Errors", err)
+			}
+		})
+}
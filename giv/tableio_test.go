@@ -0,0 +1,104 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTableIOFields(t *testing.T) {
+	flds := TableIOFields(testRowFields())
+	if testFieldNames(flds) != "Name,Size,Val,On" {
+		t.Errorf("fields: %v", testFieldNames(flds))
+	}
+	cols := tableIOCols(flds)
+	if cols[0].label != "Full Name" || cols[1].label != "Size" {
+		t.Errorf("column labels: %v", cols)
+	}
+	if tableIOColByName(cols, " full name") != 0 || tableIOColByName(cols, "name") != 0 || tableIOColByName(cols, "Notes") != -1 {
+		t.Errorf("column by label or name")
+	}
+}
+
+func TestTableIORoundTrip(t *testing.T) {
+	sl := []testRow{{Name: "Ann, Jr.", Size: 30, Val: 1.5, On: true, Notes: "n1"}, {Name: "Bob", Size: 41, Val: -2}, {Name: "Cy \"C\"", Notes: "n3"}}
+	flds := TableIOFields(testRowFields())
+	for _, delim := range []rune{',', '\t', 0} {
+		var b bytes.Buffer
+		var err error
+		if delim == 0 {
+			err = SliceExportJSON(&b, &sl, flds, []int{2, 0})
+		} else {
+			err = SliceExportCSV(&b, &sl, flds, []int{2, 0}, delim)
+		}
+		if err != nil {
+			t.Fatalf("export %q: %v", delim, err)
+		}
+		if !strings.Contains(b.String(), "Full Name") || strings.Contains(b.String(), "Notes") {
+			t.Errorf("export %q header: %v", delim, b.String())
+		}
+		var isl []*testRow
+		if delim == 0 {
+			err = SliceImportJSON(&b, &isl, flds)
+		} else {
+			err = SliceImportCSV(&b, &isl, flds, delim)
+		}
+		if err != nil {
+			t.Fatalf("import %q: %v", delim, err)
+		}
+		want := []testRow{{Name: "Cy \"C\""}, {Name: "Ann, Jr.", Size: 30, Val: 1.5, On: true}}
+		if len(isl) != len(want) || *isl[0] != want[0] || *isl[1] != want[1] {
+			t.Errorf("import %q: %v", delim, isl)
+		}
+	}
+}
+
+func TestTableIOImportErrors(t *testing.T) {
+	flds := TableIOFields(testRowFields())
+	var sl []testRow
+	csv := "Full Name,Size,Bogus,Val\nAnn,x,1,1.5\nBob,41,2,2\n"
+	err := SliceImportCSV(strings.NewReader(csv), &sl, flds, ',')
+	errs, ok := err.(TableIOErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("csv errors: %v", err)
+	}
+	if errs[0].Row != 1 || errs[0].Col != 3 || errs[1].Row != 2 || errs[1].Col != 2 || errs[1].Field != "Size" {
+		t.Errorf("csv errors: %v", errs)
+	}
+	if len(sl) != 2 || sl[0] != (testRow{Name: "Ann", Val: 1.5}) || sl[1] != (testRow{Name: "Bob", Size: 41, Val: 2}) {
+		t.Errorf("csv rows with a bad cell: %v", sl)
+	}
+
+	sl = nil
+	js := `[{"Full Name": "Ann", "Size": "x", "Val": 1.5}, {"name": "Bob", "size": "41"}, 3]`
+	err = SliceImportJSON(strings.NewReader(js), &sl, flds)
+	if errs, ok = err.(TableIOErrors); !ok || len(errs) != 2 {
+		t.Fatalf("json errors: %v", err)
+	}
+	if errs[0].Row != 1 || errs[0].Col != 2 || errs[0].Field != "Size" || errs[1].Row != 3 {
+		t.Errorf("json errors: %v", errs)
+	}
+	if len(sl) != 2 || sl[0] != (testRow{Name: "Ann", Val: 1.5}) || sl[1] != (testRow{Name: "Bob", Size: 41}) {
+		t.Errorf("json rows with a bad value: %v", sl)
+	}
+}
+
+func TestTableIOValues(t *testing.T) {
+	sl := []float32{1.5, 2}
+	var b bytes.Buffer
+	if err := SliceExportCSV(&b, &sl, nil, nil, ','); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "Value\n1.5\n2\n" {
+		t.Errorf("values csv: %q", b.String())
+	}
+	var isl []float32
+	if err := SliceImportCSV(&b, &isl, nil, ','); err != nil || !reflect.DeepEqual(isl, sl) {
+		t.Errorf("values import: %v %v", isl, err)
+	}
+}
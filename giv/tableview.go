@@ -27,7 +27,7 @@ import (
 )
 
 // todo:
-// * search option, both as a search field and as simple type-to-search
+// * simple type-to-search
 // * popup menu option -- when user does right-mouse on item, a provided func is called
 //   -- use in fileview
 // * could have a native context menu for add / delete etc.
@@ -37,6 +37,15 @@ import (
 // cursor will be displayed while updating the table
 var TableViewWaitCursorSize = 5000
 
+// TableViewVirtualSize is the length of the slice above which the table
+// automatically switches to Virtual mode, where widgets are only built for
+// the visible rows -- the 'virtual' property (bool) overrides this
+var TableViewVirtualSize = 2000
+
+// TableViewVirtualBuffer is the number of rows of widgets built in Virtual
+// mode beyond those needed to fill the window
+var TableViewVirtualBuffer = 4
+
 // TableView represents a slice-of-structs as a table, where the fields are
 // the columns, within an overall frame.  It has two modes, determined by
 // Inactive flag: if Inactive, it functions as a mutually-exclusive item
@@ -44,7 +53,12 @@ var TableViewWaitCursorSize = 5000
 // WidgetSelected signal, and TableViewDoubleClick for double clicks (can be
 // used for closing dialogs).  If !Inactive, it is a full-featured editor with
 // multiple-selection, cut-and-paste, and drag-and-drop, reporting each action
-// taken using the TableViewSig signals
+// taken using the TableViewSig signals.  For large slices, it switches to a
+// Virtual mode that only builds widgets for the visible rows, and reuses
+// them for other rows as the table is scrolled.  Rows can be sorted by
+// multiple columns (shift-click on the headers), which sorts the slice
+// itself, and filtered per column, which only affects the view -- all row
+// indexes (SelectedIdx, SelectedRows, signals) are slice indexes.
 type TableView struct {
 	gi.Frame
 	Slice            interface{}        `view:"-" json:"-" xml:"-" desc:"the slice that we are a view onto -- must be a pointer to that slice"`
@@ -56,11 +70,20 @@ type TableView struct {
 	ShowIndex        bool               `xml:"index" desc:"whether to show index or not (default true) -- updated from 'index' property (bool)"`
 	InactKeyNav      bool               `xml:"inact-key-nav" desc:"support key navigation when inactive (default true) -- updated from 'intact-key-nav' property (bool) -- no focus really plausible in inactive case, so it uses a low-pri capture of up / down events"`
 	VisRows          int                `desc:"number of rows visible in display"`
+	Virtual          bool               `xml:"virtual" desc:"only build widgets for the rows visible in the window (plus TableViewVirtualBuffer), and reuse them for other rows as the table is scrolled -- set automatically for slices longer than TableViewVirtualSize, or from the 'virtual' property (bool)"`
+	StartIdx         int                `json:"-" xml:"-" desc:"slice index of the first row that has widgets -- always 0 unless Virtual"`
+	VirtRows         int                `json:"-" xml:"-" desc:"number of rows that have widgets -- the full slice length unless Virtual"`
 	SelField         string             `view:"-" json:"-" xml:"-" desc:"current selection field -- initially select value in this field"`
 	SelVal           interface{}        `view:"-" json:"-" xml:"-" desc:"current selection value -- initially select this value in SelField"`
 	SelectedIdx      int                `json:"-" xml:"-" desc:"index (row) of currently-selected item (-1 if none) -- see SelectedRows for full set of selected rows in active editing mode"`
-	SortIdx          int                `desc:"current sort index"`
-	SortDesc         bool               `desc:"whether current sort order is descending"`
+	SortIdx          int                `desc:"current sort index -- the visible field index of the first of the SortKeys"`
+	SortDesc         bool               `desc:"whether current sort order is descending -- for the first of the SortKeys"`
+	SortKeys         []TableViewSortKey `desc:"current sort keys, in order of precedence -- see SetSortKeys"`
+	Filters          map[string]string  `desc:"current filter expressions, by field name -- see SetFilter and TableViewFilter for the syntax"`
+	ShowFilter       bool               `xml:"filter" desc:"whether to show the filter bar below the header (default false) -- updated from 'filter' property (bool)"`
+	ShowTools        bool               `xml:"tools" desc:"whether to show the Filter, Export and Import actions in the toolbar (default false) -- updated from 'tools' property (bool)"`
+	ViewIdxs         []int              `view:"-" json:"-" xml:"-" desc:"slice indexes of the rows in the view, which pass the Filters, in the order of the SortKeys -- nil if there are no filters or sort keys, in which case all rows are shown, in slice order"`
+	ColState         TableViewCols      `desc:"layout of the columns: their order, widths, and which are hidden -- restored from TVColsPrefs for the struct type, and saved there when changed by the user, unless the 'col-prefs' property is false"`
 	SelectMode       bool               `desc:"editing-mode select rows mode"`
 	SelectedRows     map[int]bool       `desc:"list of currently-selected rows"`
 	DraggedRows      []int              `desc:"list of currently-dragged rows"`
@@ -73,9 +96,12 @@ type TableView struct {
 	ToolbarSlice interface{} `desc:"the slice that we successfully set a toolbar for"`
 	StruType     reflect.Type
 	NVisFields   int
-	VisFields    []reflect.StructField `view:"-" json:"-" xml:"-" desc:"the visible fields"`
+	VisFields    []reflect.StructField `view:"-" json:"-" xml:"-" desc:"the visible fields, in the order of the ColState"`
+	AllFields    []reflect.StructField `view:"-" json:"-" xml:"-" desc:"all the fields that can be shown, including those hidden in the ColState, in the order of the struct"`
 	inFocusGrab  bool
-	curRow       int // temp row variable used e.g., in Drop method
+	curRow       int              // temp row variable used e.g., in Drop method
+	viewRows     []int            // view row for each slice index, -1 if filtered out -- nil if ViewIdxs is nil
+	colDrag      tableViewColDrag // state of a drag on the header
 }
 
 var KiT_TableView = kit.Types.AddType(&TableView{}, TableViewProps)
@@ -100,6 +126,9 @@ func (tv *TableView) SetSlice(sl interface{}, tmpSave ValueView) {
 		}
 		tv.SortIdx = -1
 		tv.SortDesc = false
+		tv.SortKeys = nil
+		tv.Filters = nil
+		tv.StartIdx = 0
 		slpTyp := reflect.TypeOf(sl)
 		if slpTyp.Kind() != reflect.Ptr {
 			log.Printf("TableView requires that you pass a pointer to a slice of struct elements -- type is not a Ptr: %v\n", slpTyp.String())
@@ -118,6 +147,7 @@ func (tv *TableView) SetSlice(sl interface{}, tmpSave ValueView) {
 		updt = tv.UpdateStart()
 		tv.SelectedRows = make(map[int]bool, 10)
 		tv.SelectMode = false
+		tv.LoadColState()
 		tv.SetFullReRender()
 	}
 	tv.ShowIndex = true
@@ -128,6 +158,12 @@ func (tv *TableView) SetSlice(sl interface{}, tmpSave ValueView) {
 	if siknp, ok := tv.Prop("inact-key-nav"); ok {
 		tv.InactKeyNav, _ = kit.ToBool(siknp)
 	}
+	if sfp, ok := tv.Prop("filter"); ok {
+		tv.ShowFilter, _ = kit.ToBool(sfp)
+	}
+	if stp, ok := tv.Prop("tools"); ok {
+		tv.ShowTools, _ = kit.ToBool(stp)
+	}
 	tv.TmpSave = tmpSave
 	tv.UpdateFromSlice()
 	tv.UpdateEnd(updt)
@@ -173,7 +209,9 @@ func (tv *TableView) UpdateValues() {
 	updt := tv.UpdateStart()
 	for _, vv := range tv.Values {
 		for _, vvf := range vv {
-			vvf.UpdateWidget()
+			if vvf != nil { // nil for blank rows past the end in Virtual mode
+				vvf.UpdateWidget()
+			}
 		}
 	}
 	tv.UpdateEnd(updt)
@@ -190,7 +228,7 @@ func (tv *TableView) StructType() reflect.Type {
 // caches those to skip in fieldSkip
 func (tv *TableView) CacheVisFields() {
 	styp := tv.StructType()
-	tv.VisFields = make([]reflect.StructField, 0, 20)
+	tv.AllFields = make([]reflect.StructField, 0, 20)
 	kit.FlatFieldsTypeFunc(styp, func(typ reflect.Type, fld reflect.StructField) bool {
 		tvtag := fld.Tag.Get("tableview")
 		add := true
@@ -204,10 +242,11 @@ func (tv *TableView) CacheVisFields() {
 			}
 		}
 		if add {
-			tv.VisFields = append(tv.VisFields, fld)
+			tv.AllFields = append(tv.AllFields, fld)
 		}
 		return true
 	})
+	tv.VisFields = tv.ColState.VisFields(tv.AllFields)
 	tv.NVisFields = len(tv.VisFields)
 }
 
@@ -241,13 +280,39 @@ func (tv *TableView) SliceFrame() (*gi.Frame, int) {
 }
 
 // SliceGrid returns the SliceGrid grid frame widget, which contains all the
-// fields and values, within SliceFrame
+// fields and values, within SliceFrame -- in Virtual mode it is within a
+// layout together with the VScroll scrollbar
 func (tv *TableView) SliceGrid() *gi.Frame {
 	sf, _ := tv.SliceFrame()
 	if sf == nil {
 		return nil
 	}
-	return sf.KnownChild(1).(*gi.Frame)
+	if gk, ok := sf.ChildByName("grid", 1); ok {
+		return gk.(*gi.Frame)
+	}
+	if glk, ok := sf.ChildByName("grid-lay", 1); ok {
+		gl := glk.(*gi.Layout)
+		if len(gl.Kids) > 0 {
+			return gl.KnownChild(0).(*gi.Frame)
+		}
+	}
+	return nil
+}
+
+// VScroll returns the vertical scrollbar used for scrolling the rows in
+// Virtual mode -- nil if not Virtual
+func (tv *TableView) VScroll() *gi.ScrollBar {
+	sf, _ := tv.SliceFrame()
+	if sf == nil {
+		return nil
+	}
+	if glk, ok := sf.ChildByName("grid-lay", 1); ok {
+		gl := glk.(*gi.Layout)
+		if len(gl.Kids) > 1 {
+			return gl.KnownChild(1).(*gi.ScrollBar)
+		}
+	}
+	return nil
 }
 
 // SliceHeader returns the Toolbar header for slice grid
@@ -259,6 +324,19 @@ func (tv *TableView) SliceHeader() *gi.ToolBar {
 	return sf.KnownChild(0).(*gi.ToolBar)
 }
 
+// FilterBar returns the filter bar below the header, with a text field for
+// the filter expression of each field -- nil if not ShowFilter
+func (tv *TableView) FilterBar() *gi.ToolBar {
+	sf, _ := tv.SliceFrame()
+	if sf == nil {
+		return nil
+	}
+	if fbk, ok := sf.ChildByName("filter-bar", 1); ok {
+		return fbk.(*gi.ToolBar)
+	}
+	return nil
+}
+
 // ToolBar returns the toolbar widget
 func (tv *TableView) ToolBar() *gi.ToolBar {
 	idx, ok := tv.Children().IndexByName("toolbar", 0)
@@ -272,7 +350,23 @@ func (tv *TableView) ToolBar() *gi.ToolBar {
 func (tv *TableView) StdSliceFrameConfig() kit.TypeAndNameList {
 	config := kit.TypeAndNameList{}
 	config.Add(gi.KiT_ToolBar, "header")
+	if tv.ShowFilter {
+		config.Add(gi.KiT_ToolBar, "filter-bar")
+	}
+	if tv.Virtual {
+		config.Add(gi.KiT_Layout, "grid-lay")
+	} else {
+		config.Add(gi.KiT_Frame, "grid")
+	}
+	return config
+}
+
+// StdGridLayConfig returns a TypeAndNameList for configuring the grid-lay
+// layout used in Virtual mode, with the grid and its vertical scrollbar
+func (tv *TableView) StdGridLayConfig() kit.TypeAndNameList {
+	config := kit.TypeAndNameList{}
 	config.Add(gi.KiT_Frame, "grid")
+	config.Add(gi.KiT_ScrollBar, "vscroll")
 	return config
 }
 
@@ -290,6 +384,32 @@ func (tv *TableView) RowWidgetNs() (nWidgPerRow, idxOff int) {
 	return
 }
 
+// RowHeight returns the estimated height of a row, in dots, used for
+// determining the number of rows to build in Virtual mode
+func (tv *TableView) RowHeight() int {
+	if tv.Sty.Font.Height > 0 {
+		return int(1.8 * tv.Sty.Font.Height)
+	}
+	return 24
+}
+
+// VirtRowsN returns the number of rows to build for a slice of given size in
+// Virtual mode: enough to fill the window, plus TableViewVirtualBuffer
+func (tv *TableView) VirtRowsN(sz int) int {
+	nr := 30
+	if win := tv.ParentWindow(); win != nil && win.Viewport.Geom.Size.Y > 0 {
+		nr = win.Viewport.Geom.Size.Y / tv.RowHeight()
+	}
+	return ints.MinInt(sz, nr+TableViewVirtualBuffer)
+}
+
+// MaxStartIdx returns the largest StartIdx for Virtual mode, at which the
+// last row of the view is the last visible row
+func (tv *TableView) MaxStartIdx() int {
+	vis := ints.MinInt(ints.MaxInt(tv.VisRows, 1), tv.VirtRows)
+	return ints.MaxInt(0, tv.ViewSize()-vis)
+}
+
 // ConfigSliceGrid configures the SliceGrid for the current slice
 func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 	if kit.IfaceIsNil(tv.Slice) {
@@ -306,13 +426,34 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 	tv.BuiltSize = sz
 
 	tv.CacheVisFields()
+	tv.FilterSlice()
+	tv.SortSlice()
+	nview := tv.ViewSize()
+
+	tv.Virtual = nview > TableViewVirtualSize
+	if vp, ok := tv.Prop("virtual"); ok {
+		tv.Virtual, _ = kit.ToBool(vp)
+	}
+	if tv.Virtual {
+		tv.VirtRows = tv.VirtRowsN(nview)
+		if tv.SelField != "" && tv.SelVal != nil {
+			tv.SelectedIdx, _ = StructSliceRowByValue(tv.Slice, tv.SelField, tv.SelVal)
+		}
+		if vr, ok := tv.ViewRow(tv.SelectedIdx); ok && (vr < tv.StartIdx || vr >= tv.StartIdx+tv.VisRows) {
+			tv.StartIdx = vr
+		}
+		tv.StartIdx = ints.MaxInt(0, ints.MinInt(tv.StartIdx, tv.MaxStartIdx()))
+	} else {
+		tv.VirtRows = nview
+		tv.StartIdx = 0
+	}
 
 	nWidgPerRow, idxOff := tv.RowWidgetNs()
 
 	// always start fresh!
 	tv.Values = make([][]ValueView, tv.NVisFields)
 	for fli := 0; fli < tv.NVisFields; fli++ {
-		tv.Values[fli] = make([]ValueView, sz)
+		tv.Values[fli] = make([]ValueView, tv.VirtRows)
 	}
 
 	sg, _ := tv.SliceFrame()
@@ -324,7 +465,7 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 	sg.SetStretchMaxHeight() // for this to work, ALL layers above need it too
 	sg.SetStretchMaxWidth()  // for this to work, ALL layers above need it too
 
-	if sz > TableViewWaitCursorSize {
+	if !tv.Virtual && sz > TableViewWaitCursorSize {
 		oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Push(cursor.Wait)
 		defer oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Pop()
 	}
@@ -343,6 +484,18 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 	sgh.SetProp("spacing", 0)
 	// sgh.SetStretchMaxWidth()
 
+	if tv.Virtual {
+		gl := sg.KnownChild(1).(*gi.Layout)
+		gl.Lay = gi.LayoutHoriz
+		gl.SetProp("overflow", "hidden") // rows beyond the visible ones are clipped
+		gl.SetProp("spacing", 0)
+		// a pref here keeps the built rows from determining our size
+		gl.SetMinPrefHeight(units.NewValue(10, units.Em))
+		gl.SetStretchMaxHeight()
+		gl.SetStretchMaxWidth()
+		gl.ConfigChildren(tv.StdGridLayConfig(), false)
+	}
+
 	sgf := tv.SliceGrid()
 	sgf.Lay = gi.LayoutGrid
 	sgf.Stripes = gi.RowStripes
@@ -352,6 +505,10 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 	sgf.SetStretchMaxHeight() // for this to work, ALL layers above need it too
 	sgf.SetStretchMaxWidth()  // for this to work, ALL layers above need it too
 	sgf.SetProp("columns", nWidgPerRow)
+	if tv.Virtual {
+		sgf.SetProp("overflow", "hidden") // VScroll does the scrolling
+		tv.ConfigVScroll()
+	}
 
 	// Configure Header
 	hcfg := kit.TypeAndNameList{}
@@ -381,16 +538,8 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 	for fli := 0; fli < tv.NVisFields; fli++ {
 		fld := tv.VisFields[fli]
 		hdr := sgh.KnownChild(idxOff + fli).(*gi.Action)
-		hdr.SetText(fld.Name)
-		if fli == tv.SortIdx {
-			if tv.SortDesc {
-				hdr.SetIcon("widget-wedge-down")
-			} else {
-				hdr.SetIcon("widget-wedge-up")
-			}
-		}
 		hdr.Data = fli
-		hdr.Tooltip = "click to sort / toggle sort direction by this column"
+		hdr.Tooltip = "click to sort / toggle sort direction by this column, shift-click to add it as a further sort key -- drag to move the column, drag the right edge to resize it, right-click to show / hide columns"
 		dsc := fld.Tag.Get("desc")
 		if dsc != "" {
 			hdr.Tooltip += ": " + dsc
@@ -399,9 +548,14 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 			tvv := recv.Embed(KiT_TableView).(*TableView)
 			act := send.(*gi.Action)
 			fldIdx := act.Data.(int)
-			tvv.SortSliceAction(fldIdx)
+			if win := tvv.ParentWindow(); win != nil && win.LastSelMode == mouse.ExtendContinuous {
+				tvv.SortSliceAddAction(fldIdx)
+			} else {
+				tvv.SortSliceAction(fldIdx)
+			}
 		})
 	}
+	tv.ConfigHeaderSort()
 	if !tv.IsInactive() {
 		lbl := sgh.KnownChild(tv.NVisFields + idxOff).(*gi.Label)
 		lbl.Text = "+"
@@ -411,13 +565,11 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 		lbl.Tooltip = "delete row"
 	}
 
+	tv.ConfigFilterBar()
+
 	sgf.DeleteChildren(true)
-	sgf.Kids = make(ki.Slice, nWidgPerRow*sz)
+	sgf.Kids = make(ki.Slice, nWidgPerRow*tv.VirtRows)
 
-	if tv.SortIdx >= 0 {
-		rawIdx := tv.VisFields[tv.SortIdx].Index
-		kit.StructSliceSort(tv.Slice, rawIdx, !tv.SortDesc)
-	}
 	tv.ConfigSliceGridRows()
 
 	sg.SetFullReRender()
@@ -427,13 +579,14 @@ func (tv *TableView) ConfigSliceGrid(forceUpdt bool) {
 
 // ConfigSliceGridRows configures the SliceGrid rows for the current slice --
 // assumes .Kids is created at the right size -- only call this for a direct
-// re-render e.g., after sorting
+// re-render e.g., after sorting.  In Virtual mode, the built rows show the
+// slice rows starting at StartIdx, reusing existing widgets where possible.
 func (tv *TableView) ConfigSliceGridRows() {
 	mv := reflect.ValueOf(tv.Slice)
 	mvnp := kit.NonPtrValue(mv)
 	sz := mvnp.Len()
 
-	if sz > TableViewWaitCursorSize {
+	if !tv.Virtual && sz > TableViewWaitCursorSize {
 		oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Push(cursor.Wait)
 		defer oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Pop()
 	}
@@ -444,8 +597,14 @@ func (tv *TableView) ConfigSliceGridRows() {
 	updt := sgf.UpdateStart()
 	defer sgf.UpdateEnd(updt)
 
-	for i := 0; i < sz; i++ {
-		ridx := i * nWidgPerRow
+	nview := tv.ViewSize()
+	for r := 0; r < tv.VirtRows; r++ {
+		ridx := r * nWidgPerRow
+		if tv.StartIdx+r >= nview {
+			tv.ConfigBlankRow(r)
+			continue
+		}
+		i := tv.ViewIdx(tv.StartIdx + r)
 		val := kit.OnePtrValue(mvnp.Index(i)) // deal with pointer lists
 		stru := val.Interface()
 		idxtxt := fmt.Sprintf("%05d", i)
@@ -479,16 +638,17 @@ func (tv *TableView) ConfigSliceGridRows() {
 				continue
 			}
 			vv.SetStructValue(fval.Addr(), stru, &field, tv.TmpSave)
+			tv.Values[fli][r] = vv
 			vtyp := vv.WidgetType()
 			valnm := fmt.Sprintf("value-%v.%v", fli, idxtxt)
 			cidx := ridx + idxOff + fli
 			var widg gi.Node2D
-			if sgf.Kids[cidx] != nil {
+			if sgf.Kids[cidx] != nil && sgf.Kids[cidx].Type() == vtyp {
 				widg = sgf.Kids[cidx].(gi.Node2D)
-			} else {
-				tv.Values[fli][i] = vv
+			} else { // new, or a different type of value in an interface field
 				widg = ki.NewOfType(vtyp).(gi.Node2D)
 				sgf.SetChild(widg, cidx, valnm)
+				sgf.SetFullReRender()
 			}
 			vv.ConfigWidget(widg)
 			wb := widg.AsWidget()
@@ -518,10 +678,17 @@ func (tv *TableView) ConfigSliceGridRows() {
 
 				addnm := fmt.Sprintf("add-%v", idxtxt)
 				delnm := fmt.Sprintf("del-%v", idxtxt)
-				addact := gi.Action{}
-				delact := gi.Action{}
-				sgf.SetChild(&addact, ridx+1+tv.NVisFields, addnm)
-				sgf.SetChild(&delact, ridx+1+tv.NVisFields+1, delnm)
+				aidx := ridx + idxOff + tv.NVisFields
+				addact, ok := sgf.Kids[aidx].(*gi.Action)
+				if !ok {
+					addact = &gi.Action{}
+					sgf.SetChild(addact, aidx, addnm)
+				}
+				delact, ok := sgf.Kids[aidx+1].(*gi.Action)
+				if !ok {
+					delact = &gi.Action{}
+					sgf.SetChild(delact, aidx+1, delnm)
+				}
 
 				addact.SetIcon("plus")
 				addact.Tooltip = "insert a new element at this index"
@@ -540,10 +707,23 @@ func (tv *TableView) ConfigSliceGridRows() {
 					tvv.SliceDelete(act.Data.(int), true)
 				})
 			}
+			if cw := tv.ColWidth(fli); cw > 0 && wb != nil {
+				tv.SetColWidthProps(wb, cw)
+			}
 			if tv.StyleFunc != nil {
 				tv.StyleFunc(tv, mvnp.Interface(), widg, i, fli, vv)
 			}
 		}
+		if tv.Virtual { // widgets may have been showing another row
+			for c := 0; c < nWidgPerRow; c++ {
+				sgf.KnownChild(ridx + c).(gi.Node2D).AsNode2D().ClearInvisible()
+			}
+			if tv.IsInactive() {
+				tv.SelectRowWidgets(i, i == tv.SelectedIdx)
+			} else {
+				tv.SelectRowWidgets(i, tv.RowIsSelected(i))
+			}
+		}
 	}
 	if tv.SelField != "" && tv.SelVal != nil {
 		tv.SelectedIdx, _ = StructSliceRowByValue(tv.Slice, tv.SelField, tv.SelVal)
@@ -553,6 +733,94 @@ func (tv *TableView) ConfigSliceGridRows() {
 	}
 }
 
+// ConfigBlankRow configures given built row (not slice index) as a blank
+// row past the end of the slice, which can happen at the end of the slice
+// in Virtual mode -- its widgets are made invisible, and placeholders are
+// created for any that do not exist yet
+func (tv *TableView) ConfigBlankRow(r int) {
+	nWidgPerRow, _ := tv.RowWidgetNs()
+	sgf := tv.SliceGrid()
+	ridx := r * nWidgPerRow
+	for c := 0; c < nWidgPerRow; c++ {
+		if sgf.Kids[ridx+c] == nil {
+			sgf.SetChild(&gi.Label{}, ridx+c, fmt.Sprintf("blank-%v.%v", r, c))
+		}
+		sgf.KnownChild(ridx + c).(gi.Node2D).AsNode2D().SetInvisible()
+	}
+	for fli := range tv.Values {
+		tv.Values[fli][r] = nil
+	}
+}
+
+// ConfigVScroll configures the vertical scrollbar used in Virtual mode
+func (tv *TableView) ConfigVScroll() {
+	sb := tv.VScroll()
+	if sb == nil {
+		return
+	}
+	sb.Dim = gi.Y
+	sb.Defaults()
+	sb.Tracking = true
+	sb.Min = 0
+	sbw := tv.Sty.Layout.ScrollBarWidth
+	if sbw.Val == 0 { // not yet styled
+		sbw = units.NewValue(16, units.Px)
+	}
+	sb.SetFixedWidth(sbw)
+	sb.SetStretchMaxHeight()
+	sb.SliderSig.ConnectOnly(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(gi.SliderValueChanged) {
+			return
+		}
+		tvv := recv.Embed(KiT_TableView).(*TableView)
+		tvv.SetStartIdx(int(data.(float32) + 0.5))
+	})
+	tv.UpdateVScroll()
+}
+
+// UpdateVScroll updates the range and position of the Virtual mode vertical
+// scrollbar from the current ViewSize, VisRows and StartIdx
+func (tv *TableView) UpdateVScroll() {
+	sb := tv.VScroll()
+	if sb == nil {
+		return
+	}
+	nview := tv.ViewSize()
+	vis := float32(ints.MinInt(ints.MaxInt(tv.VisRows, 1), nview))
+	sb.Max = float32(nview)
+	sb.ThumbVal = vis
+	sb.Step = 1
+	sb.PageStep = vis
+	sb.TrackThr = 1
+	sb.SetValue(float32(tv.StartIdx))
+}
+
+// SetStartIdx sets the view row of the first row shown in Virtual mode,
+// updating the row widgets to show the rows from there
+func (tv *TableView) SetStartIdx(idx int) {
+	if !tv.Virtual {
+		return
+	}
+	idx = ints.MaxInt(0, ints.MinInt(idx, tv.MaxStartIdx()))
+	if idx == tv.StartIdx {
+		return
+	}
+	var win *gi.Window
+	if tv.Viewport != nil {
+		win = tv.Viewport.Win
+	}
+	wupdt := false
+	if win != nil {
+		wupdt = win.UpdateStart()
+	}
+	tv.StartIdx = idx
+	tv.ConfigSliceGridRows()
+	tv.UpdateVScroll()
+	if win != nil {
+		win.UpdateEnd(wupdt)
+	}
+}
+
 // SetChanged sets the Changed flag and emits the ViewSig signal for the
 // TableView, indicating that some kind of edit / change has taken place to
 // the table data.  It isn't really practical to record all the different
@@ -602,53 +870,153 @@ func (tv *TableView) SliceDelete(idx int, reconfig bool) {
 	tv.ViewSig.Emit(tv.This(), 0, nil)
 }
 
-// SortSliceAction sorts the slice for given field index -- toggles ascending
-// vs. descending if already sorting on this dimension
+// SortSliceAction sorts the view by given field index -- toggles ascending
+// vs. descending if already sorting on this dimension -- any other sort keys
+// are removed
 func (tv *TableView) SortSliceAction(fldIdx int) {
-	oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Push(cursor.Wait)
-	defer oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Pop()
+	nm := tv.VisFields[fldIdx].Name
+	desc := false
+	if len(tv.SortKeys) > 0 && tv.SortKeys[0].Field == nm {
+		desc = !tv.SortKeys[0].Desc
+	}
+	tv.SetSortKeys([]TableViewSortKey{{Field: nm, Desc: desc}})
+}
+
+// SortSliceAddAction adds given field index as a further sort key, after
+// the existing ones -- toggles ascending vs. descending if it is already one
+// of the sort keys
+func (tv *TableView) SortSliceAddAction(fldIdx int) {
+	nm := tv.VisFields[fldIdx].Name
+	keys := make([]TableViewSortKey, len(tv.SortKeys))
+	copy(keys, tv.SortKeys)
+	if kidx := tv.SortKeyIdx(nm); kidx >= 0 {
+		keys[kidx].Desc = !keys[kidx].Desc
+	} else {
+		keys = append(keys, TableViewSortKey{Field: nm})
+	}
+	tv.SetSortKeys(keys)
+}
+
+// SetSortKeys sets the sort keys, in order of precedence, and re-sorts the
+// rows in the view (stably) and updates the display if it has already been
+// built -- the slice itself is not changed
+func (tv *TableView) SetSortKeys(keys []TableViewSortKey) {
+	tv.SortKeys = keys
+	tv.SyncSortIdx()
+	if tv.BuiltSlice != tv.Slice || tv.SliceGrid() == nil {
+		return // sorted when built
+	}
+	if tv.Viewport != nil && tv.Viewport.Win != nil {
+		oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Push(cursor.Wait)
+		defer oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Pop()
+	}
 
 	sgh := tv.SliceHeader()
 	sgh.SetFullReRender()
-	idxOff := 1
-	if !tv.ShowIndex {
-		idxOff = 0
+	tv.ConfigHeaderSort()
+
+	sgf := tv.SliceGrid()
+	sgf.SetFullReRender()
+
+	tv.FilterSlice()
+	tv.SortSlice()
+	tv.ConfigSliceGridRows()
+}
+
+// SortKeyIdx returns the index within SortKeys of the key for given field
+// name, -1 if not sorting by that field
+func (tv *TableView) SortKeyIdx(fld string) int {
+	for i, sk := range tv.SortKeys {
+		if sk.Field == fld {
+			return i
+		}
 	}
+	return -1
+}
 
-	ascending := true
+// SyncSortIdx sets SortIdx and SortDesc from the first of the SortKeys
+func (tv *TableView) SyncSortIdx() {
+	tv.SortIdx = -1
+	tv.SortDesc = false
+	if len(tv.SortKeys) == 0 {
+		return
+	}
+	tv.SortDesc = tv.SortKeys[0].Desc
+	for fli := 0; fli < tv.NVisFields; fli++ {
+		if tv.VisFields[fli].Name == tv.SortKeys[0].Field {
+			tv.SortIdx = fli
+		}
+	}
+}
+
+// SortSlice stably sorts the rows in the view, ViewIdxs, by the SortKeys,
+// leaving the slice itself in its original order -- call after FilterSlice
+// -- if there are no SortKeys, but SortIdx has been set, it is used as the
+// sort key
+func (tv *TableView) SortSlice() {
+	if len(tv.SortKeys) == 0 && tv.SortIdx >= 0 && tv.SortIdx < tv.NVisFields {
+		tv.SortKeys = []TableViewSortKey{{Field: tv.VisFields[tv.SortIdx].Name, Desc: tv.SortDesc}}
+	}
+	tv.SyncSortIdx()
+	if len(tv.SortKeys) > 0 && !kit.IfaceIsNil(tv.Slice) {
+		if tv.ViewIdxs == nil {
+			tv.ViewIdxs = make([]int, kit.NonPtrValue(reflect.ValueOf(tv.Slice)).Len())
+			for i := range tv.ViewIdxs {
+				tv.ViewIdxs[i] = i
+			}
+		}
+		StructSliceSortKeys(tv.Slice, tv.ViewIdxs, tv.SortKeys)
+	}
+	tv.viewRows = nil
+	if tv.ViewIdxs == nil {
+		return
+	}
+	tv.viewRows = make([]int, kit.NonPtrValue(reflect.ValueOf(tv.Slice)).Len())
+	for i := range tv.viewRows {
+		tv.viewRows[i] = -1
+	}
+	for vr, idx := range tv.ViewIdxs {
+		tv.viewRows[idx] = vr
+	}
+}
 
+// ConfigHeaderSort updates the header actions to show the SortKeys: the
+// sort direction, and their order if there is more than one
+func (tv *TableView) ConfigHeaderSort() {
+	sgh := tv.SliceHeader()
+	if sgh == nil {
+		return
+	}
+	_, idxOff := tv.RowWidgetNs()
 	for fli := 0; fli < tv.NVisFields; fli++ {
+		fld := tv.VisFields[fli]
+		if !sgh.Kids.IsValidIndex(idxOff + fli) {
+			return
+		}
 		hdr := sgh.KnownChild(idxOff + fli).(*gi.Action)
-		if fli == fldIdx {
-			if tv.SortIdx == fli {
-				tv.SortDesc = !tv.SortDesc
-				ascending = !tv.SortDesc
-			} else {
-				tv.SortDesc = false
-			}
-			if ascending {
-				hdr.SetIcon("widget-wedge-up")
-			} else {
-				hdr.SetIcon("widget-wedge-down")
-			}
-		} else {
+		kidx := tv.SortKeyIdx(fld.Name)
+		switch {
+		case kidx < 0:
 			hdr.SetIcon("none")
+		case tv.SortKeys[kidx].Desc:
+			hdr.SetIcon("widget-wedge-down")
+		default:
+			hdr.SetIcon("widget-wedge-up")
+		}
+		if kidx >= 0 && len(tv.SortKeys) > 1 {
+			hdr.SetText(fmt.Sprintf("%v (%v)", fld.Name, kidx+1))
+		} else {
+			hdr.SetText(fld.Name)
 		}
 	}
-
-	tv.SortIdx = fldIdx
-	rawIdx := tv.VisFields[fldIdx].Index
-
-	sgf := tv.SliceGrid()
-	sgf.SetFullReRender()
-
-	kit.StructSliceSort(tv.Slice, rawIdx, !tv.SortDesc)
-	tv.ConfigSliceGridRows()
 }
 
-// ConfigToolbar configures the toolbar actions
+// ConfigToolbar configures the toolbar actions -- Add is only shown when not
+// inactive, and Filter, Export and Import (when not inactive) only if
+// ShowTools -- these standard actions end with a separator, after which the
+// ToolBarView actions of the slice are added when not inactive
 func (tv *TableView) ConfigToolbar() {
-	if kit.IfaceIsNil(tv.Slice) || tv.IsInactive() {
+	if kit.IfaceIsNil(tv.Slice) || (tv.IsInactive() && !tv.ShowTools) {
 		return
 	}
 	if tv.ToolbarSlice == tv.Slice {
@@ -657,59 +1025,270 @@ func (tv *TableView) ConfigToolbar() {
 	tb := tv.ToolBar()
 	if len(*tb.Children()) == 0 {
 		tb.SetStretchMaxWidth()
-		tb.AddAction(gi.ActOpts{Label: "Add", Icon: "plus"},
-			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-				tvv := recv.Embed(KiT_TableView).(*TableView)
-				tvv.SliceNewAt(-1, true)
-			})
+		if !tv.IsInactive() {
+			tb.AddAction(gi.ActOpts{Label: "Add", Icon: "plus"},
+				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					tvv := recv.Embed(KiT_TableView).(*TableView)
+					tvv.SliceNewAt(-1, true)
+				})
+		}
+		if tv.ShowTools {
+			tb.AddAction(gi.ActOpts{Label: "Filter", Icon: "search", Tooltip: "show / hide the filter bar, for showing only the rows that match the filter for each column"},
+				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					tvv := recv.Embed(KiT_TableView).(*TableView)
+					tvv.SetProp("filter", !tvv.ShowFilter)
+					tvv.ShowFilter = !tvv.ShowFilter
+					tvv.UpdateFromSlice()
+				})
+			tb.AddAction(gi.ActOpts{Label: "Export", Icon: "file-save", Tooltip: "export the rows shown to a .csv, .tsv or .json file"},
+				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					tvv := recv.Embed(KiT_TableView).(*TableView)
+					tvv.ExportDialog()
+				})
+			if !tv.IsInactive() {
+				tb.AddAction(gi.ActOpts{Label: "Import", Icon: "file-open", Tooltip: "add the rows from a .csv, .tsv or .json file"},
+					tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+						tvv := recv.Embed(KiT_TableView).(*TableView)
+						tvv.ImportDialog()
+					})
+			}
+		}
+		tb.AddNewChild(gi.KiT_Separator, "std-sep")
 	}
-	sz := len(*tb.Children())
-	if sz > 1 {
-		for i := sz - 1; i >= 1; i-- {
+	if sidx, ok := tb.Children().IndexByName("std-sep", 0); ok {
+		for i := len(*tb.Children()) - 1; i > sidx; i-- {
 			tb.DeleteChildAtIndex(i, true)
 		}
 	}
-	if HasToolBarView(tv.Slice) {
+	if !tv.IsInactive() && HasToolBarView(tv.Slice) {
 		ToolBarView(tv.Slice, tv.Viewport, tb)
 	}
 	tv.ToolbarSlice = tv.Slice
 }
 
-// SortFieldName returns the name of the field being sorted, along with :up or
-// :down depending on descending
+// SortFieldName returns the names of the fields being sorted, each along
+// with :up or :down depending on descending, separated by commas in order of
+// precedence for multiple SortKeys -- can be saved and restored with
+// SetSortFieldName
 func (tv *TableView) SortFieldName() string {
-	if tv.SortIdx >= 0 && tv.SortIdx < tv.NVisFields {
-		nm := tv.VisFields[tv.SortIdx].Name
-		if tv.SortDesc {
-			nm += ":down"
-		} else {
-			nm += ":up"
-		}
-		return nm
+	if len(tv.SortKeys) == 0 && tv.SortIdx >= 0 && tv.SortIdx < tv.NVisFields {
+		return TableViewSortKey{Field: tv.VisFields[tv.SortIdx].Name, Desc: tv.SortDesc}.String()
+	}
+	ks := make([]string, len(tv.SortKeys))
+	for i, sk := range tv.SortKeys {
+		ks[i] = sk.String()
 	}
-	return ""
+	return strings.Join(ks, ",")
 }
 
-// SetSortField sets sorting to happen on given field and direction -- see
-// SortFieldName for details
+// SetSortFieldName sets sorting to happen on given field(s) and direction(s)
+// -- see SortFieldName for details
 func (tv *TableView) SetSortFieldName(nm string) {
 	if nm == "" {
 		return
 	}
-	spnm := strings.Split(nm, ":")
+	tv.SetSortKeys(SortKeysFromString(nm))
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//  Filtering
+
+// ConfigFilterBar configures the filter bar, if ShowFilter, with a text
+// field for the filter expression of each field, aligned with the header
+func (tv *TableView) ConfigFilterBar() {
+	sfb := tv.FilterBar()
+	if sfb == nil {
+		return
+	}
+	sfb.Lay = gi.LayoutHoriz
+	sfb.SetProp("overflow", "hidden") // no scrollbars!
+	sfb.SetProp("spacing", 0)
+
+	_, idxOff := tv.RowWidgetNs()
+	fcfg := kit.TypeAndNameList{}
+	if tv.ShowIndex {
+		fcfg.Add(gi.KiT_Label, "filt-idx")
+	}
 	for fli := 0; fli < tv.NVisFields; fli++ {
 		fld := tv.VisFields[fli]
-		if fld.Name == spnm[0] {
-			tv.SortIdx = fli
+		fcfg.Add(gi.KiT_TextField, fmt.Sprintf("filt-%v", fld.Name))
+	}
+	if !tv.IsInactive() {
+		fcfg.Add(gi.KiT_Label, "filt-add")
+		fcfg.Add(gi.KiT_Label, "filt-del")
+	}
+	mods, updt := sfb.ConfigChildren(fcfg, false)
+	if mods {
+		sfb.SetFullReRender()
+	} else {
+		updt = sfb.UpdateStart()
+	}
+	if tv.ShowIndex {
+		lbl := sfb.KnownChild(0).(*gi.Label)
+		lbl.Text = "Filter"
+	}
+	for fli := 0; fli < tv.NVisFields; fli++ {
+		fld := tv.VisFields[fli]
+		tf := sfb.KnownChild(idxOff + fli).(*gi.TextField)
+		tf.SetText(tv.Filters[fld.Name])
+		tf.SetProp("tv-filter-field", fld.Name)
+		tf.Tooltip = "show only rows matching this filter for this column: text contained in the value, /regexp/, or lo..hi numerical range (either end optional)"
+		tf.TextFieldSig.ConnectOnly(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.TextFieldDone) {
+				return
+			}
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			tff := send.(*gi.TextField)
+			fnm := tff.KnownProp("tv-filter-field").(string)
+			if err := tvv.SetFilter(fnm, tff.Text()); err != nil {
+				gi.PromptDialog(tvv.Viewport, gi.DlgOpts{Title: "Invalid Filter", Prompt: err.Error()}, true, false, nil, nil)
+			}
+		})
+	}
+	sfb.UpdateEnd(updt)
+}
+
+// SetFilter sets the filter expression for given field name, and updates
+// the view to only show the rows that pass all the filters -- an empty
+// expression removes the filter for that field -- see TableViewFilter for
+// the syntax -- returns an error for an invalid expression, which is not set
+func (tv *TableView) SetFilter(fld, expr string) error {
+	if expr != "" {
+		if _, err := NewTableViewFilter(fld, expr); err != nil {
+			return err
 		}
 	}
-	if len(spnm) == 2 {
-		if spnm[1] == "down" {
-			tv.SortDesc = true
-		} else {
-			tv.SortDesc = false
+	if tv.Filters == nil {
+		tv.Filters = make(map[string]string)
+	}
+	if expr == "" {
+		delete(tv.Filters, fld)
+	} else {
+		tv.Filters[fld] = expr
+	}
+	tv.FilterUpdate()
+	return nil
+}
+
+// SetFilters sets all the filter expressions, by field name, replacing any
+// existing ones, and updates the view -- returns the first error for an
+// invalid expression, which is skipped
+func (tv *TableView) SetFilters(filts map[string]string) error {
+	var rerr error
+	tv.Filters = make(map[string]string, len(filts))
+	for fld, expr := range filts {
+		if expr == "" {
+			continue
+		}
+		if _, err := NewTableViewFilter(fld, expr); err != nil {
+			if rerr == nil {
+				rerr = err
+			}
+			continue
+		}
+		tv.Filters[fld] = expr
+	}
+	tv.FilterUpdate()
+	return rerr
+}
+
+// ClearFilters removes all the filters, showing all the rows
+func (tv *TableView) ClearFilters() {
+	tv.Filters = nil
+	tv.FilterUpdate()
+}
+
+// FilterUpdate updates the view for the current Filters, if it has already
+// been built -- selected rows that are filtered out are unselected
+func (tv *TableView) FilterUpdate() {
+	if kit.IfaceIsNil(tv.Slice) || tv.BuiltSlice != tv.Slice {
+		return // filtered when built
+	}
+	updt := tv.UpdateStart()
+	tv.ConfigSliceGrid(true)
+	for r := range tv.SelectedRows {
+		if _, ok := tv.ViewRow(r); !ok {
+			delete(tv.SelectedRows, r)
+		}
+	}
+	tv.SetFullReRender()
+	tv.UpdateEnd(updt)
+}
+
+// FilterSlice computes ViewIdxs, the slice indexes of the rows that pass all
+// of the Filters, in slice order -- ViewIdxs is nil if there are no (valid)
+// filters, in which case all rows are shown -- SortSlice then sorts them
+func (tv *TableView) FilterSlice() {
+	tv.ViewIdxs = nil
+	if len(tv.Filters) == 0 || kit.IfaceIsNil(tv.Slice) {
+		return
+	}
+	filts := make([]*TableViewFilter, 0, len(tv.Filters))
+	for fld, expr := range tv.Filters {
+		if expr == "" {
+			continue
+		}
+		tf, err := NewTableViewFilter(fld, expr)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		filts = append(filts, tf)
+	}
+	if len(filts) == 0 {
+		return
+	}
+	tv.ViewIdxs = StructSliceFilter(tv.Slice, filts)
+}
+
+// ViewSize returns the number of rows in the view, i.e., those that pass the
+// Filters
+func (tv *TableView) ViewSize() int {
+	if tv.ViewIdxs != nil {
+		return len(tv.ViewIdxs)
+	}
+	return tv.BuiltSize
+}
+
+// ViewIdx returns the slice index of the row at given position in the view
+func (tv *TableView) ViewIdx(vr int) int {
+	if tv.ViewIdxs != nil {
+		return tv.ViewIdxs[vr]
+	}
+	return vr
+}
+
+// ViewRow returns the position in the view of the row at given slice index
+// -- false if out of range or filtered out
+func (tv *TableView) ViewRow(idx int) (int, bool) {
+	if idx < 0 || idx >= tv.BuiltSize {
+		return -1, false
+	}
+	if tv.viewRows != nil {
+		if idx >= len(tv.viewRows) {
+			return -1, false
 		}
+		vr := tv.viewRows[idx]
+		return vr, vr >= 0
+	}
+	return idx, true
+}
+
+// ViewRowOffset returns the slice index of the row that is given number of
+// rows (positive or negative) away from given row in the view, clamped to
+// the rows in the view -- a row that is not in the view is treated as just
+// before the first row -- returns -1 if the view is empty
+func (tv *TableView) ViewRowOffset(row, off int) int {
+	nview := tv.ViewSize()
+	if nview == 0 {
+		return -1
+	}
+	vr, ok := tv.ViewRow(row)
+	if !ok {
+		vr = -1
 	}
+	vr = ints.MaxInt(0, ints.MinInt(vr+off, nview-1))
+	return tv.ViewIdx(vr)
 }
 
 func (tv *TableView) Style2D() {
@@ -735,24 +1314,32 @@ func (tv *TableView) Layout2D(parBBox image.Rectangle, iter int) bool {
 	nfld := tv.NVisFields + idxOff
 	sgh := tv.SliceHeader()
 	sgf := tv.SliceGrid()
+	sfb := tv.FilterBar()
+	if sfb != nil && len(sfb.Kids) != len(sgh.Kids) {
+		sfb = nil
+	}
 	if len(sgf.Kids) >= nfld {
 		sumwd := float32(0)
-		for fli := 0; fli < nfld; fli++ {
-			lbl := sgh.KnownChild(fli).(gi.Node2D).AsWidget()
+		ncol := nfld
+		if !tv.IsInactive() {
+			ncol += 2
+		}
+		for fli := 0; fli < ncol; fli++ {
 			wd := sgf.GridData[gi.Col][fli].AllocSize
+			lbl := sgh.KnownChild(fli).(gi.Node2D).AsWidget()
 			lbl.SetMinPrefWidth(units.NewValue(wd-sgf.Spacing.Dots, units.Dot))
-			sumwd += wd
-		}
-		if !tv.IsInactive() {
-			for fli := nfld; fli < nfld+2; fli++ {
-				lbl := sgh.KnownChild(fli).(gi.Node2D).AsWidget()
-				wd := sgf.GridData[gi.Col][fli].AllocSize
-				lbl.SetMinPrefWidth(units.NewValue(wd-sgf.Spacing.Dots, units.Dot))
-				sumwd += wd
+			if sfb != nil {
+				fw := sfb.KnownChild(fli).(gi.Node2D).AsWidget()
+				fw.SetMinPrefWidth(units.NewValue(wd-sgf.Spacing.Dots, units.Dot))
 			}
+			sumwd += wd
 		}
 		sgh.SetMinPrefWidth(units.NewValue(sumwd, units.Dot))
 		sgh.Layout2D(parBBox, iter)
+		if sfb != nil {
+			sfb.SetMinPrefWidth(units.NewValue(sumwd, units.Dot))
+			sfb.Layout2D(parBBox, iter)
+		}
 	}
 	return redo
 }
@@ -768,7 +1355,10 @@ func (tv *TableView) Render2D() {
 		return
 	}
 	if tv.PushBounds() {
-		if tv.Sty.Font.Height > 0 {
+		if tv.Virtual {
+			tv.VisRows = tv.VirtVisRows()
+			tv.UpdateVScroll()
+		} else if tv.Sty.Font.Height > 0 {
 			tv.VisRows = (tv.VpBBox.Max.Y - tv.VpBBox.Min.Y) / int(1.8*tv.Sty.Font.Height)
 		} else {
 			tv.VisRows = 10
@@ -778,7 +1368,8 @@ func (tv *TableView) Render2D() {
 		tv.RenderScrolls()
 		tv.Render2DChildren()
 		tv.PopBounds()
-		if tv.SelectedIdx > -1 {
+		// in Virtual mode, ConfigSliceGrid keeps the selected row in view
+		if tv.SelectedIdx > -1 && !tv.Virtual {
 			tv.ScrollToRow(tv.SelectedIdx)
 		}
 	} else {
@@ -786,6 +1377,20 @@ func (tv *TableView) Render2D() {
 	}
 }
 
+// VirtVisRows returns the number of rows that fit within the visible part of
+// the grid in Virtual mode, based on the allocated height of the rows
+func (tv *TableView) VirtVisRows() int {
+	sgf := tv.SliceGrid()
+	if sgf == nil {
+		return 10
+	}
+	rowht := float32(tv.RowHeight())
+	if len(sgf.GridData[gi.Row]) > 0 && sgf.GridData[gi.Row][0].AllocSize > 0 {
+		rowht = sgf.GridData[gi.Row][0].AllocSize + sgf.Spacing.Dots
+	}
+	return ints.MaxInt(1, int(float32(sgf.VpBBox.Max.Y-sgf.VpBBox.Min.Y)/rowht))
+}
+
 func (tv *TableView) ConnectEvents2D() {
 	tv.TableViewEvents()
 }
@@ -814,19 +1419,35 @@ func (tv *TableView) RowStruct(row int) interface{} {
 	return stru
 }
 
+// RowGridIdx returns the index within the SliceGrid children of the first
+// widget for given row -- false if out of range, filtered out, or, in Virtual
+// mode, if the row does not currently have widgets
+func (tv *TableView) RowGridIdx(row int) (int, bool) {
+	vr, ok := tv.ViewRow(row)
+	if !ok || vr < tv.StartIdx || vr >= tv.StartIdx+tv.VirtRows {
+		return -1, false
+	}
+	nWidgPerRow, _ := tv.RowWidgetNs()
+	ridx := (vr - tv.StartIdx) * nWidgPerRow
+	sgf := tv.SliceGrid()
+	if sgf == nil || !sgf.Kids.IsValidIndex(ridx+nWidgPerRow-1) {
+		return -1, false
+	}
+	return ridx, true
+}
+
 // RowFirstWidget returns the first widget for given row (could be index or
 // not) -- false if out of range
 func (tv *TableView) RowFirstWidget(row int) (*gi.WidgetBase, bool) {
 	if tv.RowStruct(row) == nil { // range check
 		return nil, false
 	}
-	nWidgPerRow, _ := tv.RowWidgetNs()
-	sg, _ := tv.SliceFrame()
-	if sg == nil {
+	ridx, ok := tv.RowGridIdx(row)
+	if !ok {
 		return nil, false
 	}
 	sgf := tv.SliceGrid()
-	widg := sgf.Kids[row*nWidgPerRow].(gi.Node2D).AsWidget()
+	widg := sgf.Kids[ridx].(gi.Node2D).AsWidget()
 	return widg, true
 }
 
@@ -836,17 +1457,16 @@ func (tv *TableView) RowFirstVisWidget(row int) (*gi.WidgetBase, bool) {
 	if tv.RowStruct(row) == nil { // range check
 		return nil, false
 	}
-	nWidgPerRow, idxOff := tv.RowWidgetNs()
-	sg, _ := tv.SliceFrame()
-	if sg == nil {
+	_, idxOff := tv.RowWidgetNs()
+	ridx, ok := tv.RowGridIdx(row)
+	if !ok {
 		return nil, false
 	}
 	sgf := tv.SliceGrid()
-	widg := sgf.Kids[row*nWidgPerRow].(gi.Node2D).AsWidget()
+	widg := sgf.Kids[ridx].(gi.Node2D).AsWidget()
 	if widg.VpBBox != image.ZR {
 		return widg, true
 	}
-	ridx := nWidgPerRow * row
 	for fli := 0; fli < tv.NVisFields; fli++ {
 		widg := sgf.KnownChild(ridx + idxOff + fli).(gi.Node2D).AsWidget()
 		if widg.VpBBox != image.ZR {
@@ -864,12 +1484,11 @@ func (tv *TableView) RowGrabFocus(row int) *gi.WidgetBase {
 		return nil
 	}
 	// fmt.Printf("grab row focus: %v\n", row)
-	nWidgPerRow, idxOff := tv.RowWidgetNs()
-	sg, _ := tv.SliceFrame()
-	if sg == nil {
+	_, idxOff := tv.RowWidgetNs()
+	ridx, ok := tv.RowGridIdx(row)
+	if !ok {
 		return nil
 	}
-	ridx := nWidgPerRow * row
 	sgf := tv.SliceGrid()
 	// first check if we already have focus
 	for fli := 0; fli < tv.NVisFields; fli++ {
@@ -903,7 +1522,9 @@ func (tv *TableView) RowPos(row int) image.Point {
 // RowFromPos returns the row that contains given vertical position, false if not found
 func (tv *TableView) RowFromPos(posY int) (int, bool) {
 	// todo: could optimize search to approx loc, and search up / down from there
-	for rw := 0; rw < tv.BuiltSize; rw++ {
+	nvr := ints.MinInt(tv.ViewSize(), tv.StartIdx+tv.VirtRows)
+	for vr := tv.StartIdx; vr < nvr; vr++ {
+		rw := tv.ViewIdx(vr)
 		widg, ok := tv.RowFirstWidget(rw)
 		if ok {
 			if widg.ObjBBox.Min.Y < posY && posY < widg.ObjBBox.Max.Y {
@@ -915,9 +1536,31 @@ func (tv *TableView) RowFromPos(posY int) (int, bool) {
 }
 
 // ScrollToRow ensures that given row is visible by scrolling layout as needed
-// -- returns true if any scrolling was performed
+// -- returns true if any scrolling was performed.  In Virtual mode, this
+// moves the StartIdx to bring the row into view.
 func (tv *TableView) ScrollToRow(row int) bool {
 	row = ints.MinInt(row, tv.BuiltSize-1)
+	if tv.Virtual {
+		vr, ok := tv.ViewRow(row)
+		if !ok {
+			return false
+		}
+		vis := ints.MinInt(ints.MaxInt(tv.VisRows, 1), tv.VirtRows)
+		st := tv.StartIdx
+		switch {
+		case vr < st:
+			st = vr
+		case vr >= st+vis:
+			st = vr - vis + 1
+		default:
+			return false
+		}
+		tv.SetStartIdx(st)
+		if !tv.IsInactive() && tv.RowIsSelected(row) {
+			tv.RowGrabFocus(row)
+		}
+		return true
+	}
 	sgf := tv.SliceGrid()
 	if widg, ok := tv.RowFirstWidget(row); ok {
 		return sgf.ScrollToItem(widg)
@@ -977,11 +1620,11 @@ func (tv *TableView) MoveDown(selMode mouse.SelectModes) int {
 			selMode = mouse.ExtendContinuous
 		}
 	}
-	if tv.SelectedIdx >= tv.BuiltSize-1 {
-		tv.SelectedIdx = tv.BuiltSize - 1
+	nrow := tv.ViewRowOffset(tv.SelectedIdx, 1)
+	if nrow < 0 || nrow == tv.SelectedIdx {
 		return -1
 	}
-	tv.SelectedIdx++
+	tv.SelectedIdx = nrow
 	tv.SelectRowAction(tv.SelectedIdx, selMode)
 	return tv.SelectedIdx
 }
@@ -1006,11 +1649,11 @@ func (tv *TableView) MoveUp(selMode mouse.SelectModes) int {
 			selMode = mouse.ExtendContinuous
 		}
 	}
-	if tv.SelectedIdx <= 0 {
-		tv.SelectedIdx = 0
+	nrow := tv.ViewRowOffset(tv.SelectedIdx, -1)
+	if nrow < 0 || nrow == tv.SelectedIdx {
 		return -1
 	}
-	tv.SelectedIdx--
+	tv.SelectedIdx = nrow
 	tv.SelectRowAction(tv.SelectedIdx, selMode)
 	return tv.SelectedIdx
 }
@@ -1035,12 +1678,11 @@ func (tv *TableView) MovePageDown(selMode mouse.SelectModes) int {
 			selMode = mouse.ExtendContinuous
 		}
 	}
-	if tv.SelectedIdx >= tv.BuiltSize-1 {
-		tv.SelectedIdx = tv.BuiltSize - 1
+	nrow := tv.ViewRowOffset(tv.SelectedIdx, ints.MaxInt(tv.VisRows, 1))
+	if nrow < 0 || nrow == tv.SelectedIdx {
 		return -1
 	}
-	tv.SelectedIdx += tv.VisRows
-	tv.SelectedIdx = ints.MinInt(tv.SelectedIdx, tv.BuiltSize-1)
+	tv.SelectedIdx = nrow
 	tv.SelectRowAction(tv.SelectedIdx, selMode)
 	return tv.SelectedIdx
 }
@@ -1065,12 +1707,11 @@ func (tv *TableView) MovePageUp(selMode mouse.SelectModes) int {
 			selMode = mouse.ExtendContinuous
 		}
 	}
-	if tv.SelectedIdx <= 0 {
-		tv.SelectedIdx = 0
+	nrow := tv.ViewRowOffset(tv.SelectedIdx, -ints.MaxInt(tv.VisRows, 1))
+	if nrow < 0 || nrow == tv.SelectedIdx {
 		return -1
 	}
-	tv.SelectedIdx -= tv.VisRows
-	tv.SelectedIdx = ints.MaxInt(0, tv.SelectedIdx)
+	tv.SelectedIdx = nrow
 	tv.SelectRowAction(tv.SelectedIdx, selMode)
 	return tv.SelectedIdx
 }
@@ -1090,11 +1731,16 @@ func (tv *TableView) MovePageUpAction(selMode mouse.SelectModes) int {
 //////////////////////////////////////////////////////////////////////////////
 //    Selection: user operates on the index labels
 
-// SelectRowWidgets sets the selection state of given row of widgets
+// SelectRowWidgets sets the selection state of given row of widgets -- does
+// nothing for rows that do not currently have widgets in Virtual mode
 func (tv *TableView) SelectRowWidgets(idx int, sel bool) {
 	if idx < 0 {
 		return
 	}
+	ridx, ok := tv.RowGridIdx(idx)
+	if !ok {
+		return
+	}
 	var win *gi.Window
 	if tv.Viewport != nil {
 		win = tv.Viewport.Win
@@ -1104,8 +1750,7 @@ func (tv *TableView) SelectRowWidgets(idx int, sel bool) {
 		updt = win.UpdateStart()
 	}
 	sgf := tv.SliceGrid()
-	nWidgPerRow, idxOff := tv.RowWidgetNs()
-	ridx := idx * nWidgPerRow
+	_, idxOff := tv.RowWidgetNs()
 	for fli := 0; fli < tv.NVisFields; fli++ {
 		seldx := ridx + idxOff + fli
 		if sgf.Kids.IsValidIndex(seldx) {
@@ -1219,8 +1864,10 @@ func (tv *TableView) SelectAllRows() {
 		updt = win.UpdateStart()
 	}
 	tv.UnselectAllRows()
-	tv.SelectedRows = make(map[int]bool, tv.BuiltSize)
-	for row := 0; row < tv.BuiltSize; row++ {
+	nview := tv.ViewSize()
+	tv.SelectedRows = make(map[int]bool, nview)
+	for vr := 0; vr < nview; vr++ {
+		row := tv.ViewIdx(vr)
 		tv.SelectedRows[row] = true
 		tv.SelectRowWidgets(row, true)
 	}
@@ -1266,11 +1913,17 @@ func (tv *TableView) SelectRowAction(row int, mode mouse.SelectModes) {
 			if row < minIdx {
 				for cidx < minIdx {
 					r := tv.MoveDown(mouse.SelectModesN) // just select
+					if r < 0 {
+						break
+					}
 					cidx = r
 				}
 			} else if row > maxIdx {
 				for cidx > maxIdx {
 					r := tv.MoveUp(mouse.SelectModesN) // just select
+					if r < 0 {
+						break
+					}
 					cidx = r
 				}
 			}
@@ -1549,7 +2202,16 @@ func (tv *TableView) DragNDropStart() {
 		tv.MimeDataRow(&md, r)
 	}
 	rws := tv.SelectedRowsList(true) // descending sort
-	widg, ok := tv.RowFirstVisWidget(rws[0])
+	var widg *gi.WidgetBase
+	ok := false
+	for _, r := range rws { // in Virtual mode, rows may not have widgets
+		if widg, ok = tv.RowFirstVisWidget(r); ok {
+			break
+		}
+	}
+	if !ok && tv.Virtual {
+		widg, ok = tv.RowFirstVisWidget(tv.StartIdx)
+	}
 	if ok {
 		bi := &gi.Bitmap{}
 		bi.InitName(bi, tv.UniqueName())
@@ -1741,11 +2403,17 @@ func (tv *TableView) ItemCtxtMenu(row int) {
 	}
 }
 
+// KeyContext satisfies the gi.KeyContexter interface -- table views use the
+// SliceView context-specific key map
+func (tv *TableView) KeyContext() gi.KeyContext {
+	return gi.KeyContextSliceView
+}
+
 func (tv *TableView) KeyInputActive(kt *key.ChordEvent) {
 	if gi.KeyEventTrace {
 		fmt.Printf("TableView KeyInput: %v\n", tv.PathUnique())
 	}
-	kf := gi.KeyFun(kt.Chord())
+	kf := gi.KeyFunContext(kt.Chord(), gi.KeyContextSliceView)
 	selMode := mouse.SelectModeBits(kt.Modifiers)
 	row := tv.SelectedIdx
 	switch kf {
@@ -1814,32 +2482,34 @@ func (tv *TableView) KeyInputInactive(kt *key.ChordEvent) {
 	if gi.KeyEventTrace {
 		fmt.Printf("TableView Inactive KeyInput: %v\n", tv.PathUnique())
 	}
-	kf := gi.KeyFun(kt.Chord())
+	kf := gi.KeyFunContext(kt.Chord(), gi.KeyContextSliceView)
 	row := tv.SelectedIdx
 	switch {
 	case kf == gi.KeyFunMoveDown:
-		nr := row + 1
-		if nr < tv.BuiltSize {
+		nr := tv.ViewRowOffset(row, 1)
+		if nr >= 0 && nr != row {
 			tv.ScrollToRow(nr)
 			tv.UpdateSelect(nr, true)
 			kt.SetProcessed()
 		}
 	case kf == gi.KeyFunMoveUp:
-		nr := row - 1
-		if nr >= 0 {
+		nr := tv.ViewRowOffset(row, -1)
+		if nr >= 0 && nr != row {
 			tv.ScrollToRow(nr)
 			tv.UpdateSelect(nr, true)
 			kt.SetProcessed()
 		}
 	case kf == gi.KeyFunPageDown:
-		nr := ints.MinInt(row+tv.VisRows, tv.BuiltSize-1)
-		tv.ScrollToRow(nr)
-		tv.UpdateSelect(nr, true)
+		if nr := tv.ViewRowOffset(row, tv.VisRows); nr >= 0 {
+			tv.ScrollToRow(nr)
+			tv.UpdateSelect(nr, true)
+		}
 		kt.SetProcessed()
 	case kf == gi.KeyFunPageUp:
-		nr := ints.MaxInt(row-tv.VisRows, 0)
-		tv.ScrollToRow(nr)
-		tv.UpdateSelect(nr, true)
+		if nr := tv.ViewRowOffset(row, -tv.VisRows); nr >= 0 {
+			tv.ScrollToRow(nr)
+			tv.UpdateSelect(nr, true)
+		}
 		kt.SetProcessed()
 	case kf == gi.KeyFunEnter || kf == gi.KeyFunAccept || kt.Rune == ' ':
 		tv.TableViewSig.Emit(tv.This(), int64(TableViewDoubleClicked), tv.SelectedIdx)
@@ -1848,6 +2518,25 @@ func (tv *TableView) KeyInputInactive(kt *key.ChordEvent) {
 }
 
 func (tv *TableView) TableViewEvents() {
+	tv.HeaderEvents()
+	if tv.Virtual {
+		tv.ConnectEvent(oswin.MouseScrollEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+			me := d.(*mouse.ScrollEvent)
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			if me.Delta.Y == 0 {
+				return
+			}
+			me.SetProcessed()
+			nr := me.Delta.Y / tvv.RowHeight()
+			if nr == 0 {
+				nr = 1
+				if me.Delta.Y < 0 {
+					nr = -1
+				}
+			}
+			tvv.SetStartIdx(tvv.StartIdx + nr)
+		})
+	}
 	if tv.IsInactive() {
 		if tv.InactKeyNav {
 			tv.ConnectEvent(oswin.KeyChordEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
@@ -1864,6 +2553,9 @@ func (tv *TableView) TableViewEvents() {
 				me.SetProcessed()
 			}
 			if me.Button == mouse.Right && me.Action == mouse.Release {
+				if fli, _ := tvv.HeaderColFromPos(me.Where); fli >= 0 {
+					return // ColsCtxtMenu
+				}
 				tvv.ItemCtxtMenu(tvv.SelectedIdx)
 				me.SetProcessed()
 			}
@@ -1873,6 +2565,9 @@ func (tv *TableView) TableViewEvents() {
 			me := d.(*mouse.Event)
 			tvv := recv.Embed(KiT_TableView).(*TableView)
 			if me.Button == mouse.Right && me.Action == mouse.Release {
+				if fli, _ := tvv.HeaderColFromPos(me.Where); fli >= 0 {
+					return // ColsCtxtMenu
+				}
 				tvv.ItemCtxtMenu(tvv.SelectedIdx)
 				me.SetProcessed()
 			}
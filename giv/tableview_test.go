@@ -0,0 +1,204 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin/mouse"
+)
+
+// testTableView returns a TableView of given slice in a window, with Virtual
+// mode for slices longer than 50 rows
+func testTableView(t *testing.T, name string, sl *[]testRow, props map[string]interface{}) (*TableView, func()) {
+	vsz := TableViewVirtualSize
+	TableViewVirtualSize = 50
+	var tv *TableView
+	h := testWin(t, name, func(mfr *gi.Frame) {
+		tv = mfr.AddNewChild(KiT_TableView, "tv").(*TableView)
+		for k, v := range props {
+			tv.SetProp(k, v)
+		}
+		tv.SetSlice(sl, nil)
+	})
+	return tv, func() {
+		h.Close()
+		TableViewVirtualSize = vsz
+	}
+}
+
+// testTableSelRows returns the rows whose widgets show them as selected
+func testTableSelRows(tv *TableView) []int {
+	var sel []int
+	nWidgPerRow, idxOff := tv.RowWidgetNs()
+	sgf := tv.SliceGrid()
+	for r := 0; r < tv.VirtRows; r++ {
+		if sgf.KnownChild(r*nWidgPerRow + idxOff).(gi.Node2D).AsNode2D().IsSelected() {
+			sel = append(sel, tv.StartIdx+r)
+		}
+	}
+	return sel
+}
+
+func TestTableViewVirtual(t *testing.T) {
+	sl := testRows(200)
+	tv, done := testTableView(t, "test-tableview-virtual", &sl, nil)
+	defer done()
+
+	vis := tv.VisRows
+	if !tv.Virtual || tv.VirtRows >= len(sl) || vis < 3 || vis > tv.VirtRows {
+		t.Fatalf("virtual: %v built rows: %v visible: %v", tv.Virtual, tv.VirtRows, vis)
+	}
+	nWidgPerRow, _ := tv.RowWidgetNs()
+	if n := len(tv.SliceGrid().Kids); n != tv.VirtRows*nWidgPerRow {
+		t.Errorf("built widgets: %v", n)
+	}
+
+	tv.SetStartIdx(1000)
+	if tv.StartIdx != len(sl)-vis || tv.MaxStartIdx() != len(sl)-vis {
+		t.Errorf("start past the end: %v max: %v", tv.StartIdx, tv.MaxStartIdx())
+	}
+	tv.SetStartIdx(-5)
+	if tv.StartIdx != 0 {
+		t.Errorf("start before the beginning: %v", tv.StartIdx)
+	}
+
+	tv.SetStartIdx(10)
+	if lbl := tv.SliceGrid().KnownChild(0).(*gi.Label); lbl.Text != "00010" {
+		t.Errorf("first row label: %v", lbl.Text)
+	}
+	if _, ok := tv.RowGridIdx(9); ok {
+		t.Errorf("row before the built rows has widgets")
+	}
+	if ridx, ok := tv.RowGridIdx(10); !ok || ridx != 0 {
+		t.Errorf("first built row: %v %v", ridx, ok)
+	}
+	last := 10 + tv.VirtRows - 1
+	if ridx, ok := tv.RowGridIdx(last); !ok || ridx != (tv.VirtRows-1)*nWidgPerRow {
+		t.Errorf("last built row: %v %v", ridx, ok)
+	}
+	if _, ok := tv.RowGridIdx(last + 1); ok {
+		t.Errorf("row after the built rows has widgets")
+	}
+
+	if tv.ScrollToRow(11) || tv.StartIdx != 10 {
+		t.Errorf("scroll to visible row: %v", tv.StartIdx)
+	}
+	if !tv.ScrollToRow(100) || tv.StartIdx != 100-vis+1 {
+		t.Errorf("scroll down: %v", tv.StartIdx)
+	}
+	if !tv.ScrollToRow(5) || tv.StartIdx != 5 {
+		t.Errorf("scroll up: %v", tv.StartIdx)
+	}
+	if !tv.ScrollToRow(1000) || tv.StartIdx != tv.MaxStartIdx() {
+		t.Errorf("scroll past the end: %v", tv.StartIdx)
+	}
+}
+
+func TestTableViewVirtualMove(t *testing.T) {
+	sl := testRows(200)
+	tv, done := testTableView(t, "test-tableview-virtual-move", &sl, nil)
+	defer done()
+
+	vis := tv.VisRows
+	tv.SelectRowAction(vis-1, mouse.NoSelectMode)
+	if sel := testTableSelRows(tv); !reflect.DeepEqual(sel, []int{vis - 1}) {
+		t.Errorf("selected last visible row: %v", sel)
+	}
+	// moving past the last visible row scrolls by one, reusing the widgets
+	// of the first row for the new one
+	if row := tv.MoveDownAction(mouse.NoSelectMode); row != vis || tv.StartIdx != 1 {
+		t.Errorf("move down: %v start: %v", row, tv.StartIdx)
+	}
+	if sel := testTableSelRows(tv); !reflect.DeepEqual(sel, []int{vis}) {
+		t.Errorf("move down selection: %v", sel)
+	}
+	if row := tv.MovePageDownAction(mouse.NoSelectMode); row != 2*vis || tv.StartIdx != vis+1 {
+		t.Errorf("page down: %v start: %v", row, tv.StartIdx)
+	}
+	if sel := testTableSelRows(tv); !reflect.DeepEqual(sel, []int{2 * vis}) {
+		t.Errorf("page down selection: %v", sel)
+	}
+	for tv.MovePageDownAction(mouse.NoSelectMode) >= 0 {
+	}
+	if tv.SelectedIdx != len(sl)-1 || tv.StartIdx != tv.MaxStartIdx() {
+		t.Errorf("page down to the end: %v start: %v", tv.SelectedIdx, tv.StartIdx)
+	}
+	if sel := testTableSelRows(tv); !reflect.DeepEqual(sel, []int{len(sl) - 1}) {
+		t.Errorf("page down to the end selection: %v", sel)
+	}
+
+	// the selection is kept for rows scrolled out of view and back
+	tv.SetStartIdx(0)
+	if sel := testTableSelRows(tv); len(sel) != 0 || !tv.RowIsSelected(len(sl)-1) {
+		t.Errorf("selection scrolled out of view: %v", sel)
+	}
+	tv.ScrollToRow(len(sl) - 1)
+	if sel := testTableSelRows(tv); !reflect.DeepEqual(sel, []int{len(sl) - 1}) {
+		t.Errorf("selection scrolled back into view: %v", sel)
+	}
+}
+
+func TestTableViewVirtualProp(t *testing.T) {
+	sl := testRows(60)
+	tv, done := testTableView(t, "test-tableview-virtual-off", &sl, map[string]interface{}{"virtual": false})
+	if tv.Virtual || tv.VirtRows != len(sl) || tv.StartIdx != 0 {
+		t.Errorf("virtual off: %v built rows: %v", tv.Virtual, tv.VirtRows)
+	}
+	done()
+
+	ssl := testRows(10)
+	tv, done = testTableView(t, "test-tableview-virtual-on", &ssl, map[string]interface{}{"virtual": true})
+	defer done()
+	if !tv.Virtual || tv.VirtRows != len(ssl) || tv.MaxStartIdx() != 0 {
+		t.Errorf("virtual on: %v built rows: %v max start: %v", tv.Virtual, tv.VirtRows, tv.MaxStartIdx())
+	}
+}
+
+func TestTableViewSort(t *testing.T) {
+	sl := testRows(10)
+	for i := range sl {
+		sl[i].On = i%2 == 0
+	}
+	orig := make([]testRow, len(sl))
+	copy(orig, sl)
+	tv, done := testTableView(t, "test-tableview-sort", &sl, nil)
+	defer done()
+
+	tv.SetSortKeys(SortKeysFromString("On:down,Size:down"))
+	if want := []int{8, 6, 4, 2, 0, 9, 7, 5, 3, 1}; !reflect.DeepEqual(tv.ViewIdxs, want) {
+		t.Errorf("sorted view: %v, want %v", tv.ViewIdxs, want)
+	}
+	if !reflect.DeepEqual(sl, orig) {
+		t.Errorf("slice changed by sort: %v", sl)
+	}
+	if vr, ok := tv.ViewRow(0); !ok || vr != 4 {
+		t.Errorf("view row of slice index 0: %v %v", vr, ok)
+	}
+	if idx := tv.ViewIdx(5); idx != 9 {
+		t.Errorf("slice index of view row 5: %v", idx)
+	}
+
+	// filtered rows are sorted among themselves
+	if err := tv.SetFilter("Size", "..5"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{4, 2, 0, 5, 3, 1}; !reflect.DeepEqual(tv.ViewIdxs, want) {
+		t.Errorf("sorted filtered view: %v, want %v", tv.ViewIdxs, want)
+	}
+	tv.SetSortKeys(nil)
+	if want := []int{0, 1, 2, 3, 4, 5}; !reflect.DeepEqual(tv.ViewIdxs, want) {
+		t.Errorf("unsorted filtered view: %v, want %v", tv.ViewIdxs, want)
+	}
+	tv.ClearFilters()
+	if tv.ViewIdxs != nil || tv.ViewSize() != len(sl) {
+		t.Errorf("unsorted, unfiltered view: %v", tv.ViewIdxs)
+	}
+	if !reflect.DeepEqual(sl, orig) {
+		t.Errorf("slice changed: %v", sl)
+	}
+}
@@ -0,0 +1,544 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"encoding/json"
+	"image"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// TableViewColMinWidth is the minimum width of a column resized by dragging
+// its header separator, in dots
+var TableViewColMinWidth = float32(16)
+
+// TableViewColResizePix is the distance from the edge of a header within
+// which dragging resizes the column, instead of moving it, in dots
+var TableViewColResizePix = 4
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  TableViewCols
+
+// TableViewColState records the layout of one column of a TableView
+type TableViewColState struct {
+	Field  string  `desc:"name of the struct field shown in the column"`
+	Width  float32 `desc:"width of the values in the column, in dots, as set by resizing -- 0 for the default width from the values"`
+	Hidden bool    `desc:"the column is hidden"`
+}
+
+// TableViewCols records the column layout of a TableView for a given struct
+// type: the columns in display order, with their widths and whether they are
+// hidden -- fields that are not listed are shown after the listed ones, in
+// the order of the struct
+type TableViewCols struct {
+	Type string              `desc:"full type name of the struct"`
+	Cols []TableViewColState `desc:"the columns, in display order"`
+}
+
+// ColIdx returns the index of the column for given field name, -1 if not
+// listed
+func (tc *TableViewCols) ColIdx(fld string) int {
+	for i, cs := range tc.Cols {
+		if cs.Field == fld {
+			return i
+		}
+	}
+	return -1
+}
+
+// Width returns the width of the column for given field name, 0 for the
+// default width
+func (tc *TableViewCols) Width(fld string) float32 {
+	if ci := tc.ColIdx(fld); ci >= 0 {
+		return tc.Cols[ci].Width
+	}
+	return 0
+}
+
+// IsHidden returns true if the column for given field name is hidden
+func (tc *TableViewCols) IsHidden(fld string) bool {
+	if ci := tc.ColIdx(fld); ci >= 0 {
+		return tc.Cols[ci].Hidden
+	}
+	return false
+}
+
+// Sync updates the columns to list exactly the given fields: the listed
+// ones in their current order, followed by any others in the order given
+func (tc *TableViewCols) Sync(all []reflect.StructField) {
+	has := make(map[string]bool, len(all))
+	for _, fld := range all {
+		has[fld.Name] = true
+	}
+	cols := make([]TableViewColState, 0, len(all))
+	for _, cs := range tc.Cols {
+		if has[cs.Field] {
+			cols = append(cols, cs)
+			delete(has, cs.Field)
+		}
+	}
+	for _, fld := range all {
+		if has[fld.Name] {
+			cols = append(cols, TableViewColState{Field: fld.Name})
+		}
+	}
+	tc.Cols = cols
+}
+
+// VisFields returns the given fields that are not hidden, in display order
+func (tc *TableViewCols) VisFields(all []reflect.StructField) []reflect.StructField {
+	if len(tc.Cols) == 0 {
+		return all
+	}
+	vis := make([]reflect.StructField, 0, len(all))
+	listed := make(map[string]bool, len(tc.Cols))
+	for _, cs := range tc.Cols {
+		listed[cs.Field] = true
+		if cs.Hidden {
+			continue
+		}
+		for _, fld := range all {
+			if fld.Name == cs.Field {
+				vis = append(vis, fld)
+				break
+			}
+		}
+	}
+	for _, fld := range all {
+		if !listed[fld.Name] {
+			vis = append(vis, fld)
+		}
+	}
+	return vis
+}
+
+// Copy returns a copy of the layout, not sharing the columns
+func (tc *TableViewCols) Copy() TableViewCols {
+	cp := TableViewCols{Type: tc.Type}
+	cp.Cols = make([]TableViewColState, len(tc.Cols))
+	copy(cp.Cols, tc.Cols)
+	return cp
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  TableViewColsPrefs
+
+// TVColsPrefs are the column layouts of TableViews, by struct type, which
+// are saved persistently in the GoGi prefs directory, alongside the
+// WinGeomPrefs
+var TVColsPrefs = TableViewColsPrefs{}
+
+// TableViewColsPrefs records the column layouts of TableViews by the full
+// type name of the struct -- looks up the layout automatically for new
+// TableViews and saves it persistently when the user changes it
+type TableViewColsPrefs map[string]TableViewCols
+
+// TableViewColsPrefsFileName is the base name of the preferences file in
+// GoGi prefs directory
+var TableViewColsPrefsFileName = "tableview_cols_prefs"
+
+// TableViewColsPrefsMu is a mutex that protects updating of TVColsPrefs
+var TableViewColsPrefsMu sync.Mutex
+
+// tvColsPrefsOpened is set when TVColsPrefs has been opened
+var tvColsPrefsOpened = false
+
+// Open TableView column layout preferences from GoGi standard prefs directory
+// -- called under mutex or at start
+func (tp *TableViewColsPrefs) Open() error {
+	tvColsPrefsOpened = true
+	if oswin.TheApp == nil {
+		return nil
+	}
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, TableViewColsPrefsFileName+".json")
+	b, err := ioutil.ReadFile(pnm)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Println(err)
+		}
+		return err
+	}
+	err = json.Unmarshal(b, tp)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// Save TableView column layout preferences to GoGi standard prefs directory
+// -- assumed to be under mutex
+func (tp *TableViewColsPrefs) Save() error {
+	if oswin.TheApp == nil {
+		return nil
+	}
+	pdir := oswin.TheApp.GoGiPrefsDir()
+	pnm := filepath.Join(pdir, TableViewColsPrefsFileName+".json")
+	b, err := json.MarshalIndent(tp, "", "  ")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = ioutil.WriteFile(pnm, b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// RecordPref records given column layout, for its struct Type, and saves
+// the preferences
+func (tp *TableViewColsPrefs) RecordPref(cols *TableViewCols) {
+	TableViewColsPrefsMu.Lock()
+	defer TableViewColsPrefsMu.Unlock()
+	if !tvColsPrefsOpened {
+		tp.Open()
+	}
+	if *tp == nil {
+		*tp = make(TableViewColsPrefs, 100)
+	}
+	if len(cols.Cols) == 0 {
+		delete(*tp, cols.Type)
+	} else {
+		(*tp)[cols.Type] = cols.Copy()
+	}
+	tp.Save()
+}
+
+// Pref returns the column layout for given struct type name, false if none
+// has been recorded
+func (tp *TableViewColsPrefs) Pref(typnm string) (TableViewCols, bool) {
+	TableViewColsPrefsMu.Lock()
+	defer TableViewColsPrefsMu.Unlock()
+	if !tvColsPrefsOpened {
+		tp.Open()
+	}
+	tc, ok := (*tp)[typnm]
+	if !ok {
+		return TableViewCols{}, false
+	}
+	return tc.Copy(), true
+}
+
+// DeleteAll deletes the file that saves the column layouts, and clears the
+// current in-memory cache
+func (tp *TableViewColsPrefs) DeleteAll() {
+	TableViewColsPrefsMu.Lock()
+	defer TableViewColsPrefsMu.Unlock()
+	if oswin.TheApp != nil {
+		pdir := oswin.TheApp.GoGiPrefsDir()
+		pnm := filepath.Join(pdir, TableViewColsPrefsFileName+".json")
+		os.Remove(pnm)
+	}
+	*tp = make(TableViewColsPrefs, 100)
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  TableView column layout
+
+// tableViewColDrag is the state of a drag on the header, resizing or moving
+// a column
+type tableViewColDrag struct {
+	col     int         // visible field index of the column, -1 if not dragging
+	resize  bool        // resizing the column, vs. moving it
+	start   image.Point // window position of the press that started the drag
+	width   float32     // width of the column at the start
+	dragged bool        // drag events have been received
+	cursor  bool        // a cursor was pushed
+}
+
+// UseColPrefs returns true if the column layout is restored from and saved
+// to TVColsPrefs -- true unless the 'col-prefs' property is false
+func (tv *TableView) UseColPrefs() bool {
+	if cpp, ok := tv.Prop("col-prefs"); ok {
+		cp, _ := kit.ToBool(cpp)
+		return cp
+	}
+	return true
+}
+
+// LoadColState sets the ColState from TVColsPrefs for the struct type, if
+// UseColPrefs, or resets it otherwise
+func (tv *TableView) LoadColState() {
+	typnm := kit.FullTypeName(tv.StructType())
+	tv.ColState = TableViewCols{Type: typnm}
+	if !tv.UseColPrefs() {
+		return
+	}
+	if tc, ok := TVColsPrefs.Pref(typnm); ok {
+		tv.ColState = tc
+		tv.ColState.Type = typnm
+	}
+}
+
+// SaveColState records the ColState in TVColsPrefs, if UseColPrefs
+func (tv *TableView) SaveColState() {
+	if !tv.UseColPrefs() || tv.ColState.Type == "" {
+		return
+	}
+	TVColsPrefs.RecordPref(&tv.ColState)
+}
+
+// ColStateUpdate saves the ColState and updates the VisFields and the
+// display for it
+func (tv *TableView) ColStateUpdate() {
+	tv.SaveColState()
+	tv.VisFields = tv.ColState.VisFields(tv.AllFields)
+	tv.NVisFields = len(tv.VisFields)
+	if kit.IfaceIsNil(tv.Slice) || tv.BuiltSlice != tv.Slice {
+		return
+	}
+	updt := tv.UpdateStart()
+	tv.ConfigSliceGrid(true)
+	tv.SetFullReRender()
+	tv.UpdateEnd(updt)
+}
+
+// ColWidth returns the width set for the values of given visible field
+// index, in dots -- 0 for the default width
+func (tv *TableView) ColWidth(fli int) float32 {
+	if fli < 0 || fli >= tv.NVisFields {
+		return 0
+	}
+	return tv.ColState.Width(tv.VisFields[fli].Name)
+}
+
+// SetColWidth sets the width of the values of given visible field index, in
+// dots -- 0 resets it to the default width -- call SaveColState to save the
+// layout, as done at the end of resizing by dragging
+func (tv *TableView) SetColWidth(fli int, wd float32) {
+	if fli < 0 || fli >= tv.NVisFields {
+		return
+	}
+	if wd > 0 && wd < TableViewColMinWidth {
+		wd = TableViewColMinWidth
+	}
+	tv.ColState.Sync(tv.AllFields)
+	ci := tv.ColState.ColIdx(tv.VisFields[fli].Name)
+	if tv.ColState.Cols[ci].Width == wd {
+		return
+	}
+	tv.ColState.Cols[ci].Width = wd
+	sgf := tv.SliceGrid()
+	if sgf == nil || wd == 0 { // default widths require new widgets
+		tv.ColStateUpdate()
+		return
+	}
+	updt := tv.UpdateStart()
+	nWidgPerRow, idxOff := tv.RowWidgetNs()
+	for r := 0; r < tv.VirtRows; r++ {
+		cidx := r*nWidgPerRow + idxOff + fli
+		if !sgf.Kids.IsValidIndex(cidx) {
+			break
+		}
+		if wb := sgf.KnownChild(cidx).(gi.Node2D).AsWidget(); wb != nil {
+			tv.SetColWidthProps(wb, wd)
+		}
+	}
+	tv.SetFullReRender()
+	tv.UpdateEnd(updt)
+}
+
+// SetColWidthProps sets the properties of given value widget for a column
+// width set by resizing, in dots
+func (tv *TableView) SetColWidthProps(wb *gi.WidgetBase, wd float32) {
+	wv := units.NewValue(wd, units.Dot)
+	wb.SetProp("width", wv)
+	wb.SetProp("min-width", wv)
+	wb.SetProp("max-width", wv)
+}
+
+// MoveCol moves the column at given visible field index to be at the other
+// given visible field index, and saves the layout
+func (tv *TableView) MoveCol(from, to int) {
+	if from == to || from < 0 || to < 0 || from >= tv.NVisFields || to >= tv.NVisFields {
+		return
+	}
+	tv.ColState.Sync(tv.AllFields)
+	fi := tv.ColState.ColIdx(tv.VisFields[from].Name)
+	ti := tv.ColState.ColIdx(tv.VisFields[to].Name)
+	cs := tv.ColState.Cols[fi]
+	cols := append(tv.ColState.Cols[:fi:fi], tv.ColState.Cols[fi+1:]...)
+	cols = append(cols[:ti], append([]TableViewColState{cs}, cols[ti:]...)...)
+	tv.ColState.Cols = cols
+	tv.ColStateUpdate()
+}
+
+// SetColHidden sets whether the column for given field name is hidden, and
+// saves the layout -- the last visible column cannot be hidden -- returns
+// false if not changed
+func (tv *TableView) SetColHidden(fld string, hide bool) bool {
+	if hide && tv.NVisFields <= 1 {
+		return false
+	}
+	tv.ColState.Sync(tv.AllFields)
+	ci := tv.ColState.ColIdx(fld)
+	if ci < 0 || tv.ColState.Cols[ci].Hidden == hide {
+		return false
+	}
+	tv.ColState.Cols[ci].Hidden = hide
+	tv.ColStateUpdate()
+	return true
+}
+
+// ResetCols resets the layout of the columns to the default: all shown, in
+// the order of the struct, with default widths
+func (tv *TableView) ResetCols() {
+	tv.ColState.Cols = nil
+	tv.ColStateUpdate()
+}
+
+// HeaderColFromPos returns the visible field index of the header at given
+// window position, and whether the position is at the right edge of it,
+// where dragging resizes the column -- -1 if not on a header
+func (tv *TableView) HeaderColFromPos(pos image.Point) (int, bool) {
+	sgh := tv.SliceHeader()
+	if sgh == nil || !pos.In(sgh.WinBBox) {
+		return -1, false
+	}
+	_, idxOff := tv.RowWidgetNs()
+	for fli := 0; fli < tv.NVisFields; fli++ {
+		if !sgh.Kids.IsValidIndex(idxOff + fli) {
+			break
+		}
+		hw := sgh.KnownChild(idxOff + fli).(gi.Node2D).AsWidget()
+		bb := hw.WinBBox
+		if pos.X < bb.Min.X+TableViewColResizePix && fli > 0 && pos.X >= bb.Min.X-TableViewColResizePix {
+			return fli - 1, true
+		}
+		if pos.X >= bb.Min.X && pos.X < bb.Max.X+int(sgh.Spacing.Dots) {
+			return fli, pos.X >= bb.Max.X-TableViewColResizePix
+		}
+	}
+	return -1, false
+}
+
+// ColCurWidth returns the current width of the values of given visible
+// field index, from the layout of the first row
+func (tv *TableView) ColCurWidth(fli int) float32 {
+	sgf := tv.SliceGrid()
+	_, idxOff := tv.RowWidgetNs()
+	if sgf == nil || !sgf.Kids.IsValidIndex(idxOff+fli) {
+		return 0
+	}
+	wb := sgf.KnownChild(idxOff + fli).(gi.Node2D).AsWidget()
+	return wb.LayData.AllocSize.X - 2*wb.Sty.BoxSpace()
+}
+
+// ColsCtxtMenu adds actions to given menu to show or hide each of the
+// columns, and to reset the column layout
+func (tv *TableView) ColsCtxtMenu(m *gi.Menu) {
+	tv.ColState.Sync(tv.AllFields)
+	for _, cs := range tv.ColState.Cols {
+		lbl := "Hide " + cs.Field
+		if cs.Hidden {
+			lbl = "Show " + cs.Field
+		}
+		m.AddAction(gi.ActOpts{Label: lbl, Data: cs.Field},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				tvv := recv.Embed(KiT_TableView).(*TableView)
+				act := send.(*gi.Action)
+				fld := act.Data.(string)
+				tvv.SetColHidden(fld, !tvv.ColState.IsHidden(fld))
+			})
+	}
+	m.AddSeparator("sep-cols")
+	m.AddAction(gi.ActOpts{Label: "Reset Columns", Tooltip: "show all the columns, in the default order and widths"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv := recv.Embed(KiT_TableView).(*TableView)
+			tvv.ResetCols()
+		})
+}
+
+// HeaderEvents connects to the mouse events on the header, for resizing
+// columns by dragging the separators between headers, moving columns by
+// dragging the headers, and the ColsCtxtMenu
+func (tv *TableView) HeaderEvents() {
+	sgh := tv.SliceHeader()
+	if sgh == nil {
+		return
+	}
+	tv.colDrag.col = -1
+	sgh.ConnectEvent(oswin.MouseEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		switch {
+		case me.Button == mouse.Right && me.Action == mouse.Release:
+			if fli, _ := tv.HeaderColFromPos(me.Where); fli < 0 {
+				return
+			}
+			me.SetProcessed()
+			var men gi.Menu
+			tv.ColsCtxtMenu(&men)
+			gi.PopupMenu(men, me.Where.X, me.Where.Y, tv.Viewport, tv.Nm+"-cols-menu")
+		case me.Button == mouse.Left && me.Action == mouse.Press:
+			fli, edge := tv.HeaderColFromPos(me.Where)
+			tv.colDrag = tableViewColDrag{col: fli, resize: edge, start: me.Where}
+			if fli < 0 || !edge {
+				return
+			}
+			me.SetProcessed() // no click on the header
+			tv.colDrag.width = tv.ColCurWidth(fli)
+			tv.colDrag.cursor = true
+			oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Push(cursor.LeftRight)
+		case me.Button == mouse.Left && me.Action == mouse.Release:
+			cd := tv.colDrag
+			tv.colDrag.col = -1
+			if cd.col < 0 {
+				return
+			}
+			if cd.cursor {
+				oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Pop()
+			}
+			if !cd.dragged {
+				if cd.resize {
+					me.SetProcessed()
+				}
+				return
+			}
+			me.SetProcessed() // not a click
+			_, idxOff := tv.RowWidgetNs()
+			if hdr, ok := sgh.KnownChild(idxOff + cd.col).(*gi.Action); ok {
+				hdr.SetButtonState(gi.ButtonActive)
+			}
+			if cd.resize {
+				tv.SaveColState()
+				return
+			}
+			if to, _ := tv.HeaderColFromPos(image.Point{me.Where.X, sgh.WinBBox.Min.Y}); to >= 0 {
+				tv.MoveCol(cd.col, to)
+			}
+		}
+	})
+	sgh.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.DragEvent)
+		cd := &tv.colDrag
+		if cd.col < 0 {
+			return
+		}
+		me.SetProcessed()
+		cd.dragged = true
+		if cd.resize {
+			tv.SetColWidth(cd.col, cd.width+float32(me.Where.X-cd.start.X))
+			return
+		}
+		if !cd.cursor {
+			cd.cursor = true
+			oswin.TheApp.Cursor(tv.Viewport.Win.OSWin).Push(cursor.DragMove)
+		}
+	})
+}
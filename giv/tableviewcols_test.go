@@ -0,0 +1,123 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testColsView returns a TableView for testRow that is not built, and does
+// not use the column prefs
+func testColsView() *TableView {
+	tv := &TableView{}
+	tv.InitName(tv, "tv")
+	tv.SetProp("col-prefs", false)
+	tv.AllFields = testRowFields()
+	tv.ColStateUpdate()
+	return tv
+}
+
+func TestTableViewColsSync(t *testing.T) {
+	tc := TableViewCols{Cols: []TableViewColState{{Field: "Val", Width: 10}, {Field: "Gone"}, {Field: "Name", Hidden: true}}}
+	tc.Sync(testRowFields())
+	want := []TableViewColState{{Field: "Val", Width: 10}, {Field: "Name", Hidden: true}, {Field: "Size"}, {Field: "On"}, {Field: "Notes"}}
+	if !reflect.DeepEqual(tc.Cols, want) {
+		t.Errorf("sync: %v", tc.Cols)
+	}
+	if tc.Width("Val") != 10 || tc.Width("Size") != 0 || !tc.IsHidden("Name") || tc.IsHidden("Gone") {
+		t.Errorf("width / hidden after sync")
+	}
+}
+
+func TestTableViewColsVisFields(t *testing.T) {
+	all := testRowFields()
+	tc := TableViewCols{}
+	if nms := testFieldNames(tc.VisFields(all)); nms != "Name,Size,Val,On,Notes" {
+		t.Errorf("default vis fields: %v", nms)
+	}
+	tc.Cols = []TableViewColState{{Field: "Val"}, {Field: "Name", Hidden: true}, {Field: "Gone"}}
+	if nms := testFieldNames(tc.VisFields(all)); nms != "Val,Size,On,Notes" {
+		t.Errorf("vis fields: %v", nms)
+	}
+}
+
+func TestTableViewMoveCol(t *testing.T) {
+	tv := testColsView()
+	tv.MoveCol(0, 2)
+	if nms := testFieldNames(tv.VisFields); nms != "Size,Val,Name,On,Notes" {
+		t.Errorf("move right: %v", nms)
+	}
+	tv.MoveCol(3, 0)
+	if nms := testFieldNames(tv.VisFields); nms != "On,Size,Val,Name,Notes" {
+		t.Errorf("move left: %v", nms)
+	}
+	tv.MoveCol(1, 5) // out of range
+	if nms := testFieldNames(tv.VisFields); nms != "On,Size,Val,Name,Notes" {
+		t.Errorf("move out of range: %v", nms)
+	}
+	tv.ResetCols()
+	if nms := testFieldNames(tv.VisFields); nms != "Name,Size,Val,On,Notes" {
+		t.Errorf("reset: %v", nms)
+	}
+}
+
+func TestTableViewSetColHidden(t *testing.T) {
+	tv := testColsView()
+	if !tv.SetColHidden("Size", true) || tv.SetColHidden("Size", true) || tv.SetColHidden("Nope", true) {
+		t.Errorf("set hidden return values")
+	}
+	if nms := testFieldNames(tv.VisFields); nms != "Name,Val,On,Notes" {
+		t.Errorf("hidden Size: %v", nms)
+	}
+	tv.MoveCol(2, 0) // moves On before Name, past the hidden Size
+	if nms := testFieldNames(tv.VisFields); nms != "On,Name,Val,Notes" {
+		t.Errorf("move with hidden: %v", nms)
+	}
+	tv.SetColHidden("Name", true)
+	tv.SetColHidden("Val", true)
+	tv.SetColHidden("Notes", true)
+	if tv.SetColHidden("On", true) || tv.NVisFields != 1 {
+		t.Errorf("last visible column should not be hidden: %v", tv.NVisFields)
+	}
+	if !tv.SetColHidden("Size", false) || testFieldNames(tv.VisFields) != "On,Size" {
+		t.Errorf("unhide Size: %v", testFieldNames(tv.VisFields))
+	}
+}
+
+func TestTableViewExportHiddenCols(t *testing.T) {
+	tv := testColsView()
+	tv.SetColHidden("Size", true)
+	if nms := testFieldNames(tv.ExportFields()); nms != "Name,Val,On" {
+		t.Errorf("export fields: %v", nms)
+	}
+	if nms := testFieldNames(tv.ImportFields()); nms != "Name,Val,On,Size" {
+		t.Errorf("import fields: %v", nms)
+	}
+	sl := []testRow{{Name: "x", Size: 1, Val: 1.5, On: true}}
+	var b bytes.Buffer
+	if err := SliceExportCSV(&b, &sl, tv.ExportFields(), nil, ','); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "Full Name,Val,On\nx,1.5,true\n" {
+		t.Errorf("export with hidden column: %q", b.String())
+	}
+	var isl []testRow
+	if err := SliceImportCSV(strings.NewReader("Name,Size,Val,On\nx,1,1.5,true\n"), &isl, tv.ImportFields(), ','); err != nil {
+		t.Errorf("import with hidden column: %v", err)
+	}
+	if len(isl) != 1 || isl[0] != sl[0] {
+		t.Errorf("import with hidden column: %v", isl)
+	}
+	isl = nil
+	if err := SliceImportCSV(strings.NewReader("y,2.5,false\n"), &isl, tv.ImportFields(), ','); err != nil {
+		t.Errorf("import without header: %v", err)
+	}
+	if len(isl) != 1 || isl[0] != (testRow{Name: "y", Val: 2.5}) {
+		t.Errorf("import without header: %v", isl)
+	}
+}
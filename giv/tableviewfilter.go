@@ -0,0 +1,275 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goki/ki/kit"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Sorting
+
+// TableViewSortKey is one key of a multi-column sort of a TableView, in
+// order of precedence
+type TableViewSortKey struct {
+	Field string `desc:"name of the struct field to sort by"`
+	Desc  bool   `desc:"sort in descending order"`
+}
+
+// String returns the key as Field:up or Field:down, as used in SortFieldName
+func (sk TableViewSortKey) String() string {
+	if sk.Desc {
+		return sk.Field + ":down"
+	}
+	return sk.Field + ":up"
+}
+
+// intValuer is satisfied by types such as FileSize and FileTime that
+// provide an int value for sorting
+type intValuer interface {
+	Int() int64
+}
+
+// ValueFloat returns the numerical value of given value, for numbers and
+// types with an Int() int64 method (e.g., FileSize, FileTime) -- false if
+// not a number
+func ValueFloat(v reflect.Value) (float64, bool) {
+	if v.CanInterface() {
+		if iv, ok := v.Interface().(intValuer); ok {
+			return float64(iv.Int()), true
+		}
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// CompareValues compares two values of the same type for sorting, returning
+// -1, 0, or 1 if a is less than, equal to, or greater than b -- numbers
+// (including types with an Int() int64 method) and time.Time are compared by
+// value, and everything else by its string representation
+func CompareValues(a, b reflect.Value) int {
+	if !a.CanInterface() || !b.CanInterface() {
+		return 0
+	}
+	if at, ok := a.Interface().(time.Time); ok {
+		bt := b.Interface().(time.Time)
+		switch {
+		case at.Before(bt):
+			return -1
+		case at.After(bt):
+			return 1
+		}
+		return 0
+	}
+	if af, ok := ValueFloat(a); ok {
+		bf, _ := ValueFloat(b)
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(kit.ToString(a.Interface()), kit.ToString(b.Interface()))
+}
+
+// StructSliceSortKeys stably sorts the given indexes of the elements of a
+// slice of structs (or pointers to structs) by the given fields of those
+// elements, in order of precedence -- the slice itself is not changed --
+// keys for fields that do not exist are ignored, and nil pointer elements
+// are sorted last
+func StructSliceSortKeys(struSlice interface{}, idxs []int, keys []TableViewSortKey) {
+	mv := reflect.ValueOf(struSlice)
+	mvnp := kit.NonPtrValue(mv)
+	struTyp := kit.NonPtrType(kit.SliceElType(struSlice))
+	fidxs := make([][]int, 0, len(keys))
+	descs := make([]bool, 0, len(keys))
+	for _, sk := range keys {
+		fld, ok := struTyp.FieldByName(sk.Field)
+		if !ok {
+			log.Printf("giv.StructSliceSortKeys: field name: %v not found\n", sk.Field)
+			continue
+		}
+		fidxs = append(fidxs, fld.Index)
+		descs = append(descs, sk.Desc)
+	}
+	if len(fidxs) == 0 {
+		return
+	}
+	sort.SliceStable(idxs, func(i, j int) bool {
+		iv := kit.NonPtrValue(mvnp.Index(idxs[i]))
+		jv := kit.NonPtrValue(mvnp.Index(idxs[j]))
+		if !iv.IsValid() || !jv.IsValid() {
+			return iv.IsValid()
+		}
+		for k, fi := range fidxs {
+			cmp := CompareValues(iv.FieldByIndex(fi), jv.FieldByIndex(fi))
+			if cmp == 0 {
+				continue
+			}
+			if descs[k] {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+}
+
+// SortKeysFromString parses a list of sort keys from a comma-separated list
+// of Field:up or Field:down (or just Field, for ascending)
+func SortKeysFromString(s string) []TableViewSortKey {
+	var keys []TableViewSortKey
+	for _, ks := range strings.Split(s, ",") {
+		ks = strings.TrimSpace(ks)
+		if ks == "" {
+			continue
+		}
+		spnm := strings.Split(ks, ":")
+		sk := TableViewSortKey{Field: spnm[0]}
+		if len(spnm) == 2 && spnm[1] == "down" {
+			sk.Desc = true
+		}
+		keys = append(keys, sk)
+	}
+	return keys
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  Filtering
+
+// TableViewFilter is a compiled filter predicate for one column of a
+// TableView, parsed from a filter expression:
+//   - /regexp/ matches the string value of the field against the regexp.
+//   - lo..hi matches numerical values in the inclusive range, where either
+//     end can be omitted, e.g., 10.. for all values >= 10.
+//   - anything else matches string values that contain the text, ignoring case.
+type TableViewFilter struct {
+	Field  string         `desc:"name of the struct field that is filtered"`
+	Expr   string         `desc:"the filter expression"`
+	Re     *regexp.Regexp `desc:"regexp, for a /regexp/ expression"`
+	Range  bool           `desc:"this is a lo..hi range expression"`
+	Min    float64        `desc:"lower end of the range, if HasMin"`
+	Max    float64        `desc:"upper end of the range, if HasMax"`
+	HasMin bool           `desc:"range has a lower end"`
+	HasMax bool           `desc:"range has an upper end"`
+	Text   string         `desc:"lowercase text for a contains expression"`
+}
+
+// NewTableViewFilter parses given filter expression for given field --
+// returns an error for an invalid regexp
+func NewTableViewFilter(fld, expr string) (*TableViewFilter, error) {
+	tf := &TableViewFilter{Field: fld, Expr: expr}
+	ex := strings.TrimSpace(expr)
+	if len(ex) >= 2 && strings.HasPrefix(ex, "/") && strings.HasSuffix(ex, "/") {
+		re, err := regexp.Compile(ex[1 : len(ex)-1])
+		if err != nil {
+			return nil, fmt.Errorf("giv.TableViewFilter: invalid regexp for field: %v: %v", fld, err)
+		}
+		tf.Re = re
+		return tf, nil
+	}
+	if rs := strings.Split(ex, ".."); len(rs) == 2 && ex != ".." {
+		lo, hi := strings.TrimSpace(rs[0]), strings.TrimSpace(rs[1])
+		var lerr, herr error
+		if lo != "" {
+			tf.Min, lerr = strconv.ParseFloat(lo, 64)
+			tf.HasMin = lerr == nil
+		}
+		if hi != "" {
+			tf.Max, herr = strconv.ParseFloat(hi, 64)
+			tf.HasMax = herr == nil
+		}
+		if lerr == nil && herr == nil {
+			tf.Range = true
+			return tf, nil
+		}
+	}
+	tf.Text = strings.ToLower(ex)
+	return tf, nil
+}
+
+// Matches returns true if given field value passes the filter
+func (tf *TableViewFilter) Matches(v reflect.Value) bool {
+	switch {
+	case tf.Re != nil:
+		return tf.Re.MatchString(kit.ToString(v.Interface()))
+	case tf.Range:
+		fv, ok := ValueFloat(v)
+		if !ok {
+			fv, ok = kit.ToFloat(v.Interface())
+			if !ok {
+				return false
+			}
+		}
+		if tf.HasMin && fv < tf.Min {
+			return false
+		}
+		if tf.HasMax && fv > tf.Max {
+			return false
+		}
+		return true
+	}
+	return strings.Contains(strings.ToLower(kit.ToString(v.Interface())), tf.Text)
+}
+
+// StructSliceFilter returns the indexes of the elements of a slice of
+// structs (or pointers to structs) that pass all of the given filters, in
+// order -- nil pointer elements never pass
+func StructSliceFilter(struSlice interface{}, filts []*TableViewFilter) []int {
+	mv := reflect.ValueOf(struSlice)
+	mvnp := kit.NonPtrValue(mv)
+	sz := mvnp.Len()
+	struTyp := kit.NonPtrType(kit.SliceElType(struSlice))
+	fidxs := make([][]int, len(filts))
+	for fi, tf := range filts {
+		if fld, ok := struTyp.FieldByName(tf.Field); ok {
+			fidxs[fi] = fld.Index
+		}
+	}
+	idxs := make([]int, 0, sz)
+	for i := 0; i < sz; i++ {
+		val := kit.NonPtrValue(mvnp.Index(i))
+		if !val.IsValid() {
+			continue
+		}
+		pass := true
+		for fi, tf := range filts {
+			if fidxs[fi] == nil {
+				continue
+			}
+			if !tf.Matches(val.FieldByIndex(fidxs[fi])) {
+				pass = false
+				break
+			}
+		}
+		if pass {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package giv
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewTableViewFilter(t *testing.T) {
+	tests := []struct {
+		expr  string
+		err   bool
+		val   interface{}
+		match bool
+	}{
+		{"/^a.c$/", false, "abc", true},
+		{"/^a.c$/", false, "abcd", false},
+		{"/a(/", true, nil, false},
+		{"10..", false, 10, true},
+		{"10..", false, 9.5, false},
+		{"..5", false, 5, true},
+		{"..5", false, 6, false},
+		{"2..4", false, float32(3), true},
+		{"2..4", false, "3", true},
+		{"2..4", false, "x", false},
+		{"Foo", false, "a food item", true},
+		{"Foo", false, "bar", false},
+		{"a..b", false, "a..b", true},
+	}
+	for _, tt := range tests {
+		tf, err := NewTableViewFilter("Name", tt.expr)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%v: expected error", tt.expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", tt.expr, err)
+			continue
+		}
+		if m := tf.Matches(reflect.ValueOf(tt.val)); m != tt.match {
+			t.Errorf("%v matches %v: %v, want %v", tt.expr, tt.val, m, tt.match)
+		}
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		a, b interface{}
+		cmp  int
+	}{
+		{1, 2, -1},
+		{2, 1, 1},
+		{uint8(3), uint8(3), 0},
+		{float32(1.5), float32(-1), 1},
+		{false, true, -1},
+		{"b", "a", 1},
+		{"10", "9", -1}, // strings by string value
+		{now, now.Add(time.Second), -1},
+		{now.Add(time.Second), now, 1},
+		{FileSize(2048), FileSize(100), 1},
+	}
+	for _, tt := range tests {
+		if cmp := CompareValues(reflect.ValueOf(tt.a), reflect.ValueOf(tt.b)); cmp != tt.cmp {
+			t.Errorf("compare %v %v: %v, want %v", tt.a, tt.b, cmp, tt.cmp)
+		}
+	}
+}
+
+func TestStructSliceSortKeys(t *testing.T) {
+	sl := []*testRow{
+		{Name: "c", Size: 2, Val: 1},
+		nil,
+		{Name: "a", Size: 1, Val: 1},
+		{Name: "b", Size: 2, Val: 2},
+		{Name: "d", Size: 1, Val: 1},
+		{Name: "e", Size: 2, Val: 1},
+	}
+	orig := make([]*testRow, len(sl))
+	copy(orig, sl)
+	idxs := []int{0, 1, 2, 3, 4, 5}
+	StructSliceSortKeys(&sl, idxs, []TableViewSortKey{{Field: "Size", Desc: true}, {Field: "Val"}, {Field: "NoSuchField"}})
+	// c, e, b, a, d, with the nil element last
+	if !reflect.DeepEqual(idxs, []int{0, 5, 3, 2, 4, 1}) {
+		t.Errorf("sort order: %v", idxs)
+	}
+	for i := range sl {
+		if sl[i] != orig[i] {
+			t.Fatalf("slice changed by sort at %v: %v, was %v", i, sl[i], orig[i])
+		}
+	}
+	// only the given indexes are sorted, e.g., those passing a filter
+	idxs = []int{1, 2, 3, 4}
+	StructSliceSortKeys(&sl, idxs, SortKeysFromString("Name:down"))
+	if !reflect.DeepEqual(idxs, []int{4, 3, 2, 1}) {
+		t.Errorf("descending name sort: %v", idxs)
+	}
+}
+
+func TestStructSliceFilter(t *testing.T) {
+	sl := []*testRow{{Name: "apple", Size: 1}, nil, {Name: "banana", Size: 5}, {Name: "cherry", Size: 10}}
+	nf, _ := NewTableViewFilter("Name", "an")
+	sf, _ := NewTableViewFilter("Size", "2..")
+	if idxs := StructSliceFilter(&sl, []*TableViewFilter{sf}); !reflect.DeepEqual(idxs, []int{2, 3}) {
+		t.Errorf("size filter: %v", idxs)
+	}
+	if idxs := StructSliceFilter(&sl, []*TableViewFilter{nf, sf}); !reflect.DeepEqual(idxs, []int{2}) {
+		t.Errorf("name and size filter: %v", idxs)
+	}
+	if idxs := StructSliceFilter(&sl, nil); !reflect.DeepEqual(idxs, []int{0, 2, 3}) {
+		t.Errorf("no filter: %v", idxs)
+	}
+}
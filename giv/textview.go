@@ -20,6 +20,7 @@ import (
 	"github.com/goki/gi/histyle"
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/ime"
 	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mimedata"
 	"github.com/goki/gi/oswin/mouse"
@@ -70,6 +71,7 @@ type TextView struct {
 	BlinkOn       bool                      `json:"-" xml:"-" desc:"oscillates between on and off for blinking"`
 	CursorMu      sync.Mutex                `json:"-" xml:"-" view:"-" desc:"mutex protecting cursor rendering -- shared between blink and main code"`
 	HasLinks      bool                      `json:"-" xml:"-" desc:"at least one of the renders has links -- determines if we set the cursor for hand movements"`
+	Preedit       gi.Preedit                `json:"-" xml:"-" view:"-" desc:"composition text from an input method editor (IME), shown at the cursor while composing"`
 	lastRecenter  int
 	lastFilename  gi.FileName
 }
@@ -87,6 +89,7 @@ var TextViewProps = ki.Props{
 	"margin":           units.NewValue(2, units.Px),
 	"vertical-align":   gi.AlignTop,
 	"text-align":       gi.AlignLeft,
+	"unicode-bidi":     gi.BidiPlaintext,
 	"tab-size":         4,
 	"color":            &gi.Prefs.Colors.Font,
 	"background-color": &gi.Prefs.Colors.Background,
@@ -802,6 +805,41 @@ func (tv *TextView) CursorForward(steps int) {
 	tv.CursorSelect(org)
 }
 
+// CursorMoveVisual moves the cursor one position visually to the right (dir
+// > 0) or left (dir < 0) within the current (wrapped) line of bidirectional
+// text, which can be forward or backward in the text -- at the visual end of
+// the line, it moves on to the logically next or previous line, and
+// otherwise it is the same as CursorForward or CursorBackward
+func (tv *TextView) CursorMoveVisual(dir int) {
+	tv.ValidateCursor()
+	fwd := dir > 0
+	if tv.CursorPos.Ln < len(tv.Renders) {
+		tr := &tv.Renders[tv.CursorPos.Ln]
+		_, si, ri, _ := tr.RuneRelPos(tv.CursorPos.Ch)
+		if si >= 0 && tr.Spans[si].HasBidi() {
+			sr := &tr.Spans[si]
+			if np := sr.CursorMoveVisual(ri, dir); np >= 0 {
+				updt := tv.Viewport.Win.UpdateStart()
+				defer tv.Viewport.Win.UpdateEnd(updt)
+				org := tv.CursorPos
+				tv.CursorPos.Ch += np - ri
+				tv.SetCursorCol(tv.CursorPos)
+				tv.SetCursorShow(tv.CursorPos)
+				tv.CursorSelect(org)
+				return
+			}
+			if sr.Dir == gi.RLTB { // visual end of rtl line is logical start
+				fwd = !fwd
+			}
+		}
+	}
+	if fwd {
+		tv.CursorForward(1)
+	} else {
+		tv.CursorBackward(1)
+	}
+}
+
 // CursorForwardWord moves the cursor forward by words
 func (tv *TextView) CursorForwardWord(steps int) {
 	updt := tv.Viewport.Win.UpdateStart()
@@ -2688,7 +2726,14 @@ func (tv *TextView) RenderCursor(on bool) {
 	} else {
 		win.InactivateSprite(sp.Nm)
 	}
-	sp.Geom.Pos = tv.CharStartPos(tv.CursorPos).ToPointFloor()
+	cpos := tv.CharStartPos(tv.CursorPos)
+	if tv.Preedit.IsActive() {
+		cpos.X += tv.Preedit.CursorOff
+	}
+	sp.Geom.Pos = cpos.ToPointFloor()
+	if on && win.OSWin != nil {
+		win.OSWin.SetIMEPos(image.Point{sp.Geom.Pos.X, sp.Geom.Pos.Y + int(tv.FontHeight)})
+	}
 	win.RenderOverlays() // needs an explicit call!
 	win.UpdateSig()      // publish
 }
@@ -2773,6 +2818,10 @@ func (tv *TextView) RenderRegionBox(reg TextRegion, state TextViewStates) {
 	sty := &tv.StateStyles[state]
 	spc := sty.BoxSpace()
 
+	if tv.RenderRegionBoxBidi(reg, sty) {
+		return
+	}
+
 	rst := tv.RenderStartPos()
 	ex := float32(tv.VpBBox.Max.X) - spc
 	sx := rst.X + tv.LineNoOff
@@ -2804,6 +2853,53 @@ func (tv *TextView) RenderRegionBox(reg TextRegion, state TextViewStates) {
 	pc.FillBox(rs, sed, epos.Sub(sed), &sty.Font.BgColor)
 }
 
+// RenderRegionBoxBidi renders the region box for a region of lines that
+// have bidirectional text, where the region can be visually discontinuous
+// within each line, using given style -- returns false if the lines do not
+// have any bidi text, for standard RenderRegionBox rendering
+func (tv *TextView) RenderRegionBoxBidi(reg TextRegion, sty *gi.Style) bool {
+	st := reg.Start
+	ed := reg.End
+	if ed.Ln >= len(tv.Renders) || ed.Ln >= tv.NLines {
+		return false
+	}
+	has := false
+	for ln := st.Ln; ln <= ed.Ln && !has; ln++ {
+		for si := range tv.Renders[ln].Spans {
+			if tv.Renders[ln].Spans[si].HasBidi() {
+				has = true
+				break
+			}
+		}
+	}
+	if !has {
+		return false
+	}
+	rs := &tv.Viewport.Render
+	pc := &rs.Paint
+	sx := tv.RenderStartPos().X + tv.LineNoOff
+	for ln := st.Ln; ln <= ed.Ln; ln++ {
+		stc := 0
+		if ln == st.Ln {
+			stc = st.Ch
+		}
+		edc := tv.Buf.LineLen(ln)
+		if ln == ed.Ln {
+			edc = ed.Ch
+		}
+		spoff := 0
+		for si := range tv.Renders[ln].Spans {
+			sr := &tv.Renders[ln].Spans[si]
+			y := tv.CharStartPos(TextPos{Ln: ln, Ch: spoff}).Y
+			for _, rng := range sr.VisualRangesLR(stc-spoff, edc-spoff) {
+				pc.FillBox(rs, gi.Vec2D{sx + rng[0], y}, gi.Vec2D{rng[1] - rng[0], tv.LineHeight}, &sty.Font.BgColor)
+			}
+			spoff += len(sr.Text)
+		}
+	}
+	return true
+}
+
 // RenderStartPos is absolute rendering start position from our allocpos
 func (tv *TextView) RenderStartPos() gi.Vec2D {
 	st := &tv.Sty
@@ -2909,6 +3005,9 @@ func (tv *TextView) RenderAllLinesInBounds() {
 			lp.X += tv.LineNoOff
 			tv.Renders[ln].Render(rs, lp) // not top pos -- already has baseline offset
 		}
+		if tv.CursorPos.Ln >= stln && tv.CursorPos.Ln <= edln {
+			tv.RenderPreedit()
+		}
 		rs.Unlock()
 		if tv.HasLineNos() {
 			rs.PopBounds()
@@ -2918,6 +3017,16 @@ func (tv *TextView) RenderAllLinesInBounds() {
 	}
 }
 
+// RenderPreedit renders the composition text from an input method editor
+// (IME), if any, at the cursor -- called within context of other render
+func (tv *TextView) RenderPreedit() {
+	if !tv.Preedit.IsActive() {
+		return
+	}
+	sty := &tv.Sty
+	tv.Preedit.RenderAt(&tv.Viewport.Render, tv.CharStartPos(tv.CursorPos), &sty.Font, &sty.UnContext, &sty.Text, &sty.Font.BgColor)
+}
+
 // RenderLineNosBoxAll renders the background for the line numbers in a darker shade
 func (tv *TextView) RenderLineNosBoxAll() {
 	if !tv.HasLineNos() {
@@ -3065,6 +3174,9 @@ func (tv *TextView) RenderLines(st, ed int) bool {
 			lp.X += tv.LineNoOff
 			tv.Renders[ln].Render(rs, lp) // not top pos -- already has baseline offset
 		}
+		if tv.CursorPos.Ln >= visSt && tv.CursorPos.Ln <= visEd {
+			tv.RenderPreedit()
+		}
 		rs.Unlock()
 		if tv.HasLineNos() {
 			rs.PopBounds()
@@ -3193,6 +3305,10 @@ func (tv *TextView) PixelToCursor(pt image.Point) TextPos {
 	if rsz == 0 {
 		return TextPos{Ln: cln, Ch: spoff}
 	}
+	if sr := &tv.Renders[cln].Spans[si]; sr.HasBidi() { // rendered positions not in order
+		x := float32(pt.X) + xoff - (tv.RenderStartPos().X + tv.LineNoOff)
+		return TextPos{Ln: cln, Ch: spoff + sr.CursorAtX(x)}
+	}
 	// fmt.Printf("sc: %v  rsz: %v\n", sc, rsz)
 
 	c, _ := tv.Renders[cln].SpanPosToRuneIdx(si, rsz-1) // end
@@ -3299,12 +3415,18 @@ func (tv *TextView) ShiftSelect(kt *key.ChordEvent) {
 	}
 }
 
+// KeyContext satisfies the gi.KeyContexter interface -- text views use the
+// TextView context-specific key map
+func (tv *TextView) KeyContext() gi.KeyContext {
+	return gi.KeyContextTextView
+}
+
 // KeyInput handles keyboard input into the text field and from the completion menu
 func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 	if gi.KeyEventTrace {
 		fmt.Printf("TextView KeyInput: %v\n", tv.PathUnique())
 	}
-	kf := gi.KeyFun(kt.Chord())
+	kf := gi.KeyFunContext(kt.Chord(), gi.KeyContextTextView)
 	win := tv.ParentWindow()
 
 	tv.RefreshIfNeeded()
@@ -3356,7 +3478,7 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 		tv.ISearchCancel() // note: may need to generalize to cancel more stuff
 		kt.SetProcessed()
 		tv.ShiftSelect(kt)
-		tv.CursorForward(1)
+		tv.CursorMoveVisual(1)
 		tv.OfferComplete()
 	case gi.KeyFunWordRight:
 		tv.ISearchCancel()
@@ -3368,7 +3490,7 @@ func (tv *TextView) KeyInput(kt *key.ChordEvent) {
 		tv.ISearchCancel()
 		kt.SetProcessed()
 		tv.ShiftSelect(kt)
-		tv.CursorBackward(1)
+		tv.CursorMoveVisual(-1)
 		tv.OfferComplete()
 	case gi.KeyFunWordLeft:
 		tv.ISearchCancel()
@@ -3743,6 +3865,23 @@ func (tv *TextView) MouseEvent(me *mouse.Event) {
 	}
 }
 
+// IMEInput handles composed text input from an input method editor (IME) --
+// the preedit text is shown at the cursor until the final text is committed
+// and inserted
+func (tv *TextView) IMEInput(ie *ime.Event) {
+	if tv.IsInactive() || tv.ISearch.On || tv.QReplace.On {
+		return
+	}
+	ie.SetProcessed()
+	tv.Preedit.SetFromEvent(ie)
+	if ie.Action == ime.Commit {
+		tv.InsertAtCursor([]byte(ie.Text))
+		tv.OfferComplete()
+	}
+	tv.RenderLines(tv.CursorPos.Ln, tv.CursorPos.Ln)
+	tv.RenderCursor(true)
+}
+
 func (tv *TextView) MouseMoveEvent() {
 	if !tv.HasLinks {
 		return
@@ -3815,6 +3954,11 @@ func (tv *TextView) TextViewEvents() {
 		kt := d.(*key.ChordEvent)
 		txf.KeyInput(kt)
 	})
+	tv.ConnectEvent(oswin.IMEEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		txf := recv.Embed(KiT_TextView).(*TextView)
+		ie := d.(*ime.Event)
+		txf.IMEInput(ie)
+	})
 	if dlg, ok := tv.Viewport.This().(*gi.Dialog); ok {
 		dlg.DialogSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			txf, _ := recv.Embed(KiT_TextView).(*TextView)
@@ -3936,6 +4080,7 @@ func (tv *TextView) FocusChanged2D(change gi.FocusChanges) {
 	switch change {
 	case gi.FocusLost:
 		tv.ClearFlag(int(TextViewFocusActive))
+		tv.Preedit.Clear()
 		// tv.EditDone()
 		tv.UpdateSig()
 		// fmt.Printf("lost focus: %v\n", tv.Nm)
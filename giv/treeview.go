@@ -1352,11 +1352,17 @@ func (tv *TreeView) RootTreeView() *TreeView {
 	return rn
 }
 
+// KeyContext satisfies the gi.KeyContexter interface -- tree views use the
+// TreeView context-specific key map
+func (tv *TreeView) KeyContext() gi.KeyContext {
+	return gi.KeyContextTreeView
+}
+
 func (tv *TreeView) KeyInput(kt *key.ChordEvent) {
 	if gi.KeyEventTrace {
 		fmt.Printf("TreeView KeyInput: %v\n", tv.PathUnique())
 	}
-	kf := gi.KeyFun(kt.Chord())
+	kf := gi.KeyFunContext(kt.Chord(), gi.KeyContextTreeView)
 	selMode := mouse.SelectModeBits(kt.Modifiers)
 
 	// first all the keys that work for inactive and active
@@ -0,0 +1,314 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headlessdriver
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/clip"
+	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/window"
+)
+
+// PrefsDir is the directory used in place of the OS preferences directory
+// -- it defaults to a temporary directory so that running tests does not
+// read or modify the user's actual preferences.
+var PrefsDir = filepath.Join(os.TempDir(), "gogi-headless")
+
+type appImpl struct {
+	mu            sync.Mutex
+	windows       []*windowImpl
+	screens       []*oswin.Screen
+	ctxtwin       *windowImpl
+	name          string
+	about         string
+	quitting      bool // set to true when quitting and closing windows
+	quitReqFunc   func()
+	quitCleanFunc func()
+}
+
+var theApp *appImpl
+
+func newAppImpl() *appImpl {
+	app := &appImpl{
+		windows: make([]*windowImpl, 0),
+		name:    "GoGi",
+	}
+
+	const mmPerInch = 25.4
+	sc := &oswin.Screen{
+		ScreenNumber:     0,
+		Geometry:         image.Rectangle{Max: ScreenSize},
+		Depth:            32,
+		LogicalDPI:       ScreenDPI,
+		PhysicalDPI:      ScreenDPI,
+		DevicePixelRatio: 1,
+		RefreshRate:      60,
+		PhysicalSize: image.Point{
+			int(float32(ScreenSize.X) * mmPerInch / ScreenDPI),
+			int(float32(ScreenSize.Y) * mmPerInch / ScreenDPI),
+		},
+		Name: "headless:0",
+	}
+	app.screens = []*oswin.Screen{sc}
+
+	oswin.TheApp = app
+	theApp = app
+	return app
+}
+
+func (app *appImpl) NewImage(size image.Point) (oswin.Image, error) {
+	if size.X < 0 || size.Y < 0 {
+		return nil, fmt.Errorf("headlessdriver: invalid image size %v", size)
+	}
+	return &imageImpl{
+		rgba: image.NewRGBA(image.Rectangle{Max: size}),
+		size: size,
+	}, nil
+}
+
+func (app *appImpl) NewTexture(win oswin.Window, size image.Point) (oswin.Texture, error) {
+	if size.X < 0 || size.Y < 0 {
+		return nil, fmt.Errorf("headlessdriver: invalid texture size %v", size)
+	}
+	ww := win.(*windowImpl)
+	nt := &textureImpl{
+		w:    ww,
+		rgba: image.NewRGBA(image.Rectangle{Max: size}),
+		size: size,
+	}
+	ww.AddTexture(nt)
+	return nt, nil
+}
+
+func (app *appImpl) NewWindow(opts *oswin.NewWindowOptions) (oswin.Window, error) {
+	if opts == nil {
+		opts = &oswin.NewWindowOptions{}
+	}
+	opts.Fixup()
+
+	sc := app.Screen(0)
+	w := &windowImpl{
+		app:   app,
+		front: image.NewRGBA(image.Rectangle{Max: opts.Size}),
+		WindowBase: oswin.WindowBase{
+			Titl:    opts.GetTitle(),
+			Sz:      opts.Size,
+			Pos:     opts.Pos,
+			PhysDPI: sc.PhysicalDPI,
+			LogDPI:  sc.LogicalDPI,
+			Scrn:    sc,
+			Flag:    opts.Flags,
+		},
+	}
+
+	app.mu.Lock()
+	app.windows = append(app.windows, w)
+	app.mu.Unlock()
+
+	// a new window immediately gets the focus and is painted, as in the
+	// sequence of events sent by a real window manager
+	w.setFocus(true)
+	sendWindowEvent(w, window.Paint)
+	return w, nil
+}
+
+func (app *appImpl) deleteWin(win *windowImpl) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for i, w := range app.windows {
+		if w == win {
+			app.windows = append(app.windows[:i], app.windows[i+1:]...)
+			break
+		}
+	}
+	if app.ctxtwin == win {
+		app.ctxtwin = nil
+	}
+}
+
+func (app *appImpl) NScreens() int {
+	return len(app.screens)
+}
+
+func (app *appImpl) Screen(scrN int) *oswin.Screen {
+	sz := len(app.screens)
+	if scrN < sz {
+		return app.screens[scrN]
+	}
+	return nil
+}
+
+func (app *appImpl) NWindows() int {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return len(app.windows)
+}
+
+func (app *appImpl) Window(win int) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	sz := len(app.windows)
+	if win < sz {
+		return app.windows[win]
+	}
+	return nil
+}
+
+func (app *appImpl) WindowByName(name string) oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.windows {
+		if win.Name() == name {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) WindowInFocus() oswin.Window {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	for _, win := range app.windows {
+		if win.IsFocus() {
+			return win
+		}
+	}
+	return nil
+}
+
+func (app *appImpl) ContextWindow() oswin.Window {
+	app.mu.Lock()
+	cw := app.ctxtwin
+	app.mu.Unlock()
+	if cw == nil {
+		return nil
+	}
+	return cw
+}
+
+// Platform returns the platform of the machine we are actually running on,
+// so platform-conditional behavior (e.g., key maps) is the same as with the
+// regular driver.
+func (app *appImpl) Platform() oswin.Platforms {
+	switch runtime.GOOS {
+	case "darwin":
+		return oswin.MacOS
+	case "windows":
+		return oswin.Windows
+	}
+	return oswin.LinuxX11
+}
+
+func (app *appImpl) Name() string {
+	return app.name
+}
+
+func (app *appImpl) SetName(name string) {
+	app.name = name
+}
+
+func (app *appImpl) PrefsDir() string {
+	os.MkdirAll(PrefsDir, 0755)
+	return PrefsDir
+}
+
+func (app *appImpl) GoGiPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), "GoGi")
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+func (app *appImpl) AppPrefsDir() string {
+	pdir := filepath.Join(app.PrefsDir(), app.Name())
+	os.MkdirAll(pdir, 0755)
+	return pdir
+}
+
+func (app *appImpl) FontPaths() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"/Library/Fonts"}
+	case "windows":
+		return []string{"C:\\Windows\\Fonts"}
+	}
+	return []string{"/usr/share/fonts/truetype"}
+}
+
+func (app *appImpl) ClipBoard(win oswin.Window) clip.Board {
+	app.mu.Lock()
+	app.ctxtwin, _ = win.(*windowImpl)
+	app.mu.Unlock()
+	return &theClip
+}
+
+func (app *appImpl) Cursor(win oswin.Window) cursor.Cursor {
+	app.mu.Lock()
+	app.ctxtwin, _ = win.(*windowImpl)
+	app.mu.Unlock()
+	return &theCursor
+}
+
+func (app *appImpl) About() string {
+	return app.about
+}
+
+func (app *appImpl) SetAbout(about string) {
+	app.about = about
+}
+
+// OpenURL is a no-op for the headless driver.
+func (app *appImpl) OpenURL(url string) {
+}
+
+func (app *appImpl) SetQuitReqFunc(fun func()) {
+	app.quitReqFunc = fun
+}
+
+func (app *appImpl) SetQuitCleanFunc(fun func()) {
+	app.quitCleanFunc = fun
+}
+
+func (app *appImpl) QuitReq() {
+	if app.quitting {
+		return
+	}
+	if app.quitReqFunc != nil {
+		app.quitReqFunc()
+	} else {
+		app.Quit()
+	}
+}
+
+func (app *appImpl) IsQuitting() bool {
+	return app.quitting
+}
+
+func (app *appImpl) QuitClean() {
+	app.quitting = true
+	if app.quitCleanFunc != nil {
+		app.quitCleanFunc()
+	}
+	app.mu.Lock()
+	wins := make([]*windowImpl, len(app.windows))
+	copy(wins, app.windows)
+	app.mu.Unlock()
+	for i := len(wins) - 1; i >= 0; i-- {
+		wins[i].Close()
+	}
+}
+
+func (app *appImpl) Quit() {
+	app.QuitClean()
+}
+
+// check for interface implementation
+var _ oswin.App = &appImpl{}
@@ -0,0 +1,64 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headlessdriver
+
+import (
+	"sync"
+
+	"github.com/goki/gi/oswin/mimedata"
+)
+
+// clipImpl is an in-memory clipboard that is private to the process -- it
+// just holds onto whatever was last written.
+type clipImpl struct {
+	mu   sync.Mutex
+	data mimedata.Mimes
+}
+
+var theClip = clipImpl{}
+
+func (ci *clipImpl) IsEmpty() bool {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	return len(ci.data) == 0
+}
+
+func (ci *clipImpl) Read(types []string) mimedata.Mimes {
+	if types == nil {
+		return nil
+	}
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	if len(ci.data) == 0 {
+		return nil
+	}
+	wantText := mimedata.IsText(types[0])
+	for _, typ := range types {
+		if ci.data.HasType(typ) {
+			return ci.data
+		}
+	}
+	if wantText { // any text is better than nothing, as with the other drivers
+		for _, d := range ci.data {
+			if mimedata.IsText(d.Type) {
+				return mimedata.NewMime(types[0], d.Data)
+			}
+		}
+	}
+	return nil
+}
+
+func (ci *clipImpl) Write(data mimedata.Mimes) error {
+	ci.mu.Lock()
+	ci.data = data
+	ci.mu.Unlock()
+	return nil
+}
+
+func (ci *clipImpl) Clear() {
+	ci.mu.Lock()
+	ci.data = nil
+	ci.mu.Unlock()
+}
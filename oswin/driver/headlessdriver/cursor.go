@@ -0,0 +1,70 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headlessdriver
+
+import (
+	"sync"
+
+	"github.com/goki/gi/oswin/cursor"
+)
+
+// cursorImpl maintains the standard cursor stack and visibility state, but
+// there is nothing to actually display.
+type cursorImpl struct {
+	cursor.CursorBase
+	mu sync.Mutex
+}
+
+var theCursor = cursorImpl{CursorBase: cursor.CursorBase{Vis: true}}
+
+func (c *cursorImpl) Set(sh cursor.Shapes) {
+	c.mu.Lock()
+	c.Cur = sh
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Push(sh cursor.Shapes) {
+	c.mu.Lock()
+	c.PushStack(sh)
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Pop() {
+	c.mu.Lock()
+	c.PopStack()
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Hide() {
+	c.mu.Lock()
+	c.Vis = false
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) Show() {
+	c.mu.Lock()
+	c.Vis = true
+	c.mu.Unlock()
+}
+
+func (c *cursorImpl) PushIfNot(sh cursor.Shapes) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Cur == sh {
+		return false
+	}
+	c.PushStack(sh)
+	return true
+}
+
+func (c *cursorImpl) PopIf(sh cursor.Shapes) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Cur == sh {
+		c.PopStack()
+		return true
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package headlessdriver provides a pure-Go, offscreen driver for oswin,
+// which renders into in-memory RGBA buffers instead of an OS window.  It
+// requires no display server, so the full gi Window.EventLoop rendering
+// pipeline can be run from within go test, e.g., in CI containers.
+//
+// Windows receive the same initial Focus and Paint events that a real
+// driver would send, and all subsequent events must be injected by the
+// program (e.g., via the Send method of oswin.EventDeque).  The last
+// published contents of a window can be retrieved with Capture.
+package headlessdriver
+
+import (
+	"image"
+
+	"github.com/goki/gi/oswin"
+)
+
+// ScreenSize is the size in raw dots of the synthetic screen -- set prior
+// to calling Main to configure.
+var ScreenSize = image.Point{1920, 1080}
+
+// ScreenDPI is the physical and logical DPI of the synthetic screen -- set
+// prior to calling Main to configure.  A fixed value ensures that rendering
+// is reproducible across machines.
+var ScreenDPI = float32(96)
+
+// Main is called by the program's main function to run the graphical
+// application.
+//
+// It calls f on the App in the same goroutine, as there is no OS-specific
+// main thread requirement.  It returns when f returns.
+func Main(f func(oswin.App)) {
+	app := newAppImpl()
+	f(app)
+}
+
+// Capture returns a copy of the image most recently published to the given
+// window, which must have been created by this driver -- returns nil
+// otherwise.
+func Capture(win oswin.Window) *image.RGBA {
+	w, ok := win.(*windowImpl)
+	if !ok {
+		return nil
+	}
+	return w.Capture()
+}
@@ -0,0 +1,202 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headlessdriver
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/oswin/window"
+	"golang.org/x/image/math/f64"
+)
+
+// winActions returns the actions of the window events queued on w so far,
+// reading up to a ShowEvent marker so that it does not block
+func winActions(w *windowImpl) []window.Actions {
+	w.Send(&window.ShowEvent{})
+	var acts []window.Actions
+	for {
+		switch ev := w.NextEvent().(type) {
+		case *window.ShowEvent:
+			return acts
+		case *window.Event:
+			acts = append(acts, ev.Action)
+		}
+	}
+}
+
+func sameActions(a, b []window.Actions) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func newTestWin(t *testing.T, app *appImpl, sz image.Point) *windowImpl {
+	win, err := app.NewWindow(&oswin.NewWindowOptions{Title: "test", Size: sz})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return win.(*windowImpl)
+}
+
+func TestWindowEvents(t *testing.T) {
+	app := newAppImpl()
+	if oswin.TheApp != app || app.NScreens() != 1 || app.Screen(0).LogicalDPI != ScreenDPI {
+		t.Errorf("app screens: %v", app.screens)
+	}
+	w := newTestWin(t, app, image.Point{100, 50})
+	if acts := winActions(w); !sameActions(acts, []window.Actions{window.Focus, window.Paint}) {
+		t.Errorf("new window events: %v", acts)
+	}
+	if app.NWindows() != 1 || app.WindowInFocus() != w || app.WindowByName(w.Name()) != w {
+		t.Errorf("app windows: %v", app.windows)
+	}
+
+	w.SetSize(image.Point{200, 100})
+	w.SetSize(image.Point{200, 100})
+	w.SetPos(image.Point{10, 10})
+	if acts := winActions(w); !sameActions(acts, []window.Actions{window.Resize, window.Move}) {
+		t.Errorf("geom events: %v", acts)
+	}
+	if w.Size() != (image.Point{200, 100}) || w.Position() != (image.Point{10, 10}) {
+		t.Errorf("geom: %v %v", w.Size(), w.Position())
+	}
+
+	w2 := newTestWin(t, app, image.Point{100, 50})
+	winActions(w2)
+	w.Minimize()
+	if acts := winActions(w); !sameActions(acts, []window.Actions{window.DeFocus}) || !w.IsMinimized() {
+		t.Errorf("minimize events: %v", acts)
+	}
+	w.Raise()
+	if acts := winActions(w); !sameActions(acts, []window.Actions{window.Paint, window.Focus}) || w.IsMinimized() {
+		t.Errorf("raise events: %v", acts)
+	}
+	if acts := winActions(w2); !sameActions(acts, []window.Actions{window.DeFocus}) || app.WindowInFocus() != w {
+		t.Errorf("raise other window events: %v", acts)
+	}
+
+	closed := 0
+	w.SetCloseCleanFunc(func(win oswin.Window) { closed++ })
+	w.Close()
+	w.Close()
+	if acts := winActions(w); !sameActions(acts, []window.Actions{window.Close}) || closed != 1 {
+		t.Errorf("close events: %v %v", acts, closed)
+	}
+	if app.NWindows() != 1 || app.Window(0) != w2 {
+		t.Errorf("app windows after close: %v", app.windows)
+	}
+	app.Quit()
+	if app.NWindows() != 0 || !app.IsQuitting() {
+		t.Errorf("app windows after quit: %v", app.windows)
+	}
+}
+
+func TestWindowDraw(t *testing.T) {
+	app := newAppImpl()
+	w := newTestWin(t, app, image.Point{20, 10})
+	if img := w.Capture(); img.At(0, 0) != (color.RGBA{}) {
+		t.Errorf("initial capture: %v", img.At(0, 0))
+	}
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	w.Fill(image.Rect(0, 0, 10, 10), red, draw.Src)
+	if img := w.Capture(); img.At(0, 0) != (color.RGBA{}) {
+		t.Errorf("capture before publish: %v", img.At(0, 0))
+	}
+	w.Publish()
+	img := w.Capture()
+	if img.Bounds() != image.Rect(0, 0, 20, 10) || img.At(9, 9) != red || img.At(10, 0) != (color.RGBA{}) {
+		t.Errorf("fill: %v %v", img.At(9, 9), img.At(10, 0))
+	}
+
+	oimg, err := app.NewImage(image.Point{4, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	draw.Draw(oimg.RGBA(), oimg.Bounds(), image.NewUniform(blue), image.ZP, draw.Src)
+	w.Upload(image.Point{12, 2}, oimg, image.Rect(-2, 0, 4, 4)) // clipped to the image
+	w.Publish()
+	if img := w.Capture(); img.At(14, 2) != blue || img.At(13, 2) == blue || img.At(18, 2) == blue {
+		t.Errorf("upload: %v %v %v", img.At(14, 2), img.At(13, 2), img.At(18, 2))
+	}
+
+	// resizing keeps the contents of the back buffer
+	w.SetSize(image.Point{30, 10})
+	w.Publish()
+	if img := w.Capture(); img.Bounds().Dx() != 30 || img.At(0, 0) != red {
+		t.Errorf("resized capture: %v %v", img.Bounds(), img.At(0, 0))
+	}
+	if _, err := app.NewImage(image.Point{-1, 1}); err == nil {
+		t.Errorf("negative image size should fail")
+	}
+}
+
+func TestTexture(t *testing.T) {
+	app := newAppImpl()
+	w := newTestWin(t, app, image.Point{20, 20})
+	tx, err := app.NewTexture(w, image.Point{4, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	green := color.RGBA{0, 255, 0, 255}
+	tx.Fill(tx.Bounds(), green, draw.Src)
+
+	// integer translation copies exactly
+	w.Draw(f64.Aff3{1, 0, 5, 0, 1, 6}, tx, tx.Bounds(), draw.Src, nil)
+	w.Publish()
+	img := w.Capture()
+	if img.At(5, 6) != green || img.At(8, 9) != green || img.At(9, 9) == green || img.At(4, 6) == green {
+		t.Errorf("draw translate: %v %v %v", img.At(5, 6), img.At(9, 9), img.At(4, 6))
+	}
+
+	// scaling fills the destination rectangle
+	w.Fill(image.Rect(0, 0, 20, 20), color.Transparent, draw.Src)
+	w.Scale(image.Rect(10, 10, 18, 18), tx, tx.Bounds(), draw.Src, nil)
+	w.Publish()
+	img = w.Capture()
+	if img.At(11, 11) != green || img.At(16, 16) != green || img.At(19, 19) == green {
+		t.Errorf("draw scale: %v %v %v", img.At(11, 11), img.At(16, 16), img.At(19, 19))
+	}
+
+	if len(w.textures) != 1 {
+		t.Errorf("window textures: %v", len(w.textures))
+	}
+	w.Close()
+	if len(w.textures) != 0 || !tx.(*textureImpl).released {
+		t.Errorf("textures not released on close: %v", len(w.textures))
+	}
+	if _, err := app.NewTexture(w, image.Point{1, -1}); err == nil {
+		t.Errorf("negative texture size should fail")
+	}
+}
+
+func TestClip(t *testing.T) {
+	app := newAppImpl()
+	w := newTestWin(t, app, image.Point{20, 20})
+	cb := app.ClipBoard(w)
+	cb.Clear()
+	if !cb.IsEmpty() || app.ContextWindow() != w {
+		t.Errorf("clip not empty")
+	}
+	cb.Write(mimedata.NewText("hello"))
+	if md := cb.Read([]string{mimedata.TextPlain}); len(md) != 1 || string(md[0].Data) != "hello" {
+		t.Errorf("clip read: %v", md)
+	}
+	if md := cb.Read([]string{"image/png"}); md != nil {
+		t.Errorf("clip read other type: %v", md)
+	}
+	cb.Clear()
+}
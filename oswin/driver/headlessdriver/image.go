@@ -0,0 +1,44 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headlessdriver
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+)
+
+// imageImpl is a plain in-memory RGBA image -- no special allocation is
+// required as there is no display server to share it with.
+type imageImpl struct {
+	rgba *image.RGBA
+	size image.Point
+
+	mu       sync.Mutex
+	released bool
+}
+
+func (b *imageImpl) Size() image.Point       { return b.size }
+func (b *imageImpl) Bounds() image.Rectangle { return image.Rectangle{Max: b.size} }
+func (b *imageImpl) RGBA() *image.RGBA       { return b.rgba }
+
+func (b *imageImpl) Release() {
+	b.mu.Lock()
+	b.released = true
+	b.mu.Unlock()
+}
+
+// upload copies the sr region of src into dst at dp, using the draw.Src
+// operator, as required by the oswin.Uploader interface.
+func upload(dst *image.RGBA, dp image.Point, src *image.RGBA, sr image.Rectangle) {
+	originalSRMin := sr.Min
+	sr = sr.Intersect(src.Bounds())
+	if sr.Empty() {
+		return
+	}
+	dp = dp.Add(sr.Min.Sub(originalSRMin))
+	dr := image.Rectangle{Min: dp, Max: dp.Add(sr.Size())}
+	draw.Draw(dst, dr, src, sr.Min, draw.Src)
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headlessdriver
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+type textureImpl struct {
+	w    *windowImpl
+	rgba *image.RGBA
+	size image.Point
+
+	mu       sync.Mutex
+	released bool
+}
+
+func (t *textureImpl) Size() image.Point       { return t.size }
+func (t *textureImpl) Bounds() image.Rectangle { return image.Rectangle{Max: t.size} }
+
+func (t *textureImpl) Release() {
+	t.mu.Lock()
+	released := t.released
+	t.released = true
+	t.mu.Unlock()
+	if released {
+		return
+	}
+	t.w.DeleteTexture(t)
+}
+
+func (t *textureImpl) Upload(dp image.Point, src oswin.Image, sr image.Rectangle) {
+	t.mu.Lock()
+	upload(t.rgba, dp, src.RGBA(), sr)
+	t.mu.Unlock()
+}
+
+func (t *textureImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
+	t.mu.Lock()
+	draw.Draw(t.rgba, dr, image.NewUniform(src), image.ZP, op)
+	t.mu.Unlock()
+}
+
+// drawAff3 draws the sr region of src onto dst using the src2dst
+// transform -- pure integer translations are done with a direct copy, so
+// that the typical Window.Copy path is exact and fast, and everything else
+// uses bilinear interpolation.
+func drawAff3(dst *image.RGBA, src2dst *f64.Aff3, src image.Image, sr image.Rectangle, op draw.Op) {
+	if sr.Empty() {
+		return
+	}
+	if src2dst[0] == 1 && src2dst[1] == 0 && src2dst[3] == 0 && src2dst[4] == 1 &&
+		src2dst[2] == math.Trunc(src2dst[2]) && src2dst[5] == math.Trunc(src2dst[5]) {
+		dp := image.Point{int(src2dst[2]), int(src2dst[5])}
+		dr := sr.Add(dp)
+		draw.Draw(dst, dr, src, sr.Min, op)
+		return
+	}
+	xdraw.ApproxBiLinear.Transform(dst, *src2dst, src, sr, op, nil)
+}
@@ -0,0 +1,319 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package headlessdriver
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/driver/internal/drawer"
+	"github.com/goki/gi/oswin/driver/internal/event"
+	"github.com/goki/gi/oswin/window"
+	"github.com/goki/ki/bitflag"
+	"golang.org/x/image/math/f64"
+)
+
+type windowImpl struct {
+	oswin.WindowBase
+
+	app *appImpl
+
+	event.Deque
+
+	// back is the buffer that all Upload, Fill and Draw calls render into --
+	// it is copied to front on Publish
+	back *image.RGBA
+
+	// front holds the most recently published image for the window
+	front *image.RGBA
+
+	// textures are the textures created for this window -- they are released
+	// when the window is closed
+	textures map[*textureImpl]struct{}
+
+	mu             sync.Mutex
+	released       bool
+	closeReqFunc   func(win oswin.Window)
+	closeCleanFunc func(win oswin.Window)
+}
+
+// for sending window.Event's
+func sendWindowEvent(w *windowImpl, act window.Actions) {
+	winEv := window.Event{
+		Action: act,
+	}
+	winEv.Init()
+	w.Send(&winEv)
+}
+
+// backBuf returns the back buffer, (re)allocating it if the window has been
+// resized -- must be called with w.mu locked.
+func (w *windowImpl) backBuf() *image.RGBA {
+	if w.back == nil || w.back.Rect.Size() != w.Sz {
+		nb := image.NewRGBA(image.Rectangle{Max: w.Sz})
+		if w.back != nil {
+			draw.Draw(nb, nb.Bounds(), w.back, image.ZP, draw.Src)
+		}
+		w.back = nb
+	}
+	return w.back
+}
+
+func (w *windowImpl) Upload(dp image.Point, src oswin.Image, sr image.Rectangle) {
+	w.mu.Lock()
+	upload(w.backBuf(), dp, src.RGBA(), sr)
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) Fill(dr image.Rectangle, src color.Color, op draw.Op) {
+	w.mu.Lock()
+	draw.Draw(w.backBuf(), dr, image.NewUniform(src), image.ZP, op)
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) DrawUniform(src2dst f64.Aff3, src color.Color, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	w.mu.Lock()
+	drawAff3(w.backBuf(), &src2dst, image.NewUniform(src), sr, op)
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) Draw(src2dst f64.Aff3, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	t := src.(*textureImpl)
+	t.mu.Lock()
+	w.mu.Lock()
+	drawAff3(w.backBuf(), &src2dst, t.rgba, sr.Intersect(t.Bounds()), op)
+	w.mu.Unlock()
+	t.mu.Unlock()
+}
+
+func (w *windowImpl) Copy(dp image.Point, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	drawer.Copy(w, dp, src, sr, op, opts)
+}
+
+func (w *windowImpl) Scale(dr image.Rectangle, src oswin.Texture, sr image.Rectangle, op draw.Op, opts *oswin.DrawOptions) {
+	drawer.Scale(w, dr, src, sr, op, opts)
+}
+
+func (w *windowImpl) Publish() oswin.PublishResult {
+	w.mu.Lock()
+	bb := w.backBuf()
+	if w.front == nil || w.front.Rect != bb.Rect {
+		w.front = image.NewRGBA(bb.Rect)
+	}
+	copy(w.front.Pix, bb.Pix)
+	w.mu.Unlock()
+	return oswin.PublishResult{BackImagePreserved: true}
+}
+
+// Capture returns a copy of the most recently published window image.
+func (w *windowImpl) Capture() *image.RGBA {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.front == nil {
+		return nil
+	}
+	img := image.NewRGBA(w.front.Rect)
+	copy(img.Pix, w.front.Pix)
+	return img
+}
+
+func (w *windowImpl) Screen() *oswin.Screen {
+	w.mu.Lock()
+	sc := w.Scrn
+	w.mu.Unlock()
+	return sc
+}
+
+func (w *windowImpl) Size() image.Point {
+	w.mu.Lock()
+	sz := w.Sz
+	w.mu.Unlock()
+	return sz
+}
+
+func (w *windowImpl) Position() image.Point {
+	w.mu.Lock()
+	ps := w.Pos
+	w.mu.Unlock()
+	return ps
+}
+
+func (w *windowImpl) PhysicalDPI() float32 {
+	w.mu.Lock()
+	dpi := w.PhysDPI
+	w.mu.Unlock()
+	return dpi
+}
+
+func (w *windowImpl) LogicalDPI() float32 {
+	w.mu.Lock()
+	dpi := w.LogDPI
+	w.mu.Unlock()
+	return dpi
+}
+
+func (w *windowImpl) SetLogicalDPI(dpi float32) {
+	w.mu.Lock()
+	w.LogDPI = dpi
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) SetTitle(title string) {
+	w.Titl = title
+}
+
+func (w *windowImpl) SetSize(sz image.Point) {
+	w.mu.Lock()
+	if w.Sz == sz {
+		w.mu.Unlock()
+		return
+	}
+	w.Sz = sz
+	w.mu.Unlock()
+	sendWindowEvent(w, window.Resize)
+}
+
+func (w *windowImpl) SetPos(pos image.Point) {
+	w.mu.Lock()
+	if w.Pos == pos {
+		w.mu.Unlock()
+		return
+	}
+	w.Pos = pos
+	w.mu.Unlock()
+	sendWindowEvent(w, window.Move)
+}
+
+func (w *windowImpl) SetGeom(pos image.Point, sz image.Point) {
+	w.SetPos(pos)
+	w.SetSize(sz)
+}
+
+func (w *windowImpl) MainMenu() oswin.MainMenu {
+	return nil
+}
+
+func (w *windowImpl) Raise() {
+	w.mu.Lock()
+	wasMin := bitflag.Has(w.Flag, int(oswin.Minimized))
+	bitflag.Clear(&w.Flag, int(oswin.Minimized))
+	w.mu.Unlock()
+	if wasMin {
+		sendWindowEvent(w, window.Paint)
+	}
+	for _, ow := range w.otherWindows() {
+		ow.setFocus(false)
+	}
+	w.setFocus(true)
+}
+
+func (w *windowImpl) Minimize() {
+	w.mu.Lock()
+	bitflag.Set(&w.Flag, int(oswin.Minimized))
+	w.mu.Unlock()
+	w.setFocus(false)
+}
+
+// setFocus updates the focus flag and sends the corresponding window event
+// if the focus state changed.
+func (w *windowImpl) setFocus(focus bool) {
+	w.mu.Lock()
+	has := bitflag.Has(w.Flag, int(oswin.Focus))
+	if has == focus {
+		w.mu.Unlock()
+		return
+	}
+	if focus {
+		bitflag.Set(&w.Flag, int(oswin.Focus))
+	} else {
+		bitflag.Clear(&w.Flag, int(oswin.Focus))
+	}
+	w.mu.Unlock()
+	if focus {
+		sendWindowEvent(w, window.Focus)
+	} else {
+		sendWindowEvent(w, window.DeFocus)
+	}
+}
+
+// otherWindows returns all the app windows other than this one
+func (w *windowImpl) otherWindows() []*windowImpl {
+	w.app.mu.Lock()
+	defer w.app.mu.Unlock()
+	ow := make([]*windowImpl, 0, len(w.app.windows))
+	for _, aw := range w.app.windows {
+		if aw != w {
+			ow = append(ow, aw)
+		}
+	}
+	return ow
+}
+
+func (w *windowImpl) AddTexture(t *textureImpl) {
+	w.mu.Lock()
+	if w.textures == nil {
+		w.textures = make(map[*textureImpl]struct{})
+	}
+	w.textures[t] = struct{}{}
+	w.mu.Unlock()
+}
+
+// DeleteTexture just deletes it from our list -- does not Release -- is called during t.Release
+func (w *windowImpl) DeleteTexture(t *textureImpl) {
+	w.mu.Lock()
+	if w.textures != nil {
+		delete(w.textures, t)
+	}
+	w.mu.Unlock()
+}
+
+func (w *windowImpl) SetCloseReqFunc(fun func(win oswin.Window)) {
+	w.closeReqFunc = fun
+}
+
+func (w *windowImpl) SetCloseCleanFunc(fun func(win oswin.Window)) {
+	w.closeCleanFunc = fun
+}
+
+func (w *windowImpl) CloseReq() {
+	if theApp.quitting {
+		w.Close()
+		return
+	}
+	if w.closeReqFunc != nil {
+		w.closeReqFunc(w)
+	} else {
+		w.Close()
+	}
+}
+
+func (w *windowImpl) CloseClean() {
+	if w.closeCleanFunc != nil {
+		w.closeCleanFunc(w)
+	}
+}
+
+func (w *windowImpl) Close() {
+	w.mu.Lock()
+	released := w.released
+	w.released = true
+	texs := make([]*textureImpl, 0, len(w.textures))
+	for t := range w.textures {
+		texs = append(texs, t)
+	}
+	w.mu.Unlock()
+	if released {
+		return
+	}
+	w.CloseClean()
+	sendWindowEvent(w, window.Close)
+	for _, t := range texs {
+		t.Release() // deletes from map
+	}
+	w.app.deleteWin(w)
+}
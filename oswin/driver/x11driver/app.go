@@ -91,6 +91,7 @@ type appImpl struct {
 	nPendingUploads int
 	completionKeys  []uint16
 	selNotifyChan   chan xproto.SelectionNotifyEvent
+	xim             *ximImpl // nil if there is no XIM server
 	name            string
 	about           string
 	quitting        bool // set to true when quitting and closing windows
@@ -133,6 +134,7 @@ func newAppImpl(xc *xgb.Conn) (*appImpl, error) {
 	if err := app.initWindow32(); err != nil {
 		return nil, err
 	}
+	app.xim = newXIM(app)
 
 	var err error
 	app.opaqueP, err = render.NewPictureId(xc)
@@ -231,6 +233,8 @@ mainloop:
 		case xproto.DestroyNotifyEvent:
 			if w := app.findWindow(ev.Window); w != nil {
 				w.closed()
+			} else if app.xim != nil && app.xim.isServer(ev.Window) {
+				app.xim.serverGone()
 			}
 		case shm.CompletionEvent:
 			app.mu.Lock()
@@ -239,6 +243,10 @@ mainloop:
 			app.mu.Unlock()
 
 		case xproto.ClientMessageEvent:
+			if app.xim != nil && ev.Window == app.xim.win {
+				app.xim.handleClientMessage(ev)
+				break
+			}
 			if ev.Type != app.atomWMProtocols || ev.Format != 32 {
 				break
 			}
@@ -283,6 +291,9 @@ mainloop:
 				w.mu.Unlock()
 				// fmt.Printf("focused %v\n", w.Name())
 				sendWindowEvent(w, window.Focus)
+				if app.xim != nil {
+					app.xim.setFocus(w, true)
+				}
 			} else {
 				noWindowFound = true
 			}
@@ -294,20 +305,27 @@ mainloop:
 				w.mu.Unlock()
 				// fmt.Printf("defocused %v\n", w.Name())
 				sendWindowEvent(w, window.DeFocus)
+				if app.xim != nil {
+					app.xim.setFocus(w, false)
+				}
 			} else {
 				noWindowFound = true
 			}
 
 		case xproto.KeyPressEvent:
 			if w := app.findWindow(ev.Event); w != nil {
-				w.handleKey(ev.Detail, ev.State, key.Press)
+				if app.xim == nil || !app.xim.forwardKey(w, ev.Bytes(), ev.Sequence, false) {
+					w.handleKey(ev.Detail, ev.State, key.Press)
+				}
 			} else {
 				noWindowFound = true
 			}
 
 		case xproto.KeyReleaseEvent:
 			if w := app.findWindow(ev.Event); w != nil {
-				w.handleKey(ev.Detail, ev.State, key.Release)
+				if app.xim == nil || !app.xim.forwardKey(w, ev.Bytes(), ev.Sequence, true) {
+					w.handleKey(ev.Detail, ev.State, key.Release)
+				}
 			} else {
 				noWindowFound = true
 			}
@@ -334,7 +352,11 @@ mainloop:
 			}
 
 		case xproto.SelectionNotifyEvent:
-			app.selNotifyChan <- ev
+			if app.xim != nil && ev.Requestor == app.xim.win {
+				app.xim.handleSelectionNotify(ev)
+			} else {
+				app.selNotifyChan <- ev
+			}
 
 		case xproto.SelectionRequestEvent:
 			theClip.SendLastWrite(ev)
@@ -578,6 +600,10 @@ func (app *appImpl) NewWindow(opts *oswin.NewWindowOptions) (oswin.Window, error
 		w.SetGeom(opts.Pos, opts.Size)
 	}
 
+	if app.xim != nil {
+		app.xim.createIC(w)
+	}
+
 	return w, nil
 }
 
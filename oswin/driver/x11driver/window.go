@@ -446,6 +446,12 @@ func (w *windowImpl) Minimize() {
 	xproto.SendEvent(w.app.xc, true, w.xw, uint32(mask), string(minmsg.Bytes()))
 }
 
+func (w *windowImpl) SetIMEPos(pos image.Point) {
+	if w.app.xim != nil {
+		w.app.xim.setSpot(w, pos)
+	}
+}
+
 func (w *windowImpl) AddTexture(t *textureImpl) {
 	if w.textures == nil {
 		w.textures = make(map[*textureImpl]struct{})
@@ -490,6 +496,9 @@ func (w *windowImpl) CloseClean() {
 func (w *windowImpl) closeRelease() {
 	w.CloseClean()
 	sendWindowEvent(w, window.Close)
+	if w.app.xim != nil {
+		w.app.xim.destroyIC(w)
+	}
 	render.FreePicture(w.app.xc, w.xp)
 	xproto.FreeGC(w.app.xc, w.xg)
 	if w.textures != nil {
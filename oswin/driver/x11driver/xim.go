@@ -0,0 +1,940 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android dragonfly openbsd
+
+package x11driver
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/goki/gi/oswin/ime"
+	"github.com/goki/gi/oswin/key"
+)
+
+// implements composed text input (IME) support for X11, as a client of an X
+// Input Method (XIM) server such as ibus or fcitx, speaking the XIM protocol
+// directly over the X transport (ClientMessage events and window properties)
+// -- https://www.x.org/releases/X11R7.6/doc/libX11/specs/XIM/xim.html
+//
+// We ask for the PreeditCallbacks input style, so that the server reports the
+// composition (preedit) text to us, which we send as ime.Event's, and the
+// text widgets draw at their cursor.  Key presses for windows with an input
+// context are forwarded to the server, which sends back those that it does
+// not use, which are then processed as regular key events.
+
+// XIMDebug turns on debugging output for the XIM protocol
+var XIMDebug = false
+
+// XIM protocol message opcodes
+const (
+	ximConnect             = 1
+	ximConnectReply        = 2
+	ximError               = 20
+	ximOpen                = 30
+	ximOpenReply           = 31
+	ximSetEventMask        = 37
+	ximEncodingNegotiation = 38
+	ximEncodingNegReply    = 39
+	ximCreateIC            = 50
+	ximCreateICReply       = 51
+	ximDestroyIC           = 52
+	ximSetICValues         = 54
+	ximSetICFocus          = 58
+	ximUnsetICFocus        = 59
+	ximForwardEvent        = 60
+	ximSync                = 61
+	ximSyncReply           = 62
+	ximCommit              = 63
+	ximPreeditStart        = 73
+	ximPreeditStartReply   = 74
+	ximPreeditDraw         = 75
+	ximPreeditCaret        = 76
+	ximPreeditCaretReply   = 77
+	ximPreeditDone         = 78
+)
+
+const (
+	ximPreeditCallbacks = 0x0002
+	ximStatusNothing    = 0x0400
+
+	ximFlagSync     = 1
+	ximCommitChars  = 2
+	ximCommitKeySym = 4
+
+	ximDrawNoString = 1
+
+	ximCaretForward   = 0
+	ximCaretBackward  = 1
+	ximCaretLineStart = 8
+	ximCaretLineEnd   = 9
+	ximCaretAbsolute  = 10
+
+	// ximCMSize is the number of bytes of data in one ClientMessage
+	ximCMSize = 20
+
+	// ximNPropAtoms is the number of property atoms cycled through for
+	// sending long messages through window properties
+	ximNPropAtoms = 4
+)
+
+// ximIC is an XIM input context, one for each window
+type ximIC struct {
+	w       *windowImpl
+	id      uint16
+	preedit []rune
+	cursor  int
+	spot    image.Point
+	focus   bool
+}
+
+// ximImpl is the connection to the XIM server -- all access after creation
+// is under mu, as windows are created and the IME position is set from
+// other goroutines than the app.run event loop
+type ximImpl struct {
+	app *appImpl
+	mu  sync.Mutex
+
+	// win is our communication window, which receives all messages from the server
+	win xproto.Window
+
+	// owner is the owner of the server selection, used to make the connection
+	owner xproto.Window
+
+	// imsWin is the communication window of the server, once connected
+	imsWin xproto.Window
+
+	// transport version of the server, and the maximum size of a message to
+	// send as multiple ClientMessages
+	major, minor uint32
+	divSize      int
+
+	atomServer    xproto.Atom
+	atomTransport xproto.Atom
+	atomXConnect  xproto.Atom
+	atomProtocol  xproto.Atom
+	atomMoreData  xproto.Atom
+	propAtoms     [ximNPropAtoms]xproto.Atom
+	propIdx       int
+
+	// ready is set when the input method is open and input contexts can be created
+	ready bool
+	imID  uint16
+	utf8  bool
+
+	// icAttrs are the ids of the input context attributes, by name
+	icAttrs map[string]uint16
+
+	// fwdMask is the mask of key events to forward to the server
+	fwdMask uint32
+
+	// rdbuf accumulates the current incoming message
+	rdbuf []byte
+
+	// pending are windows waiting for a XIM_CREATE_IC_REPLY, in order
+	pending []*windowImpl
+	ics     map[*windowImpl]*ximIC
+	icByID  map[uint16]*ximIC
+}
+
+// newXIM looks for an XIM server and starts the connection to it -- returns
+// nil if there is no server, or XMODIFIERS is @im=none
+func newXIM(app *appImpl) *ximImpl {
+	imnm := ""
+	for _, md := range strings.Split(os.Getenv("XMODIFIERS"), "@") {
+		if strings.HasPrefix(md, "im=") {
+			imnm = strings.TrimSpace(md[3:])
+		}
+	}
+	if imnm == "none" {
+		return nil
+	}
+	xi := &ximImpl{
+		app:     app,
+		icAttrs: map[string]uint16{},
+		fwdMask: xproto.EventMaskKeyPress,
+		ics:     map[*windowImpl]*ximIC{},
+		icByID:  map[uint16]*ximIC{},
+	}
+	var err error
+	if imnm != "" {
+		xi.atomServer, err = app.internAtom("@server=" + imnm)
+		if err != nil {
+			return nil
+		}
+	} else {
+		xi.atomServer = xi.firstServer()
+	}
+	if xi.atomServer == xproto.AtomNone {
+		return nil
+	}
+	selown, err := xproto.GetSelectionOwner(app.xc, xi.atomServer).Reply()
+	if err != nil || selown.Owner == xproto.WindowNone {
+		return nil
+	}
+	xi.owner = selown.Owner
+	for i, nm := range []string{"TRANSPORT", "_XIM_XCONNECT", "_XIM_PROTOCOL", "_XIM_MOREDATA"} {
+		at, err := app.internAtom(nm)
+		if err != nil {
+			return nil
+		}
+		switch i {
+		case 0:
+			xi.atomTransport = at
+		case 1:
+			xi.atomXConnect = at
+		case 2:
+			xi.atomProtocol = at
+		case 3:
+			xi.atomMoreData = at
+		}
+	}
+	for i := range xi.propAtoms {
+		xi.propAtoms[i], err = app.internAtom(fmt.Sprintf("_GOGI_XIM_%v", i))
+		if err != nil {
+			return nil
+		}
+	}
+	xi.win, err = xproto.NewWindowId(app.xc)
+	if err != nil {
+		return nil
+	}
+	xproto.CreateWindow(app.xc, 0, xi.win, app.xsci.Root, 0, 0, 1, 1, 0,
+		xproto.WindowClassInputOnly, 0, 0, nil)
+	// first step: ask for the transports the server supports
+	xproto.ConvertSelection(app.xc, xi.win, xi.atomServer, xi.atomTransport, xi.atomTransport, xproto.TimeCurrentTime)
+	return xi
+}
+
+// firstServer returns the first server listed in the XIM_SERVERS property of
+// the root window, or AtomNone if there are none
+func (xi *ximImpl) firstServer() xproto.Atom {
+	app := xi.app
+	srvs, err := app.internAtom("XIM_SERVERS")
+	if err != nil {
+		return xproto.AtomNone
+	}
+	prop, err := xproto.GetProperty(app.xc, false, app.xsci.Root, srvs, xproto.AtomAtom, 0, 1024).Reply()
+	if err != nil || prop.Format != 32 || len(prop.Value) < 4 {
+		return xproto.AtomNone
+	}
+	return xproto.Atom(binary.LittleEndian.Uint32(prop.Value))
+}
+
+// isServer returns true if given window is the server communication window
+func (xi *ximImpl) isServer(xw xproto.Window) bool {
+	xi.mu.Lock()
+	defer xi.mu.Unlock()
+	return xi.imsWin != xproto.WindowNone && xw == xi.imsWin
+}
+
+// serverGone resets everything when the server goes away -- key events are
+// then processed directly
+func (xi *ximImpl) serverGone() {
+	xi.mu.Lock()
+	defer xi.mu.Unlock()
+	log.Printf("x11driver: XIM server went away\n")
+	xi.ready = false
+	xi.imsWin = xproto.WindowNone
+	xi.pending = nil
+	for w, ic := range xi.ics {
+		if len(ic.preedit) > 0 {
+			sendEvent(w, &ime.Event{Action: ime.Preedit})
+		}
+	}
+	xi.ics = map[*windowImpl]*ximIC{}
+	xi.icByID = map[uint16]*ximIC{}
+}
+
+// handleSelectionNotify receives the list of transports from the server,
+// and asks to connect with the X transport
+func (xi *ximImpl) handleSelectionNotify(ev xproto.SelectionNotifyEvent) {
+	app := xi.app
+	if ev.Property == xproto.AtomNone {
+		log.Printf("x11driver: XIM server did not report transports\n")
+		return
+	}
+	prop, err := xproto.GetProperty(app.xc, true, xi.win, ev.Property, xproto.AtomAny, 0, 1024).Reply()
+	if err != nil {
+		log.Printf("x11driver: XIM transport read error: %v\n", err)
+		return
+	}
+	if !strings.Contains(string(prop.Value), "X/") {
+		log.Printf("x11driver: XIM server does not support the X transport: %v\n", string(prop.Value))
+		return
+	}
+	// we ask for transport version 0.2 = only-CM, multi-CM, and property-with-CM
+	dat := xproto.ClientMessageDataUnionData32New([]uint32{uint32(xi.win), 0, 2, 0, 0})
+	cm := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: xi.owner,
+		Type:   xi.atomXConnect,
+		Data:   dat,
+	}
+	xproto.SendEvent(app.xc, false, xi.owner, xproto.EventMaskNoEvent, string(cm.Bytes()))
+}
+
+// handleClientMessage handles all messages from the server
+func (xi *ximImpl) handleClientMessage(ev xproto.ClientMessageEvent) {
+	xi.mu.Lock()
+	defer xi.mu.Unlock()
+	switch {
+	case ev.Type == xi.atomXConnect && ev.Format == 32:
+		d := ev.Data.Data32
+		xi.imsWin = xproto.Window(d[0])
+		xi.major = d[1]
+		xi.minor = d[2]
+		xi.divSize = int(d[3])
+		// so we learn when the server goes away
+		xproto.ChangeWindowAttributes(xi.app.xc, xi.imsWin, xproto.CwEventMask, []uint32{xproto.EventMaskStructureNotify})
+		var b ximWriter
+		b.put8('l') // little-endian
+		b.put8(0)
+		b.put16(1) // protocol version 1.0
+		b.put16(0)
+		b.put16(0) // no authentication
+		xi.send(ximConnect, b)
+	case ev.Type == xi.atomMoreData && ev.Format == 8:
+		xi.rdbuf = append(xi.rdbuf, ev.Data.Data8[:ximCMSize]...)
+	case ev.Type == xi.atomProtocol && ev.Format == 8:
+		xi.rdbuf = append(xi.rdbuf, ev.Data.Data8[:ximCMSize]...)
+		xi.recv()
+	case ev.Type == xi.atomProtocol && ev.Format == 32:
+		d := ev.Data.Data32
+		prop, err := xproto.GetProperty(xi.app.xc, true, xi.win, xproto.Atom(d[1]), xproto.AtomAny, 0, (d[0]+3)/4).Reply()
+		if err != nil {
+			log.Printf("x11driver: XIM property read error: %v\n", err)
+			xi.rdbuf = xi.rdbuf[:0]
+			return
+		}
+		xi.rdbuf = append(xi.rdbuf, prop.Value...)
+		xi.recv()
+	}
+}
+
+// recv processes the message in rdbuf, and resets it
+func (xi *ximImpl) recv() {
+	buf := xi.rdbuf
+	xi.rdbuf = xi.rdbuf[:0]
+	if len(buf) < 4 {
+		return
+	}
+	op := buf[0]
+	n := 4 + 4*int(binary.LittleEndian.Uint16(buf[2:]))
+	if n > len(buf) {
+		log.Printf("x11driver: XIM message %v truncated: %v < %v\n", op, len(buf), n)
+		return
+	}
+	if XIMDebug {
+		fmt.Printf("XIM recv: %v len: %v\n", op, n)
+	}
+	r := ximReader{buf: buf[4:n]}
+	switch op {
+	case ximConnectReply:
+		var b ximWriter
+		b.putStr(ximLocale())
+		b.pad(b.len())
+		xi.send(ximOpen, b)
+	case ximOpenReply:
+		xi.recvOpenReply(&r)
+	case ximEncodingNegReply:
+		r.get16() // imID
+		r.get16() // category
+		idx := int16(r.get16())
+		if idx < 0 {
+			log.Printf("x11driver: XIM server does not support UTF-8 or COMPOUND_TEXT encoding\n")
+			return
+		}
+		xi.utf8 = idx == 0
+		xi.ready = true
+		xi.app.mu.Lock()
+		wins := append([]*windowImpl{}, xi.app.winlist...)
+		xi.app.mu.Unlock()
+		for _, w := range wins {
+			xi.createICLocked(w)
+		}
+	case ximSetEventMask:
+		r.get16()
+		r.get16()
+		xi.fwdMask = r.get32()
+	case ximCreateICReply:
+		r.get16()
+		id := r.get16()
+		if len(xi.pending) == 0 {
+			return
+		}
+		w := xi.pending[0]
+		xi.pending = xi.pending[1:]
+		if w == nil { // window closed in the meantime
+			var b ximWriter
+			b.put16(xi.imID)
+			b.put16(id)
+			xi.send(ximDestroyIC, b)
+			return
+		}
+		ic := &ximIC{w: w, id: id}
+		xi.ics[w] = ic
+		xi.icByID[id] = ic
+		if w.IsFocus() {
+			xi.setFocusLocked(ic, true)
+		}
+	case ximSync:
+		imID := r.get16()
+		icID := r.get16()
+		xi.syncReply(imID, icID)
+	case ximForwardEvent:
+		xi.recvForwardEvent(&r)
+	case ximCommit:
+		xi.recvCommit(&r)
+	case ximPreeditStart:
+		imID := r.get16()
+		icID := r.get16()
+		var b ximWriter
+		b.put16(imID)
+		b.put16(icID)
+		b.put32(0xffffffff) // -1 = no maximum length
+		xi.send(ximPreeditStartReply, b)
+	case ximPreeditDraw:
+		xi.recvPreeditDraw(&r)
+	case ximPreeditCaret:
+		xi.recvPreeditCaret(&r)
+	case ximPreeditDone:
+		r.get16()
+		if ic := xi.icByID[r.get16()]; ic != nil {
+			ic.preedit = nil
+			ic.cursor = 0
+			sendEvent(ic.w, &ime.Event{Action: ime.Preedit})
+		}
+	case ximError:
+		r.get16()
+		r.get16()
+		r.get16()
+		code := r.get16()
+		sz := int(r.get16())
+		r.get16()
+		log.Printf("x11driver: XIM error: %v: %v\n", code, string(r.bytes(sz)))
+	}
+}
+
+// recvOpenReply records the input method id and the ids of the input context
+// attributes that we use, and negotiates the encoding
+func (xi *ximImpl) recvOpenReply(r *ximReader) {
+	xi.imID = r.get16()
+	r.skip(int(r.get16())) // im attributes
+	icsz := int(r.get16())
+	r.get16()
+	icr := ximReader{buf: r.bytes(icsz)}
+	for !icr.done() {
+		id := icr.get16()
+		icr.get16() // type
+		nl := int(icr.get16())
+		xi.icAttrs[string(icr.bytes(nl))] = id
+		icr.skip(ximPad(2 + nl))
+	}
+	var encs ximWriter
+	encs.putStr("UTF-8")
+	encs.putStr("COMPOUND_TEXT")
+	var b ximWriter
+	b.put16(xi.imID)
+	b.put16(uint16(encs.len()))
+	b.bytes(encs)
+	b.pad(encs.len())
+	b.put16(0) // no encoding info
+	b.put16(0)
+	xi.send(ximEncodingNegotiation, b)
+}
+
+// recvForwardEvent handles a key event sent back by the server, which it did
+// not use
+func (xi *ximImpl) recvForwardEvent(r *ximReader) {
+	imID, icID, flag, _, xev := ximReadForwardEvent(r)
+	if len(xev) == 32 {
+		switch xev[0] & 0x7f {
+		case xproto.KeyPress:
+			ev := xproto.KeyPressEventNew(xev).(xproto.KeyPressEvent)
+			if w := xi.app.findWindow(ev.Event); w != nil {
+				w.handleKey(ev.Detail, ev.State, key.Press)
+			}
+		case xproto.KeyRelease:
+			ev := xproto.KeyReleaseEventNew(xev).(xproto.KeyReleaseEvent)
+			if w := xi.app.findWindow(ev.Event); w != nil {
+				w.handleKey(ev.Detail, ev.State, key.Release)
+			}
+		}
+	}
+	if flag&ximFlagSync != 0 {
+		xi.syncReply(imID, icID)
+	}
+}
+
+// recvCommit sends the committed text as an ime.Commit event
+func (xi *ximImpl) recvCommit(r *ximReader) {
+	imID := r.get16()
+	icID := r.get16()
+	flag := r.get16()
+	var txt string
+	if flag&ximCommitKeySym != 0 {
+		// note: commits of a keysym only are not used by any current servers
+		r.get16()
+		r.get32()
+	}
+	if flag&ximCommitChars != 0 {
+		txt = xi.decode(r.bytes(int(r.get16())))
+	}
+	if ic := xi.icByID[icID]; ic != nil && txt != "" {
+		ic.preedit = nil
+		ic.cursor = 0
+		sendEvent(ic.w, &ime.Event{Action: ime.Commit, Text: txt})
+	}
+	if flag&ximFlagSync != 0 {
+		xi.syncReply(imID, icID)
+	}
+}
+
+// recvPreeditDraw updates the preedit text, and sends it as an ime.Preedit event
+func (xi *ximImpl) recvPreeditDraw(r *ximReader) {
+	r.get16()
+	ic := xi.icByID[r.get16()]
+	caret := int(int32(r.get32()))
+	first := int(int32(r.get32()))
+	chlen := int(int32(r.get32()))
+	status := r.get32()
+	var ins []rune
+	if status&ximDrawNoString == 0 {
+		ins = []rune(xi.decode(r.bytes(int(r.get16()))))
+	}
+	if ic == nil {
+		return
+	}
+	sz := len(ic.preedit)
+	first = clampInt(first, 0, sz)
+	end := clampInt(first+chlen, first, sz)
+	pe := make([]rune, 0, sz-(end-first)+len(ins))
+	pe = append(pe, ic.preedit[:first]...)
+	pe = append(pe, ins...)
+	pe = append(pe, ic.preedit[end:]...)
+	ic.preedit = pe
+	ic.cursor = clampInt(caret, 0, len(pe))
+	sendEvent(ic.w, &ime.Event{Action: ime.Preedit, Text: string(ic.preedit), Cursor: ic.cursor})
+}
+
+// recvPreeditCaret moves the preedit cursor, and replies with its new position
+func (xi *ximImpl) recvPreeditCaret(r *ximReader) {
+	imID := r.get16()
+	icID := r.get16()
+	pos := int(int32(r.get32()))
+	dir := r.get32()
+	ic := xi.icByID[icID]
+	if ic == nil {
+		return
+	}
+	switch dir {
+	case ximCaretForward:
+		ic.cursor++
+	case ximCaretBackward:
+		ic.cursor--
+	case ximCaretLineStart:
+		ic.cursor = 0
+	case ximCaretLineEnd:
+		ic.cursor = len(ic.preedit)
+	case ximCaretAbsolute:
+		ic.cursor = pos
+	}
+	ic.cursor = clampInt(ic.cursor, 0, len(ic.preedit))
+	sendEvent(ic.w, &ime.Event{Action: ime.Preedit, Text: string(ic.preedit), Cursor: ic.cursor})
+	var b ximWriter
+	b.put16(imID)
+	b.put16(icID)
+	b.put32(uint32(ic.cursor))
+	xi.send(ximPreeditCaretReply, b)
+}
+
+func (xi *ximImpl) syncReply(imID, icID uint16) {
+	var b ximWriter
+	b.put16(imID)
+	b.put16(icID)
+	xi.send(ximSyncReply, b)
+}
+
+// createIC creates an input context for given window, if the input method is
+// ready -- otherwise it is created for all windows once it is
+func (xi *ximImpl) createIC(w *windowImpl) {
+	xi.mu.Lock()
+	defer xi.mu.Unlock()
+	if xi.ready {
+		xi.createICLocked(w)
+	}
+}
+
+func (xi *ximImpl) createICLocked(w *windowImpl) {
+	if _, has := xi.ics[w]; has {
+		return
+	}
+	for _, pw := range xi.pending {
+		if pw == w {
+			return
+		}
+	}
+	var attrs ximWriter
+	xi.putAttr32(&attrs, "inputStyle", ximPreeditCallbacks|ximStatusNothing)
+	xi.putAttr32(&attrs, "clientWindow", uint32(w.xw))
+	xi.putAttr32(&attrs, "focusWindow", uint32(w.xw))
+	var b ximWriter
+	b.put16(xi.imID)
+	b.put16(uint16(attrs.len()))
+	b.bytes(attrs)
+	xi.pending = append(xi.pending, w)
+	xi.send(ximCreateIC, b)
+}
+
+// destroyIC destroys the input context of given window, which is closing
+func (xi *ximImpl) destroyIC(w *windowImpl) {
+	xi.mu.Lock()
+	defer xi.mu.Unlock()
+	for i, pw := range xi.pending {
+		if pw == w { // reply still to come: destroyed when it does
+			xi.pending[i] = nil
+		}
+	}
+	ic, has := xi.ics[w]
+	if !has {
+		return
+	}
+	delete(xi.ics, w)
+	delete(xi.icByID, ic.id)
+	if xi.ready {
+		var b ximWriter
+		b.put16(xi.imID)
+		b.put16(ic.id)
+		xi.send(ximDestroyIC, b)
+	}
+}
+
+// setFocus tells the server when a window gets or loses the focus
+func (xi *ximImpl) setFocus(w *windowImpl, focus bool) {
+	xi.mu.Lock()
+	defer xi.mu.Unlock()
+	if ic, has := xi.ics[w]; has {
+		xi.setFocusLocked(ic, focus)
+	}
+}
+
+func (xi *ximImpl) setFocusLocked(ic *ximIC, focus bool) {
+	if !xi.ready || ic.focus == focus {
+		return
+	}
+	ic.focus = focus
+	var b ximWriter
+	b.put16(xi.imID)
+	b.put16(ic.id)
+	if focus {
+		xi.send(ximSetICFocus, b)
+	} else {
+		xi.send(ximUnsetICFocus, b)
+	}
+}
+
+// setSpot sets the position of the text cursor in given window, where the
+// server shows its candidate window
+func (xi *ximImpl) setSpot(w *windowImpl, pos image.Point) {
+	xi.mu.Lock()
+	defer xi.mu.Unlock()
+	ic, has := xi.ics[w]
+	if !xi.ready || !has || ic.spot == pos {
+		return
+	}
+	ic.spot = pos
+	peid, ok := xi.icAttrs["preeditAttributes"]
+	if !ok {
+		return
+	}
+	var spot ximWriter
+	xi.putAttr(&spot, "spotLocation", []byte{byte(pos.X), byte(pos.X >> 8), byte(pos.Y), byte(pos.Y >> 8)})
+	var attrs ximWriter
+	attrs.put16(peid)
+	attrs.put16(uint16(spot.len()))
+	attrs.bytes(spot)
+	attrs.pad(spot.len())
+	var b ximWriter
+	b.put16(xi.imID)
+	b.put16(ic.id)
+	b.put16(uint16(attrs.len()))
+	b.put16(0)
+	b.bytes(attrs)
+	xi.send(ximSetICValues, b)
+}
+
+// forwardKey forwards a key event for given window to the server, returning
+// false if it should be processed directly instead
+func (xi *ximImpl) forwardKey(w *windowImpl, xev []byte, serial uint16, release bool) bool {
+	xi.mu.Lock()
+	defer xi.mu.Unlock()
+	ic, has := xi.ics[w]
+	if !xi.ready || !has {
+		return false
+	}
+	mask := uint32(xproto.EventMaskKeyPress)
+	if release {
+		mask = xproto.EventMaskKeyRelease
+	}
+	if xi.fwdMask&mask == 0 {
+		return false
+	}
+	xi.send(ximForwardEvent, ximForwardEventBody(xi.imID, ic.id, 0, serial, xev))
+	return true
+}
+
+// ximForwardEventBody returns the body of a XIM_FORWARD_EVENT message for
+// given X key event (32 bytes)
+func ximForwardEventBody(imID, icID, flag, serial uint16, xev []byte) ximWriter {
+	var b ximWriter
+	b.put16(imID)
+	b.put16(icID)
+	b.put16(flag)
+	b.put16(serial)
+	b.bytes(xev)
+	return b
+}
+
+// ximReadForwardEvent reads the body of a XIM_FORWARD_EVENT message -- xev
+// is the X event, which is short if the message is truncated
+func ximReadForwardEvent(r *ximReader) (imID, icID, flag, serial uint16, xev []byte) {
+	imID = r.get16()
+	icID = r.get16()
+	flag = r.get16()
+	serial = r.get16()
+	xev = r.bytes(32)
+	return
+}
+
+// putAttr32 adds a CARD32 valued attribute, if the server supports it
+func (xi *ximImpl) putAttr32(b *ximWriter, name string, val uint32) {
+	v := make([]byte, 4)
+	binary.LittleEndian.PutUint32(v, val)
+	xi.putAttr(b, name, v)
+}
+
+// putAttr adds an attribute, if the server supports it
+func (xi *ximImpl) putAttr(b *ximWriter, name string, val []byte) {
+	id, ok := xi.icAttrs[name]
+	if !ok {
+		return
+	}
+	b.put16(id)
+	b.put16(uint16(len(val)))
+	b.bytes(val)
+	b.pad(len(val))
+}
+
+// send sends a message to the server -- short ones fit in one
+// ClientMessage, and longer ones are sent with multiple ClientMessages or
+// through a window property, depending on what the server supports
+func (xi *ximImpl) send(op byte, body ximWriter) {
+	if xi.imsWin == xproto.WindowNone {
+		return
+	}
+	msg := ximMsg(op, body)
+	if XIMDebug {
+		fmt.Printf("XIM send: %v len: %v\n", op, len(msg))
+	}
+
+	xc := xi.app.xc
+	// transport 0.1 only has multi-CM, and 0.2 uses it up to the dividing size
+	multiCM := xi.major == 0 && (xi.minor == 1 || (xi.minor == 2 && len(msg) < xi.divSize))
+	if len(msg) <= ximCMSize || multiCM {
+		for len(msg) > 0 {
+			dat := make([]byte, ximCMSize)
+			n := copy(dat, msg)
+			msg = msg[n:]
+			typ := xi.atomProtocol
+			if len(msg) > 0 {
+				typ = xi.atomMoreData
+			}
+			cm := xproto.ClientMessageEvent{
+				Format: 8,
+				Window: xi.imsWin,
+				Type:   typ,
+				Data:   xproto.ClientMessageDataUnionData8New(dat),
+			}
+			xproto.SendEvent(xc, false, xi.imsWin, xproto.EventMaskNoEvent, string(cm.Bytes()))
+		}
+		return
+	}
+	prop := xi.propAtoms[xi.propIdx]
+	xi.propIdx = (xi.propIdx + 1) % ximNPropAtoms
+	xproto.ChangeProperty(xc, xproto.PropModeAppend, xi.imsWin, prop, xproto.AtomString, 8, uint32(len(msg)), msg)
+	cm := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: xi.imsWin,
+		Type:   xi.atomProtocol,
+		Data:   xproto.ClientMessageDataUnionData32New([]uint32{uint32(len(msg)), uint32(prop), 0, 0, 0}),
+	}
+	xproto.SendEvent(xc, false, xi.imsWin, xproto.EventMaskNoEvent, string(cm.Bytes()))
+}
+
+// ximMsg returns the message with given opcode and body: a header with the
+// opcode and the length of the body in 4 byte units, and the padded body
+func ximMsg(op byte, body ximWriter) []byte {
+	body.pad(len(body))
+	msg := make([]byte, 4, 4+len(body))
+	msg[0] = op
+	binary.LittleEndian.PutUint16(msg[2:], uint16(len(body)/4))
+	return append(msg, body...)
+}
+
+// decode decodes text from the server, in the negotiated encoding
+func (xi *ximImpl) decode(b []byte) string {
+	if xi.utf8 {
+		return string(b)
+	}
+	return decodeCompoundText(b)
+}
+
+// ximLocale returns the locale name to open the input method with
+func ximLocale() string {
+	for _, ev := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if lc := os.Getenv(ev); lc != "" {
+			return lc
+		}
+	}
+	return "C"
+}
+
+// decodeCompoundText decodes the COMPOUND_TEXT encoding, used by servers that
+// do not support UTF-8 -- only ASCII, Latin-1, and embedded UTF-8 segments
+// are supported, and characters in other character sets are replaced with
+// utf8.RuneError
+func decodeCompoundText(b []byte) string {
+	var sb strings.Builder
+	inUTF8 := false
+	other := false // in an unsupported character set
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if inUTF8 {
+			if c == 0x1b && i+2 < len(b) && b[i+1] == '%' && b[i+2] == '@' {
+				inUTF8 = false
+				i += 2
+				continue
+			}
+			r, sz := utf8.DecodeRune(b[i:])
+			sb.WriteRune(r)
+			i += sz - 1
+			continue
+		}
+		switch {
+		case c == 0x1b: // escape sequence: intermediate bytes then a final byte
+			j := i + 1
+			for j < len(b) && b[j] >= 0x20 && b[j] <= 0x2f {
+				j++
+			}
+			if j >= len(b) {
+				return sb.String()
+			}
+			seq := string(b[i+1 : j+1])
+			switch {
+			case seq == "%G":
+				inUTF8 = true
+			case seq == "(B" || seq == "-A": // ASCII or Latin-1
+				other = false
+			default:
+				other = true
+			}
+			i = j
+		case c == 0x9b: // direction control -- ignored
+			for i+1 < len(b) && (b[i+1] < 0x40 || b[i+1] > 0x7e) {
+				i++
+			}
+			i++
+		case c == '\n' || c == '\t':
+			sb.WriteByte(c)
+		case other && (c&0x7f) >= 0x20:
+			sb.WriteRune(utf8.RuneError)
+		default:
+			sb.WriteRune(rune(c))
+		}
+	}
+	return sb.String()
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// ximPad returns the number of padding bytes to bring n up to a multiple of 4
+func ximPad(n int) int {
+	return (4 - n%4) % 4
+}
+
+// ximWriter builds the body of a message, in little-endian byte order
+type ximWriter []byte
+
+func (b *ximWriter) len() int { return len(*b) }
+
+func (b *ximWriter) put8(v byte) { *b = append(*b, v) }
+
+func (b *ximWriter) put16(v uint16) { *b = append(*b, byte(v), byte(v>>8)) }
+
+func (b *ximWriter) put32(v uint32) {
+	*b = append(*b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func (b *ximWriter) bytes(v []byte) { *b = append(*b, v...) }
+
+// putStr adds a STR: a length byte followed by the string
+func (b *ximWriter) putStr(s string) {
+	b.put8(byte(len(s)))
+	*b = append(*b, s...)
+}
+
+// pad adds the padding for an item of n bytes
+func (b *ximWriter) pad(n int) {
+	*b = append(*b, make([]byte, ximPad(n))...)
+}
+
+// ximReader reads the body of a message -- reads past the end return zeros
+type ximReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *ximReader) done() bool { return r.pos >= len(r.buf) }
+
+func (r *ximReader) skip(n int) { r.pos += n }
+
+func (r *ximReader) bytes(n int) []byte {
+	st := clampInt(r.pos, 0, len(r.buf))
+	ed := clampInt(r.pos+n, st, len(r.buf))
+	r.pos += n
+	return r.buf[st:ed]
+}
+
+func (r *ximReader) get16() uint16 {
+	b := r.bytes(2)
+	if len(b) < 2 {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+func (r *ximReader) get32() uint32 {
+	b := r.bytes(4)
+	if len(b) < 4 {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
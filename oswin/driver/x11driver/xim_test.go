@@ -0,0 +1,146 @@
+// Copyright 2018 The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,!android dragonfly openbsd
+
+package x11driver
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BurntSushi/xgb/xproto"
+	"github.com/goki/gi/oswin/ime"
+)
+
+func TestXIMMsg(t *testing.T) {
+	var b ximWriter
+	b.put16(1)
+	b.put16(2)
+	msg := ximMsg(ximSyncReply, b)
+	if !bytes.Equal(msg, []byte{ximSyncReply, 0, 1, 0, 1, 0, 2, 0}) {
+		t.Errorf("sync reply: %v", msg)
+	}
+	b.put8(3)
+	msg = ximMsg(ximSyncReply, b)
+	if len(msg) != 12 || msg[2] != 2 || msg[8] != 3 {
+		t.Errorf("padded message: %v", msg)
+	}
+}
+
+func TestXIMForwardEvent(t *testing.T) {
+	kev := xproto.KeyPressEvent{Detail: 38, Event: 7, State: xproto.ModMaskShift}
+	xev := kev.Bytes()
+	msg := ximMsg(ximForwardEvent, ximForwardEventBody(1, 3, ximFlagSync, 42, xev))
+	if msg[0] != ximForwardEvent || len(msg) != 4+8+32 {
+		t.Fatalf("forward event message: %v", msg)
+	}
+	r := ximReader{buf: msg[4:]}
+	imID, icID, flag, serial, rxev := ximReadForwardEvent(&r)
+	if imID != 1 || icID != 3 || flag != ximFlagSync || serial != 42 || !bytes.Equal(rxev, xev) {
+		t.Errorf("forward event: %v %v %v %v %v", imID, icID, flag, serial, rxev)
+	}
+	if rkev := xproto.KeyPressEventNew(rxev).(xproto.KeyPressEvent); rkev.Detail != 38 || rkev.Event != 7 || rkev.State != xproto.ModMaskShift {
+		t.Errorf("forward event key: %v", rkev)
+	}
+	r = ximReader{buf: msg[4:24]}
+	if _, _, _, _, rxev = ximReadForwardEvent(&r); len(rxev) == 32 {
+		t.Errorf("truncated forward event should have a short event")
+	}
+}
+
+// testXIMContext returns an XIM connection (without a server) with an input
+// context with given id for a window
+func testXIMContext(id uint16) (*ximImpl, *windowImpl) {
+	w := &windowImpl{}
+	xi := &ximImpl{utf8: true, ics: map[*windowImpl]*ximIC{}, icByID: map[uint16]*ximIC{}}
+	ic := &ximIC{w: w, id: id}
+	xi.ics[w] = ic
+	xi.icByID[id] = ic
+	return xi, w
+}
+
+// testXIMRecv processes the message with given opcode and body, and returns
+// the resulting ime.Event
+func testXIMRecv(t *testing.T, xi *ximImpl, w *windowImpl, op byte, b ximWriter) *ime.Event {
+	xi.rdbuf = ximMsg(op, b)
+	xi.recv()
+	ev, ok := w.NextEvent().(*ime.Event)
+	if !ok {
+		t.Fatalf("message %v did not send an ime.Event", op)
+	}
+	return ev
+}
+
+// testXIMText puts given text in the message, with its length and padding
+func testXIMText(b *ximWriter, txt string) {
+	b.put16(uint16(len(txt)))
+	b.bytes([]byte(txt))
+	b.pad(2 + len(txt))
+}
+
+func TestXIMCommit(t *testing.T) {
+	xi, w := testXIMContext(3)
+	xi.icByID[3].preedit = []rune("nihon")
+	var b ximWriter
+	b.put16(1)
+	b.put16(3)
+	b.put16(ximCommitChars | ximFlagSync)
+	testXIMText(&b, "日本")
+	ev := testXIMRecv(t, xi, w, ximCommit, b)
+	if ev.Action != ime.Commit || ev.Text != "日本" {
+		t.Errorf("commit event: %v %q", ev.Action, ev.Text)
+	}
+	if len(xi.icByID[3].preedit) != 0 {
+		t.Errorf("commit should clear the preedit text")
+	}
+
+	xi.utf8 = false
+	b = nil
+	b.put16(1)
+	b.put16(3)
+	b.put16(ximCommitChars)
+	testXIMText(&b, "caf\xe9 \x1b%G日\x1b%@")
+	if ev = testXIMRecv(t, xi, w, ximCommit, b); ev.Text != "café 日" {
+		t.Errorf("compound text commit: %q", ev.Text)
+	}
+}
+
+func TestXIMPreeditDraw(t *testing.T) {
+	xi, w := testXIMContext(3)
+	draw := func(caret, first, chlen int, status uint32, txt string) *ime.Event {
+		var b ximWriter
+		b.put16(1)
+		b.put16(3)
+		b.put32(uint32(caret))
+		b.put32(uint32(first))
+		b.put32(uint32(chlen))
+		b.put32(status)
+		if status&ximDrawNoString == 0 {
+			testXIMText(&b, txt)
+		}
+		return testXIMRecv(t, xi, w, ximPreeditDraw, b)
+	}
+	if ev := draw(2, 0, 0, 0, "ka"); ev.Action != ime.Preedit || ev.Text != "ka" || ev.Cursor != 2 {
+		t.Errorf("preedit insert: %q %v", ev.Text, ev.Cursor)
+	}
+	if ev := draw(1, 0, 2, 0, "か"); ev.Text != "か" || ev.Cursor != 1 {
+		t.Errorf("preedit replace: %q %v", ev.Text, ev.Cursor)
+	}
+	if ev := draw(9, 1, 0, 0, "な"); ev.Text != "かな" || ev.Cursor != 2 {
+		t.Errorf("preedit append, caret clamped: %q %v", ev.Text, ev.Cursor)
+	}
+	if ev := draw(0, 0, 1, ximDrawNoString, ""); ev.Text != "な" || ev.Cursor != 0 {
+		t.Errorf("preedit delete: %q %v", ev.Text, ev.Cursor)
+	}
+
+	var b ximWriter
+	b.put16(1)
+	b.put16(3)
+	b.put32(0)
+	b.put32(ximCaretLineEnd)
+	if ev := testXIMRecv(t, xi, w, ximPreeditCaret, b); ev.Text != "な" || ev.Cursor != 1 {
+		t.Errorf("preedit caret: %q %v", ev.Text, ev.Cursor)
+	}
+}
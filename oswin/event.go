@@ -121,6 +121,11 @@ const (
 	// CustomEventType is a user-defined event with a data interface{} field
 	CustomEventType
 
+	// IMEEvent is for composed text input from an input method editor (IME),
+	// e.g., for CJK languages -- reports changes in the composition
+	// (preedit) text, and the final committed text
+	IMEEvent
+
 	// number of event types
 	EventTypeN
 )
@@ -0,0 +1,84 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ime defines events for composed text input from an input method
+// editor (IME), as used for CJK and other languages where one character
+// takes several keys to enter -- while composing, the IME reports the
+// current composition (preedit) text, which text widgets display at the
+// cursor, and then commits the final text, which is inserted.
+//
+// Keys that are not used by the IME are still delivered as regular key
+// events.  Widgets that accept text input should also call
+// oswin.Window.SetIMEPos with the position of their text cursor, so that the
+// IME can show its candidate window there.
+package ime
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/kit"
+)
+
+// ime.Event reports a change in composed text input from the IME
+type Event struct {
+	oswin.EventBase
+
+	// Action is what happened: the preedit text changed, or text was committed
+	Action Actions
+
+	// Text is the entire current preedit text for Preedit, which is empty
+	// when the composition ends, or the text to insert for Commit
+	Text string
+
+	// Cursor is the cursor position within the preedit Text, in runes
+	Cursor int
+}
+
+// Actions are the actions of an IME
+type Actions int32
+
+const (
+	// Preedit means that the composition (preedit) text changed -- Text is
+	// the new preedit text, and Cursor the cursor position within it -- an
+	// empty Text means that the composition has ended
+	Preedit Actions = iota
+
+	// Commit means that the IME has completed composing text -- Text is the
+	// final text, to be inserted at the cursor, replacing any preedit text
+	Commit
+
+	ActionsN
+)
+
+//go:generate stringer -type=Actions
+
+var KiT_Actions = kit.Enums.AddEnum(ActionsN, false, nil)
+
+/////////////////////////////
+// oswin.Event interface
+
+func (ev Event) Type() oswin.EventType {
+	return oswin.IMEEvent
+}
+
+func (ev Event) HasPos() bool {
+	return false
+}
+
+func (ev Event) Pos() image.Point {
+	return image.ZP
+}
+
+func (ev Event) OnFocus() bool {
+	return true
+}
+
+func (ev Event) String() string {
+	return fmt.Sprintf("Type: %v Action: %v  Text: %q  Cursor: %v  Time: %v", ev.Type(), ev.Action, ev.Text, ev.Cursor, ev.Time())
+}
+
+// check for interface implementation
+var _ oswin.Event = &Event{}
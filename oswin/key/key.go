@@ -63,6 +63,11 @@ type Event struct {
 	// Action is the key action taken: Press, Release, or None (for key repeats).
 	Action Actions
 
+	// Seq is the prefix of a multi-key chord sequence that was typed prior
+	// to this key, which this key completes -- it is included in the Chord --
+	// set by the gi.Window for chord events
+	Seq Chord
+
 	// TODO: add a Device ID, for multiple input devices?
 }
 
@@ -125,14 +130,30 @@ func (e *Event) HasAllModifier(mods ...Modifiers) bool {
 
 // key.Chord represents the key chord associated with a given key function -- it
 // is linked to the KeyChordEdit in the giv ValueView system so you can just
-// type keys to set key chords.
+// type keys to set key chords.  A chord can also be a multi-key sequence of
+// chords separated by ChordSeqSep, e.g., "Control+X, Control+S".
 type Chord string
 
+// ChordSeqSep separates the chords of a multi-key chord sequence -- a chord
+// only has a space as the space bar rune, by itself, so this cannot occur
+// within a chord
+const ChordSeqSep = ", "
+
 // Chord returns a string representation of the keyboard event suitable for
 // keyboard function maps, etc -- printable runes are sent directly, and
 // non-printable ones are converted to their corresponding code names without
-// the "Code" prefix.
+// the "Code" prefix.  If the event completes a multi-key sequence, the Seq
+// prefix is included.
 func (e *Event) Chord() Chord {
+	if e.Seq != "" {
+		return e.Seq + ChordSeqSep + e.KeyChord()
+	}
+	return e.KeyChord()
+}
+
+// KeyChord returns the chord for the key of this event alone, without any
+// Seq prefix -- see Chord
+func (e *Event) KeyChord() Chord {
 	modstr := ModsString(e.Modifiers)
 	if modstr != "" && e.Code == CodeSpacebar { // modified space is not regular space
 		return Chord(modstr + "Spacebar")
@@ -149,9 +170,38 @@ func (e *Event) Chord() Chord {
 	return Chord(modstr + codestr)
 }
 
+// SplitSeq splits a multi-key chord sequence into the prefix sequence and the
+// last chord -- the prefix is empty for a single chord
+func (ch Chord) SplitSeq() (prefix, last Chord) {
+	cs := string(ch)
+	i := strings.LastIndex(cs, ChordSeqSep)
+	if i <= 0 {
+		return "", ch
+	}
+	return Chord(cs[:i]), Chord(cs[i+len(ChordSeqSep):])
+}
+
+// IsSeq returns true if the chord is a multi-key sequence of chords
+func (ch Chord) IsSeq() bool {
+	prefix, _ := ch.SplitSeq()
+	return prefix != ""
+}
+
+// Chords returns the individual chords of a multi-key chord sequence, or
+// just the chord itself if it is not a sequence
+func (ch Chord) Chords() []Chord {
+	prefix, last := ch.SplitSeq()
+	if prefix == "" {
+		return []Chord{last}
+	}
+	return append(prefix.Chords(), last)
+}
+
 // Decode decodes a chord string into rune and modifiers (set as bit flags)
+// -- for a multi-key sequence, it decodes the last chord (see SplitSeq)
 func (ch Chord) Decode() (r rune, mods int32, err error) {
-	cs := string(ch)
+	_, last := ch.SplitSeq()
+	cs := string(last)
 	mods, cs = ModsFmString(cs)
 	rs := ([]rune)(cs)
 	if len(rs) == 1 {
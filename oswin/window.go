@@ -118,6 +118,13 @@ type Window interface {
 	// input etc).
 	IsFocus() bool
 
+	// SetIMEPos sets the position, in raw underlying dots / pixels relative to
+	// the window, of the text cursor where composed text input from an input
+	// method editor (IME) takes place -- the IME shows its candidate window
+	// there (see the ime package).  Only drivers that support an IME do
+	// anything with this.
+	SetIMEPos(pos image.Point)
+
 	// SetCloseReqFunc sets the function that is called whenver there is a
 	// request to close the window (via a OS or a call to CloseReq() method).  That
 	// function can then adjudicate whether and when to actually call Close.
@@ -223,6 +230,10 @@ func (w *WindowBase) IsFocus() bool {
 	return bitflag.HasAtomic(&w.Flag, int(Focus))
 }
 
+// SetIMEPos does nothing by default -- drivers with IME support override it
+func (w *WindowBase) SetIMEPos(pos image.Point) {
+}
+
 ////////////////////////////////////////////////////////////////////////////
 // WindowOptions
 
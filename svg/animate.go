@@ -0,0 +1,1000 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/kit"
+)
+
+// Animator is the interface for the SMIL animation elements: Animate, Set,
+// AnimateTransform and AnimateMotion, which are applied to their target
+// element at the current time of the Clock of the SVG, prior to rendering
+// it (see SVG ApplyAnims)
+type Animator interface {
+	gi.Node2D
+
+	// AsAnimBase returns the AnimBase for the animation
+	AsAnimBase() *AnimBase
+
+	// ApplyAnim applies the animation to its target at given time, in
+	// seconds, if it is active at that time
+	ApplyAnim(t float32)
+
+	// RestoreAnim restores the values of the target that were changed by
+	// the last ApplyAnim, other than its transform
+	RestoreAnim()
+
+	// AnimProp returns true if the animation sets a style property of its
+	// target, which must then be styled again
+	AnimProp() bool
+
+	// AnimXForm returns true if the animation sets the transform of its
+	// target, which is reset from its transform property prior to applying
+	// any such animations
+	AnimXForm() bool
+}
+
+// AnimBase has the timing and value attributes that are common to all of
+// the animation elements, which by default animate their parent element
+type AnimBase struct {
+	NodeBase
+	Href        string        `xml:"href" desc:"link to the element to animate, e.g., #id -- the parent element if empty"`
+	Begin       float32       `xml:"begin" desc:"time at which the animation begins, in seconds"`
+	BeginIndef  bool          `desc:"begin is indefinite (or an event, which is not supported), so the animation does not begin until BeginAt is called"`
+	Dur         float32       `xml:"dur" desc:"duration of each iteration of the animation, in seconds -- 0 is indefinite"`
+	RepeatCount float32       `xml:"repeatCount" desc:"number of iterations of the animation, which can be fractional -- 0 means one iteration, and -1 repeats indefinitely"`
+	Freeze      bool          `xml:"fill" desc:"fill = freeze: keep the final value of the animation after it ends, instead of removing its effect"`
+	CalcMode    AnimCalcModes `xml:"calcMode" desc:"how the values are interpolated"`
+	Values      []string      `xml:"values" desc:"values to animate through in turn -- overrides From, To and By"`
+	KeyTimes    []float32     `xml:"keyTimes" desc:"times for each of the Values, as proportions 0..1 of the duration -- evenly spaced if empty"`
+	From        string        `xml:"from" desc:"starting value -- the underlying value of the target if empty"`
+	To          string        `xml:"to" desc:"ending value"`
+	By          string        `xml:"by" desc:"ending value, relative to the starting value, if no To value"`
+	Additive    bool          `xml:"additive" desc:"additive = sum: add the animated value to the underlying value of the target, instead of replacing it"`
+	saved       animSaved
+}
+
+var KiT_AnimBase = kit.Types.AddType(&AnimBase{}, nil)
+
+// AnimCalcModes are the ways in which the values of an animation are
+// interpolated
+type AnimCalcModes int32
+
+const (
+	// AnimLinear interpolates linearly between the values
+	AnimLinear AnimCalcModes = iota
+
+	// AnimDiscrete jumps from one value to the next, without interpolation
+	AnimDiscrete
+
+	// AnimPaced interpolates at an even pace -- currently the same as
+	// linear, except for motion paths, which are always paced
+	AnimPaced
+
+	// AnimSpline interpolates along bezier curves -- not yet supported, and
+	// the same as linear
+	AnimSpline
+
+	AnimCalcModesN
+)
+
+//go:generate stringer -type=AnimCalcModes
+
+var KiT_AnimCalcModes = kit.Enums.AddEnumAltLower(AnimCalcModesN, false, nil, "Anim")
+
+func (ev AnimCalcModes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *AnimCalcModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// animSaved is the value of the target of an animation prior to applying
+// it, for restoring it
+type animSaved struct {
+	tgt     gi.Node2D
+	field   *float32
+	fval    float32
+	data    []PathData
+	prop    string
+	pval    interface{}
+	hasProp bool
+}
+
+func (g *AnimBase) AsAnimBase() *AnimBase {
+	return g
+}
+
+func (g *AnimBase) ApplyAnim(t float32) {
+}
+
+func (g *AnimBase) AnimProp() bool {
+	return false
+}
+
+func (g *AnimBase) AnimXForm() bool {
+	return false
+}
+
+func (g *AnimBase) RestoreAnim() {
+	sv := &g.saved
+	switch {
+	case sv.tgt == nil:
+		return
+	case sv.field != nil:
+		*sv.field = sv.fval
+	case sv.data != nil:
+		if p, ok := sv.tgt.(*Path); ok {
+			p.Data = sv.data
+		}
+	case sv.prop != "":
+		if sv.hasProp {
+			sv.tgt.SetProp(sv.prop, sv.pval)
+		} else {
+			sv.tgt.DeleteProp(sv.prop)
+		}
+	}
+	g.saved = animSaved{}
+}
+
+// Render2D does nothing -- animations are applied to their targets by the
+// SVG, and not rendered
+func (g *AnimBase) Render2D() {
+}
+
+// Target returns the element that the animation applies to: the one
+// referred to by Href, or the parent element
+func (g *AnimBase) Target() gi.Node2D {
+	if g.Href != "" {
+		return g.FindSVGURL(g.Href)
+	}
+	par, _ := gi.KiToNode2D(g.Par)
+	return par
+}
+
+// BeginAt sets the animation to begin at given time, in seconds, e.g., for
+// an animation with an indefinite begin
+func (g *AnimBase) BeginAt(t float32) {
+	g.Begin = t
+	g.BeginIndef = false
+}
+
+// Repeats returns the number of iterations of the animation, or -1 if
+// indefinite
+func (g *AnimBase) Repeats() float32 {
+	if g.RepeatCount == 0 {
+		return 1
+	}
+	return g.RepeatCount
+}
+
+// DoneTime returns the time after which the animation no longer changes
+func (g *AnimBase) DoneTime() float32 {
+	switch {
+	case g.BeginIndef:
+		return 0
+	case g.Dur <= 0:
+		return g.Begin
+	case g.RepeatCount < 0:
+		return math32.Inf(1)
+	}
+	return g.Begin + g.Dur*g.Repeats()
+}
+
+// Frac returns the progress of the current iteration of the animation at
+// given time, as a proportion 0..1 of its duration -- false if the
+// animation is not active at that time (and not frozen at its end)
+func (g *AnimBase) Frac(t float32) (float32, bool) {
+	if g.BeginIndef || t < g.Begin {
+		return 0, false
+	}
+	if g.Dur <= 0 {
+		return 0, true
+	}
+	lt := t - g.Begin
+	if rc := g.Repeats(); rc > 0 && lt >= rc*g.Dur {
+		if !g.Freeze {
+			return 0, false
+		}
+		if f := rc - math32.Floor(rc); f > 0 {
+			return f, true
+		}
+		return 1, true
+	}
+	return math32.Mod(lt, g.Dur) / g.Dur, true
+}
+
+// valueIndex returns the index of the value at given progress frac among n
+// values, and the weight 0..1 of the next value for interpolating toward it
+// -- discrete never interpolates
+func (g *AnimBase) valueIndex(frac float32, n int, discrete bool) (int, float32) {
+	if n <= 1 {
+		return 0, 0
+	}
+	kt := g.KeyTimes
+	if len(kt) != n {
+		kt = nil
+	}
+	if discrete {
+		if kt == nil {
+			i := int(frac * float32(n))
+			if i >= n {
+				i = n - 1
+			}
+			return i, 0
+		}
+		i := 0
+		for j := 1; j < n; j++ {
+			if kt[j] <= frac {
+				i = j
+			}
+		}
+		return i, 0
+	}
+	if kt == nil {
+		seg := frac * float32(n-1)
+		i := int(seg)
+		if i >= n-1 {
+			i = n - 2
+		}
+		return i, seg - float32(i)
+	}
+	for i := 0; i < n-1; i++ {
+		if frac > kt[i+1] && i < n-2 {
+			continue
+		}
+		d := kt[i+1] - kt[i]
+		if d <= 0 {
+			return i, 1
+		}
+		return i, gi.InRange32((frac-kt[i])/d, 0, 1)
+	}
+	return n - 2, 1
+}
+
+// ValueStrings returns the values of the animation from Values, or From, To
+// and By, using base as the starting value if there is no From -- by is true
+// if the last value is relative to the first one
+func (g *AnimBase) ValueStrings(base string) (vals []string, by bool) {
+	switch {
+	case len(g.Values) > 0:
+		return g.Values, false
+	case g.To != "":
+		if g.From != "" {
+			return []string{g.From, g.To}, false
+		}
+		return []string{base, g.To}, false
+	case g.By != "":
+		if g.From != "" {
+			return []string{g.From, g.By}, true
+		}
+		return []string{base, g.By}, true
+	}
+	return nil, false
+}
+
+// animVecs parses the given values into vectors of numbers using given
+// parse function, adding the first value to the last if by -- false if
+// there are no values, or any could not be parsed or differ in length
+func animVecs(vals []string, by bool, parse func(s string) ([]float32, bool)) ([][]float32, bool) {
+	if len(vals) == 0 {
+		return nil, false
+	}
+	vecs := make([][]float32, len(vals))
+	for i, s := range vals {
+		v, ok := parse(s)
+		if !ok || (i > 0 && len(v) != len(vecs[0])) {
+			return nil, false
+		}
+		vecs[i] = v
+	}
+	if by && len(vecs) == 2 {
+		for i := range vecs[1] {
+			vecs[1][i] += vecs[0][i]
+		}
+	}
+	return vecs, true
+}
+
+// interpVecs returns the value at given progress frac, interpolated between
+// the given values parsed into vectors of numbers by parse -- false if they
+// could not be parsed
+func (g *AnimBase) interpVecs(frac float32, vals []string, by bool, parse func(s string) ([]float32, bool)) ([]float32, bool) {
+	vecs, ok := animVecs(vals, by, parse)
+	if !ok {
+		return nil, false
+	}
+	i, w := g.valueIndex(frac, len(vecs), g.CalcMode == AnimDiscrete)
+	if w == 0 {
+		return vecs[i], true
+	}
+	a, b := vecs[i], vecs[i+1]
+	v := make([]float32, len(a))
+	for j := range a {
+		v[j] = a[j] + w*(b[j]-a[j])
+	}
+	return v, true
+}
+
+// discreteVal returns the value at given progress frac, among the given
+// values, without interpolation
+func (g *AnimBase) discreteVal(frac float32, vals []string) (string, bool) {
+	if len(vals) == 0 {
+		return "", false
+	}
+	i, _ := g.valueIndex(frac, len(vals), true)
+	return vals[i], true
+}
+
+// ParseAnimNums parses a list of numbers separated by commas and / or
+// spaces, as used for animation values -- false if empty or not all
+// numbers
+func ParseAnimNums(s string) ([]float32, bool) {
+	flds := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	if len(flds) == 0 {
+		return nil, false
+	}
+	v := make([]float32, len(flds))
+	for i, f := range flds {
+		fv, err := strconv.ParseFloat(f, 32)
+		if err != nil {
+			return nil, false
+		}
+		v[i] = float32(fv)
+	}
+	return v, true
+}
+
+// parseAnimColor parses a color value as a vector of its RGBA components
+func parseAnimColor(s string) ([]float32, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "none" || strings.HasPrefix(s, "url(") {
+		return nil, false
+	}
+	var c gi.Color
+	if err := c.SetString(s, nil); err != nil {
+		return nil, false
+	}
+	return []float32{float32(c.R), float32(c.G), float32(c.B), float32(c.A)}, true
+}
+
+// splitAnimUnit splits a value into its number and any unit suffix (e.g., px)
+func splitAnimUnit(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	i := strings.LastIndexFunc(s, func(r rune) bool { return unicode.IsDigit(r) || r == '.' })
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i+1], s[i+1:]
+}
+
+// AnimFieldPtr returns a pointer to the field of given element for given
+// geometric attribute (e.g., x, width, r), or nil if it has no such
+// attribute
+func AnimFieldPtr(gii gi.Node2D, name string) *float32 {
+	switch g := gii.(type) {
+	case *Rect:
+		switch name {
+		case "x":
+			return &g.Pos.X
+		case "y":
+			return &g.Pos.Y
+		case "width":
+			return &g.Size.X
+		case "height":
+			return &g.Size.Y
+		case "rx":
+			return &g.Radius.X
+		case "ry":
+			return &g.Radius.Y
+		}
+	case *Circle:
+		switch name {
+		case "cx":
+			return &g.Pos.X
+		case "cy":
+			return &g.Pos.Y
+		case "r":
+			return &g.Radius
+		}
+	case *Ellipse:
+		switch name {
+		case "cx":
+			return &g.Pos.X
+		case "cy":
+			return &g.Pos.Y
+		case "rx":
+			return &g.Radii.X
+		case "ry":
+			return &g.Radii.Y
+		}
+	case *Line:
+		switch name {
+		case "x1":
+			return &g.Start.X
+		case "y1":
+			return &g.Start.Y
+		case "x2":
+			return &g.End.X
+		case "y2":
+			return &g.End.Y
+		}
+	case *Use:
+		switch name {
+		case "x":
+			return &g.Pos.X
+		case "y":
+			return &g.Pos.Y
+		case "width":
+			return &g.Size.X
+		case "height":
+			return &g.Size.Y
+		}
+	case *Image:
+		switch name {
+		case "x":
+			return &g.Pos.X
+		case "y":
+			return &g.Pos.Y
+		case "width":
+			return &g.Size.X
+		case "height":
+			return &g.Size.Y
+		}
+	case *Text:
+		switch name {
+		case "x":
+			return &g.Pos.X
+		case "y":
+			return &g.Pos.Y
+		}
+	case *TextPath:
+		if name == "startOffset" {
+			return &g.StartOffset
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+//  Animate, Set
+
+// Animate is a SVG animate element, which animates an attribute of its
+// target element: a geometric attribute (e.g., x, width, r), the path data
+// (d) of a Path, or a style property (e.g., fill, opacity) -- numbers,
+// colors and path data with the same commands are interpolated, and other
+// values change discretely
+type Animate struct {
+	AnimBase
+	AttributeName string `xml:"attributeName" desc:"name of the attribute or property to animate"`
+}
+
+var KiT_Animate = kit.Types.AddType(&Animate{}, nil)
+
+// AnimProp returns true if the attribute is a style property of the target
+func (g *Animate) AnimProp() bool {
+	tgt := g.Target()
+	if tgt == nil || AnimFieldPtr(tgt, g.AttributeName) != nil {
+		return false
+	}
+	_, isPath := tgt.(*Path)
+	return !(isPath && g.AttributeName == "d")
+}
+
+func (g *Animate) ApplyAnim(t float32) {
+	g.applyAttr(t, false)
+}
+
+// applyAttr applies the animation of the attribute at given time -- set uses
+// the To value for the entire duration
+func (g *Animate) applyAttr(t float32, set bool) {
+	tgt := g.Target()
+	if tgt == nil || g.AttributeName == "" {
+		return
+	}
+	frac, ok := g.Frac(t)
+	if !ok {
+		return
+	}
+	nm := g.AttributeName
+	vals := func(base string) ([]string, bool) {
+		if set {
+			return []string{g.To}, false
+		}
+		return g.ValueStrings(base)
+	}
+	sv := &g.saved
+	sv.tgt = tgt
+	if fp := AnimFieldPtr(tgt, nm); fp != nil {
+		sv.field, sv.fval = fp, *fp
+		vs, by := vals(gi.FmtFloat32(*fp))
+		if v, ok := g.interpVecs(frac, vs, by, ParseAnimNums); ok && len(v) == 1 {
+			if g.Additive {
+				*fp += v[0]
+			} else {
+				*fp = v[0]
+			}
+		}
+		return
+	}
+	if p, ok := tgt.(*Path); ok && nm == "d" {
+		sv.data = p.Data
+		vs, _ := vals(PathDataString(p.Data))
+		if v, ok := g.interpPathData(frac, vs); ok {
+			p.Data = v
+		}
+		return
+	}
+	sv.prop = nm
+	sv.pval, sv.hasProp = tgt.Prop(nm)
+	if set {
+		tgt.SetProp(nm, g.To)
+		return
+	}
+	base := ""
+	if sv.hasProp {
+		base = kit.ToString(sv.pval)
+	}
+	vs, by := vals(base)
+	if len(vs) == 0 {
+		return
+	}
+	nvs := make([]string, len(vs))
+	_, unit := splitAnimUnit(vs[len(vs)-1])
+	for i, s := range vs {
+		nvs[i], _ = splitAnimUnit(s)
+	}
+	if v, ok := g.interpVecs(frac, nvs, by, ParseAnimNums); ok && len(v) == 1 {
+		if g.Additive {
+			bs, _ := splitAnimUnit(base)
+			if bv, err := strconv.ParseFloat(bs, 32); err == nil {
+				v[0] += float32(bv)
+			}
+		}
+		tgt.SetProp(nm, gi.FmtFloat32(v[0])+unit)
+		return
+	}
+	if v, ok := g.interpVecs(frac, vs, false, parseAnimColor); ok {
+		var c gi.Color
+		c.SetUInt8(uint8(v[0]+0.5), uint8(v[1]+0.5), uint8(v[2]+0.5), uint8(v[3]+0.5))
+		if c.A == 255 {
+			tgt.SetProp(nm, c.HexString())
+		} else { // rgba keeps the alpha, as parsed by gi.Color.SetString
+			tgt.SetProp(nm, fmt.Sprintf("rgba(%d,%d,%d,%d)", c.R, c.G, c.B, c.A))
+		}
+		return
+	}
+	if s, ok := g.discreteVal(frac, vs); ok {
+		tgt.SetProp(nm, s)
+	}
+}
+
+// interpPathData returns the path data at given progress frac, among the
+// given path data values, which are interpolated if they all have the same
+// commands, and otherwise change discretely
+func (g *AnimBase) interpPathData(frac float32, vals []string) ([]PathData, bool) {
+	if len(vals) == 0 {
+		return nil, false
+	}
+	datas := make([][]PathData, len(vals))
+	for i, s := range vals {
+		d, err := PathDataParse(s)
+		if err != nil {
+			return nil, false
+		}
+		datas[i] = d
+	}
+	i, w := g.valueIndex(frac, len(datas), g.CalcMode == AnimDiscrete)
+	if w > 0 {
+		if d, ok := PathDataInterp(datas[i], datas[i+1], w); ok {
+			return d, true
+		}
+		i, _ = g.valueIndex(frac, len(datas), true)
+	}
+	return datas[i], true
+}
+
+// PathDataInterp returns the path data interpolated between a and b by
+// weight w 0..1 -- they must have the same sequence of commands, and
+// otherwise false is returned
+func PathDataInterp(a, b []PathData, w float32) ([]PathData, bool) {
+	sz := len(a)
+	if len(b) != sz {
+		return nil, false
+	}
+	d := make([]PathData, sz)
+	for i := 0; i < sz; {
+		ci := i
+		ca, n := PathDataNextCmd(a, &i)
+		cb, nb := b[ci].Cmd()
+		if ca != cb || n != nb || i+n > sz {
+			return nil, false
+		}
+		d[ci] = a[ci]
+		for j := i; j < i+n; j++ {
+			d[j] = a[j] + PathData(w)*(b[j]-a[j])
+		}
+		i += n
+	}
+	return d, true
+}
+
+// Set is a SVG set element, which sets an attribute of its target element
+// to the To value while it is active
+type Set struct {
+	Animate
+}
+
+var KiT_Set = kit.Types.AddType(&Set{}, nil)
+
+func (g *Set) ApplyAnim(t float32) {
+	g.applyAttr(t, true)
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+//  AnimateTransform
+
+// AnimateTransform is a SVG animateTransform element, which animates the
+// transform of its target element, replacing its transform property or,
+// if Additive, applying on top of it -- values are lists of the parameters
+// of the Type of transform, e.g., angle, cx, cy for rotate
+type AnimateTransform struct {
+	Animate
+	Type string `xml:"type" desc:"type of transform: translate, scale, rotate, skewX or skewY"`
+}
+
+var KiT_AnimateTransform = kit.Types.AddType(&AnimateTransform{}, nil)
+
+func (g *AnimateTransform) AnimProp() bool {
+	return false
+}
+
+func (g *AnimateTransform) AnimXForm() bool {
+	return true
+}
+
+func (g *AnimateTransform) ApplyAnim(t float32) {
+	tgt := g.Target()
+	pn, ok := tgt.(gi.Painter)
+	if tgt == nil || !ok {
+		return
+	}
+	frac, ok := g.Frac(t)
+	if !ok {
+		return
+	}
+	vs, by := g.ValueStrings(g.identityVal())
+	v, ok := g.interpVecs(frac, vs, by, g.parseVal)
+	if !ok {
+		return
+	}
+	pc := pn.Paint()
+	if g.Additive {
+		pc.XForm = g.XFormOf(v).Multiply(pc.XForm)
+	} else {
+		pc.XForm = g.XFormOf(v)
+	}
+}
+
+// identityVal returns the parameters for the identity transform of the Type
+func (g *AnimateTransform) identityVal() string {
+	if g.Type == "scale" {
+		return "1 1"
+	}
+	return "0 0 0"
+}
+
+// parseVal parses the parameters of the Type of transform, filling in the
+// defaults for missing ones
+func (g *AnimateTransform) parseVal(s string) ([]float32, bool) {
+	v, ok := ParseAnimNums(s)
+	if !ok {
+		return nil, false
+	}
+	switch g.Type {
+	case "scale":
+		if len(v) == 1 {
+			v = append(v, v[0])
+		}
+		return v[:2], true
+	case "rotate":
+		for len(v) < 3 {
+			v = append(v, 0)
+		}
+		return v[:3], true
+	case "skewX", "skewY":
+		return v[:1], true
+	}
+	if len(v) == 1 {
+		v = append(v, 0)
+	}
+	return v[:2], true
+}
+
+// XFormOf returns the transform for given parameters of the Type of
+// transform
+func (g *AnimateTransform) XFormOf(v []float32) gi.Matrix2D {
+	switch g.Type {
+	case "scale":
+		return gi.Scale2D(v[0], v[1])
+	case "rotate":
+		ang := v[0] * math32.Pi / 180
+		return gi.Translate2D(-v[1], -v[2]).Multiply(gi.Rotate2D(ang)).Multiply(gi.Translate2D(v[1], v[2]))
+	case "skewX":
+		return gi.Skew2D(v[0]*math32.Pi/180, 0)
+	case "skewY":
+		return gi.Skew2D(0, v[0]*math32.Pi/180)
+	case "translate", "":
+		return gi.Translate2D(v[0], v[1])
+	}
+	log.Printf("gi.svg AnimateTransform: type %v not supported\n", g.Type)
+	return gi.Identity2D()
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+//  AnimateMotion
+
+// AnimateMotion is a SVG animateMotion element, which moves its target
+// element along a motion path, or through the x,y points of its values, on
+// top of its transform, optionally rotating it to follow the path
+type AnimateMotion struct {
+	AnimBase
+	Path     []PathData `xml:"-" desc:"the motion path -- overrides Values, From, To and By"`
+	PathStr  string     `xml:"path" desc:"string version of the motion path"`
+	MPath    string     `xml:"-" desc:"link to a Path element whose data is used as the motion path, e.g., #id, as given by a mpath child element -- overrides Path"`
+	Rotate   string     `xml:"rotate" desc:"rotation of the target: auto to follow the direction of the path, auto-reverse for the opposite direction, or an angle in degrees"`
+	flat     *PathFlat
+	flatData []PathData
+}
+
+var KiT_AnimateMotion = kit.Types.AddType(&AnimateMotion{}, nil)
+
+// SetPath sets the motion path to given path data string
+func (g *AnimateMotion) SetPath(data string) error {
+	g.PathStr = data
+	var err error
+	g.Path, err = PathDataParse(data)
+	return err
+}
+
+func (g *AnimateMotion) AnimXForm() bool {
+	return true
+}
+
+func (g *AnimateMotion) ApplyAnim(t float32) {
+	tgt := g.Target()
+	pn, ok := tgt.(gi.Painter)
+	if tgt == nil || !ok {
+		return
+	}
+	frac, ok := g.Frac(t)
+	if !ok {
+		return
+	}
+	pt, ang, ok := g.MotionAt(frac)
+	if !ok {
+		return
+	}
+	rot := float32(0)
+	switch g.Rotate {
+	case "", "0":
+	case "auto":
+		rot = ang
+	case "auto-reverse":
+		rot = ang + math32.Pi
+	default:
+		if deg, err := strconv.ParseFloat(strings.TrimSpace(g.Rotate), 32); err == nil {
+			rot = float32(deg) * math32.Pi / 180
+		}
+	}
+	pc := pn.Paint()
+	pc.XForm = pc.XForm.Multiply(gi.Rotate2D(rot).Multiply(gi.Translate2D(pt.X, pt.Y)))
+}
+
+// MotionAt returns the position of the motion at given progress 0..1 of its
+// duration, and the angle of its direction there, in radians -- false if it
+// has no valid path or values
+func (g *AnimateMotion) MotionAt(frac float32) (gi.Vec2D, float32, bool) {
+	data := g.Path
+	if g.MPath != "" {
+		if p, ok := g.FindSVGURL(g.MPath).(*Path); ok {
+			data = p.Data
+		}
+	}
+	if len(data) > 0 {
+		pf := g.pathFlat(data)
+		if g.CalcMode == AnimDiscrete {
+			frac = 0
+		}
+		return pf.PointAt(frac * pf.Length())
+	}
+	vs, by := g.ValueStrings("0 0")
+	vecs, ok := animVecs(vs, by, ParseAnimNums)
+	if !ok || len(vecs[0]) != 2 {
+		return gi.Vec2D{}, 0, false
+	}
+	n := len(vecs)
+	i, w := g.valueIndex(frac, n, g.CalcMode == AnimDiscrete)
+	a := gi.Vec2D{vecs[i][0], vecs[i][1]}
+	if i+1 >= n {
+		return a, 0, true
+	}
+	b := gi.Vec2D{vecs[i+1][0], vecs[i+1][1]}
+	d := b.Sub(a)
+	return a.Add(d.MulVal(w)), math32.Atan2(d.Y, d.X), true
+}
+
+// pathFlat returns the flattened version of given motion path data,
+// caching it for as long as the data is the same
+func (g *AnimateMotion) pathFlat(data []PathData) *PathFlat {
+	if g.flat != nil && len(g.flatData) == len(data) && &g.flatData[0] == &data[0] {
+		return g.flat
+	}
+	mp := &Path{Data: data}
+	mp.Pnt.XForm = gi.Identity2D()
+	g.flat = NewPathFlat(mp)
+	g.flatData = data
+	return g.flat
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+//  XML
+
+// ParseClockValue parses a SMIL clock value, e.g., 2s, 500ms, 1.5min, 1h,
+// 01:30 or 00:01:30.5, returning the time in seconds -- a plain number is
+// in seconds
+func ParseClockValue(s string) (float32, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ":") {
+		var t float32
+		for _, p := range strings.Split(s, ":") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+			if err != nil {
+				return 0, err
+			}
+			t = t*60 + float32(v)
+		}
+		return t, nil
+	}
+	mult := float32(1)
+	switch {
+	case strings.HasSuffix(s, "ms"):
+		mult = 0.001
+		s = strings.TrimSuffix(s, "ms")
+	case strings.HasSuffix(s, "min"):
+		mult = 60
+		s = strings.TrimSuffix(s, "min")
+	case strings.HasSuffix(s, "h"):
+		mult = 3600
+		s = strings.TrimSuffix(s, "h")
+	case strings.HasSuffix(s, "s"):
+		s = strings.TrimSuffix(s, "s")
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 32)
+	return float32(v) * mult, err
+}
+
+// SetAnimXMLAttr sets the timing and value attributes of an animation from
+// given XML attribute, returning false if it is not one of them
+func SetAnimXMLAttr(g *AnimBase, name, val string) (bool, error) {
+	var err error
+	switch name {
+	case "href":
+		g.Href = val
+	case "begin":
+		bv := strings.TrimSpace(strings.Split(val, ";")[0])
+		if bv == "indefinite" {
+			g.BeginIndef = true
+			break
+		}
+		if g.Begin, err = ParseClockValue(bv); err != nil {
+			log.Printf("gi.svg animation begin: %v not supported -- only clock values are\n", val)
+			g.BeginIndef = true
+			err = nil
+		}
+	case "dur":
+		if val == "indefinite" || val == "media" {
+			g.Dur = 0
+			break
+		}
+		g.Dur, err = ParseClockValue(val)
+	case "repeatCount":
+		if val == "indefinite" {
+			g.RepeatCount = -1
+			break
+		}
+		g.RepeatCount, err = gi.ParseFloat32(strings.TrimSpace(val))
+	case "fill":
+		g.Freeze = strings.TrimSpace(val) == "freeze"
+	case "calcMode":
+		err = g.CalcMode.FromString("Anim" + strings.Title(strings.TrimSpace(val)))
+	case "values":
+		g.Values = nil
+		for _, v := range strings.Split(val, ";") {
+			if v = strings.TrimSpace(v); v != "" {
+				g.Values = append(g.Values, v)
+			}
+		}
+	case "keyTimes":
+		g.KeyTimes = nil
+		for _, v := range strings.Split(val, ";") {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+			var kt float32
+			if kt, err = gi.ParseFloat32(v); err != nil {
+				break
+			}
+			g.KeyTimes = append(g.KeyTimes, kt)
+		}
+	case "from":
+		g.From = val
+	case "to":
+		g.To = val
+	case "by":
+		g.By = val
+	case "additive":
+		g.Additive = strings.TrimSpace(val) == "sum"
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// animXMLAttrs returns the XML attributes for the timing and value
+// attributes of an animation
+func animXMLAttrs(g *AnimBase) []xml.Attr {
+	var attrs []xml.Attr
+	if g.Href != "" {
+		attrs = append(attrs, gi.NewXMLAttr("xlink:href", g.Href))
+	}
+	if g.BeginIndef {
+		attrs = append(attrs, gi.NewXMLAttr("begin", "indefinite"))
+	} else if g.Begin != 0 {
+		attrs = append(attrs, gi.NewXMLAttr("begin", gi.FmtFloat32(g.Begin)+"s"))
+	}
+	if g.Dur > 0 {
+		attrs = append(attrs, gi.NewXMLAttr("dur", gi.FmtFloat32(g.Dur)+"s"))
+	}
+	if g.RepeatCount < 0 {
+		attrs = append(attrs, gi.NewXMLAttr("repeatCount", "indefinite"))
+	} else if g.RepeatCount > 0 {
+		attrs = append(attrs, gi.NewXMLAttr("repeatCount", gi.FmtFloat32(g.RepeatCount)))
+	}
+	if g.Freeze {
+		attrs = append(attrs, gi.NewXMLAttr("fill", "freeze"))
+	}
+	if g.CalcMode != AnimLinear {
+		attrs = append(attrs, gi.NewXMLAttr("calcMode", strings.ToLower(strings.TrimPrefix(g.CalcMode.String(), "Anim"))))
+	}
+	if len(g.Values) > 0 {
+		attrs = append(attrs, gi.NewXMLAttr("values", strings.Join(g.Values, ";")))
+	}
+	if len(g.KeyTimes) > 0 {
+		kts := make([]string, len(g.KeyTimes))
+		for i, kt := range g.KeyTimes {
+			kts[i] = gi.FmtFloat32(kt)
+		}
+		attrs = append(attrs, gi.NewXMLAttr("keyTimes", strings.Join(kts, ";")))
+	}
+	if g.From != "" {
+		attrs = append(attrs, gi.NewXMLAttr("from", g.From))
+	}
+	if g.To != "" {
+		attrs = append(attrs, gi.NewXMLAttr("to", g.To))
+	}
+	if g.By != "" {
+		attrs = append(attrs, gi.NewXMLAttr("by", g.By))
+	}
+	if g.Additive {
+		attrs = append(attrs, gi.NewXMLAttr("additive", "sum"))
+	}
+	return attrs
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/kit"
+)
+
+const testAnimSVG = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 200 200">
+  <rect id="r1" x="10" y="10" width="20" height="20">
+    <animate attributeName="x" from="10" to="110" dur="2s" fill="freeze" />
+    <animate attributeName="opacity" values="1;0;1" dur="2s" repeatCount="indefinite" />
+  </rect>
+  <circle id="c1" cx="50" cy="50" r="5">
+    <animate attributeName="r" to="25" begin="1s" dur="1s" />
+    <set attributeName="fill" to="red" begin="1s" dur="1s" />
+  </circle>
+  <g id="g1">
+    <animateTransform attributeName="transform" type="rotate" from="0 100 100" to="90 100 100" dur="4s" />
+    <rect id="r2" x="0" y="0" width="10" height="10" />
+  </g>
+</svg>`
+
+func testAnimRead(t *testing.T) *SVG {
+	sv := &SVG{}
+	sv.InitName(sv, "svg")
+	if err := sv.ReadXML(strings.NewReader(testAnimSVG)); err != nil {
+		t.Fatal(err)
+	}
+	return sv
+}
+
+func TestAnimSeek(t *testing.T) {
+	sv := testAnimRead(t)
+	if n := len(sv.Anims()); n != 5 {
+		t.Fatalf("expected 5 animations, got: %v", n)
+	}
+	r1k, _ := sv.ChildByName("r1", 0)
+	c1k, _ := sv.ChildByName("c1", 0)
+	g1k, _ := sv.ChildByName("g1", 0)
+	r1, c1, g1 := r1k.(*Rect), c1k.(*Circle), g1k.(*Group)
+
+	sv.AnimSeek(0.5)
+	if r1.Pos.X != 35 {
+		t.Errorf("rect x at 0.5s expected: 35 got: %v", r1.Pos.X)
+	}
+	if op, _ := r1.Prop("opacity"); kit.ToString(op) != "0.5" {
+		t.Errorf("rect opacity at 0.5s expected: 0.5 got: %v", op)
+	}
+	if c1.Radius != 5 {
+		t.Errorf("circle r before begin expected: 5 got: %v", c1.Radius)
+	}
+	if _, has := c1.Prop("fill"); has {
+		t.Errorf("circle fill set before begin")
+	}
+
+	sv.AnimSeek(1.5)
+	if c1.Radius != 15 {
+		t.Errorf("circle r at 1.5s expected: 15 got: %v", c1.Radius)
+	}
+	if fill, _ := c1.Prop("fill"); kit.ToString(fill) != "red" {
+		t.Errorf("circle fill at 1.5s expected: red got: %v", fill)
+	}
+
+	sv.AnimSeek(2)
+	xf := gi.Translate2D(-100, -100).Multiply(gi.Rotate2D(45 * math32.Pi / 180)).Multiply(gi.Translate2D(100, 100))
+	if !testAnimXFormEq(g1.Pnt.XForm, xf) {
+		t.Errorf("group transform at 2s expected: %v got: %v", xf, g1.Pnt.XForm)
+	}
+
+	sv.AnimSeek(5)
+	if r1.Pos.X != 110 {
+		t.Errorf("frozen rect x expected: 110 got: %v", r1.Pos.X)
+	}
+	if c1.Radius != 5 {
+		t.Errorf("removed circle r expected: 5 got: %v", c1.Radius)
+	}
+	if _, has := c1.Prop("fill"); has {
+		t.Errorf("circle fill still set after end")
+	}
+	if !testAnimXFormEq(g1.Pnt.XForm, gi.Identity2D()) {
+		t.Errorf("group transform after end expected identity, got: %v", g1.Pnt.XForm)
+	}
+
+	if sv.AnimsDone(5) {
+		t.Errorf("indefinite animation reported as done")
+	}
+	if !sv.RestoreAnims() || r1.Pos.X != 10 {
+		t.Errorf("rect x after restore expected: 10 got: %v", r1.Pos.X)
+	}
+}
+
+func testAnimXFormEq(a, b gi.Matrix2D) bool {
+	eq := func(x, y float32) bool { return math32.Abs(x-y) < 1.0e-4 }
+	return eq(a.XX, b.XX) && eq(a.YX, b.YX) && eq(a.XY, b.XY) && eq(a.YY, b.YY) && eq(a.X0, b.X0) && eq(a.Y0, b.Y0)
+}
+
+func TestAnimKeyTimes(t *testing.T) {
+	an := &AnimBase{Dur: 1, Values: []string{"0", "10", "20"}, KeyTimes: []float32{0, 0.8, 1}}
+	for _, tc := range []struct {
+		t, exp float32
+	}{{0, 0}, {0.4, 5}, {0.9, 15}} {
+		frac, _ := an.Frac(tc.t)
+		vs, by := an.ValueStrings("")
+		v, ok := an.interpVecs(frac, vs, by, ParseAnimNums)
+		if !ok || math32.Abs(v[0]-tc.exp) > 1.0e-4 {
+			t.Errorf("value at %v expected: %v got: %v", tc.t, tc.exp, v)
+		}
+	}
+}
+
+func TestParseClockValue(t *testing.T) {
+	for _, tc := range []struct {
+		s   string
+		exp float32
+	}{{"2s", 2}, {"500ms", 0.5}, {"1.5min", 90}, {"1h", 3600}, {"01:30", 90}, {"00:01:30.5", 90.5}, {"3", 3}} {
+		v, err := ParseClockValue(tc.s)
+		if err != nil || math32.Abs(v-tc.exp) > 1.0e-4 {
+			t.Errorf("%v expected: %v got: %v %v", tc.s, tc.exp, v, err)
+		}
+	}
+}
+
+func TestAnimClock(t *testing.T) {
+	sv := &SVG{}
+	sv.InitName(sv, "svg")
+	src := `<svg xmlns="http://www.w3.org/2000/svg"><rect x="10" y="10" width="20" height="20"><animate attributeName="x" from="10" to="110" dur="2s" /></rect></svg>`
+	if err := sv.ReadXML(strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	clk := &gi.ManualClock{T: time.Unix(0, 0)}
+	an := &gi.Animator{Clock: clk}
+	sv.animStart(an)
+	clk.Advance(500 * time.Millisecond)
+	an.Step()
+	if ct := sv.Clock.CurTime(); ct != 0.5 || !sv.Clock.IsRunning() {
+		t.Errorf("clock time expected: 0.5 got: %v", ct)
+	}
+	sv.AnimPause()
+	clk.Advance(time.Second)
+	if an.NRunning() != 0 || sv.Clock.CurTime() != 0.5 {
+		t.Errorf("paused clock still stepped: %v", sv.Clock.CurTime())
+	}
+	sv.animStart(an)
+	clk.Advance(2 * time.Second)
+	an.Step()
+	if ct := sv.Clock.CurTime(); ct != 2.5 || sv.Clock.IsRunning() || an.NRunning() != 0 {
+		t.Errorf("clock should stop when done at: %v", ct)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+)
+
+// AnimClock is the timeline for the animations of an SVG -- while running,
+// it is stepped by the Animator of the window (see gi.Animator), on the
+// window event loop, updating the Time and signaling the SVG to re-render,
+// which re-renders just that viewport into the window -- it can also be
+// paused, and set to any time with AnimSeek, e.g., for testing
+type AnimClock struct {
+	Time    float32      `desc:"current time of the animations, in seconds"`
+	Running bool         `desc:"the clock is running, updating the time in real time"`
+	Paused  bool         `desc:"the clock was paused, or set to a time by AnimSeek, and is not started automatically upon rendering"`
+	started bool         // the clock was started
+	start   time.Time    // animator time corresponding to a Time of 0
+	anims   *gi.Animator // animator stepping the clock while running
+	mu      sync.Mutex   // protects the clock state
+}
+
+// CurTime returns the current time of the clock, in seconds
+func (ac *AnimClock) CurTime() float32 {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	return ac.Time
+}
+
+// IsRunning returns true if the clock is running
+func (ac *AnimClock) IsRunning() bool {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	return ac.Running
+}
+
+// Anims returns all of the animation elements in the svg, including within
+// Defs, but not within any nested SVG, which has its own clock
+func (svg *SVG) Anims() []Animator {
+	var anims []Animator
+	fun := func(k ki.Ki, level int, d interface{}) bool {
+		if k != svg.This() {
+			if _, ok := k.(*SVG); ok {
+				return false
+			}
+		}
+		if an, ok := k.(Animator); ok {
+			anims = append(anims, an)
+		}
+		return true
+	}
+	for _, k := range svg.Defs.Kids {
+		k.FuncDownMeFirst(0, nil, fun)
+	}
+	svg.FuncDownMeFirst(0, nil, fun)
+	return anims
+}
+
+// ApplyAnims applies all of the animations of the svg at the current time of
+// the Clock, first restoring the values changed by the last application --
+// returns false if there are no animations
+func (svg *SVG) ApplyAnims() bool {
+	anims := svg.Anims()
+	if len(anims) == 0 {
+		return false
+	}
+	t := svg.Clock.CurTime()
+	for i := len(anims) - 1; i >= 0; i-- {
+		anims[i].RestoreAnim()
+	}
+	xfs := make(map[gi.Node2D]bool)
+	for _, an := range anims {
+		if !an.AnimXForm() {
+			continue
+		}
+		tgt := an.AsAnimBase().Target()
+		if tgt == nil || xfs[tgt] {
+			continue
+		}
+		xfs[tgt] = true
+		if pn, ok := tgt.(gi.Painter); ok {
+			xf := gi.Identity2D()
+			if tp, ok := tgt.Prop("transform"); ok {
+				switch tv := tp.(type) {
+				case string:
+					xf.SetString(tv)
+				case *gi.Matrix2D:
+					xf = *tv
+				}
+			}
+			pn.Paint().XForm = xf
+		}
+	}
+	styled := make(map[gi.Node2D]bool)
+	for _, an := range anims {
+		if !an.AnimProp() {
+			continue
+		}
+		an.ApplyAnim(t)
+		tgt := an.AsAnimBase().Target()
+		if tgt != nil && !styled[tgt] && tgt.AsNode2D().Viewport != nil {
+			styled[tgt] = true
+		}
+	}
+	for tgt := range styled {
+		tgt.AsNode2D().Style2DTree()
+	}
+	for _, an := range anims {
+		if !an.AnimProp() {
+			an.ApplyAnim(t)
+		}
+	}
+	return true
+}
+
+// RestoreAnims restores all of the values changed by the animations of the
+// svg, e.g., prior to saving it, returning true if any were restored --
+// ApplyAnims applies them again
+func (svg *SVG) RestoreAnims() bool {
+	anims := svg.Anims()
+	restored := false
+	for i := len(anims) - 1; i >= 0; i-- {
+		if anims[i].AsAnimBase().saved.tgt != nil {
+			restored = true
+		}
+		anims[i].RestoreAnim()
+	}
+	return restored
+}
+
+// AnimsDone returns true if none of the animations of the svg change after
+// given time
+func (svg *SVG) AnimsDone(t float32) bool {
+	for _, an := range svg.Anims() {
+		if an.AsAnimBase().DoneTime() > t {
+			return false
+		}
+	}
+	return true
+}
+
+// animName is the name of the gi.Anim that steps the animation clock
+const animName = "svg-anims"
+
+// AnimStart starts (or resumes) running the animation clock from its current
+// time, stepped by the Animator of the window -- this is done automatically
+// upon first rendering an svg with animations, unless paused
+func (svg *SVG) AnimStart() {
+	win := svg.ParentWindow()
+	if win == nil {
+		svg.Clock.mu.Lock()
+		svg.Clock.started = true
+		svg.Clock.Paused = false
+		svg.Clock.mu.Unlock()
+		return
+	}
+	svg.animStart(&win.Anims)
+}
+
+// animStart starts running the animation clock, stepped by given animator
+func (svg *SVG) animStart(an *gi.Animator) {
+	ac := &svg.Clock
+	ac.mu.Lock()
+	ac.started = true
+	ac.Paused = false
+	if ac.Running {
+		ac.mu.Unlock()
+		return
+	}
+	ac.Running = true
+	ac.anims = an
+	ac.start = an.Now().Add(-time.Duration(float64(ac.Time) * float64(time.Second)))
+	ac.mu.Unlock()
+	an.Start(&gi.Anim{Recv: svg.This(), Name: animName, Dur: gi.AnimForever, Func: func(p float32) {
+		svg.animStep()
+	}})
+}
+
+// AnimStop stops running the animation clock, keeping its current time
+func (svg *SVG) AnimStop() {
+	ac := &svg.Clock
+	ac.mu.Lock()
+	if !ac.Running {
+		ac.mu.Unlock()
+		return
+	}
+	ac.Running = false
+	an := ac.anims
+	ac.anims = nil
+	ac.mu.Unlock()
+	an.Stop(svg.This(), animName)
+}
+
+// AnimPause stops running the animation clock, and prevents it from being
+// started automatically upon rendering
+func (svg *SVG) AnimPause() {
+	svg.AnimStop()
+	svg.Clock.mu.Lock()
+	svg.Clock.Paused = true
+	svg.Clock.mu.Unlock()
+}
+
+// AnimSeek pauses the animation clock and sets it to given time, in seconds,
+// applying the animations at that time, and re-rendering if the svg is in a
+// window
+func (svg *SVG) AnimSeek(t float32) {
+	svg.AnimPause()
+	svg.Clock.mu.Lock()
+	svg.Clock.Time = t
+	svg.Clock.mu.Unlock()
+	svg.ApplyAnims()
+	if svg.ParentWindow() != nil {
+		svg.UpdateSig()
+	}
+}
+
+// AnimReset stops the animation clock and sets it back to time 0, so it
+// starts again upon next rendering
+func (svg *SVG) AnimReset() {
+	svg.AnimStop()
+	svg.Clock.mu.Lock()
+	svg.Clock.Time = 0
+	svg.Clock.started = false
+	svg.Clock.Paused = false
+	svg.Clock.mu.Unlock()
+}
+
+// AnimFrame applies the animations for the current frame, starting the
+// clock if this is the first frame -- called at the start of Render2D
+func (svg *SVG) AnimFrame() {
+	if !svg.ApplyAnims() {
+		return
+	}
+	ac := &svg.Clock
+	ac.mu.Lock()
+	start := !ac.started && !ac.Paused
+	ac.mu.Unlock()
+	if start && svg.ParentWindow() != nil && !svg.AnimsDone(0) {
+		svg.AnimStart()
+	}
+}
+
+// animStep updates the time of the running animation clock from its
+// animator, signaling the svg to re-render, and stops the clock when all of
+// the animations are done -- called by the animator on the window event loop
+func (svg *SVG) animStep() {
+	ac := &svg.Clock
+	ac.mu.Lock()
+	if !ac.Running {
+		ac.mu.Unlock()
+		return
+	}
+	ac.Time = float32(ac.anims.Now().Sub(ac.start).Seconds())
+	t := ac.Time
+	ac.mu.Unlock()
+	if svg.ParentWindow() != nil {
+		svg.UpdateSig()
+	}
+	if svg.AnimsDone(t) {
+		svg.AnimStop()
+	}
+}
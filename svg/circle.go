@@ -24,6 +24,8 @@ func (g *Circle) Render2D() {
 	}
 	pc := &g.Pnt
 	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
 	rs.Lock()
 	rs.PushXForm(pc.XForm)
 	pc.DrawCircle(rs, g.Pos.X, g.Pos.Y, g.Radius)
@@ -34,4 +36,11 @@ func (g *Circle) Render2D() {
 	g.Render2DChildren()
 
 	rs.PopXFormLock()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
+}
+
+// LocalBBox returns the bounding box of the circle in its user space
+func (g *Circle) LocalBBox() (min, max gi.Vec2D) {
+	return g.Pos.SubVal(g.Radius), g.Pos.AddVal(g.Radius)
 }
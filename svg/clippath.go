@@ -5,14 +5,138 @@
 package svg
 
 import (
+	"image"
+	"image/color"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
 	"github.com/goki/ki/kit"
 )
 
-// todo: needs to be impl
-
-// ClipPath is used for holding a path that renders as a clip path
+// ClipPath is used for holding a path that renders as a clip path -- its
+// children are not rendered directly, but only as a mask for the elements
+// that refer to it via a clip-path: url(#id) property
 type ClipPath struct {
 	NodeBase
+	Units ClipPathUnits `xml:"clipPathUnits" desc:"coordinate system for the contents of the clip path"`
 }
 
 var KiT_ClipPath = kit.Types.AddType(&ClipPath{}, nil)
+
+// ClipPathUnits specifies the coordinate system for the contents of a clip path
+type ClipPathUnits int32
+
+const (
+	// ClipUserSpaceOnUse means that the contents are in the user space of the
+	// element referring to the clip path
+	ClipUserSpaceOnUse ClipPathUnits = iota
+
+	// ClipObjectBoundingBox means that the contents are in units of the
+	// bounding box of the element referring to the clip path, i.e., 0..1
+	ClipObjectBoundingBox
+
+	ClipPathUnitsN
+)
+
+//go:generate stringer -type=ClipPathUnits
+
+var KiT_ClipPathUnits = kit.Enums.AddEnumAltLower(ClipPathUnitsN, false, gi.StylePropProps, "Clip")
+
+func (ev ClipPathUnits) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *ClipPathUnits) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// Render2D does nothing -- clip paths are only rendered as masks, by
+// RenderMask
+func (g *ClipPath) Render2D() {
+}
+
+// clipPaint saves the paint settings of an element of a clip path that are
+// overridden while rendering it into a mask
+type clipPaint struct {
+	pc      *gi.Paint
+	off     bool
+	fill    gi.FillStyle
+	stroke  gi.StrokeStyle
+	opacity float32
+}
+
+// RenderMask renders the elements of the clip path into an alpha mask the
+// size of the render image, with the current transform of the RenderState
+// being the user space of the element referring to the clip path -- min, max
+// are the bounding box of that element in its user space, used for
+// ClipObjectBoundingBox units.  The elements are rendered as solid opaque
+// fills, using their clip-rule, per the SVG standard.  Must be called
+// outside of the render lock.
+func (g *ClipPath) RenderMask(rs *gi.RenderState, min, max gi.Vec2D) *image.Alpha {
+	rs.Lock()
+	rs.StartMask()
+	rs.PushXForm(g.Pnt.XForm)
+	if g.Units == ClipObjectBoundingBox {
+		sz := max.Sub(min)
+		rs.PushXForm(gi.Identity2D().Translate(min.X, min.Y).Scale(sz.X, sz.Y))
+	}
+	rs.Unlock()
+
+	saved := g.setClipPaint()
+	g.Render2DChildren()
+	for _, cp := range saved {
+		cp.pc.Off = cp.off
+		cp.pc.FillStyle = cp.fill
+		cp.pc.StrokeStyle = cp.stroke
+		cp.pc.FontStyle.Opacity = cp.opacity
+	}
+
+	rs.Lock()
+	if g.Units == ClipObjectBoundingBox {
+		rs.PopXForm()
+	}
+	rs.PopXForm()
+	mask := rs.EndMask()
+	rs.Unlock()
+	return mask
+}
+
+// setClipPaint sets the paint of all the elements of the clip path to
+// render as an opaque fill, returning the prior settings
+func (g *ClipPath) setClipPaint() []clipPaint {
+	var saved []clipPaint
+	g.FuncDownMeFirst(0, g.This(), func(k ki.Ki, level int, d interface{}) bool {
+		if k == g.This() {
+			return true
+		}
+		pntr, ok := k.(gi.Painter)
+		if !ok {
+			return true
+		}
+		pc := pntr.Paint()
+		saved = append(saved, clipPaint{pc: pc, off: pc.Off, fill: pc.FillStyle,
+			stroke: pc.StrokeStyle, opacity: pc.FontStyle.Opacity})
+		pc.Off = false
+		pc.FillStyle.SetColor(color.Black)
+		pc.FillStyle.On = true
+		pc.FillStyle.Opacity = 1
+		pc.FillStyle.Rule = g.clipRule(k)
+		pc.StrokeStyle.On = false
+		pc.FontStyle.Opacity = 1
+		return true
+	})
+	return saved
+}
+
+// clipRule returns the fill rule for given element of the clip path, from
+// the clip-rule property on it or any of its parents up to the clip path
+func (g *ClipPath) clipRule(k ki.Ki) gi.FillRule {
+	for k != nil {
+		if cr, ok := k.Prop("clip-rule"); ok {
+			if kit.ToString(cr) == "evenodd" {
+				return gi.FillRuleEvenOdd
+			}
+			return gi.FillRuleNonZero
+		}
+		if k == g.This() {
+			break
+		}
+		k = k.Parent()
+	}
+	return gi.FillRuleNonZero
+}
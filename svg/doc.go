@@ -8,14 +8,21 @@ Package svg provides SVG rendering classes, I/O parsing: full SVG rendering
 SVG currently supports most of SVG, but not:
 
 	* Flow
-	* Filter Effects
+	* Filter primitives other than feGaussianBlur, feOffset, feFlood,
+	  feMerge, feColorMatrix, feComposite and feBlend, or primitive subregions
 	* 3D Perspective transforms
 
-See gi/examples/svg for a basic SVG viewer app, using the svg.Editor, which
-will ultimately be expanded to support more advanced editing.  Also in that
-directory are a number of test files that stress different aspects of
+See gi/examples/svg for a basic SVG viewer app, using the svg.Editor.  Also
+in that directory are a number of test files that stress different aspects of
 rendering.
 
+The Editor also supports editing, in its EditSelect and EditNodes modes:
+elements are selected by clicking or with a rubber band, and moved, scaled
+and rotated with the handles around the selection, which sets their
+transforms (snapped to the SnapGrid if set), and the points of selected
+Paths can be dragged in EditNodes mode.  Edits are recorded for undo / redo,
+and the result is saved with SaveXML.
+
 svg.NodeBase is the base type for all SVG elements -- unlike Widget nodes, SVG
 nodes do not use layout logic, and just draw directly into a parent SVG
 viewport, with cumulative transforms determining drawing position, etc.  The
@@ -27,5 +34,42 @@ interface for drawing.
 The Path element uses a compiled bytecode version of the Data path for
 increased speed.
 
+SVG drawings are read with OpenXML / ReadXML, and written back out with
+SaveXML / WriteXML, which preserve element ids, classes, transforms and
+any other attributes (stored as properties on the nodes).
+
+Elements with a clip-path: url(#id) property are clipped by the referenced
+ClipPath, which is rendered into an alpha mask (see gi.RenderState
+StartMask / EndMask) in either userSpaceOnUse or objectBoundingBox units --
+note that text glyphs are not yet clipped, only filled and stroked shapes.
+
+Elements with a filter: url(#id) property are rendered into an offscreen
+image (see gi.RenderState StartOffscreen / EndOffscreen), which is then
+processed by the primitives of the referenced Filter, within the filter
+region, and the result drawn into the SVG.
+
+Use elements render the element (or Symbol) they refer to by href, which is
+looked up again on each render, so changes to the referenced element are
+always reflected.  Symbols are only rendered through Use elements, mapping
+their ViewBox into the Use width and height.  Image elements render a raster
+image from an embedded data: URI or an external file, relative to the SVG
+file.
+
+Text elements lay out all of the tspans and textPaths within them as a
+single flow of glyphs, honoring per-character x, y, dx, dy and rotate lists,
+text-anchor for each chunk of text starting at an absolute position, and
+textLength / lengthAdjust.  TextPath lays out its glyphs along the
+referenced Path, using a flattened version of it (PathFlat).
+
+The animate, set, animateTransform and animateMotion elements animate their
+parent element (or the one referred to by href), and are applied at the
+current time of the SVG Clock at the start of each render (see ApplyAnims).
+The clock starts running upon first rendering an SVG with animations,
+stepped by the window Animator (gi.Animator) on the event loop, and
+re-rendering just that SVG -- it can be paused, restarted and set to any
+time with AnimPause, AnimStart and AnimSeek.
+Only clock values are supported for begin (not events or syncbases), and
+the spline calcMode is the same as linear.
+
 */
 package svg
@@ -6,32 +6,681 @@ package svg
 
 import (
 	"fmt"
+	"image"
+	"image/color"
 
+	"github.com/chewxy/math32"
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/giv"
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/cursor"
+	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
 	"github.com/goki/ki"
 	"github.com/goki/ki/kit"
 )
 
-// Editor supports editing of SVG elements
+// Editor supports viewing and editing of SVG elements -- in all modes,
+// scrolling zooms the view, and right-clicking on an element opens a view
+// of it.  In EditView mode, dragging pans the view.  In EditSelect mode,
+// elements are selected by clicking on them (Shift toggles elements in and
+// out of the selection, and Control selects elements within groups) or by
+// dragging a rubber band around them, and the selection is moved by
+// dragging it, and scaled or rotated by dragging its handles (Shift keeps the
+// aspect ratio, or snaps the angle), which sets the transform of each
+// element.  EditNodes mode also allows the points of selected Paths to be
+// dragged.  Moves snap to SnapGrid if set, all edits can be undone and
+// redone, and the drawing is saved to Filename with the save key.
 type Editor struct {
 	SVG
-	Trans         gi.Vec2D `desc:"view translation offset (from dragging)"`
-	Scale         float32  `desc:"view scaling (from zooming)"`
-	SetDragCursor bool     `desc:"has dragging cursor been set yet?"`
+	Trans         gi.Vec2D     `desc:"view translation offset (from dragging)"`
+	Scale         float32      `desc:"view scaling (from zooming)"`
+	SetDragCursor bool         `desc:"has dragging cursor been set yet?"`
+	Mode          EditorModes  `desc:"editing mode: view only, select and transform elements, or also edit the points of paths"`
+	SnapGrid      float32      `desc:"if > 0, moving and scaling elements, and moving the points of paths, snaps to a grid of this spacing, in the user coordinates of the drawing"`
+	Selected      []gi.Node2D  `json:"-" xml:"-" view:"-" desc:"currently selected elements"`
+	Undos         []EditorEdit `json:"-" xml:"-" view:"-" desc:"edits that can be undone, most recent last"`
+	Redos         []EditorEdit `json:"-" xml:"-" view:"-" desc:"undone edits that can be redone, most recent last"`
+	drag          editorDrag
 }
 
 var KiT_Editor = kit.Types.AddType(&Editor{}, nil)
 
+// EditorModes are the editing modes of the Editor
+type EditorModes int32
+
+const (
+	// EditView only supports viewing: dragging pans and scrolling zooms
+	EditView EditorModes = iota
+
+	// EditSelect supports selecting elements and moving, scaling and
+	// rotating them
+	EditSelect
+
+	// EditNodes supports everything in EditSelect, and also moving the
+	// points of selected paths
+	EditNodes
+
+	EditorModesN
+)
+
+//go:generate stringer -type=EditorModes
+
+var KiT_EditorModes = kit.Enums.AddEnumAltLower(EditorModesN, false, nil, "Edit")
+
+func (ev EditorModes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *EditorModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// EditorHandleSize is the size of the selection handles and path points, in
+// pixels
+var EditorHandleSize = 8
+
+// EditorRotateOffset is the distance of the rotation handle above the
+// selection, in pixels
+var EditorRotateOffset = 24
+
+// EditorSelectColor is the color used for drawing the selection
+var EditorSelectColor = color.RGBA{0, 120, 215, 255}
+
+// EditorRotateHandle is the index of the rotation handle, as returned by
+// HandleAt -- the scale handles are 0..7, clockwise from the top-left corner
+const EditorRotateHandle = 8
+
+// editorHandleDirs are the directions of the scale handles from the center
+// of the selection, clockwise from the top-left corner
+var editorHandleDirs = [8]image.Point{{-1, -1}, {0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}}
+
+// editorDragTypes are the kinds of dragging in the Editor
+type editorDragTypes int
+
+const (
+	editorDragNone editorDragTypes = iota
+	editorDragMove
+	editorDragScale
+	editorDragRotate
+	editorDragRubber
+	editorDragNode
+)
+
+// editorDrag is the state of the current drag in the Editor -- all points
+// are in the pixel coordinates of the drawing
+type editorDrag struct {
+	typ    editorDragTypes
+	handle int
+	start  image.Point
+	cur    image.Point
+	bbox   image.Rectangle
+	xforms []gi.Matrix2D
+	path   *Path
+	ptIdx  int
+	ptPos  gi.Vec2D
+	data   []PathData
+	edit   EditorEdit
+	moved  bool
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+//  Undo
+
+// EditorState is the editable state of an element, which is saved prior to
+// an edit so that it can be undone
+type EditorState struct {
+	Node  gi.Node2D   `desc:"the element"`
+	XForm interface{} `desc:"transform property of the element -- nil if not set"`
+	Data  []PathData  `desc:"path data, for Path elements"`
+}
+
+// NewEditorState returns the current state of given element
+func NewEditorState(gii gi.Node2D) EditorState {
+	st := EditorState{Node: gii}
+	st.XForm, _ = gii.Prop("transform")
+	if p, ok := gii.(*Path); ok {
+		st.Data = make([]PathData, len(p.Data))
+		copy(st.Data, p.Data)
+	}
+	return st
+}
+
+// Restore restores the element to this state
+func (st *EditorState) Restore() {
+	if st.XForm == nil {
+		st.Node.DeleteProp("transform")
+	} else {
+		st.Node.SetProp("transform", st.XForm)
+	}
+	if p, ok := st.Node.(*Path); ok && st.Data != nil {
+		p.Data = make([]PathData, len(st.Data))
+		copy(p.Data, st.Data)
+		p.DataStr = PathDataString(p.Data)
+	}
+}
+
+// EditorEdit is the state of the elements changed by an edit, prior to it
+type EditorEdit []EditorState
+
+// NewEditorEdit returns the current state of given elements
+func NewEditorEdit(nodes []gi.Node2D) EditorEdit {
+	ed := make(EditorEdit, len(nodes))
+	for i, n := range nodes {
+		ed[i] = NewEditorState(n)
+	}
+	return ed
+}
+
+// Restore restores the elements to their state prior to the edit, returning
+// their current state, which undoes the restore
+func (ed EditorEdit) Restore() EditorEdit {
+	cur := make(EditorEdit, len(ed))
+	for i := range ed {
+		cur[i] = NewEditorState(ed[i].Node)
+		ed[i].Restore()
+	}
+	return cur
+}
+
+// SaveEdit records given state of elements prior to an edit on the undo
+// stack -- any undone edits can no longer be redone
+func (svg *Editor) SaveEdit(ed EditorEdit) {
+	svg.Undos = append(svg.Undos, ed)
+	svg.Redos = nil
+}
+
+// Undo undoes the last edit, returning false if there is nothing to undo
+func (svg *Editor) Undo() bool {
+	n := len(svg.Undos)
+	if n == 0 {
+		return false
+	}
+	ed := svg.Undos[n-1]
+	svg.Undos = svg.Undos[:n-1]
+	svg.Redos = append(svg.Redos, ed.Restore())
+	svg.EditUpdate()
+	return true
+}
+
+// Redo redoes the last undone edit, returning false if there is nothing to
+// redo
+func (svg *Editor) Redo() bool {
+	n := len(svg.Redos)
+	if n == 0 {
+		return false
+	}
+	ed := svg.Redos[n-1]
+	svg.Redos = svg.Redos[:n-1]
+	svg.Undos = append(svg.Undos, ed.Restore())
+	svg.EditUpdate()
+	return true
+}
+
+// EditUpdate re-renders the drawing after an edit or a change in selection
+func (svg *Editor) EditUpdate() {
+	svg.SetFullReRender()
+	svg.UpdateSig()
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+//  Selection
+
+// IsSelected returns true if given element is selected
+func (svg *Editor) IsSelected(gii gi.Node2D) bool {
+	for _, s := range svg.Selected {
+		if s == gii {
+			return true
+		}
+	}
+	return false
+}
+
+// Select adds given element to the selection
+func (svg *Editor) Select(gii gi.Node2D) {
+	if !svg.IsSelected(gii) {
+		svg.Selected = append(svg.Selected, gii)
+	}
+}
+
+// Unselect removes given element from the selection
+func (svg *Editor) Unselect(gii gi.Node2D) {
+	for i, s := range svg.Selected {
+		if s == gii {
+			svg.Selected = append(svg.Selected[:i], svg.Selected[i+1:]...)
+			return
+		}
+	}
+}
+
+// ClearSelection unselects all elements
+func (svg *Editor) ClearSelection() {
+	svg.Selected = nil
+}
+
+// SelectAll selects all of the top-level elements of the drawing
+func (svg *Editor) SelectAll() {
+	svg.Selected = nil
+	for _, k := range svg.Kids {
+		if gii, ok := k.(gi.Node2D); ok && svg.IsSelectable(gii) {
+			svg.Selected = append(svg.Selected, gii)
+		}
+	}
+}
+
+// IsSelectable returns true if given element can be selected: it is a
+// rendered element, with a non-empty bounding box
+func (svg *Editor) IsSelectable(gii gi.Node2D) bool {
+	if gii.IsDeleted() || gii.Parent() == nil {
+		return false
+	}
+	return !gii.AsNode2D().BBox.Empty()
+}
+
+// pruneSelection removes any elements that have been deleted from the
+// selection
+func (svg *Editor) pruneSelection() {
+	for i := len(svg.Selected) - 1; i >= 0; i-- {
+		if gii := svg.Selected[i]; gii.IsDeleted() || gii.Parent() == nil {
+			svg.Selected = append(svg.Selected[:i], svg.Selected[i+1:]...)
+		}
+	}
+}
+
+// ElementAt returns the first leaf element whose bounding box contains
+// given point, in window coordinates -- elements with only animations as
+// children count as leaves -- nil if none
+func (svg *Editor) ElementAt(pt image.Point) gi.Node2D {
+	var rval gi.Node2D
+	svg.FuncDownMeFirst(0, svg.This(), func(k ki.Ki, level int, d interface{}) bool {
+		if k == svg.This() {
+			return true
+		}
+		if _, ok := k.(Animator); ok {
+			return false
+		}
+		for _, kid := range *k.Children() {
+			if _, ok := kid.(Animator); !ok {
+				return true
+			}
+		}
+		gii, ni := gi.KiToNode2D(k)
+		if ni == nil {
+			return false
+		}
+		if pt.In(ni.WinBBox) {
+			rval = gii
+			return false
+		}
+		return true
+	})
+	return rval
+}
+
+// SelectableAt returns the element to select at given point in window
+// coordinates, or nil if none -- this is the top-level element of the drawing
+// containing the point, unless inGroups, in which case it is the element
+// within any groups (but tspans select their text element)
+func (svg *Editor) SelectableAt(pt image.Point, inGroups bool) gi.Node2D {
+	gii := svg.ElementAt(pt)
+	if gii == nil {
+		return nil
+	}
+	for gii.Parent() != nil && gii.Parent() != svg.This() {
+		if inGroups {
+			txt, ok := gii.(*Text)
+			if !ok || !txt.IsParText() {
+				break
+			}
+		}
+		par, ok := gii.Parent().(gi.Node2D)
+		if !ok {
+			break
+		}
+		gii = par
+	}
+	if !svg.IsSelectable(gii) {
+		return nil
+	}
+	return gii
+}
+
+// SelectionBBox returns the bounding box of the selection, in the pixel
+// coordinates of the drawing
+func (svg *Editor) SelectionBBox() image.Rectangle {
+	var bb image.Rectangle
+	for i, s := range svg.Selected {
+		sbb := s.AsNode2D().BBox
+		if i == 0 {
+			bb = sbb
+		} else {
+			bb = bb.Union(sbb)
+		}
+	}
+	return bb
+}
+
+// HandlePos returns the position of given handle of the selection, with
+// given bounding box, in the pixel coordinates of the drawing
+func HandlePos(bb image.Rectangle, handle int) gi.Vec2D {
+	c := gi.NewVec2DFmPoint(bb.Min.Add(bb.Max)).MulVal(0.5)
+	if handle == EditorRotateHandle {
+		return gi.Vec2D{c.X, float32(bb.Min.Y - EditorRotateOffset)}
+	}
+	hs := gi.NewVec2DFmPoint(bb.Size()).MulVal(0.5)
+	return c.Add(gi.NewVec2DFmPoint(editorHandleDirs[handle]).Mul(hs))
+}
+
+// HandleAt returns the selection handle at given point in the pixel
+// coordinates of the drawing, or -1 if none
+func (svg *Editor) HandleAt(pt image.Point) int {
+	if len(svg.Selected) == 0 {
+		return -1
+	}
+	bb := svg.SelectionBBox()
+	p := gi.NewVec2DFmPoint(pt)
+	hs := float32(EditorHandleSize)
+	for h := 0; h <= EditorRotateHandle; h++ {
+		d := p.Sub(HandlePos(bb, h)).Abs()
+		if d.X <= hs && d.Y <= hs {
+			return h
+		}
+	}
+	return -1
+}
+
+// PathPointAt returns the selected path, and the index of its point in the
+// path data, at given point in the pixel coordinates of the drawing, or nil
+// if none
+func (svg *Editor) PathPointAt(pt image.Point) (*Path, int, gi.Vec2D) {
+	p := gi.NewVec2DFmPoint(pt)
+	hs := float32(EditorHandleSize)
+	for _, s := range svg.Selected {
+		path, ok := s.(*Path)
+		if !ok {
+			continue
+		}
+		xf := path.Pnt.XForm.Multiply(svg.ParXForm(path))
+		fidx := -1
+		var fpt gi.Vec2D
+		PathDataIterFunc(path.Data, func(idx int, cmd PathCmds, ptIdx int, cx, cy float32) bool {
+			vp := xf.TransformPointVec2D(gi.Vec2D{cx, cy})
+			d := p.Sub(vp).Abs()
+			if d.X <= hs && d.Y <= hs {
+				fidx = idx
+				fpt = vp
+				return false
+			}
+			return true
+		})
+		if fidx >= 0 {
+			return path, fidx, fpt
+		}
+	}
+	return nil, -1, gi.Vec2D{}
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+//  Transforms
+
+// ParXForm returns the transform from the user coordinates of the parent of
+// given element to the pixel coordinates of the drawing
+func (svg *Editor) ParXForm(gii gi.Node2D) gi.Matrix2D {
+	xf := gi.Identity2D()
+	for p := gii.Parent(); p != nil && p != svg.This(); p = p.Parent() {
+		if pn, ok := p.(gi.Painter); ok {
+			xf = xf.Multiply(pn.Paint().XForm)
+		}
+	}
+	return xf.Multiply(svg.Pnt.XForm)
+}
+
+// SetElXForm sets the transform of given element, including its transform
+// property, which is written out when saving -- an identity transform
+// deletes the property
+func SetElXForm(gii gi.Node2D, xf gi.Matrix2D) {
+	if pn, ok := gii.(gi.Painter); ok {
+		pn.Paint().XForm = xf
+	}
+	if xf == gi.Identity2D() {
+		gii.DeleteProp("transform")
+		return
+	}
+	gii.SetProp("transform", fmt.Sprintf("matrix(%v,%v,%v,%v,%v,%v)", gi.FmtFloat32(xf.XX), gi.FmtFloat32(xf.YX), gi.FmtFloat32(xf.XY), gi.FmtFloat32(xf.YY), gi.FmtFloat32(xf.X0), gi.FmtFloat32(xf.Y0)))
+}
+
+// XFormSelected applies given transform, in the pixel coordinates of the
+// drawing, to the selected elements, on top of their given original
+// transforms
+func (svg *Editor) XFormSelected(xf gi.Matrix2D, orig []gi.Matrix2D) {
+	for i, s := range svg.Selected {
+		if i >= len(orig) {
+			break
+		}
+		pxf := svg.ParXForm(s)
+		SetElXForm(s, orig[i].Multiply(pxf).Multiply(xf).Multiply(pxf.Inverse()))
+	}
+}
+
+// SnapPoint snaps given point in the pixel coordinates of the drawing to
+// SnapGrid in the user coordinates of the drawing, if SnapGrid is set
+func (svg *Editor) SnapPoint(pt gi.Vec2D) gi.Vec2D {
+	if svg.SnapGrid <= 0 {
+		return pt
+	}
+	up := svg.Pnt.XForm.Inverse().TransformPointVec2D(pt)
+	up.X = math32.Floor(up.X/svg.SnapGrid+0.5) * svg.SnapGrid
+	up.Y = math32.Floor(up.Y/svg.SnapGrid+0.5) * svg.SnapGrid
+	return svg.Pnt.XForm.TransformPointVec2D(up)
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+//  Dragging
+
+// DragStart starts dragging at given point in window coordinates, in
+// EditSelect or EditNodes mode: dragging a handle scales or rotates the
+// selection, dragging a selected path point moves it (EditNodes), dragging
+// an element selects and moves it, and dragging empty space selects the
+// elements within a rubber band -- toggle adds to or removes from the
+// selection, and inGroups selects elements within groups
+func (svg *Editor) DragStart(pt image.Point, toggle, inGroups bool) {
+	svg.pruneSelection()
+	dr := &svg.drag
+	*dr = editorDrag{}
+	dr.start = pt.Sub(svg.WinBBox.Min)
+	dr.cur = dr.start
+	if svg.Mode == EditNodes {
+		if path, idx, ppt := svg.PathPointAt(dr.start); path != nil {
+			dr.typ = editorDragNode
+			dr.path = path
+			dr.ptIdx = idx
+			dr.ptPos = ppt
+			dr.data = make([]PathData, len(path.Data))
+			copy(dr.data, path.Data)
+			dr.edit = NewEditorEdit([]gi.Node2D{path})
+			return
+		}
+	}
+	if h := svg.HandleAt(dr.start); h >= 0 {
+		dr.handle = h
+		if h == EditorRotateHandle {
+			dr.typ = editorDragRotate
+		} else {
+			dr.typ = editorDragScale
+		}
+	} else {
+		gii := svg.SelectableAt(pt, inGroups)
+		switch {
+		case gii == nil:
+			if !toggle {
+				svg.ClearSelection()
+			}
+			dr.typ = editorDragRubber
+		case toggle && svg.IsSelected(gii):
+			svg.Unselect(gii)
+		case toggle:
+			svg.Select(gii)
+			dr.typ = editorDragMove
+		default:
+			if !svg.IsSelected(gii) {
+				svg.Selected = []gi.Node2D{gii}
+			}
+			dr.typ = editorDragMove
+		}
+	}
+	if dr.typ != editorDragRubber && dr.typ != editorDragNone {
+		dr.bbox = svg.SelectionBBox()
+		dr.xforms = make([]gi.Matrix2D, len(svg.Selected))
+		for i, s := range svg.Selected {
+			dr.xforms[i] = gi.Identity2D()
+			if pn, ok := s.(gi.Painter); ok {
+				dr.xforms[i] = pn.Paint().XForm
+			}
+		}
+		dr.edit = NewEditorEdit(svg.Selected)
+	}
+	svg.EditUpdate()
+}
+
+// DragMove continues the current drag to given point in window coordinates
+// -- constrain keeps the aspect ratio when scaling, and snaps the angle to
+// multiples of 15 degrees when rotating
+func (svg *Editor) DragMove(pt image.Point, constrain bool) {
+	dr := &svg.drag
+	if dr.typ == editorDragNone {
+		return
+	}
+	dr.cur = pt.Sub(svg.WinBBox.Min)
+	del := gi.NewVec2DFmPoint(dr.cur.Sub(dr.start))
+	switch dr.typ {
+	case editorDragRubber:
+		svg.EditUpdate()
+		return
+	case editorDragMove:
+		bbmin := gi.NewVec2DFmPoint(dr.bbox.Min)
+		del = svg.SnapPoint(bbmin.Add(del)).Sub(bbmin)
+		svg.XFormSelected(gi.Translate2D(del.X, del.Y), dr.xforms)
+	case editorDragScale:
+		dir := editorHandleDirs[dr.handle]
+		hp := HandlePos(dr.bbox, dr.handle)
+		anc := HandlePos(dr.bbox, (dr.handle+4)%8)
+		np := svg.SnapPoint(hp.Add(del))
+		sx, sy := float32(1), float32(1)
+		if dir.X != 0 && hp.X != anc.X {
+			sx = (np.X - anc.X) / (hp.X - anc.X)
+		}
+		if dir.Y != 0 && hp.Y != anc.Y {
+			sy = (np.Y - anc.Y) / (hp.Y - anc.Y)
+		}
+		if constrain {
+			switch {
+			case dir.X == 0:
+				sx = sy
+			case dir.Y == 0:
+				sy = sx
+			case math32.Abs(sx) > math32.Abs(sy):
+				sy = sx
+			default:
+				sx = sy
+			}
+		}
+		if sx == 0 || sy == 0 {
+			return
+		}
+		xf := gi.Translate2D(-anc.X, -anc.Y).Multiply(gi.Scale2D(sx, sy)).Multiply(gi.Translate2D(anc.X, anc.Y))
+		svg.XFormSelected(xf, dr.xforms)
+	case editorDragRotate:
+		c := gi.NewVec2DFmPoint(dr.bbox.Min.Add(dr.bbox.Max)).MulVal(0.5)
+		st := gi.NewVec2DFmPoint(dr.start).Sub(c)
+		cu := gi.NewVec2DFmPoint(dr.cur).Sub(c)
+		ang := math32.Atan2(cu.Y, cu.X) - math32.Atan2(st.Y, st.X)
+		if constrain {
+			snap := math32.Pi / 12
+			ang = math32.Floor(ang/snap+0.5) * snap
+		}
+		xf := gi.Translate2D(-c.X, -c.Y).Multiply(gi.Rotate2D(ang)).Multiply(gi.Translate2D(c.X, c.Y))
+		svg.XFormSelected(xf, dr.xforms)
+	case editorDragNode:
+		p := dr.path
+		xf := p.Pnt.XForm.Multiply(svg.ParXForm(p)).Inverse()
+		np := svg.SnapPoint(dr.ptPos.Add(del))
+		ld := xf.TransformPointVec2D(np).Sub(xf.TransformPointVec2D(dr.ptPos))
+		copy(p.Data, dr.data)
+		PathDataMovePoint(p.Data, dr.ptIdx, ld.X, ld.Y)
+		p.DataStr = PathDataString(p.Data)
+	}
+	dr.moved = true
+	svg.EditUpdate()
+}
+
+// DragEnd finishes the current drag, selecting the elements within the
+// rubber band, or saving the edit for undo
+func (svg *Editor) DragEnd() {
+	dr := &svg.drag
+	switch {
+	case dr.typ == editorDragRubber:
+		rb := image.Rect(dr.start.X, dr.start.Y, dr.cur.X, dr.cur.Y)
+		if rb.Dx() > 0 || rb.Dy() > 0 {
+			for _, k := range svg.Kids {
+				gii, ok := k.(gi.Node2D)
+				if !ok || !svg.IsSelectable(gii) {
+					continue
+				}
+				if gii.AsNode2D().BBox.In(rb) {
+					svg.Select(gii)
+				}
+			}
+		}
+	case dr.moved && dr.edit != nil:
+		svg.SaveEdit(dr.edit)
+	}
+	*dr = editorDrag{}
+	svg.EditUpdate()
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+//  Events
+
+// KeyInput handles keyboard input for editing: undo, redo, select all,
+// cancel selection and save
+func (svg *Editor) KeyInput(kt *key.ChordEvent) {
+	kf := gi.KeyFun(kt.Chord())
+	switch kf {
+	case gi.KeyFunUndo:
+		kt.SetProcessed()
+		svg.Undo()
+	case gi.KeyFunRedo:
+		kt.SetProcessed()
+		svg.Redo()
+	case gi.KeyFunSelectAll:
+		if svg.Mode == EditView {
+			return
+		}
+		kt.SetProcessed()
+		svg.SelectAll()
+		svg.EditUpdate()
+	case gi.KeyFunCancelSelect, gi.KeyFunAbort:
+		kt.SetProcessed()
+		svg.ClearSelection()
+		svg.EditUpdate()
+	case gi.KeyFunMenuSave:
+		if svg.Filename == "" {
+			return
+		}
+		kt.SetProcessed()
+		svg.SaveXML(svg.Filename)
+	}
+}
+
 // EditorEvents handles svg editing events
 func (svg *Editor) EditorEvents() {
 	svg.ConnectEvent(oswin.MouseDragEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
 		me := d.(*mouse.DragEvent)
 		me.SetProcessed()
 		ssvg := recv.Embed(KiT_Editor).(*Editor)
+		if ssvg.drag.typ != editorDragNone {
+			ssvg.DragMove(me.Where, me.HasAnyModifier(key.Shift))
+			return
+		}
+		if ssvg.Mode != EditView {
+			return
+		}
 		if ssvg.IsDragging() {
 			if !ssvg.SetDragCursor {
 				oswin.TheApp.Cursor(ssvg.Viewport.Win.OSWin).Push(cursor.HandOpen)
@@ -75,7 +724,19 @@ func (svg *Editor) EditorEvents() {
 			oswin.TheApp.Cursor(ssvg.Viewport.Win.OSWin).Pop()
 			ssvg.SetDragCursor = false
 		}
-		obj := ssvg.FirstContainingPoint(me.Where, true)
+		if me.Button == mouse.Left && ssvg.Mode != EditView {
+			switch me.Action {
+			case mouse.Press:
+				me.SetProcessed()
+				ssvg.GrabFocus()
+				ssvg.DragStart(me.Where, me.HasAnyModifier(key.Shift), me.HasAnyModifier(key.Control, key.Meta))
+			case mouse.Release:
+				me.SetProcessed()
+				ssvg.DragEnd()
+			}
+			return
+		}
+		obj := ssvg.ElementAt(me.Where)
 		if me.Action == mouse.Release && me.Button == mouse.Right {
 			me.SetProcessed()
 			if obj != nil {
@@ -87,13 +748,18 @@ func (svg *Editor) EditorEvents() {
 		me := d.(*mouse.HoverEvent)
 		me.SetProcessed()
 		ssvg := recv.Embed(KiT_Editor).(*Editor)
-		obj := ssvg.FirstContainingPoint(me.Where, true)
+		obj := ssvg.ElementAt(me.Where)
 		if obj != nil {
 			pos := me.Where
 			ttxt := fmt.Sprintf("element name: %v -- use right mouse click to edit", obj.Name())
 			gi.PopupTooltip(obj.Name(), pos.X, pos.Y, svg.Viewport, ttxt)
 		}
 	})
+	svg.ConnectEvent(oswin.KeyChordEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		ssvg := recv.Embed(KiT_Editor).(*Editor)
+		kt := d.(*key.ChordEvent)
+		ssvg.KeyInput(kt)
+	})
 }
 
 // InitScale ensures that Scale is initialized and non-zero
@@ -113,8 +779,14 @@ func (svg *Editor) SetTransform() {
 	svg.SetProp("transform", fmt.Sprintf("translate(%v,%v) scale(%v,%v)", svg.Trans.X, svg.Trans.Y, svg.Scale, svg.Scale))
 }
 
+func (svg *Editor) Init2D() {
+	svg.SVG.Init2D()
+	svg.SetFlag(int(gi.CanFocus))
+}
+
 func (svg *Editor) Render2D() {
 	if svg.PushBounds() {
+		svg.AnimFrame()
 		rs := &svg.Render
 		svg.EditorEvents()
 		if svg.Fill {
@@ -122,8 +794,71 @@ func (svg *Editor) Render2D() {
 		}
 		rs.PushXForm(svg.Pnt.XForm)
 		svg.Render2DChildren() // we must do children first, then us!
-		svg.PopBounds()
 		rs.PopXForm()
+		svg.RenderSelection()
+		svg.PopBounds()
 		svg.RenderViewport2D() // update our parent image
 	}
 }
+
+// RenderSelection draws the selection bounding box and handles, the rubber
+// band, and the points of selected paths in EditNodes mode, on top of the
+// drawing
+func (svg *Editor) RenderSelection() {
+	svg.pruneSelection()
+	if svg.Mode == EditView || (len(svg.Selected) == 0 && svg.drag.typ != editorDragRubber) {
+		return
+	}
+	rs := &svg.Render
+	pc := &gi.Paint{}
+	pc.Defaults()
+	pc.StrokeStyle.SetColor(EditorSelectColor)
+	pc.StrokeStyle.Width = units.NewValue(1, units.Dot)
+	pc.StrokeStyle.Width.Dots = 1
+	pc.FillStyle.SetColor(nil)
+	rs.PushXForm(gi.Identity2D())
+	rs.XForm = gi.Identity2D()
+	defer rs.PopXForm()
+	if svg.drag.typ == editorDragRubber {
+		rb := image.Rect(svg.drag.start.X, svg.drag.start.Y, svg.drag.cur.X, svg.drag.cur.Y)
+		pc.DrawRectangle(rs, float32(rb.Min.X), float32(rb.Min.Y), float32(rb.Dx()), float32(rb.Dy()))
+		pc.FillStrokeClear(rs)
+	}
+	if len(svg.Selected) == 0 {
+		return
+	}
+	for _, s := range svg.Selected {
+		bb := s.AsNode2D().BBox
+		pc.DrawRectangle(rs, float32(bb.Min.X), float32(bb.Min.Y), float32(bb.Dx()), float32(bb.Dy()))
+		pc.FillStrokeClear(rs)
+	}
+	bb := svg.SelectionBBox()
+	hs := float32(EditorHandleSize)
+	rp := HandlePos(bb, EditorRotateHandle)
+	pc.DrawLine(rs, rp.X, rp.Y, rp.X, float32(bb.Min.Y))
+	pc.FillStrokeClear(rs)
+	pc.FillStyle.SetColor(color.White)
+	for h := 0; h < EditorRotateHandle; h++ {
+		hp := HandlePos(bb, h)
+		pc.DrawRectangle(rs, hp.X-0.5*hs, hp.Y-0.5*hs, hs, hs)
+		pc.FillStrokeClear(rs)
+	}
+	pc.DrawCircle(rs, rp.X, rp.Y, 0.5*hs)
+	pc.FillStrokeClear(rs)
+	if svg.Mode != EditNodes {
+		return
+	}
+	for _, s := range svg.Selected {
+		path, ok := s.(*Path)
+		if !ok {
+			continue
+		}
+		xf := path.Pnt.XForm.Multiply(svg.ParXForm(path))
+		PathDataIterFunc(path.Data, func(idx int, cmd PathCmds, ptIdx int, cx, cy float32) bool {
+			vp := xf.TransformPointVec2D(gi.Vec2D{cx, cy})
+			pc.DrawCircle(rs, vp.X, vp.Y, 0.4*hs)
+			pc.FillStrokeClear(rs)
+			return true
+		})
+	}
+}
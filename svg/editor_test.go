@@ -0,0 +1,243 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+	"testing"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+)
+
+// testEditorDraw returns an editor with identity transforms and two rects,
+// r1 at 10,10 -- 40,40 and r2 at 50,50 -- 60,60, as laid out in the drawing
+// (and window)
+func testEditorDraw() (*Editor, *Rect, *Rect) {
+	ed := &Editor{}
+	ed.InitName(ed, "editor")
+	ed.Pnt.XForm = gi.Identity2D()
+	ed.Mode = EditSelect
+	var rs [2]*Rect
+	for i, bb := range []image.Rectangle{image.Rect(10, 10, 40, 40), image.Rect(50, 50, 60, 60)} {
+		r := ed.AddNewChild(KiT_Rect, []string{"r1", "r2"}[i]).(*Rect)
+		r.Pnt.XForm = gi.Identity2D()
+		r.BBox = bb
+		r.WinBBox = bb
+		rs[i] = r
+	}
+	return ed, rs[0], rs[1]
+}
+
+// xformPtEq returns true if the transform of node maps pt to about exp
+func xformPtEq(g *Rect, pt, exp gi.Vec2D) bool {
+	d := g.Pnt.XForm.TransformPointVec2D(pt).Sub(exp)
+	return math32.Abs(d.X) < 1.0e-3 && math32.Abs(d.Y) < 1.0e-3
+}
+
+func TestEditorEditRestore(t *testing.T) {
+	p := &Path{}
+	p.InitName(p, "path")
+	if err := p.SetData("M10,10 L20,10"); err != nil {
+		t.Fatal(err)
+	}
+	ed := NewEditorEdit([]gi.Node2D{p})
+	SetElXForm(p, gi.Translate2D(5, 0))
+	PathDataMovePoint(p.Data, 4, 0, 5)
+	if xf, _ := p.Prop("transform"); xf != "matrix(1,0,0,1,5,0)" {
+		t.Errorf("transform expected: matrix(1,0,0,1,5,0) got: %v", xf)
+	}
+	redo := ed.Restore()
+	if _, has := p.Prop("transform"); has {
+		t.Errorf("transform should be removed by restore")
+	}
+	if ds := PathDataString(p.Data); ds != "M10,10 L20,10" {
+		t.Errorf("restored path expected: M10,10 L20,10 got: %v", ds)
+	}
+	redo.Restore()
+	if ds := PathDataString(p.Data); ds != "M10,10 L20,15" {
+		t.Errorf("redone path expected: M10,10 L20,15 got: %v", ds)
+	}
+}
+
+func TestEditorUndoRedo(t *testing.T) {
+	ed, r1, _ := testEditorDraw()
+	if ed.Undo() || ed.Redo() {
+		t.Errorf("undo / redo with nothing to undo")
+	}
+	ed.SaveEdit(NewEditorEdit([]gi.Node2D{r1}))
+	SetElXForm(r1, gi.Translate2D(5, 0))
+	ed.SaveEdit(NewEditorEdit([]gi.Node2D{r1}))
+	SetElXForm(r1, gi.Translate2D(5, 5))
+
+	if !ed.Undo() || len(ed.Undos) != 1 || len(ed.Redos) != 1 {
+		t.Errorf("undo: undos: %v redos: %v", len(ed.Undos), len(ed.Redos))
+	}
+	if xf, _ := r1.Prop("transform"); xf != "matrix(1,0,0,1,5,0)" {
+		t.Errorf("undo transform expected: matrix(1,0,0,1,5,0) got: %v", xf)
+	}
+	if !ed.Undo() {
+		t.Errorf("second undo failed")
+	}
+	if _, has := r1.Prop("transform"); has {
+		t.Errorf("transform should be removed by undo")
+	}
+	if !ed.Redo() || !ed.Redo() || ed.Redo() {
+		t.Errorf("redo: undos: %v redos: %v", len(ed.Undos), len(ed.Redos))
+	}
+	if xf, _ := r1.Prop("transform"); xf != "matrix(1,0,0,1,5,5)" {
+		t.Errorf("redo transform expected: matrix(1,0,0,1,5,5) got: %v", xf)
+	}
+
+	// a new edit after an undo discards the undone edit
+	ed.Undo()
+	ed.SaveEdit(NewEditorEdit([]gi.Node2D{r1}))
+	if len(ed.Undos) != 2 || len(ed.Redos) != 0 || ed.Redo() {
+		t.Errorf("edit after undo: undos: %v redos: %v", len(ed.Undos), len(ed.Redos))
+	}
+}
+
+func TestEditorSnapPoint(t *testing.T) {
+	ed, _, _ := testEditorDraw()
+	pt := gi.Vec2D{13, 27}
+	if sp := ed.SnapPoint(pt); sp != pt {
+		t.Errorf("no snap grid: %v", sp)
+	}
+	ed.SnapGrid = 10
+	if sp := ed.SnapPoint(pt); sp != (gi.Vec2D{10, 30}) {
+		t.Errorf("snap expected: {10 30} got: %v", sp)
+	}
+	// the grid is in user coordinates: 6.5,13.5 snaps to 10,10
+	ed.Pnt.XForm = gi.Scale2D(2, 2)
+	if sp := ed.SnapPoint(pt); sp != (gi.Vec2D{20, 20}) {
+		t.Errorf("scaled snap expected: {20 20} got: %v", sp)
+	}
+}
+
+func TestEditorDragScale(t *testing.T) {
+	ed, r1, r2 := testEditorDraw()
+	ed.Selected = []gi.Node2D{r1}
+
+	// bottom-right handle, anchored at the top-left
+	hp := HandlePos(r1.BBox, 4)
+	if hp != (gi.Vec2D{40, 40}) || ed.HandleAt(image.Point{41, 39}) != 4 {
+		t.Fatalf("bottom-right handle: %v", hp)
+	}
+	ed.DragStart(image.Point{40, 40}, false, false)
+	ed.DragMove(image.Point{70, 55}, false)
+	if !xformPtEq(r1, gi.Vec2D{10, 10}, gi.Vec2D{10, 10}) || !xformPtEq(r1, gi.Vec2D{40, 40}, gi.Vec2D{70, 55}) {
+		t.Errorf("scale: %v", r1.Pnt.XForm)
+	}
+	// constrained: the larger scale (x 2) is used for both
+	ed.DragMove(image.Point{70, 55}, true)
+	if !xformPtEq(r1, gi.Vec2D{40, 40}, gi.Vec2D{70, 70}) {
+		t.Errorf("constrained scale: %v", r1.Pnt.XForm)
+	}
+	if _, has := r2.Prop("transform"); has {
+		t.Errorf("unselected element transformed")
+	}
+	ed.DragEnd()
+	if len(ed.Undos) != 1 {
+		t.Errorf("scale edit not saved: %v", len(ed.Undos))
+	}
+	ed.Undo()
+	if _, has := r1.Prop("transform"); has {
+		t.Errorf("scale not undone")
+	}
+}
+
+func TestEditorDragRotate(t *testing.T) {
+	ed, r1, _ := testEditorDraw()
+	ed.Selected = []gi.Node2D{r1}
+
+	// the rotate handle is above the center at 25,25 -- dragging it to the
+	// right of the center rotates by 90 degrees
+	hp := HandlePos(r1.BBox, EditorRotateHandle)
+	if hp != (gi.Vec2D{25, float32(10 - EditorRotateOffset)}) {
+		t.Fatalf("rotate handle: %v", hp)
+	}
+	ed.DragStart(image.Point{25, 10 - EditorRotateOffset}, false, false)
+	ed.DragMove(image.Point{60, 25}, false)
+	if !xformPtEq(r1, gi.Vec2D{25, 25}, gi.Vec2D{25, 25}) || !xformPtEq(r1, gi.Vec2D{25, 15}, gi.Vec2D{35, 25}) {
+		t.Errorf("rotate: %v", r1.Pnt.XForm)
+	}
+	// constrained: a bit past 90 degrees snaps back to it
+	ed.DragMove(image.Point{60, 27}, true)
+	if !xformPtEq(r1, gi.Vec2D{25, 15}, gi.Vec2D{35, 25}) {
+		t.Errorf("constrained rotate: %v", r1.Pnt.XForm)
+	}
+	ed.DragMove(image.Point{60, 27}, false)
+	if xformPtEq(r1, gi.Vec2D{25, 15}, gi.Vec2D{35, 25}) {
+		t.Errorf("unconstrained rotate should not snap: %v", r1.Pnt.XForm)
+	}
+	ed.DragEnd()
+}
+
+func TestEditorDragMove(t *testing.T) {
+	ed, r1, r2 := testEditorDraw()
+	ed.SnapGrid = 5
+	ed.DragStart(image.Point{25, 25}, false, false)
+	if len(ed.Selected) != 1 || ed.Selected[0] != r1 {
+		t.Fatalf("drag start selection: %v", ed.Selected)
+	}
+	// the top-left of the selection snaps to the grid: 10+7 -> 15
+	ed.DragMove(image.Point{32, 26}, false)
+	if !xformPtEq(r1, gi.Vec2D{10, 10}, gi.Vec2D{15, 10}) {
+		t.Errorf("snapped move: %v", r1.Pnt.XForm)
+	}
+	ed.DragEnd()
+	if len(ed.Undos) != 1 {
+		t.Errorf("move edit not saved: %v", len(ed.Undos))
+	}
+
+	// a click without moving selects, but saves no edit
+	ed.DragStart(image.Point{55, 55}, false, false)
+	ed.DragEnd()
+	if len(ed.Selected) != 1 || ed.Selected[0] != r2 || len(ed.Undos) != 1 {
+		t.Errorf("click selection: %v undos: %v", ed.Selected, len(ed.Undos))
+	}
+	ed.DragStart(image.Point{25, 25}, true, false)
+	ed.DragEnd()
+	if len(ed.Selected) != 2 {
+		t.Errorf("toggle select: %v", ed.Selected)
+	}
+	ed.DragStart(image.Point{25, 25}, true, false)
+	ed.DragEnd()
+	if len(ed.Selected) != 1 || ed.Selected[0] != r2 {
+		t.Errorf("toggle unselect: %v", ed.Selected)
+	}
+}
+
+func TestEditorRubberBand(t *testing.T) {
+	ed, r1, r2 := testEditorDraw()
+	ed.Selected = []gi.Node2D{r2}
+	ed.DragStart(image.Point{5, 5}, false, false)
+	if len(ed.Selected) != 0 {
+		t.Errorf("drag in empty space should clear selection: %v", ed.Selected)
+	}
+	ed.DragMove(image.Point{45, 45}, false)
+	ed.DragEnd()
+	if len(ed.Selected) != 1 || ed.Selected[0] != r1 {
+		t.Errorf("rubber band selection: %v", ed.Selected)
+	}
+	if len(ed.Undos) != 0 {
+		t.Errorf("rubber band saved an edit")
+	}
+
+	// toggle keeps the selection, and only elements entirely within the
+	// band are added
+	ed.DragStart(image.Point{65, 65}, true, false)
+	ed.DragMove(image.Point{55, 45}, false)
+	ed.DragEnd()
+	if len(ed.Selected) != 1 {
+		t.Errorf("partial rubber band: %v", ed.Selected)
+	}
+	ed.DragStart(image.Point{65, 65}, true, false)
+	ed.DragMove(image.Point{45, 45}, false)
+	ed.DragEnd()
+	if len(ed.Selected) != 2 || !ed.IsSelected(r1) || !ed.IsSelected(r2) {
+		t.Errorf("toggle rubber band: %v", ed.Selected)
+	}
+}
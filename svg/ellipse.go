@@ -24,6 +24,8 @@ func (g *Ellipse) Render2D() {
 	}
 	pc := &g.Pnt
 	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
 	rs.Lock()
 	rs.PushXForm(pc.XForm)
 	pc.DrawEllipse(rs, g.Pos.X, g.Pos.Y, g.Radii.X, g.Radii.Y)
@@ -34,4 +36,11 @@ func (g *Ellipse) Render2D() {
 	g.Render2DChildren()
 
 	rs.PopXFormLock()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
+}
+
+// LocalBBox returns the bounding box of the ellipse in its user space
+func (g *Ellipse) LocalBBox() (min, max gi.Vec2D) {
+	return g.Pos.Sub(g.Radii), g.Pos.Add(g.Radii)
 }
@@ -5,13 +5,342 @@
 package svg
 
 import (
+	"image"
+	"log"
+	"strings"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
 	"github.com/goki/ki/kit"
 )
 
-// Filter represents SVG filter* elements
+// Filter represents SVG filter* elements -- the filter element itself has
+// FilterType "filter", and contains the fe* filter primitive elements (e.g.,
+// feGaussianBlur), which are also Filter nodes, with FilterType set to the
+// element name.  All of the attributes are stored as properties, and are
+// interpreted when the filter is applied, by Apply.
 type Filter struct {
 	NodeBase
 	FilterType string
 }
 
 var KiT_Filter = kit.Types.AddType(&Filter{}, nil)
+
+// Render2D does nothing -- filters are only applied to the elements that
+// refer to them via a filter: url(#id) property, by Apply
+func (g *Filter) Render2D() {
+}
+
+// IsFilter returns true if this is a filter element, as opposed to a filter
+// primitive or other filter-like element
+func (g *Filter) IsFilter() bool {
+	return g.FilterType == "filter"
+}
+
+// filterCtxt is the context for applying the primitives of a filter
+type filterCtxt struct {
+	rect    image.Rectangle        // filter region, in pixels
+	scale   gi.Vec2D               // scaling from primitive units to pixels
+	src     *image.RGBA            // SourceGraphic
+	srcA    *image.RGBA            // SourceAlpha, computed as needed
+	last    *image.RGBA            // result of the last primitive
+	results map[string]*image.RGBA // named results
+}
+
+// input returns the input image of given name, per the in / in2 attributes
+func (fc *filterCtxt) input(g *Filter, nm string) *image.RGBA {
+	switch nm {
+	case "":
+		return fc.last
+	case "SourceGraphic":
+		return fc.src
+	case "SourceAlpha":
+		if fc.srcA == nil {
+			fc.srcA = filterSourceAlpha(fc.src, fc.rect)
+		}
+		return fc.srcA
+	}
+	if img, ok := fc.results[nm]; ok {
+		return img
+	}
+	log.Printf("gi.svg Filter: input: %v not found or not supported for filter primitive: %v\n", nm, g.PathUnique())
+	return fc.last
+}
+
+// propStr returns the given property as a string, or "" if not set
+func (g *Filter) propStr(name string) string {
+	if p, ok := g.Props[name]; ok {
+		return strings.TrimSpace(kit.ToString(p))
+	}
+	return ""
+}
+
+// propFloat returns the given property as a float, or def if not set
+func (g *Filter) propFloat(name string, def float32) float32 {
+	ps := g.propStr(name)
+	if ps == "" {
+		return def
+	}
+	v, _, err := parseFilterNum(ps)
+	if err != nil {
+		log.Printf("gi.svg Filter: %v: could not parse %v: %v\n", g.PathUnique(), name, err)
+		return def
+	}
+	return v
+}
+
+// propFloats returns the given property as a list of floats
+func (g *Filter) propFloats(name string) []float32 {
+	return gi.ReadPoints(g.propStr(name))
+}
+
+// parseFilterNum parses a number that can be a percentage, which is returned
+// as a fraction, with pct = true
+func parseFilterNum(s string) (v float32, pct bool, err error) {
+	if strings.HasSuffix(s, "%") {
+		v, err = gi.ParseFloat32(strings.TrimSuffix(s, "%"))
+		return v / 100, true, err
+	}
+	v, err = gi.ParseFloat32(s)
+	return v, false, err
+}
+
+// regionVal returns the filter region value for given attribute, with
+// given default fraction of the bounding box, from bounding box min and
+// size values along the relevant dimension
+func (g *Filter) regionVal(name string, def float32, bbUnits bool, min, size float32) float32 {
+	ps := g.propStr(name)
+	v, pct := def, true
+	if ps != "" {
+		var err error
+		v, pct, err = parseFilterNum(ps)
+		if err != nil {
+			log.Printf("gi.svg Filter: %v: could not parse %v: %v\n", g.PathUnique(), name, err)
+			v, pct = def, true
+		}
+	}
+	if bbUnits || pct {
+		return min + v*size
+	}
+	return v
+}
+
+// Apply applies the filter to given source image (rendering of the element
+// that refers to the filter), with given transform from the user space of
+// that element to pixels, and bounding box of the element in its user space
+// -- returns the filtered image and the filter region in pixels that it
+// covers.  Filter primitive subregions are not supported.
+func (g *Filter) Apply(src *image.RGBA, xf gi.Matrix2D, min, max gi.Vec2D) (*image.RGBA, image.Rectangle) {
+	bbUnits := g.propStr("filterUnits") != "userSpaceOnUse"
+	sz := max.Sub(min)
+	rmin := gi.Vec2D{g.regionVal("x", -0.1, bbUnits, min.X, sz.X), g.regionVal("y", -0.1, bbUnits, min.Y, sz.Y)}
+	rsz := gi.Vec2D{g.regionVal("width", 1.2, bbUnits, 0, sz.X), g.regionVal("height", 1.2, bbUnits, 0, sz.Y)}
+	pmin, pmax := XFormBBox(xf, rmin, rmin.Add(rsz))
+	rect := image.Rect(int(math32.Floor(pmin.X)), int(math32.Floor(pmin.Y)),
+		int(math32.Ceil(pmax.X)), int(math32.Ceil(pmax.Y))).Intersect(src.Bounds())
+
+	fc := &filterCtxt{rect: rect, src: src, last: src, results: make(map[string]*image.RGBA)}
+	fc.scale.X, fc.scale.Y = xf.ExtractScale()
+	fc.scale.X, fc.scale.Y = math32.Abs(fc.scale.X), math32.Abs(fc.scale.Y)
+	if g.propStr("primitiveUnits") == "objectBoundingBox" {
+		fc.scale = fc.scale.Mul(sz)
+	}
+	for _, kid := range g.Kids {
+		fe, ok := kid.(*Filter)
+		if !ok {
+			continue
+		}
+		res := fe.applyPrimitive(fc)
+		if rn := fe.propStr("result"); rn != "" {
+			fc.results[rn] = res
+		}
+		fc.last = res
+	}
+	return fc.last, rect
+}
+
+// applyPrimitive applies this filter primitive in given context, returning
+// the result
+func (g *Filter) applyPrimitive(fc *filterCtxt) *image.RGBA {
+	in := fc.input(g, g.propStr("in"))
+	switch g.FilterType {
+	case "feGaussianBlur":
+		sd := g.propFloats("stdDeviation")
+		if len(sd) == 0 {
+			return in
+		}
+		sx, sy := sd[0], sd[0]
+		if len(sd) > 1 {
+			sy = sd[1]
+		}
+		return FilterBlur(in, fc.rect, sx*fc.scale.X, sy*fc.scale.Y)
+	case "feOffset":
+		dx := g.propFloat("dx", 0) * fc.scale.X
+		dy := g.propFloat("dy", 0) * fc.scale.Y
+		return FilterOffset(in, fc.rect, image.Point{int(math32.Floor(dx + 0.5)), int(math32.Floor(dy + 0.5))})
+	case "feFlood":
+		var clr gi.Color
+		clr.SetUInt8(0, 0, 0, 255)
+		if cs := g.propStr("flood-color"); cs != "" {
+			if err := clr.SetString(cs, nil); err != nil {
+				log.Printf("gi.svg Filter: %v: could not parse flood-color: %v\n", g.PathUnique(), err)
+			}
+		}
+		return FilterFlood(in.Bounds(), fc.rect, clr, g.propFloat("flood-opacity", 1))
+	case "feMerge":
+		res := image.NewRGBA(in.Bounds())
+		for _, kid := range g.Kids {
+			mn, ok := kid.(*Filter)
+			if !ok || mn.FilterType != "feMergeNode" {
+				continue
+			}
+			res = FilterComposite(fc.input(mn, mn.propStr("in")), res, fc.rect, "over", nil)
+		}
+		return res
+	case "feColorMatrix":
+		return FilterColorMatrix(in, fc.rect, g.colorMatrix())
+	case "feComposite":
+		in2 := fc.input(g, g.propStr("in2"))
+		op := g.propStr("operator")
+		if op == "" {
+			op = "over"
+		}
+		ks := []float32{g.propFloat("k1", 0), g.propFloat("k2", 0), g.propFloat("k3", 0), g.propFloat("k4", 0)}
+		return FilterComposite(in, in2, fc.rect, op, ks)
+	case "feBlend":
+		in2 := fc.input(g, g.propStr("in2"))
+		mode := g.propStr("mode")
+		if mode == "" {
+			mode = "normal"
+		}
+		return FilterBlend(in, in2, fc.rect, mode)
+	}
+	log.Printf("gi.svg Filter: filter primitive: %v is not supported -- passing input through\n", g.FilterType)
+	return in
+}
+
+// colorMatrix returns the 4x5 color matrix for a feColorMatrix primitive,
+// from its type and values attributes
+func (g *Filter) colorMatrix() []float32 {
+	vals := g.propFloats("values")
+	switch g.propStr("type") {
+	case "saturate":
+		s := float32(1)
+		if len(vals) > 0 {
+			s = vals[0]
+		}
+		return []float32{
+			0.213 + 0.787*s, 0.715 - 0.715*s, 0.072 - 0.072*s, 0, 0,
+			0.213 - 0.213*s, 0.715 + 0.285*s, 0.072 - 0.072*s, 0, 0,
+			0.213 - 0.213*s, 0.715 - 0.715*s, 0.072 + 0.928*s, 0, 0,
+			0, 0, 0, 1, 0}
+	case "hueRotate":
+		var ang float32
+		if len(vals) > 0 {
+			ang = gi.Radians(vals[0])
+		}
+		c, s := math32.Cos(ang), math32.Sin(ang)
+		return []float32{
+			0.213 + c*0.787 - s*0.213, 0.715 - c*0.715 - s*0.715, 0.072 - c*0.072 + s*0.928, 0, 0,
+			0.213 - c*0.213 + s*0.143, 0.715 + c*0.285 + s*0.140, 0.072 - c*0.072 - s*0.283, 0, 0,
+			0.213 - c*0.213 - s*0.787, 0.715 - c*0.715 + s*0.715, 0.072 + c*0.928 + s*0.072, 0, 0,
+			0, 0, 0, 1, 0}
+	case "luminanceToAlpha":
+		return []float32{
+			0, 0, 0, 0, 0,
+			0, 0, 0, 0, 0,
+			0, 0, 0, 0, 0,
+			0.2125, 0.7154, 0.0721, 0, 0}
+	}
+	if len(vals) != 20 {
+		if len(vals) > 0 {
+			log.Printf("gi.svg Filter: %v: feColorMatrix values must have 20 numbers, has: %v\n", g.PathUnique(), len(vals))
+		}
+		return []float32{
+			1, 0, 0, 0, 0,
+			0, 1, 0, 0, 0,
+			0, 0, 1, 0, 0,
+			0, 0, 0, 1, 0}
+	}
+	return vals
+}
+
+// FilterRef checks for a filter property, and if set, attempts to find that
+// filter and return it
+func (g *NodeBase) FilterRef() *Filter {
+	fs, ok := g.Props["filter"]
+	if !ok {
+		return nil
+	}
+	fnm, ok := fs.(string)
+	if !ok {
+		f, ok := fs.(*Filter)
+		if !ok {
+			log.Printf("gi.svg filter property should be a string url or pointer to Filter element, instead is: %T\n", fs)
+			return nil
+		}
+		return f
+	}
+	fn := g.FindSVGURL(fnm)
+	if fn != nil {
+		f, ok := fn.(*Filter)
+		if !ok || !f.IsFilter() {
+			log.Printf("gi.svg Found element named: %v but isn't a filter element, instead is: %T", fnm, fn)
+			return nil
+		}
+		return f
+	}
+	return nil
+}
+
+// PushFilter starts rendering into an offscreen image if there is a filter
+// referred to by the filter property, returning that filter -- must be
+// called at the start of Render2D, after PushClipPath and outside of the
+// render lock, and balanced by a call to PopFilter with the return value.
+func (g *NodeBase) PushFilter() *Filter {
+	f := g.FilterRef()
+	if f == nil {
+		return nil
+	}
+	rs := &g.Viewport.Render
+	rs.Lock()
+	rs.StartOffscreen()
+	rs.Unlock()
+	return f
+}
+
+// PopFilter applies the filter returned by PushFilter to everything
+// rendered since then, and draws the result into the render image, through
+// the current mask if any.
+func (g *NodeBase) PopFilter(f *Filter) {
+	if f == nil {
+		return
+	}
+	rs := &g.Viewport.Render
+	rs.Lock()
+	defer rs.Unlock()
+	src := rs.EndOffscreen()
+	if src == nil {
+		return
+	}
+	xf := g.Pnt.XForm.Multiply(rs.XForm)
+	min, max := g.filterBBox(xf, src.Bounds())
+	img, r := f.Apply(src, xf, min, max)
+	r = r.Intersect(rs.Bounds)
+	FilterDraw(rs.Image, img, r, rs.Mask)
+}
+
+// filterBBox returns the bounding box of the node in its user space, for
+// the filter region: its LocalBBox if it is a LocalBBoxer, and otherwise its
+// computed BBox (or given bounds of the offscreen rendering if that is
+// empty), transformed back by the inverse of given transform
+func (g *NodeBase) filterBBox(xf gi.Matrix2D, bounds image.Rectangle) (min, max gi.Vec2D) {
+	if lb, ok := g.This().(LocalBBoxer); ok {
+		return lb.LocalBBox()
+	}
+	bb := g.BBox
+	if bb.Empty() {
+		bb = bounds
+	}
+	return XFormBBox(xf.Inverse(), gi.NewVec2DFmPoint(bb.Min), gi.NewVec2DFmPoint(bb.Max))
+}
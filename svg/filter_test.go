@@ -0,0 +1,89 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/goki/gi/gi"
+)
+
+// testFilterImage returns a 40x40 image with an opaque white 10x10 square
+// in the middle
+func testFilterImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	draw.Draw(img, image.Rect(15, 15, 25, 25), image.NewUniform(color.White), image.ZP, draw.Src)
+	return img
+}
+
+func TestFilterOffset(t *testing.T) {
+	src := testFilterImage()
+	res := FilterOffset(src, src.Bounds(), image.Point{5, -3})
+	if a := res.RGBAAt(20, 17).A; a != 255 {
+		t.Errorf("offset: pixel in offset square should be opaque, got alpha: %v", a)
+	}
+	if a := res.RGBAAt(16, 16).A; a != 0 {
+		t.Errorf("offset: pixel outside of offset square should be transparent, got alpha: %v", a)
+	}
+}
+
+func TestFilterBlur(t *testing.T) {
+	src := testFilterImage()
+	res := FilterBlur(src, src.Bounds(), 2, 2)
+	sum := func(img *image.RGBA) int {
+		s := 0
+		for y := 0; y < 40; y++ {
+			for x := 0; x < 40; x++ {
+				s += int(img.RGBAAt(x, y).A)
+			}
+		}
+		return s
+	}
+	ss, rs := sum(src), sum(res)
+	if rs < ss*9/10 || rs > ss*11/10 {
+		t.Errorf("blur: total alpha should be about the same: src: %v result: %v", ss, rs)
+	}
+	if a := res.RGBAAt(13, 20).A; a == 0 || a == 255 {
+		t.Errorf("blur: pixel next to square edge should be partially transparent, got alpha: %v", a)
+	}
+	if l, r := res.RGBAAt(12, 20).A, res.RGBAAt(27, 20).A; l != r {
+		t.Errorf("blur: should be symmetric, got: %v vs %v", l, r)
+	}
+	if res.RGBAAt(0, 0).A != 0 {
+		t.Errorf("blur: far corner should stay transparent")
+	}
+}
+
+func TestFilterComposite(t *testing.T) {
+	src := testFilterImage()
+	var red gi.Color
+	red.SetUInt8(255, 0, 0, 255)
+	flood := FilterFlood(src.Bounds(), src.Bounds(), red, 0.5)
+	res := FilterComposite(flood, src, src.Bounds(), "in", nil)
+	if c := res.RGBAAt(20, 20); c.R != 128 || c.A != 128 || c.G != 0 {
+		t.Errorf("composite in: expected half-opacity red inside square, got: %v", c)
+	}
+	if c := res.RGBAAt(5, 5); c.A != 0 {
+		t.Errorf("composite in: expected transparent outside square, got: %v", c)
+	}
+}
+
+func TestFilterBBox(t *testing.T) {
+	g := &NodeBase{} // not a LocalBBoxer
+	g.InitName(g, "node")
+	g.BBox = image.Rect(10, 10, 30, 20)
+	min, max := g.filterBBox(gi.Scale2D(2, 2), image.Rect(0, 0, 100, 100))
+	if min != (gi.Vec2D{5, 5}) || max != (gi.Vec2D{15, 10}) {
+		t.Errorf("filter bbox from BBox: %v %v", min, max)
+	}
+	g.BBox = image.ZR
+	min, max = g.filterBBox(gi.Scale2D(2, 2), image.Rect(0, 0, 100, 100))
+	if min != (gi.Vec2D{0, 0}) || max != (gi.Vec2D{50, 50}) {
+		t.Errorf("filter bbox from bounds: %v %v", min, max)
+	}
+}
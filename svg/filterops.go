@@ -0,0 +1,288 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+	"log"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+	"golang.org/x/image/draw"
+)
+
+// These are the image processing functions for the SVG filter primitives --
+// they all operate on alpha-premultiplied RGBA images, within the given
+// filter region rectangle r, returning a new image of the same size as the
+// input, which is transparent outside of r.
+
+// filterPix is an alpha-premultiplied pixel as normalized 0..1 floats
+type filterPix [4]float32
+
+// getFilterPix returns the pixel at given offset in given image
+func getFilterPix(img *image.RGBA, i int) filterPix {
+	return filterPix{float32(img.Pix[i]) / 255, float32(img.Pix[i+1]) / 255,
+		float32(img.Pix[i+2]) / 255, float32(img.Pix[i+3]) / 255}
+}
+
+// setFilterPix sets the pixel at given offset in given image, clamping
+// values to 0..1, and colors to the alpha value
+func setFilterPix(img *image.RGBA, i int, p filterPix) {
+	a := gi.InRange32(p[3], 0, 1)
+	for c := 0; c < 3; c++ {
+		img.Pix[i+c] = uint8(gi.InRange32(p[c], 0, a)*255 + 0.5)
+	}
+	img.Pix[i+3] = uint8(a*255 + 0.5)
+}
+
+// filterPixOp returns a new image with the result of given function of the
+// pixels of images a and b (b can be nil) within rect r
+func filterPixOp(a, b *image.RGBA, r image.Rectangle, fun func(pa, pb filterPix) filterPix) *image.RGBA {
+	dst := image.NewRGBA(a.Bounds())
+	r = r.Intersect(a.Bounds())
+	var pb filterPix
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			i := a.PixOffset(x, y)
+			if b != nil {
+				pb = getFilterPix(b, i)
+			}
+			setFilterPix(dst, i, fun(getFilterPix(a, i), pb))
+		}
+	}
+	return dst
+}
+
+// filterSourceAlpha returns the SourceAlpha image for given source image:
+// black with the alpha of the source
+func filterSourceAlpha(src *image.RGBA, r image.Rectangle) *image.RGBA {
+	return filterPixOp(src, nil, r, func(pa, pb filterPix) filterPix {
+		return filterPix{0, 0, 0, pa[3]}
+	})
+}
+
+// FilterBlur returns the Gaussian blur of src, with given standard
+// deviations in pixels along each axis, approximated by three successive
+// box blurs, per the SVG standard
+func FilterBlur(src *image.RGBA, r image.Rectangle, sx, sy float32) *image.RGBA {
+	r = r.Intersect(src.Bounds())
+	cur := image.NewRGBA(src.Bounds())
+	draw.Draw(cur, r, src, r.Min, draw.Src)
+	tmp := image.NewRGBA(src.Bounds())
+	if rad := blurBoxRadius(sx); rad > 0 {
+		for i := 0; i < 3; i++ {
+			boxBlur(tmp, cur, r, rad, true)
+			cur, tmp = tmp, cur
+		}
+	}
+	if rad := blurBoxRadius(sy); rad > 0 {
+		for i := 0; i < 3; i++ {
+			boxBlur(tmp, cur, r, rad, false)
+			cur, tmp = tmp, cur
+		}
+	}
+	return cur
+}
+
+// blurBoxRadius returns the radius of the box blur for given standard
+// deviation
+func blurBoxRadius(sd float32) int {
+	if sd <= 0 {
+		return 0
+	}
+	d := int(math32.Floor(sd*3*math32.Sqrt(2*math32.Pi)/4 + 0.5))
+	return d / 2
+}
+
+// boxBlur does one box blur pass of given radius over src into dst, within
+// r, horizontally or vertically -- pixels outside of r are transparent
+func boxBlur(dst, src *image.RGBA, r image.Rectangle, rad int, horiz bool) {
+	n := 2*rad + 1
+	// i indexes along the blur, j across it
+	imin, imax, jmin, jmax := r.Min.X, r.Max.X, r.Min.Y, r.Max.Y
+	if !horiz {
+		imin, imax, jmin, jmax = r.Min.Y, r.Max.Y, r.Min.X, r.Max.X
+	}
+	off := func(i, j int) int {
+		if horiz {
+			return src.PixOffset(i, j)
+		}
+		return src.PixOffset(j, i)
+	}
+	for j := jmin; j < jmax; j++ {
+		var sum [4]int
+		for i := imin; i < imin+rad && i < imax; i++ {
+			o := off(i, j)
+			for c := 0; c < 4; c++ {
+				sum[c] += int(src.Pix[o+c])
+			}
+		}
+		for i := imin; i < imax; i++ {
+			if ia := i + rad; ia < imax {
+				o := off(ia, j)
+				for c := 0; c < 4; c++ {
+					sum[c] += int(src.Pix[o+c])
+				}
+			}
+			if ir := i - rad - 1; ir >= imin {
+				o := off(ir, j)
+				for c := 0; c < 4; c++ {
+					sum[c] -= int(src.Pix[o+c])
+				}
+			}
+			o := off(i, j)
+			for c := 0; c < 4; c++ {
+				dst.Pix[o+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+}
+
+// FilterOffset returns src offset by given number of pixels
+func FilterOffset(src *image.RGBA, r image.Rectangle, d image.Point) *image.RGBA {
+	dst := image.NewRGBA(src.Bounds())
+	r = r.Intersect(src.Bounds())
+	dr := r.Add(d).Intersect(r)
+	draw.Draw(dst, dr, src, dr.Min.Sub(d), draw.Src)
+	return dst
+}
+
+// FilterFlood returns an image of given bounds filled with given color and
+// opacity within r
+func FilterFlood(b image.Rectangle, r image.Rectangle, clr gi.Color, opacity float32) *image.RGBA {
+	dst := image.NewRGBA(b)
+	op := gi.InRange32(opacity, 0, 1)
+	var fc gi.Color
+	fc.SetUInt8(uint8(float32(clr.R)*op+0.5), uint8(float32(clr.G)*op+0.5), uint8(float32(clr.B)*op+0.5), uint8(float32(clr.A)*op+0.5))
+	draw.Draw(dst, r.Intersect(b), image.NewUniform(fc), image.ZP, draw.Src)
+	return dst
+}
+
+// FilterComposite returns the Porter-Duff composite of a with b, using given
+// operator: over, in, out, atop, xor, or arithmetic, which uses the k1..k4
+// values in ks
+func FilterComposite(a, b *image.RGBA, r image.Rectangle, op string, ks []float32) *image.RGBA {
+	var fun func(pa, pb filterPix) filterPix
+	switch op {
+	case "in":
+		fun = func(pa, pb filterPix) filterPix {
+			return filterPix{pa[0] * pb[3], pa[1] * pb[3], pa[2] * pb[3], pa[3] * pb[3]}
+		}
+	case "out":
+		fun = func(pa, pb filterPix) filterPix {
+			ib := 1 - pb[3]
+			return filterPix{pa[0] * ib, pa[1] * ib, pa[2] * ib, pa[3] * ib}
+		}
+	case "atop":
+		fun = func(pa, pb filterPix) filterPix {
+			var pr filterPix
+			for c := 0; c < 4; c++ {
+				pr[c] = pa[c]*pb[3] + pb[c]*(1-pa[3])
+			}
+			return pr
+		}
+	case "xor":
+		fun = func(pa, pb filterPix) filterPix {
+			var pr filterPix
+			for c := 0; c < 4; c++ {
+				pr[c] = pa[c]*(1-pb[3]) + pb[c]*(1-pa[3])
+			}
+			return pr
+		}
+	case "arithmetic":
+		var k [4]float32
+		copy(k[:], ks)
+		fun = func(pa, pb filterPix) filterPix {
+			var pr filterPix
+			for c := 0; c < 4; c++ {
+				pr[c] = k[0]*pa[c]*pb[c] + k[1]*pa[c] + k[2]*pb[c] + k[3]
+			}
+			return pr
+		}
+	default:
+		if op != "over" {
+			log.Printf("gi.svg FilterComposite: operator: %v not supported -- using over\n", op)
+		}
+		fun = func(pa, pb filterPix) filterPix {
+			var pr filterPix
+			for c := 0; c < 4; c++ {
+				pr[c] = pa[c] + pb[c]*(1-pa[3])
+			}
+			return pr
+		}
+	}
+	return filterPixOp(a, b, r, fun)
+}
+
+// FilterBlend returns the blend of a on top of b using given blend mode:
+// normal, multiply, screen, darken, or lighten
+func FilterBlend(a, b *image.RGBA, r image.Rectangle, mode string) *image.RGBA {
+	var fun func(ca, cb, qa, qb float32) float32
+	switch mode {
+	case "multiply":
+		fun = func(ca, cb, qa, qb float32) float32 {
+			return (1-qa)*cb + (1-qb)*ca + ca*cb
+		}
+	case "screen":
+		fun = func(ca, cb, qa, qb float32) float32 {
+			return cb + ca - ca*cb
+		}
+	case "darken":
+		fun = func(ca, cb, qa, qb float32) float32 {
+			return gi.Min32((1-qa)*cb+ca, (1-qb)*ca+cb)
+		}
+	case "lighten":
+		fun = func(ca, cb, qa, qb float32) float32 {
+			return gi.Max32((1-qa)*cb+ca, (1-qb)*ca+cb)
+		}
+	default:
+		if mode != "normal" {
+			log.Printf("gi.svg FilterBlend: mode: %v not supported -- using normal\n", mode)
+		}
+		fun = func(ca, cb, qa, qb float32) float32 {
+			return (1-qa)*cb + ca
+		}
+	}
+	return filterPixOp(a, b, r, func(pa, pb filterPix) filterPix {
+		var pr filterPix
+		for c := 0; c < 3; c++ {
+			pr[c] = fun(pa[c], pb[c], pa[3], pb[3])
+		}
+		pr[3] = 1 - (1-pa[3])*(1-pb[3])
+		return pr
+	})
+}
+
+// FilterColorMatrix returns src transformed by given 4x5 color matrix, which
+// applies to non-premultiplied color values, with the 5th column an offset
+func FilterColorMatrix(src *image.RGBA, r image.Rectangle, mat []float32) *image.RGBA {
+	return filterPixOp(src, nil, r, func(pa, pb filterPix) filterPix {
+		if pa[3] > 0 {
+			for c := 0; c < 3; c++ {
+				pa[c] /= pa[3]
+			}
+		}
+		var pr filterPix
+		for c := 0; c < 4; c++ {
+			m := mat[c*5 : c*5+5]
+			pr[c] = m[0]*pa[0] + m[1]*pa[1] + m[2]*pa[2] + m[3]*pa[3] + m[4]
+		}
+		pr[3] = gi.InRange32(pr[3], 0, 1)
+		for c := 0; c < 3; c++ {
+			pr[c] = gi.InRange32(pr[c], 0, 1) * pr[3]
+		}
+		return pr
+	})
+}
+
+// FilterDraw draws the r region of filter result src into dst, through
+// given mask if non-nil
+func FilterDraw(dst, src *image.RGBA, r image.Rectangle, mask *image.Alpha) {
+	if mask == nil {
+		draw.Draw(dst, r, src, r.Min, draw.Over)
+		return
+	}
+	draw.DrawMask(dst, r, src, r.Min, mask, r.Min, draw.Over)
+}
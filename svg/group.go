@@ -46,10 +46,38 @@ func (g *Group) Render2D() {
 	}
 	pc := &g.Pnt
 	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
 	rs.PushXFormLock(pc.XForm)
 
 	g.Render2DChildren()
 	g.ComputeBBoxSVG()
 
 	rs.PopXFormLock()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
+}
+
+// LocalBBox returns the union of the bounding boxes of the children, in the
+// user space of the group
+func (g *Group) LocalBBox() (min, max gi.Vec2D) {
+	got := false
+	for _, kid := range g.Kids {
+		lb, ok := kid.(LocalBBoxer)
+		if !ok {
+			continue
+		}
+		kmin, kmax := lb.LocalBBox()
+		if pntr, ok := kid.(gi.Painter); ok {
+			kmin, kmax = XFormBBox(pntr.Paint().XForm, kmin, kmax)
+		}
+		if !got {
+			min, max = kmin, kmax
+			got = true
+		} else {
+			min.SetMin(kmin)
+			max.SetMax(kmax)
+		}
+	}
+	return
 }
@@ -0,0 +1,183 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"log"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/kit"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// Image is a SVG image element, which renders a raster image, either
+// embedded as a data: URI or loaded from an external file (e.g., PNG, JPEG)
+type Image struct {
+	NodeBase
+	Pos                 gi.Vec2D                   `xml:"{x,y}" desc:"position of the top-left of the image"`
+	Size                gi.Vec2D                   `xml:"{width,height}" desc:"rendered size of the image -- zero means the size of the image in pixels"`
+	Href                string                     `xml:"href" desc:"link to the image: a data: URI with the encoded image, or a file name, which is relative to the directory of the SVG file if not absolute"`
+	PreserveAspectRatio ViewBoxPreserveAspectRatio `xml:"preserveAspectRatio" desc:"how to fit the image within the Size"`
+	Pixels              image.Image                `json:"-" xml:"-" view:"-" desc:"the image, as loaded from Href or set by SetImage"`
+	loadTried           bool
+}
+
+var KiT_Image = kit.Types.AddType(&Image{}, nil)
+
+// SetImage sets the image to render directly
+func (g *Image) SetImage(img image.Image) {
+	g.Pixels = img
+	g.loadTried = true
+}
+
+// OpenImage sets Href to given file name and loads the image from it
+func (g *Image) OpenImage(filename string) error {
+	g.Href = filename
+	g.Pixels = nil
+	return g.LoadImage()
+}
+
+// LoadImage loads the image from Href, which is either a data: URI or a
+// file name -- relative file names are relative to the directory of the
+// parent SVG file, if set
+func (g *Image) LoadImage() error {
+	g.loadTried = true
+	if g.Href == "" {
+		return fmt.Errorf("svg.Image LoadImage: %v: no href", g.PathUnique())
+	}
+	if strings.HasPrefix(g.Href, "data:") {
+		img, err := DecodeDataURIImage(g.Href)
+		if err != nil {
+			err = fmt.Errorf("svg.Image LoadImage: %v: %v", g.PathUnique(), err)
+			log.Println(err)
+			return err
+		}
+		g.Pixels = img
+		return nil
+	}
+	fn := strings.TrimPrefix(g.Href, "file://")
+	if !filepath.IsAbs(fn) {
+		if sv := g.ParentSVG(); sv != nil && sv.Filename != "" {
+			fn = filepath.Join(filepath.Dir(sv.Filename), fn)
+		}
+	}
+	img, err := gi.OpenImage(fn)
+	if err != nil {
+		err = fmt.Errorf("svg.Image LoadImage: %v: could not open image file: %v: %v", g.PathUnique(), fn, err)
+		log.Println(err)
+		return err
+	}
+	g.Pixels = img
+	return nil
+}
+
+// DecodeDataURIImage decodes an image from a data: URI, e.g.,
+// data:image/png;base64,...
+func DecodeDataURIImage(uri string) (image.Image, error) {
+	ci := strings.Index(uri, ",")
+	if !strings.HasPrefix(uri, "data:") || ci < 0 {
+		return nil, fmt.Errorf("invalid data URI")
+	}
+	hdr := uri[len("data:"):ci]
+	dat := uri[ci+1:]
+	var b []byte
+	if strings.HasSuffix(hdr, ";base64") {
+		dat = strings.Map(func(r rune) rune {
+			if r == ' ' || r == '\n' || r == '\r' || r == '\t' {
+				return -1
+			}
+			return r
+		}, dat)
+		var err error
+		b, err = base64.StdEncoding.DecodeString(dat)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ds, err := url.PathUnescape(dat)
+		if err != nil {
+			return nil, err
+		}
+		b = []byte(ds)
+	}
+	img, _, err := image.Decode(bytes.NewReader(b))
+	return img, err
+}
+
+// EffSize returns the effective rendered size of the image
+func (g *Image) EffSize() gi.Vec2D {
+	sz := g.Size
+	if g.Pixels != nil {
+		isz := g.Pixels.Bounds().Size()
+		if sz.X == 0 {
+			sz.X = float32(isz.X)
+		}
+		if sz.Y == 0 {
+			sz.Y = float32(isz.Y)
+		}
+	}
+	return sz
+}
+
+func (g *Image) Render2D() {
+	if g.Viewport == nil {
+		g.This().(gi.Node2D).Init2D()
+	}
+	if g.Pixels == nil && !g.loadTried {
+		g.LoadImage()
+	}
+	if g.Pixels == nil {
+		return
+	}
+	pc := &g.Pnt
+	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
+	rs.Lock()
+	rs.PushXForm(pc.XForm)
+	sz := g.EffSize()
+	ib := g.Pixels.Bounds()
+	vb := ViewBox{Min: gi.NewVec2DFmPoint(ib.Min), Size: gi.NewVec2DFmPoint(ib.Size()), PreserveAspectRatio: g.PreserveAspectRatio}
+	m := vb.XForm(sz).Multiply(gi.Translate2D(g.Pos.X, g.Pos.Y)).Multiply(rs.XForm)
+	pmin, pmax := XFormBBox(rs.XForm, g.Pos, g.Pos.Add(sz))
+	bb := image.Rectangle{Min: pmin.ToPointFloor(), Max: pmax.ToPointCeil()}.Intersect(rs.Bounds)
+	rs.LastRenderBBox = bb
+	if !bb.Empty() {
+		drawImage(rs, bb, m, g.Pixels)
+	}
+	rs.Unlock()
+	g.ComputeBBoxSVG()
+	g.Render2DChildren()
+	rs.PopXFormLock()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
+}
+
+// drawImage draws img through transform m into the bb region of the render
+// Image, clipped by the render Mask if set -- SubImage keeps the coordinates
+// of the full Image, which are the same as those of the Mask, so DstMaskP is
+// zero and not bb.Min
+func drawImage(rs *gi.RenderState, bb image.Rectangle, m gi.Matrix2D, img image.Image) {
+	dst := rs.Image.SubImage(bb).(*image.RGBA)
+	s2d := f64.Aff3{float64(m.XX), float64(m.XY), float64(m.X0), float64(m.YX), float64(m.YY), float64(m.Y0)}
+	var opts *draw.Options
+	if rs.Mask != nil {
+		opts = &draw.Options{DstMask: rs.Mask, DstMaskP: image.ZP}
+	}
+	draw.BiLinear.Transform(dst, s2d, img, img.Bounds(), draw.Over, opts)
+}
+
+// LocalBBox returns the bounding box of the image in its user space
+func (g *Image) LocalBBox() (min, max gi.Vec2D) {
+	return g.Pos, g.Pos.Add(g.EffSize())
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/goki/gi/gi"
+)
+
+func TestDrawImageMask(t *testing.T) {
+	rs := &gi.RenderState{}
+	rs.Image = image.NewRGBA(image.Rect(0, 0, 40, 40))
+	rs.Mask = image.NewAlpha(rs.Image.Bounds())
+	draw.Draw(rs.Mask, image.Rect(0, 0, 20, 40), image.Opaque, image.ZP, draw.Src)
+	img := image.NewRGBA(image.Rect(0, 0, 30, 30))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{255, 0, 0, 255}), image.ZP, draw.Src)
+	drawImage(rs, image.Rect(10, 10, 40, 40), gi.Translate2D(10, 10), img)
+	if a := rs.Image.RGBAAt(15, 20).A; a != 255 {
+		t.Errorf("inside mask: alpha %v", a)
+	}
+	if a := rs.Image.RGBAAt(25, 20).A; a != 0 {
+		t.Errorf("outside mask: alpha %v", a)
+	}
+	if a := rs.Image.RGBAAt(5, 5).A; a != 0 {
+		t.Errorf("outside bbox: alpha %v", a)
+	}
+}
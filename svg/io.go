@@ -11,16 +11,20 @@
 package svg
 
 import (
+	"bufio"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/units"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
 	"golang.org/x/net/html/charset"
 )
 
@@ -54,6 +58,7 @@ func (svg *SVG) OpenXML(filename string) error {
 		log.Println(err)
 		return err
 	}
+	svg.Filename = filename
 	return svg.ReadXML(fp)
 }
 
@@ -105,8 +110,27 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 	var curTxt *Text
 	inTspn := false
 	var curTspn *Text
+	var curTxtPath *TextPath
+	var curLeaf gi.Node2D // current shape element, which can contain animations
+	var curMotion *AnimateMotion
 	var defPrevPar gi.Node2D // previous parent before a def encountered
 
+	// animPar returns the parent for animation elements, which by default
+	// animate their parent
+	animPar := func() gi.Node2D {
+		switch {
+		case curLeaf != nil:
+			return curLeaf
+		case inTspn && curTspn != nil:
+			return curTspn
+		case curTxtPath != nil:
+			return curTxtPath
+		case inTxt && curTxt != nil:
+			return curTxt
+		}
+		return curPar
+	}
+
 	for {
 		var t xml.Token
 		var err error
@@ -182,6 +206,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 				}
 			case nm == "rect":
 				rect := curPar.AddNewChild(KiT_Rect, "rect").(*Rect)
+				curLeaf = rect
 				var x, y, w, h, rx, ry float32
 				for _, attr := range se.Attr {
 					if rect.SetStdXMLAttr(attr.Name.Local, attr.Value) {
@@ -212,6 +237,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 				rect.Radius.Set(rx, ry)
 			case nm == "circle":
 				circle := curPar.AddNewChild(KiT_Circle, "circle").(*Circle)
+				curLeaf = circle
 				var cx, cy, r float32
 				for _, attr := range se.Attr {
 					if circle.SetStdXMLAttr(attr.Name.Local, attr.Value) {
@@ -235,6 +261,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 				circle.Radius = r
 			case nm == "ellipse":
 				ellipse := curPar.AddNewChild(KiT_Ellipse, "ellipse").(*Ellipse)
+				curLeaf = ellipse
 				var cx, cy, rx, ry float32
 				for _, attr := range se.Attr {
 					if ellipse.SetStdXMLAttr(attr.Name.Local, attr.Value) {
@@ -260,6 +287,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 				ellipse.Radii.Set(rx, ry)
 			case nm == "line":
 				line := curPar.AddNewChild(KiT_Line, "line").(*Line)
+				curLeaf = line
 				var x1, x2, y1, y2 float32
 				for _, attr := range se.Attr {
 					if line.SetStdXMLAttr(attr.Name.Local, attr.Value) {
@@ -285,6 +313,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 				line.End.Set(x2, y2)
 			case nm == "polygon":
 				polygon := curPar.AddNewChild(KiT_Polygon, "polygon").(*Polygon)
+				curLeaf = polygon
 				for _, attr := range se.Attr {
 					if polygon.SetStdXMLAttr(attr.Name.Local, attr.Value) {
 						continue
@@ -314,6 +343,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 				}
 			case nm == "polyline":
 				polyline := curPar.AddNewChild(KiT_Polyline, "polyline").(*Polyline)
+				curLeaf = polyline
 				for _, attr := range se.Attr {
 					if polyline.SetStdXMLAttr(attr.Name.Local, attr.Value) {
 						continue
@@ -343,6 +373,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 				}
 			case nm == "path":
 				path := curPar.AddNewChild(KiT_Path, "path").(*Path)
+				curLeaf = path
 				for _, attr := range se.Attr {
 					if path.SetStdXMLAttr(attr.Name.Local, attr.Value) {
 						continue
@@ -366,10 +397,12 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 					inTxt = true
 					curTxt = txt
 				} else {
-					if inTxt && curTxt != nil {
+					switch {
+					case curTxtPath != nil:
+						txt = curTxtPath.AddNewChild(KiT_Text, "tspan").(*Text)
+					case inTxt && curTxt != nil:
 						txt = curTxt.AddNewChild(KiT_Text, "tspan").(*Text)
-						txt.Pos = curTxt.Pos
-					} else {
+					default:
 						txt = curPar.AddNewChild(KiT_Text, "tspan").(*Text)
 					}
 					inTspn = true
@@ -379,49 +412,31 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 					if txt.SetStdXMLAttr(attr.Name.Local, attr.Value) {
 						continue
 					}
+					if !SetTextXMLAttr(txt, attr.Name.Local, attr.Value) {
+						txt.SetProp(attr.Name.Local, attr.Value)
+					}
+				}
+			case nm == "textPath":
+				if !inTxt || curTxt == nil {
+					log.Printf("gi.svg textPath element must be within a text element -- ignored\n")
+					break
+				}
+				curTxtPath = curTxt.AddNewChild(KiT_TextPath, "textPath").(*TextPath)
+				for _, attr := range se.Attr {
+					if curTxtPath.SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
 					switch attr.Name.Local {
-					case "x":
-						pts := gi.ReadPoints(attr.Value)
-						if len(pts) > 1 {
-							txt.CharPosX = pts
-						} else if len(pts) == 1 {
-							txt.Pos.X = pts[0]
-						}
-					case "y":
-						pts := gi.ReadPoints(attr.Value)
-						if len(pts) > 1 {
-							txt.CharPosY = pts
-						} else if len(pts) == 1 {
-							txt.Pos.Y = pts[0]
-						}
-					case "dx":
-						pts := gi.ReadPoints(attr.Value)
-						if len(pts) > 0 {
-							txt.CharPosDX = pts
-						}
-					case "dy":
-						pts := gi.ReadPoints(attr.Value)
-						if len(pts) > 0 {
-							txt.CharPosDY = pts
-						}
-					case "rotate":
-						pts := gi.ReadPoints(attr.Value)
-						if len(pts) > 0 {
-							txt.CharRots = pts
-						}
-					case "textLength":
-						tl, err := gi.ParseFloat32(attr.Value)
-						if err != nil {
-							txt.TextLength = tl
-						}
-					case "lengthAdjust":
-						if attr.Value == "spacingAndGlyphs" {
-							txt.AdjustGlyphs = true
-						} else {
-							txt.AdjustGlyphs = false
-						}
+					case "href":
+						curTxtPath.Href = attr.Value
+					case "startOffset":
+						sv := strings.TrimSpace(attr.Value)
+						curTxtPath.StartOffsetPct = strings.HasSuffix(sv, "%")
+						curTxtPath.StartOffset, err = gi.ParseFloat32(strings.TrimSuffix(sv, "%"))
 					default:
-						txt.SetProp(attr.Name.Local, attr.Value)
+						if !SetTextXMLAttr(&curTxtPath.Text, attr.Name.Local, attr.Value) {
+							curTxtPath.SetProp(attr.Name.Local, attr.Value)
+						}
 					}
 					if err != nil {
 						return err
@@ -495,6 +510,12 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 						continue
 					}
 					switch attr.Name.Local {
+					case "clipPathUnits":
+						if attr.Value == "objectBoundingBox" {
+							cp.Units = ClipObjectBoundingBox
+						} else {
+							cp.Units = ClipUserSpaceOnUse
+						}
 					default:
 						cp.SetProp(attr.Name.Local, attr.Value)
 					}
@@ -518,7 +539,7 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 						szx, err = gi.ParseFloat32(attr.Value)
 					case "markerHeight":
 						szy, err = gi.ParseFloat32(attr.Value)
-					case "matrixUnits":
+					case "markerUnits", "matrixUnits":
 						if attr.Value == "strokeWidth" {
 							mrk.Units = StrokeWidth
 						} else {
@@ -545,21 +566,157 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 				mrk.RefPos.Set(rx, ry)
 				mrk.Size.Set(szx, szy)
 			case nm == "use":
-				link := gi.XMLAttr("href", se.Attr)
-				itm := curPar.FindNamedElement(link)
-				if itm != nil {
-					cln := itm.Clone().(gi.Node2D)
-					if cln != nil {
-						curPar.AddChild(cln)
-						for _, attr := range se.Attr {
-							if cln.AsNode2D().SetStdXMLAttr(attr.Name.Local, attr.Value) {
-								continue
-							}
-							switch attr.Name.Local {
-							default:
-								cln.SetProp(attr.Name.Local, attr.Value)
-							}
+				use := curPar.AddNewChild(KiT_Use, "use").(*Use)
+				curLeaf = use
+				var x, y, w, h float32
+				for _, attr := range se.Attr {
+					if use.SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					switch attr.Name.Local {
+					case "x":
+						x, err = gi.ParseFloat32(attr.Value)
+					case "y":
+						y, err = gi.ParseFloat32(attr.Value)
+					case "width":
+						w, err = gi.ParseFloat32(attr.Value)
+					case "height":
+						h, err = gi.ParseFloat32(attr.Value)
+					case "href":
+						use.Href = attr.Value
+					default:
+						use.SetProp(attr.Name.Local, attr.Value)
+					}
+					if err != nil {
+						return err
+					}
+				}
+				use.Pos.Set(x, y)
+				use.Size.Set(w, h)
+			case nm == "symbol":
+				curPar = curPar.AddNewChild(KiT_Symbol, "symbol").(gi.Node2D)
+				sym := curPar.(*Symbol)
+				for _, attr := range se.Attr {
+					if sym.SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					switch attr.Name.Local {
+					case "viewBox":
+						pts := gi.ReadPoints(attr.Value)
+						if len(pts) != 4 {
+							return paramMismatchError
 						}
+						sym.ViewBox.Min.Set(pts[0], pts[1])
+						sym.ViewBox.Size.Set(pts[2], pts[3])
+					case "preserveAspectRatio":
+						err = sym.ViewBox.PreserveAspectRatio.SetString(attr.Value)
+					default:
+						sym.SetProp(attr.Name.Local, attr.Value)
+					}
+					if err != nil {
+						return err
+					}
+				}
+			case nm == "image":
+				img := curPar.AddNewChild(KiT_Image, "image").(*Image)
+				curLeaf = img
+				var x, y, w, h float32
+				for _, attr := range se.Attr {
+					if img.SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					switch attr.Name.Local {
+					case "x":
+						x, err = gi.ParseFloat32(attr.Value)
+					case "y":
+						y, err = gi.ParseFloat32(attr.Value)
+					case "width":
+						w, err = gi.ParseFloat32(attr.Value)
+					case "height":
+						h, err = gi.ParseFloat32(attr.Value)
+					case "href":
+						img.Href = attr.Value
+					case "preserveAspectRatio":
+						err = img.PreserveAspectRatio.SetString(attr.Value)
+					default:
+						img.SetProp(attr.Name.Local, attr.Value)
+					}
+					if err != nil {
+						return err
+					}
+				}
+				img.Pos.Set(x, y)
+				img.Size.Set(w, h)
+			case nm == "animate":
+				fallthrough
+			case nm == "set":
+				fallthrough
+			case nm == "animateTransform":
+				var an *Animate
+				var at *AnimateTransform
+				switch nm {
+				case "set":
+					an = &animPar().AddNewChild(KiT_Set, "set").(*Set).Animate
+				case "animateTransform":
+					at = animPar().AddNewChild(KiT_AnimateTransform, "animateTransform").(*AnimateTransform)
+					an = &at.Animate
+				default:
+					an = animPar().AddNewChild(KiT_Animate, "animate").(*Animate)
+				}
+				for _, attr := range se.Attr {
+					if an.SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					if ok, aerr := SetAnimXMLAttr(&an.AnimBase, attr.Name.Local, attr.Value); ok {
+						if aerr != nil {
+							return aerr
+						}
+						continue
+					}
+					switch attr.Name.Local {
+					case "attributeName":
+						an.AttributeName = attr.Value
+					case "attributeType":
+					case "type":
+						if at != nil {
+							at.Type = attr.Value
+						}
+					default:
+						an.SetProp(attr.Name.Local, attr.Value)
+					}
+				}
+			case nm == "animateMotion":
+				curMotion = animPar().AddNewChild(KiT_AnimateMotion, "animateMotion").(*AnimateMotion)
+				for _, attr := range se.Attr {
+					if curMotion.SetStdXMLAttr(attr.Name.Local, attr.Value) {
+						continue
+					}
+					if ok, aerr := SetAnimXMLAttr(&curMotion.AnimBase, attr.Name.Local, attr.Value); ok {
+						if aerr != nil {
+							return aerr
+						}
+						continue
+					}
+					switch attr.Name.Local {
+					case "path":
+						err = curMotion.SetPath(attr.Value)
+					case "rotate":
+						curMotion.Rotate = attr.Value
+					default:
+						curMotion.SetProp(attr.Name.Local, attr.Value)
+					}
+					if err != nil {
+						return err
+					}
+				}
+			case nm == "mpath":
+				if curMotion == nil {
+					log.Printf("gi.SVG mpath element not within animateMotion\n")
+					break
+				}
+				for _, attr := range se.Attr {
+					if attr.Name.Local == "href" {
+						curMotion.MPath = attr.Value
 					}
 				}
 			case nm == "Work":
@@ -638,24 +795,29 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 				inCSS = false
 				curCSS = nil
 			case "text":
+				if curTxt != nil {
+					curTxt.TrimXMLSpaceRight()
+				}
 				inTxt = false
 				curTxt = nil
 			case "tspan":
 				inTspn = false
 				curTspn = nil
+			case "textPath":
+				curTxtPath = nil
 			case "defs":
 				if inDef {
 					inDef = false
 					curPar = defPrevPar
 				}
-			case "rect":
-			case "circle":
-			case "ellipse":
-			case "line":
-			case "polygon":
-			case "polyline":
-			case "path":
-			case "use":
+			case "rect", "circle", "ellipse", "line", "polygon", "polyline", "path", "use", "image":
+				curLeaf = nil
+			case "animate":
+			case "set":
+			case "animateTransform":
+			case "animateMotion":
+				curMotion = nil
+			case "mpath":
 			case "linearGradient":
 			case "radialGradient":
 			default:
@@ -685,9 +847,18 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 			case inDesc:
 				curSvg.Desc += trspc
 			case inTspn && curTspn != nil:
-				curTspn.Text = trspc
+				if curTspn.Text != "" && trspc == "" { // e.g., around animation elements
+					break
+				}
+				if len(curTspn.CharPosX) > 0 { // positioned: not part of flow
+					curTspn.Text += trspc
+				} else {
+					curTspn.Text += TextXMLSpace(string(se), false)
+				}
+			case curTxtPath != nil:
+				curTxtPath.AddXMLText(string(se))
 			case inTxt && curTxt != nil:
-				curTxt.Text = trspc
+				curTxt.AddXMLText(string(se))
 			case inCSS && curCSS != nil:
 				curCSS.ParseString(trspc)
 				cp := curCSS.CSSProps()
@@ -703,3 +874,542 @@ func (svg *SVG) UnmarshalXML(decoder *xml.Decoder, se xml.StartElement) error {
 	}
 	return nil
 }
+
+////////////////////////////////////////////////////////////////////////////////////
+//  Writing
+
+// SaveXML saves the svg to a XML-encoded file, using WriteXML, and sets
+// Filename to it
+func (svg *SVG) SaveXML(filename string) error {
+	fp, err := os.Create(filename)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer fp.Close()
+	bw := bufio.NewWriter(fp)
+	err = svg.WriteXML(bw, true)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = bw.Flush()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	svg.Filename = filename
+	return nil
+}
+
+// WriteXML writes XML-formatted SVG output to io.Writer, and uses
+// XMLEncoder -- indent adds newlines and indentation between elements
+func (svg *SVG) WriteXML(writer io.Writer, indent bool) error {
+	enc := xml.NewEncoder(writer)
+	if indent {
+		enc.Indent("", "  ")
+	}
+	err := enc.EncodeToken(xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8" standalone="no"`)})
+	if err != nil {
+		return err
+	}
+	if indent {
+		enc.EncodeToken(xml.CharData("\n"))
+	}
+	err = svg.MarshalXML(enc, xml.StartElement{Name: xml.Name{Local: "svg"}})
+	if err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// MarshalXML marshals the svg and all of its elements (including defs) using
+// xml.Encoder, as the inverse of UnmarshalXML -- element ids, classes and all
+// properties (including transforms and any attributes not otherwise
+// processed when reading) are written as attributes -- animated values are
+// restored while writing, so the original values are written
+func (svg *SVG) MarshalXML(enc *xml.Encoder, se xml.StartElement) error {
+	se.Attr = append(se.Attr, gi.NewXMLAttr("xmlns", "http://www.w3.org/2000/svg"),
+		gi.NewXMLAttr("xmlns:xlink", "http://www.w3.org/1999/xlink"))
+	if svg.RestoreAnims() {
+		defer svg.ApplyAnims()
+	}
+	return MarshalXMLTree(svg.This().(gi.Node2D), enc, se)
+}
+
+// svgElDefNames are the default names given to nodes by UnmarshalXML for
+// each element type -- ids are only written for other names
+var svgElDefNames = map[string]string{
+	"text":           "txt",
+	"linearGradient": "lin-grad",
+	"radialGradient": "rad-grad",
+	"clipPath":       "clip-path",
+}
+
+// svgWidgetProps are properties of SVG nodes that are used for the layout
+// and styling of the svg as a widget, or written from the SVG fields, and
+// are not written as attributes
+var svgWidgetProps = map[string]bool{
+	"width":            true,
+	"height":           true,
+	"viewBox":          true,
+	"transform":        true,
+	"background-color": true,
+	"norm":             true,
+	"invert-y":         true,
+	"xmlns":            true,
+	"xlink":            true,
+}
+
+// MarshalXMLTree writes the given svg node and all of its children as XML
+// elements, using any attributes in the start element -- nodes of types that
+// have no XML representation are skipped.
+func MarshalXMLTree(gii gi.Node2D, enc *xml.Encoder, se xml.StartElement) error {
+	nm := XMLElName(gii)
+	if nm == "" {
+		log.Printf("svg.MarshalXMLTree: no svg element for node %v of type %v -- skipped\n", gii.PathUnique(), gii.Type().Name())
+		return nil
+	}
+	se.Name.Local = nm
+	nb := gii.AsNode2D()
+	if dn, ok := svgElDefNames[nm]; nb.Nm != nm && (!ok || nb.Nm != dn) {
+		se.Attr = append(se.Attr, gi.NewXMLAttr("id", nb.Nm))
+	}
+	if nb.Class != "" && nb.Class != nm {
+		se.Attr = append(se.Attr, gi.NewXMLAttr("class", nb.Class))
+	}
+
+	if grad, ok := gii.(*gi.Gradient); ok {
+		return grad.Grad.MarshalXML(enc, se)
+	}
+
+	var chars string
+	switch g := gii.(type) {
+	case *gi.StyleSheet:
+		if g.Sheet != nil {
+			chars = g.Sheet.String()
+		}
+	case *Text:
+		se.Attr = append(se.Attr, textXMLAttrs(g)...)
+		chars = g.Text
+	case *TextPath:
+		se.Attr = append(se.Attr, NodeXMLAttrs(gii)...)
+		se.Attr = append(se.Attr, textXMLAttrs(&g.Text)...)
+		chars = g.Text.Text
+	default:
+		se.Attr = append(se.Attr, NodeXMLAttrs(gii)...)
+	}
+	se.Attr = append(se.Attr, propsXMLAttrs(gii)...)
+	if err := enc.EncodeToken(se); err != nil {
+		return err
+	}
+
+	if svk := gii.Embed(KiT_SVG); svk != nil {
+		sv := svk.(*SVG)
+		if err := encodeXMLCharEl(enc, "title", sv.Title); err != nil {
+			return err
+		}
+		if err := encodeXMLCharEl(enc, "desc", sv.Desc); err != nil {
+			return err
+		}
+		if sv.Defs.HasChildren() {
+			ds := xml.StartElement{Name: xml.Name{Local: "defs"}}
+			if err := enc.EncodeToken(ds); err != nil {
+				return err
+			}
+			if err := marshalXMLChildren(&sv.Defs.Node2DBase, enc); err != nil {
+				return err
+			}
+			if err := enc.EncodeToken(ds.End()); err != nil {
+				return err
+			}
+		}
+	}
+	if chars != "" {
+		if err := enc.EncodeToken(xml.CharData(chars)); err != nil {
+			return err
+		}
+	}
+	if am, ok := gii.(*AnimateMotion); ok && am.MPath != "" {
+		ms := xml.StartElement{Name: xml.Name{Local: "mpath"}, Attr: []xml.Attr{gi.NewXMLAttr("xlink:href", am.MPath)}}
+		if err := enc.EncodeToken(ms); err != nil {
+			return err
+		}
+		if err := enc.EncodeToken(ms.End()); err != nil {
+			return err
+		}
+	}
+	if err := marshalXMLChildren(nb, enc); err != nil {
+		return err
+	}
+	return enc.EncodeToken(se.End())
+}
+
+// marshalXMLChildren calls MarshalXMLTree on each of the children of node
+func marshalXMLChildren(nb *gi.Node2DBase, enc *xml.Encoder) error {
+	for _, kid := range nb.Kids {
+		kii, _ := gi.KiToNode2D(kid)
+		if kii == nil {
+			continue
+		}
+		if err := MarshalXMLTree(kii, enc, xml.StartElement{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeXMLCharEl encodes an element with given name containing only
+// given character data, if non-empty
+func encodeXMLCharEl(enc *xml.Encoder, name, chars string) error {
+	if chars == "" {
+		return nil
+	}
+	return enc.EncodeElement(chars, xml.StartElement{Name: xml.Name{Local: name}})
+}
+
+// XMLElName returns the name of the svg XML element for given node, or ""
+// if it has none
+func XMLElName(gii gi.Node2D) string {
+	switch g := gii.(type) {
+	case *gi.MetaData2D:
+		return g.Class
+	case *Flow:
+		return g.FlowType
+	case *Filter:
+		return g.FilterType
+	case *Text:
+		if g.IsParText() {
+			return "tspan"
+		}
+		return "text"
+	case *TextPath:
+		return "textPath"
+	case *gi.Gradient:
+		if g.Grad.Source == gi.RadialGradient {
+			return "radialGradient"
+		}
+		return "linearGradient"
+	case *gi.StyleSheet:
+		return "style"
+	}
+	if gii.TypeEmbeds(KiT_SVG) {
+		return "svg"
+	}
+	switch gii.(type) {
+	case *Group:
+		return "g"
+	case *Rect:
+		return "rect"
+	case *Circle:
+		return "circle"
+	case *Ellipse:
+		return "ellipse"
+	case *Line:
+		return "line"
+	case *Polygon:
+		return "polygon"
+	case *Polyline:
+		return "polyline"
+	case *Path:
+		return "path"
+	case *ClipPath:
+		return "clipPath"
+	case *Marker:
+		return "marker"
+	case *Use:
+		return "use"
+	case *Symbol:
+		return "symbol"
+	case *Image:
+		return "image"
+	case *Animate:
+		return "animate"
+	case *Set:
+		return "set"
+	case *AnimateTransform:
+		return "animateTransform"
+	case *AnimateMotion:
+		return "animateMotion"
+	}
+	return ""
+}
+
+// NodeXMLAttrs returns the XML attributes for the fields of given svg node
+// (not including its properties), for all node types other than Text
+func NodeXMLAttrs(gii gi.Node2D) []xml.Attr {
+	var attrs []xml.Attr
+	switch g := gii.(type) {
+	case *Rect:
+		attrs = append(attrs, gi.NewXMLAttr("x", gi.FmtFloat32(g.Pos.X)), gi.NewXMLAttr("y", gi.FmtFloat32(g.Pos.Y)),
+			gi.NewXMLAttr("width", gi.FmtFloat32(g.Size.X)), gi.NewXMLAttr("height", gi.FmtFloat32(g.Size.Y)))
+		if g.Radius.X != 0 {
+			attrs = append(attrs, gi.NewXMLAttr("rx", gi.FmtFloat32(g.Radius.X)))
+		}
+		if g.Radius.Y != 0 {
+			attrs = append(attrs, gi.NewXMLAttr("ry", gi.FmtFloat32(g.Radius.Y)))
+		}
+	case *Circle:
+		attrs = append(attrs, gi.NewXMLAttr("cx", gi.FmtFloat32(g.Pos.X)), gi.NewXMLAttr("cy", gi.FmtFloat32(g.Pos.Y)),
+			gi.NewXMLAttr("r", gi.FmtFloat32(g.Radius)))
+	case *Ellipse:
+		attrs = append(attrs, gi.NewXMLAttr("cx", gi.FmtFloat32(g.Pos.X)), gi.NewXMLAttr("cy", gi.FmtFloat32(g.Pos.Y)),
+			gi.NewXMLAttr("rx", gi.FmtFloat32(g.Radii.X)), gi.NewXMLAttr("ry", gi.FmtFloat32(g.Radii.Y)))
+	case *Line:
+		attrs = append(attrs, gi.NewXMLAttr("x1", gi.FmtFloat32(g.Start.X)), gi.NewXMLAttr("y1", gi.FmtFloat32(g.Start.Y)),
+			gi.NewXMLAttr("x2", gi.FmtFloat32(g.End.X)), gi.NewXMLAttr("y2", gi.FmtFloat32(g.End.Y)))
+	case *Polygon:
+		attrs = append(attrs, gi.NewXMLAttr("points", fmtPoints(g.Points)))
+	case *Polyline:
+		attrs = append(attrs, gi.NewXMLAttr("points", fmtPoints(g.Points)))
+	case *Path:
+		attrs = append(attrs, gi.NewXMLAttr("d", PathDataString(g.Data)))
+	case *TextPath:
+		attrs = append(attrs, gi.NewXMLAttr("xlink:href", g.Href))
+		if g.StartOffsetPct {
+			attrs = append(attrs, gi.NewXMLAttr("startOffset", gi.FmtFloat32(g.StartOffset)+"%"))
+		} else if g.StartOffset != 0 {
+			attrs = append(attrs, gi.NewXMLAttr("startOffset", gi.FmtFloat32(g.StartOffset)))
+		}
+	case *Use:
+		attrs = append(attrs, gi.NewXMLAttr("xlink:href", g.Href))
+		if !g.Pos.IsZero() {
+			attrs = append(attrs, gi.NewXMLAttr("x", gi.FmtFloat32(g.Pos.X)), gi.NewXMLAttr("y", gi.FmtFloat32(g.Pos.Y)))
+		}
+		if !g.Size.IsZero() {
+			attrs = append(attrs, gi.NewXMLAttr("width", gi.FmtFloat32(g.Size.X)), gi.NewXMLAttr("height", gi.FmtFloat32(g.Size.Y)))
+		}
+	case *Symbol:
+		if !g.ViewBox.Size.IsZero() {
+			attrs = append(attrs, gi.NewXMLAttr("viewBox", g.ViewBox.String()))
+		}
+		if !g.ViewBox.PreserveAspectRatio.IsDefault() {
+			attrs = append(attrs, gi.NewXMLAttr("preserveAspectRatio", g.ViewBox.PreserveAspectRatio.String()))
+		}
+	case *Image:
+		attrs = append(attrs, gi.NewXMLAttr("x", gi.FmtFloat32(g.Pos.X)), gi.NewXMLAttr("y", gi.FmtFloat32(g.Pos.Y)))
+		if !g.Size.IsZero() {
+			attrs = append(attrs, gi.NewXMLAttr("width", gi.FmtFloat32(g.Size.X)), gi.NewXMLAttr("height", gi.FmtFloat32(g.Size.Y)))
+		}
+		if !g.PreserveAspectRatio.IsDefault() {
+			attrs = append(attrs, gi.NewXMLAttr("preserveAspectRatio", g.PreserveAspectRatio.String()))
+		}
+		attrs = append(attrs, gi.NewXMLAttr("xlink:href", g.Href))
+	case *ClipPath:
+		if g.Units == ClipObjectBoundingBox {
+			attrs = append(attrs, gi.NewXMLAttr("clipPathUnits", "objectBoundingBox"))
+		}
+	case *Marker:
+		if !g.ViewBox.Size.IsZero() {
+			attrs = append(attrs, gi.NewXMLAttr("viewBox", g.ViewBox.String()))
+		}
+		attrs = append(attrs, gi.NewXMLAttr("refX", gi.FmtFloat32(g.RefPos.X)), gi.NewXMLAttr("refY", gi.FmtFloat32(g.RefPos.Y)),
+			gi.NewXMLAttr("markerWidth", gi.FmtFloat32(g.Size.X)), gi.NewXMLAttr("markerHeight", gi.FmtFloat32(g.Size.Y)))
+		if g.Units == UserSpaceOnUse {
+			attrs = append(attrs, gi.NewXMLAttr("markerUnits", "userSpaceOnUse"))
+		}
+		if g.Orient != "" {
+			attrs = append(attrs, gi.NewXMLAttr("orient", g.Orient))
+		}
+	case *Animate:
+		attrs = append(attrs, gi.NewXMLAttr("attributeName", g.AttributeName))
+		attrs = append(attrs, animXMLAttrs(&g.AnimBase)...)
+	case *Set:
+		attrs = append(attrs, gi.NewXMLAttr("attributeName", g.AttributeName))
+		attrs = append(attrs, animXMLAttrs(&g.AnimBase)...)
+	case *AnimateTransform:
+		an := g.AttributeName
+		if an == "" {
+			an = "transform"
+		}
+		attrs = append(attrs, gi.NewXMLAttr("attributeName", an), gi.NewXMLAttr("type", g.Type))
+		attrs = append(attrs, animXMLAttrs(&g.AnimBase)...)
+	case *AnimateMotion:
+		attrs = append(attrs, animXMLAttrs(&g.AnimBase)...)
+		if len(g.Path) > 0 {
+			attrs = append(attrs, gi.NewXMLAttr("path", PathDataString(g.Path)))
+		}
+		if g.Rotate != "" {
+			attrs = append(attrs, gi.NewXMLAttr("rotate", g.Rotate))
+		}
+	default:
+		if sv := gii.Embed(KiT_SVG); sv != nil {
+			vb := &sv.(*SVG).ViewBox
+			if !vb.Size.IsZero() {
+				attrs = append(attrs, gi.NewXMLAttr("viewBox", vb.String()),
+					gi.NewXMLAttr("width", gi.FmtFloat32(vb.Size.X)), gi.NewXMLAttr("height", gi.FmtFloat32(vb.Size.Y)))
+			}
+		}
+	}
+	return attrs
+}
+
+// SetTextXMLAttr sets the text positioning field of given Text (or tspan,
+// textPath) corresponding to given XML attribute, returning false if it is
+// not such an attribute -- for tspans, a single x or y value is an explicit
+// position for the first character
+func SetTextXMLAttr(txt *Text, name, val string) bool {
+	switch name {
+	case "x":
+		pts := gi.ReadPoints(val)
+		if len(pts) > 1 || (len(pts) == 1 && txt.IsParText()) {
+			txt.CharPosX = pts
+		}
+		if len(pts) > 0 && !txt.IsParText() {
+			txt.Pos.X = pts[0]
+		}
+	case "y":
+		pts := gi.ReadPoints(val)
+		if len(pts) > 1 || (len(pts) == 1 && txt.IsParText()) {
+			txt.CharPosY = pts
+		}
+		if len(pts) > 0 && !txt.IsParText() {
+			txt.Pos.Y = pts[0]
+		}
+	case "dx":
+		pts := gi.ReadPoints(val)
+		if len(pts) > 0 {
+			txt.CharPosDX = pts
+		}
+	case "dy":
+		pts := gi.ReadPoints(val)
+		if len(pts) > 0 {
+			txt.CharPosDY = pts
+		}
+	case "rotate":
+		pts := gi.ReadPoints(val)
+		if len(pts) > 0 {
+			txt.CharRots = pts
+		}
+	case "textLength":
+		tl, err := gi.ParseFloat32(val)
+		if err == nil {
+			txt.TextLength = tl
+		}
+	case "lengthAdjust":
+		txt.AdjustGlyphs = (val == "spacingAndGlyphs")
+	default:
+		return false
+	}
+	return true
+}
+
+// textXMLAttrs returns the XML attributes for the fields of a Text node
+func textXMLAttrs(g *Text) []xml.Attr {
+	var attrs []xml.Attr
+	par := g.IsParText() // tspans only have explicit positions
+	if len(g.CharPosX) > 0 {
+		attrs = append(attrs, gi.NewXMLAttr("x", fmtFloats(g.CharPosX)))
+	} else if !par {
+		attrs = append(attrs, gi.NewXMLAttr("x", gi.FmtFloat32(g.Pos.X)))
+	}
+	if len(g.CharPosY) > 0 {
+		attrs = append(attrs, gi.NewXMLAttr("y", fmtFloats(g.CharPosY)))
+	} else if !par {
+		attrs = append(attrs, gi.NewXMLAttr("y", gi.FmtFloat32(g.Pos.Y)))
+	}
+	if len(g.CharPosDX) > 0 {
+		attrs = append(attrs, gi.NewXMLAttr("dx", fmtFloats(g.CharPosDX)))
+	}
+	if len(g.CharPosDY) > 0 {
+		attrs = append(attrs, gi.NewXMLAttr("dy", fmtFloats(g.CharPosDY)))
+	}
+	if len(g.CharRots) > 0 {
+		attrs = append(attrs, gi.NewXMLAttr("rotate", fmtFloats(g.CharRots)))
+	}
+	if g.TextLength != 0 {
+		attrs = append(attrs, gi.NewXMLAttr("textLength", gi.FmtFloat32(g.TextLength)))
+		if g.AdjustGlyphs {
+			attrs = append(attrs, gi.NewXMLAttr("lengthAdjust", "spacingAndGlyphs"))
+		}
+	}
+	return attrs
+}
+
+// propsXMLAttrs returns the properties of the node as XML attributes, in
+// sorted order -- references to other nodes (e.g., markers) are written as
+// url(#id) references
+func propsXMLAttrs(gii gi.Node2D) []xml.Attr {
+	props := *gii.Properties()
+	if len(props) == 0 {
+		return nil
+	}
+	isSVG := gii.TypeEmbeds(KiT_SVG)
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		if isSVG && svgWidgetProps[k] {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]xml.Attr, 0, len(keys))
+	for _, k := range keys {
+		var val string
+		switch pv := props[k].(type) {
+		case string:
+			val = pv
+		case ki.Ki:
+			val = "url(#" + pv.Name() + ")"
+		default:
+			val = kit.ToString(pv)
+		}
+		attrs = append(attrs, gi.NewXMLAttr(k, val))
+	}
+	return attrs
+}
+
+// PathDataString returns the standard svg string representation of the path
+// data, which can be parsed by PathDataParse
+func PathDataString(data []PathData) string {
+	var sb strings.Builder
+	sz := len(data)
+	for i := 0; i < sz; {
+		cmd, n := PathDataNextCmd(data, &i)
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteRune(PathCmdRune(cmd))
+		for np := 0; np < n && i < sz; np++ {
+			if np > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(gi.FmtFloat32(PathDataNext(data, &i)))
+		}
+	}
+	return sb.String()
+}
+
+// PathCmdRune returns the rune for given path command -- the inverse of
+// PathDecodeCmd
+func PathCmdRune(cmd PathCmds) rune {
+	for r, c := range PathCmdMap {
+		if c == cmd {
+			return r
+		}
+	}
+	return '?'
+}
+
+// String returns the viewbox as the standard svg viewBox attribute value
+func (vb *ViewBox) String() string {
+	return gi.FmtFloat32(vb.Min.X) + " " + gi.FmtFloat32(vb.Min.Y) + " " + gi.FmtFloat32(vb.Size.X) + " " + gi.FmtFloat32(vb.Size.Y)
+}
+
+// fmtFloats formats a list of floats separated by spaces
+func fmtFloats(vals []float32) string {
+	strs := make([]string, len(vals))
+	for i, v := range vals {
+		strs[i] = gi.FmtFloat32(v)
+	}
+	return strings.Join(strs, " ")
+}
+
+// fmtPoints formats a list of points as x,y pairs separated by spaces
+func fmtPoints(pts []gi.Vec2D) string {
+	strs := make([]string, len(pts))
+	for i, p := range pts {
+		strs[i] = gi.FmtFloat32(p.X) + "," + gi.FmtFloat32(p.Y)
+	}
+	return strings.Join(strs, " ")
+}
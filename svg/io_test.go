@@ -0,0 +1,151 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// propsString returns the properties of node as a string, sorted by key
+func propsString(k ki.Ki) string {
+	props := *k.Properties()
+	keys := make([]string, 0, len(props))
+	for pk := range props {
+		keys = append(keys, pk)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, pk := range keys {
+		fmt.Fprintf(&sb, " %v: %v", pk, kit.ToString(props[pk]))
+	}
+	return sb.String()
+}
+
+// nodeDiff returns a description of the first difference between nodes a and
+// b and all of their children (and defs), or "" if there is none -- the type,
+// name, class and properties are compared, and the exported fields of each
+// node type that are not embedded, tagged xml:"-", or nodes themselves
+func nodeDiff(a, b ki.Ki) string {
+	gia, na := gi.KiToNode2D(a)
+	gib, nb := gi.KiToNode2D(b)
+	if gia == nil || gib == nil {
+		return ""
+	}
+	if a.Type() != b.Type() || a.Name() != b.Name() || na.Class != nb.Class {
+		return fmt.Sprintf("%v %v class: %v != %v %v class: %v", a.PathUnique(), a.Type().Name(), na.Class,
+			b.PathUnique(), b.Type().Name(), nb.Class)
+	}
+	if pa, pb := propsString(a), propsString(b); pa != pb {
+		return fmt.Sprintf("%v props:%v != %v", a.PathUnique(), pa, pb)
+	}
+	va := reflect.ValueOf(a).Elem()
+	vb := reflect.ValueOf(b).Elem()
+	typ := va.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Anonymous || f.PkgPath != "" || f.Tag.Get("xml") == "-" {
+			continue
+		}
+		fa := va.Field(i)
+		if _, isKi := fa.Addr().Interface().(ki.Ki); isKi {
+			continue
+		}
+		if fb := vb.Field(i); !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			return fmt.Sprintf("%v %v: %v != %v", a.PathUnique(), f.Name, fa.Interface(), fb.Interface())
+		}
+	}
+	if sva := a.Embed(KiT_SVG); sva != nil {
+		da, db := sva.(*SVG).Defs.Kids, b.Embed(KiT_SVG).(*SVG).Defs.Kids
+		if len(da) != len(db) {
+			return fmt.Sprintf("%v number of defs: %v != %v", a.PathUnique(), len(da), len(db))
+		}
+		for i := range da {
+			if d := nodeDiff(da[i], db[i]); d != "" {
+				return d
+			}
+		}
+	}
+	ka, kb := *a.Children(), *b.Children()
+	if len(ka) != len(kb) {
+		return fmt.Sprintf("%v number of children: %v != %v", a.PathUnique(), len(ka), len(kb))
+	}
+	for i := range ka {
+		if d := nodeDiff(ka[i], kb[i]); d != "" {
+			return d
+		}
+	}
+	return ""
+}
+
+func TestSVGRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "examples", "svg", "*.svg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no svg test files found in examples/svg")
+	}
+	for _, fn := range files {
+		sv1 := &SVG{}
+		sv1.InitName(sv1, "svg")
+		if err := sv1.OpenXML(fn); err != nil {
+			t.Errorf("%v: open error: %v", fn, err)
+			continue
+		}
+		var b1 bytes.Buffer
+		if err := sv1.WriteXML(&b1, true); err != nil {
+			t.Errorf("%v: write error: %v", fn, err)
+			continue
+		}
+
+		sv2 := &SVG{}
+		sv2.InitName(sv2, "svg")
+		if err := sv2.ReadXML(bytes.NewReader(b1.Bytes())); err != nil {
+			t.Errorf("%v: error reading written svg: %v", fn, err)
+			continue
+		}
+		if d := nodeDiff(sv1.This(), sv2.This()); d != "" {
+			t.Errorf("%v: node differs after round-trip: original != read: %v", fn, d)
+		}
+
+		var b2 bytes.Buffer
+		if err := sv2.WriteXML(&b2, true); err != nil {
+			t.Errorf("%v: write error: %v", fn, err)
+			continue
+		}
+		if b1.String() != b2.String() {
+			t.Errorf("%v: written svg differs after round-trip", fn)
+		}
+	}
+}
+
+func TestPathDataString(t *testing.T) {
+	pds := []string{
+		"M10,20 L30,40 Z",
+		"m-1.5,2.25 c1,2,3,4,5,6 s1,1,2,2 h10 v-10 z",
+		"M0,0 A25,26,-30,0,1,50,-25 Q1,2,3,4 T5,6",
+	}
+	for _, pd := range pds {
+		data, err := PathDataParse(pd)
+		if err != nil {
+			t.Errorf("parse error: %v on: %v", err, pd)
+			continue
+		}
+		str := PathDataString(data)
+		if str != pd {
+			t.Errorf("PathDataString: %v != original: %v", str, pd)
+		}
+	}
+}
@@ -25,6 +25,8 @@ func (g *Line) Render2D() {
 	}
 	pc := &g.Pnt
 	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
 	rs.Lock()
 	rs.PushXForm(pc.XForm)
 	pc.DrawLine(rs, g.Start.X, g.Start.Y, g.End.X, g.End.Y)
@@ -43,4 +45,14 @@ func (g *Line) Render2D() {
 
 	g.Render2DChildren()
 	rs.PopXFormLock()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
+}
+
+// LocalBBox returns the bounding box of the line in its user space
+func (g *Line) LocalBBox() (min, max gi.Vec2D) {
+	min, max = g.Start, g.Start
+	min.SetMin(g.End)
+	max.SetMax(g.End)
+	return
 }
@@ -191,11 +191,15 @@ func (g *NodeBase) Render2D() {
 	}
 	pc := &g.Pnt
 	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
 	rs.PushXFormLock(pc.XForm)
 	// render path elements, then compute bbox, then fill / stroke
 	g.ComputeBBoxSVG()
 	g.Render2DChildren()
 	rs.PopXFormLock()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
 }
 
 func (g *NodeBase) Move2D(delta image.Point, parBBox image.Rectangle) {
@@ -216,6 +220,100 @@ func (g *NodeBase) FindSVGURL(url string) gi.Node2D {
 	return rv
 }
 
+// ClipPathRef checks for a clip-path property, and if set, attempts to find
+// that clip path and return it
+func (g *NodeBase) ClipPathRef() *ClipPath {
+	cps, ok := g.Props["clip-path"]
+	if !ok {
+		return nil
+	}
+	cpnm, ok := cps.(string)
+	if !ok {
+		cp, ok := cps.(*ClipPath)
+		if !ok {
+			log.Printf("gi.svg clip-path property should be a string url or pointer to ClipPath element, instead is: %T\n", cps)
+			return nil
+		}
+		return cp
+	}
+	cpn := g.FindSVGURL(cpnm)
+	if cpn != nil {
+		cp, ok := cpn.(*ClipPath)
+		if !ok {
+			log.Printf("gi.svg Found element named: %v but isn't a ClipPath type, instead is: %T", cpnm, cpn)
+			return nil
+		}
+		return cp
+	}
+	return nil
+}
+
+// PushClipPath applies the clip path referred to by the clip-path property,
+// if any: it pushes the current mask onto the clip stack and sets the mask
+// to its intersection with the mask rendered from the clip path.  Must be
+// called at the start of Render2D, prior to pushing our own transform and
+// outside of the render lock, and balanced by a call to PopClipPath with the
+// return value.
+func (g *NodeBase) PushClipPath() bool {
+	cp := g.ClipPathRef()
+	if cp == nil {
+		return false
+	}
+	var min, max gi.Vec2D
+	if cp.Units == ClipObjectBoundingBox {
+		if lb, ok := g.This().(LocalBBoxer); ok {
+			min, max = lb.LocalBBox()
+		} else {
+			log.Printf("gi.svg clip path: %v with objectBoundingBox units is not supported for element: %v of type: %T\n", cp.Nm, g.PathUnique(), g.This())
+		}
+	}
+	rs := &g.Viewport.Render
+	rs.PushXFormLock(g.Pnt.XForm)
+	mask := cp.RenderMask(rs, min, max)
+	rs.PopXFormLock()
+	if mask == nil {
+		return false
+	}
+	rs.Lock()
+	rs.PushClip()
+	g.Pnt.SetMask(rs, gi.IntersectMasks(rs.Mask, mask))
+	rs.Unlock()
+	return true
+}
+
+// PopClipPath restores the mask in effect prior to PushClipPath -- clipped
+// is the value returned by PushClipPath
+func (g *NodeBase) PopClipPath(clipped bool) {
+	if !clipped {
+		return
+	}
+	rs := &g.Viewport.Render
+	rs.Lock()
+	rs.PopClip()
+	rs.Unlock()
+}
+
+// LocalBBoxer is implemented by SVG nodes that can compute their bounding
+// box in their own user space, i.e., prior to their own transform -- needed
+// for clip paths with objectBoundingBox units
+type LocalBBoxer interface {
+	LocalBBox() (min, max gi.Vec2D)
+}
+
+// XFormBBox returns the bounding box of the given bounding box after
+// transforming it by given transform
+func XFormBBox(xf gi.Matrix2D, min, max gi.Vec2D) (gi.Vec2D, gi.Vec2D) {
+	pts := [4]gi.Vec2D{min, {max.X, min.Y}, max, {min.X, max.Y}}
+	tmin := xf.TransformPointVec2D(pts[0])
+	tmax := tmin
+	for _, pt := range pts[1:] {
+		tp := xf.TransformPointVec2D(pt)
+		tmin.SetMin(tp)
+		tmax.SetMax(tp)
+	}
+	return tmin, tmax
+}
+
 // Marker checks for a marker property of given name, or generic "marker"
 // type, and if set, attempts to find that marker and return it
 func (g *NodeBase) Marker(marker string) *Marker {
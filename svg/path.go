@@ -51,6 +51,8 @@ func (g *Path) Render2D() {
 
 	pc := &g.Pnt
 	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
 	rs.Lock()
 	rs.PushXForm(pc.XForm)
 	PathDataRender(g.Data, pc, rs)
@@ -91,6 +93,13 @@ func (g *Path) Render2D() {
 
 	g.Render2DChildren()
 	rs.PopXFormLock()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
+}
+
+// LocalBBox returns the bounding box of the path data in its user space
+func (g *Path) LocalBBox() (min, max gi.Vec2D) {
+	return PathDataMinMax(g.Data)
 }
 
 // PathCmds are the commands within the path SVG drawing data type
@@ -380,7 +389,7 @@ func PathDataIterFunc(data []PathData, fun func(idx int, cmd PathCmds, ptIdx int
 	if sz == 0 {
 		return
 	}
-	var cx, cy, x1, y1 float32
+	var stx, sty, cx, cy, x1, y1 float32
 	for i := 0; i < sz; {
 		cmd, n := PathDataNextCmd(data, &i)
 		rel := false
@@ -388,6 +397,7 @@ func PathDataIterFunc(data []PathData, fun func(idx int, cmd PathCmds, ptIdx int
 		case PcM:
 			cx = PathDataNext(data, &i)
 			cy = PathDataNext(data, &i)
+			stx, sty = cx, cy
 			if !fun(i-2, cmd, 0, cx, cy) {
 				return
 			}
@@ -401,7 +411,8 @@ func PathDataIterFunc(data []PathData, fun func(idx int, cmd PathCmds, ptIdx int
 		case Pcm:
 			cx += PathDataNext(data, &i)
 			cy += PathDataNext(data, &i)
-			if !fun(i, cmd, 0, cx, cy) {
+			stx, sty = cx, cy
+			if !fun(i-2, cmd, 0, cx, cy) {
 				return
 			}
 			for np := 1; np < n/2; np++ {
@@ -560,12 +571,82 @@ func PathDataIterFunc(data []PathData, fun func(idx int, cmd PathCmds, ptIdx int
 				}
 			}
 		case PcZ:
+			fallthrough
 		case Pcz:
+			cx, cy = stx, sty
 		}
 	}
 	return
 }
 
+// PathDataMovePoint moves the point of the path whose coordinates start at
+// given index in the data (as passed to PathDataIterFunc) by given amount,
+// leaving the rest of the path in place -- if the command is relative, the
+// following relative segment is adjusted so that it does not move along
+// with it
+func PathDataMovePoint(data []PathData, idx int, dx, dy float32) {
+	sz := len(data)
+	for i := 0; i < sz; {
+		cmd, n := PathDataNextCmd(data, &i)
+		st := i
+		i += n
+		if idx < st || idx >= i {
+			continue
+		}
+		pathDataMoveCoords(data, idx, cmd, dx, dy)
+		if !pathCmdIsRel(cmd) {
+			return
+		}
+		np := PathCmdNMap[cmd]
+		nxt := st + ((idx-st)/np+1)*np // start of next segment
+		ncmd := cmd
+		if nxt >= i {
+			if i >= sz {
+				return
+			}
+			ncmd, _ = data[i].Cmd()
+			nxt = i + 1
+		}
+		if !pathCmdIsRel(ncmd) {
+			return
+		}
+		switch ncmd {
+		case Pca:
+			pathDataMoveCoords(data, nxt+5, ncmd, -dx, -dy)
+		case Pcz:
+		default:
+			for c := 0; c < PathCmdNMap[ncmd]; c += 2 {
+				pathDataMoveCoords(data, nxt+c, ncmd, -dx, -dy)
+			}
+		}
+		return
+	}
+}
+
+// pathDataMoveCoords adds given amount to the coordinates at given index in
+// the data, for given command
+func pathDataMoveCoords(data []PathData, idx int, cmd PathCmds, dx, dy float32) {
+	switch cmd {
+	case PcH, Pch:
+		data[idx] += PathData(dx)
+	case PcV, Pcv:
+		data[idx] += PathData(dy)
+	default:
+		data[idx] += PathData(dx)
+		data[idx+1] += PathData(dy)
+	}
+}
+
+// pathCmdIsRel returns true if the command uses coordinates relative to the
+// current point (lower-case)
+func pathCmdIsRel(cmd PathCmds) bool {
+	switch cmd {
+	case Pcm, Pcl, Pch, Pcv, Pcc, Pcs, Pcq, Pct, Pca, Pcz:
+		return true
+	}
+	return false
+}
+
 // PathDataMinMax traverses the path data and extracts the min and max point coords
 func PathDataMinMax(data []PathData) (min, max gi.Vec2D) {
 	PathDataIterFunc(data, func(idx int, cmd PathCmds, ptIdx int, cx, cy float32) bool {
@@ -0,0 +1,45 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"testing"
+
+	"github.com/goki/gi/gi"
+)
+
+// pathDataPoints returns the points of the path, and their data indexes
+func pathDataPoints(data []PathData) ([]gi.Vec2D, []int) {
+	var pts []gi.Vec2D
+	var idxs []int
+	PathDataIterFunc(data, func(idx int, cmd PathCmds, ptIdx int, cx, cy float32) bool {
+		pts = append(pts, gi.Vec2D{cx, cy})
+		idxs = append(idxs, idx)
+		return true
+	})
+	return pts, idxs
+}
+
+func TestPathDataMovePoint(t *testing.T) {
+	for _, ps := range []string{"M 10 10 L 20 10 L 20 20 Z", "m 10 10 l 10 0 l 0 10 z", "M 10 10 h 10 l 0 10"} {
+		data, err := PathDataParse(ps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pts, idxs := pathDataPoints(data)
+		if len(pts) != 3 {
+			t.Errorf("%v: expected 3 points, got: %v", ps, pts)
+			continue
+		}
+		PathDataMovePoint(data, idxs[1], 5, 0)
+		npts, _ := pathDataPoints(data)
+		exp := []gi.Vec2D{{10, 10}, {25, 10}, {20, 20}}
+		for i := range exp {
+			if npts[i] != exp[i] {
+				t.Errorf("%v: after move, point %v expected: %v got: %v", ps, i, exp[i], npts[i])
+			}
+		}
+	}
+}
@@ -28,6 +28,8 @@ func (g *Polygon) Render2D() {
 	}
 	pc := &g.Pnt
 	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
 	rs.PushXForm(pc.XForm)
 	pc.DrawPolygon(rs, g.Points)
 	pc.FillStrokeClear(rs)
@@ -57,4 +59,11 @@ func (g *Polygon) Render2D() {
 
 	g.Render2DChildren()
 	rs.PopXForm()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
+}
+
+// LocalBBox returns the bounding box of the points in their user space
+func (g *Polygon) LocalBBox() (min, max gi.Vec2D) {
+	return PointsMinMax(g.Points)
 }
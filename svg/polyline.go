@@ -28,6 +28,8 @@ func (g *Polyline) Render2D() {
 	}
 	pc := &g.Pnt
 	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
 	rs.PushXForm(pc.XForm)
 	pc.DrawPolyline(rs, g.Points)
 	pc.FillStrokeClear(rs)
@@ -57,4 +59,24 @@ func (g *Polyline) Render2D() {
 
 	g.Render2DChildren()
 	rs.PopXForm()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
+}
+
+// LocalBBox returns the bounding box of the points in their user space
+func (g *Polyline) LocalBBox() (min, max gi.Vec2D) {
+	return PointsMinMax(g.Points)
+}
+
+// PointsMinMax returns the min and max of given points
+func PointsMinMax(pts []gi.Vec2D) (min, max gi.Vec2D) {
+	if len(pts) == 0 {
+		return
+	}
+	min, max = pts[0], pts[0]
+	for _, pt := range pts[1:] {
+		min.SetMin(pt)
+		max.SetMax(pt)
+	}
+	return
 }
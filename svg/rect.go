@@ -25,6 +25,8 @@ func (g *Rect) Render2D() {
 	}
 	pc := &g.Pnt
 	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
 	rs.PushXForm(pc.XForm)
 	if g.Radius.X == 0 && g.Radius.Y == 0 {
 		pc.DrawRectangle(rs, g.Pos.X, g.Pos.Y, g.Size.X, g.Size.Y)
@@ -36,4 +38,11 @@ func (g *Rect) Render2D() {
 	g.ComputeBBoxSVG()
 	g.Render2DChildren()
 	rs.PopXForm()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
+}
+
+// LocalBBox returns the bounding box of the rectangle in its user space
+func (g *Rect) LocalBBox() (min, max gi.Vec2D) {
+	return g.Pos, g.Pos.Add(g.Size)
 }
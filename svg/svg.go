@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
 	"github.com/goki/ki/kit"
 )
 
@@ -20,13 +21,15 @@ import (
 // svg tag in html -- it provides its own bitmap for drawing into
 type SVG struct {
 	gi.Viewport2D
-	ViewBox ViewBox  `desc:"viewbox defines the coordinate system for the drawing"`
-	Norm    bool     `desc:"prop: norm = install a transform that renormalizes so that the specified ViewBox exactly fits within the allocated SVG size"`
-	InvertY bool     `desc:"prop: invert-y = when doing Norm transform, also flip the Y axis so that the smallest Y value is at the bottom of the SVG box, instead of being at the top as it is by default"`
-	Pnt     gi.Paint `json:"-" xml:"-" desc:"paint styles -- inherited by nodes"`
-	Defs    Group    `desc:"all defs defined elements go here (gradients, symbols, etc)"`
-	Title   string   `xml:"title" desc:"the title of the svg"`
-	Desc    string   `xml:"desc" desc:"the description of the svg"`
+	ViewBox  ViewBox   `desc:"viewbox defines the coordinate system for the drawing"`
+	Norm     bool      `desc:"prop: norm = install a transform that renormalizes so that the specified ViewBox exactly fits within the allocated SVG size"`
+	InvertY  bool      `desc:"prop: invert-y = when doing Norm transform, also flip the Y axis so that the smallest Y value is at the bottom of the SVG box, instead of being at the top as it is by default"`
+	Pnt      gi.Paint  `json:"-" xml:"-" desc:"paint styles -- inherited by nodes"`
+	Defs     Group     `desc:"all defs defined elements go here (gradients, symbols, etc)"`
+	Title    string    `xml:"title" desc:"the title of the svg"`
+	Desc     string    `xml:"desc" desc:"the description of the svg"`
+	Filename string    `json:"-" xml:"-" desc:"file name of the svg, as last opened with OpenXML or saved with SaveXML -- relative links to external files (e.g., images) are relative to its directory"`
+	Clock    AnimClock `json:"-" xml:"-" desc:"clock for the animations (animate, set, animateTransform, animateMotion elements) in the svg"`
 }
 
 var KiT_SVG = kit.Types.AddType(&SVG{}, nil)
@@ -39,6 +42,7 @@ func (g *SVG) Paint() *gi.Paint {
 // DeleteAll deletes any existing elements in this svg
 func (svg *SVG) DeleteAll() {
 	updt := svg.UpdateStart()
+	svg.AnimReset()
 	svg.DeleteChildren(true)
 	svg.ViewBox.Defaults()
 	svg.Pnt.Defaults()
@@ -117,6 +121,7 @@ func (svg *SVG) Layout2D(parBBox image.Rectangle, iter int) bool {
 
 func (svg *SVG) Render2D() {
 	if svg.PushBounds() {
+		svg.AnimFrame()
 		rs := &svg.Render
 		if svg.Fill {
 			svg.FillViewport()
@@ -147,6 +152,21 @@ func (svg *SVG) FindNamedElement(name string) gi.Node2D {
 		return def.(gi.Node2D)
 	}
 
+	var fel gi.Node2D
+	svg.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if fel != nil {
+			return false
+		}
+		if k != svg.This() && k.Name() == name {
+			fel, _ = gi.KiToNode2D(k)
+			return fel == nil
+		}
+		return true
+	})
+	if fel != nil {
+		return fel
+	}
+
 	if svg.Par == nil {
 		log.Printf("gi.SVG FindNamedElement: could not find name: %v\n", name)
 		return nil
@@ -0,0 +1,23 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"github.com/goki/ki/kit"
+)
+
+// Symbol is a SVG symbol element, which defines a group of elements with
+// its own viewbox coordinate system -- it is not rendered directly, only
+// through Use elements that refer to it, which determine the viewport size
+type Symbol struct {
+	Group
+	ViewBox ViewBox `desc:"viewbox defines the internal coordinate system for the elements within the symbol"`
+}
+
+var KiT_Symbol = kit.Types.AddType(&Symbol{}, nil)
+
+// Render2D does nothing -- symbols are only rendered by Use elements
+func (g *Symbol) Render2D() {
+}
@@ -6,114 +6,443 @@ package svg
 
 import (
 	"image"
+	"strings"
+	"unicode"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/units"
+	"github.com/goki/ki"
 	"github.com/goki/ki/ints"
 	"github.com/goki/ki/kit"
 )
 
-// Text renders SVG text-- it handles both text and tspan elements (a tspan is
-// just nested under a parent text)
+// Text renders SVG text -- it handles text, tspan and textPath elements
+// (tspans and textPaths are nested under a parent text).  The parent text
+// element lays out all of the text within it as a single flow, glyph by
+// glyph: each tspan continues from where the previous text left off, unless
+// it specifies its own x, y positions.  Any text following a tspan within a
+// text element is represented by an additional (unpositioned) tspan.  Text
+// with a vertical writing-mode (e.g., tb-rl or vertical-rl) flows
+// top-to-bottom from its position, with CJK characters upright and others
+// rotated sideways, per glyph-orientation-vertical (not along a textPath).
 type Text struct {
 	NodeBase
-	Pos          gi.Vec2D      `xml:"{x,y}" desc:"position of the left, baseline of the text"`
+	Pos          gi.Vec2D      `xml:"{x,y}" desc:"position of the left, baseline of the text -- for vertical text, the top of the text on its center line -- only for the outer text element: tspans use CharPosX, CharPosY"`
 	Width        float32       `xml:"width" desc:"width of text to render if using word-wrapping"`
 	Text         string        `xml:"text" desc:"text string to render"`
 	Render       gi.TextRender `xml:"-" json:"-" desc:"render version of text"`
-	CharPosX     []float32     `desc:"character positions along X axis, if specified"`
-	CharPosY     []float32     `desc:"character positions along Y axis, if specified"`
+	CharPosX     []float32     `desc:"absolute character positions along X axis, if specified -- each such position starts a new chunk of text for text-anchor alignment"`
+	CharPosY     []float32     `desc:"absolute character positions along Y axis, if specified"`
 	CharPosDX    []float32     `desc:"character delta-positions along X axis, if specified"`
 	CharPosDY    []float32     `desc:"character delta-positions along Y axis, if specified"`
-	CharRots     []float32     `desc:"character rotations, if specified"`
-	TextLength   float32       `desc:"author's computed text length, if specified -- we attempt to match"`
+	CharRots     []float32     `desc:"character rotations in degrees, if specified -- the last value applies to all remaining characters"`
+	TextLength   float32       `desc:"author's computed text length, if specified -- the text, including any tspans within it, is stretched or compressed to match"`
 	AdjustGlyphs bool          `desc:"in attempting to match TextLength, should we adjust glyphs in addition to spacing?"`
+	glyphSt      int
+	glyphEd      int
+	layMin       gi.Vec2D
+	layMax       gi.Vec2D
+	laidOut      bool
 }
 
 var KiT_Text = kit.Types.AddType(&Text{}, nil)
 
+// textGlyph is the layout of one rune of text, in the user coordinates of
+// the outer text element
+type textGlyph struct {
+	txt    *Text    // element that the rune belongs to
+	idx    int      // index of rune within txt
+	pos    gi.Vec2D // position of the left, baseline of the rune -- for vertical text, the top of the rune on the center line
+	off    gi.Vec2D // offset from pos of the baseline rendering position, for vertical text
+	adv    float32  // advance width of the rune -- its height for vertical text
+	rot    float32  // rotation in radians, about pos
+	vrot   float32  // rotation of the rune in vertical text: sideways or upright
+	vert   bool     // vertical (top-to-bottom) text, per the writing-mode
+	scx    float32  // horizontal scaling from lengthAdjust, 0 = none
+	chunk  bool     // starts a new chunk of text, at an absolute position
+	onPath bool     // laid out along a textPath
+	hide   bool     // not rendered -- off the end of a textPath
+}
+
+// along returns the position of the glyph along its line of text: X, or Y
+// for vertical text
+func (gl *textGlyph) along() *float32 {
+	if gl.vert {
+		return &gl.pos.Y
+	}
+	return &gl.pos.X
+}
+
+// IsParText returns true if this is a tspan or textPath within a parent
+// text element, which lays out and renders all of the text within it
+func (g *Text) IsParText() bool {
+	if g.Par == nil {
+		return false
+	}
+	_, ok := g.Par.Embed(KiT_Text).(*Text)
+	return ok
+}
+
 func (g *Text) BBox2D() image.Rectangle {
 	rs := &g.Viewport.Render
-	// todo: could be much more accurate..
-	return g.Pnt.BoundingBox(rs, g.Pos.X, g.Pos.Y, g.Pos.X+g.Render.Size.X, g.Pos.Y+g.Render.Size.Y)
+	min, max := g.LocalBBox()
+	min, max = XFormBBox(rs.XForm, min, max)
+	return image.Rectangle{Min: min.ToPointFloor(), Max: max.ToPointCeil()}
 }
 
 func (g *Text) Render2D() {
 	if g.Viewport == nil {
 		g.This().(gi.Node2D).Init2D()
 	}
+	if g.IsParText() {
+		return // rendered by the outer text element
+	}
 	pc := &g.Pnt
 	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
 	rs.PushXForm(pc.XForm)
+	gls := g.layoutText()
+	g.renderGlyphs(rs, gls)
+	rs.PopXForm()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
+}
+
+// layoutText lays out all of the text within this text element, including
+// any tspans and textPaths, returning the position of each glyph
+func (g *Text) layoutText() []textGlyph {
+	var gls []textGlyph
+	cur := g.Pos
+	g.layoutNode(&gls, &cur)
+	anchorTextChunks(gls)
+	return gls
+}
+
+// layoutNode lays out the text of this element and then the text elements
+// within it, continuing from the current text position cur
+func (g *Text) layoutNode(gls *[]textGlyph, cur *gi.Vec2D) {
+	pc := &g.Pnt
+	tp, isPath := g.This().(*TextPath)
+	if isPath {
+		*cur = gi.Vec2D{} // X is distance along path, Y is offset from it
+	}
+	g.glyphSt = len(*gls)
+	g.Render.Spans = nil
 	if len(g.Text) > 0 {
-		orgsz := pc.FontStyle.Size
-		pos := rs.XForm.TransformPointVec2D(gi.Vec2D{g.Pos.X, g.Pos.Y})
-		rot := rs.XForm.ExtractRot()
-		scx, scy := rs.XForm.ExtractScale()
-		scalex := scx / scy
-		if scalex == 1 {
-			scalex = 0
-		}
 		pc.FontStyle.OpenFont(&pc.UnContext) // use original size font
 		if !pc.FillStyle.Color.IsNil() {
 			pc.FontStyle.Color = pc.FillStyle.Color.Color
 		}
-		g.Render.SetString(g.Text, &pc.FontStyle, &pc.UnContext, &pc.TextStyle, true, rot, scalex)
-		g.Render.Size = g.Render.Size.Mul(gi.Vec2D{scx, scy})
-		if gi.IsAlignMiddle(pc.TextStyle.Align) || pc.TextStyle.Anchor == gi.AnchorMiddle {
-			pos.X -= g.Render.Size.X * .5
-		} else if gi.IsAlignEnd(pc.TextStyle.Align) || pc.TextStyle.Anchor == gi.AnchorEnd {
-			pos.X -= g.Render.Size.X
-		}
-		pc.FontStyle.Size = units.Value{orgsz.Val * scy, orgsz.Un, orgsz.Dots * scy} // rescale by y
-		pc.FontStyle.OpenFont(&pc.UnContext)
+		g.Render.SetString(g.Text, &pc.FontStyle, &pc.UnContext, &pc.TextStyle, true, 0, 0)
 		sr := &(g.Render.Spans[0])
-		sr.Render[0].Face = pc.FontStyle.Face // upscale
-		for i := range sr.Render {
-			sr.Render[i].RelPos = rs.XForm.TransformVectorVec2D(sr.Render[i].RelPos)
-			sr.Render[i].Size.Y *= scy
-			sr.Render[i].Size.X *= scx
-		}
-		pc.FontStyle.Size = orgsz
-		if len(g.CharPosX) > 0 {
-			mx := ints.MinInt(len(g.CharPosX), len(sr.Render))
-			for i := 0; i < mx; i++ {
-				// todo: this may not be fully correct, given relativity constraints
-				sr.Render[i].RelPos.X, _ = rs.XForm.TransformVector(g.CharPosX[i], 0)
-			}
+		nr := len(sr.Render)
+		vert := pc.TextStyle.IsVertical()
+		var vpos []float32
+		if vert {
+			sr.SetRunePosTB(pc.TextStyle.LetterSpacing.Dots, pc.TextStyle.WordSpacing.Dots, pc.TextStyle.OrientationVert == 0)
+			vpos = sr.RunePosTB()
 		}
-		if len(g.CharPosY) > 0 {
-			mx := ints.MinInt(len(g.CharPosY), len(sr.Render))
-			for i := 0; i < mx; i++ {
-				_, sr.Render[i].RelPos.Y = rs.XForm.TransformPoint(g.CharPosY[i], 0)
+		for i := 0; i < nr; i++ {
+			gl := textGlyph{txt: g, idx: i, vert: vert}
+			if vert {
+				rr := &(sr.Render[i])
+				gl.adv = vpos[i+1] - vpos[i]
+				gl.off = gi.Vec2D{rr.RelPos.X, rr.RelPos.Y - vpos[i]}
+				gl.vrot = rr.RotRad
+			} else if i < nr-1 {
+				gl.adv = sr.Render[i+1].RelPos.X - sr.Render[i].RelPos.X
+			} else {
+				gl.adv = sr.LastPos.X - sr.Render[i].RelPos.X
+			}
+			if len(*gls) == 0 {
+				gl.chunk = true
+			}
+			if i < len(g.CharPosX) {
+				cur.X = g.CharPosX[i]
+				gl.chunk = true
+			}
+			if i < len(g.CharPosY) {
+				cur.Y = g.CharPosY[i]
+				gl.chunk = true
 			}
+			if i < len(g.CharPosDX) {
+				cur.X += g.CharPosDX[i]
+			}
+			if i < len(g.CharPosDY) {
+				cur.Y += g.CharPosDY[i]
+			}
+			if nrot := len(g.CharRots); nrot > 0 {
+				gl.rot = gi.Radians(g.CharRots[ints.MinInt(i, nrot-1)])
+			}
+			gl.pos = *cur
+			if vert {
+				cur.Y += gl.adv
+			} else {
+				cur.X += gl.adv
+			}
+			*gls = append(*gls, gl)
+		}
+	}
+	for _, kid := range g.Kids {
+		if kt, ok := kid.Embed(KiT_Text).(*Text); ok && kt != nil {
+			kt.layoutNode(gls, cur)
 		}
-		if len(g.CharPosDX) > 0 {
-			mx := ints.MinInt(len(g.CharPosDX), len(sr.Render))
-			for i := 0; i < mx; i++ {
-				dx, _ := rs.XForm.TransformVector(g.CharPosDX[i], 0)
-				if i > 0 {
-					sr.Render[i].RelPos.X = sr.Render[i-1].RelPos.X + dx
-				} else {
-					sr.Render[i].RelPos.X = dx // todo: not sure this is right
-				}
+	}
+	g.glyphEd = len(*gls)
+	if g.TextLength > 0 {
+		g.adjustLength((*gls)[g.glyphSt:g.glyphEd], cur)
+	}
+	if isPath {
+		tp.layoutOnPath((*gls)[g.glyphSt:g.glyphEd], cur)
+	}
+}
+
+// adjustLength adjusts the spacing (and glyphs, if AdjustGlyphs) of given
+// glyphs so that they span TextLength, updating the current text position
+func (g *Text) adjustLength(gls []textGlyph, cur *gi.Vec2D) {
+	n := len(gls)
+	if n == 0 {
+		return
+	}
+	x0 := *gls[0].along()
+	ln := *gls[n-1].along() + gls[n-1].adv - x0
+	if ln <= 0 {
+		return
+	}
+	if g.AdjustGlyphs {
+		sc := g.TextLength / ln
+		for i := range gls {
+			gl := &gls[i]
+			*gl.along() = x0 + (*gl.along()-x0)*sc
+			gl.adv *= sc
+			if gl.scx == 0 {
+				gl.scx = sc
+			} else {
+				gl.scx *= sc
 			}
 		}
-		if len(g.CharPosDY) > 0 {
-			mx := ints.MinInt(len(g.CharPosDY), len(sr.Render))
-			for i := 0; i < mx; i++ {
-				dy, _ := rs.XForm.TransformVector(g.CharPosDY[i], 0)
-				if i > 0 {
-					sr.Render[i].RelPos.Y = sr.Render[i-1].RelPos.Y + dy
-				} else {
-					sr.Render[i].RelPos.Y = dy // todo: not sure this is right
-				}
+	} else if n > 1 {
+		d := (g.TextLength - ln) / float32(n-1)
+		for i := range gls {
+			*gls[i].along() += float32(i) * d
+		}
+	}
+	if gls[0].vert {
+		cur.Y += g.TextLength - ln
+	} else {
+		cur.X += g.TextLength - ln
+	}
+}
+
+// TextAnchorFrac returns the proportion of the width of a chunk of text to
+// shift it to the left by, for the text-anchor (or text-align) of given paint
+func TextAnchorFrac(pc *gi.Paint) float32 {
+	switch {
+	case gi.IsAlignMiddle(pc.TextStyle.Align) || pc.TextStyle.Anchor == gi.AnchorMiddle:
+		return 0.5
+	case gi.IsAlignEnd(pc.TextStyle.Align) || pc.TextStyle.Anchor == gi.AnchorEnd:
+		return 1
+	}
+	return 0
+}
+
+// anchorTextChunks aligns each chunk of text, which starts at each glyph
+// with an absolute position, according to the text-anchor of the element
+// that it starts in -- text along a path is anchored by the textPath
+func anchorTextChunks(gls []textGlyph) {
+	n := len(gls)
+	for st := 0; st < n; {
+		if gls[st].onPath {
+			st++
+			continue
+		}
+		en := st + 1
+		for en < n && !gls[en].chunk && !gls[en].onPath {
+			en++
+		}
+		if frac := TextAnchorFrac(&gls[st].txt.Pnt); frac != 0 {
+			mn := *gls[st].along()
+			mx := mn + gls[st].adv
+			for i := st + 1; i < en; i++ {
+				mn = gi.Min32(mn, *gls[i].along())
+				mx = gi.Max32(mx, *gls[i].along()+gls[i].adv)
+			}
+			sh := -frac * (mx - mn)
+			for i := st; i < en; i++ {
+				*gls[i].along() += sh
 			}
 		}
-		// todo: TextLength, AdjustGlyphs -- also svg2 at least supports word wrapping!
-		g.Render.Render(rs, pos)
-		g.ComputeBBoxSVG()
+		st = en
 	}
-	g.Render2DChildren()
-	rs.PopXForm()
+}
+
+// renderGlyphs sets the rendering positions of each rune in this text
+// element and those within it from the given layout, using the current
+// transform, and renders them
+func (g *Text) renderGlyphs(rs *gi.RenderState, gls []textGlyph) {
+	xf := rs.XForm
+	rot := xf.ExtractRot()
+	scx, scy := xf.ExtractScale()
+	for _, gl := range gls {
+		rr := &(gl.txt.Render.Spans[0].Render[gl.idx])
+		rr.RelPos = xf.TransformPointVec2D(gl.pos.Add(gl.off))
+		rr.RotRad = rot + gl.rot + gl.vrot
+		sx := scx / scy
+		if gl.scx != 0 {
+			sx *= gl.scx
+		}
+		if sx == 1 {
+			sx = 0
+		}
+		rr.ScaleX = sx
+		rr.Size.X *= scx
+		rr.Size.Y *= scy
+	}
+	g.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		t, ok := k.Embed(KiT_Text).(*Text)
+		if !ok || t == nil {
+			return false
+		}
+		t.setLayoutBBox(gls)
+		t.renderSpan(rs, gls, scy)
+		if t.laidOut {
+			t.ComputeBBoxSVG()
+		}
+		return true
+	})
+}
+
+// renderSpan renders the runes of this element's own text, as positioned by
+// renderGlyphs, using a font scaled by the given transform y scaling
+func (g *Text) renderSpan(rs *gi.RenderState, gls []textGlyph, scy float32) {
+	if len(g.Render.Spans) == 0 {
+		return
+	}
+	pc := &g.Pnt
+	sr := &(g.Render.Spans[0])
+	sr.RelPos = gi.Vec2D{}
+	// trim any runes that are not rendered, which are always at the ends
+	st, ed := 0, len(sr.Render)
+	for st < ed && gls[g.glyphSt+st].hide {
+		st++
+	}
+	for ed > st && gls[g.glyphSt+ed-1].hide {
+		ed--
+	}
+	if st == ed {
+		g.Render.Spans = nil
+		return
+	}
+	if st > 0 && sr.Render[st].Color == nil {
+		sr.Render[st].Color = sr.Render[0].Color
+	}
+	sr.Text = sr.Text[st:ed]
+	sr.Render = sr.Render[st:ed]
+	sr.Glyphs = nil // runes are positioned individually, so rendered individually
+	orgsz := pc.FontStyle.Size
+	pc.FontStyle.Size = units.Value{orgsz.Val * scy, orgsz.Un, orgsz.Dots * scy} // rescale by y
+	pc.FontStyle.OpenFont(&pc.UnContext)
+	sr.Render[0].Face = pc.FontStyle.Face // upscale
+	pc.FontStyle.Size = orgsz
+	g.Render.Render(rs, gi.Vec2D{})
+}
+
+// setLayoutBBox sets the bounding box of the laid-out glyphs of this text
+// element and those within it, in user coordinates
+func (g *Text) setLayoutBBox(gls []textGlyph) {
+	g.laidOut = false
+	pc := &g.Pnt
+	if pc.FontStyle.Face == nil {
+		return
+	}
+	met := pc.FontStyle.Face.Metrics()
+	asc := gi.FixedToFloat32(met.Ascent)
+	dsc := gi.FixedToFloat32(met.Descent)
+	for _, gl := range gls[g.glyphSt:g.glyphEd] {
+		if gl.hide {
+			continue
+		}
+		min, max := gi.Vec2D{0, -asc}, gi.Vec2D{gl.adv, dsc}
+		if gl.vert { // em box centered on the column
+			min, max = gi.Vec2D{-0.5 * (asc + dsc), 0}, gi.Vec2D{0.5 * (asc + dsc), gl.adv}
+		}
+		if gl.rot != 0 {
+			min, max = XFormBBox(gi.Rotate2D(gl.rot).Multiply(gi.Translate2D(gl.pos.X, gl.pos.Y)), min, max)
+		} else {
+			min, max = min.Add(gl.pos), max.Add(gl.pos)
+		}
+		if !g.laidOut {
+			g.layMin, g.layMax = min, max
+			g.laidOut = true
+			continue
+		}
+		g.layMin.SetMin(min)
+		g.layMax.SetMax(max)
+	}
+}
+
+// LocalBBox returns the bounding box of the text in its user space, as of
+// the last layout -- prior to layout, it is only approximate, based on the
+// last rendered size of the text, which includes any scaling transform
+func (g *Text) LocalBBox() (min, max gi.Vec2D) {
+	if g.laidOut {
+		return g.layMin, g.layMax
+	}
+	min = gi.Vec2D{g.Pos.X, g.Pos.Y - g.Render.Size.Y}
+	max = gi.Vec2D{g.Pos.X + g.Render.Size.X, g.Pos.Y}
+	return
+}
+
+// TextXMLSpace returns the given character data from text within a SVG text
+// element, with each run of white space (including newlines) collapsed into
+// a single space, per the default xml:space handling -- leading space is
+// removed if trimLeft, e.g., at the start of the text element
+func TextXMLSpace(s string, trimLeft bool) string {
+	var sb strings.Builder
+	sp := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			sp = true
+			continue
+		}
+		if sp && (sb.Len() > 0 || !trimLeft) {
+			sb.WriteByte(' ')
+		}
+		sp = false
+		sb.WriteRune(r)
+	}
+	if sp && sb.Len() > 0 {
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
+
+// AddXMLText adds given character data from within this element in a SVG
+// file -- any text following a tspan or textPath is added as a new tspan,
+// and white space between elements is ignored
+func (g *Text) AddXMLText(s string) {
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+	if g.HasChildren() {
+		tsp := g.AddNewChild(KiT_Text, "tspan").(*Text)
+		tsp.Text = TextXMLSpace(s, false)
+		return
+	}
+	g.Text += TextXMLSpace(s, g.Text == "" && !g.IsParText())
+}
+
+// TrimXMLSpaceRight removes any trailing space from the last text within
+// this text element, at the end of the element in a SVG file
+func (g *Text) TrimXMLSpaceRight() {
+	lt := g
+	for lt.HasChildren() {
+		kt, ok := lt.Kids[len(lt.Kids)-1].Embed(KiT_Text).(*Text)
+		if !ok || kt == nil {
+			break
+		}
+		lt = kt
+	}
+	lt.Text = strings.TrimRight(lt.Text, " ")
 }
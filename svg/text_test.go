@@ -0,0 +1,54 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"testing"
+
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+)
+
+func TestTextXMLSpace(t *testing.T) {
+	cases := []struct {
+		in       string
+		trimLeft bool
+		out      string
+	}{
+		{"Hello", true, "Hello"},
+		{"\n   Hello \t world\n  ", true, "Hello world "},
+		{"\n   Hello \t world\n  ", false, " Hello world "},
+		{" \n ", false, ""},
+	}
+	for _, c := range cases {
+		if out := TextXMLSpace(c.in, c.trimLeft); out != c.out {
+			t.Errorf("TextXMLSpace(%q, %v): expected: %q got: %q", c.in, c.trimLeft, c.out, out)
+		}
+	}
+}
+
+func TestPathFlat(t *testing.T) {
+	p := &Path{}
+	p.InitName(p, "path")
+	p.Pnt.XForm = gi.Identity2D()
+	if err := p.SetData("M 0 0 L 100 0 L 100 50"); err != nil {
+		t.Fatal(err)
+	}
+	pf := NewPathFlat(p)
+	if ln := pf.Length(); ln != 150 {
+		t.Errorf("path length should be 150, got: %v", ln)
+	}
+	pt, ang, ok := pf.PointAt(50)
+	if !ok || pt != (gi.Vec2D{50, 0}) || ang != 0 {
+		t.Errorf("point at 50: expected: (50, 0) angle 0, got: %v angle %v ok: %v", pt, ang, ok)
+	}
+	pt, ang, ok = pf.PointAt(125)
+	if !ok || pt != (gi.Vec2D{100, 25}) || math32.Abs(ang-math32.Pi/2) > 1.0e-5 {
+		t.Errorf("point at 125: expected: (100, 25) angle Pi/2, got: %v angle %v ok: %v", pt, ang, ok)
+	}
+	if _, _, ok = pf.PointAt(151); ok {
+		t.Errorf("point beyond end of path should not be ok")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"github.com/chewxy/math32"
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/kit"
+	"golang.org/x/image/math/fixed"
+)
+
+// TextPath is a SVG textPath element, within a Text element, which lays
+// out its text (including any tspans within it) along the Path that it
+// refers to by Href -- each glyph is positioned at its distance along the
+// path and rotated to follow it, and glyphs beyond the ends of the path are
+// not rendered
+type TextPath struct {
+	Text
+	Href           string  `xml:"href" desc:"link to the path to lay out the text along, e.g., #id"`
+	StartOffset    float32 `xml:"startOffset" desc:"distance along the path at which the text starts -- a percentage of the length of the path if StartOffsetPct"`
+	StartOffsetPct bool    `desc:"StartOffset is a percentage of the length of the path"`
+}
+
+var KiT_TextPath = kit.Types.AddType(&TextPath{}, nil)
+
+// PathRef returns the path referred to by Href, or nil if not found
+func (g *TextPath) PathRef() *Path {
+	if g.Href == "" {
+		return nil
+	}
+	p, _ := g.FindSVGURL(g.Href).(*Path)
+	return p
+}
+
+// layoutOnPath maps the given glyphs, laid out horizontally as distances
+// along the path (and offsets from it in Y), onto the path, updating the
+// current text position to the end of the last glyph
+func (g *TextPath) layoutOnPath(gls []textGlyph, cur *gi.Vec2D) {
+	for i := range gls {
+		gls[i].onPath = true
+		gls[i].hide = true
+	}
+	p := g.PathRef()
+	n := len(gls)
+	if p == nil || n == 0 {
+		return
+	}
+	pf := NewPathFlat(p)
+	off := g.StartOffset
+	if g.StartOffsetPct {
+		off *= pf.Length() / 100
+	}
+	ln := gls[n-1].pos.X + gls[n-1].adv - gls[0].pos.X
+	off -= TextAnchorFrac(&g.Pnt) * ln
+	for i := range gls {
+		gl := &gls[i]
+		pt, ang, ok := pf.PointAt(off + gl.pos.X + 0.5*gl.adv)
+		if !ok {
+			continue
+		}
+		tan := gi.Vec2D{math32.Cos(ang), math32.Sin(ang)}
+		nrm := gi.Vec2D{-tan.Y, tan.X}
+		gl.pos = pt.Sub(tan.MulVal(0.5 * gl.adv)).Add(nrm.MulVal(gl.pos.Y))
+		gl.rot += ang
+		gl.hide = false
+		*cur = gl.pos.Add(tan.MulVal(gl.adv))
+	}
+}
+
+// PathFlat is a path flattened into a sequence of straight line segments,
+// for finding points and angles at given distances along the path, e.g., to
+// lay out text along it -- it is a rasterx.Adder, which is used to collect
+// the segments from a rendered path
+type PathFlat struct {
+	Segs  [][2]gi.Vec2D `desc:"line segments, as start, end points"`
+	Dists []float32     `desc:"cumulative distance along the path at the end of each segment"`
+	start gi.Vec2D
+	cur   gi.Vec2D
+}
+
+// pathFlatCurveSegs is the number of line segments used for each curve
+const pathFlatCurveSegs = 16
+
+// NewPathFlat returns the flattened version of given path, including the
+// transform of the path itself
+func NewPathFlat(p *Path) *PathFlat {
+	rs := &gi.RenderState{}
+	rs.XForm = p.Pnt.XForm
+	PathDataRender(p.Data, &p.Pnt, rs)
+	pf := &PathFlat{}
+	rs.Path.AddTo(pf)
+	return pf
+}
+
+// Length returns the total length of the path
+func (pf *PathFlat) Length() float32 {
+	if len(pf.Dists) == 0 {
+		return 0
+	}
+	return pf.Dists[len(pf.Dists)-1]
+}
+
+// PointAt returns the point at given distance along the path, and the angle
+// of the path there, in radians -- false if the distance is beyond either
+// end of the path
+func (pf *PathFlat) PointAt(d float32) (pt gi.Vec2D, ang float32, ok bool) {
+	if d < 0 || d > pf.Length() {
+		return
+	}
+	prv := float32(0)
+	for i, sd := range pf.Dists {
+		if d > sd && i < len(pf.Dists)-1 {
+			prv = sd
+			continue
+		}
+		sg := pf.Segs[i]
+		dv := sg[1].Sub(sg[0])
+		t := float32(0)
+		if sd > prv {
+			t = (d - prv) / (sd - prv)
+		}
+		return sg[0].Add(dv.MulVal(t)), math32.Atan2(dv.Y, dv.X), true
+	}
+	return
+}
+
+func (pf *PathFlat) addSeg(b gi.Vec2D) {
+	ln := pf.cur.Distance(b)
+	if ln == 0 {
+		return
+	}
+	pf.Segs = append(pf.Segs, [2]gi.Vec2D{pf.cur, b})
+	pf.Dists = append(pf.Dists, pf.Length()+ln)
+	pf.cur = b
+}
+
+// Start starts a new sub-path at given point -- rasterx.Adder interface
+func (pf *PathFlat) Start(a fixed.Point26_6) {
+	pf.start = gi.NewVec2DFmFixed(a)
+	pf.cur = pf.start
+}
+
+// Line adds a line to given point -- rasterx.Adder interface
+func (pf *PathFlat) Line(b fixed.Point26_6) {
+	pf.addSeg(gi.NewVec2DFmFixed(b))
+}
+
+// QuadBezier adds a quadratic bezier curve -- rasterx.Adder interface
+func (pf *PathFlat) QuadBezier(b, c fixed.Point26_6) {
+	p0, p1, p2 := pf.cur, gi.NewVec2DFmFixed(b), gi.NewVec2DFmFixed(c)
+	for i := 1; i <= pathFlatCurveSegs; i++ {
+		t := float32(i) / pathFlatCurveSegs
+		mt := 1 - t
+		pf.addSeg(p0.MulVal(mt * mt).Add(p1.MulVal(2 * mt * t)).Add(p2.MulVal(t * t)))
+	}
+}
+
+// CubeBezier adds a cubic bezier curve -- rasterx.Adder interface
+func (pf *PathFlat) CubeBezier(b, c, d fixed.Point26_6) {
+	p0, p1, p2, p3 := pf.cur, gi.NewVec2DFmFixed(b), gi.NewVec2DFmFixed(c), gi.NewVec2DFmFixed(d)
+	for i := 1; i <= pathFlatCurveSegs; i++ {
+		t := float32(i) / pathFlatCurveSegs
+		mt := 1 - t
+		pf.addSeg(p0.MulVal(mt * mt * mt).Add(p1.MulVal(3 * mt * mt * t)).Add(p2.MulVal(3 * mt * t * t)).Add(p3.MulVal(t * t * t)))
+	}
+}
+
+// Stop ends the current sub-path, closing it if closeLoop -- rasterx.Adder
+// interface
+func (pf *PathFlat) Stop(closeLoop bool) {
+	if closeLoop {
+		pf.addSeg(pf.start)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+	"log"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// Use is a SVG use element, which renders another element (or Symbol) that
+// it refers to by Href, at its own position -- the referenced element is
+// looked up again on each render, so any changes to it are reflected, and
+// elements can be referred to before they are defined.
+type Use struct {
+	NodeBase
+	Pos       gi.Vec2D  `xml:"{x,y}" desc:"position of the referenced element -- added to the transform"`
+	Size      gi.Vec2D  `xml:"{width,height}" desc:"size of the viewport for a referenced Symbol -- zero means use the size of the symbol viewbox"`
+	Href      string    `xml:"href" desc:"link to the referenced element, e.g., #id"`
+	Ref       gi.Node2D `json:"-" xml:"-" view:"-" desc:"referenced element, as of the last render"`
+	refBBox   image.Rectangle
+	rendering bool
+}
+
+var KiT_Use = kit.Types.AddType(&Use{}, nil)
+
+// FindRef looks up the element referred to by Href, returning nil if not
+// found
+func (g *Use) FindRef() gi.Node2D {
+	if g.Href == "" {
+		return nil
+	}
+	ref := g.FindSVGURL(g.Href)
+	if ref == g.This() {
+		return nil
+	}
+	return ref
+}
+
+// SymbolXForm returns the transform for rendering the given symbol within
+// the viewport defined by our Size
+func (g *Use) SymbolXForm(sym *Symbol) gi.Matrix2D {
+	sz := g.Size
+	if sz.X == 0 {
+		sz.X = sym.ViewBox.Size.X
+	}
+	if sz.Y == 0 {
+		sz.Y = sym.ViewBox.Size.Y
+	}
+	return sym.ViewBox.XForm(sz)
+}
+
+func (g *Use) Render2D() {
+	if g.Viewport == nil {
+		g.This().(gi.Node2D).Init2D()
+	}
+	if g.rendering {
+		log.Printf("gi.svg Use: %v: recursive reference to: %v -- not rendered\n", g.PathUnique(), g.Href)
+		return
+	}
+	g.Ref = g.FindRef()
+	if g.Ref == nil {
+		return
+	}
+	g.rendering = true
+	pc := &g.Pnt
+	rs := &g.Viewport.Render
+	clipped := g.PushClipPath()
+	filt := g.PushFilter()
+	rs.PushXFormLock(pc.XForm)
+	rs.PushXFormLock(gi.Translate2D(g.Pos.X, g.Pos.Y))
+	// the referenced element renders in place, so its own bboxes (and those
+	// of its children) are restored afterward -- otherwise they would end up
+	// as those of the last use of it
+	saved := saveBBoxes(g.Ref)
+	if sym, ok := g.Ref.(*Symbol); ok {
+		rs.PushXFormLock(g.SymbolXForm(sym))
+		sym.Render2DChildren()
+		rs.PopXFormLock()
+		g.refBBox = sym.BBoxFromChildren()
+	} else {
+		g.Ref.Render2D()
+		g.refBBox = g.Ref.AsNode2D().BBox
+	}
+	restoreBBoxes(saved)
+	rs.PopXFormLock()
+	rs.PopXFormLock()
+	g.ComputeBBoxSVG()
+	g.PopFilter(filt)
+	g.PopClipPath(clipped)
+	g.rendering = false
+}
+
+// BBox2D returns the bounding box of the referenced element, as last
+// rendered by us
+func (g *Use) BBox2D() image.Rectangle {
+	if g.Ref == nil {
+		return image.ZR
+	}
+	return g.refBBox
+}
+
+// nodeBBoxes records the bounding boxes of a node
+type nodeBBoxes struct {
+	nb                             *gi.Node2DBase
+	BBox, ObjBBox, VpBBox, WinBBox image.Rectangle
+}
+
+// saveBBoxes returns the bounding boxes of k and all of its children, for
+// restoreBBoxes
+func saveBBoxes(k ki.Ki) []nodeBBoxes {
+	var saved []nodeBBoxes
+	k.FuncDownMeFirst(0, nil, func(kid ki.Ki, level int, d interface{}) bool {
+		_, nb := gi.KiToNode2D(kid)
+		if nb != nil {
+			saved = append(saved, nodeBBoxes{nb, nb.BBox, nb.ObjBBox, nb.VpBBox, nb.WinBBox})
+		}
+		return true
+	})
+	return saved
+}
+
+// restoreBBoxes restores bounding boxes saved by saveBBoxes
+func restoreBBoxes(saved []nodeBBoxes) {
+	for _, sb := range saved {
+		sb.nb.BBox, sb.nb.ObjBBox, sb.nb.VpBBox, sb.nb.WinBBox = sb.BBox, sb.ObjBBox, sb.VpBBox, sb.WinBBox
+	}
+}
+
+// LocalBBox returns the bounding box of the referenced element in our user
+// space -- for a Symbol, it is the viewport defined by our Pos and Size
+func (g *Use) LocalBBox() (min, max gi.Vec2D) {
+	ref := g.Ref
+	if ref == nil {
+		ref = g.FindRef()
+	}
+	switch rf := ref.(type) {
+	case nil:
+		return
+	case *Symbol:
+		sz := g.Size
+		if sz.IsZero() {
+			sz = rf.ViewBox.Size
+		}
+		return g.Pos, g.Pos.Add(sz)
+	case LocalBBoxer:
+		min, max = rf.LocalBBox()
+		if pntr, ok := ref.(gi.Painter); ok {
+			min, max = XFormBBox(pntr.Paint().XForm, min, max)
+		}
+		return min.Add(g.Pos), max.Add(g.Pos)
+	}
+	return
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"image"
+	"testing"
+)
+
+func TestUseRestoreBBoxes(t *testing.T) {
+	g := &Group{}
+	g.InitName(g, "group")
+	r := g.AddNewChild(KiT_Rect, "rect").(*Rect)
+	g.BBox = image.Rect(0, 0, 20, 20)
+	r.BBox = image.Rect(5, 5, 10, 10)
+	r.WinBBox = image.Rect(15, 15, 20, 20)
+	saved := saveBBoxes(g)
+	g.BBox = image.Rect(50, 50, 70, 70)
+	r.BBox = image.Rect(55, 55, 60, 60)
+	r.WinBBox = image.ZR
+	restoreBBoxes(saved)
+	if g.BBox != image.Rect(0, 0, 20, 20) {
+		t.Errorf("group bbox not restored: %v", g.BBox)
+	}
+	if r.BBox != image.Rect(5, 5, 10, 10) || r.WinBBox != image.Rect(15, 15, 20, 20) {
+		t.Errorf("rect bboxes not restored: %v %v", r.BBox, r.WinBBox)
+	}
+}
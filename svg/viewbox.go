@@ -4,7 +4,12 @@
 
 package svg
 
-import "github.com/goki/gi/gi"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
 
 ////////////////////////////////////////////////////////////////////////////////////////
 // ViewBox defines the SVG viewbox
@@ -16,13 +21,12 @@ type ViewBox struct {
 	PreserveAspectRatio ViewBoxPreserveAspectRatio `desc:"how to scale the view box within parent Viewport2D"`
 }
 
-// todo: need to implement the viewbox preserve aspect ratio logic!
-
-// Defaults returns viewbox to defaults
+// Defaults returns viewbox to defaults -- a zero Align is the svg default
+// of "xMidYMid meet"
 func (vb *ViewBox) Defaults() {
 	vb.Min = gi.Vec2DZero
 	vb.Size = gi.Vec2DZero
-	vb.PreserveAspectRatio.Align = None
+	vb.PreserveAspectRatio.Align = 0
 	vb.PreserveAspectRatio.MeetOrSlice = Meet
 }
 
@@ -62,3 +66,116 @@ type ViewBoxPreserveAspectRatio struct {
 	Align       ViewBoxAlign       `svg:"align" desc:"how to align x,y coordinates within viewbox"`
 	MeetOrSlice ViewBoxMeetOrSlice `svg:"meetOrSlice" desc:"how to scale the view box relative to the viewport"`
 }
+
+// viewBoxAlignNames are the svg names of the x and y alignment values
+var viewBoxAlignNames = []struct {
+	align ViewBoxAlign
+	name  string
+}{{XMin, "xMin"}, {XMid, "xMid"}, {XMax, "xMax"}, {YMin, "YMin"}, {YMid, "YMid"}, {YMax, "YMax"}}
+
+// SetString sets the preserve aspect ratio from a standard svg-formatted
+// preserveAspectRatio string, e.g., "xMidYMid meet" or "none"
+func (pa *ViewBoxPreserveAspectRatio) SetString(s string) error {
+	flds := strings.Fields(s)
+	if len(flds) > 0 && flds[0] == "defer" {
+		flds = flds[1:]
+	}
+	if len(flds) == 0 {
+		return fmt.Errorf("svg: empty preserveAspectRatio value")
+	}
+	pa.MeetOrSlice = Meet
+	if flds[0] == "none" {
+		pa.Align = None
+	} else {
+		al := flds[0]
+		pa.Align = 0
+		for _, an := range viewBoxAlignNames {
+			if strings.Contains(al, an.name) {
+				pa.Align |= an.align
+			}
+		}
+		if pa.Align&XMask == 0 || pa.Align&YMask == 0 {
+			pa.Align = 0
+			return fmt.Errorf("svg: invalid preserveAspectRatio alignment: %v", al)
+		}
+	}
+	if len(flds) > 1 && flds[1] == "slice" {
+		pa.MeetOrSlice = Slice
+	}
+	return nil
+}
+
+// String returns the standard svg-formatted preserveAspectRatio string --
+// a zero Align is the svg default of "xMidYMid meet"
+func (pa *ViewBoxPreserveAspectRatio) String() string {
+	al := pa.Align
+	if al == 0 {
+		al = XMid | YMid
+	}
+	s := ""
+	if al&None != 0 {
+		s = "none"
+	} else {
+		for _, an := range viewBoxAlignNames {
+			if al&an.align != 0 {
+				s += an.name
+			}
+		}
+	}
+	if pa.MeetOrSlice == Slice {
+		s += " slice"
+	}
+	return s
+}
+
+// IsDefault returns true if the preserve aspect ratio has the svg default
+// value of "xMidYMid meet"
+func (pa *ViewBoxPreserveAspectRatio) IsDefault() bool {
+	return (pa.Align == 0 || pa.Align == XMid|YMid) && pa.MeetOrSlice == Meet
+}
+
+// Transform returns the transform that maps the viewbox into a viewport of
+// given size, according to PreserveAspectRatio, as a scaling that is applied
+// first, followed by a translation -- a zero Align is the svg default of
+// "xMidYMid meet".  If the viewbox has zero size, then the identity
+// transform is returned.
+func (vb *ViewBox) Transform(size gi.Vec2D) (scale, trans gi.Vec2D) {
+	if vb.Size.X == 0 || vb.Size.Y == 0 {
+		return gi.Vec2D{1, 1}, gi.Vec2DZero
+	}
+	scale = size.Div(vb.Size)
+	al := vb.PreserveAspectRatio.Align
+	if al == 0 {
+		al = XMid | YMid
+	}
+	if al&None != 0 {
+		return scale, vb.Min.Mul(scale).MulVal(-1)
+	}
+	sc := gi.Min32(scale.X, scale.Y)
+	if vb.PreserveAspectRatio.MeetOrSlice == Slice {
+		sc = gi.Max32(scale.X, scale.Y)
+	}
+	scale = gi.Vec2D{sc, sc}
+	trans = vb.Min.MulVal(-sc)
+	extra := size.Sub(vb.Size.MulVal(sc))
+	switch {
+	case al&XMid != 0:
+		trans.X += 0.5 * extra.X
+	case al&XMax != 0:
+		trans.X += extra.X
+	}
+	switch {
+	case al&YMid != 0:
+		trans.Y += 0.5 * extra.Y
+	case al&YMax != 0:
+		trans.Y += extra.Y
+	}
+	return scale, trans
+}
+
+// XForm returns the transform that maps the viewbox into a viewport of
+// given size, according to PreserveAspectRatio -- see Transform
+func (vb *ViewBox) XForm(size gi.Vec2D) gi.Matrix2D {
+	scale, trans := vb.Transform(size)
+	return gi.Identity2D().Translate(trans.X, trans.Y).Scale(scale.X, scale.Y)
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2018, The GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package svg
+
+import (
+	"testing"
+
+	"github.com/goki/gi/gi"
+)
+
+func TestViewBoxDefaults(t *testing.T) {
+	vb := ViewBox{}
+	vb.PreserveAspectRatio.SetString("none")
+	vb.Defaults()
+	if !vb.PreserveAspectRatio.IsDefault() {
+		t.Errorf("defaults preserveAspectRatio: %v", vb.PreserveAspectRatio.String())
+	}
+	vb.Size = gi.Vec2D{10, 10}
+	if sc, tr := vb.Transform(gi.Vec2D{20, 10}); sc != (gi.Vec2D{1, 1}) || tr != (gi.Vec2D{5, 0}) {
+		t.Errorf("defaults transform: scale %v trans %v", sc, tr)
+	}
+}
+
+func TestViewBoxTransform(t *testing.T) {
+	tests := []struct {
+		par   string
+		scale gi.Vec2D
+		trans gi.Vec2D
+	}{
+		{"none", gi.Vec2D{2, 1}, gi.Vec2D{0, 0}},
+		{"xMinYMin meet", gi.Vec2D{1, 1}, gi.Vec2D{0, 0}},
+		{"xMaxYMax meet", gi.Vec2D{1, 1}, gi.Vec2D{10, 0}},
+		{"xMidYMid slice", gi.Vec2D{2, 2}, gi.Vec2D{0, -5}},
+	}
+	for _, tt := range tests {
+		vb := ViewBox{Size: gi.Vec2D{10, 10}}
+		if err := vb.PreserveAspectRatio.SetString(tt.par); err != nil {
+			t.Errorf("%v: %v", tt.par, err)
+			continue
+		}
+		if sc, tr := vb.Transform(gi.Vec2D{20, 10}); sc != tt.scale || tr != tt.trans {
+			t.Errorf("%v: scale %v trans %v, want %v %v", tt.par, sc, tr, tt.scale, tt.trans)
+		}
+	}
+}
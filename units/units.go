@@ -312,6 +312,17 @@ func (v *Value) Convert(to Unit, ctxt *Context) Value {
 	return Value{dots / ctxt.ToDotsFactor(to), to, dots}
 }
 
+// Interpolate returns the value t (0..1) of the way from this value to given
+// value -- if they have different units, the result is in Dot units, using
+// the Dots of both values, which must have been computed with ToDots
+func (v *Value) Interpolate(to Value, t float32) Value {
+	dots := v.Dots + (to.Dots-v.Dots)*t
+	if v.Un == to.Un {
+		return Value{v.Val + (to.Val-v.Val)*t, v.Un, dots}
+	}
+	return Value{dots, Dot, dots}
+}
+
 // String implements the fmt.Stringer interface.
 func (v *Value) String() string {
 	return fmt.Sprintf("%f%s", v.Val, UnitNames[v.Un])